@@ -0,0 +1,97 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorkeycmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs(endpointURL(string([]byte{0x0})))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse 'url'")
+	})
+}
+
+func TestRotate(t *testing.T) {
+	var method, authHeader string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		authHeader = r.Header.Get("Authorization")
+
+		_, err := fmt.Fprint(w, `{"id":"key-1"}`)
+		require.NoError(t, err)
+	}))
+	defer serv.Close()
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetCmd()
+
+		cmd.SetArgs(endpointURL("wrongurl"))
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, endpointURL(serv.URL+"/actorkeys/rotate")...)
+		args = append(args, flag+authTokenFlagName, "token")
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, http.MethodPost, method)
+		require.Equal(t, "Bearer token", authHeader)
+	})
+}
+
+func endpointURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
@@ -0,0 +1,164 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package casgccmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the CAS garbage collection endpoint, e.g. https://orb.domain.com/cas/gc." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	dryRunFlagName  = "dry-run"
+	dryRunFlagUsage = "If true then the unreachable content is reported but not deleted." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + dryRunEnvKey
+	dryRunEnvKey = "ORB_CLI_DRY_RUN"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const dryRunParam = "dry-run"
+
+// GetCmd returns the Cobra CAS garbage collection command.
+func GetCmd() *cobra.Command {
+	createCmd := createCmd()
+
+	createFlags(createCmd)
+
+	return createCmd
+}
+
+func createCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "gc",
+		Short: "garbage collect the local CAS",
+		Long: "Deletes content from the local CAS that's no longer reachable from any DID anchor. " +
+			"The result (including the number of reclaimed bytes) is written to stdout.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			endpointURL, err := getEndpointURL(cmd)
+			if err != nil {
+				return err
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName,
+				authTokenEnvKey)
+
+			headers := make(map[string]string)
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			resp, err := common.SendRequest(httpClient, nil, headers, http.MethodPost, endpointURL)
+			if err != nil {
+				return fmt.Errorf("failed to send http request: %w", err)
+			}
+
+			fmt.Printf("%s\n", resp)
+
+			return nil
+		},
+	}
+}
+
+func getEndpointURL(cmd *cobra.Command) (string, error) {
+	endpoint, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+	if err != nil {
+		return "", err
+	}
+
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("parse 'url' %s: %w", endpoint, err)
+	}
+
+	dryRunString := cmdutils.GetUserSetOptionalVarFromString(cmd, dryRunFlagName, dryRunEnvKey)
+
+	if dryRunString != "" {
+		dryRun, err := strconv.ParseBool(dryRunString)
+		if err != nil {
+			return "", fmt.Errorf("invalid value for %s [%s]: %w", dryRunFlagName, dryRunString, err)
+		}
+
+		query := endpointURL.Query()
+		query.Set(dryRunParam, strconv.FormatBool(dryRun))
+
+		endpointURL.RawQuery = query.Encode()
+	}
+
+	return endpointURL.String(), nil
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	startCmd.Flags().StringP(dryRunFlagName, "", "", dryRunFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package casgccmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestStartCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		startCmd.SetArgs(endpointURL(string([]byte{0x0})))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse 'url'")
+	})
+
+	t.Run("test invalid dry-run arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, endpointURL("localhost:8080")...)
+		args = append(args, dryRun("xxx")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for dry-run [xxx]")
+	})
+}
+
+func TestCollect(t *testing.T) {
+	var dryRunValue string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dryRunValue = r.URL.Query().Get(dryRunParam)
+
+		_, err := fmt.Fprint(w, `{"collected":2}`)
+		require.NoError(t, err)
+	}))
+	defer serv.Close()
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetCmd()
+
+		cmd.SetArgs(endpointURL("wrongurl"))
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetCmd()
+
+		cmd.SetArgs(endpointURL(serv.URL + "/cas/gc"))
+
+		require.NoError(t, cmd.Execute())
+		require.Empty(t, dryRunValue)
+	})
+
+	t.Run("success - dry run", func(t *testing.T) {
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, endpointURL(serv.URL+"/cas/gc")...)
+		args = append(args, dryRun("true")...)
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, "true", dryRunValue)
+	})
+}
+
+func endpointURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func dryRun(value string) []string {
+	return []string{flag + dryRunFlagName, value}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package exportdidcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the anchor export endpoint, e.g. https://orb.domain.com/anchor/export." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	didURIFlagName  = "did-uri"
+	didURIFlagUsage = "The DID (or DID suffix) to export. " +
+		" Alternatively, this can be set with the following environment variable: " + didURIEnvKey
+	didURIEnvKey = "ORB_CLI_DID_URI"
+
+	outputFileFlagName  = "output-file"
+	outputFileFlagUsage = "The file to which the CAR file is written." +
+		" Alternatively, this can be set with the following environment variable: " + outputFileEnvKey
+	outputFileEnvKey = "ORB_CLI_OUTPUT_FILE"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const outputFilePerm = 0o600
+
+// GetExportDIDCmd returns the Cobra export DID command.
+func GetExportDIDCmd() *cobra.Command {
+	exportDIDCmd := exportDIDCmd()
+
+	createFlags(exportDIDCmd)
+
+	return exportDIDCmd
+}
+
+func exportDIDCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export",
+		Short: "export the anchor history of a DID",
+		Long: "Exports the anchors of a DID, along with the Sidetree files that they reference, " +
+			"to a CAR (IPLD Content Archive) file.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			endpointURL, err := getEndpointURL(cmd)
+			if err != nil {
+				return err
+			}
+
+			outputFile, err := cmdutils.GetUserSetVarFromString(cmd, outputFileFlagName, outputFileEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName,
+				authTokenEnvKey)
+
+			headers := make(map[string]string)
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			resp, err := common.SendRequest(httpClient, nil, headers, http.MethodGet, endpointURL)
+			if err != nil {
+				return fmt.Errorf("failed to send http request: %w", err)
+			}
+
+			if err := ioutil.WriteFile(outputFile, resp, outputFilePerm); err != nil {
+				return fmt.Errorf("failed to write file %s: %w", outputFile, err)
+			}
+
+			fmt.Printf("Exported %d bytes to %s\n", len(resp), outputFile)
+
+			return nil
+		},
+	}
+}
+
+func getEndpointURL(cmd *cobra.Command) (string, error) {
+	endpoint, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := url.Parse(endpoint); err != nil {
+		return "", fmt.Errorf("parse 'url' %s: %w", endpoint, err)
+	}
+
+	didURI, err := cmdutils.GetUserSetVarFromString(cmd, didURIFlagName, didURIEnvKey, false)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(endpoint, "/") + "/" + url.PathEscape(didURI), nil
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	startCmd.Flags().StringP(didURIFlagName, "", "", didURIFlagUsage)
+	startCmd.Flags().StringP(outputFileFlagName, "", "", outputFileFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package exportdidcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetExportDIDCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestExportDIDCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetExportDIDCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		startCmd := GetExportDIDCmd()
+
+		startCmd.SetArgs(endpointURL(string([]byte{0x0})))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse 'url'")
+	})
+
+	t.Run("test missing did-uri arg", func(t *testing.T) {
+		startCmd := GetExportDIDCmd()
+
+		startCmd.SetArgs(endpointURL("localhost:8080"))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither did-uri (command line flag) nor ORB_CLI_DID_URI (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test missing output-file arg", func(t *testing.T) {
+		startCmd := GetExportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL("localhost:8080")...)
+		args = append(args, didURI("did:orb:uAAA:suffix")...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither output-file (command line flag) nor ORB_CLI_OUTPUT_FILE (environment variable) have been set.",
+			err.Error())
+	})
+}
+
+func TestExportDID(t *testing.T) {
+	var path string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.EscapedPath()
+
+		_, err := fmt.Fprint(w, "car file")
+		require.NoError(t, err)
+	}))
+	defer serv.Close()
+
+	outputFile := filepath.Join(t.TempDir(), "did.car")
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetExportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL("wrongurl")...)
+		args = append(args, didURI("suffix")...)
+		args = append(args, outputFileArg(outputFile)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("test failed to write file", func(t *testing.T) {
+		cmd := GetExportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL(serv.URL+"/anchor/export")...)
+		args = append(args, didURI("suffix")...)
+		args = append(args, outputFileArg(filepath.Join(t.TempDir(), "missing", "did.car"))...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to write file")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetExportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL(serv.URL+"/anchor/export/")...)
+		args = append(args, didURI("did:orb:uAAA:suffix")...)
+		args = append(args, outputFileArg(outputFile)...)
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, "/anchor/export/did:orb:uAAA:suffix", path)
+
+		content, err := ioutil.ReadFile(outputFile) //nolint:gosec
+		require.NoError(t, err)
+		require.Equal(t, "car file", string(content))
+	})
+}
+
+func endpointURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func didURI(value string) []string {
+	return []string{flag + didURIFlagName, value}
+}
+
+func outputFileArg(value string) []string {
+	return []string{flag + outputFileFlagName, value}
+}
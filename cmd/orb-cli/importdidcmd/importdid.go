@@ -0,0 +1,151 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package importdidcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+)
+
+const (
+	urlFlagName  = "url"
+	urlFlagUsage = "The URL of the anchor import endpoint, e.g. https://orb.domain.com/anchor/import." +
+		" Alternatively, this can be set with the following environment variable: " + urlEnvKey
+	urlEnvKey = "ORB_CLI_URL"
+
+	inputFileFlagName  = "input-file"
+	inputFileFlagUsage = "The CAR file (produced by the export command) to import." +
+		" Alternatively, this can be set with the following environment variable: " + inputFileEnvKey
+	inputFileEnvKey = "ORB_CLI_INPUT_FILE"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const carContentType = "application/vnd.ipld.car"
+
+// GetImportDIDCmd returns the Cobra import DID command.
+func GetImportDIDCmd() *cobra.Command {
+	importDIDCmd := importDIDCmd()
+
+	createFlags(importDIDCmd)
+
+	return importDIDCmd
+}
+
+func importDIDCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import",
+		Short: "import the anchor history of a DID",
+		Long: "Imports a CAR (IPLD Content Archive) file that was produced by the export command. " +
+			"The content is written to the CAS and the anchors are processed so that the DID may be resolved. " +
+			"The result is written to stdout.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			endpoint, err := cmdutils.GetUserSetVarFromString(cmd, urlFlagName, urlEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			if _, err := url.Parse(endpoint); err != nil {
+				return fmt.Errorf("parse 'url' %s: %w", endpoint, err)
+			}
+
+			inputFile, err := cmdutils.GetUserSetVarFromString(cmd, inputFileFlagName, inputFileEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			content, err := ioutil.ReadFile(inputFile) //nolint:gosec
+			if err != nil {
+				return fmt.Errorf("failed to read file %s: %w", inputFile, err)
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName,
+				authTokenEnvKey)
+
+			headers := map[string]string{"Content-Type": carContentType}
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			resp, err := common.SendRequest(httpClient, content, headers, http.MethodPost, endpoint)
+			if err != nil {
+				return fmt.Errorf("failed to send http request: %w", err)
+			}
+
+			fmt.Printf("%s\n", resp)
+
+			return nil
+		},
+	}
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(urlFlagName, "", "", urlFlagUsage)
+	startCmd.Flags().StringP(inputFileFlagName, "", "", inputFileFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package importdidcmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flag = "--"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetImportDIDCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestImportDIDCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing url arg", func(t *testing.T) {
+		startCmd := GetImportDIDCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither url (command line flag) nor ORB_CLI_URL (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid url arg", func(t *testing.T) {
+		startCmd := GetImportDIDCmd()
+
+		startCmd.SetArgs(endpointURL(string([]byte{0x0})))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse 'url'")
+	})
+
+	t.Run("test missing input-file arg", func(t *testing.T) {
+		startCmd := GetImportDIDCmd()
+
+		startCmd.SetArgs(endpointURL("localhost:8080"))
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither input-file (command line flag) nor ORB_CLI_INPUT_FILE (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test input file not found", func(t *testing.T) {
+		startCmd := GetImportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL("localhost:8080")...)
+		args = append(args, inputFileArg(filepath.Join(t.TempDir(), "did.car"))...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to read file")
+	})
+}
+
+func TestImportDID(t *testing.T) {
+	var contentType, content string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		content = string(body)
+
+		_, err = fmt.Fprint(w, `{"suffix":"suffix","anchors":2,"blocks":5}`)
+		require.NoError(t, err)
+	}))
+	defer serv.Close()
+
+	inputFile := filepath.Join(t.TempDir(), "did.car")
+
+	require.NoError(t, ioutil.WriteFile(inputFile, []byte("car file"), 0o600))
+
+	t.Run("test failed to send request", func(t *testing.T) {
+		os.Clearenv()
+		cmd := GetImportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL("wrongurl")...)
+		args = append(args, inputFileArg(inputFile)...)
+		cmd.SetArgs(args)
+
+		err := cmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to send http request")
+	})
+
+	t.Run("success", func(t *testing.T) {
+		cmd := GetImportDIDCmd()
+
+		var args []string
+		args = append(args, endpointURL(serv.URL+"/anchor/import")...)
+		args = append(args, inputFileArg(inputFile)...)
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, carContentType, contentType)
+		require.Equal(t, "car file", content)
+	})
+}
+
+func endpointURL(value string) []string {
+	return []string{flag + urlFlagName, value}
+}
+
+func inputFileArg(value string) []string {
+	return []string{flag + inputFileFlagName, value}
+}
@@ -10,14 +10,19 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/trustbloc/edge-core/pkg/log"
 
+	"github.com/trustbloc/orb/cmd/orb-cli/actorkeycmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/casgccmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/createdidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/deactivatedidcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/exportdidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/followcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/importdidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipfskeygencmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipnshostmetagencmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/ipnshostmetauploadcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/recoverdidcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/updatedidcmd"
+	"github.com/trustbloc/orb/cmd/orb-cli/verifyhashlinkcmd"
 	"github.com/trustbloc/orb/cmd/orb-cli/witnesscmd"
 )
 
@@ -45,6 +50,25 @@ func main() {
 		},
 	}
 
+	casCmd := &cobra.Command{
+		Use: "cas",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	actorKeyCmd := &cobra.Command{
+		Use: "actorkey",
+		Run: func(cmd *cobra.Command, args []string) {
+			cmd.HelpFunc()(cmd, args)
+		},
+	}
+
+	casCmd.AddCommand(casgccmd.GetCmd())
+	casCmd.AddCommand(verifyhashlinkcmd.GetCmd())
+
+	actorKeyCmd.AddCommand(actorkeycmd.GetCmd())
+
 	ipfsCmd.AddCommand(ipfskeygencmd.GetCmd())
 	ipfsCmd.AddCommand(ipnshostmetagencmd.GetCmd())
 	ipfsCmd.AddCommand(ipnshostmetauploadcmd.GetCmd())
@@ -53,9 +77,13 @@ func main() {
 	didCmd.AddCommand(updatedidcmd.GetUpdateDIDCmd())
 	didCmd.AddCommand(recoverdidcmd.GetRecoverDIDCmd())
 	didCmd.AddCommand(deactivatedidcmd.GetDeactivateDIDCmd())
+	didCmd.AddCommand(exportdidcmd.GetExportDIDCmd())
+	didCmd.AddCommand(importdidcmd.GetImportDIDCmd())
 
 	rootCmd.AddCommand(didCmd)
 	rootCmd.AddCommand(ipfsCmd)
+	rootCmd.AddCommand(casCmd)
+	rootCmd.AddCommand(actorKeyCmd)
 	rootCmd.AddCommand(followcmd.GetCmd())
 	rootCmd.AddCommand(witnesscmd.GetCmd())
 
@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifyhashlinkcmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
+	tlsutils "github.com/trustbloc/edge-core/pkg/utils/tls"
+
+	"github.com/trustbloc/orb/cmd/orb-cli/common"
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+const (
+	hashLinkFlagName  = "hashlink"
+	hashLinkFlagUsage = "The hashlink to verify. The content of each of the links in the hashlink metadata is" +
+		" fetched and verified against the resource hash(es) of the hashlink." +
+		" Alternatively, this can be set with the following environment variable: " + hashLinkEnvKey
+	hashLinkEnvKey = "ORB_CLI_HASHLINK"
+
+	ipfsGatewayFlagName  = "ipfs-gateway"
+	ipfsGatewayFlagUsage = "The URL of the IPFS HTTP gateway that is used to fetch the content of ipfs:// links," +
+		" e.g. https://ipfs.io. If not set then ipfs:// links can't be verified." +
+		" Alternatively, this can be set with the following environment variable: " + ipfsGatewayEnvKey
+	ipfsGatewayEnvKey = "ORB_CLI_IPFS_GATEWAY"
+
+	tlsSystemCertPoolFlagName  = "tls-systemcertpool"
+	tlsSystemCertPoolFlagUsage = "Use system certificate pool." +
+		" Possible values [true] [false]. Defaults to false if not set." +
+		" Alternatively, this can be set with the following environment variable: " + tlsSystemCertPoolEnvKey
+	tlsSystemCertPoolEnvKey = "ORB_CLI_TLS_SYSTEMCERTPOOL"
+
+	tlsCACertsFlagName  = "tls-cacerts"
+	tlsCACertsFlagUsage = "Comma-Separated list of ca certs path." +
+		" Alternatively, this can be set with the following environment variable: " + tlsCACertsEnvKey
+	tlsCACertsEnvKey = "ORB_CLI_TLS_CACERTS"
+
+	authTokenFlagName  = "auth-token"
+	authTokenFlagUsage = "Auth token." +
+		" Alternatively, this can be set with the following environment variable: " + authTokenEnvKey
+	authTokenEnvKey = "ORB_CLI_AUTH_TOKEN" //nolint:gosec
+)
+
+const (
+	httpPrefix  = "http://"
+	httpsPrefix = "https://"
+	ipfsPrefix  = "ipfs://"
+)
+
+// GetCmd returns the Cobra verify hashlink command.
+func GetCmd() *cobra.Command {
+	cmd := newCmd()
+
+	createFlags(cmd)
+
+	return cmd
+}
+
+func newCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify",
+		Short: "verify the links of a hashlink",
+		Long: "Fetches the content of each of the links (WebCAS and IPFS) in the metadata of a hashlink and " +
+			"verifies that the content matches the resource hash and any additional resource hashes of the hashlink.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rootCAs, err := getRootCAs(cmd)
+			if err != nil {
+				return err
+			}
+
+			httpClient := &http.Client{
+				Transport: &http.Transport{
+					TLSClientConfig: &tls.Config{
+						RootCAs:    rootCAs,
+						MinVersion: tls.VersionTLS12,
+					},
+				},
+			}
+
+			hl, err := cmdutils.GetUserSetVarFromString(cmd, hashLinkFlagName, hashLinkEnvKey, false)
+			if err != nil {
+				return err
+			}
+
+			ipfsGateway, err := getIPFSGateway(cmd)
+			if err != nil {
+				return err
+			}
+
+			authToken := cmdutils.GetUserSetOptionalVarFromString(cmd, authTokenFlagName,
+				authTokenEnvKey)
+
+			headers := make(map[string]string)
+			if authToken != "" {
+				headers["Authorization"] = "Bearer " + authToken
+			}
+
+			f := &fetcher{httpClient: httpClient, headers: headers, ipfsGateway: ipfsGateway}
+
+			err = hashlink.New(hashlink.WithAcceptedMultihashCodes(hashlink.SupportedMultihashCodes...)).
+				Verify(hl, f.fetch)
+			if err != nil {
+				return err
+			}
+
+			fmt.Printf("The content of all links of hashlink %s was successfully verified\n", hl)
+
+			return nil
+		},
+	}
+}
+
+type fetcher struct {
+	httpClient  *http.Client
+	headers     map[string]string
+	ipfsGateway string
+}
+
+func (f *fetcher) fetch(link string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(link, httpsPrefix) || strings.HasPrefix(link, httpPrefix):
+		return common.SendRequest(f.httpClient, nil, f.headers, http.MethodGet, link)
+	case strings.HasPrefix(link, ipfsPrefix):
+		if f.ipfsGateway == "" {
+			return nil, fmt.Errorf("an IPFS gateway is required to fetch %s (set the %s flag)", link,
+				ipfsGatewayFlagName)
+		}
+
+		return common.SendRequest(f.httpClient, nil, nil, http.MethodGet,
+			f.ipfsGateway+"/ipfs/"+strings.TrimPrefix(link, ipfsPrefix))
+	default:
+		return nil, fmt.Errorf("unsupported link: %s", link)
+	}
+}
+
+func getIPFSGateway(cmd *cobra.Command) (string, error) {
+	ipfsGateway := cmdutils.GetUserSetOptionalVarFromString(cmd, ipfsGatewayFlagName, ipfsGatewayEnvKey)
+	if ipfsGateway == "" {
+		return "", nil
+	}
+
+	if _, err := url.Parse(ipfsGateway); err != nil {
+		return "", fmt.Errorf("parse '%s' %s: %w", ipfsGatewayFlagName, ipfsGateway, err)
+	}
+
+	return strings.TrimSuffix(ipfsGateway, "/"), nil
+}
+
+func getRootCAs(cmd *cobra.Command) (*x509.CertPool, error) {
+	tlsSystemCertPoolString := cmdutils.GetUserSetOptionalVarFromString(cmd, tlsSystemCertPoolFlagName,
+		tlsSystemCertPoolEnvKey)
+
+	tlsSystemCertPool := false
+
+	if tlsSystemCertPoolString != "" {
+		var err error
+		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tlsCACerts := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, tlsCACertsFlagName,
+		tlsCACertsEnvKey)
+
+	return tlsutils.GetCertPool(tlsSystemCertPool, tlsCACerts)
+}
+
+func createFlags(startCmd *cobra.Command) {
+	startCmd.Flags().StringP(tlsSystemCertPoolFlagName, "", "", tlsSystemCertPoolFlagUsage)
+	startCmd.Flags().StringArrayP(tlsCACertsFlagName, "", []string{}, tlsCACertsFlagUsage)
+	startCmd.Flags().StringP(hashLinkFlagName, "", "", hashLinkFlagUsage)
+	startCmd.Flags().StringP(ipfsGatewayFlagName, "", "", ipfsGatewayFlagUsage)
+	startCmd.Flags().StringP(authTokenFlagName, "", "", authTokenFlagUsage)
+}
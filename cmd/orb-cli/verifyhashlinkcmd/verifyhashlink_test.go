@@ -0,0 +1,163 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package verifyhashlinkcmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/hashlink"
+)
+
+const (
+	flag = "--"
+
+	content = "content"
+	cid     = "bafkreie5kmgqekfusfbgs4qvvlg3gn6a7ds6ogj7ilv5ykyr4m7dtbbsbq"
+)
+
+func TestTLSSystemCertPoolInvalidArgsEnvVar(t *testing.T) {
+	startCmd := GetCmd()
+
+	require.NoError(t, os.Setenv(tlsSystemCertPoolEnvKey, "wrongvalue"))
+	defer os.Clearenv()
+
+	err := startCmd.Execute()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid syntax")
+}
+
+func TestVerifyHashLinkCmdWithMissingArg(t *testing.T) {
+	t.Run("test missing hashlink arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Equal(t,
+			"Neither hashlink (command line flag) nor ORB_CLI_HASHLINK (environment variable) have been set.",
+			err.Error())
+	})
+
+	t.Run("test invalid ipfs-gateway arg", func(t *testing.T) {
+		startCmd := GetCmd()
+
+		var args []string
+		args = append(args, hashLinkArg("hl:xyz")...)
+		args = append(args, ipfsGatewayArg(string([]byte{0x0}))...)
+		startCmd.SetArgs(args)
+
+		err := startCmd.Execute()
+
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse 'ipfs-gateway'")
+	})
+}
+
+func TestVerifyHashLink(t *testing.T) {
+	var authHeader string
+
+	serv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cas/valid":
+			authHeader = r.Header.Get("Authorization")
+
+			_, err := w.Write([]byte(content))
+			require.NoError(t, err)
+		case "/ipfs/" + cid:
+			_, err := w.Write([]byte(content))
+			require.NoError(t, err)
+		case "/cas/tampered":
+			_, err := w.Write([]byte("tampered"))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer serv.Close()
+
+	hl := hashlink.New(hashlink.WithAdditionalMultihashCodes(hashlink.SupportedMultihashCodes[2:]...))
+
+	t.Run("success", func(t *testing.T) {
+		hashLink, err := hl.CreateHashLink([]byte(content), []string{serv.URL + "/cas/valid", "ipfs://" + cid})
+		require.NoError(t, err)
+
+		cmd := GetCmd()
+
+		var args []string
+		args = append(args, hashLinkArg(hashLink)...)
+		args = append(args, ipfsGatewayArg(serv.URL+"/")...)
+		args = append(args, authTokenArg("ADMIN_TOKEN")...)
+		cmd.SetArgs(args)
+
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, "Bearer ADMIN_TOKEN", authHeader)
+	})
+
+	t.Run("content doesn't match", func(t *testing.T) {
+		hashLink, err := hl.CreateHashLink([]byte(content), []string{serv.URL + "/cas/tampered"})
+		require.NoError(t, err)
+
+		cmd := GetCmd()
+		cmd.SetArgs(hashLinkArg(hashLink))
+
+		err = cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "/cas/tampered: the resource hash of the content")
+	})
+
+	t.Run("content not found", func(t *testing.T) {
+		hashLink, err := hl.CreateHashLink([]byte(content), []string{serv.URL + "/cas/unknown"})
+		require.NoError(t, err)
+
+		cmd := GetCmd()
+		cmd.SetArgs(hashLinkArg(hashLink))
+
+		err = cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "status '404'")
+	})
+
+	t.Run("IPFS gateway not set", func(t *testing.T) {
+		hashLink, err := hl.CreateHashLink([]byte(content), []string{"ipfs://" + cid})
+		require.NoError(t, err)
+
+		cmd := GetCmd()
+		cmd.SetArgs(hashLinkArg(hashLink))
+
+		err = cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "an IPFS gateway is required")
+	})
+
+	t.Run("unsupported link", func(t *testing.T) {
+		hashLink, err := hl.CreateHashLink([]byte(content), []string{"ftp://example.com/content"})
+		require.NoError(t, err)
+
+		cmd := GetCmd()
+		cmd.SetArgs(hashLinkArg(hashLink))
+
+		err = cmd.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported link")
+	})
+}
+
+func hashLinkArg(value string) []string {
+	return []string{flag + hashLinkFlagName, value}
+}
+
+func ipfsGatewayArg(value string) []string {
+	return []string{flag + ipfsGatewayFlagName, value}
+}
+
+func authTokenArg(value string) []string {
+	return []string{flag + authTokenFlagName, value}
+}
@@ -14,10 +14,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/spf13/cobra"
 	cmdutils "github.com/trustbloc/edge-core/pkg/utils/cmd"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 
+	"github.com/trustbloc/orb/pkg/activitypub/httpsig"
+	"github.com/trustbloc/orb/pkg/admission"
+	"github.com/trustbloc/orb/pkg/cas/resolver"
+	"github.com/trustbloc/orb/pkg/document/updatehandler/ratelimit"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
 )
 
@@ -27,6 +33,26 @@ const (
 	defaultActivityPubPageSize          = 50
 	defaultNodeInfoRefreshInterval      = 15 * time.Second
 	defaultIPFSTimeout                  = 20 * time.Second
+	defaultAnchorSweeperInterval        = time.Minute
+	defaultAnchorSweeperAction          = "abandon"
+	defaultHashLinkAlgorithm            = "sha2-256"
+	defaultOpQueueType                  = opQueueTypeMQ
+	defaultHTTPSignatureKeyType         = kms.ED25519Type
+	defaultHTTPSignatureMaxClockSkew    = 5 * time.Minute
+	defaultAuthJWKSRefreshInterval      = 15 * time.Minute
+	defaultHTTPSignatureReplayCacheSize = 10000
+	defaultActorKeyGracePeriod          = 24 * time.Hour
+	defaultUndeliverableRetryInterval   = 10 * time.Minute
+	defaultUndeliverableReplayTimeout   = 10 * time.Minute
+	defaultAnchorSyncInterval           = time.Minute
+	defaultSTHGossipInterval            = 5 * time.Minute
+	defaultCASResolveSourceTimeout      = 0
+	defaultCASResolveHedgingDelay       = 0
+	defaultCASGCGracePeriod             = 24 * time.Hour
+	defaultAnchorGraphCacheSize         = 1000
+	defaultOpRateLimitInterval          = time.Minute
+	defaultAdmissionWindow              = 5 * time.Minute
+	defaultAnchorGraphCacheExpiration   = time.Hour
 	mqDefaultMaxConnectionSubscriptions = 1000
 
 	commonEnvVarUsageText = "Alternatively, this can be set with the following environment variable: "
@@ -110,8 +136,9 @@ const (
 	casTypeFlagShorthand = "c"
 	casTypeEnvKey        = "CAS_TYPE"
 	casTypeFlagUsage     = "The type of the Content Addressable Storage (CAS). " +
-		"Supported options: local, ipfs. For local, the storage provider specified by " + databaseTypeFlagName +
+		"Supported options: local, ipfs, s3. For local, the storage provider specified by " + databaseTypeFlagName +
 		" will be used. For ipfs, the node specified by " + ipfsURLFlagName +
+		" will be used. For s3, the S3-compatible object store specified by " + s3EndpointFlagName +
 		" will be used. This is a required parameter. " + commonEnvVarUsageText + casTypeEnvKey
 
 	ipfsURLFlagName      = "ipfs-url"
@@ -124,8 +151,9 @@ const (
 
 	localCASReplicateInIPFSFlagName  = "replicate-local-cas-writes-in-ipfs"
 	localCASReplicateInIPFSEnvKey    = "REPLICATE_LOCAL_CAS_WRITES_IN_IPFS"
-	localCASReplicateInIPFSFlagUsage = "If enabled, writes to the local CAS will also be " +
-		"replicated in IPFS. This setting only takes effect if this server has both a local CAS and IPFS enabled. " +
+	localCASReplicateInIPFSFlagUsage = "If enabled, writes to the local (or s3) CAS will also be " +
+		"replicated in IPFS. This setting only takes effect if this server has both a local (or s3) CAS and IPFS " +
+		"enabled. " +
 		"If the IPFS node is set to ipfs.io, then this setting will be disabled since ipfs.io does not support " +
 		"writes. Supported options: false, true. Defaults to false if not set. " + commonEnvVarUsageText + localCASReplicateInIPFSEnvKey
 
@@ -140,6 +168,26 @@ const (
 	mqOpPoolFlagUsage     = "The size of the operation queue subscriber pool. If 0 then a pool will not be created. " +
 		commonEnvVarUsageText + mqOpPoolEnvKey
 
+	opQueueTypeFlagName  = "op-queue-type"
+	opQueueTypeEnvKey    = "OP_QUEUE_TYPE"
+	opQueueTypeFlagUsage = "The type of the operation queue. Possible values are 'mq' (operations are queued in the " +
+		"message broker, or in memory if " + mqURLFlagName + " is not set) and 'store' (operations are persisted in the " +
+		"database so that operations which haven't been batched survive a restart). Defaults to 'mq'. " +
+		commonEnvVarUsageText + opQueueTypeEnvKey
+
+	opQueueInstanceIDFlagName  = "op-queue-instance-id"
+	opQueueInstanceIDEnvKey    = "OP_QUEUE_INSTANCE_ID"
+	opQueueInstanceIDFlagUsage = "The ID of this server instance, which is used to name the database store of the " +
+		"persistent operation queue (see " + opQueueTypeFlagName + "). Each instance in a cluster must have a unique " +
+		"ID which remains the same across restarts so that the operations queued by an instance are restored when " +
+		"that instance restarts, for example the pod name of a Kubernetes StatefulSet. (The host name is not suitable " +
+		"for a Kubernetes Deployment since a pod gets a new name when it's rescheduled, which would strand the " +
+		"operations in the queue of the previous pod.) Required if " + opQueueTypeFlagName + " is 'store'. " +
+		commonEnvVarUsageText + opQueueInstanceIDEnvKey
+
+	opQueueTypeMQ    = "mq"
+	opQueueTypeStore = "store"
+
 	mqMaxConnectionSubscriptionsFlagName      = "mq-max-connection-subscription"
 	mqMaxConnectionSubscriptionsFlagShorthand = "C"
 	mqMaxConnectionSubscriptionsEnvKey        = "MQ_MAX_CONNECTION_SUBSCRIPTIONS"
@@ -255,6 +303,36 @@ const (
 	httpSignaturesEnabledUsage     = `Set to "true" to enable HTTP signatures in ActivityPub. ` +
 		commonEnvVarUsageText + httpSignaturesEnabledEnvKey
 
+	httpSignatureKeyTypeFlagName  = "http-signature-key-type"
+	httpSignatureKeyTypeEnvKey    = "HTTP_SIGNATURE_KEY_TYPE"
+	httpSignatureKeyTypeFlagUsage = "The type of key used to sign ActivityPub HTTP requests. Possible values are " +
+		"'ED25519', 'ECDSAP256DER', 'ECDSAP256IEEEP1363', 'ECDSAP384DER', 'ECDSAP384IEEEP1363' and 'RSARS256' " +
+		"(the KMS must support RSA keys). Defaults to 'ED25519'. " +
+		commonEnvVarUsageText + httpSignatureKeyTypeEnvKey
+
+	httpSignatureMaxClockSkewFlagName  = "http-signature-max-clock-skew"
+	httpSignatureMaxClockSkewEnvKey    = "HTTP_SIGNATURE_MAX_CLOCK_SKEW"
+	httpSignatureMaxClockSkewFlagUsage = "The maximum allowed difference between the signed Date header of an " +
+		"ActivityPub request and the current time. Requests outside of this window are rejected. If 0 then the " +
+		"Date header is not checked and the replay cache must be disabled (see http-signature-replay-cache-size). " +
+		"Defaults to 5m. " +
+		commonEnvVarUsageText + httpSignatureMaxClockSkewEnvKey
+
+	httpSignatureReplayCacheSizeFlagName  = "http-signature-replay-cache-size"
+	httpSignatureReplayCacheSizeEnvKey    = "HTTP_SIGNATURE_REPLAY_CACHE_SIZE"
+	httpSignatureReplayCacheSizeFlagUsage = "The maximum number of request signatures held in the in-memory " +
+		"replay cache. Signatures are also persisted in the database (for twice the maximum clock skew) so that " +
+		"replayed requests are detected across all instances in a cluster. If 0 then replay protection is " +
+		"disabled. Must be 0 if http-signature-max-clock-skew is 0 since replayed requests can't be bounded in " +
+		"time without a Date check. Defaults to 10000. " +
+		commonEnvVarUsageText + httpSignatureReplayCacheSizeEnvKey
+
+	actorKeyGracePeriodFlagName  = "actor-key-grace-period"
+	actorKeyGracePeriodEnvKey    = "ACTOR_KEY_GRACE_PERIOD"
+	actorKeyGracePeriodFlagUsage = "The period for which the previous keys of the ActivityPub service remain " +
+		"valid after the key is rotated, which gives other services time to retrieve the updated service actor. " +
+		"For example, '12h'. Defaults to 24h. " + commonEnvVarUsageText + actorKeyGracePeriodEnvKey
+
 	enableDidDiscoveryFlagName = "enable-did-discovery"
 	enableDidDiscoveryEnvKey   = "DID_DISCOVERY_ENABLED"
 	enableDidDiscoveryUsage    = `Set to "true" to enable did discovery. ` +
@@ -282,6 +360,31 @@ const (
 	authTokensFlagUsage     = "Authorization tokens."
 	authTokensEnvKey        = "ORB_AUTH_TOKENS"
 
+	authJWKSFlagName  = "auth-jwt-jwks"
+	authJWKSEnvKey    = "ORB_AUTH_JWT_JWKS"
+	authJWKSFlagUsage = "The URL (http or https) or file path of the JSON Web Key Set used to verify JWT bearer " +
+		"tokens issued by an identity provider. If set then a bearer token that doesn't match one of the static " +
+		"auth-tokens is validated as a JWT and the scopes in the token's 'scope' (or 'scp') claim are matched " +
+		"against the token IDs (e.g. 'read' or 'admin') in auth-tokens-def. " +
+		commonEnvVarUsageText + authJWKSEnvKey
+
+	authJWTIssuerFlagName  = "auth-jwt-issuer"
+	authJWTIssuerEnvKey    = "ORB_AUTH_JWT_ISSUER"
+	authJWTIssuerFlagUsage = "The expected issuer ('iss' claim) of JWT bearer tokens. " +
+		commonEnvVarUsageText + authJWTIssuerEnvKey
+
+	authJWTAudienceFlagName  = "auth-jwt-audience"
+	authJWTAudienceEnvKey    = "ORB_AUTH_JWT_AUDIENCE"
+	authJWTAudienceFlagUsage = "The expected audience ('aud' claim) of JWT bearer tokens. Required if " +
+		authJWKSFlagName + " is set so that tokens issued for other services are rejected. " +
+		commonEnvVarUsageText + authJWTAudienceEnvKey
+
+	authJWKSRefreshIntervalFlagName  = "auth-jwt-jwks-refresh-interval"
+	authJWKSRefreshIntervalEnvKey    = "ORB_AUTH_JWT_JWKS_REFRESH_INTERVAL"
+	authJWKSRefreshIntervalFlagUsage = "The interval at which the JSON Web Key Set is reloaded. The key set is also " +
+		"reloaded when a token is signed by an unknown key. Defaults to 15m. " +
+		commonEnvVarUsageText + authJWKSRefreshIntervalEnvKey
+
 	activityPubPageSizeFlagName      = "activitypub-page-size"
 	activityPubPageSizeFlagShorthand = "P"
 	activityPubPageSizeEnvKey        = "ACTIVITYPUB_PAGE_SIZE"
@@ -305,6 +408,187 @@ const (
 	ipfsTimeoutFlagUsage     = "The timeout for IPFS requests. For example, '30s' for a 30 second timeout. " +
 		commonEnvVarUsageText + ipfsTimeoutEnvKey
 
+	anchorSweeperIntervalFlagName  = "anchor-sweeper-interval"
+	anchorSweeperIntervalEnvKey    = "ANCHOR_SWEEPER_INTERVAL"
+	anchorSweeperIntervalFlagUsage = "The interval at which in-process anchor credentials are checked for expired " +
+		"witness responses. For example, '1m' for a one minute interval. " +
+		commonEnvVarUsageText + anchorSweeperIntervalEnvKey
+
+	anchorSweeperActionFlagName  = "anchor-sweeper-action"
+	anchorSweeperActionEnvKey    = "ANCHOR_SWEEPER_ACTION"
+	anchorSweeperActionFlagUsage = "The action taken for an anchor credential whose witnesses did not respond within " +
+		"the maximum witness delay. Possible values are 'rewitness' (offer the anchor credential to the alternate " +
+		"witnesses) and 'abandon' (add the operations back to the operation queue). Defaults to 'abandon'. " +
+		commonEnvVarUsageText + anchorSweeperActionEnvKey
+
+	anchorSweeperAlternateWitnessesFlagName  = "anchor-sweeper-alternate-witnesses"
+	anchorSweeperAlternateWitnessesEnvKey    = "ANCHOR_SWEEPER_ALTERNATE_WITNESSES"
+	anchorSweeperAlternateWitnessesFlagUsage = "The service IRIs of the witnesses to which an expired anchor " +
+		"credential is offered when the sweeper action is 'rewitness'. " +
+		commonEnvVarUsageText + anchorSweeperAlternateWitnessesEnvKey
+
+	undeliverableRetryIntervalFlagName  = "undeliverable-retry-interval"
+	undeliverableRetryIntervalEnvKey    = "UNDELIVERABLE_RETRY_INTERVAL"
+	undeliverableRetryIntervalFlagUsage = "The interval at which activities that could not be delivered are retried " +
+		"for hosts that have come back online. For example, '10m' for a 10 minute interval. Defaults to 10m. " +
+		commonEnvVarUsageText + undeliverableRetryIntervalEnvKey
+
+	undeliverableReplayTimeoutFlagName  = "undeliverable-replay-timeout"
+	undeliverableReplayTimeoutEnvKey    = "UNDELIVERABLE_REPLAY_TIMEOUT"
+	undeliverableReplayTimeoutFlagUsage = "The time after which a replayed undeliverable activity that has not " +
+		"failed again is assumed to have been delivered and is removed. For example, '10m' for 10 minutes. " +
+		"Defaults to 10m. " + commonEnvVarUsageText + undeliverableReplayTimeoutEnvKey
+
+	anchorSyncIntervalFlagName  = "anchor-sync-interval"
+	anchorSyncIntervalEnvKey    = "ANCHOR_SYNC_INTERVAL"
+	anchorSyncIntervalFlagUsage = "The interval at which the outboxes of followed services are checked for anchors " +
+		"that were missed (for example, while this server was offline). For example, '1m' for a one minute " +
+		"interval. Defaults to 1m. " + commonEnvVarUsageText + anchorSyncIntervalEnvKey
+
+	sthGossipIntervalFlagName  = "sth-gossip-interval"
+	sthGossipIntervalEnvKey    = "STH_GOSSIP_INTERVAL"
+	sthGossipIntervalFlagUsage = "The interval at which the latest signed tree heads of the monitored VCT logs " +
+		"are announced to followers. For example, '5m' for a five minute interval. Defaults to 5m. " +
+		commonEnvVarUsageText + sthGossipIntervalEnvKey
+
+	casResolveSourcesFlagName  = "cas-resolve-sources"
+	casResolveSourcesEnvKey    = "CAS_RESOLVE_SOURCES"
+	casResolveSourcesFlagUsage = "The sources from which CAS data is retrieved, in the order in which they are tried. " +
+		"Supported sources are 'local', 'webcas', 'ipfs' and 'domain'. Defaults to local,webcas,ipfs,domain. " +
+		commonEnvVarUsageText + casResolveSourcesEnvKey
+
+	casResolveSourceTimeoutFlagName  = "cas-resolve-source-timeout"
+	casResolveSourceTimeoutEnvKey    = "CAS_RESOLVE_SOURCE_TIMEOUT"
+	casResolveSourceTimeoutFlagUsage = "The maximum time to wait for a single (remote) CAS source to return data " +
+		"before moving on to the next source. For example, '5s' for five seconds. Defaults to 0 (no timeout). " +
+		commonEnvVarUsageText + casResolveSourceTimeoutEnvKey
+
+	casResolveHedgingDelayFlagName  = "cas-resolve-hedging-delay"
+	casResolveHedgingDelayEnvKey    = "CAS_RESOLVE_HEDGING_DELAY"
+	casResolveHedgingDelayFlagUsage = "If set, a request is sent to the next CAS source when the previous source " +
+		"has not responded within the given delay, and the first valid response is used. For example, '500ms'. " +
+		"Defaults to 0 (hedged requests are disabled). " + commonEnvVarUsageText + casResolveHedgingDelayEnvKey
+
+	hashLinkAlgorithmsFlagName  = "hashlink-hash-algorithms"
+	hashLinkAlgorithmsEnvKey    = "HASHLINK_HASH_ALGORITHMS"
+	hashLinkAlgorithmsFlagUsage = "The hash algorithms used for the hashlinks of CAS content. The first algorithm is " +
+		"used for the resource hash (i.e. the CAS address) and any additional algorithms are used for additional " +
+		"resource hashes in the hashlink metadata, so that the hash algorithm may be migrated without breaking " +
+		"existing hashlinks. Supported algorithms are sha2-256, sha2-512, sha3-256, sha3-384, sha3-512, " +
+		"blake2b-256 and blake2b-512. Defaults to sha2-256. " + commonEnvVarUsageText + hashLinkAlgorithmsEnvKey
+
+	casGCGracePeriodFlagName  = "cas-gc-grace-period"
+	casGCGracePeriodEnvKey    = "CAS_GC_GRACE_PERIOD"
+	casGCGracePeriodFlagUsage = "The minimum age of unreachable content in the local CAS before it may be deleted " +
+		"by the garbage collector. This protects content that was written but not yet anchored. " +
+		"Defaults to 24h. " + commonEnvVarUsageText + casGCGracePeriodEnvKey
+
+	s3EndpointFlagName  = "s3-endpoint"
+	s3EndpointEnvKey    = "S3_ENDPOINT"
+	s3EndpointFlagUsage = "The URL of the S3-compatible object store (e.g. AWS S3 or MinIO) that's used when " +
+		casTypeFlagName + " is s3. For example, https://s3.us-east-1.amazonaws.com or http://localhost:9000. " +
+		"Objects are addressed using path-style URLs. " + commonEnvVarUsageText + s3EndpointEnvKey
+
+	s3RegionFlagName  = "s3-region"
+	s3RegionEnvKey    = "S3_REGION"
+	s3RegionFlagUsage = "The region of the S3 bucket. Defaults to us-east-1. " + commonEnvVarUsageText + s3RegionEnvKey
+
+	s3BucketFlagName  = "s3-bucket"
+	s3BucketEnvKey    = "S3_BUCKET"
+	s3BucketFlagUsage = "The name of the (existing) S3 bucket in which CAS content is stored. " +
+		"This is a required parameter if " + casTypeFlagName + " is s3. " + commonEnvVarUsageText + s3BucketEnvKey
+
+	s3KeyPrefixFlagName  = "s3-key-prefix"
+	s3KeyPrefixEnvKey    = "S3_KEY_PREFIX"
+	s3KeyPrefixFlagUsage = "An optional prefix for the keys of the objects in the S3 bucket. " +
+		commonEnvVarUsageText + s3KeyPrefixEnvKey
+
+	s3AccessKeyIDFlagName  = "s3-access-key-id"
+	s3AccessKeyIDEnvKey    = "S3_ACCESS_KEY_ID"
+	s3AccessKeyIDFlagUsage = "The access key ID used to sign requests to the S3-compatible object store. " +
+		commonEnvVarUsageText + s3AccessKeyIDEnvKey
+
+	s3SecretAccessKeyFlagName  = "s3-secret-access-key"
+	s3SecretAccessKeyEnvKey    = "S3_SECRET_ACCESS_KEY" //nolint:gosec
+	s3SecretAccessKeyFlagUsage = "The secret access key used to sign requests to the S3-compatible object store. " +
+		commonEnvVarUsageText + s3SecretAccessKeyEnvKey
+
+	anchorGraphCacheSizeFlagName  = "anchor-graph-cache-size"
+	anchorGraphCacheSizeEnvKey    = "ANCHOR_GRAPH_CACHE_SIZE"
+	anchorGraphCacheSizeFlagUsage = "The maximum number of parsed anchor credentials that are cached by the " +
+		"anchor graph. Defaults to 1000. " + commonEnvVarUsageText + anchorGraphCacheSizeEnvKey
+
+	anchorGraphCacheExpirationFlagName  = "anchor-graph-cache-expiration"
+	anchorGraphCacheExpirationEnvKey    = "ANCHOR_GRAPH_CACHE_EXPIRATION"
+	anchorGraphCacheExpirationFlagUsage = "The time after which a parsed anchor credential is evicted from the " +
+		"anchor graph cache. For example, '30m'. Defaults to 1h. " +
+		commonEnvVarUsageText + anchorGraphCacheExpirationEnvKey
+
+	opRateLimitPerDIDFlagName  = "operation-rate-limit-per-did"
+	opRateLimitPerDIDEnvKey    = "OPERATION_RATE_LIMIT_PER_DID"
+	opRateLimitPerDIDFlagUsage = "The maximum number of create/update operations that are accepted for a DID " +
+		"within the operation rate limit interval. If 0 then operations are not limited per DID. Defaults to 0. " +
+		commonEnvVarUsageText + opRateLimitPerDIDEnvKey
+
+	opRateLimitPerClientFlagName  = "operation-rate-limit-per-client"
+	opRateLimitPerClientEnvKey    = "OPERATION_RATE_LIMIT_PER_CLIENT"
+	opRateLimitPerClientFlagUsage = "The maximum number of create/update operations that are accepted from a " +
+		"client (identified by its bearer token or IP address) within the operation rate limit interval. " +
+		"If 0 then operations are not limited per client. Defaults to 0. " +
+		commonEnvVarUsageText + opRateLimitPerClientEnvKey
+
+	opRateLimitIntervalFlagName  = "operation-rate-limit-interval"
+	opRateLimitIntervalEnvKey    = "OPERATION_RATE_LIMIT_INTERVAL"
+	opRateLimitIntervalFlagUsage = "The window over which create/update operations are rate limited. " +
+		"For example, '30s'. Defaults to 1m. " + commonEnvVarUsageText + opRateLimitIntervalEnvKey
+
+	opRateLimitClientIPHeaderFlagName  = "operation-rate-limit-ip-header"
+	opRateLimitClientIPHeaderEnvKey    = "OPERATION_RATE_LIMIT_IP_HEADER"
+	opRateLimitClientIPHeaderFlagUsage = "The HTTP header (e.g. X-Forwarded-For) that contains the IP address of " +
+		"the client when this server is behind a proxy. If not set then the remote address of the request is used. " +
+		"This must only be set if this server is behind a trusted proxy that appends the address of its peer to " +
+		"the header since a client may otherwise set the header to an arbitrary address. " +
+		commonEnvVarUsageText + opRateLimitClientIPHeaderEnvKey
+
+	opRateLimitClientIPHeaderHopsFlagName  = "operation-rate-limit-ip-header-hops"
+	opRateLimitClientIPHeaderHopsEnvKey    = "OPERATION_RATE_LIMIT_IP_HEADER_HOPS"
+	opRateLimitClientIPHeaderHopsFlagUsage = "The position, counted from the right, of the entry in the client IP " +
+		"header that holds the IP address of the client, i.e. the number of trusted proxies in front of this " +
+		"server. Entries further to the left are ignored since they may be set by the client. Defaults to 1 " +
+		"(the rightmost entry). " + commonEnvVarUsageText + opRateLimitClientIPHeaderHopsEnvKey
+
+	admissionEnabledFlagName  = "admission-enabled"
+	admissionEnabledEnvKey    = "ADMISSION_ENABLED"
+	admissionEnabledFlagUsage = `Set to "true" to enable admission control, which throttles batch writing or ` +
+		"rejects new operations (with status 503) when witnesses are unresponsive or CAS writes are failing. " +
+		"Defaults to false. " + commonEnvVarUsageText + admissionEnabledEnvKey
+
+	admissionWindowFlagName  = "admission-window"
+	admissionWindowEnvKey    = "ADMISSION_WINDOW"
+	admissionWindowFlagUsage = "The window over which the witness response rate and CAS error rate are " +
+		"calculated by admission control. For example, '10m'. Defaults to 5m. " +
+		commonEnvVarUsageText + admissionWindowEnvKey
+
+	admissionWitnessThrottleRateFlagName  = "admission-witness-throttle-rate"
+	admissionWitnessThrottleRateEnvKey    = "ADMISSION_WITNESS_THROTTLE_RATE"
+	admissionWitnessThrottleRateFlagUsage = "The witness response rate (0-1) below which batch writing is " +
+		"throttled. Defaults to 0.5. " + commonEnvVarUsageText + admissionWitnessThrottleRateEnvKey
+
+	admissionWitnessRejectRateFlagName  = "admission-witness-reject-rate"
+	admissionWitnessRejectRateEnvKey    = "ADMISSION_WITNESS_REJECT_RATE"
+	admissionWitnessRejectRateFlagUsage = "The witness response rate (0-1) below which new operations are " +
+		"rejected. Defaults to 0.2. " + commonEnvVarUsageText + admissionWitnessRejectRateEnvKey
+
+	admissionCASThrottleErrorRateFlagName  = "admission-cas-throttle-error-rate"
+	admissionCASThrottleErrorRateEnvKey    = "ADMISSION_CAS_THROTTLE_ERROR_RATE"
+	admissionCASThrottleErrorRateFlagUsage = "The CAS write error rate (0-1) above which batch writing is " +
+		"throttled. Defaults to 0.2. " + commonEnvVarUsageText + admissionCASThrottleErrorRateEnvKey
+
+	admissionCASRejectErrorRateFlagName  = "admission-cas-reject-error-rate"
+	admissionCASRejectErrorRateEnvKey    = "ADMISSION_CAS_REJECT_ERROR_RATE"
+	admissionCASRejectErrorRateFlagUsage = "The CAS write error rate (0-1) above which new operations are " +
+		"rejected. Defaults to 0.5. " + commonEnvVarUsageText + admissionCASRejectErrorRateEnvKey
+
 	// TODO: Add verification method
 
 )
@@ -344,17 +628,41 @@ type orbParameters struct {
 	syncTimeout                    uint64
 	signWithLocalWitness           bool
 	httpSignaturesEnabled          bool
+	httpSignatureKeyType           kms.KeyType
+	httpSignatureMaxClockSkew      time.Duration
+	httpSignatureReplayCacheSize   int
+	actorKeyGracePeriod            time.Duration
 	didDiscoveryEnabled            bool
 	createDocumentStoreEnabled     bool
 	updateDocumentStoreEnabled     bool
 	updateDocumentStoreTypes       []operation.Type
 	authTokenDefinitions           []*auth.TokenDef
 	authTokens                     map[string]string
+	authJWT                        auth.JWTConfig
 	opQueuePoolSize                uint
+	opQueueType                    string
+	opQueueInstanceID              string
 	activityPubPageSize            int
 	enableDevMode                  bool
 	nodeInfoRefreshInterval        time.Duration
 	ipfsTimeout                    time.Duration
+	anchorSweeperInterval          time.Duration
+	anchorSweeperAction            string
+	anchorSweeperWitnesses         []*url.URL
+	undeliverableRetryInterval     time.Duration
+	undeliverableReplayTimeout     time.Duration
+	anchorSyncInterval             time.Duration
+	sthGossipInterval              time.Duration
+	casResolveSources              []string
+	casResolveSourceTimeout        time.Duration
+	casResolveHedgingDelay         time.Duration
+	hashLinkMultihashCodes         []uint
+	casGCGracePeriod               time.Duration
+	s3Parameters                   *s3Parameters
+	anchorGraphCacheSize           int
+	anchorGraphCacheExpiration     time.Duration
+	opRateLimit                    ratelimit.Config
+	admissionControl               *admission.Config
 }
 
 type anchorCredentialParams struct {
@@ -365,6 +673,15 @@ type anchorCredentialParams struct {
 	url                string
 }
 
+type s3Parameters struct {
+	endpoint        string
+	region          string
+	bucket          string
+	keyPrefix       string
+	accessKeyID     string
+	secretAccessKey string
+}
+
 type dbParameters struct {
 	databaseType             string
 	databaseURL              string
@@ -658,6 +975,11 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		return nil, fmt.Errorf("authorization tokens: %w", err)
 	}
 
+	authJWT, err := getAuthJWTConfig(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("JWT authorization: %w", err)
+	}
+
 	activityPubPageSize, err := getActivityPubPageSize(cmd)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", activityPubPageSizeFlagName, err)
@@ -673,6 +995,142 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		return nil, fmt.Errorf("%s: %w", ipfsTimeoutFlagName, err)
 	}
 
+	anchorSweeperInterval, err := getAnchorSweeperInterval(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorSweeperIntervalFlagName, err)
+	}
+
+	anchorSweeperAction, err := getAnchorSweeperAction(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorSweeperActionFlagName, err)
+	}
+
+	opQueueType, err := getOpQueueType(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", opQueueTypeFlagName, err)
+	}
+
+	var opQueueInstanceID string
+
+	if opQueueType == opQueueTypeStore {
+		opQueueInstanceID, err = getOpQueueInstanceID(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opQueueInstanceIDFlagName, err)
+		}
+	}
+
+	httpSignatureKeyType, err := getHTTPSignatureKeyType(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", httpSignatureKeyTypeFlagName, err)
+	}
+
+	httpSignatureMaxClockSkew, err := getDuration(cmd, httpSignatureMaxClockSkewFlagName,
+		httpSignatureMaxClockSkewEnvKey, defaultHTTPSignatureMaxClockSkew)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", httpSignatureMaxClockSkewFlagName, err)
+	}
+
+	httpSignatureReplayCacheSize, err := getNonNegativeInt(cmd, httpSignatureReplayCacheSizeFlagName,
+		httpSignatureReplayCacheSizeEnvKey, defaultHTTPSignatureReplayCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", httpSignatureReplayCacheSizeFlagName, err)
+	}
+
+	if httpSignaturesEnabled && httpSignatureReplayCacheSize > 0 && httpSignatureMaxClockSkew == 0 {
+		return nil, fmt.Errorf("%s must be 0 when %s is 0",
+			httpSignatureReplayCacheSizeFlagName, httpSignatureMaxClockSkewFlagName)
+	}
+
+	actorKeyGracePeriod, err := getDuration(cmd, actorKeyGracePeriodFlagName,
+		actorKeyGracePeriodEnvKey, defaultActorKeyGracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", actorKeyGracePeriodFlagName, err)
+	}
+
+	anchorSweeperAlternateWitnesses, err := getAnchorSweeperAlternateWitnesses(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorSweeperAlternateWitnessesFlagName, err)
+	}
+
+	undeliverableRetryInterval, err := getDuration(cmd, undeliverableRetryIntervalFlagName,
+		undeliverableRetryIntervalEnvKey, defaultUndeliverableRetryInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", undeliverableRetryIntervalFlagName, err)
+	}
+
+	undeliverableReplayTimeout, err := getDuration(cmd, undeliverableReplayTimeoutFlagName,
+		undeliverableReplayTimeoutEnvKey, defaultUndeliverableReplayTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", undeliverableReplayTimeoutFlagName, err)
+	}
+
+	anchorSyncInterval, err := getDuration(cmd, anchorSyncIntervalFlagName,
+		anchorSyncIntervalEnvKey, defaultAnchorSyncInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorSyncIntervalFlagName, err)
+	}
+
+	sthGossipInterval, err := getDuration(cmd, sthGossipIntervalFlagName,
+		sthGossipIntervalEnvKey, defaultSTHGossipInterval)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", sthGossipIntervalFlagName, err)
+	}
+
+	casResolveSources, err := getCASResolveSources(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casResolveSourcesFlagName, err)
+	}
+
+	casResolveSourceTimeout, err := getDuration(cmd, casResolveSourceTimeoutFlagName,
+		casResolveSourceTimeoutEnvKey, defaultCASResolveSourceTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casResolveSourceTimeoutFlagName, err)
+	}
+
+	casResolveHedgingDelay, err := getDuration(cmd, casResolveHedgingDelayFlagName,
+		casResolveHedgingDelayEnvKey, defaultCASResolveHedgingDelay)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casResolveHedgingDelayFlagName, err)
+	}
+
+	hashLinkMultihashCodes, err := getHashLinkMultihashCodes(cmd)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", hashLinkAlgorithmsFlagName, err)
+	}
+
+	casGCGracePeriod, err := getDuration(cmd, casGCGracePeriodFlagName,
+		casGCGracePeriodEnvKey, defaultCASGCGracePeriod)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", casGCGracePeriodFlagName, err)
+	}
+
+	s3Params, err := getS3Parameters(cmd, strings.EqualFold(casType, "s3"))
+	if err != nil {
+		return nil, err
+	}
+
+	anchorGraphCacheSize, err := getPositiveInt(cmd, anchorGraphCacheSizeFlagName,
+		anchorGraphCacheSizeEnvKey, defaultAnchorGraphCacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorGraphCacheSizeFlagName, err)
+	}
+
+	anchorGraphCacheExpiration, err := getDuration(cmd, anchorGraphCacheExpirationFlagName,
+		anchorGraphCacheExpirationEnvKey, defaultAnchorGraphCacheExpiration)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", anchorGraphCacheExpirationFlagName, err)
+	}
+
+	opRateLimit, err := getOpRateLimitConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	admissionControl, err := getAdmissionConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	return &orbParameters{
 		hostURL:                        hostURL,
 		hostMetricsURL:                 hostMetricsURL,
@@ -696,6 +1154,12 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		mqURL:                          mqURL,
 		mqMaxConnectionSubscriptions:   mqMaxSubscriptionsPerConnection,
 		opQueuePoolSize:                uint(mqOpPoolSize),
+		opQueueType:                    opQueueType,
+		opQueueInstanceID:              opQueueInstanceID,
+		httpSignatureKeyType:           httpSignatureKeyType,
+		httpSignatureMaxClockSkew:      httpSignatureMaxClockSkew,
+		httpSignatureReplayCacheSize:   httpSignatureReplayCacheSize,
+		actorKeyGracePeriod:            actorKeyGracePeriod,
 		batchWriterTimeout:             batchWriterTimeout,
 		anchorCredentialParams:         anchorCredentialParams,
 		logLevel:                       loggingLevel,
@@ -712,10 +1176,28 @@ func getOrbParameters(cmd *cobra.Command) (*orbParameters, error) {
 		updateDocumentStoreEnabled:     updateDocumentStoreEnabled,
 		authTokenDefinitions:           authTokenDefs,
 		authTokens:                     authTokens,
+		authJWT:                        authJWT,
 		activityPubPageSize:            activityPubPageSize,
 		enableDevMode:                  enableDevMode,
 		nodeInfoRefreshInterval:        nodeInfoRefreshInterval,
 		ipfsTimeout:                    ipfsTimeout,
+		anchorSweeperInterval:          anchorSweeperInterval,
+		anchorSweeperAction:            anchorSweeperAction,
+		anchorSweeperWitnesses:         anchorSweeperAlternateWitnesses,
+		undeliverableRetryInterval:     undeliverableRetryInterval,
+		undeliverableReplayTimeout:     undeliverableReplayTimeout,
+		anchorSyncInterval:             anchorSyncInterval,
+		sthGossipInterval:              sthGossipInterval,
+		casResolveSources:              casResolveSources,
+		casResolveSourceTimeout:        casResolveSourceTimeout,
+		casResolveHedgingDelay:         casResolveHedgingDelay,
+		hashLinkMultihashCodes:         hashLinkMultihashCodes,
+		casGCGracePeriod:               casGCGracePeriod,
+		s3Parameters:                   s3Params,
+		anchorGraphCacheSize:           anchorGraphCacheSize,
+		anchorGraphCacheExpiration:     anchorGraphCacheExpiration,
+		opRateLimit:                    opRateLimit,
+		admissionControl:               admissionControl,
 	}, nil
 }
 
@@ -750,6 +1232,33 @@ func getAnchorCredentialParameters(cmd *cobra.Command) (*anchorCredentialParams,
 	}, nil
 }
 
+func getS3Parameters(cmd *cobra.Command, required bool) (*s3Parameters, error) {
+	endpoint, err := cmdutils.GetUserSetVarFromString(cmd, s3EndpointFlagName, s3EndpointEnvKey, !required)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, err := cmdutils.GetUserSetVarFromString(cmd, s3BucketFlagName, s3BucketEnvKey, !required)
+	if err != nil {
+		return nil, err
+	}
+
+	region := cmdutils.GetUserSetOptionalVarFromString(cmd, s3RegionFlagName, s3RegionEnvKey)
+	keyPrefix := cmdutils.GetUserSetOptionalVarFromString(cmd, s3KeyPrefixFlagName, s3KeyPrefixEnvKey)
+	accessKeyID := cmdutils.GetUserSetOptionalVarFromString(cmd, s3AccessKeyIDFlagName, s3AccessKeyIDEnvKey)
+	secretAccessKey := cmdutils.GetUserSetOptionalVarFromString(cmd, s3SecretAccessKeyFlagName,
+		s3SecretAccessKeyEnvKey)
+
+	return &s3Parameters{
+		endpoint:        endpoint,
+		region:          region,
+		bucket:          bucket,
+		keyPrefix:       keyPrefix,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+	}, nil
+}
+
 func getDBParameters(cmd *cobra.Command, kmOptional bool) (*dbParameters, error) {
 	databaseType, err := cmdutils.GetUserSetVarFromString(cmd, databaseTypeFlagName,
 		databaseTypeEnvKey, false)
@@ -874,6 +1383,37 @@ func getAuthTokens(cmd *cobra.Command) (map[string]string, error) {
 	return authTokens, nil
 }
 
+func getAuthJWTConfig(cmd *cobra.Command) (auth.JWTConfig, error) {
+	jwks := cmdutils.GetUserSetOptionalVarFromString(cmd, authJWKSFlagName, authJWKSEnvKey)
+	issuer := cmdutils.GetUserSetOptionalVarFromString(cmd, authJWTIssuerFlagName, authJWTIssuerEnvKey)
+	audience := cmdutils.GetUserSetOptionalVarFromString(cmd, authJWTAudienceFlagName, authJWTAudienceEnvKey)
+
+	refreshInterval, err := getDuration(cmd, authJWKSRefreshIntervalFlagName, authJWKSRefreshIntervalEnvKey,
+		defaultAuthJWKSRefreshInterval)
+	if err != nil {
+		return auth.JWTConfig{}, fmt.Errorf("%s: %w", authJWKSRefreshIntervalFlagName, err)
+	}
+
+	if jwks == "" && (issuer != "" || audience != "") {
+		return auth.JWTConfig{}, fmt.Errorf("%s must be set when %s or %s is set",
+			authJWKSFlagName, authJWTIssuerFlagName, authJWTAudienceFlagName)
+	}
+
+	// Without an audience, any token signed by the identity provider (e.g. a token that was issued for another
+	// service) would be accepted.
+	if jwks != "" && audience == "" {
+		return auth.JWTConfig{}, fmt.Errorf("%s must be set when %s is set",
+			authJWTAudienceFlagName, authJWKSFlagName)
+	}
+
+	return auth.JWTConfig{
+		JWKS:            jwks,
+		Issuer:          issuer,
+		Audience:        audience,
+		RefreshInterval: refreshInterval,
+	}, nil
+}
+
 func getActivityPubPageSize(cmd *cobra.Command) (int, error) {
 	activityPubPageSizeStr, err := cmdutils.GetUserSetVarFromString(cmd, activityPubPageSizeFlagName, activityPubPageSizeEnvKey, true)
 	if err != nil {
@@ -933,6 +1473,309 @@ func getIPFSTimeout(cmd *cobra.Command) (time.Duration, error) {
 	return ipfsTimeout, nil
 }
 
+func getAnchorSweeperInterval(cmd *cobra.Command) (time.Duration, error) {
+	intervalStr, err := cmdutils.GetUserSetVarFromString(cmd, anchorSweeperIntervalFlagName,
+		anchorSweeperIntervalEnvKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if intervalStr == "" {
+		return defaultAnchorSweeperInterval, nil
+	}
+
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]: %w", intervalStr, err)
+	}
+
+	return interval, nil
+}
+
+func getAnchorSweeperAction(cmd *cobra.Command) (string, error) {
+	action, err := cmdutils.GetUserSetVarFromString(cmd, anchorSweeperActionFlagName, anchorSweeperActionEnvKey, true)
+	if err != nil {
+		return "", err
+	}
+
+	switch action {
+	case "":
+		return defaultAnchorSweeperAction, nil
+	case "rewitness", "abandon":
+		return action, nil
+	default:
+		return "", fmt.Errorf("invalid value [%s]", action)
+	}
+}
+
+func getOpQueueType(cmd *cobra.Command) (string, error) {
+	queueType, err := cmdutils.GetUserSetVarFromString(cmd, opQueueTypeFlagName, opQueueTypeEnvKey, true)
+	if err != nil {
+		return "", err
+	}
+
+	switch queueType {
+	case "":
+		return defaultOpQueueType, nil
+	case opQueueTypeMQ, opQueueTypeStore:
+		return queueType, nil
+	default:
+		return "", fmt.Errorf("invalid value [%s]", queueType)
+	}
+}
+
+func getOpQueueInstanceID(cmd *cobra.Command) (string, error) {
+	return cmdutils.GetUserSetVarFromString(cmd, opQueueInstanceIDFlagName, opQueueInstanceIDEnvKey, false)
+}
+
+func getHTTPSignatureKeyType(cmd *cobra.Command) (kms.KeyType, error) {
+	keyType, err := cmdutils.GetUserSetVarFromString(cmd, httpSignatureKeyTypeFlagName, httpSignatureKeyTypeEnvKey, true)
+	if err != nil {
+		return "", err
+	}
+
+	if keyType == "" {
+		return defaultHTTPSignatureKeyType, nil
+	}
+
+	for _, supported := range httpsig.SupportedKeyTypes() {
+		if kms.KeyType(keyType) == supported {
+			return supported, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid value [%s]", keyType)
+}
+
+func getDuration(cmd *cobra.Command, flagName, envKey string, defaultDuration time.Duration) (time.Duration, error) {
+	durationStr, err := cmdutils.GetUserSetVarFromString(cmd, flagName, envKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if durationStr == "" {
+		return defaultDuration, nil
+	}
+
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]: %w", durationStr, err)
+	}
+
+	return duration, nil
+}
+
+func getPositiveInt(cmd *cobra.Command, flagName, envKey string, defaultValue int) (int, error) {
+	valueStr, err := cmdutils.GetUserSetVarFromString(cmd, flagName, envKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]: %w", valueStr, err)
+	}
+
+	if value <= 0 {
+		return 0, errors.New("value must be greater than 0")
+	}
+
+	return value, nil
+}
+
+func getNonNegativeInt(cmd *cobra.Command, flagName, envKey string, defaultValue int) (int, error) {
+	valueStr, err := cmdutils.GetUserSetVarFromString(cmd, flagName, envKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]: %w", valueStr, err)
+	}
+
+	if value < 0 {
+		return 0, errors.New("value must not be negative")
+	}
+
+	return value, nil
+}
+
+func getOpRateLimitConfig(cmd *cobra.Command) (ratelimit.Config, error) {
+	perDID, err := getNonNegativeInt(cmd, opRateLimitPerDIDFlagName, opRateLimitPerDIDEnvKey, 0)
+	if err != nil {
+		return ratelimit.Config{}, fmt.Errorf("%s: %w", opRateLimitPerDIDFlagName, err)
+	}
+
+	perClient, err := getNonNegativeInt(cmd, opRateLimitPerClientFlagName, opRateLimitPerClientEnvKey, 0)
+	if err != nil {
+		return ratelimit.Config{}, fmt.Errorf("%s: %w", opRateLimitPerClientFlagName, err)
+	}
+
+	interval, err := getDuration(cmd, opRateLimitIntervalFlagName, opRateLimitIntervalEnvKey,
+		defaultOpRateLimitInterval)
+	if err != nil {
+		return ratelimit.Config{}, fmt.Errorf("%s: %w", opRateLimitIntervalFlagName, err)
+	}
+
+	clientIPHeader := cmdutils.GetUserSetOptionalVarFromString(cmd, opRateLimitClientIPHeaderFlagName,
+		opRateLimitClientIPHeaderEnvKey)
+
+	clientIPHeaderHops, err := getPositiveInt(cmd, opRateLimitClientIPHeaderHopsFlagName,
+		opRateLimitClientIPHeaderHopsEnvKey, 1)
+	if err != nil {
+		return ratelimit.Config{}, fmt.Errorf("%s: %w", opRateLimitClientIPHeaderHopsFlagName, err)
+	}
+
+	return ratelimit.Config{
+		MaxOperationsPerDID:    perDID,
+		MaxOperationsPerClient: perClient,
+		Interval:               interval,
+		ClientIPHeader:         clientIPHeader,
+		ClientIPHeaderHops:     clientIPHeaderHops,
+	}, nil
+}
+
+func getRate(cmd *cobra.Command, flagName, envKey string) (float64, error) {
+	valueStr, err := cmdutils.GetUserSetVarFromString(cmd, flagName, envKey, true)
+	if err != nil {
+		return 0, err
+	}
+
+	if valueStr == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value [%s]: %w", valueStr, err)
+	}
+
+	if value < 0 || value > 1 {
+		return 0, errors.New("value must be between 0 and 1")
+	}
+
+	return value, nil
+}
+
+// getAdmissionConfig returns the admission control configuration or nil if admission control is disabled.
+// Rates that aren't specified are left as zero so that the admission controller defaults are used.
+func getAdmissionConfig(cmd *cobra.Command) (*admission.Config, error) {
+	enabledStr := cmdutils.GetUserSetOptionalVarFromString(cmd, admissionEnabledFlagName, admissionEnabledEnvKey)
+	if enabledStr == "" {
+		return nil, nil
+	}
+
+	enabled, err := strconv.ParseBool(enabledStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", admissionEnabledFlagName, err)
+	}
+
+	if !enabled {
+		return nil, nil
+	}
+
+	window, err := getDuration(cmd, admissionWindowFlagName, admissionWindowEnvKey, defaultAdmissionWindow)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", admissionWindowFlagName, err)
+	}
+
+	cfg := &admission.Config{Window: window}
+
+	rates := []struct {
+		flagName string
+		envKey   string
+		value    *float64
+	}{
+		{admissionWitnessThrottleRateFlagName, admissionWitnessThrottleRateEnvKey, &cfg.WitnessThrottleRate},
+		{admissionWitnessRejectRateFlagName, admissionWitnessRejectRateEnvKey, &cfg.WitnessRejectRate},
+		{admissionCASThrottleErrorRateFlagName, admissionCASThrottleErrorRateEnvKey, &cfg.CASThrottleErrorRate},
+		{admissionCASRejectErrorRateFlagName, admissionCASRejectErrorRateEnvKey, &cfg.CASRejectErrorRate},
+	}
+
+	for _, r := range rates {
+		*r.value, err = getRate(cmd, r.flagName, r.envKey)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.flagName, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+func getAnchorSweeperAlternateWitnesses(cmd *cobra.Command) ([]*url.URL, error) {
+	witnessStrs := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, anchorSweeperAlternateWitnessesFlagName,
+		anchorSweeperAlternateWitnessesEnvKey)
+
+	var witnesses []*url.URL
+
+	for _, w := range witnessStrs {
+		u, err := url.Parse(w)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value [%s]: %w", w, err)
+		}
+
+		witnesses = append(witnesses, u)
+	}
+
+	return witnesses, nil
+}
+
+func getCASResolveSources(cmd *cobra.Command) ([]string, error) {
+	sources := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, casResolveSourcesFlagName, casResolveSourcesEnvKey)
+	if len(sources) == 0 {
+		return resolver.DefaultSources, nil
+	}
+
+	for _, source := range sources {
+		switch source {
+		case resolver.SourceLocal, resolver.SourceWebCAS, resolver.SourceIPFS, resolver.SourceDomain:
+		default:
+			return nil, fmt.Errorf("invalid value [%s]", source)
+		}
+	}
+
+	return sources, nil
+}
+
+// getHashLinkMultihashCodes returns the multihash codes of the configured hashlink hash algorithms. The first
+// code is the code of the resource hash and the remaining codes are the codes of the additional resource hashes.
+func getHashLinkMultihashCodes(cmd *cobra.Command) ([]uint, error) {
+	algorithms := cmdutils.GetUserSetOptionalVarFromArrayString(cmd, hashLinkAlgorithmsFlagName,
+		hashLinkAlgorithmsEnvKey)
+	if len(algorithms) == 0 {
+		algorithms = []string{defaultHashLinkAlgorithm}
+	}
+
+	codes := make([]uint, 0, len(algorithms))
+	exists := make(map[uint]bool)
+
+	for _, algorithm := range algorithms {
+		code, err := hashlink.GetMultihashCode(algorithm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value [%s]: %w", algorithm, err)
+		}
+
+		if exists[code] {
+			return nil, fmt.Errorf("duplicate value [%s]", algorithm)
+		}
+
+		exists[code] = true
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
 func getMQParameters(cmd *cobra.Command) (mqURL string, mqOpPoolSize int, mqMaxConnectionSubscriptions int, err error) {
 	mqURL, err = cmdutils.GetUserSetVarFromString(cmd, mqURLFlagName, mqURLEnvKey, true)
 	if err != nil {
@@ -997,6 +1840,12 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(localCASReplicateInIPFSFlagName, "", "false", localCASReplicateInIPFSFlagUsage)
 	startCmd.Flags().StringP(mqURLFlagName, mqURLFlagShorthand, "", mqURLFlagUsage)
 	startCmd.Flags().StringP(mqOpPoolFlagName, mqOpPoolFlagShorthand, "", mqOpPoolFlagUsage)
+	startCmd.Flags().String(opQueueTypeFlagName, "", opQueueTypeFlagUsage)
+	startCmd.Flags().String(opQueueInstanceIDFlagName, "", opQueueInstanceIDFlagUsage)
+	startCmd.Flags().String(httpSignatureKeyTypeFlagName, "", httpSignatureKeyTypeFlagUsage)
+	startCmd.Flags().String(httpSignatureMaxClockSkewFlagName, "", httpSignatureMaxClockSkewFlagUsage)
+	startCmd.Flags().String(httpSignatureReplayCacheSizeFlagName, "", httpSignatureReplayCacheSizeFlagUsage)
+	startCmd.Flags().String(actorKeyGracePeriodFlagName, "", actorKeyGracePeriodFlagUsage)
 	startCmd.Flags().StringP(mqMaxConnectionSubscriptionsFlagName, mqMaxConnectionSubscriptionsFlagShorthand, "", mqMaxConnectionSubscriptionsFlagUsage)
 	startCmd.Flags().String(cidVersionFlagName, "1", cidVersionFlagUsage)
 	startCmd.Flags().StringP(didNamespaceFlagName, didNamespaceFlagShorthand, "", didNamespaceFlagUsage)
@@ -1020,8 +1869,43 @@ func createFlags(startCmd *cobra.Command) {
 	startCmd.Flags().StringP(discoveryMinimumResolversFlagName, "", "", discoveryMinimumResolversFlagUsage)
 	startCmd.Flags().StringArrayP(authTokensDefFlagName, authTokensDefFlagShorthand, nil, authTokensDefFlagUsage)
 	startCmd.Flags().StringArrayP(authTokensFlagName, authTokensFlagShorthand, nil, authTokensFlagUsage)
+	startCmd.Flags().String(authJWKSFlagName, "", authJWKSFlagUsage)
+	startCmd.Flags().String(authJWTIssuerFlagName, "", authJWTIssuerFlagUsage)
+	startCmd.Flags().String(authJWTAudienceFlagName, "", authJWTAudienceFlagUsage)
+	startCmd.Flags().String(authJWKSRefreshIntervalFlagName, "", authJWKSRefreshIntervalFlagUsage)
 	startCmd.Flags().StringP(activityPubPageSizeFlagName, activityPubPageSizeFlagShorthand, "", activityPubPageSizeFlagUsage)
 	startCmd.Flags().String(devModeEnabledFlagName, "false", devModeEnabledUsage)
 	startCmd.Flags().StringP(nodeInfoRefreshIntervalFlagName, nodeInfoRefreshIntervalFlagShorthand, "", nodeInfoRefreshIntervalFlagUsage)
 	startCmd.Flags().StringP(ipfsTimeoutFlagName, ipfsTimeoutFlagShorthand, "", ipfsTimeoutFlagUsage)
+	startCmd.Flags().String(anchorSweeperIntervalFlagName, "", anchorSweeperIntervalFlagUsage)
+	startCmd.Flags().String(anchorSweeperActionFlagName, "", anchorSweeperActionFlagUsage)
+	startCmd.Flags().StringArray(anchorSweeperAlternateWitnessesFlagName, []string{}, anchorSweeperAlternateWitnessesFlagUsage)
+	startCmd.Flags().String(undeliverableRetryIntervalFlagName, "", undeliverableRetryIntervalFlagUsage)
+	startCmd.Flags().String(undeliverableReplayTimeoutFlagName, "", undeliverableReplayTimeoutFlagUsage)
+	startCmd.Flags().String(anchorSyncIntervalFlagName, "", anchorSyncIntervalFlagUsage)
+	startCmd.Flags().String(sthGossipIntervalFlagName, "", sthGossipIntervalFlagUsage)
+	startCmd.Flags().StringArray(casResolveSourcesFlagName, []string{}, casResolveSourcesFlagUsage)
+	startCmd.Flags().String(casResolveSourceTimeoutFlagName, "", casResolveSourceTimeoutFlagUsage)
+	startCmd.Flags().String(casResolveHedgingDelayFlagName, "", casResolveHedgingDelayFlagUsage)
+	startCmd.Flags().StringArray(hashLinkAlgorithmsFlagName, []string{}, hashLinkAlgorithmsFlagUsage)
+	startCmd.Flags().String(casGCGracePeriodFlagName, "", casGCGracePeriodFlagUsage)
+	startCmd.Flags().String(s3EndpointFlagName, "", s3EndpointFlagUsage)
+	startCmd.Flags().String(s3RegionFlagName, "", s3RegionFlagUsage)
+	startCmd.Flags().String(s3BucketFlagName, "", s3BucketFlagUsage)
+	startCmd.Flags().String(s3KeyPrefixFlagName, "", s3KeyPrefixFlagUsage)
+	startCmd.Flags().String(s3AccessKeyIDFlagName, "", s3AccessKeyIDFlagUsage)
+	startCmd.Flags().String(s3SecretAccessKeyFlagName, "", s3SecretAccessKeyFlagUsage)
+	startCmd.Flags().String(anchorGraphCacheSizeFlagName, "", anchorGraphCacheSizeFlagUsage)
+	startCmd.Flags().String(anchorGraphCacheExpirationFlagName, "", anchorGraphCacheExpirationFlagUsage)
+	startCmd.Flags().String(opRateLimitPerDIDFlagName, "", opRateLimitPerDIDFlagUsage)
+	startCmd.Flags().String(opRateLimitPerClientFlagName, "", opRateLimitPerClientFlagUsage)
+	startCmd.Flags().String(opRateLimitIntervalFlagName, "", opRateLimitIntervalFlagUsage)
+	startCmd.Flags().String(opRateLimitClientIPHeaderFlagName, "", opRateLimitClientIPHeaderFlagUsage)
+	startCmd.Flags().String(opRateLimitClientIPHeaderHopsFlagName, "", opRateLimitClientIPHeaderHopsFlagUsage)
+	startCmd.Flags().String(admissionEnabledFlagName, "", admissionEnabledFlagUsage)
+	startCmd.Flags().String(admissionWindowFlagName, "", admissionWindowFlagUsage)
+	startCmd.Flags().String(admissionWitnessThrottleRateFlagName, "", admissionWitnessThrottleRateFlagUsage)
+	startCmd.Flags().String(admissionWitnessRejectRateFlagName, "", admissionWitnessRejectRateFlagUsage)
+	startCmd.Flags().String(admissionCASThrottleErrorRateFlagName, "", admissionCASThrottleErrorRateFlagUsage)
+	startCmd.Flags().String(admissionCASRejectErrorRateFlagName, "", admissionCASRejectErrorRateFlagUsage)
 }
@@ -10,10 +10,8 @@ import (
 	"context"
 	"crypto/ed25519"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
@@ -54,6 +52,7 @@ import (
 	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
 	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
 	"github.com/trustbloc/sidetree-core-go/pkg/batch"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
 	"github.com/trustbloc/sidetree-core-go/pkg/dochandler"
 	"github.com/trustbloc/sidetree-core-go/pkg/processor"
 	restcommon "github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
@@ -66,23 +65,38 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/httpsig"
 	aphandler "github.com/trustbloc/orb/pkg/activitypub/resthandler"
 	apservice "github.com/trustbloc/orb/pkg/activitypub/service"
+	"github.com/trustbloc/orb/pkg/activitypub/service/actorkeys"
+	actorkeyshandler "github.com/trustbloc/orb/pkg/activitypub/service/actorkeys/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/anchorsync"
 	"github.com/trustbloc/orb/pkg/activitypub/service/monitoring"
 	apspi "github.com/trustbloc/orb/pkg/activitypub/service/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/service/sthgossip"
+	"github.com/trustbloc/orb/pkg/activitypub/service/undeliverable"
+	undeliverablehandler "github.com/trustbloc/orb/pkg/activitypub/service/undeliverable/resthandler"
 	"github.com/trustbloc/orb/pkg/activitypub/service/vct"
 	apariesstore "github.com/trustbloc/orb/pkg/activitypub/store/ariesstore"
 	apmemstore "github.com/trustbloc/orb/pkg/activitypub/store/memstore"
 	activitypubspi "github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/admission"
+	"github.com/trustbloc/orb/pkg/anchor/archive"
+	archivehandler "github.com/trustbloc/orb/pkg/anchor/archive/resthandler"
 	"github.com/trustbloc/orb/pkg/anchor/builder"
 	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/handler/acknowledgement"
+	ackhandler "github.com/trustbloc/orb/pkg/anchor/handler/acknowledgement/resthandler"
 	"github.com/trustbloc/orb/pkg/anchor/handler/credential"
 	"github.com/trustbloc/orb/pkg/anchor/handler/proof"
 	"github.com/trustbloc/orb/pkg/anchor/policy"
 	policyhandler "github.com/trustbloc/orb/pkg/anchor/policy/resthandler"
+	"github.com/trustbloc/orb/pkg/anchor/sweeper"
 	"github.com/trustbloc/orb/pkg/anchor/writer"
 	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	casgc "github.com/trustbloc/orb/pkg/cas/gc"
+	casgchandler "github.com/trustbloc/orb/pkg/cas/gc/resthandler"
 	ipfscas "github.com/trustbloc/orb/pkg/cas/ipfs"
 	"github.com/trustbloc/orb/pkg/cas/resolver"
+	s3cas "github.com/trustbloc/orb/pkg/cas/s3"
 	"github.com/trustbloc/orb/pkg/config"
 	sidetreecontext "github.com/trustbloc/orb/pkg/context"
 	"github.com/trustbloc/orb/pkg/context/common"
@@ -94,12 +108,15 @@ import (
 	discoveryrest "github.com/trustbloc/orb/pkg/discovery/endpoint/restapi"
 	"github.com/trustbloc/orb/pkg/document/resolvehandler"
 	"github.com/trustbloc/orb/pkg/document/updatehandler"
+	"github.com/trustbloc/orb/pkg/document/updatehandler/ratelimit"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	"github.com/trustbloc/orb/pkg/httpserver"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
 	"github.com/trustbloc/orb/pkg/ldcontextrest"
 	"github.com/trustbloc/orb/pkg/metrics"
 	"github.com/trustbloc/orb/pkg/nodeinfo"
 	"github.com/trustbloc/orb/pkg/observer"
+	forkhandler "github.com/trustbloc/orb/pkg/observer/resthandler"
 	"github.com/trustbloc/orb/pkg/protocolversion/factoryregistry"
 	"github.com/trustbloc/orb/pkg/pubsub/amqp"
 	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
@@ -107,6 +124,7 @@ import (
 	"github.com/trustbloc/orb/pkg/resolver/resource"
 	"github.com/trustbloc/orb/pkg/resolver/resource/registry"
 	"github.com/trustbloc/orb/pkg/resolver/resource/registry/didanchorinfo"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
 	casstore "github.com/trustbloc/orb/pkg/store/cas"
 	didanchorstore "github.com/trustbloc/orb/pkg/store/didanchor"
 	opstore "github.com/trustbloc/orb/pkg/store/operation"
@@ -154,8 +172,14 @@ const (
 
 	webKeyStoreKey = "web-key-store"
 	kidKey         = "kid"
+	httpSigKIDKey  = "http-sig-kid"
 )
 
+type operationQueue interface {
+	cutter.OperationQueue
+	Contains(suffix string, opBuffer []byte) bool
+}
+
 type pubSub interface {
 	Subscribe(ctx context.Context, topic string) (<-chan *message.Message, error)
 	SubscribeWithOpts(ctx context.Context, topic string, opts ...spi.Option) (<-chan *message.Message, error)
@@ -270,6 +294,26 @@ func createKID(km kms.KeyManager, parameters *orbParameters, cfg storage.Store)
 	}, parameters.syncTimeout)
 }
 
+// createHTTPSignatureKID returns the ID of the key used to sign ActivityPub HTTP requests. The main key is used if
+// it's of the requested type, otherwise a separate key is created (once) for the given key type.
+func createHTTPSignatureKID(km kms.KeyManager, parameters *orbParameters, cfg storage.Store) (string, error) {
+	if parameters.httpSignatureKeyType == kmsKeyType {
+		return parameters.keyID, nil
+	}
+
+	var keyID string
+
+	// The key type is included in the config key so that a new key is created if the key type is changed.
+	err := getOrInit(cfg, fmt.Sprintf("%s-%s", httpSigKIDKey, parameters.httpSignatureKeyType), &keyID,
+		func() (interface{}, error) {
+			kid, _, e := km.Create(parameters.httpSignatureKeyType)
+
+			return kid, e
+		}, parameters.syncTimeout)
+
+	return keyID, err
+}
+
 func importPrivateKey(km kms.KeyManager, parameters *orbParameters, cfg storage.Store) error {
 	return getOrInit(cfg, kidKey, &parameters.keyID, func() (interface{}, error) {
 		keyBytes, err := base64.RawStdEncoding.DecodeString(parameters.privateKeyBase64)
@@ -322,13 +366,20 @@ func startOrbServices(parameters *orbParameters) error {
 
 	casIRI := mustParseURL(parameters.externalEndpoint, casPath)
 
+	// Hashlinks created by other servers may use any of the supported hash algorithms.
+	hl := hashlink.New(
+		hashlink.WithMultihashCode(parameters.hashLinkMultihashCodes[0]),
+		hashlink.WithAdditionalMultihashCodes(parameters.hashLinkMultihashCodes[1:]...),
+		hashlink.WithAcceptedMultihashCodes(hashlink.SupportedMultihashCodes...),
+	)
+
 	var coreCASClient extendedcasclient.Client
 
 	switch {
 	case strings.EqualFold(parameters.casType, "ipfs"):
 		logger.Infof("Initializing Orb CAS with IPFS.")
 		coreCASClient = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
-			extendedcasclient.WithCIDVersion(parameters.cidVersion))
+			hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 	case strings.EqualFold(parameters.casType, "local"):
 		logger.Infof("Initializing Orb CAS with local storage provider.")
 
@@ -339,21 +390,63 @@ func startOrbServices(parameters *orbParameters) error {
 
 			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(),
 				ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
-					extendedcasclient.WithCIDVersion(parameters.cidVersion)),
-				metrics.Get(), defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+					hl, extendedcasclient.WithCIDVersion(parameters.cidVersion)),
+				metrics.Get(), defaultCasCacheSize, hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 			if err != nil {
 				return err
 			}
 		} else {
 			coreCASClient, err = casstore.New(storeProviders.provider, casIRI.String(), nil,
-				metrics.Get(), defaultCasCacheSize, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+				metrics.Get(), defaultCasCacheSize, hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
 			if err != nil {
 				return err
 			}
 		}
 
+	case strings.EqualFold(parameters.casType, "s3"):
+		logger.Infof("Initializing Orb CAS with S3-compatible object store [%s].", parameters.s3Parameters.endpoint)
+
+		var ipfsClient *ipfscas.Client
+
+		if parameters.localCASReplicateInIPFSEnabled {
+			logger.Infof("S3 CAS writes will be replicated in IPFS.")
+
+			ipfsClient = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
+				hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		}
+
+		var err error
+
+		coreCASClient, err = s3cas.New(&s3cas.Config{
+			Endpoint:        parameters.s3Parameters.endpoint,
+			Region:          parameters.s3Parameters.region,
+			Bucket:          parameters.s3Parameters.bucket,
+			KeyPrefix:       parameters.s3Parameters.keyPrefix,
+			AccessKeyID:     parameters.s3Parameters.accessKeyID,
+			SecretAccessKey: parameters.s3Parameters.secretAccessKey,
+		}, httpClient, casIRI.String(), ipfsClient, metrics.Get(), defaultCasCacheSize,
+			hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		if err != nil {
+			return err
+		}
+
 	default:
-		return fmt.Errorf("%s is not a valid CAS type. It must be either local or ipfs", parameters.casType)
+		return fmt.Errorf("%s is not a valid CAS type. It must be either local, ipfs or s3", parameters.casType)
+	}
+
+	// The local CAS is required by the CAS garbage collector so it must be retrieved before the client is wrapped.
+	localCAS, isLocalCAS := coreCASClient.(*casstore.CAS)
+
+	var admissionController *admission.Controller
+
+	if parameters.admissionControl != nil {
+		admissionCfg := *parameters.admissionControl
+		admissionCfg.BatchTimeout = parameters.batchWriterTimeout
+		admissionCfg.WitnessResponseGracePeriod = parameters.maxWitnessDelay
+
+		admissionController = admission.New(admissionCfg, metrics.Get())
+
+		coreCASClient = admissionController.WrapCASClient(coreCASClient)
 	}
 
 	didAnchors, err := didanchorstore.New(storeProviders.provider)
@@ -361,6 +454,11 @@ func startOrbServices(parameters *orbParameters) error {
 		return err
 	}
 
+	anchorForks, err := anchorfork.New(storeProviders.provider)
+	if err != nil {
+		return err
+	}
+
 	opStore, err := opstore.New(storeProviders.provider)
 	if err != nil {
 		return err
@@ -414,10 +512,45 @@ func startOrbServices(parameters *orbParameters) error {
 		}
 	}
 
+	httpSigKeyID, err := createHTTPSignatureKID(km, parameters, configStore)
+	if err != nil {
+		return fmt.Errorf("create HTTP signature kid: %w", err)
+	}
+
+	apServiceIRI := mustParseURL(parameters.externalEndpoint, activityPubServicesPath)
+
 	apServicePublicKeyIRI := mustParseURL(parameters.externalEndpoint,
 		fmt.Sprintf("%s/keys/%s", activityPubServicesPath, aphandler.MainKeyID))
 
-	apGetSigner, apPostSigner := getActivityPubSigners(parameters, km, cr)
+	httpSigPubKey, err := km.ExportPubKeyBytes(httpSigKeyID)
+	if err != nil {
+		return fmt.Errorf("failed to export HTTP signature pub key: %w", err)
+	}
+
+	publicKey, err := getActivityPubPublicKey(parameters.httpSignatureKeyType, httpSigPubKey,
+		apServiceIRI, apServicePublicKeyIRI)
+	if err != nil {
+		return fmt.Errorf("get public key: %w", err)
+	}
+
+	var activityPubService *apservice.Service
+
+	actorKeyManager, err := actorkeys.New(
+		actorkeys.Config{
+			ServiceIRI:       apServiceIRI,
+			KeyType:          parameters.httpSignatureKeyType,
+			MainKMSKeyID:     httpSigKeyID,
+			MainPublicKeyPem: publicKey.PublicKeyPem,
+			GracePeriod:      parameters.actorKeyGracePeriod,
+		},
+		storeProviders.provider, km, cr,
+		func() actorkeys.Outbox { return activityPubService.Outbox() },
+	)
+	if err != nil {
+		return fmt.Errorf("create actor key manager: %w", err)
+	}
+
+	apGetSigner, apPostSigner := getActivityPubSigners(parameters, actorKeyManager)
 
 	t := transport.New(httpClient, apServicePublicKeyIRI, apGetSigner, apPostSigner)
 
@@ -425,14 +558,27 @@ func startOrbServices(parameters *orbParameters) error {
 
 	webCASResolver := resolver.NewWebCASResolver(t, wfClient, webFingerURIScheme)
 
+	casResolverOpts := []resolver.Option{
+		resolver.WithSources(parameters.casResolveSources...),
+		resolver.WithHedgingDelay(parameters.casResolveHedgingDelay),
+		resolver.WithHashLink(hl),
+	}
+
+	if parameters.casResolveSourceTimeout > 0 {
+		for _, source := range []string{resolver.SourceWebCAS, resolver.SourceIPFS, resolver.SourceDomain} {
+			casResolverOpts = append(casResolverOpts,
+				resolver.WithSourceTimeout(source, parameters.casResolveSourceTimeout))
+		}
+	}
+
 	var ipfsReader *ipfscas.Client
 	var casResolver *resolver.Resolver
 	if parameters.ipfsURL != "" {
 		ipfsReader = ipfscas.New(parameters.ipfsURL, parameters.ipfsTimeout, defaultCasCacheSize, metrics.Get(),
-			extendedcasclient.WithCIDVersion(parameters.cidVersion))
-		casResolver = resolver.New(coreCASClient, ipfsReader, webCASResolver, metrics.Get())
+			hl, extendedcasclient.WithCIDVersion(parameters.cidVersion))
+		casResolver = resolver.New(coreCASClient, ipfsReader, webCASResolver, metrics.Get(), casResolverOpts...)
 	} else {
-		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics.Get())
+		casResolver = resolver.New(coreCASClient, nil, webCASResolver, metrics.Get(), casResolverOpts...)
 	}
 
 	graphProviders := &graph.Providers{
@@ -442,7 +588,11 @@ func startOrbServices(parameters *orbParameters) error {
 		DocLoader:   orbDocumentLoader,
 	}
 
-	anchorGraph := graph.New(graphProviders)
+	anchorGraph := graph.New(graphProviders,
+		graph.WithCacheSize(parameters.anchorGraphCacheSize),
+		graph.WithCacheExpiration(parameters.anchorGraphCacheExpiration),
+		graph.WithMetrics(metrics.Get()),
+	)
 
 	// get protocol client provider
 	pcp, err := getProtocolClientProvider(parameters, coreCASClient, casResolver, opStore, storeProviders.provider)
@@ -493,11 +643,17 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to create vc store: %s", err.Error())
 	}
 
-	witnessProofStore, err := proofstore.New(storeProviders.provider)
+	proofStore, err := proofstore.New(storeProviders.provider)
 	if err != nil {
 		return fmt.Errorf("failed to create proof store: %s", err.Error())
 	}
 
+	var witnessProofStore admission.WitnessStore = proofStore
+
+	if admissionController != nil {
+		witnessProofStore = admissionController.WrapWitnessStore(proofStore)
+	}
+
 	vcStatusStore, err := vcstatus.New(storeProviders.provider)
 	if err != nil {
 		return fmt.Errorf("failed to create vc status store: %s", err.Error())
@@ -524,8 +680,6 @@ func startOrbServices(parameters *orbParameters) error {
 	resourceRegistry := registry.New(registry.WithResourceInfoProvider(didAnchoringInfoProvider))
 	logger.Debugf("started resource registry: %+v", resourceRegistry)
 
-	apServiceIRI := mustParseURL(parameters.externalEndpoint, activityPubServicesPath)
-
 	var pubSub pubSub
 
 	if parameters.mqURL != "" {
@@ -554,17 +708,28 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to export pub key: %w", err)
 	}
 
-	publicKey, err := getActivityPubPublicKey(pubKey, apServiceIRI, apServicePublicKeyIRI)
-	if err != nil {
-		return fmt.Errorf("get public key: %w", err)
-	}
-
 	// TODO: Pass config from startup params
 	apClient := client.New(client.Config{}, t)
 
-	apSigVerifier := getActivityPubVerifier(parameters, km, cr, apClient)
+	var replayCache *httpsig.ReplayCache
 
-	monitoringSvc, err := monitoring.New(storeProviders.provider, orbDocumentLoader, wfClient, monitoring.WithHTTPClient(httpClient))
+	if parameters.httpSignaturesEnabled && parameters.httpSignatureReplayCacheSize > 0 {
+		// A request whose Date header is outside of the clock skew window is rejected anyway, so requests only
+		// need to be remembered for twice the clock skew.
+		replayCache, err = httpsig.NewReplayCache(httpsig.ReplayCacheConfig{
+			Expiry:    2 * parameters.httpSignatureMaxClockSkew,
+			CacheSize: parameters.httpSignatureReplayCacheSize,
+		}, storeProviders.provider)
+		if err != nil {
+			return fmt.Errorf("create HTTP signature replay cache: %w", err)
+		}
+	}
+
+	apSigVerifier := getActivityPubVerifier(parameters, km, cr, apClient, replayCache)
+
+	monitoringSvc, err := monitoring.New(storeProviders.provider, orbDocumentLoader, wfClient,
+		monitoring.WithHTTPClient(httpClient), monitoring.WithMetrics(metrics.Get()),
+	)
 	if err != nil {
 		return fmt.Errorf("monitoring: %w", err)
 	}
@@ -593,6 +758,14 @@ func startOrbServices(parameters *orbParameters) error {
 		vct.WithDocumentLoader(orbDocumentLoader),
 	)
 
+	// Only the local witness used by the anchor writer is tracked by admission control since the ActivityPub
+	// service also uses the witness on behalf of other servers.
+	var anchorWitness admission.Witness = witness
+
+	if admissionController != nil {
+		anchorWitness = admissionController.WrapWitness(witness)
+	}
+
 	if parameters.vctURL != "" {
 		err = vctclient.New(parameters.vctURL, vctclient.WithHTTPClient(httpClient)).
 			AddJSONLDContexts(context.Background(), defaultContexts...)
@@ -601,8 +774,6 @@ func startOrbServices(parameters *orbParameters) error {
 		}
 	}
 
-	var activityPubService *apservice.Service
-
 	// create new observer and start it
 	providers := &observer.Providers{
 		ProtocolClientProvider: pcp,
@@ -611,6 +782,7 @@ func startOrbServices(parameters *orbParameters) error {
 		PubSub:                 pubSub,
 		Metrics:                metrics.Get(),
 		Outbox:                 func() observer.Outbox { return activityPubService.Outbox() },
+		AnchorForks:            anchorForks,
 	}
 
 	o, err := observer.New(providers, observer.WithDiscoveryDomain(parameters.discoveryDomain))
@@ -620,23 +792,73 @@ func startOrbServices(parameters *orbParameters) error {
 
 	resourceResolver := resource.New(httpClient, ipfsReader)
 
+	undeliverableSvc, err := undeliverable.New(
+		undeliverable.Config{
+			RetryInterval: parameters.undeliverableRetryInterval,
+			ReplayTimeout: parameters.undeliverableReplayTimeout,
+		},
+		storeProviders.provider,
+		func() undeliverable.Outbox { return activityPubService.Outbox() },
+		httpClient,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create undeliverable activity service: %s", err.Error())
+	}
+
+	anchorEventAckHandler, err := acknowledgement.New(apServiceIRI, storeProviders.provider, apStore)
+	if err != nil {
+		return fmt.Errorf("failed to create anchor event acknowledgement handler: %s", err.Error())
+	}
+
+	sthGossipSvc, err := sthgossip.New(
+		sthgossip.Config{
+			ServiceIRI: apServiceIRI,
+			Interval:   parameters.sthGossipInterval,
+		},
+		monitoringSvc,
+		func() sthgossip.Outbox { return activityPubService.Outbox() },
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create STH gossip service: %s", err.Error())
+	}
+
+	anchorCredHandler := credential.New(
+		o.Publisher(), casResolver, orbDocumentLoader, monitoringSvc, parameters.maxWitnessDelay,
+	)
+
 	activityPubService, err = apservice.New(apConfig,
 		apStore, t, apSigVerifier, pubSub, apClient, resourceResolver, metrics.Get(),
 		apspi.WithProofHandler(proofHandler),
 		apspi.WithWitness(witness),
-		apspi.WithAnchorCredentialHandler(credential.New(
-			o.Publisher(), casResolver, orbDocumentLoader, monitoringSvc, parameters.maxWitnessDelay,
-		)),
+		apspi.WithUndeliverableHandler(undeliverableSvc),
+		apspi.WithAnchorEventAcknowledgementHandler(anchorEventAckHandler),
+		apspi.WithAnchorCredentialHandler(anchorCredHandler),
+		apspi.WithSignedTreeHeadHandler(sthGossipSvc),
 		// TODO: Define the following ActivityPub handlers.
 		// apspi.WithWitnessInvitationAuth(inviteWitnessAuth),
 		// apspi.WithFollowerAuth(followerAuth),
-		// apspi.WithUndeliverableHandler(undeliverableHandler),
-		// apspi.WithAnchorEventAcknowledgementHandler(anchorEventHandler),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create ActivityPub service: %s", err.Error())
 	}
 
+	anchorSyncSvc, err := anchorsync.New(
+		anchorsync.Config{
+			ServiceIRI: apServiceIRI,
+			Interval:   parameters.anchorSyncInterval,
+		},
+		&anchorsync.Providers{
+			Client:                  apClient,
+			ActivityStore:           apStore,
+			AnchorCredentialHandler: anchorCredHandler,
+			Subscriber:              activityPubService,
+		},
+		storeProviders.provider,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create anchor sync service: %s", err.Error())
+	}
+
 	o.Start()
 
 	anchorWriterProviders := &writer.Providers{
@@ -647,7 +869,7 @@ func startOrbServices(parameters *orbParameters) error {
 		VCStatusStore: vcStatusStore,
 		OpProcessor:   opProcessor,
 		Outbox:        activityPubService.Outbox(),
-		Witness:       witness,
+		Witness:       anchorWitness,
 		Signer:        vcSigner,
 		MonitoringSvc: monitoringSvc,
 		ActivityStore: apStore,
@@ -667,14 +889,43 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("failed to create writer: %s", err.Error())
 	}
 
-	opQueue, err := opqueue.New(opqueue.Config{PoolSize: parameters.opQueuePoolSize}, pubSub, metrics.Get())
+	opQueue, err := createOperationQueue(parameters, pubSub, storeProviders.provider)
 	if err != nil {
 		return fmt.Errorf("failed to create operation queue: %s", err.Error())
 	}
 
+	// The batch writer reads from a throttled queue when admission control is enabled.
+	var batchOpQueue cutter.OperationQueue = opQueue
+
+	if admissionController != nil {
+		batchOpQueue = admissionController.WrapOperationQueue(opQueue)
+	}
+
+	anchorSweeper, err := sweeper.New(
+		sweeper.Config{
+			Interval:           parameters.anchorSweeperInterval,
+			MaxWitnessDelay:    parameters.maxWitnessDelay,
+			Action:             sweeper.Action(parameters.anchorSweeperAction),
+			AlternateWitnesses: parameters.anchorSweeperWitnesses,
+		},
+		&sweeper.Providers{
+			VCStore:                vcStore,
+			VCStatusStore:          vcStatusStore,
+			WitnessStore:           witnessProofStore,
+			OpQueue:                opQueue,
+			ProtocolClientProvider: pcp,
+			Outbox:                 activityPubService.Outbox(),
+			WFClient:               wfClient,
+		},
+		storeProviders.provider, metrics.Get(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create anchor sweeper: %s", err.Error())
+	}
+
 	// create new batch writer
 	batchWriter, err := batch.New(parameters.didNamespace,
-		sidetreecontext.New(pc, anchorWriter, opQueue),
+		sidetreecontext.New(pc, anchorWriter, batchOpQueue),
 		batch.WithBatchTimeout(parameters.batchWriterTimeout))
 	if err != nil {
 		return fmt.Errorf("failed to create batch writer: %s", err.Error())
@@ -684,6 +935,8 @@ func startOrbServices(parameters *orbParameters) error {
 	batchWriter.Start()
 	logger.Infof("started batch writer")
 
+	anchorSweeper.Start()
+
 	var didDocHandlerOpts []dochandler.Option
 	didDocHandlerOpts = append(didDocHandlerOpts, dochandler.WithDomain("https:"+u.Host))
 	didDocHandlerOpts = append(didDocHandlerOpts, dochandler.WithLabel(unpublishedDIDLabel))
@@ -706,6 +959,12 @@ func startOrbServices(parameters *orbParameters) error {
 		AuthTokens:    parameters.authTokens,
 	}
 
+	if parameters.authJWT.JWKS != "" {
+		logger.Infof("Bearer tokens will be validated as JWTs using JWKS [%s]", parameters.authJWT.JWKS)
+
+		authCfg.TokenValidator = auth.NewJWTValidator(parameters.authJWT, httpClient)
+	}
+
 	apEndpointCfg := &aphandler.Config{
 		Config:                 authCfg,
 		BasePath:               activityPubServicesPath,
@@ -717,6 +976,7 @@ func startOrbServices(parameters *orbParameters) error {
 	var resolveHandlerOpts []resolvehandler.Option
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithUnpublishedDIDLabel(unpublishedDIDLabel))
 	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithEnableDIDDiscovery(parameters.didDiscoveryEnabled))
+	resolveHandlerOpts = append(resolveHandlerOpts, resolvehandler.WithHashLink(hl))
 
 	var updateHandlerOpts []updatehandler.Option
 
@@ -773,16 +1033,42 @@ func startOrbServices(parameters *orbParameters) error {
 		return fmt.Errorf("ldcontext rest: %w", err)
 	}
 
-	nodeInfoService := nodeinfo.NewService(apStore, apServiceIRI, parameters.nodeInfoRefreshInterval)
+	var nodeInfoOpts []nodeinfo.Option
+
+	if admissionController != nil {
+		nodeInfoOpts = append(nodeInfoOpts,
+			nodeinfo.WithMetadata("admissionControl", func() interface{} { return admissionController.Status() }),
+		)
+	}
+
+	nodeInfoService := nodeinfo.NewService(apStore, apServiceIRI, parameters.nodeInfoRefreshInterval,
+		nodeInfoOpts...)
+
+	didArchiver := archive.New(&archive.Providers{
+		DIDAnchors:             didAnchors,
+		AnchorGraph:            anchorGraph,
+		CASResolver:            casResolver,
+		CASWriter:              coreCASClient,
+		ProtocolClientProvider: pcp,
+		Publisher:              o.Publisher(),
+	})
+
+	var updateHandler restcommon.HTTPHandler = ratelimit.NewHandlerWrapper(parameters.opRateLimit,
+		diddochandler.NewUpdateHandler(baseUpdatePath, orbDocUpdateHandler, pc),
+		orbDocUpdateHandler.Namespace(), pc, opQueue, metrics.Get())
+
+	if admissionController != nil {
+		updateHandler = admission.NewHandlerWrapper(updateHandler, admissionController)
+	}
 
 	handlers := make([]restcommon.HTTPHandler, 0)
 
 	handlers = append(handlers,
-		auth.NewHandlerWrapper(authCfg, diddochandler.NewUpdateHandler(baseUpdatePath, orbDocUpdateHandler, pc)),
+		auth.NewHandlerWrapper(authCfg, updateHandler),
 		auth.NewHandlerWrapper(authCfg, diddochandler.NewResolveHandler(baseResolvePath, orbDocResolveHandler)),
 		activityPubService.InboxHTTPHandler(),
-		aphandler.NewServices(apEndpointCfg, apStore, publicKey),
-		aphandler.NewPublicKeys(apEndpointCfg, apStore, publicKey),
+		aphandler.NewServices(apEndpointCfg, apStore, publicKey, aphandler.WithPublicKeyProvider(actorKeyManager)),
+		aphandler.NewPublicKeys(apEndpointCfg, apStore, publicKey, aphandler.WithPublicKeyProvider(actorKeyManager)),
 		aphandler.NewFollowers(apEndpointCfg, apStore, apSigVerifier),
 		aphandler.NewFollowing(apEndpointCfg, apStore, apSigVerifier),
 		aphandler.NewOutbox(apEndpointCfg, apStore, apSigVerifier),
@@ -795,12 +1081,46 @@ func startOrbServices(parameters *orbParameters) error {
 		aphandler.NewPostOutbox(apEndpointCfg, activityPubService.Outbox(), apStore, apSigVerifier),
 		aphandler.NewActivity(apEndpointCfg, apStore, apSigVerifier),
 		webcas.New(apEndpointCfg, apStore, apSigVerifier, coreCASClient),
+		webcas.NewBatch(apEndpointCfg, apStore, apSigVerifier, coreCASClient),
 		auth.NewHandlerWrapper(authCfg, policyhandler.New(configStore)),
+		auth.NewHandlerWrapper(authCfg, undeliverablehandler.NewList(undeliverableSvc)),
+		auth.NewHandlerWrapper(authCfg, undeliverablehandler.NewReplay(undeliverableSvc)),
+		auth.NewHandlerWrapper(authCfg, undeliverablehandler.NewDiscard(undeliverableSvc)),
+		auth.NewHandlerWrapper(authCfg, actorkeyshandler.NewList(actorKeyManager)),
+		auth.NewHandlerWrapper(authCfg, actorkeyshandler.NewRotate(actorKeyManager)),
+		auth.NewHandlerWrapper(authCfg, ackhandler.New(anchorEventAckHandler)),
+		auth.NewHandlerWrapper(authCfg, forkhandler.New(anchorForks)),
+		auth.NewHandlerWrapper(authCfg, archivehandler.NewExport(didArchiver)),
+		auth.NewHandlerWrapper(authCfg, archivehandler.NewImport(didArchiver)),
 		ctxRest,
 		auth.NewHandlerWrapper(authCfg, nodeinfo.NewHandler(nodeinfo.V2_0, nodeInfoService)),
 		auth.NewHandlerWrapper(authCfg, nodeinfo.NewHandler(nodeinfo.V2_1, nodeInfoService)),
 	)
 
+	if isLocalCAS {
+		// The collector only reads anchors from the local CAS.
+		gcAnchorGraph := graph.New(&graph.Providers{
+			CasResolver: resolver.New(coreCASClient, nil, webCASResolver, metrics.Get(),
+				resolver.WithSources(resolver.SourceLocal), resolver.WithHashLink(hl)),
+			CasWriter: coreCASClient,
+			Pkf:       graphProviders.Pkf,
+			DocLoader: graphProviders.DocLoader,
+		})
+
+		casCollector := casgc.New(&casgc.Providers{
+			DIDAnchors:             didAnchors,
+			Suffixes:               opStore,
+			AnchorGraph:            gcAnchorGraph,
+			CAS:                    localCAS,
+			ProtocolClientProvider: pcp,
+			AnchorForks:            anchorForks,
+		}, parameters.casGCGracePeriod)
+
+		handlers = append(handlers, auth.NewHandlerWrapper(authCfg, casgchandler.New(casCollector)))
+	} else {
+		logger.Infof("CAS garbage collection is only supported for the local CAS.")
+	}
+
 	handlers = append(handlers,
 		endpointDiscoveryOp.GetRESTHandlers()...)
 
@@ -818,8 +1138,23 @@ func startOrbServices(parameters *orbParameters) error {
 
 	activityPubService.Start()
 
+	undeliverableSvc.Start()
+
+	actorKeyManager.Start()
+
+	anchorSyncSvc.Start()
+	sthGossipSvc.Start()
+
 	nodeInfoService.Start()
 
+	if admissionController != nil {
+		admissionController.Start()
+	}
+
+	if replayCache != nil {
+		replayCache.Start()
+	}
+
 	err = metricsHttpServer.Start()
 	if err != nil {
 		return fmt.Errorf("start metrics HTTP server at %s: %w", parameters.hostMetricsURL, err)
@@ -836,10 +1171,27 @@ func startOrbServices(parameters *orbParameters) error {
 
 	nodeInfoService.Stop()
 
+	if admissionController != nil {
+		admissionController.Stop()
+	}
+
+	if replayCache != nil {
+		replayCache.Stop()
+	}
+
 	batchWriter.Stop()
 
+	anchorSweeper.Stop()
+
 	o.Stop()
 
+	sthGossipSvc.Stop()
+	anchorSyncSvc.Stop()
+
+	undeliverableSvc.Stop()
+
+	actorKeyManager.Stop()
+
 	activityPubService.Stop()
 
 	if err := pubSub.Close(); err != nil {
@@ -959,6 +1311,17 @@ func (k kmsProvider) SecretLock() secretlock.Service {
 	return k.secretLockService
 }
 
+func createOperationQueue(parameters *orbParameters, pubSub pubSub,
+	provider storage.Provider) (operationQueue, error) {
+	if parameters.opQueueType == opQueueTypeStore {
+		logger.Infof("Using persistent operation queue for instance [%s]", parameters.opQueueInstanceID)
+
+		return opqueue.NewStoreQueue(provider, parameters.opQueueInstanceID, metrics.Get())
+	}
+
+	return opqueue.New(opqueue.Config{PoolSize: parameters.opQueuePoolSize}, pubSub, metrics.Get())
+}
+
 type storageProviders struct {
 	provider           storage.Provider
 	kmsSecretsProvider storage.Provider
@@ -1099,21 +1462,17 @@ func mustParseURL(basePath, relativePath string) *url.URL {
 	return u
 }
 
-func getActivityPubPublicKey(pubKey []byte, apServiceIRI, apServicePublicKeyIRI *url.URL) (*vocab.PublicKeyType, error) {
-	pubDerKey, err := x509.MarshalPKIXPublicKey(ed25519.PublicKey(pubKey))
+func getActivityPubPublicKey(keyType kms.KeyType, pubKey []byte,
+	apServiceIRI, apServicePublicKeyIRI *url.URL) (*vocab.PublicKeyType, error) {
+	pemStr, err := httpsig.PublicKeyPEM(keyType, pubKey)
 	if err != nil {
 		return nil, fmt.Errorf("marshal pub key: %w", err)
 	}
 
-	pemBytes := pem.EncodeToMemory(&pem.Block{
-		Type:  "PUBLIC KEY",
-		Bytes: pubDerKey,
-	})
-
 	return vocab.NewPublicKey(
 		vocab.WithID(apServicePublicKeyIRI),
 		vocab.WithOwner(apServiceIRI),
-		vocab.WithPublicKeyPem(string(pemBytes)),
+		vocab.WithPublicKeyPem(pemStr),
 	), nil
 }
 
@@ -1125,11 +1484,12 @@ type signatureVerifier interface {
 	VerifyRequest(req *http.Request) (bool, *url.URL, error)
 }
 
-func getActivityPubSigners(parameters *orbParameters, km kms.KeyManager,
-	cr acrypto.Crypto) (getSigner signer, postSigner signer) {
+func getActivityPubSigners(parameters *orbParameters,
+	actorKeyManager *actorkeys.Manager) (getSigner signer, postSigner signer) {
 	if parameters.httpSignaturesEnabled {
-		getSigner = httpsig.NewSigner(httpsig.DefaultGetSignerConfig(), cr, km, parameters.keyID)
-		postSigner = httpsig.NewSigner(httpsig.DefaultPostSignerConfig(), cr, km, parameters.keyID)
+		// The signers sign with the current key of the service actor so that the key may be rotated.
+		getSigner = actorKeyManager.Signer(httpsig.DefaultGetSignerConfig())
+		postSigner = actorKeyManager.Signer(httpsig.DefaultPostSignerConfig())
 	} else {
 		getSigner = &transport.NoOpSigner{}
 		postSigner = &transport.NoOpSigner{}
@@ -1143,9 +1503,20 @@ type httpTransport interface {
 }
 
 func getActivityPubVerifier(parameters *orbParameters, km kms.KeyManager,
-	cr acrypto.Crypto, apClient *client.Client) signatureVerifier {
+	cr acrypto.Crypto, apClient *client.Client, replayCache *httpsig.ReplayCache) signatureVerifier {
 	if parameters.httpSignaturesEnabled {
-		return httpsig.NewVerifier(apClient, cr, km)
+		opts := []httpsig.Option{
+			httpsig.WithMaxClockSkew(parameters.httpSignatureMaxClockSkew),
+			httpsig.WithMetrics(metrics.Get()),
+		}
+
+		if replayCache != nil {
+			opts = append(opts, httpsig.WithReplayCache(replayCache))
+		} else {
+			logger.Warnf("HTTP signature replay protection for ActivityPub is disabled.")
+		}
+
+		return httpsig.NewVerifier(apClient, cr, km, opts...)
 	}
 
 	logger.Warnf("HTTP signature verification for ActivityPub is disabled.")
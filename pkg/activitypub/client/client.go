@@ -123,6 +123,31 @@ func (c *Client) getActor(actorIRI *url.URL) (*vocab.ActorType, error) {
 	return actor, nil
 }
 
+// RefreshActor removes the actor (and the actor's public keys) from the cache and retrieves
+// the latest version of the actor from the given IRI.
+func (c *Client) RefreshActor(actorIRI *url.URL) (*vocab.ActorType, error) {
+	for _, result := range removeFromCache(c.actorCache, actorIRI) {
+		c.removePublicKeysFromCache(result.(*vocab.ActorType))
+	}
+
+	actor, err := c.GetActor(actorIRI)
+	if err != nil {
+		return nil, err
+	}
+
+	c.removePublicKeysFromCache(actor)
+
+	return actor, nil
+}
+
+func (c *Client) removePublicKeysFromCache(actor *vocab.ActorType) {
+	for _, pk := range actor.PublicKeys() {
+		if pk != nil && pk.ID != nil {
+			removeFromCache(c.publicKeyCache, pk.ID.URL())
+		}
+	}
+}
+
 // GetPublicKey retrieves the public key at the given IRI.
 //nolint:interfacer
 func (c *Client) GetPublicKey(keyIRI *url.URL) (*vocab.PublicKeyType, error) {
@@ -172,6 +197,76 @@ func (c *Client) GetReferences(iri *url.URL) (ReferenceIterator, error) {
 	return newIterator(items, firstPage, totalItems, c.get), nil
 }
 
+// ActivityPage contains the activities in a single page of a collection along with the links to the
+// adjacent pages.
+type ActivityPage struct {
+	Activities []*vocab.ActivityType
+	Next       *url.URL
+	Prev       *url.URL
+}
+
+// GetActivitiesPage returns the page of activities at the given IRI. If the IRI refers to a collection
+// (or ordered collection) rather than a page then the first page of the collection is returned. The caller
+// controls which pages are retrieved so a large collection may be traversed in either direction without
+// holding more than one page in memory.
+func (c *Client) GetActivitiesPage(iri *url.URL) (*ActivityPage, error) {
+	respBytes, err := c.get(iri)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response from %s: %w", iri, err)
+	}
+
+	logger.Debugf("Got response from %s: %s", iri, respBytes)
+
+	obj := &vocab.ObjectType{}
+
+	if err := json.Unmarshal(respBytes, &obj); err != nil {
+		return nil, fmt.Errorf("error unmarsalling response from %s: %w", iri, err)
+	}
+
+	if obj.Type().Is(vocab.TypeCollection) || obj.Type().Is(vocab.TypeOrderedCollection) {
+		_, firstPage, _, err := unmarshalReference(respBytes)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarsalling response from %s: %w", iri, err)
+		}
+
+		if firstPage == nil {
+			return &ActivityPage{}, nil
+		}
+
+		respBytes, err = c.get(firstPage)
+		if err != nil {
+			return nil, fmt.Errorf("request to %s failed: %w", firstPage, err)
+		}
+	}
+
+	page, err := unmarshalActivityPage(respBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// removeFromCache removes all entries from the given cache whose key matches the given IRI and returns
+// the removed values. (The cache is keyed by URL pointer so different instances of the same IRI may be
+// in the cache.)
+func removeFromCache(cache gcache.Cache, iri *url.URL) []interface{} {
+	var removed []interface{}
+
+	for key, value := range cache.GetALL(false) {
+		u, ok := key.(*url.URL)
+		if !ok || u.String() != iri.String() {
+			continue
+		}
+
+		cache.Remove(key)
+
+		removed = append(removed, value)
+	}
+
+	return removed
+}
+
 func (c *Client) get(iri *url.URL) ([]byte, error) {
 	resp, err := c.Get(context.Background(), transport.NewRequest(iri,
 		transport.WithHeader(transport.AcceptHeader, transport.ActivityStreamsContentType)))
@@ -357,3 +452,65 @@ func unmarshalCollectionPage(respBytes []byte) ([]*url.URL, *url.URL, error) {
 
 	return refs, next, nil
 }
+
+// activityPage is used to unmarshal the raw items of a collection page so that each item
+// may be unmarshalled as an activity.
+type activityPage struct {
+	Items        []json.RawMessage `json:"items,omitempty"`
+	OrderedItems []json.RawMessage `json:"orderedItems,omitempty"`
+}
+
+func unmarshalActivityPage(respBytes []byte) (*ActivityPage, error) {
+	obj := &vocab.ObjectType{}
+
+	if err := json.Unmarshal(respBytes, &obj); err != nil {
+		return nil, err
+	}
+
+	result := &ActivityPage{}
+
+	switch {
+	case obj.Type().Is(vocab.TypeCollectionPage):
+		coll := &vocab.CollectionPageType{}
+
+		if err := json.Unmarshal(respBytes, coll); err != nil {
+			return nil, fmt.Errorf("invalid collection page in response: %w", err)
+		}
+
+		result.Next = coll.Next()
+		result.Prev = coll.Prev()
+
+	case obj.Type().Is(vocab.TypeOrderedCollectionPage):
+		coll := &vocab.OrderedCollectionPageType{}
+
+		if err := json.Unmarshal(respBytes, coll); err != nil {
+			return nil, fmt.Errorf("invalid ordered collection page in response: %w", err)
+		}
+
+		result.Next = coll.Next()
+		result.Prev = coll.Prev()
+
+	default:
+		return nil, fmt.Errorf("expecting CollectionPage or OrderedCollectionPage in response payload")
+	}
+
+	page := &activityPage{}
+
+	if err := json.Unmarshal(respBytes, page); err != nil {
+		return nil, fmt.Errorf("invalid items in collection page: %w", err)
+	}
+
+	for _, item := range append(page.Items, page.OrderedItems...) {
+		activity := &vocab.ActivityType{}
+
+		if err := json.Unmarshal(item, activity); err != nil {
+			logger.Warnf("Expecting activity in collection but got: %s", item)
+
+			continue
+		}
+
+		result.Activities = append(result.Activities, activity)
+	}
+
+	return result, nil
+}
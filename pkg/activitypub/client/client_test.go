@@ -182,6 +182,68 @@ func TestClient_GetActor(t *testing.T) {
 	})
 }
 
+func TestClient_RefreshActor(t *testing.T) {
+	actorIRI := testutil.MustParseURL("https://example.com/services/service1")
+
+	actorBytes, e := json.Marshal(aptestutil.NewMockService(actorIRI))
+	require.NoError(t, e)
+
+	t.Run("Success", func(t *testing.T) {
+		rw1 := httptest.NewRecorder()
+
+		_, err := rw1.Write(actorBytes)
+		require.NoError(t, err)
+
+		rw2 := httptest.NewRecorder()
+
+		_, err = rw2.Write(actorBytes)
+		require.NoError(t, err)
+
+		result1 := rw1.Result()
+		result2 := rw2.Result()
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturnsOnCall(0, result1, nil)
+		httpClient.GetReturnsOnCall(1, result2, nil)
+
+		c := New(Config{}, httpClient)
+		require.NotNil(t, t, c)
+
+		actor, err := c.GetActor(actorIRI)
+		require.NoError(t, err)
+		require.NotNil(t, actor)
+
+		// Should be retrieved from the cache.
+		_, err = c.GetActor(actorIRI)
+		require.NoError(t, err)
+		require.Equal(t, 1, httpClient.GetCallCount())
+
+		actor, err = c.RefreshActor(testutil.MustParseURL(actorIRI.String()))
+		require.NoError(t, err)
+		require.NotNil(t, actor)
+		require.Equal(t, actorIRI.String(), actor.ID().String())
+		require.Equal(t, 2, httpClient.GetCallCount())
+
+		require.NoError(t, result1.Body.Close())
+		require.NoError(t, result2.Body.Close())
+	})
+
+	t.Run("HTTP client error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected HTTP client error")
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturns(nil, errExpected)
+
+		c := New(Config{}, httpClient)
+		require.NotNil(t, t, c)
+
+		actor, err := c.RefreshActor(actorIRI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Nil(t, actor)
+	})
+}
+
 func TestClient_GetReferences(t *testing.T) {
 	log.SetLevel("activitypub_client", log.DEBUG)
 
@@ -498,6 +560,108 @@ func TestClient_GetReferences(t *testing.T) {
 	})
 }
 
+func TestClient_GetActivitiesPage(t *testing.T) {
+	serviceIRI := testutil.MustParseURL("https://example.com/services/service1")
+	outboxIRI := testutil.NewMockID(serviceIRI, "/outbox")
+	page1IRI := testutil.NewMockID(outboxIRI, "?page=true")
+	page2IRI := testutil.NewMockID(outboxIRI, "?page=true&page-num=0")
+
+	activities := aptestutil.NewMockCreateActivities(3)
+
+	newResult := func(t *testing.T, obj interface{}) *http.Response {
+		t.Helper()
+
+		b, e := json.Marshal(obj)
+		require.NoError(t, e)
+
+		rw := httptest.NewRecorder()
+
+		_, e = rw.Write(b)
+		require.NoError(t, e)
+
+		return rw.Result()
+	}
+
+	t.Run("Collection -> first page", func(t *testing.T) {
+		result1 := newResult(t, aptestutil.NewMockOrderedCollection(outboxIRI, page1IRI, len(activities)))
+		result2 := newResult(t, vocab.NewOrderedCollectionPage(
+			[]*vocab.ObjectProperty{
+				vocab.NewObjectProperty(vocab.WithActivity(activities[0])),
+				vocab.NewObjectProperty(vocab.WithActivity(activities[1])),
+			},
+			vocab.WithID(page1IRI), vocab.WithNext(page2IRI),
+		))
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturnsOnCall(0, result1, nil)
+		httpClient.GetReturnsOnCall(1, result2, nil)
+
+		page, e := New(Config{}, httpClient).GetActivitiesPage(outboxIRI)
+		require.NoError(t, e)
+		require.Len(t, page.Activities, 2)
+		require.Equal(t, activities[0].ID().String(), page.Activities[0].ID().String())
+		require.Equal(t, page2IRI.String(), page.Next.String())
+		require.Nil(t, page.Prev)
+
+		require.NoError(t, result1.Body.Close())
+		require.NoError(t, result2.Body.Close())
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		result := newResult(t, vocab.NewCollectionPage(
+			[]*vocab.ObjectProperty{vocab.NewObjectProperty(vocab.WithActivity(activities[2]))},
+			vocab.WithID(page2IRI), vocab.WithPrev(page1IRI),
+		))
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturns(result, nil)
+
+		page, e := New(Config{}, httpClient).GetActivitiesPage(page2IRI)
+		require.NoError(t, e)
+		require.Len(t, page.Activities, 1)
+		require.Nil(t, page.Next)
+		require.Equal(t, page1IRI.String(), page.Prev.String())
+
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("HTTP client error", func(t *testing.T) {
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturns(nil, errors.New("injected HTTP client error"))
+
+		_, e := New(Config{}, httpClient).GetActivitiesPage(outboxIRI)
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "injected HTTP client error")
+	})
+
+	t.Run("First page error", func(t *testing.T) {
+		result := newResult(t, aptestutil.NewMockOrderedCollection(outboxIRI, page1IRI, len(activities)))
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturnsOnCall(0, result, nil)
+		httpClient.GetReturnsOnCall(1, nil, errors.New("injected HTTP client error"))
+
+		_, e := New(Config{}, httpClient).GetActivitiesPage(outboxIRI)
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "injected HTTP client error")
+
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Invalid page", func(t *testing.T) {
+		result := newResult(t, aptestutil.NewMockService(serviceIRI))
+
+		httpClient := &mocks.HTTPTransport{}
+		httpClient.GetReturns(result, nil)
+
+		_, e := New(Config{}, httpClient).GetActivitiesPage(page1IRI)
+		require.Error(t, e)
+		require.Contains(t, e.Error(), "expecting CollectionPage or OrderedCollectionPage in response payload")
+
+		require.NoError(t, result.Body.Close())
+	})
+}
+
 func TestClient_GetPublicKey(t *testing.T) {
 	serviceIRI := testutil.MustParseURL("https://example.com/services/service1")
 	keyIRI := testutil.NewMockID(serviceIRI, "/keys/main-key")
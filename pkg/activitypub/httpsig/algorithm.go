@@ -7,9 +7,6 @@ SPDX-License-Identifier: Apache-2.0
 package httpsig
 
 import (
-	"crypto/ed25519"
-	"crypto/x509"
-	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/url"
@@ -90,10 +87,11 @@ func (a *SignatureHashAlgorithm) Verify(secret httpsig.Secret, data, signature [
 
 	logger.Debugf("Got key %+v from keyID [%s]", pubKey, secret.KeyID)
 
-	if !ed25519.Verify(pubKey.Value, data, signature) {
-		logger.Infof("Signature verification failed using keyID [%s]", secret.KeyID)
+	if err := verifySignature(pubKey, data, signature); err != nil {
+		logger.Infof("Signature verification failed using keyID [%s] and key type [%s]: %s",
+			secret.KeyID, pubKey.Type, err)
 
-		return ErrInvalidSignature
+		return err
 	}
 
 	logger.Debugf("Successfully verified signature using keyID [%s]", secret.KeyID)
@@ -129,22 +127,14 @@ func (r *KeyResolver) Resolve(keyID string) (*ariesverifier.PublicKey, error) {
 		return nil, fmt.Errorf("retrieve public key for ID [%s]: %w", keyID, err)
 	}
 
-	block, rest := pem.Decode([]byte(pubKey.PublicKeyPem))
-	if block == nil {
-		logger.Warnf("invalid public key: nil block. Rest: %s", rest)
-
-		return nil, fmt.Errorf("invalid public key for ID [%s]: nil block", keyID)
-	}
-
-	pk, err := x509.ParsePKIXPublicKey(block.Bytes)
+	pk, err := parsePublicKeyPEM(pubKey.PublicKeyPem)
 	if err != nil {
-		return nil, fmt.Errorf("parse public key for ID [%s]: %w", keyID, err)
+		return nil, fmt.Errorf("invalid public key for ID [%s]: %w", keyID, err)
 	}
 
-	return &ariesverifier.PublicKey{
-		Type:  kms.ED25519, // TODO: Support other algorithms?
-		Value: pk.(ed25519.PublicKey),
-	}, nil
+	logger.Debugf("Resolved public key of type [%s] for key IRI [%s]", pk.Type, keyIRI)
+
+	return pk, nil
 }
 
 // SecretRetriever implements a custom key retriever to be used with the HTTP signature library.
@@ -0,0 +1,222 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+
+	ariesverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+)
+
+const pemTypePublicKey = "PUBLIC KEY"
+
+// SupportedKeyTypes returns the KMS key types that may be used to sign HTTP requests.
+func SupportedKeyTypes() []kms.KeyType {
+	return []kms.KeyType{
+		kms.ED25519Type,
+		kms.ECDSAP256TypeDER, kms.ECDSAP256TypeIEEEP1363,
+		kms.ECDSAP384TypeDER, kms.ECDSAP384TypeIEEEP1363,
+		kms.RSARS256Type,
+	}
+}
+
+// PublicKeyPEM converts the public key bytes exported from the KMS for a key of the given type
+// into a PEM-encoded (PKIX) public key, which is the format of the publicKeyPem field of an actor.
+func PublicKeyPEM(keyType kms.KeyType, pubKeyBytes []byte) (string, error) {
+	pk, err := unmarshalKMSPublicKey(keyType, pubKeyBytes)
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return "", fmt.Errorf("marshal public key: %w", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  pemTypePublicKey,
+		Bytes: der,
+	})), nil
+}
+
+func unmarshalKMSPublicKey(keyType kms.KeyType, pubKeyBytes []byte) (crypto.PublicKey, error) {
+	switch keyType {
+	case kms.ED25519Type:
+		if len(pubKeyBytes) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ED25519 public key")
+		}
+
+		return ed25519.PublicKey(pubKeyBytes), nil
+
+	case kms.ECDSAP256TypeDER, kms.ECDSAP384TypeDER:
+		// The KMS exports DER keys in PKIX format.
+		pk, err := x509.ParsePKIXPublicKey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse ECDSA public key: %w", err)
+		}
+
+		return pk, nil
+
+	case kms.ECDSAP256TypeIEEEP1363, kms.ECDSAP384TypeIEEEP1363:
+		// The KMS exports IEEE P1363 keys as marshalled elliptic curve points.
+		curve := elliptic.P256()
+		if keyType == kms.ECDSAP384TypeIEEEP1363 {
+			curve = elliptic.P384()
+		}
+
+		x, y := elliptic.Unmarshal(curve, pubKeyBytes)
+		if x == nil {
+			return nil, errors.New("invalid ECDSA public key")
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case kms.RSARS256Type:
+		if pk, err := x509.ParsePKIXPublicKey(pubKeyBytes); err == nil {
+			return pk, nil
+		}
+
+		pk, err := x509.ParsePKCS1PublicKey(pubKeyBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse RSA public key: %w", err)
+		}
+
+		return pk, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type [%s]", keyType)
+	}
+}
+
+// parsePublicKeyPEM parses the given PEM-encoded public key and determines the signature algorithm from the
+// type of key.
+func parsePublicKeyPEM(pemStr string) (*ariesverifier.PublicKey, error) {
+	block, rest := pem.Decode([]byte(pemStr))
+	if block == nil {
+		logger.Warnf("invalid public key: nil block. Rest: %s", rest)
+
+		return nil, errors.New("nil block")
+	}
+
+	pk, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse public key: %w", err)
+	}
+
+	switch key := pk.(type) {
+	case ed25519.PublicKey:
+		return &ariesverifier.PublicKey{Type: kms.ED25519, Value: key}, nil
+
+	case *ecdsa.PublicKey:
+		var keyType string
+
+		switch key.Curve {
+		case elliptic.P256():
+			keyType = kms.ECDSAP256DER
+		case elliptic.P384():
+			keyType = kms.ECDSAP384DER
+		default:
+			return nil, fmt.Errorf("unsupported elliptic curve [%s]", key.Curve.Params().Name)
+		}
+
+		return &ariesverifier.PublicKey{Type: keyType, Value: elliptic.Marshal(key.Curve, key.X, key.Y)}, nil
+
+	case *rsa.PublicKey:
+		return &ariesverifier.PublicKey{Type: kms.RSARS256, Value: x509.MarshalPKCS1PublicKey(key)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported public key type [%T]", pk)
+	}
+}
+
+// verifySignature verifies the signature over the given data using the algorithm that corresponds
+// to the type of public key.
+func verifySignature(pubKey *ariesverifier.PublicKey, data, signature []byte) error {
+	switch pubKey.Type {
+	case kms.ED25519, "":
+		if len(pubKey.Value) != ed25519.PublicKeySize || !ed25519.Verify(pubKey.Value, data, signature) {
+			return ErrInvalidSignature
+		}
+
+		return nil
+
+	case kms.ECDSAP256DER:
+		return verifyECDSA(elliptic.P256(), pubKey.Value, data, signature, sha256.New)
+
+	case kms.ECDSAP384DER:
+		// The standard hash for P-384 is SHA-384 although the Tink (DER) key template for P-384 uses SHA-512,
+		// so both are accepted.
+		err := verifyECDSA(elliptic.P384(), pubKey.Value, data, signature, sha512.New384)
+		if err == nil {
+			return nil
+		}
+
+		return verifyECDSA(elliptic.P384(), pubKey.Value, data, signature, sha512.New)
+
+	case kms.RSARS256:
+		pk, err := x509.ParsePKCS1PublicKey(pubKey.Value)
+		if err != nil {
+			return fmt.Errorf("parse RSA public key: %w", err)
+		}
+
+		digest := sha256.Sum256(data)
+
+		if rsa.VerifyPKCS1v15(pk, crypto.SHA256, digest[:], signature) != nil {
+			return ErrInvalidSignature
+		}
+
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported key type [%s]", pubKey.Type)
+	}
+}
+
+// verifyECDSA verifies an ECDSA signature that's encoded either in IEEE P1363 format (r||s) or in ASN.1 DER format.
+func verifyECDSA(curve elliptic.Curve, pubKeyBytes, data, signature []byte, newHash func() hash.Hash) error {
+	x, y := elliptic.Unmarshal(curve, pubKeyBytes)
+	if x == nil {
+		return errors.New("invalid ECDSA public key")
+	}
+
+	pk := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+
+	h := newHash()
+	h.Write(data) //nolint:errcheck
+
+	digest := h.Sum(nil)
+
+	keySize := (curve.Params().BitSize + 7) / 8 //nolint:gomnd
+
+	if len(signature) == 2*keySize {
+		r := new(big.Int).SetBytes(signature[:keySize])
+		s := new(big.Int).SetBytes(signature[keySize:])
+
+		if ecdsa.Verify(pk, digest, r, s) {
+			return nil
+		}
+	}
+
+	if ecdsa.VerifyASN1(pk, digest, signature) {
+		return nil
+	}
+
+	return ErrInvalidSignature
+}
@@ -0,0 +1,266 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpsig
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	ariesverifier "github.com/hyperledger/aries-framework-go/pkg/doc/signature/verifier"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/igor-pavlenko/httpsignatures-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/mocks"
+)
+
+func TestSignAndVerifyWithKMS(t *testing.T) {
+	km, err := localkms.New("local-lock://custom/master/key/", &kmsProvider{
+		storageProvider:   mem.NewProvider(),
+		secretLockService: &noop.NoLock{},
+	})
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	secret := httpsignatures.Secret{KeyID: "https://example.com/services/orb/keys/main-key"}
+	data := []byte("data")
+
+	for _, keyType := range SupportedKeyTypes() {
+		if keyType == kms.RSARS256Type {
+			// RSA keys aren't supported by the local KMS.
+			continue
+		}
+
+		t.Run(string(keyType), func(t *testing.T) {
+			keyID, _, err := km.Create(keyType)
+			require.NoError(t, err)
+
+			pubKeyBytes, err := km.ExportPubKeyBytes(keyID)
+			require.NoError(t, err)
+
+			pubKeyPEM, err := PublicKeyPEM(keyType, pubKeyBytes)
+			require.NoError(t, err)
+
+			pubKey, err := parsePublicKeyPEM(pubKeyPEM)
+			require.NoError(t, err)
+
+			signature, err := NewSignerAlgorithm(cr, km, keyID).Create(secret, data)
+			require.NoError(t, err)
+
+			resolver := &mocks.KeyResolver{}
+			resolver.ResolveReturns(pubKey, nil)
+
+			algo := NewVerifierAlgorithm(cr, km, resolver)
+
+			require.NoError(t, algo.Verify(secret, data, signature))
+			require.True(t, errors.Is(algo.Verify(secret, []byte("other data"), signature), ErrInvalidSignature))
+		})
+	}
+}
+
+func TestPublicKeyPEM(t *testing.T) {
+	t.Run("RSA", func(t *testing.T) {
+		privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		pemStr, err := PublicKeyPEM(kms.RSARS256Type, x509.MarshalPKCS1PublicKey(&privKey.PublicKey))
+		require.NoError(t, err)
+
+		pubKey, err := parsePublicKeyPEM(pemStr)
+		require.NoError(t, err)
+		require.Equal(t, kms.RSARS256, pubKey.Type)
+
+		pkixBytes, err := x509.MarshalPKIXPublicKey(&privKey.PublicKey)
+		require.NoError(t, err)
+
+		pemStr2, err := PublicKeyPEM(kms.RSARS256Type, pkixBytes)
+		require.NoError(t, err)
+		require.Equal(t, pemStr, pemStr2)
+	})
+
+	t.Run("Invalid keys -> error", func(t *testing.T) {
+		_, err := PublicKeyPEM(kms.ED25519Type, []byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid ED25519 public key")
+
+		_, err = PublicKeyPEM(kms.ECDSAP256TypeDER, []byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse ECDSA public key")
+
+		_, err = PublicKeyPEM(kms.ECDSAP384TypeIEEEP1363, []byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid ECDSA public key")
+
+		_, err = PublicKeyPEM(kms.RSARS256Type, []byte("invalid"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse RSA public key")
+
+		_, err = PublicKeyPEM(kms.BLS12381G2Type, []byte("key"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported key type")
+	})
+}
+
+func TestParsePublicKeyPEM(t *testing.T) {
+	t.Run("Invalid PEM -> error", func(t *testing.T) {
+		_, err := parsePublicKeyPEM("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "nil block")
+
+		_, err = parsePublicKeyPEM(string(pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: []byte("x")})))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse public key")
+	})
+
+	t.Run("Unsupported curve -> error", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		require.NoError(t, err)
+
+		_, err = parsePublicKeyPEM(toPEM(t, &privKey.PublicKey))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported elliptic curve")
+	})
+}
+
+func TestVerifySignature(t *testing.T) {
+	data := []byte("data")
+
+	t.Run("ECDSA P-256", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+
+		pubKey, err := parsePublicKeyPEM(toPEM(t, &privKey.PublicKey))
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP256DER, pubKey.Type)
+
+		digest := sha256.Sum256(data)
+
+		// ASN.1 DER signature.
+		sig, err := ecdsa.SignASN1(rand.Reader, privKey, digest[:])
+		require.NoError(t, err)
+		require.NoError(t, verifySignature(pubKey, data, sig))
+
+		// IEEE P1363 signature.
+		r, s, err := ecdsa.Sign(rand.Reader, privKey, digest[:])
+		require.NoError(t, err)
+
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+
+		require.NoError(t, verifySignature(pubKey, data, sig))
+		require.True(t, errors.Is(verifySignature(pubKey, []byte("other data"), sig), ErrInvalidSignature))
+
+		err = verifySignature(&ariesverifier.PublicKey{Type: kms.ECDSAP256DER, Value: []byte("invalid")}, data, sig)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid ECDSA public key")
+	})
+
+	t.Run("ECDSA P-384", func(t *testing.T) {
+		privKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		require.NoError(t, err)
+
+		pubKey, err := parsePublicKeyPEM(toPEM(t, &privKey.PublicKey))
+		require.NoError(t, err)
+		require.Equal(t, kms.ECDSAP384DER, pubKey.Type)
+
+		digest384 := sha512.Sum384(data)
+
+		sig, err := ecdsa.SignASN1(rand.Reader, privKey, digest384[:])
+		require.NoError(t, err)
+		require.NoError(t, verifySignature(pubKey, data, sig))
+
+		digest512 := sha512.Sum512(data)
+
+		sig, err = ecdsa.SignASN1(rand.Reader, privKey, digest512[:])
+		require.NoError(t, err)
+		require.NoError(t, verifySignature(pubKey, data, sig))
+
+		require.True(t, errors.Is(verifySignature(pubKey, []byte("other data"), sig), ErrInvalidSignature))
+	})
+
+	t.Run("RSA", func(t *testing.T) {
+		privKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		pubKey, err := parsePublicKeyPEM(toPEM(t, &privKey.PublicKey))
+		require.NoError(t, err)
+		require.Equal(t, kms.RSARS256, pubKey.Type)
+
+		digest := sha256.Sum256(data)
+
+		sig, err := rsa.SignPKCS1v15(rand.Reader, privKey, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+
+		require.NoError(t, verifySignature(pubKey, data, sig))
+		require.True(t, errors.Is(verifySignature(pubKey, []byte("other data"), sig), ErrInvalidSignature))
+
+		err = verifySignature(&ariesverifier.PublicKey{Type: kms.RSARS256, Value: []byte("invalid")}, data, sig)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse RSA public key")
+	})
+
+	t.Run("Ed25519", func(t *testing.T) {
+		pk, privKey, err := ed25519.GenerateKey(rand.Reader)
+		require.NoError(t, err)
+
+		pubKey, err := parsePublicKeyPEM(toPEM(t, pk))
+		require.NoError(t, err)
+		require.Equal(t, kms.ED25519, pubKey.Type)
+
+		require.NoError(t, verifySignature(pubKey, data, ed25519.Sign(privKey, data)))
+
+		err = verifySignature(&ariesverifier.PublicKey{Type: kms.ED25519, Value: []byte("invalid")}, data, nil)
+		require.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("Unsupported key type", func(t *testing.T) {
+		err := verifySignature(&ariesverifier.PublicKey{Type: kms.BLS12381G2}, data, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported key type")
+	})
+}
+
+func toPEM(t *testing.T, pk crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pk)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: pemTypePublicKey, Bytes: der}))
+}
+
+type kmsProvider struct {
+	storageProvider   storage.Provider
+	secretLockService secretlock.Service
+}
+
+func (k *kmsProvider) StorageProvider() storage.Provider {
+	return k.storageProvider
+}
+
+func (k *kmsProvider) SecretLock() secretlock.Service {
+	return k.secretLockService
+}
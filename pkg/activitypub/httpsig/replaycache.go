@@ -0,0 +1,250 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpsig
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+const (
+	replayStoreName = "httpsig-replay"
+	replayTag       = "replay"
+
+	defaultReplayCacheSize     = 10000
+	defaultReplayPurgeInterval = time.Minute
+)
+
+// ReplayCacheConfig contains the configuration for the replay cache.
+type ReplayCacheConfig struct {
+	// Expiry is the time for which a request is remembered. This must be set and should be at least twice the
+	// maximum clock skew since a request whose Date header is outside of the clock skew window is rejected anyway.
+	Expiry time.Duration
+
+	// CacheSize is the maximum number of requests held in the local (in-memory) cache.
+	CacheSize int
+
+	// PurgeInterval is the interval at which expired requests are removed from the database.
+	PurgeInterval time.Duration
+}
+
+type replayEntry struct {
+	Expires time.Time `json:"expires"`
+}
+
+// ReplayCache records the requests that were accepted so that a replayed request may be detected. A hash of each
+// request key is persisted using the storage provider so that the cache is shared by all instances in a cluster.
+// A bounded, in-memory cache avoids a database lookup for recently seen requests and expired entries are
+// periodically purged from the database.
+type ReplayCache struct {
+	*lifecycle.Lifecycle
+	ReplayCacheConfig
+
+	store storage.Store
+	local gcache.Cache
+	done  chan struct{}
+	now   func() time.Time
+}
+
+// NewReplayCache returns a new replay cache.
+func NewReplayCache(cfg ReplayCacheConfig, provider storage.Provider) (*ReplayCache, error) {
+	if cfg.Expiry <= 0 {
+		return nil, errors.New("replay cache expiry must be greater than 0")
+	}
+
+	store, err := provider.OpenStore(replayStoreName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", replayStoreName, err)
+	}
+
+	err = provider.SetStoreConfig(replayStoreName, storage.StoreConfiguration{TagNames: []string{replayTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	cfg = populateReplayCacheDefaults(cfg)
+
+	c := &ReplayCache{
+		ReplayCacheConfig: cfg,
+		store:             store,
+		local:             gcache.New(cfg.CacheSize).LRU().Build(),
+		done:              make(chan struct{}),
+		now:               time.Now,
+	}
+
+	c.Lifecycle = lifecycle.New("httpsig-replay-cache",
+		lifecycle.WithStart(c.start),
+		lifecycle.WithStop(c.stop))
+
+	return c, nil
+}
+
+// Add adds the given request key to the cache. True is returned if the key was already in the cache,
+// i.e. the request is a replay.
+func (c *ReplayCache) Add(requestKey string) (bool, error) {
+	key := hashKey(requestKey)
+
+	if _, err := c.local.Get(key); err == nil {
+		return true, nil
+	}
+
+	now := c.now()
+
+	value, err := c.store.Get(key)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return false, orberrors.NewTransient(fmt.Errorf("get replay entry: %w", err))
+	}
+
+	if err == nil {
+		entry := &replayEntry{}
+
+		if e := json.Unmarshal(value, entry); e != nil {
+			return false, fmt.Errorf("unmarshal replay entry: %w", e)
+		}
+
+		if now.Before(entry.Expires) {
+			c.setLocal(key, entry.Expires.Sub(now))
+
+			return true, nil
+		}
+	}
+
+	expires := now.Add(c.Expiry)
+
+	entryBytes, err := json.Marshal(&replayEntry{Expires: expires})
+	if err != nil {
+		return false, fmt.Errorf("marshal replay entry: %w", err)
+	}
+
+	if err := c.store.Put(key, entryBytes, storage.Tag{Name: replayTag}); err != nil {
+		return false, orberrors.NewTransient(fmt.Errorf("store replay entry: %w", err))
+	}
+
+	c.setLocal(key, c.Expiry)
+
+	return false, nil
+}
+
+func (c *ReplayCache) setLocal(key string, expiration time.Duration) {
+	if err := c.local.SetWithExpire(key, true, expiration); err != nil {
+		logger.Warnf("Error adding replay entry to local cache: %s", err)
+	}
+}
+
+func (c *ReplayCache) start() {
+	go c.run()
+
+	logger.Infof("Started HTTP signature replay cache - Expiry [%s], Purge interval [%s]",
+		c.Expiry, c.PurgeInterval)
+}
+
+func (c *ReplayCache) stop() {
+	close(c.done)
+
+	logger.Infof("Stopped HTTP signature replay cache")
+}
+
+func (c *ReplayCache) run() {
+	for {
+		select {
+		case <-time.After(c.PurgeInterval):
+			if err := c.purge(); err != nil {
+				logger.Warnf("Error purging expired replay entries: %s", err)
+			}
+		case <-c.done:
+			logger.Debugf("Exiting replay cache purge job.")
+
+			return
+		}
+	}
+}
+
+// purge deletes the expired entries from the database.
+func (c *ReplayCache) purge() error {
+	it, err := c.store.Query(replayTag)
+	if err != nil {
+		return fmt.Errorf("query replay entries: %w", err)
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	now := c.now()
+
+	var expired []storage.Operation
+
+	ok, err := it.Next()
+	if err != nil {
+		return fmt.Errorf("iterator error: %w", err)
+	}
+
+	for ok {
+		key, e := it.Key()
+		if e != nil {
+			return fmt.Errorf("failed to get iterator key: %w", e)
+		}
+
+		value, e := it.Value()
+		if e != nil {
+			return fmt.Errorf("failed to get iterator value: %w", e)
+		}
+
+		entry := &replayEntry{}
+
+		if e := json.Unmarshal(value, entry); e != nil || !now.Before(entry.Expires) {
+			expired = append(expired, storage.Operation{Key: key})
+		}
+
+		ok, err = it.Next()
+		if err != nil {
+			return fmt.Errorf("iterator error: %w", err)
+		}
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	if err := c.store.Batch(expired); err != nil {
+		return fmt.Errorf("delete expired replay entries: %w", err)
+	}
+
+	logger.Debugf("Purged %d expired replay entries", len(expired))
+
+	return nil
+}
+
+func hashKey(key string) string {
+	h := sha256.Sum256([]byte(key))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func populateReplayCacheDefaults(cfg ReplayCacheConfig) ReplayCacheConfig {
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = defaultReplayCacheSize
+	}
+
+	if cfg.PurgeInterval == 0 {
+		cfg.PurgeInterval = defaultReplayPurgeInterval
+	}
+
+	return cfg
+}
@@ -0,0 +1,185 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package httpsig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	mockstore "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	requestKey1 = "https://domain1.com/services/orb/keys/main-key\n(request-target): post /services/orb/inbox\n" +
+		"date: Tue, 07 Jun 2022 20:51:35 GMT\ndigest: SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE="
+	requestKey2 = "https://domain1.com/services/orb/keys/main-key\n(request-target): post /services/orb/inbox\n" +
+		"date: Tue, 07 Jun 2022 20:51:36 GMT\ndigest: SHA-256=X48E9qOokqqrvdts8nOJRJN3OWDUoyWxBf7kbu9DBPE="
+)
+
+func TestNewReplayCache(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, mem.NewProvider())
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.Equal(t, time.Minute, c.Expiry)
+		require.Equal(t, defaultReplayCacheSize, c.CacheSize)
+		require.Equal(t, defaultReplayPurgeInterval, c.PurgeInterval)
+	})
+
+	t.Run("No expiry", func(t *testing.T) {
+		_, err := NewReplayCache(ReplayCacheConfig{}, mem.NewProvider())
+		require.EqualError(t, err, "replay cache expiry must be greater than 0")
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		p := &mockstore.Provider{ErrOpenStore: errors.New("injected open error")}
+
+		_, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected open error")
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		p := &mockstore.Provider{
+			OpenStoreReturn:   &mockstore.Store{},
+			ErrSetStoreConfig: errors.New("injected config error"),
+		}
+
+		_, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected config error")
+	})
+}
+
+func TestReplayCache_Add(t *testing.T) {
+	provider := mem.NewProvider()
+
+	now := time.Now()
+
+	c, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, provider)
+	require.NoError(t, err)
+
+	c.now = func() time.Time { return now }
+
+	replayed, err := c.Add(requestKey1)
+	require.NoError(t, err)
+	require.False(t, replayed)
+
+	replayed, err = c.Add(requestKey1)
+	require.NoError(t, err)
+	require.True(t, replayed)
+
+	replayed, err = c.Add(requestKey2)
+	require.NoError(t, err)
+	require.False(t, replayed)
+
+	t.Run("Shared with other instances", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, provider)
+		require.NoError(t, err)
+
+		c2.now = func() time.Time { return now }
+
+		replayed, err := c2.Add(requestKey1)
+		require.NoError(t, err)
+		require.True(t, replayed)
+	})
+
+	t.Run("Expired entry", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, provider)
+		require.NoError(t, err)
+
+		c2.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+		replayed, err := c2.Add(requestKey1)
+		require.NoError(t, err)
+		require.False(t, replayed)
+	})
+
+	t.Run("Get error", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{ErrGet: errors.New("injected get error")},
+		})
+		require.NoError(t, err)
+
+		_, err = c2.Add(requestKey1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected get error")
+	})
+
+	t.Run("Put error", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{
+				ErrGet: storage.ErrDataNotFound,
+				ErrPut: errors.New("injected put error"),
+			},
+		})
+		require.NoError(t, err)
+
+		_, err = c2.Add(requestKey1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected put error")
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{GetReturn: []byte("{")},
+		})
+		require.NoError(t, err)
+
+		_, err = c2.Add(requestKey1)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal replay entry")
+	})
+}
+
+func TestReplayCache_Purge(t *testing.T) {
+	provider := mem.NewProvider()
+
+	now := time.Now()
+
+	c, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute, PurgeInterval: 10 * time.Millisecond}, provider)
+	require.NoError(t, err)
+
+	c.now = func() time.Time { return now }
+
+	_, err = c.Add(requestKey1)
+	require.NoError(t, err)
+
+	c.now = func() time.Time { return now.Add(30 * time.Second) }
+
+	_, err = c.Add(requestKey2)
+	require.NoError(t, err)
+
+	// Only the first entry has expired.
+	c.now = func() time.Time { return now.Add(75 * time.Second) }
+
+	c.Start()
+	defer c.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, err = c.store.Get(hashKey(requestKey1))
+	require.True(t, errors.Is(err, storage.ErrDataNotFound))
+
+	_, err = c.store.Get(hashKey(requestKey2))
+	require.NoError(t, err)
+
+	t.Run("Query error", func(t *testing.T) {
+		c2, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{ErrQuery: errors.New("injected query error")},
+		})
+		require.NoError(t, err)
+
+		err = c2.purge()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected query error")
+	})
+}
@@ -7,11 +7,22 @@ SPDX-License-Identifier: Apache-2.0
 package httpsig
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/pkg/crypto"
 	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	httpsig "github.com/igor-pavlenko/httpsignatures-go"
@@ -27,24 +38,97 @@ type actorRetriever interface {
 	publicKeyRetriever
 
 	GetActor(actorIRI *url.URL) (*vocab.ActorType, error)
+	RefreshActor(actorIRI *url.URL) (*vocab.ActorType, error)
 }
 
 type verifier interface {
 	Verify(r *http.Request) error
 }
 
+type replayCache interface {
+	Add(key string) (bool, error)
+}
+
+type metricsProvider interface {
+	HTTPSignatureRejected(reason string)
+}
+
+const (
+	digestHeader    = "Digest"
+	hostHeader      = "Host"
+	signatureHeader = "Signature"
+	requestTarget   = "(request-target)"
+
+	defaultMaxClockSkew            = 5 * time.Minute
+	defaultMaxBodySize             = 10 * 1024 * 1024
+	defaultMinActorRefreshInterval = 10 * time.Second
+	refreshedActorsCacheSize       = 1000
+)
+
+// Reasons for rejecting a request (reported to the metrics provider).
+const (
+	RejectReasonClockSkew = "clock_skew"
+	RejectReasonDigest    = "digest"
+	RejectReasonReplay    = "replay"
+)
+
 // Verifier verifies signatures of HTTP requests.
 type Verifier struct {
 	actorRetriever actorRetriever
 	verifier       func() verifier
+	maxClockSkew   time.Duration
+	requireDigest  bool
+	maxBodySize    int64
+	replayCache    replayCache
+	metrics        metricsProvider
+	now            func() time.Time
+
+	minActorRefreshInterval time.Duration
+	refreshMutex            sync.Mutex
+	refreshedActors         gcache.Cache
+}
+
+// Option is a verifier option.
+type Option func(v *Verifier)
+
+// WithMaxClockSkew sets the maximum allowed difference between the (signed) Date header of a request and the
+// current time. If zero then the Date header is not checked.
+func WithMaxClockSkew(maxClockSkew time.Duration) Option {
+	return func(v *Verifier) {
+		v.maxClockSkew = maxClockSkew
+	}
+}
+
+// WithReplayCache sets the cache that's used to detect replayed requests. If not set then replayed requests
+// are not detected.
+func WithReplayCache(cache replayCache) Option {
+	return func(v *Verifier) {
+		v.replayCache = cache
+	}
+}
+
+// WithMinActorRefreshInterval sets the minimum interval between refreshes of the same actor. An actor is refreshed
+// when a request is signed with a key that's not on the cached actor, so this interval prevents a flood of requests
+// with unknown key IDs from causing an actor to be retrieved for each request.
+func WithMinActorRefreshInterval(interval time.Duration) Option {
+	return func(v *Verifier) {
+		v.minActorRefreshInterval = interval
+	}
+}
+
+// WithMetrics sets the metrics provider which is notified of rejected requests.
+func WithMetrics(metrics metricsProvider) Option {
+	return func(v *Verifier) {
+		v.metrics = metrics
+	}
 }
 
 // NewVerifier returns a new HTTP signature verifier.
-func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyManager) *Verifier {
+func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyManager, opts ...Option) *Verifier {
 	algo := NewVerifierAlgorithm(cr, km, NewKeyResolver(actorRetriever))
 	secretRetriever := &SecretRetriever{}
 
-	return &Verifier{
+	v := &Verifier{
 		actorRetriever: actorRetriever,
 		verifier: func() verifier {
 			// Return a new instance for each verification since the HTTP signature
@@ -54,12 +138,31 @@ func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyMana
 
 			return hs
 		},
+		maxClockSkew:            defaultMaxClockSkew,
+		requireDigest:           true,
+		maxBodySize:             defaultMaxBodySize,
+		metrics:                 &noOpMetrics{},
+		now:                     time.Now,
+		minActorRefreshInterval: defaultMinActorRefreshInterval,
+	}
+
+	for _, opt := range opts {
+		opt(v)
 	}
+
+	if v.minActorRefreshInterval > 0 {
+		v.refreshedActors = gcache.New(refreshedActorsCacheSize).LRU().Expiration(v.minActorRefreshInterval).Build()
+	}
+
+	return v
 }
 
 // VerifyRequest verifies the following:
+// - The signed Date header is within the maximum clock skew.
+// - The signed Digest header matches the body of the request (if the request has a body).
 // - HTTP signature on the request.
 // - Ensures that the key ID in the request header is owned by the actor.
+// - The signed content of the request was not already accepted (for requests other than GET and HEAD).
 //
 // Returns:
 // - true if the signature was successfully verified, otherwise false.
@@ -68,6 +171,14 @@ func NewVerifier(actorRetriever actorRetriever, cr crypto.Crypto, km kms.KeyMana
 func (v *Verifier) VerifyRequest(req *http.Request) (bool, *url.URL, error) {
 	logger.Debugf("Verifying request. Headers: %s", req.Header)
 
+	if reason, err := v.checkHeaders(req); err != nil {
+		logger.Infof("Rejecting request %s: %s", req.URL, err)
+
+		v.metrics.HTTPSignatureRejected(reason)
+
+		return false, nil, nil
+	}
+
 	err := v.verifier().Verify(req)
 	if err != nil {
 		logger.Infof("Signature verification failed for request %s: %s", req.URL, err)
@@ -105,48 +216,280 @@ func (v *Verifier) VerifyRequest(req *http.Request) (bool, *url.URL, error) {
 		return false, nil, fmt.Errorf("get actor [%s]: %w", publicKey.Owner, err)
 	}
 
-	if actor.PublicKey() == nil {
-		logger.Debugf("nil public key on actor [%s] in request %s: %s", actor.ID(), req.URL)
+	if len(actor.PublicKeys()) == 0 {
+		logger.Debugf("nil public key on actor [%s] in request %s", actor.ID(), req.URL)
 
 		return false, nil, nil
 	}
 
-	if actor.PublicKey().ID.String() != publicKey.ID.String() {
-		logger.Debugf("public key [%s] of actor [%s] does not match the provided public key ID [%s] in request %s",
-			actor.PublicKey().ID, actor.ID(), publicKey.ID, req.URL)
+	if findKey(actor, publicKey.ID.String()) == nil {
+		if !v.allowRefresh(publicKey.Owner.String()) {
+			logger.Debugf("Public key [%s] not found on cached actor [%s] and the actor was refreshed recently.",
+				publicKey.ID, actor.ID())
+
+			return false, nil, nil
+		}
+
+		// The actor may have rotated its key since it was cached, so get the latest version of the actor.
+		logger.Debugf("Public key [%s] not found on cached actor [%s]. Refreshing actor.", publicKey.ID, actor.ID())
+
+		actor, err = v.actorRetriever.RefreshActor(publicKey.Owner.URL())
+		if err != nil {
+			return false, nil, fmt.Errorf("refresh actor [%s]: %w", publicKey.Owner, err)
+		}
+	}
+
+	// The actor may advertise multiple keys (e.g. during key rotation) so any of the actor's keys that's
+	// currently valid is accepted.
+	if pk := findKey(actor, publicKey.ID.String()); pk == nil || !pk.IsValid(v.now()) {
+		logger.Debugf("public key(s) of actor [%s] do not include a valid key with the provided ID [%s] in request %s",
+			actor.ID(), publicKey.ID, req.URL)
 
 		return false, nil, nil
 	}
 
+	if v.replayCache != nil && req.Method != http.MethodGet && req.Method != http.MethodHead {
+		// GET requests aren't checked since identical requests that are made within the same second
+		// may legitimately have the same signature.
+		replayed, err := v.replayCache.Add(replayKey(req, keyID))
+		if err != nil {
+			return false, nil, fmt.Errorf("check replay cache: %w", err)
+		}
+
+		if replayed {
+			logger.Infof("Rejecting replayed request %s from actor [%s]", req.URL, actor.ID())
+
+			v.metrics.HTTPSignatureRejected(RejectReasonReplay)
+
+			return false, nil, nil
+		}
+	}
+
 	logger.Debugf("Successfully verified signature in header. Actor [%s]", actor.ID())
 
 	return true, actor.ID().URL(), nil
 }
 
+// allowRefresh returns true if the given actor may be refreshed, i.e. the actor wasn't refreshed within the
+// minimum refresh interval. If true is returned then the actor is recorded as refreshed.
+func (v *Verifier) allowRefresh(actorIRI string) bool {
+	if v.refreshedActors == nil {
+		return true
+	}
+
+	v.refreshMutex.Lock()
+	defer v.refreshMutex.Unlock()
+
+	if _, err := v.refreshedActors.Get(actorIRI); err == nil {
+		return false
+	}
+
+	if err := v.refreshedActors.Set(actorIRI, struct{}{}); err != nil {
+		// Shouldn't happen since a loader function isn't used.
+		logger.Warnf("Error caching refreshed actor [%s]: %s", actorIRI, err)
+	}
+
+	return true
+}
+
+// checkHeaders checks the Date and Digest headers of the request. If a check fails then the reason
+// and an error are returned.
+func (v *Verifier) checkHeaders(req *http.Request) (string, error) {
+	signedHeaders := strings.Fields(strings.ToLower(getSignatureHeaderParam(req, "headers")))
+
+	if v.maxClockSkew > 0 {
+		if err := v.checkDate(req, signedHeaders); err != nil {
+			return RejectReasonClockSkew, err
+		}
+	}
+
+	if v.requireDigest {
+		if err := v.checkDigest(req, signedHeaders); err != nil {
+			return RejectReasonDigest, err
+		}
+	}
+
+	return "", nil
+}
+
+func (v *Verifier) checkDate(req *http.Request, signedHeaders []string) error {
+	if !contains(signedHeaders, strings.ToLower(dateHeader)) {
+		return errors.New("the Date header is not signed")
+	}
+
+	date, err := http.ParseTime(req.Header.Get(dateHeader))
+	if err != nil {
+		return fmt.Errorf("invalid Date header: %w", err)
+	}
+
+	skew := v.now().Sub(date)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	if skew > v.maxClockSkew {
+		return fmt.Errorf("the Date header [%s] is outside of the maximum clock skew [%s]",
+			req.Header.Get(dateHeader), v.maxClockSkew)
+	}
+
+	return nil
+}
+
+// checkDigest ensures that, if the request has a body, the Digest header is signed and that it matches the body.
+func (v *Verifier) checkDigest(req *http.Request, signedHeaders []string) error {
+	if req.Body == nil {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, v.maxBodySize+1))
+	if err != nil {
+		return fmt.Errorf("read body: %w", err)
+	}
+
+	if int64(len(body)) > v.maxBodySize {
+		return fmt.Errorf("request body exceeds %d bytes", v.maxBodySize)
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if len(body) == 0 {
+		return nil
+	}
+
+	if !contains(signedHeaders, strings.ToLower(digestHeader)) {
+		return errors.New("the Digest header is not signed")
+	}
+
+	var verified bool
+
+	// The Digest header may contain multiple comma-separated digests, e.g. "SHA-256=xxx,SHA-512=yyy".
+	for _, d := range strings.Split(req.Header.Get(digestHeader), ",") {
+		const numParts = 2
+
+		parts := strings.SplitN(strings.TrimSpace(d), "=", numParts)
+		if len(parts) != numParts {
+			return fmt.Errorf("invalid Digest header [%s]", d)
+		}
+
+		var h hash.Hash
+
+		switch strings.ToUpper(parts[0]) {
+		case "SHA-256":
+			h = sha256.New()
+		case "SHA-512":
+			h = sha512.New()
+		default:
+			continue
+		}
+
+		h.Write(body) //nolint:errcheck
+
+		if base64.StdEncoding.EncodeToString(h.Sum(nil)) != parts[1] {
+			return fmt.Errorf("the %s digest does not match the body", parts[0])
+		}
+
+		verified = true
+	}
+
+	if !verified {
+		return fmt.Errorf("no supported digest in Digest header [%s]", req.Header.Get(digestHeader))
+	}
+
+	return nil
+}
+
+// replayKey returns the key under which the request is recorded in the replay cache. The key is made up of the
+// key ID and the values of the signed headers (typically (request-target), Date, Digest and Host) rather than the
+// raw Signature header, since the Signature header may be altered without invalidating the signature, for example by
+// reordering its parameters or by re-encoding the signature value.
+func replayKey(req *http.Request, keyID string) string {
+	signedHeaders := strings.Fields(strings.ToLower(getSignatureHeaderParam(req, "headers")))
+	if len(signedHeaders) == 0 {
+		// If the headers parameter is not specified then only the Date header is signed.
+		signedHeaders = []string{strings.ToLower(dateHeader)}
+	}
+
+	key := &strings.Builder{}
+
+	key.WriteString(keyID)
+
+	for _, name := range signedHeaders {
+		key.WriteString("\n")
+		key.WriteString(name)
+		key.WriteString(": ")
+		key.WriteString(signedHeaderValue(req, name))
+	}
+
+	return key.String()
+}
+
+func signedHeaderValue(req *http.Request, name string) string {
+	switch {
+	case name == requestTarget:
+		return strings.ToLower(req.Method) + " " + req.URL.RequestURI()
+	case strings.HasPrefix(name, "("):
+		// Other pseudo-headers, e.g. (created) and (expires), are parameters of the Signature header.
+		return getSignatureHeaderParam(req, strings.Trim(name, "()"))
+	case name == strings.ToLower(hostHeader) && req.Header.Get(hostHeader) == "":
+		// The Host header of an incoming request is removed from the header map by the HTTP server.
+		return req.Host
+	default:
+		return strings.Join(req.Header.Values(name), ", ")
+	}
+}
+
 func getKeyIDFromSignatureHeader(req *http.Request) string {
-	signatureHeader, ok := req.Header["Signature"]
-	if !ok || len(signatureHeader) == 0 {
+	return getSignatureHeaderParam(req, "keyId")
+}
+
+func getSignatureHeaderParam(req *http.Request, name string) string {
+	values, ok := req.Header[signatureHeader]
+	if !ok || len(values) == 0 {
 		logger.Debugf("'Signature' not found in request header for request %s", req.URL)
 
 		return ""
 	}
 
-	var keyID string
+	var value string
 
 	const kvLength = 2
 
-	for _, v := range signatureHeader {
+	for _, v := range values {
 		for _, kv := range strings.Split(v, ",") {
-			parts := strings.Split(kv, "=")
+			parts := strings.SplitN(kv, "=", kvLength)
 			if len(parts) != kvLength {
 				continue
 			}
 
-			if parts[0] == "keyId" {
-				keyID = strings.ReplaceAll(parts[1], `"`, "")
+			if strings.TrimSpace(parts[0]) == name {
+				value = strings.ReplaceAll(strings.TrimSpace(parts[1]), `"`, "")
 			}
 		}
 	}
 
-	return keyID
+	return value
+}
+
+func findKey(actor *vocab.ActorType, keyID string) *vocab.PublicKeyType {
+	for _, pk := range actor.PublicKeys() {
+		if pk != nil && pk.ID != nil && pk.ID.String() == keyID {
+			return pk
+		}
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
 }
+
+type noOpMetrics struct{}
+
+func (m *noOpMetrics) HTTPSignatureRejected(string) {}
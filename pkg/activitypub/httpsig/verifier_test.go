@@ -10,12 +10,22 @@ import (
 	"bytes"
 	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/bluele/gcache"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	mockcrypto "github.com/hyperledger/aries-framework-go/pkg/mock/crypto"
 	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
 	"github.com/stretchr/testify/require"
@@ -51,6 +61,12 @@ func TestNewVerifier(t *testing.T) {
 
 	v := NewVerifier(retriever, &mockcrypto.Crypto{}, &mockkms.KeyManager{})
 	require.NotNil(t, v)
+	require.Equal(t, defaultMinActorRefreshInterval, v.minActorRefreshInterval)
+	require.NotNil(t, v.refreshedActors)
+
+	v = NewVerifier(retriever, &mockcrypto.Crypto{}, &mockkms.KeyManager{}, WithMinActorRefreshInterval(0))
+	require.NotNil(t, v)
+	require.Nil(t, v.refreshedActors)
 }
 
 func TestVerifier_VerifyRequest(t *testing.T) {
@@ -84,6 +100,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		v := &Verifier{
 			actorRetriever: retriever,
 			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:            time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -119,6 +136,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		v := &Verifier{
 			actorRetriever: retriever,
 			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:            time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -136,6 +154,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		v := &Verifier{
 			actorRetriever: retriever,
 			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:            time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -153,6 +172,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		v := &Verifier{
 			actorRetriever: retriever,
 			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:            time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -171,6 +191,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		v := &Verifier{
 			actorRetriever: servicemocks.NewActorRetriever().WithPublicKey(publicKey),
 			verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:            time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -191,6 +212,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 				WithPublicKey(publicKey).
 				WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(nil))),
 			verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:      time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -216,6 +238,7 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 				WithPublicKey(publicKey).
 				WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(actorPublicKey))),
 			verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:      time.Now,
 		}
 
 		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
@@ -228,6 +251,460 @@ func TestVerifier_VerifyRequest(t *testing.T) {
 		require.False(t, ok)
 		require.Nil(t, actorID)
 	})
+
+	t.Run("Rotated key", func(t *testing.T) {
+		created := time.Now().Add(-time.Hour)
+		expires := time.Now().Add(time.Hour)
+
+		newPublicKey := vocab.NewPublicKey(
+			vocab.WithID(testutil.NewMockID(actorIRI, "/keys/key-1")),
+			vocab.WithOwner(actorIRI),
+			vocab.WithPublicKeyPem(string(pubKeyPem)),
+			vocab.WithCreated(&created),
+		)
+
+		oldPublicKey := vocab.NewPublicKey(
+			vocab.WithID(pubKeyIRI),
+			vocab.WithOwner(actorIRI),
+			vocab.WithPublicKeyPem(string(pubKeyPem)),
+			vocab.WithExpires(&expires),
+		)
+
+		v := &Verifier{
+			actorRetriever: servicemocks.NewActorRetriever().
+				WithPublicKey(publicKey).
+				WithActor(aptestutil.NewMockService(actorIRI,
+					aptestutil.WithPublicKey(newPublicKey), aptestutil.WithPublicKeys(oldPublicKey))),
+			verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
+			now:      time.Now,
+		}
+
+		t.Run("Old key still valid", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+			require.NoError(t, err)
+
+			require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+			ok, actorID, err := v.VerifyRequest(req)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, actorIRI.String(), actorID.String())
+		})
+
+		t.Run("Old key expired", func(t *testing.T) {
+			v.now = func() time.Time { return expires.Add(time.Second) }
+
+			req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+			require.NoError(t, err)
+
+			require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+			ok, actorID, err := v.VerifyRequest(req)
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.Nil(t, actorID)
+		})
+
+		t.Run("Stale actor is refreshed", func(t *testing.T) {
+			v := &Verifier{
+				actorRetriever: &staleActorRetriever{
+					ActorRetriever: servicemocks.NewActorRetriever().
+						WithPublicKey(newPublicKey).
+						WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(newPublicKey))),
+					staleActor: aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)),
+				},
+				verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
+				now:      time.Now,
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+			require.NoError(t, err)
+
+			require.NoError(t, signer.SignRequest(newPublicKey.ID.String(), req))
+
+			ok, actorID, err := v.VerifyRequest(req)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, actorIRI.String(), actorID.String())
+		})
+
+		t.Run("Actor refresh is rate limited", func(t *testing.T) {
+			retriever := &staleActorRetriever{
+				ActorRetriever: servicemocks.NewActorRetriever().
+					WithPublicKey(newPublicKey).
+					WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey))),
+				staleActor: aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)),
+			}
+
+			clock := gcache.NewFakeClock()
+
+			v := &Verifier{
+				actorRetriever: retriever,
+				verifier:       func() verifier { return &mocks.HTTPSignatureVerifier{} },
+				now:            time.Now,
+				refreshedActors: gcache.New(refreshedActorsCacheSize).LRU().
+					Expiration(defaultMinActorRefreshInterval).Clock(clock).Build(),
+			}
+
+			verify := func() {
+				req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+				require.NoError(t, err)
+
+				require.NoError(t, signer.SignRequest(newPublicKey.ID.String(), req))
+
+				ok, actorID, err := v.VerifyRequest(req)
+				require.NoError(t, err)
+				require.False(t, ok)
+				require.Nil(t, actorID)
+			}
+
+			// The key isn't on the cached actor so the actor is refreshed.
+			verify()
+			require.Equal(t, int32(1), retriever.refreshCount())
+
+			// The actor was refreshed recently so it isn't refreshed again.
+			verify()
+			verify()
+			require.Equal(t, int32(1), retriever.refreshCount())
+
+			clock.Advance(defaultMinActorRefreshInterval + time.Second)
+
+			verify()
+			require.Equal(t, int32(2), retriever.refreshCount())
+		})
+
+		t.Run("Refresh actor error", func(t *testing.T) {
+			v := &Verifier{
+				actorRetriever: &staleActorRetriever{
+					ActorRetriever: servicemocks.NewActorRetriever().WithPublicKey(newPublicKey),
+					staleActor:     aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)),
+				},
+				verifier: func() verifier { return &mocks.HTTPSignatureVerifier{} },
+				now:      time.Now,
+			}
+
+			req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+			require.NoError(t, err)
+
+			require.NoError(t, signer.SignRequest(newPublicKey.ID.String(), req))
+
+			ok, actorID, err := v.VerifyRequest(req)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "refresh actor")
+			require.False(t, ok)
+			require.Nil(t, actorID)
+		})
+	})
+}
+
+// staleActorRetriever returns a stale (cached) version of an actor from GetActor and the
+// latest version from RefreshActor.
+type staleActorRetriever struct {
+	*servicemocks.ActorRetriever
+
+	staleActor *vocab.ActorType
+	refreshes  int32
+}
+
+func (m *staleActorRetriever) GetActor(*url.URL) (*vocab.ActorType, error) {
+	return m.staleActor, nil
+}
+
+func (m *staleActorRetriever) RefreshActor(actorIRI *url.URL) (*vocab.ActorType, error) {
+	atomic.AddInt32(&m.refreshes, 1)
+
+	return m.ActorRetriever.RefreshActor(actorIRI)
+}
+
+func (m *staleActorRetriever) refreshCount() int32 {
+	return atomic.LoadInt32(&m.refreshes)
+}
+
+func TestVerifier_CheckHeaders(t *testing.T) {
+	actorIRI := testutil.MustParseURL("https://example.com/services/orb")
+	pubKeyIRI := testutil.NewMockID(actorIRI, "/keys/main-key")
+
+	publicKey := vocab.NewPublicKey(vocab.WithID(pubKeyIRI), vocab.WithOwner(actorIRI))
+
+	retriever := servicemocks.NewActorRetriever().
+		WithPublicKey(publicKey).
+		WithActor(aptestutil.NewMockService(actorIRI, aptestutil.WithPublicKey(publicKey)))
+
+	getSigner := NewSigner(DefaultGetSignerConfig(), &mockcrypto.Crypto{}, &mockkms.KeyManager{}, "123456")
+	postSigner := NewSigner(DefaultPostSignerConfig(), &mockcrypto.Crypto{}, &mockkms.KeyManager{}, "123456")
+
+	payload := []byte("payload")
+
+	newVerifier := func(opts ...Option) (*Verifier, *mockMetrics) {
+		metrics := &mockMetrics{}
+
+		v := NewVerifier(retriever, &mockcrypto.Crypto{}, &mockkms.KeyManager{}, append(opts, WithMetrics(metrics))...)
+		v.verifier = func() verifier { return &mocks.HTTPSignatureVerifier{} }
+
+		return v, metrics
+	}
+
+	newPostRequest := func(t *testing.T) *http.Request {
+		t.Helper()
+
+		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+
+		require.NoError(t, postSigner.SignRequest(publicKey.ID.String(), req))
+
+		return req
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		req := newPostRequest(t)
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Empty(t, metrics.rejected)
+
+		// The body must still be readable.
+		body, err := ioutil.ReadAll(req.Body)
+		require.NoError(t, err)
+		require.Equal(t, payload, body)
+	})
+
+	t.Run("Clock skew", func(t *testing.T) {
+		v, metrics := newVerifier(WithMaxClockSkew(time.Minute))
+
+		v.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+
+		ok, _, err := v.VerifyRequest(newPostRequest(t))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		v.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+
+		ok, _, err = v.VerifyRequest(newPostRequest(t))
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, []string{RejectReasonClockSkew, RejectReasonClockSkew}, metrics.rejected)
+
+		// Clock skew check disabled.
+		v.maxClockSkew = 0
+
+		ok, _, err = v.VerifyRequest(newPostRequest(t))
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Date not signed", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		signer := NewSigner(SignerConfig{Headers: []string{"(request-target)", "Digest"}},
+			&mockcrypto.Crypto{}, &mockkms.KeyManager{}, "123456")
+
+		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+
+		require.NoError(t, signer.SignRequest(publicKey.ID.String(), req))
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonClockSkew}, metrics.rejected)
+	})
+
+	t.Run("Invalid date", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		req := newPostRequest(t)
+		req.Header.Set(dateHeader, "invalid")
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonClockSkew}, metrics.rejected)
+	})
+
+	t.Run("Digest not signed", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		req, err := http.NewRequest(http.MethodPost, "https://domain1.com", bytes.NewBuffer(payload))
+		require.NoError(t, err)
+
+		require.NoError(t, getSigner.SignRequest(publicKey.ID.String(), req))
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonDigest}, metrics.rejected)
+	})
+
+	t.Run("Digest mismatch", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		req := newPostRequest(t)
+		req.Body = ioutil.NopCloser(bytes.NewBufferString("other payload"))
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonDigest}, metrics.rejected)
+	})
+
+	t.Run("Invalid digest", func(t *testing.T) {
+		v, metrics := newVerifier()
+
+		req := newPostRequest(t)
+
+		req.Header.Set(digestHeader, "invalid")
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		req.Header.Set(digestHeader, "MD5=xxx")
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		require.Equal(t, []string{RejectReasonDigest, RejectReasonDigest}, metrics.rejected)
+	})
+
+	t.Run("SHA-256 digest", func(t *testing.T) {
+		v, _ := newVerifier()
+
+		req := newPostRequest(t)
+
+		digest := sha256.Sum256(payload)
+
+		req.Header.Set(digestHeader, "SHA-256="+base64.StdEncoding.EncodeToString(digest[:]))
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("GET request without body", func(t *testing.T) {
+		v, _ := newVerifier()
+
+		req, err := http.NewRequest(http.MethodGet, "https://domain1.com", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, getSigner.SignRequest(publicKey.ID.String(), req))
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		replayCache, err := NewReplayCache(ReplayCacheConfig{Expiry: time.Minute}, mem.NewProvider())
+		require.NoError(t, err)
+
+		v, metrics := newVerifier(WithReplayCache(replayCache))
+
+		req := newPostRequest(t)
+
+		ok, _, err := v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonReplay}, metrics.rejected)
+
+		// Reorder and re-space the parameters of the Signature header.
+		params := strings.Split(req.Header.Get(signatureHeader), ",")
+
+		for i, j := 0, len(params)-1; i < j; i, j = i+1, j-1 {
+			params[i], params[j] = params[j], params[i]
+		}
+
+		req.Header.Set(signatureHeader, strings.Join(params, ", "))
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		// Re-encode the signature.
+		for i, param := range params {
+			if strings.HasPrefix(param, "signature=") {
+				params[i] = `signature="cmUtZW5jb2RlZA=="`
+			}
+		}
+
+		req.Header.Set(signatureHeader, strings.Join(params, ","))
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonReplay, RejectReasonReplay, RejectReasonReplay}, metrics.rejected)
+
+		// A request with different signed content is not a replay.
+		req = newPostRequest(t)
+		req.Header.Set(dateHeader, time.Now().Add(time.Second).UTC().Format(http.TimeFormat))
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		// GET requests aren't checked for replays.
+		req, err = http.NewRequest(http.MethodGet, "https://domain1.com", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, getSigner.SignRequest(publicKey.ID.String(), req))
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		ok, _, err = v.VerifyRequest(req)
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Body too large", func(t *testing.T) {
+		v, metrics := newVerifier()
+		v.maxBodySize = int64(len(payload)) - 1
+
+		ok, _, err := v.VerifyRequest(newPostRequest(t))
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Equal(t, []string{RejectReasonDigest}, metrics.rejected)
+
+		v.maxBodySize = int64(len(payload))
+
+		ok, _, err = v.VerifyRequest(newPostRequest(t))
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("Replay cache error", func(t *testing.T) {
+		v, _ := newVerifier(WithReplayCache(&mockReplayCache{err: errors.New("injected replay cache error")}))
+
+		ok, _, err := v.VerifyRequest(newPostRequest(t))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected replay cache error")
+		require.False(t, ok)
+	})
+}
+
+type mockMetrics struct {
+	rejected []string
+}
+
+func (m *mockMetrics) HTTPSignatureRejected(reason string) {
+	m.rejected = append(m.rejected, reason)
+}
+
+type mockReplayCache struct {
+	err error
+}
+
+func (m *mockReplayCache) Add(string) (bool, error) {
+	return false, m.err
 }
 
 func getPublicKeyPem(pubKey interface{}) ([]byte, error) {
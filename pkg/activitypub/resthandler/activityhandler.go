@@ -15,6 +15,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 // NewActivity returns a new 'activities/{id}' REST handler that retrieves a single activity by ID.
@@ -176,13 +177,18 @@ func (h *Activities) handleActivitiesPage(rw http.ResponseWriter, req *http.Requ
 			spi.WithSortOrder(spi.SortDescending),
 		)
 	} else {
-		page, err = h.getPage(objectIRI, id, refType,
-			spi.WithPageSize(h.PageSize),
-			spi.WithSortOrder(spi.SortDescending),
-		)
+		page, err = h.getPageAtCursor(objectIRI, id, refType, h.getCursor(req))
 	}
 
 	if err != nil {
+		if orberrors.IsBadRequest(err) {
+			logger.Debugf("[%s] Invalid page request for object IRI [%s]: %s", h.endpoint, objectIRI, err)
+
+			h.writeResponse(rw, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+
 		logger.Errorf("[%s] Error retrieving page for object IRI [%s]: %s",
 			h.endpoint, objectIRI, err)
 
@@ -297,6 +303,64 @@ func (h *Activities) getPage(objectIRI, id *url.URL, refType spi.ReferenceType,
 	), nil
 }
 
+func (h *Activities) getPageAtCursor(objectIRI, id *url.URL, refType spi.ReferenceType,
+	token string) (*vocab.OrderedCollectionPageType, error) {
+	page, err := h.getCursorPage(id, spi.SortDescending, token,
+		func(opts ...spi.QueryOpt) ([]*vocab.ObjectProperty, []string, int, error) {
+			return h.queryActivities(objectIRI, refType, opts...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return vocab.NewOrderedCollectionPage(page.items,
+		vocab.WithContext(vocab.ContextActivityStreams),
+		vocab.WithID(page.id),
+		vocab.WithPrev(page.prev),
+		vocab.WithNext(page.next),
+		vocab.WithTotalItems(page.totalItems),
+	), nil
+}
+
+func (h *Activities) queryActivities(objectIRI *url.URL, refType spi.ReferenceType,
+	opts ...spi.QueryOpt) ([]*vocab.ObjectProperty, []string, int, error) {
+	it, err := h.activityStore.QueryActivities(
+		spi.NewCriteria(
+			spi.WithReferenceType(refType),
+			spi.WithObjectIRI(objectIRI),
+		), opts...,
+	)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	defer func() {
+		err = it.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	activities, cursors, err := storeutil.ReadActivitiesWithCursors(it, storeutil.GetQueryOptions(opts...).PageSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	items := make([]*vocab.ObjectProperty, len(activities))
+
+	for i, activity := range activities {
+		items[i] = vocab.NewObjectProperty(vocab.WithActivity(activity))
+	}
+
+	totalItems, err := it.TotalItems()
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to get total items from activity query: %w", err)
+	}
+
+	return items, cursors, totalItems, nil
+}
+
 // Activity implements a REST handler that retrieves a single activity by ID.
 type Activity struct {
 	*handler
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resthandler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -587,6 +588,73 @@ func TestShares_Handler(t *testing.T) {
 	})
 }
 
+func TestActivities_CursorPaging(t *testing.T) {
+	activityStore := memstore.New("")
+
+	verifier := &mocks.SignatureVerifier{}
+	verifier.VerifyRequestReturns(true, serviceIRI, nil)
+
+	activities := newMockCreateActivities(6)
+
+	for _, activity := range activities {
+		require.NoError(t, activityStore.AddActivity(activity))
+		require.NoError(t, activityStore.AddReference(spi.Inbox, serviceIRI, activity.ID().URL()))
+	}
+
+	h := NewInbox(&Config{ObjectIRI: serviceIRI, PageSize: 4}, activityStore, verifier)
+	require.NotNil(t, h)
+
+	page := getOrderedCollectionPage(t, h.handle, inboxURL+"?page=true")
+	require.Len(t, page.Items(), 4)
+	require.Equal(t, activities[5].ID().String(), page.Items()[0].Object().ID().String())
+	require.Nil(t, page.Prev())
+	require.NotNil(t, page.Next())
+
+	page = getOrderedCollectionPage(t, h.handle, page.Next().String())
+	require.Len(t, page.Items(), 2)
+	require.Equal(t, activities[1].ID().String(), page.Items()[0].Object().ID().String())
+	require.Equal(t, activities[0].ID().String(), page.Items()[1].Object().ID().String())
+	require.NotNil(t, page.Prev())
+	require.Nil(t, page.Next())
+
+	page = getOrderedCollectionPage(t, h.handle, page.Prev().String())
+	require.Len(t, page.Items(), 4)
+	require.Equal(t, activities[5].ID().String(), page.Items()[0].Object().ID().String())
+	require.Equal(t, activities[2].ID().String(), page.Items()[3].Object().ID().String())
+	require.Nil(t, page.Prev())
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, inboxURL+"?page=true&cursor=xxx", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func getOrderedCollectionPage(t *testing.T, handle http.HandlerFunc,
+	target string) *vocab.OrderedCollectionPageType {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	handle(rw, httptest.NewRequest(http.MethodGet, target, nil))
+
+	result := rw.Result()
+	require.Equal(t, http.StatusOK, result.StatusCode)
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	page := &vocab.OrderedCollectionPageType{}
+	require.NoError(t, json.Unmarshal(respBytes, page))
+
+	return page
+}
+
 func TestShares_PageHandler(t *testing.T) {
 	const id = "https://sally.example.com/transactions/d607506e-6964-4991-a19f-674952380760"
 
@@ -1070,8 +1138,8 @@ const (
 
 	inboxFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://example1.com/services/orb/inbox?page=true&page-num=4",
-  "next": "https://example1.com/services/orb/inbox?page=true&page-num=3",
+  "id": "https://example1.com/services/orb/inbox?page=true",
+  "next": "https://example1.com/services/orb/inbox?page=true&cursor=bmV4dDoxNg",
   "orderedItems": [
     {
       "@context": "https://www.w3.org/ns/activitystreams",
@@ -1316,9 +1384,9 @@ const (
 	//nolint:lll
 	sharesFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://sally.example.com/services/orb/shares?id=https%3A%2F%2Fsally.example.com%2Ftransactions%2Fd607506e-6964-4991-a19f-674952380760&page=true&page-num=4",
+  "id": "https://sally.example.com/services/orb/shares?id=https%3A%2F%2Fsally.example.com%2Ftransactions%2Fd607506e-6964-4991-a19f-674952380760&page=true",
   "type": "OrderedCollectionPage",
-  "next": "https://sally.example.com/services/orb/shares?id=https%3A%2F%2Fsally.example.com%2Ftransactions%2Fd607506e-6964-4991-a19f-674952380760&page=true&page-num=3",
+  "next": "https://sally.example.com/services/orb/shares?id=https%3A%2F%2Fsally.example.com%2Ftransactions%2Fd607506e-6964-4991-a19f-674952380760&page=true&cursor=bmV4dDoxNg",
   "totalItems": 19,
   "orderedItems": [
     {
@@ -1396,8 +1464,8 @@ const (
 	//nolint:lll
 	likedFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://example1.com/services/orb/liked?page=true&page-num=9",
-  "next": "https://example1.com/services/orb/liked?page=true&page-num=8",
+  "id": "https://example1.com/services/orb/liked?page=true",
+  "next": "https://example1.com/services/orb/liked?page=true&cursor=bmV4dDoxOA",
   "orderedItems": [
     {
       "@context": [
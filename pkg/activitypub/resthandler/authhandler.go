@@ -65,6 +65,11 @@ func NewAuthHandler(cfg *Config, endpoint, method string, s store.Store, verifie
 	return h
 }
 
+// AuthRequired returns true if requests to the endpoint must be authorized, i.e. the endpoint isn't open to everyone.
+func (h *AuthHandler) AuthRequired() bool {
+	return h.tokenVerifier.TokenRequired()
+}
+
 // Authorize authorizes the request, first checking the required bearer token and then, if the bearer token was not
 // provided, the HTTP signature.
 func (h *AuthHandler) Authorize(req *http.Request) (bool, *url.URL, error) {
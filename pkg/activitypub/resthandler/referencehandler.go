@@ -14,6 +14,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 // NewFollowers returns a new 'followers' REST handler that retrieves a service's list of followers.
@@ -152,11 +153,18 @@ func (h *Reference) handleReferencePage(w http.ResponseWriter, req *http.Request
 		page, err = h.getPage(objectIRI, id,
 			spi.WithPageSize(h.PageSize), spi.WithPageNum(pageNum), spi.WithSortOrder(h.sortOrder))
 	} else {
-		page, err = h.getPage(objectIRI, id,
-			spi.WithPageSize(h.PageSize), spi.WithSortOrder(h.sortOrder))
+		page, err = h.getPageAtCursor(objectIRI, id, h.getCursor(req))
 	}
 
 	if err != nil {
+		if orberrors.IsBadRequest(err) {
+			logger.Debugf("[%s] Invalid page request for object IRI [%s]: %s", h.endpoint, objectIRI, err)
+
+			h.writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+
 		logger.Errorf("[%s] Error retrieving page for object IRI [%s]: %s",
 			h.endpoint, objectIRI, err)
 
@@ -268,6 +276,62 @@ func (h *Reference) getPage(objectIRI, id *url.URL, opts ...spi.QueryOpt) (inter
 	), nil
 }
 
+func (h *Reference) getPageAtCursor(objectIRI, id *url.URL, token string) (interface{}, error) {
+	page, err := h.getCursorPage(id, h.sortOrder, token,
+		func(opts ...spi.QueryOpt) ([]*vocab.ObjectProperty, []string, int, error) {
+			return h.queryReferences(objectIRI, opts...)
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.createCollectionPage(page.items,
+		vocab.WithContext(vocab.ContextActivityStreams),
+		vocab.WithID(page.id),
+		vocab.WithPrev(page.prev),
+		vocab.WithNext(page.next),
+		vocab.WithTotalItems(page.totalItems),
+	), nil
+}
+
+func (h *Reference) queryReferences(objectIRI *url.URL,
+	opts ...spi.QueryOpt) ([]*vocab.ObjectProperty, []string, int, error) {
+	it, err := h.activityStore.QueryReferences(
+		h.refType,
+		spi.NewCriteria(spi.WithObjectIRI(objectIRI)),
+		opts...,
+	)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	defer func() {
+		err = it.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	refs, cursors, err := storeutil.ReadReferencesWithCursors(it, storeutil.GetQueryOptions(opts...).PageSize)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	items := make([]*vocab.ObjectProperty, len(refs))
+
+	for i, ref := range refs {
+		items[i] = vocab.NewObjectProperty(vocab.WithIRI(ref))
+	}
+
+	totalItems, err := it.TotalItems()
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to get total items from reference query: %w", err)
+	}
+
+	return items, cursors, totalItems, nil
+}
+
 func createCollection(ordered bool) createCollectionFunc {
 	if ordered {
 		return func(items []*vocab.ObjectProperty, opts ...vocab.Opt) interface{} {
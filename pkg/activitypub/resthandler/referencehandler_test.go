@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resthandler
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -22,6 +23,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/store/ariesstore"
 	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
@@ -401,6 +403,94 @@ func TestFollowers_PageHandler(t *testing.T) {
 	})
 }
 
+func TestFollowers_CursorPaging(t *testing.T) {
+	followers := testutil.NewMockURLs(10, func(i int) string {
+		return fmt.Sprintf("https://example%d.com/services/orb", i+1)
+	})
+
+	activityStore := memstore.New("")
+
+	for _, ref := range followers {
+		require.NoError(t, activityStore.AddReference(spi.Follower, serviceIRI, ref))
+	}
+
+	cfg := &Config{
+		ObjectIRI: serviceIRI,
+		PageSize:  4,
+	}
+
+	verifier := &mocks.SignatureVerifier{}
+	verifier.VerifyRequestReturns(true, serviceIRI, nil)
+
+	h := NewFollowers(cfg, activityStore, verifier)
+	require.NotNil(t, h)
+
+	page := getCollectionPage(t, h.handle, serviceIRI.String()+"/followers?page=true")
+	require.Len(t, page.Items(), 4)
+	require.Equal(t, followers[0].String(), page.Items()[0].IRI().String())
+	require.Nil(t, page.Prev())
+	require.NotNil(t, page.Next())
+
+	// Adding a follower while paging shouldn't shift the items in the next page.
+	require.NoError(t, activityStore.AddReference(spi.Follower, serviceIRI,
+		testutil.MustParseURL("https://example100.com/services/orb")))
+
+	page = getCollectionPage(t, h.handle, page.Next().String())
+	require.Len(t, page.Items(), 4)
+	require.Equal(t, followers[4].String(), page.Items()[0].IRI().String())
+	require.Equal(t, followers[7].String(), page.Items()[3].IRI().String())
+	require.NotNil(t, page.Prev())
+	require.NotNil(t, page.Next())
+
+	prevPage := getCollectionPage(t, h.handle, page.Prev().String())
+	require.Len(t, prevPage.Items(), 4)
+	require.Equal(t, followers[0].String(), prevPage.Items()[0].IRI().String())
+	require.Equal(t, followers[3].String(), prevPage.Items()[3].IRI().String())
+	require.Nil(t, prevPage.Prev())
+	require.NotNil(t, prevPage.Next())
+
+	page = getCollectionPage(t, h.handle, page.Next().String())
+	require.Len(t, page.Items(), 3)
+	require.Equal(t, followers[8].String(), page.Items()[0].IRI().String())
+	require.Equal(t, "https://example100.com/services/orb", page.Items()[2].IRI().String())
+	require.Equal(t, 11, page.TotalItems())
+	require.NotNil(t, page.Prev())
+	require.Nil(t, page.Next())
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		for _, cursor := range []string{"{}", encodeCursor("up", "1"), encodeCursor(cursorNext, "invalid")} {
+			rw := httptest.NewRecorder()
+
+			h.handle(rw, httptest.NewRequest(http.MethodGet,
+				serviceIRI.String()+"/followers?page=true&cursor="+url.QueryEscape(cursor), nil))
+
+			result := rw.Result()
+			require.Equal(t, http.StatusBadRequest, result.StatusCode)
+			require.NoError(t, result.Body.Close())
+		}
+	})
+}
+
+func getCollectionPage(t *testing.T, handle http.HandlerFunc, target string) *vocab.CollectionPageType {
+	t.Helper()
+
+	rw := httptest.NewRecorder()
+
+	handle(rw, httptest.NewRequest(http.MethodGet, target, nil))
+
+	result := rw.Result()
+	require.Equal(t, http.StatusOK, result.StatusCode)
+
+	respBytes, err := ioutil.ReadAll(result.Body)
+	require.NoError(t, err)
+	require.NoError(t, result.Body.Close())
+
+	page := &vocab.CollectionPageType{}
+	require.NoError(t, json.Unmarshal(respBytes, page))
+
+	return page
+}
+
 func TestWitnesses_Handler(t *testing.T) {
 	witnesses := testutil.NewMockURLs(19, func(i int) string {
 		return fmt.Sprintf("https://example%d.com/services/orb", i+1)
@@ -544,10 +634,10 @@ const (
 
 	followersFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://example1.com/services/orb/followers?page=true&page-num=0",
+  "id": "https://example1.com/services/orb/followers?page=true",
   "type": "CollectionPage",
   "totalItems": 19,
-  "next": "https://example1.com/services/orb/followers?page=true&page-num=1",
+  "next": "https://example1.com/services/orb/followers?page=true&cursor=bmV4dDo0",
   "items": [
     "https://example1.com/services/orb",
     "https://example2.com/services/orb",
@@ -603,10 +693,10 @@ const (
 
 	witnessesFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://example1.com/services/orb/witnesses?page=true&page-num=0",
+  "id": "https://example1.com/services/orb/witnesses?page=true",
   "type": "CollectionPage",
   "totalItems": 19,
-  "next": "https://example1.com/services/orb/witnesses?page=true&page-num=1",
+  "next": "https://example1.com/services/orb/witnesses?page=true&cursor=bmV4dDo0",
   "items": [
     "https://example1.com/services/orb",
     "https://example2.com/services/orb",
@@ -641,10 +731,10 @@ const (
 
 	witnessingFirstPageJSON = `{
   "@context": "https://www.w3.org/ns/activitystreams",
-  "id": "https://example1.com/services/orb/witnessing?page=true&page-num=0",
+  "id": "https://example1.com/services/orb/witnessing?page=true",
   "type": "CollectionPage",
   "totalItems": 19,
-  "next": "https://example1.com/services/orb/witnessing?page=true&page-num=1",
+  "next": "https://example1.com/services/orb/witnessing?page=true&cursor=bmV4dDo0",
   "items": [
     "https://example1.com/services/orb",
     "https://example2.com/services/orb",
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resthandler
 
 import (
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -19,6 +20,7 @@ import (
 
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/httpserver/auth"
 )
 
@@ -52,6 +54,7 @@ const (
 const (
 	pageParam    = "page"
 	pageNumParam = "page-num"
+	cursorParam  = "cursor"
 	idParam      = "id"
 
 	authHeader  = "Authorization"
@@ -202,6 +205,127 @@ func (h *handler) getIDPrevNextURL(objectIRI fmt.Stringer, totalItems int,
 	return pageURI, prevURL, nextURL, nil
 }
 
+// cursorDirection indicates whether a cursor points to the items that follow (next) or that precede (prev)
+// the item at the cursor.
+type cursorDirection string
+
+const (
+	cursorNext cursorDirection = "next"
+	cursorPrev cursorDirection = "prev"
+)
+
+type queryPageFunc func(opts ...spi.QueryOpt) ([]*vocab.ObjectProperty, []string, int, error)
+
+// cursorPage contains the items of a page that was retrieved using a cursor along with
+// the IDs of the page and of the previous and next pages.
+type cursorPage struct {
+	items      []*vocab.ObjectProperty
+	totalItems int
+	id         *url.URL
+	prev       *url.URL
+	next       *url.URL
+}
+
+// getCursorPage returns a page of items starting after the item at the cursor contained in the given token.
+// If the token is empty then the first page is returned. Unlike page numbers, cursors are based on a stable
+// sort key so that the contents of a page don't shift if items are added while a client is paging through
+// the collection.
+func (h *handler) getCursorPage(id *url.URL, sortOrder spi.SortOrder, token string,
+	query queryPageFunc) (*cursorPage, error) {
+	dir, cursor, err := decodeCursor(token)
+	if err != nil {
+		return nil, err
+	}
+
+	order := sortOrder
+	if dir == cursorPrev {
+		order = reverseSortOrder(sortOrder)
+	}
+
+	opts := []spi.QueryOpt{spi.WithPageSize(h.PageSize + 1), spi.WithSortOrder(order)}
+
+	if cursor != "" {
+		opts = append(opts, spi.WithCursor(cursor))
+	}
+
+	// Retrieve one more item than the page size in order to determine if there are more items.
+	items, cursors, totalItems, err := query(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(items) > h.PageSize
+	if hasMore {
+		items = items[:h.PageSize]
+		cursors = cursors[:h.PageSize]
+	}
+
+	if dir == cursorPrev {
+		reverseItems(items, cursors)
+	}
+
+	page := &cursorPage{
+		items:      items,
+		totalItems: totalItems,
+	}
+
+	page.id, err = h.getCursorPageURL(id, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return page, nil
+	}
+
+	hasPrev := cursor != ""
+	hasNext := hasMore
+
+	if dir == cursorPrev {
+		hasPrev, hasNext = hasMore, true
+	}
+
+	if hasPrev {
+		page.prev, err = h.getCursorPageURL(id, encodeCursor(cursorPrev, cursors[0]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if hasNext {
+		page.next, err = h.getCursorPageURL(id, encodeCursor(cursorNext, cursors[len(cursors)-1]))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+func (h *handler) getCursorPageURL(objectIRI fmt.Stringer, token string) (*url.URL, error) {
+	if token == "" {
+		return h.getPageURL(objectIRI, -1)
+	}
+
+	pageID := fmt.Sprintf("%s&%s=%s", h.getPageID(objectIRI, -1), cursorParam, token)
+
+	pageURL, err := url.Parse(pageID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid 'page' URL [%s]: %w", pageID, err)
+	}
+
+	return pageURL, nil
+}
+
+func (h *handler) getCursor(req *http.Request) string {
+	values := h.getParams(req)[cursorParam]
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
 func (h *handler) isPaging(req *http.Request) bool {
 	return h.paramAsBool(req, pageParam)
 }
@@ -246,6 +370,51 @@ func (h *handler) paramAsBool(req *http.Request, param string) bool {
 	return b
 }
 
+// encodeCursor returns an opaque token that contains the given direction and store cursor.
+func encodeCursor(dir cursorDirection, cursor string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%s", dir, cursor)))
+}
+
+func decodeCursor(token string) (cursorDirection, string, error) {
+	if token == "" {
+		return cursorNext, "", nil
+	}
+
+	value, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", "", orberrors.NewBadRequest(fmt.Errorf("invalid cursor [%s]: %w", token, err))
+	}
+
+	parts := strings.SplitN(string(value), ":", 2) //nolint:gomnd
+
+	if len(parts) != 2 || parts[1] == "" { //nolint:gomnd
+		return "", "", orberrors.NewBadRequest(fmt.Errorf("invalid cursor [%s]", token))
+	}
+
+	dir := cursorDirection(parts[0])
+
+	if dir != cursorNext && dir != cursorPrev {
+		return "", "", orberrors.NewBadRequest(fmt.Errorf("invalid cursor direction [%s]", dir))
+	}
+
+	return dir, parts[1], nil
+}
+
+func reverseSortOrder(sortOrder spi.SortOrder) spi.SortOrder {
+	if sortOrder == spi.SortAscending {
+		return spi.SortDescending
+	}
+
+	return spi.SortAscending
+}
+
+func reverseItems(items []*vocab.ObjectProperty, cursors []string) {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+		cursors[i], cursors[j] = cursors[j], cursors[i]
+	}
+}
+
 func getPrevNextAscending(current, first, last int) (int, int) {
 	prev := -1
 	next := -1
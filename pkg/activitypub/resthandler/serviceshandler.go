@@ -18,18 +18,34 @@ import (
 // MainKeyID is the ID of the service's public key.
 const MainKeyID = "main-key"
 
+// PublicKeyProvider provides the currently valid public keys of the service. The first key is
+// the key that's currently used to sign requests.
+type PublicKeyProvider interface {
+	PublicKeys() []*vocab.PublicKeyType
+}
+
+// ServicesOpt is a 'services' REST handler option.
+type ServicesOpt func(h *Services)
+
+// WithPublicKeyProvider sets the provider of the service's public keys. If set then the keys returned by the
+// provider are advertised by the service instead of the static public key, which allows keys to be rotated.
+func WithPublicKeyProvider(provider PublicKeyProvider) ServicesOpt {
+	return func(h *Services) {
+		h.publicKeyProvider = provider
+	}
+}
+
 // Services implements the 'services' REST handler to retrieve a given ActivityPub service (actor).
 type Services struct {
 	*handler
 
-	publicKey *vocab.PublicKeyType
+	publicKey         *vocab.PublicKeyType
+	publicKeyProvider PublicKeyProvider
 }
 
 // NewServices returns a new 'services' REST handler.
-func NewServices(cfg *Config, activityStore spi.Store, publicKey *vocab.PublicKeyType) *Services {
-	h := &Services{
-		publicKey: publicKey,
-	}
+func NewServices(cfg *Config, activityStore spi.Store, publicKey *vocab.PublicKeyType, opts ...ServicesOpt) *Services {
+	h := newServices(publicKey, opts)
 
 	h.handler = newHandler("", cfg, activityStore, h.handle, nil)
 
@@ -37,12 +53,23 @@ func NewServices(cfg *Config, activityStore spi.Store, publicKey *vocab.PublicKe
 }
 
 // NewPublicKeys returns a new public keys REST handler.
-func NewPublicKeys(cfg *Config, activityStore spi.Store, publicKey *vocab.PublicKeyType) *Services {
+func NewPublicKeys(cfg *Config, activityStore spi.Store, publicKey *vocab.PublicKeyType,
+	opts ...ServicesOpt) *Services {
+	h := newServices(publicKey, opts)
+
+	h.handler = newHandler(PublicKeysPath, cfg, activityStore, h.handlePublicKey, nil)
+
+	return h
+}
+
+func newServices(publicKey *vocab.PublicKeyType, opts []ServicesOpt) *Services {
 	h := &Services{
 		publicKey: publicKey,
 	}
 
-	h.handler = newHandler(PublicKeysPath, cfg, activityStore, h.handlePublicKey, nil)
+	for _, opt := range opts {
+		opt(h)
+	}
 
 	return h
 }
@@ -54,7 +81,7 @@ func (h *Services) handle(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	s, err := h.newService()
+	s, err := NewServiceActor(h.ObjectIRI, h.publicKeys()...)
 	if err != nil {
 		logger.Errorf("[%s] Invalid service configuration [%s]: %s", h.endpoint, h.ObjectIRI, err)
 
@@ -92,9 +119,8 @@ func (h *Services) handlePublicKey(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	// Currently only one key is supported. In the future we may wish to have
-	// multiple keys per service.
-	if keyID != MainKeyID {
+	publicKey := h.findPublicKey(keyID)
+	if publicKey == nil {
 		logger.Infof("[%s] Public key [%s] not found for [%s]", h.endpoint, h.ObjectIRI, keyID)
 
 		h.writeResponse(w, http.StatusNotFound, []byte(notFoundResponse))
@@ -102,7 +128,7 @@ func (h *Services) handlePublicKey(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	publicKeyBytes, err := h.marshal(h.publicKey)
+	publicKeyBytes, err := h.marshal(publicKey)
 	if err != nil {
 		logger.Errorf("[%s] Unable to marshal public key [%s]: %s", h.endpoint, h.ObjectIRI, err)
 
@@ -116,54 +142,94 @@ func (h *Services) handlePublicKey(w http.ResponseWriter, req *http.Request) {
 	h.writeResponse(w, http.StatusOK, publicKeyBytes)
 }
 
-func (h *Services) newService() (*vocab.ActorType, error) {
-	inbox, err := newID(h.ObjectIRI, InboxPath)
+func (h *Services) publicKeys() []*vocab.PublicKeyType {
+	if h.publicKeyProvider != nil {
+		if keys := h.publicKeyProvider.PublicKeys(); len(keys) > 0 {
+			return keys
+		}
+	}
+
+	if h.publicKey == nil {
+		return nil
+	}
+
+	return []*vocab.PublicKeyType{h.publicKey}
+}
+
+func (h *Services) findPublicKey(keyID string) *vocab.PublicKeyType {
+	if h.publicKeyProvider == nil {
+		// Only the main key is supported.
+		if keyID != MainKeyID {
+			return nil
+		}
+
+		return h.publicKey
+	}
+
+	keyIRI, err := newID(h.ObjectIRI, "/keys/"+keyID)
+	if err != nil {
+		return nil
+	}
+
+	for _, pk := range h.publicKeys() {
+		if pk.ID != nil && pk.ID.String() == keyIRI.String() {
+			return pk
+		}
+	}
+
+	return nil
+}
+
+// NewServiceActor returns the actor for the service with the given IRI and public keys. The first
+// public key is the service's current key.
+func NewServiceActor(serviceIRI *url.URL, publicKeys ...*vocab.PublicKeyType) (*vocab.ActorType, error) {
+	inbox, err := newID(serviceIRI, InboxPath)
 	if err != nil {
 		return nil, err
 	}
 
-	outbox, err := newID(h.ObjectIRI, OutboxPath)
+	outbox, err := newID(serviceIRI, OutboxPath)
 	if err != nil {
 		return nil, err
 	}
 
-	followers, err := newID(h.ObjectIRI, FollowersPath)
+	followers, err := newID(serviceIRI, FollowersPath)
 	if err != nil {
 		return nil, err
 	}
 
-	following, err := newID(h.ObjectIRI, FollowingPath)
+	following, err := newID(serviceIRI, FollowingPath)
 	if err != nil {
 		return nil, err
 	}
 
-	witnesses, err := newID(h.ObjectIRI, WitnessesPath)
+	witnesses, err := newID(serviceIRI, WitnessesPath)
 	if err != nil {
 		return nil, err
 	}
 
-	witnessing, err := newID(h.ObjectIRI, WitnessingPath)
+	witnessing, err := newID(serviceIRI, WitnessingPath)
 	if err != nil {
 		return nil, err
 	}
 
-	liked, err := newID(h.ObjectIRI, LikedPath)
+	liked, err := newID(serviceIRI, LikedPath)
 	if err != nil {
 		return nil, err
 	}
 
-	likes, err := newID(h.ObjectIRI, LikesPath)
+	likes, err := newID(serviceIRI, LikesPath)
 	if err != nil {
 		return nil, err
 	}
 
-	shares, err := newID(h.ObjectIRI, SharesPath)
+	shares, err := newID(serviceIRI, SharesPath)
 	if err != nil {
 		return nil, err
 	}
 
-	return vocab.NewService(h.ObjectIRI,
-		vocab.WithPublicKey(h.publicKey),
+	return vocab.NewService(serviceIRI,
+		vocab.WithPublicKeys(publicKeys...),
 		vocab.WithInbox(inbox),
 		vocab.WithOutbox(outbox),
 		vocab.WithFollowers(followers),
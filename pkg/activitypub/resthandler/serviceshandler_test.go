@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package resthandler
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -272,6 +273,94 @@ func TestPublicKeys_Handler(t *testing.T) {
 	})
 }
 
+func TestServices_WithPublicKeyProvider(t *testing.T) {
+	cfg := &Config{
+		BasePath:  basePath,
+		ObjectIRI: serviceIRI,
+	}
+
+	activityStore := memstore.New("")
+
+	newPublicKey := vocab.NewPublicKey(
+		vocab.WithID(testutil.NewMockID(serviceIRI, "/keys/key-2")),
+		vocab.WithOwner(serviceIRI),
+		vocab.WithPublicKeyPem(keyPem),
+	)
+
+	provider := &mockPublicKeyProvider{keys: []*vocab.PublicKeyType{newPublicKey, publicKey}}
+
+	t.Run("Service", func(t *testing.T) {
+		h := NewServices(cfg, activityStore, publicKey, WithPublicKeyProvider(provider))
+		require.NotNil(t, h)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, serviceIRI.String(), nil)
+
+		h.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		actor := &vocab.ActorType{}
+		require.NoError(t, json.NewDecoder(result.Body).Decode(actor))
+		require.NoError(t, result.Body.Close())
+
+		require.Len(t, actor.PublicKeys(), 2)
+		require.Equal(t, newPublicKey.ID.String(), actor.PublicKey().ID.String())
+	})
+
+	t.Run("Public keys", func(t *testing.T) {
+		h := NewPublicKeys(cfg, activityStore, publicKey, WithPublicKeyProvider(provider))
+		require.NotNil(t, h)
+
+		for _, keyID := range []string{"key-2", MainKeyID} {
+			rw := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, serviceIRI.String(), nil)
+
+			restoreID := setIDParam(keyID)
+
+			h.handlePublicKey(rw, req)
+
+			restoreID()
+
+			result := rw.Result()
+			require.Equal(t, http.StatusOK, result.StatusCode)
+
+			pk := &vocab.PublicKeyType{}
+			require.NoError(t, json.NewDecoder(result.Body).Decode(pk))
+			require.NoError(t, result.Body.Close())
+			require.Equal(t, testutil.NewMockID(serviceIRI, "/keys/"+keyID).String(), pk.ID.String())
+		}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, serviceIRI.String(), nil)
+
+		restoreID := setIDParam("key-3")
+		defer restoreID()
+
+		h.handlePublicKey(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("No keys from provider -> static key", func(t *testing.T) {
+		h := NewServices(cfg, activityStore, publicKey, WithPublicKeyProvider(&mockPublicKeyProvider{}))
+		require.NotNil(t, h)
+
+		require.Equal(t, []*vocab.PublicKeyType{publicKey}, h.publicKeys())
+	})
+}
+
+type mockPublicKeyProvider struct {
+	keys []*vocab.PublicKeyType
+}
+
+func (m *mockPublicKeyProvider) PublicKeys() []*vocab.PublicKeyType {
+	return m.keys
+}
+
 const (
 	serviceJSON = `{
   "@context": [
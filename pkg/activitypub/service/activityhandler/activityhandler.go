@@ -17,6 +17,7 @@ import (
 
 	service "github.com/trustbloc/orb/pkg/activitypub/service/spi"
 	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/lifecycle"
@@ -48,6 +49,7 @@ type Config struct {
 
 type activityPubClient interface {
 	GetActor(iri *url.URL) (*vocab.ActorType, error)
+	RefreshActor(iri *url.URL) (*vocab.ActorType, error)
 }
 
 type undoFunc func(activity *vocab.ActivityType) error
@@ -63,10 +65,11 @@ type handler struct {
 	undoFollow        undoFunc
 	undoInviteWitness undoFunc
 	undoLike          undoFunc
+	undoBlock         undoFunc
 }
 
 func newHandler(cfg *Config, s store.Store, activityPubClient activityPubClient,
-	undoFollow, undoInviteWitness, undoLike undoFunc) *handler {
+	undoFollow, undoInviteWitness, undoLike, undoBlock undoFunc) *handler {
 	if cfg.BufferSize == 0 {
 		cfg.BufferSize = defaultBufferSize
 	}
@@ -82,6 +85,7 @@ func newHandler(cfg *Config, s store.Store, activityPubClient activityPubClient,
 		undoFollow:        undoFollow,
 		undoInviteWitness: undoInviteWitness,
 		undoLike:          undoLike,
+		undoBlock:         undoBlock,
 	}
 
 	h.Lifecycle = lifecycle.New(cfg.ServiceName, lifecycle.WithStop(h.stop))
@@ -167,6 +171,9 @@ func (h *handler) undoActivity(activity *vocab.ActivityType) error {
 	case activity.Type().Is(vocab.TypeLike):
 		return h.undoLike(activity)
 
+	case activity.Type().Is(vocab.TypeBlock):
+		return h.undoBlock(activity)
+
 	default:
 		return fmt.Errorf("undo of type %s is not supported", activity.Type())
 	}
@@ -189,7 +196,70 @@ func defaultOptions() *service.Handlers {
 		WitnessInvitationAuth:   &acceptAllActorsAuth{},
 		ProofHandler:            &noOpProofHandler{},
 		AnchorEventAckHandler:   &noOpAnchorEventAcknowledgementHandler{},
+		SignedTreeHeadHandler:   &noOpSignedTreeHeadHandler{},
+	}
+}
+
+// deleteActorReferences deletes all references of the given types from the local service's
+// collections that match the given IRI. The IRI may either be an actor IRI or a domain
+// (e.g. https://orb.domain1.com), in which case all actors in that domain are removed.
+func (h *handler) deleteActorReferences(iri *url.URL, refTypes ...store.ReferenceType) error {
+	for _, refType := range refTypes {
+		refs, err := h.queryReferences(refType, h.ServiceIRI)
+		if err != nil {
+			return err
+		}
+
+		for _, ref := range refs {
+			if !matchesIRI(iri, ref) {
+				continue
+			}
+
+			if err := h.store.DeleteReference(refType, h.ServiceIRI, ref); err != nil {
+				return orberrors.NewTransient(fmt.Errorf("unable to delete %s from %s's collection of %s: %w",
+					ref, h.ServiceIRI, refType, err))
+			}
+
+			logger.Debugf("[%s] %s was deleted from %s's collection of %s",
+				h.ServiceName, ref, h.ServiceIRI, refType)
+		}
 	}
+
+	return nil
+}
+
+func (h *handler) queryReferences(refType store.ReferenceType, objectIRI *url.URL) ([]*url.URL, error) {
+	it, err := h.store.QueryReferences(refType, store.NewCriteria(store.WithObjectIRI(objectIRI)))
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query references: %w", err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	refs, err := storeutil.ReadReferences(it, -1)
+	if err != nil {
+		return nil, fmt.Errorf("read references: %w", err)
+	}
+
+	return refs, nil
+}
+
+// matchesIRI returns true if the given IRI is the same as the given pattern IRI. If the pattern
+// IRI has no path (i.e. it's a domain) then true is returned if the IRI is in the same domain.
+func matchesIRI(pattern, iri *url.URL) bool {
+	if pattern.String() == iri.String() {
+		return true
+	}
+
+	if pattern.Path != "" && pattern.Path != "/" {
+		return false
+	}
+
+	return pattern.Scheme == iri.Scheme && pattern.Host == iri.Host
 }
 
 func containsIRI(iris []*url.URL, iri fmt.Stringer) bool {
@@ -41,7 +41,10 @@ func TestNewInbox(t *testing.T) {
 		BufferSize:  100,
 	}
 
-	h := NewInbox(cfg, &mocks.ActivityStore{}, &mocks.Outbox{}, mocks.NewActorRetriever())
+	as := &mocks.ActivityStore{}
+	as.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
+
+	h := NewInbox(cfg, as, &mocks.Outbox{}, mocks.NewActorRetriever())
 	require.NotNil(t, h)
 
 	require.Equal(t, lifecycle.StateNotStarted, h.State())
@@ -85,7 +88,10 @@ func TestHandler_HandleUnsupportedActivity(t *testing.T) {
 		ServiceIRI:  testutil.MustParseURL("http://localhost:8301/services/service1"),
 	}
 
-	h := NewInbox(cfg, &mocks.ActivityStore{}, &mocks.Outbox{}, mocks.NewActorRetriever())
+	as := &mocks.ActivityStore{}
+	as.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
+
+	h := NewInbox(cfg, as, &mocks.Outbox{}, mocks.NewActorRetriever())
 	require.NotNil(t, h)
 
 	h.Start()
@@ -894,6 +900,7 @@ func TestHandler_HandleAcceptActivityValidationError(t *testing.T) {
 
 	ob := mocks.NewOutbox()
 	as := &mocks.ActivityStore{}
+	as.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 
 	h := NewInbox(cfg, as, ob, mocks.NewActorRetriever())
 	require.NotNil(t, h)
@@ -980,6 +987,7 @@ func TestHandler_HandleAcceptActivityError(t *testing.T) {
 
 	ob := mocks.NewOutbox()
 	as := &mocks.ActivityStore{}
+	as.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 
 	h := NewInbox(cfg, as, ob, mocks.NewActorRetriever())
 	require.NotNil(t, h)
@@ -1518,6 +1526,90 @@ func TestHandler_HandleAnnounceActivity(t *testing.T) {
 	})
 }
 
+func TestHandler_HandleAnnounceSignedTreeHeadActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	logURL := testutil.MustParseURL("https://vct.example.com/maple2021")
+
+	cfg := &Config{
+		ServiceName: "service1",
+		ServiceIRI:  service1IRI,
+	}
+
+	newAnnounce := func(sth *vocab.SignedTreeHeadType) *vocab.ActivityType {
+		published := time.Now()
+
+		return vocab.NewAnnounceActivity(
+			vocab.NewObjectProperty(vocab.WithSignedTreeHead(sth)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+			vocab.WithPublishedTime(&published),
+		)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		sthHandler := mocks.NewSignedTreeHeadHandler()
+
+		h := NewInbox(cfg, memstore.New(cfg.ServiceName), &mocks.Outbox{}, mocks.NewActorRetriever(),
+			spi.WithSignedTreeHeadHandler(sthHandler))
+		require.NotNil(t, h)
+
+		h.Start()
+		defer h.Stop()
+
+		subscriber := newMockActivitySubscriber(h.Subscribe())
+		go subscriber.Listen()
+
+		announce := newAnnounce(vocab.NewSignedTreeHead(logURL, 10, 1000, []byte("root"), []byte("sig")))
+
+		require.NoError(t, h.HandleActivity(announce))
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.NotNil(t, subscriber.Activity(announce.ID().URL()))
+
+		sths := sthHandler.SignedTreeHeads()
+		require.Len(t, sths, 1)
+		require.Equal(t, logURL.String(), sths[0].Log().String())
+		require.Equal(t, uint64(10), sths[0].TreeSize())
+	})
+
+	t.Run("No log", func(t *testing.T) {
+		sthHandler := mocks.NewSignedTreeHeadHandler()
+
+		h := NewInbox(cfg, memstore.New(cfg.ServiceName), &mocks.Outbox{}, mocks.NewActorRetriever(),
+			spi.WithSignedTreeHeadHandler(sthHandler))
+		require.NotNil(t, h)
+
+		err := h.HandleActivity(newAnnounce(vocab.NewSignedTreeHead(nil, 10, 1000, []byte("root"), nil)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "log is required in signed tree head")
+		require.Empty(t, sthHandler.SignedTreeHeads())
+	})
+
+	t.Run("Handler error", func(t *testing.T) {
+		errExpected := errors.New("injected handler error")
+
+		h := NewInbox(cfg, memstore.New(cfg.ServiceName), &mocks.Outbox{}, mocks.NewActorRetriever(),
+			spi.WithSignedTreeHeadHandler(mocks.NewSignedTreeHeadHandler().WithError(errExpected)))
+		require.NotNil(t, h)
+
+		err := h.HandleActivity(newAnnounce(vocab.NewSignedTreeHead(logURL, 10, 1000, []byte("root"), nil)))
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Default handler", func(t *testing.T) {
+		h := NewInbox(cfg, memstore.New(cfg.ServiceName), &mocks.Outbox{}, mocks.NewActorRetriever())
+		require.NotNil(t, h)
+
+		require.NoError(t, h.HandleActivity(
+			newAnnounce(vocab.NewSignedTreeHead(logURL, 10, 1000, []byte("root"), nil))),
+		)
+	})
+}
+
 func TestHandler_HandleOfferActivity(t *testing.T) {
 	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
 	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
@@ -2182,6 +2274,7 @@ func TestHandler_HandleUndoFollowActivity(t *testing.T) {
 		errExpected := errors.New("injected storage error")
 
 		s := &mocks.ActivityStore{}
+		s.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 		s.GetActivityReturns(nil, errExpected)
 
 		ob := mocks.NewOutbox().WithError(errExpected)
@@ -2801,6 +2894,7 @@ func TestHandler_AnnounceAnchorCredential(t *testing.T) {
 			errExpected := errors.New("injected store error")
 
 			activityStore := &mocks.ActivityStore{}
+			activityStore.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 			activityStore.AddReferenceReturns(errExpected)
 
 			ob := mocks.NewOutbox().WithActivityID(testutil.NewMockID(service2IRI, "/activities/123456789"))
@@ -2859,6 +2953,7 @@ func TestHandler_AnnounceAnchorCredential(t *testing.T) {
 			errExpected := errors.New("injected store error")
 
 			activityStore := &mocks.ActivityStore{}
+			activityStore.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 			activityStore.AddReferenceReturns(errExpected)
 
 			ob := mocks.NewOutbox().WithActivityID(testutil.NewMockID(service2IRI, "/activities/123456789"))
@@ -3008,6 +3103,7 @@ func TestHandler_InboxHandleLikeActivity(t *testing.T) {
 		errExpected := errors.New("injected store error")
 
 		activityStore := &mocks.ActivityStore{}
+		activityStore.QueryReferencesReturns(memstore.NewReferenceIterator(nil, 0), nil)
 		activityStore.AddReferenceReturns(errExpected)
 
 		h := NewInbox(cfg, activityStore, ob,
@@ -3144,6 +3240,449 @@ func (l *mockActivitySubscriber) Activity(iri fmt.Stringer) *vocab.ActivityType
 
 type stopFunc func()
 
+func TestHandler_HandleUpdateActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+
+	publishedTime := time.Now()
+
+	t.Run("Inbox Update", func(t *testing.T) {
+		cfg := &Config{
+			ServiceName: "inbox1",
+			ServiceIRI:  service1IRI,
+		}
+
+		updatedActor := vocab.NewService(service2IRI, vocab.WithInbox(testutil.NewMockID(service2IRI, "/inbox2")))
+
+		ibHandler := NewInbox(cfg, memstore.New(cfg.ServiceName), mocks.NewOutbox(),
+			mocks.NewActorRetriever().WithActor(updatedActor))
+		require.NotNil(t, ibHandler)
+
+		require.NoError(t, ibHandler.store.PutActor(vocab.NewService(service2IRI)))
+
+		t.Run("Success", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(vocab.NewService(service2IRI))),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+				vocab.WithPublishedTime(&publishedTime),
+			)
+
+			require.NoError(t, ibHandler.HandleActivity(update))
+
+			actor, err := ibHandler.store.GetActor(service2IRI)
+			require.NoError(t, err)
+			require.NotNil(t, actor.Inbox())
+			require.Equal(t, updatedActor.Inbox().String(), actor.Inbox().String())
+		})
+
+		t.Run("Object not the same as actor", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(vocab.NewService(service3IRI))),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "is not the same as the actor")
+		})
+
+		t.Run("No object", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "object must be an actor or an actor IRI")
+		})
+
+		t.Run("Refresh actor error", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+				vocab.WithID(newActivityID(service3IRI)),
+				vocab.WithActor(service3IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(update)
+			require.Error(t, err)
+			require.True(t, orberrors.IsTransient(err))
+			require.Contains(t, err.Error(), "refresh actor")
+		})
+	})
+
+	t.Run("Outbox Update", func(t *testing.T) {
+		_, obHandler, _, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+		defer stop()
+
+		t.Run("Success", func(t *testing.T) {
+			actor := vocab.NewService(service2IRI, vocab.WithInbox(testutil.NewMockID(service2IRI, "/inbox2")))
+
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(actor)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(vocab.PublicIRI),
+			)
+
+			require.NoError(t, obHandler.HandleActivity(update))
+
+			a, err := obHandler.store.GetActor(service2IRI)
+			require.NoError(t, err)
+			require.Equal(t, actor.Inbox().String(), a.Inbox().String())
+		})
+
+		t.Run("No actor", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			)
+
+			err := obHandler.HandleActivity(update)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "no actor specified")
+		})
+
+		t.Run("Not local service", func(t *testing.T) {
+			update := vocab.NewUpdateActivity(
+				vocab.NewObjectProperty(vocab.WithActorObject(vocab.NewService(service3IRI))),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			)
+
+			err := obHandler.HandleActivity(update)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "this service is not the object of the 'Update' activity")
+		})
+	})
+}
+
+func TestHandler_HandleDeleteActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+
+	ibHandler, obHandler, ibSubscriber, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+	defer stop()
+
+	t.Run("Inbox Delete", func(t *testing.T) {
+		refTypes := []store.ReferenceType{store.Follower, store.Following, store.Witness, store.Witnessing}
+
+		for _, refType := range refTypes {
+			require.NoError(t, ibHandler.store.AddReference(refType, service1IRI, service2IRI))
+			require.NoError(t, ibHandler.store.AddReference(refType, service1IRI, service3IRI))
+		}
+
+		t.Run("Success", func(t *testing.T) {
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			require.NoError(t, ibHandler.HandleActivity(del))
+
+			time.Sleep(50 * time.Millisecond)
+
+			require.NotNil(t, ibSubscriber.Activity(del.ID()))
+
+			for _, refType := range refTypes {
+				refs, err := ibHandler.queryReferences(refType, service1IRI)
+				require.NoError(t, err)
+				require.False(t, containsIRI(refs, service2IRI))
+				require.True(t, containsIRI(refs, service3IRI))
+			}
+		})
+
+		t.Run("Object not the same as actor", func(t *testing.T) {
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(del)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "is not the same as the actor")
+
+			refs, err := ibHandler.queryReferences(store.Follower, service1IRI)
+			require.NoError(t, err)
+			require.True(t, containsIRI(refs, service3IRI))
+		})
+
+		t.Run("Query error", func(t *testing.T) {
+			errExpected := errors.New("injected query error")
+
+			s := &mocks.ActivityStore{}
+			s.QueryReferencesReturnsOnCall(0, memstore.NewReferenceIterator(nil, 0), nil)
+			s.QueryReferencesReturnsOnCall(1, nil, errExpected)
+
+			cfg := &Config{
+				ServiceName: "inbox1",
+				ServiceIRI:  service1IRI,
+			}
+
+			h := NewInbox(cfg, s, mocks.NewOutbox(), mocks.NewActorRetriever())
+
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := h.HandleActivity(del)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, errExpected))
+			require.True(t, orberrors.IsTransient(err))
+		})
+	})
+
+	t.Run("Outbox Delete", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(vocab.PublicIRI),
+			)
+
+			require.NoError(t, obHandler.HandleActivity(del))
+		})
+
+		t.Run("No IRI", func(t *testing.T) {
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			)
+
+			err := obHandler.HandleActivity(del)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "no IRI specified")
+		})
+
+		t.Run("Not local service", func(t *testing.T) {
+			del := vocab.NewDeleteActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			)
+
+			err := obHandler.HandleActivity(del)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "this service is not the object of the 'Delete' activity")
+		})
+	})
+}
+
+func TestHandler_HandleBlockActivity(t *testing.T) {
+	service1IRI := testutil.MustParseURL("http://localhost:8301/services/service1")
+	service2IRI := testutil.MustParseURL("http://localhost:8302/services/service2")
+	service3IRI := testutil.MustParseURL("http://localhost:8303/services/service3")
+	domain3IRI := testutil.MustParseURL("http://localhost:8303")
+
+	ibHandler, obHandler, ibSubscriber, _, stop := startInboxOutboxWithMocks(t, service1IRI, service2IRI)
+	defer stop()
+
+	t.Run("Inbox Block", func(t *testing.T) {
+		require.NoError(t, ibHandler.store.AddReference(store.Following, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Witness, service1IRI, service2IRI))
+		require.NoError(t, ibHandler.store.AddReference(store.Follower, service1IRI, service2IRI))
+
+		t.Run("Success", func(t *testing.T) {
+			block := vocab.NewBlockActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			require.NoError(t, ibHandler.HandleActivity(block))
+
+			time.Sleep(50 * time.Millisecond)
+
+			require.NotNil(t, ibSubscriber.Activity(block.ID()))
+
+			refs, err := ibHandler.queryReferences(store.Following, service1IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service2IRI))
+
+			refs, err = ibHandler.queryReferences(store.Witness, service1IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service2IRI))
+
+			refs, err = ibHandler.queryReferences(store.Follower, service1IRI)
+			require.NoError(t, err)
+			require.True(t, containsIRI(refs, service2IRI))
+		})
+
+		t.Run("Not target service", func(t *testing.T) {
+			block := vocab.NewBlockActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service3IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			err := ibHandler.HandleActivity(block)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid 'Block' activity")
+		})
+	})
+
+	t.Run("Outbox Block", func(t *testing.T) {
+		require.NoError(t, obHandler.store.AddReference(store.Follower, service2IRI, service1IRI))
+		require.NoError(t, obHandler.store.AddReference(store.Witnessing, service2IRI, service1IRI))
+		require.NoError(t, obHandler.store.AddReference(store.Follower, service2IRI, service3IRI))
+
+		block := vocab.NewBlockActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		t.Run("Success", func(t *testing.T) {
+			require.NoError(t, obHandler.HandleActivity(block))
+
+			refs, err := obHandler.queryReferences(store.Blocked, service2IRI)
+			require.NoError(t, err)
+			require.True(t, containsIRI(refs, service1IRI))
+
+			refs, err = obHandler.queryReferences(store.Follower, service2IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service1IRI))
+			require.True(t, containsIRI(refs, service3IRI))
+
+			refs, err = obHandler.queryReferences(store.Witnessing, service2IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service1IRI))
+		})
+
+		t.Run("Undo", func(t *testing.T) {
+			require.NoError(t, obHandler.store.AddActivity(block))
+
+			undo := vocab.NewUndoActivity(
+				vocab.NewObjectProperty(vocab.WithActivity(block)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+				vocab.WithTo(service1IRI),
+			)
+
+			require.NoError(t, obHandler.HandleActivity(undo))
+
+			refs, err := obHandler.queryReferences(store.Blocked, service2IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service1IRI))
+		})
+
+		t.Run("Block domain", func(t *testing.T) {
+			blockDomain := vocab.NewBlockActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(domain3IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			)
+
+			require.NoError(t, obHandler.HandleActivity(blockDomain))
+
+			refs, err := obHandler.queryReferences(store.Follower, service2IRI)
+			require.NoError(t, err)
+			require.False(t, containsIRI(refs, service3IRI))
+		})
+
+		t.Run("No IRI", func(t *testing.T) {
+			err := obHandler.HandleActivity(vocab.NewBlockActivity(
+				vocab.NewObjectProperty(),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "no IRI specified")
+		})
+
+		t.Run("Block self", func(t *testing.T) {
+			err := obHandler.HandleActivity(vocab.NewBlockActivity(
+				vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+				vocab.WithID(newActivityID(service2IRI)),
+				vocab.WithActor(service2IRI),
+			))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "may not block itself")
+		})
+	})
+
+	t.Run("Blocked actor", func(t *testing.T) {
+		cfg := &Config{
+			ServiceName: "inbox1",
+			ServiceIRI:  service1IRI,
+		}
+
+		h := NewInbox(cfg, memstore.New(cfg.ServiceName), mocks.NewOutbox(), mocks.NewActorRetriever())
+
+		del := vocab.NewDeleteActivity(
+			vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+			vocab.WithID(newActivityID(service2IRI)),
+			vocab.WithActor(service2IRI),
+			vocab.WithTo(service1IRI),
+		)
+
+		t.Run("Actor blocked", func(t *testing.T) {
+			require.NoError(t, h.store.AddReference(store.Blocked, service1IRI, service2IRI))
+			defer func() {
+				require.NoError(t, h.store.DeleteReference(store.Blocked, service1IRI, service2IRI))
+			}()
+
+			err := h.HandleActivity(del)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, spi.ErrActorBlocked))
+		})
+
+		t.Run("Domain blocked", func(t *testing.T) {
+			domain2IRI := testutil.MustParseURL("http://localhost:8302")
+
+			require.NoError(t, h.store.AddReference(store.Blocked, service1IRI, domain2IRI))
+			defer func() {
+				require.NoError(t, h.store.DeleteReference(store.Blocked, service1IRI, domain2IRI))
+			}()
+
+			err := h.HandleActivity(del)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, spi.ErrActorBlocked))
+		})
+
+		t.Run("Domain with trailing slash blocked", func(t *testing.T) {
+			domain2IRI := testutil.MustParseURL("http://localhost:8302/")
+
+			require.NoError(t, h.store.AddReference(store.Blocked, service1IRI, domain2IRI))
+			defer func() {
+				require.NoError(t, h.store.DeleteReference(store.Blocked, service1IRI, domain2IRI))
+			}()
+
+			err := h.HandleActivity(del)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, spi.ErrActorBlocked))
+		})
+
+		t.Run("Other domain blocked", func(t *testing.T) {
+			require.NoError(t, h.store.AddReference(store.Blocked, service1IRI, domain3IRI))
+
+			require.NoError(t, h.HandleActivity(del))
+		})
+	})
+}
+
 func startInboxOutboxWithMocks(t *testing.T, inboxServiceIRI,
 	outboxServiceIRI *url.URL) (*Inbox, *Outbox, *mockActivitySubscriber, *mockActivitySubscriber, stopFunc) {
 	t.Helper()
@@ -66,6 +66,7 @@ func NewInbox(cfg *Config, s store.Store, outbox service.Outbox,
 			})
 		},
 		h.inboxUndoLike,
+		h.inboxUndoBlock,
 	)
 
 	return h
@@ -74,6 +75,10 @@ func NewInbox(cfg *Config, s store.Store, outbox service.Outbox,
 // HandleActivity handles the ActivityPub activity in the inbox.
 //nolint:cyclop
 func (h *Inbox) HandleActivity(activity *vocab.ActivityType) error {
+	if err := h.ensureNotBlocked(activity.Actor()); err != nil {
+		return err
+	}
+
 	typeProp := activity.Type()
 
 	switch {
@@ -95,6 +100,12 @@ func (h *Inbox) HandleActivity(activity *vocab.ActivityType) error {
 		return h.handleLikeActivity(activity)
 	case typeProp.Is(vocab.TypeUndo):
 		return h.handleUndoActivity(activity)
+	case typeProp.Is(vocab.TypeUpdate):
+		return h.handleUpdateActivity(activity)
+	case typeProp.Is(vocab.TypeDelete):
+		return h.handleDeleteActivity(activity)
+	case typeProp.Is(vocab.TypeBlock):
+		return h.handleBlockActivity(activity)
 	default:
 		return fmt.Errorf("unsupported activity type: %s", typeProp.Types())
 	}
@@ -439,6 +450,11 @@ func (h *Inbox) handleAnnounceActivity(announce *vocab.ActivityType) error {
 			return fmt.Errorf("error handling 'Announce' activity [%s]: %w", announce.ID(), err)
 		}
 
+	case t.Is(vocab.TypeSignedTreeHead):
+		if err := h.handleAnnounceSignedTreeHead(announce, obj.SignedTreeHead()); err != nil {
+			return fmt.Errorf("error handling 'Announce' activity [%s]: %w", announce.ID(), err)
+		}
+
 	default:
 		return fmt.Errorf("unsupported object type for 'Announce' %s", t)
 	}
@@ -615,6 +631,17 @@ func (h *Inbox) handleAnnounceCollection(announce *vocab.ActivityType, items []*
 	return nil
 }
 
+func (h *Inbox) handleAnnounceSignedTreeHead(announce *vocab.ActivityType, sth *vocab.SignedTreeHeadType) error {
+	if sth.Log() == nil {
+		return fmt.Errorf("log is required in signed tree head")
+	}
+
+	logger.Debugf("[%s] Handling signed tree head for log [%s] from [%s] - Tree size: %d",
+		h.ServiceIRI, sth.Log(), announce.Actor(), sth.TreeSize())
+
+	return h.SignedTreeHeadHandler.HandleSignedTreeHead(announce.Actor(), sth)
+}
+
 func (h *Inbox) handleLikeActivity(like *vocab.ActivityType) error {
 	logger.Infof("[%s] Handling 'Like' activity: %s", h.ServiceName, like.ID())
 
@@ -646,6 +673,126 @@ func (h *Inbox) handleLikeActivity(like *vocab.ActivityType) error {
 	return nil
 }
 
+func (h *Inbox) handleUpdateActivity(update *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Update' activity: %s", h.ServiceName, update.ID())
+
+	actorIRI, err := h.validateActorActivity(update)
+	if err != nil {
+		return fmt.Errorf("invalid 'Update' activity [%s]: %w", update.ID(), err)
+	}
+
+	// Don't trust the actor that's embedded in the activity. Retrieve the latest version
+	// of the actor from the actor's origin.
+	actor, err := h.client.RefreshActor(actorIRI)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("refresh actor [%s]: %w", actorIRI, err))
+	}
+
+	if err := h.store.PutActor(actor); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store actor [%s]: %w", actorIRI, err))
+	}
+
+	logger.Debugf("[%s] Actor [%s] was updated", h.ServiceName, actorIRI)
+
+	h.notify(update)
+
+	return nil
+}
+
+func (h *Inbox) handleDeleteActivity(del *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Delete' activity: %s", h.ServiceName, del.ID())
+
+	actorIRI, err := h.validateActorActivity(del)
+	if err != nil {
+		return fmt.Errorf("invalid 'Delete' activity [%s]: %w", del.ID(), err)
+	}
+
+	// The service has been retracted so remove it from all of our collections.
+	err = h.deleteActorReferences(actorIRI, store.Follower, store.Following, store.Witness, store.Witnessing)
+	if err != nil {
+		return fmt.Errorf("handle 'Delete' activity [%s]: %w", del.ID(), err)
+	}
+
+	logger.Debugf("[%s] Actor [%s] was deleted", h.ServiceName, actorIRI)
+
+	h.notify(del)
+
+	return nil
+}
+
+func (h *Inbox) handleBlockActivity(block *vocab.ActivityType) error {
+	logger.Infof("[%s] Handling 'Block' activity: %s", h.ServiceName, block.ID())
+
+	err := h.validateActivity(block, func() *url.URL {
+		return block.Object().IRI()
+	})
+	if err != nil {
+		return fmt.Errorf("invalid 'Block' activity [%s]: %w", block.ID(), err)
+	}
+
+	// We've been blocked by the actor so there's no use in sending it any more activities.
+	err = h.deleteActorReferences(block.Actor(), store.Following, store.Witness)
+	if err != nil {
+		return fmt.Errorf("handle 'Block' activity [%s]: %w", block.ID(), err)
+	}
+
+	h.notify(block)
+
+	return nil
+}
+
+// validateActorActivity validates an activity whose object is the actor of the activity (for example, an
+// 'Update' or 'Delete' of a service) and returns the actor IRI. An actor may only update or delete itself.
+func (h *Inbox) validateActorActivity(activity *vocab.ActivityType) (*url.URL, error) {
+	actorIRI := activity.Actor()
+	if actorIRI == nil {
+		return nil, fmt.Errorf("no actor specified")
+	}
+
+	var objIRI *url.URL
+
+	obj := activity.Object()
+
+	switch {
+	case obj.IRI() != nil:
+		objIRI = obj.IRI()
+	case obj.Actor() != nil && obj.Actor().ID() != nil:
+		objIRI = obj.Actor().ID().URL()
+	default:
+		return nil, fmt.Errorf("object must be an actor or an actor IRI")
+	}
+
+	if objIRI.String() != actorIRI.String() {
+		return nil, fmt.Errorf("the object [%s] is not the same as the actor [%s]", objIRI, actorIRI)
+	}
+
+	return actorIRI, nil
+}
+
+// ensureNotBlocked returns an ErrActorBlocked error if the given actor, or the actor's domain,
+// was blocked by this service. The 'blocked' collection is looked up by the actor IRI and by the
+// domain IRI (with and without a trailing slash) so that the whole collection isn't read.
+func (h *Inbox) ensureNotBlocked(actorIRI *url.URL) error {
+	if actorIRI == nil {
+		return nil
+	}
+
+	domainIRI := &url.URL{Scheme: actorIRI.Scheme, Host: actorIRI.Host}
+
+	for _, iri := range []*url.URL{actorIRI, domainIRI, domainIRI.ResolveReference(&url.URL{Path: "/"})} {
+		blocked, err := h.hasReference(h.ServiceIRI, iri, store.Blocked)
+		if err != nil {
+			return fmt.Errorf("query blocked actors: %w", err)
+		}
+
+		if blocked {
+			return fmt.Errorf("%w: %s", service.ErrActorBlocked, actorIRI)
+		}
+	}
+
+	return nil
+}
+
 func (h *Inbox) announceAnchorCredential(create *vocab.ActivityType) error {
 	ref, err := newAnchorReferenceFromCreate(create)
 	if err != nil {
@@ -872,6 +1019,14 @@ func (h *Inbox) inboxUndoLike(like *vocab.ActivityType) error {
 	return nil
 }
 
+func (h *Inbox) inboxUndoBlock(block *vocab.ActivityType) error {
+	// Nothing to do since the references that were removed when the 'Block' was
+	// received need to be re-established with a 'Follow' or 'Invite'.
+	logger.Debugf("[%s] Actor [%s] removed the block on %s", h.ServiceName, block.Actor(), block.Object().IRI())
+
+	return nil
+}
+
 func (h *Inbox) ensureActivityInOutbox(activity *vocab.ActivityType) (*vocab.ActivityType, error) {
 	obActivity, err := h.getActivityFromOutbox(activity.ID().URL())
 	if err != nil {
@@ -974,6 +1129,12 @@ func (p *noOpAnchorEventAcknowledgementHandler) AnchorEventAcknowledged(actor, a
 	return nil
 }
 
+type noOpSignedTreeHeadHandler struct{}
+
+func (h *noOpSignedTreeHeadHandler) HandleSignedTreeHead(*url.URL, *vocab.SignedTreeHeadType) error {
+	return nil
+}
+
 type hashLinkInfo struct {
 	hl []*url.URL
 }
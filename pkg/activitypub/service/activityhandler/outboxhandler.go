@@ -41,6 +41,11 @@ func NewOutbox(cfg *Config, s store.Store, activityPubClient activityPubClient)
 				return activity.ID().URL()
 			})
 		},
+		func(activity *vocab.ActivityType) error {
+			return h.undoAddReference(activity, store.Blocked, func() *url.URL {
+				return activity.Object().IRI()
+			})
+		},
 	)
 
 	return h
@@ -57,6 +62,12 @@ func (h *Outbox) HandleActivity(activity *vocab.ActivityType) error {
 		return h.handleUndoActivity(activity)
 	case typeProp.Is(vocab.TypeLike):
 		return h.handleLikeActivity(activity)
+	case typeProp.Is(vocab.TypeUpdate):
+		return h.handleUpdateActivity(activity)
+	case typeProp.Is(vocab.TypeDelete):
+		return h.handleDeleteActivity(activity)
+	case typeProp.Is(vocab.TypeBlock):
+		return h.handleBlockActivity(activity)
 	default:
 		// Nothing to do for activity.
 		return nil
@@ -131,3 +142,59 @@ func (h *handler) handleLikeActivity(like *vocab.ActivityType) error {
 
 	return nil
 }
+
+func (h *Outbox) handleUpdateActivity(update *vocab.ActivityType) error {
+	logger.Debugf("[%s] Handling 'Update' activity: %s", h.ServiceName, update.ID())
+
+	actor := update.Object().Actor()
+	if actor == nil || actor.ID() == nil {
+		return errors.New("no actor specified in the 'object' field of the 'Update' activity")
+	}
+
+	if actor.ID().String() != h.ServiceIRI.String() {
+		return fmt.Errorf("this service is not the object of the 'Update' activity [%s]", update.ID())
+	}
+
+	if err := h.store.PutActor(actor); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store actor: %w", err))
+	}
+
+	return nil
+}
+
+func (h *Outbox) handleDeleteActivity(del *vocab.ActivityType) error {
+	logger.Debugf("[%s] Handling 'Delete' activity: %s", h.ServiceName, del.ID())
+
+	iri := del.Object().IRI()
+	if iri == nil {
+		return errors.New("no IRI specified in the 'object' field of the 'Delete' activity")
+	}
+
+	// Only the local service may be retracted. The references in the local service's collections are
+	// left in place since they're needed in order to deliver the 'Delete' to the service's followers.
+	if iri.String() != h.ServiceIRI.String() {
+		return fmt.Errorf("this service is not the object of the 'Delete' activity [%s]", del.ID())
+	}
+
+	return nil
+}
+
+func (h *Outbox) handleBlockActivity(block *vocab.ActivityType) error {
+	logger.Debugf("[%s] Handling 'Block' activity: %s", h.ServiceName, block.ID())
+
+	iri := block.Object().IRI()
+	if iri == nil {
+		return errors.New("no IRI specified in the 'object' field of the 'Block' activity")
+	}
+
+	if matchesIRI(iri, h.ServiceIRI) {
+		return fmt.Errorf("this service may not block itself")
+	}
+
+	if err := h.store.AddReference(store.Blocked, h.ServiceIRI, iri); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("add %s to 'blocked' collection: %w", iri, err))
+	}
+
+	// Stop sending activities to (and witnessing for) the blocked actor.
+	return h.deleteActorReferences(iri, store.Follower, store.Witnessing)
+}
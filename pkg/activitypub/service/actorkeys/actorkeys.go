@@ -0,0 +1,571 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorkeys
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/activitypub/httpsig"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("activitypub_actorkeys")
+
+const (
+	storeName = "actor-keys"
+	keyTag    = "actorKey"
+
+	keyIDPrefix = "key-"
+
+	defaultGracePeriod     = 24 * time.Hour
+	defaultRefreshInterval = time.Minute
+)
+
+// Key contains a key of the service actor.
+type Key struct {
+	ID           string      `json:"id"`
+	KMSKeyID     string      `json:"kmsKeyId"`
+	KeyType      kms.KeyType `json:"keyType"`
+	PublicKeyPem string      `json:"publicKeyPem"`
+	Created      time.Time   `json:"created"`
+	Expires      *time.Time  `json:"expires,omitempty"`
+}
+
+func (k *Key) isExpired(t time.Time) bool {
+	return k.Expires != nil && !t.Before(*k.Expires)
+}
+
+// Config contains the configuration for the actor key manager.
+type Config struct {
+	// ServiceIRI is the IRI of the service actor.
+	ServiceIRI *url.URL
+
+	// KeyType is the type of key that's created when the key is rotated.
+	KeyType kms.KeyType
+
+	// MainKMSKeyID is the KMS key ID of the service's main key, which is used until the key is first rotated.
+	MainKMSKeyID string
+
+	// MainPublicKeyPem is the PEM-encoded public key of the service's main key.
+	MainPublicKeyPem string
+
+	// GracePeriod is the period for which the previous keys remain valid after the key is rotated. This
+	// gives other services time to retrieve the updated actor.
+	GracePeriod time.Duration
+
+	// RefreshInterval is the interval at which the keys are reloaded from the database so that
+	// a key that was rotated by another instance in the cluster is picked up.
+	RefreshInterval time.Duration
+}
+
+// Outbox posts activities to the service's followers and witnesses.
+type Outbox interface {
+	Post(activity *vocab.ActivityType) (*url.URL, error)
+}
+
+// Manager manages the keys of the service actor. The actor may have multiple keys, of which the most recently
+// created key is used to sign HTTP requests. When the key is rotated, a new key is created in the KMS and the
+// previous keys are assigned an expiry time (after a grace period) so that requests signed with a previous key
+// are still accepted by other services until they have retrieved the updated actor. An 'Update' activity is
+// posted to the service's followers and witnesses so that they refresh their cached copy of the actor.
+type Manager struct {
+	*lifecycle.Lifecycle
+	Config
+
+	store  storage.Store
+	km     kms.KeyManager
+	cr     crypto.Crypto
+	outbox func() Outbox
+	mutex  sync.RWMutex
+	keys   []*Key
+	done   chan struct{}
+	now    func() time.Time
+}
+
+// New returns a new actor key manager. The outbox is provided by a function since the manager is
+// created before the ActivityPub outbox.
+func New(cfg Config, provider storage.Provider, km kms.KeyManager, cr crypto.Crypto,
+	outbox func() Outbox) (*Manager, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{keyTag}})
+	if err != nil {
+		return nil, fmt.Errorf("set store configuration for [%s]: %w", storeName, err)
+	}
+
+	m := &Manager{
+		Config: populateConfigDefaults(cfg),
+		store:  store,
+		km:     km,
+		cr:     cr,
+		outbox: outbox,
+		done:   make(chan struct{}),
+		now:    time.Now,
+	}
+
+	if err := m.init(); err != nil {
+		return nil, err
+	}
+
+	m.Lifecycle = lifecycle.New("actor-keys",
+		lifecycle.WithStart(m.start),
+		lifecycle.WithStop(m.stop))
+
+	return m, nil
+}
+
+// Keys returns the keys of the service actor that have not expired, with the current key first.
+func (m *Manager) Keys() []*Key {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	now := m.now()
+
+	var keys []*Key
+
+	for _, k := range m.keys {
+		if !k.isExpired(now) {
+			key := *k
+
+			keys = append(keys, &key)
+		}
+	}
+
+	return keys
+}
+
+// PublicKeys returns the public keys of the service actor that have not expired, with the current key first.
+func (m *Manager) PublicKeys() []*vocab.PublicKeyType {
+	var publicKeys []*vocab.PublicKeyType
+
+	for _, k := range m.Keys() {
+		created := k.Created
+
+		publicKeys = append(publicKeys, vocab.NewPublicKey(
+			vocab.WithID(m.keyIRI(k.ID)),
+			vocab.WithOwner(m.ServiceIRI),
+			vocab.WithPublicKeyPem(k.PublicKeyPem),
+			vocab.WithCreated(&created),
+			vocab.WithExpires(k.Expires),
+		))
+	}
+
+	return publicKeys
+}
+
+// Rotate creates a new key for the service actor and sets the expiry time of the previous keys to the end of
+// the grace period. An 'Update' activity is then posted to the service's followers and witnesses.
+func (m *Manager) Rotate() (*Key, error) {
+	key, err := m.rotate()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Rotated key of service [%s]. New key ID [%s]", m.ServiceIRI, key.ID)
+
+	if err := m.postUpdate(); err != nil {
+		// The key was rotated. Other services will pick up the new key when they retrieve the actor.
+		logger.Warnf("Error posting 'Update' activity for rotated key [%s]: %s", key.ID, err)
+	}
+
+	return key, nil
+}
+
+// Signer returns an HTTP signer that signs requests using the current key of the service actor.
+func (m *Manager) Signer(cfg httpsig.SignerConfig) *Signer {
+	return &Signer{
+		config:  cfg,
+		manager: m,
+		signers: make(map[string]*httpsig.Signer),
+	}
+}
+
+// rotate creates the new key and sets the expiry time of the previous keys. Each key is stored as a separate
+// record so that a concurrent rotation by another instance in the cluster doesn't overwrite the new key. After the
+// keys are saved, they are reloaded and any key that's not the current key but still has no expiry time (i.e. a key
+// that was created by a concurrent rotation) is also assigned an expiry time. The current key is returned, which
+// is the key that was created by the other instance if it was created after this instance's key.
+func (m *Manager) rotate() (*Key, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Load the keys from the database since the key may have been rotated by another instance.
+	keys, err := m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	kmsKeyID, pubKeyBytes, err := m.km.CreateAndExportPubKeyBytes(m.KeyType)
+	if err != nil {
+		return nil, fmt.Errorf("create key of type [%s]: %w", m.KeyType, err)
+	}
+
+	publicKeyPem, err := httpsig.PublicKeyPEM(m.KeyType, pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("convert public key: %w", err)
+	}
+
+	now := m.now()
+
+	newKey := &Key{
+		ID:           keyIDPrefix + uuid.New().String(),
+		KMSKeyID:     kmsKeyID,
+		KeyType:      m.KeyType,
+		PublicKeyPem: publicKeyPem,
+		Created:      now,
+	}
+
+	operations, err := newPutOperations(newKey)
+	if err != nil {
+		return nil, err
+	}
+
+	expireOperations, err := m.expireOperations(keys, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.save(append(operations, expireOperations...)); err != nil {
+		return nil, err
+	}
+
+	keys, err = m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keys) == 0 {
+		return nil, orberrors.NewTransient(fmt.Errorf("key [%s] not found after rotation", newKey.ID))
+	}
+
+	// Expire any previous key that was added by a concurrent rotation.
+	expireOperations, err = m.expireOperations(keys[1:], now)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.save(expireOperations); err != nil {
+		return nil, err
+	}
+
+	keys, err = m.load()
+	if err != nil {
+		return nil, err
+	}
+
+	m.keys = keys
+
+	currentKey := *keys[0]
+
+	return &currentKey, nil
+}
+
+// expireOperations returns the operations that set the expiry time of the given keys to the end of the grace
+// period and delete the keys that have already expired.
+func (m *Manager) expireOperations(keys []*Key, now time.Time) ([]storage.Operation, error) {
+	expires := now.Add(m.GracePeriod)
+
+	var operations []storage.Operation
+
+	for _, k := range keys {
+		if k.isExpired(now) {
+			logger.Debugf("Removing expired key [%s]", k.ID)
+
+			operations = append(operations, storage.Operation{Key: k.ID})
+
+			continue
+		}
+
+		if k.Expires != nil && !k.Expires.After(expires) {
+			continue
+		}
+
+		k.Expires = &expires
+
+		putOperations, err := newPutOperations(k)
+		if err != nil {
+			return nil, err
+		}
+
+		operations = append(operations, putOperations...)
+	}
+
+	return operations, nil
+}
+
+func (m *Manager) postUpdate() error {
+	actor, err := resthandler.NewServiceActor(m.ServiceIRI, m.PublicKeys()...)
+	if err != nil {
+		return fmt.Errorf("create service actor: %w", err)
+	}
+
+	followers, err := url.Parse(m.ServiceIRI.String() + resthandler.FollowersPath)
+	if err != nil {
+		return fmt.Errorf("parse followers IRI: %w", err)
+	}
+
+	witnesses, err := url.Parse(m.ServiceIRI.String() + resthandler.WitnessesPath)
+	if err != nil {
+		return fmt.Errorf("parse witnesses IRI: %w", err)
+	}
+
+	now := m.now()
+
+	update := vocab.NewUpdateActivity(
+		vocab.NewObjectProperty(vocab.WithActorObject(actor)),
+		vocab.WithActor(m.ServiceIRI),
+		vocab.WithTo(followers, witnesses),
+		vocab.WithPublishedTime(&now),
+	)
+
+	activityID, err := m.outbox().Post(update)
+	if err != nil {
+		return fmt.Errorf("post 'Update' activity: %w", err)
+	}
+
+	logger.Debugf("Posted 'Update' activity [%s] for service [%s]", activityID, m.ServiceIRI)
+
+	return nil
+}
+
+func (m *Manager) currentKey() *Key {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.keys[0]
+}
+
+func (m *Manager) keyIRI(keyID string) *url.URL {
+	iri := *m.ServiceIRI
+	iri.Path += "/keys/" + keyID
+
+	return &iri
+}
+
+func (m *Manager) init() error {
+	keys, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) > 0 {
+		m.keys = keys
+
+		return nil
+	}
+
+	logger.Infof("Initializing the keys of service [%s] with the main key", m.ServiceIRI)
+
+	keys = []*Key{{
+		ID:           resthandler.MainKeyID,
+		KMSKeyID:     m.MainKMSKeyID,
+		KeyType:      m.KeyType,
+		PublicKeyPem: m.MainPublicKeyPem,
+		Created:      m.now(),
+	}}
+
+	operations, err := newPutOperations(keys...)
+	if err != nil {
+		return err
+	}
+
+	if err := m.save(operations); err != nil {
+		return err
+	}
+
+	m.keys = keys
+
+	return nil
+}
+
+func (m *Manager) load() ([]*Key, error) {
+	iter, err := m.store.Query(keyTag)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("load keys: %w", err))
+	}
+
+	defer func() {
+		if e := iter.Close(); e != nil {
+			logger.Warnf("Error closing iterator: %s", e)
+		}
+	}()
+
+	var keys []*Key
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator next: %w", err))
+	}
+
+	for ok {
+		keyBytes, err := iter.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator value: %w", err))
+		}
+
+		key := &Key{}
+
+		if err := json.Unmarshal(keyBytes, key); err != nil {
+			return nil, fmt.Errorf("unmarshal key: %w", err)
+		}
+
+		keys = append(keys, key)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator next: %w", err))
+		}
+	}
+
+	// The current (most recently created) key is first.
+	sort.SliceStable(keys, func(i, j int) bool {
+		return keys[i].Created.After(keys[j].Created)
+	})
+
+	return keys, nil
+}
+
+func (m *Manager) save(operations []storage.Operation) error {
+	if len(operations) == 0 {
+		return nil
+	}
+
+	if err := m.store.Batch(operations); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store keys: %w", err))
+	}
+
+	return nil
+}
+
+func newPutOperations(keys ...*Key) ([]storage.Operation, error) {
+	operations := make([]storage.Operation, len(keys))
+
+	for i, k := range keys {
+		keyBytes, err := json.Marshal(k)
+		if err != nil {
+			return nil, fmt.Errorf("marshal key [%s]: %w", k.ID, err)
+		}
+
+		operations[i] = storage.Operation{
+			Key:   k.ID,
+			Value: keyBytes,
+			Tags:  []storage.Tag{{Name: keyTag}},
+		}
+	}
+
+	return operations, nil
+}
+
+func (m *Manager) refresh() error {
+	keys, err := m.load()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if keys[0].ID != m.keys[0].ID {
+		logger.Infof("Current key of service [%s] changed to [%s]", m.ServiceIRI, keys[0].ID)
+	}
+
+	m.keys = keys
+
+	return nil
+}
+
+func (m *Manager) start() {
+	go m.run()
+
+	logger.Infof("Started actor key manager - Current key [%s], Grace period [%s]",
+		m.currentKey().ID, m.GracePeriod)
+}
+
+func (m *Manager) stop() {
+	close(m.done)
+
+	logger.Infof("Stopped actor key manager")
+}
+
+func (m *Manager) run() {
+	for {
+		select {
+		case <-time.After(m.RefreshInterval):
+			if err := m.refresh(); err != nil {
+				logger.Warnf("Error refreshing actor keys: %s", err)
+			}
+		case <-m.done:
+			logger.Debugf("Exiting actor key refresh job.")
+
+			return
+		}
+	}
+}
+
+// Signer signs HTTP requests using the current key of the service actor.
+type Signer struct {
+	config  httpsig.SignerConfig
+	manager *Manager
+	mutex   sync.Mutex
+	signers map[string]*httpsig.Signer
+}
+
+// SignRequest signs the given request with the current key. The given public key ID is ignored since
+// the ID of the current key is used instead.
+func (s *Signer) SignRequest(_ string, req *http.Request) error {
+	key := s.manager.currentKey()
+
+	return s.signer(key.KMSKeyID).SignRequest(s.manager.keyIRI(key.ID).String(), req)
+}
+
+func (s *Signer) signer(kmsKeyID string) *httpsig.Signer {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	signer, ok := s.signers[kmsKeyID]
+	if !ok {
+		signer = httpsig.NewSigner(s.config, s.manager.cr, s.manager.km, kmsKeyID)
+
+		s.signers[kmsKeyID] = signer
+	}
+
+	return signer
+}
+
+func populateConfigDefaults(cfg Config) Config {
+	if cfg.GracePeriod == 0 {
+		cfg.GracePeriod = defaultGracePeriod
+	}
+
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = defaultRefreshInterval
+	}
+
+	return cfg
+}
@@ -0,0 +1,424 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package actorkeys
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	mockstore "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
+	mockkms "github.com/hyperledger/aries-framework-go/pkg/mock/kms"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock"
+	"github.com/hyperledger/aries-framework-go/pkg/secretlock/noop"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/httpsig"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+var serviceIRI = testutil.MustParseURL("https://example.com/services/orb")
+
+func TestNew(t *testing.T) {
+	km, cr := newKMSAndCrypto(t)
+
+	t.Run("Success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		m, err := New(newConfig(t, km), provider, km, cr, newMockOutbox().get)
+		require.NoError(t, err)
+		require.NotNil(t, m)
+		require.Equal(t, defaultGracePeriod, m.GracePeriod)
+		require.Equal(t, defaultRefreshInterval, m.RefreshInterval)
+
+		keys := m.Keys()
+		require.Len(t, keys, 1)
+		require.Equal(t, resthandler.MainKeyID, keys[0].ID)
+		require.Nil(t, keys[0].Expires)
+
+		// The keys should be loaded from the database.
+		m2, err := New(Config{ServiceIRI: serviceIRI}, provider, km, cr, newMockOutbox().get)
+		require.NoError(t, err)
+		require.Len(t, m2.Keys(), 1)
+		require.Equal(t, keys[0].KMSKeyID, m2.Keys()[0].KMSKeyID)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		p := &mockstore.Provider{ErrOpenStore: errors.New("injected open error")}
+
+		_, err := New(Config{}, p, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected open error")
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		p := &mockstore.Provider{
+			OpenStoreReturn:   &mockstore.Store{},
+			ErrSetStoreConfig: errors.New("injected set config error"),
+		}
+
+		_, err := New(Config{}, p, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected set config error")
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		p := &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{ErrQuery: errors.New("injected query error")},
+		}
+
+		_, err := New(Config{}, p, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected query error")
+	})
+
+	t.Run("Iterator error", func(t *testing.T) {
+		p := &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{
+				QueryReturn: &mockstore.Iterator{ErrNext: errors.New("injected next error")},
+			},
+		}
+
+		_, err := New(Config{}, p, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected next error")
+	})
+
+	t.Run("Batch error", func(t *testing.T) {
+		p := &mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{
+				QueryReturn: &mockstore.Iterator{},
+				ErrBatch:    errors.New("injected batch error"),
+			},
+		}
+
+		_, err := New(Config{}, p, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected batch error")
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := provider.OpenStore(storeName)
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put("key-1", []byte("{"), storage.Tag{Name: keyTag}))
+
+		_, err = New(Config{}, provider, km, cr, newMockOutbox().get)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal key")
+	})
+}
+
+func TestManager_Rotate(t *testing.T) {
+	km, cr := newKMSAndCrypto(t)
+
+	t.Run("Success", func(t *testing.T) {
+		ob := newMockOutbox()
+
+		m, err := New(newConfig(t, km), mem.NewProvider(), km, cr, ob.get)
+		require.NoError(t, err)
+
+		now := time.Now()
+
+		m.now = func() time.Time { return now }
+
+		key, err := m.Rotate()
+		require.NoError(t, err)
+		require.NotNil(t, key)
+		require.True(t, strings.HasPrefix(key.ID, keyIDPrefix))
+		require.Nil(t, key.Expires)
+
+		keys := m.Keys()
+		require.Len(t, keys, 2)
+		require.Equal(t, key.ID, keys[0].ID)
+		require.Equal(t, resthandler.MainKeyID, keys[1].ID)
+		require.NotNil(t, keys[1].Expires)
+		require.True(t, now.Add(defaultGracePeriod).Equal(*keys[1].Expires))
+
+		publicKeys := m.PublicKeys()
+		require.Len(t, publicKeys, 2)
+		require.Equal(t, serviceIRI.String()+"/keys/"+key.ID, publicKeys[0].ID.String())
+		require.Equal(t, serviceIRI.String(), publicKeys[0].Owner.String())
+
+		activities := ob.activities()
+		require.Len(t, activities, 1)
+		require.True(t, activities[0].Type().Is(vocab.TypeUpdate))
+		require.Equal(t, serviceIRI.String(), activities[0].Actor().String())
+
+		actor := activities[0].Object().Actor()
+		require.NotNil(t, actor)
+		require.Equal(t, serviceIRI.String(), actor.ID().String())
+		require.Len(t, actor.PublicKeys(), 2)
+		require.Equal(t, publicKeys[0].ID.String(), actor.PublicKey().ID.String())
+
+		// Rotate again. The expiry of the main key shouldn't be extended.
+		m.now = func() time.Time { return now.Add(time.Hour) }
+
+		key2, err := m.Rotate()
+		require.NoError(t, err)
+
+		keys = m.Keys()
+		require.Len(t, keys, 3)
+		require.Equal(t, key2.ID, keys[0].ID)
+		require.Equal(t, key.ID, keys[1].ID)
+		require.True(t, now.Add(time.Hour+defaultGracePeriod).Equal(*keys[1].Expires))
+		require.Equal(t, resthandler.MainKeyID, keys[2].ID)
+		require.True(t, now.Add(defaultGracePeriod).Equal(*keys[2].Expires))
+
+		// After the grace period the expired keys are no longer returned and they are removed on the next rotation.
+		m.now = func() time.Time { return now.Add(2 * defaultGracePeriod) }
+
+		require.Len(t, m.Keys(), 1)
+		require.Len(t, m.PublicKeys(), 1)
+
+		_, err = m.Rotate()
+		require.NoError(t, err)
+		require.Len(t, m.keys, 2)
+	})
+
+	t.Run("Outbox error", func(t *testing.T) {
+		ob := newMockOutbox().withError(errors.New("injected outbox error"))
+
+		m, err := New(newConfig(t, km), mem.NewProvider(), km, cr, ob.get)
+		require.NoError(t, err)
+
+		// The key is still rotated.
+		key, err := m.Rotate()
+		require.NoError(t, err)
+		require.Equal(t, key.ID, m.Keys()[0].ID)
+	})
+
+	t.Run("Create key error", func(t *testing.T) {
+		m, err := New(newConfig(t, km), mem.NewProvider(),
+			&mockkms.KeyManager{CrAndExportPubKeyErr: errors.New("injected create error")}, cr, newMockOutbox().get)
+		require.NoError(t, err)
+
+		_, err = m.Rotate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected create error")
+	})
+
+	t.Run("Invalid public key error", func(t *testing.T) {
+		m, err := New(newConfig(t, km), mem.NewProvider(),
+			&mockkms.KeyManager{CrAndExportPubKeyValue: []byte("invalid")}, cr, newMockOutbox().get)
+		require.NoError(t, err)
+
+		_, err = m.Rotate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "convert public key")
+	})
+
+	t.Run("Load error", func(t *testing.T) {
+		s := &mockstore.Store{QueryReturn: &mockstore.Iterator{}}
+
+		m, err := New(newConfig(t, km), &mockstore.Provider{OpenStoreReturn: s}, km, cr, newMockOutbox().get)
+		require.NoError(t, err)
+
+		s.ErrQuery = errors.New("injected query error")
+
+		_, err = m.Rotate()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected query error")
+	})
+}
+
+func TestManager_ConcurrentRotate(t *testing.T) {
+	km, cr := newKMSAndCrypto(t)
+
+	const (
+		numInstances = 3
+		numRotations = 5
+	)
+
+	provider := mem.NewProvider()
+
+	var managers []*Manager
+
+	for i := 0; i < numInstances; i++ {
+		m, err := New(newConfig(t, km), provider, km, cr, newMockOutbox().get)
+		require.NoError(t, err)
+
+		managers = append(managers, m)
+	}
+
+	var wg sync.WaitGroup
+
+	for _, m := range managers {
+		wg.Add(1)
+
+		go func(m *Manager) {
+			defer wg.Done()
+
+			for i := 0; i < numRotations; i++ {
+				_, err := m.Rotate()
+				require.NoError(t, err)
+			}
+		}(m)
+	}
+
+	wg.Wait()
+
+	keys, err := managers[0].load()
+	require.NoError(t, err)
+
+	// None of the keys should be lost and only the current key should have no expiry time.
+	require.Len(t, keys, 1+numInstances*numRotations)
+	require.Nil(t, keys[0].Expires)
+
+	for _, k := range keys[1:] {
+		require.NotNil(t, k.Expires)
+	}
+}
+
+func TestManager_Refresh(t *testing.T) {
+	km, cr := newKMSAndCrypto(t)
+
+	provider := mem.NewProvider()
+
+	m1, err := New(newConfig(t, km), provider, km, cr, newMockOutbox().get)
+	require.NoError(t, err)
+
+	cfg := newConfig(t, km)
+	cfg.RefreshInterval = 10 * time.Millisecond
+
+	m2, err := New(cfg, provider, km, cr, newMockOutbox().get)
+	require.NoError(t, err)
+
+	m2.Start()
+	defer m2.Stop()
+
+	key, err := m1.Rotate()
+	require.NoError(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+
+	require.Equal(t, key.ID, m2.currentKey().ID)
+}
+
+func TestSigner(t *testing.T) {
+	km, cr := newKMSAndCrypto(t)
+
+	m, err := New(newConfig(t, km), mem.NewProvider(), km, cr, newMockOutbox().get)
+	require.NoError(t, err)
+
+	signer := m.Signer(httpsig.DefaultGetSignerConfig())
+
+	req, err := http.NewRequest(http.MethodGet, "https://domain1.com", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, signer.SignRequest("ignored", req))
+	require.Contains(t, req.Header.Get("Signature"), serviceIRI.String()+"/keys/"+resthandler.MainKeyID)
+
+	key, err := m.Rotate()
+	require.NoError(t, err)
+
+	req, err = http.NewRequest(http.MethodGet, "https://domain1.com", nil)
+	require.NoError(t, err)
+
+	require.NoError(t, signer.SignRequest("ignored", req))
+	require.Contains(t, req.Header.Get("Signature"), serviceIRI.String()+"/keys/"+key.ID)
+	require.Len(t, signer.signers, 2)
+}
+
+func newConfig(t *testing.T, km kms.KeyManager) Config {
+	t.Helper()
+
+	kmsKeyID, pubKeyBytes, err := km.CreateAndExportPubKeyBytes(kms.ED25519Type)
+	require.NoError(t, err)
+
+	pubKeyPem, err := httpsig.PublicKeyPEM(kms.ED25519Type, pubKeyBytes)
+	require.NoError(t, err)
+
+	return Config{
+		ServiceIRI:       serviceIRI,
+		KeyType:          kms.ED25519Type,
+		MainKMSKeyID:     kmsKeyID,
+		MainPublicKeyPem: pubKeyPem,
+	}
+}
+
+func newKMSAndCrypto(t *testing.T) (kms.KeyManager, *tinkcrypto.Crypto) {
+	t.Helper()
+
+	km, err := localkms.New("local-lock://custom/master/key/", &kmsProvider{
+		storageProvider:   mem.NewProvider(),
+		secretLockService: &noop.NoLock{},
+	})
+	require.NoError(t, err)
+
+	cr, err := tinkcrypto.New()
+	require.NoError(t, err)
+
+	return km, cr
+}
+
+type kmsProvider struct {
+	storageProvider   storage.Provider
+	secretLockService secretlock.Service
+}
+
+func (k *kmsProvider) StorageProvider() storage.Provider {
+	return k.storageProvider
+}
+
+func (k *kmsProvider) SecretLock() secretlock.Service {
+	return k.secretLockService
+}
+
+type mockOutbox struct {
+	mutex    sync.Mutex
+	posted   []*vocab.ActivityType
+	err      error
+	activity *url.URL
+}
+
+func newMockOutbox() *mockOutbox {
+	return &mockOutbox{activity: testutil.MustParseURL("https://example.com/services/orb/activities/1")}
+}
+
+func (m *mockOutbox) withError(err error) *mockOutbox {
+	m.err = err
+
+	return m
+}
+
+func (m *mockOutbox) get() Outbox {
+	return m
+}
+
+func (m *mockOutbox) Post(activity *vocab.ActivityType) (*url.URL, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.posted = append(m.posted, activity)
+
+	return m.activity, nil
+}
+
+func (m *mockOutbox) activities() []*vocab.ActivityType {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.posted
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/actorkeys"
+)
+
+const (
+	// ListPath is the endpoint that lists the keys of the service actor.
+	ListPath = "/actorkeys"
+	// RotatePath is the endpoint that rotates the key of the service actor.
+	RotatePath = "/actorkeys/rotate"
+)
+
+const internalServerErrorResponse = "Internal Server Error."
+
+var logger = log.New("actorkeys-rest-handler")
+
+type keyManager interface {
+	Keys() []*actorkeys.Key
+	Rotate() (*actorkeys.Key, error)
+}
+
+// List lists the keys of the service actor.
+type List struct {
+	manager keyManager
+	marshal func(interface{}) ([]byte, error)
+}
+
+// NewList returns a new handler that lists the keys of the service actor.
+func NewList(m keyManager) *List {
+	return &List{
+		manager: m,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the List service.
+func (h *List) Path() string {
+	return ListPath
+}
+
+// Method returns the HTTP REST method for the List service.
+func (h *List) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the List service.
+func (h *List) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *List) handle(w http.ResponseWriter, _ *http.Request) {
+	keys := h.manager.Keys()
+
+	if keys == nil {
+		keys = []*actorkeys.Key{}
+	}
+
+	writeJSON(w, ListPath, keys, h.marshal)
+}
+
+// Rotate creates a new key for the service actor.
+type Rotate struct {
+	manager keyManager
+	marshal func(interface{}) ([]byte, error)
+}
+
+// NewRotate returns a new handler that rotates the key of the service actor.
+func NewRotate(m keyManager) *Rotate {
+	return &Rotate{
+		manager: m,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Rotate service.
+func (h *Rotate) Path() string {
+	return RotatePath
+}
+
+// Method returns the HTTP REST method for the Rotate service.
+func (h *Rotate) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Rotate service.
+func (h *Rotate) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Rotate) handle(w http.ResponseWriter, _ *http.Request) {
+	key, err := h.manager.Rotate()
+	if err != nil {
+		logger.Errorf("[%s] Error rotating actor key: %s", RotatePath, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	logger.Debugf("[%s] Rotated actor key. New key [%s]", RotatePath, key.ID)
+
+	writeJSON(w, RotatePath, key, h.marshal)
+}
+
+func writeJSON(w http.ResponseWriter, endpoint string, v interface{}, marshal func(interface{}) ([]byte, error)) {
+	respBytes, err := marshal(v)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling response: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, respBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("Unable to write response: %s", err)
+
+			return
+		}
+
+		logger.Debugf("Wrote response: %s", body)
+	}
+}
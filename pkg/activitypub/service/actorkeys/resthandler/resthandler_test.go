@@ -0,0 +1,131 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/actorkeys"
+)
+
+const keyID = "key-1"
+
+func TestNew(t *testing.T) {
+	m := &mockManager{}
+
+	list := NewList(m)
+	require.Equal(t, ListPath, list.Path())
+	require.Equal(t, http.MethodGet, list.Method())
+	require.NotNil(t, list.Handler())
+
+	rotate := NewRotate(m)
+	require.Equal(t, RotatePath, rotate.Path())
+	require.Equal(t, http.MethodPost, rotate.Method())
+	require.NotNil(t, rotate.Handler())
+}
+
+func TestList(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		m := &mockManager{
+			keys: []*actorkeys.Key{{ID: keyID}, {ID: "main-key"}},
+		}
+
+		rw := httptest.NewRecorder()
+
+		NewList(m).handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var keys []*actorkeys.Key
+		require.NoError(t, json.Unmarshal(respBytes, &keys))
+		require.Len(t, keys, 2)
+		require.Equal(t, keyID, keys[0].ID)
+	})
+
+	t.Run("No keys", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewList(&mockManager{}).handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, "[]", string(respBytes))
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewList(&mockManager{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestRotate(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewRotate(&mockManager{}).handle(rw, httptest.NewRequest(http.MethodPost, RotatePath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		key := &actorkeys.Key{}
+		require.NoError(t, json.NewDecoder(result.Body).Decode(key))
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, keyID, key.ID)
+	})
+
+	t.Run("Manager error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewRotate(&mockManager{err: errors.New("injected error")}).handle(rw,
+			httptest.NewRequest(http.MethodPost, RotatePath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockManager struct {
+	keys []*actorkeys.Key
+	err  error
+}
+
+func (m *mockManager) Keys() []*actorkeys.Key {
+	return m.keys
+}
+
+func (m *mockManager) Rotate() (*actorkeys.Key, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &actorkeys.Key{ID: keyID}, nil
+}
@@ -0,0 +1,561 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorsync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client"
+	service "github.com/trustbloc/orb/pkg/activitypub/service/spi"
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("anchor-sync")
+
+const (
+	storeName = "anchor-sync"
+
+	// syncQueueSize is the maximum number of pending synchronization requests. If the queue is full then
+	// the request is dropped and the service is synchronized on the next interval.
+	syncQueueSize = 100
+)
+
+// Config contains the configuration for the anchor synchronization service.
+type Config struct {
+	// ServiceIRI is the IRI of the local service.
+	ServiceIRI *url.URL
+
+	// Interval is the interval at which the outboxes of all followed services are synchronized.
+	Interval time.Duration
+}
+
+type activityPubClient interface {
+	GetActor(iri *url.URL) (*vocab.ActorType, error)
+	GetActivitiesPage(iri *url.URL) (*client.ActivityPage, error)
+}
+
+type activitySubscriber interface {
+	Subscribe() <-chan *vocab.ActivityType
+}
+
+// Providers contains all of the providers required by the anchor synchronization service.
+type Providers struct {
+	Client                  activityPubClient
+	ActivityStore           store.Store
+	AnchorCredentialHandler service.AnchorCredentialHandler
+	Subscriber              activitySubscriber
+}
+
+// Service backfills the anchor credentials of the services that the local service follows. The public outbox
+// of each followed service is paged through (newest first) until the last processed activity (the checkpoint)
+// is found. The pages are then traversed in the opposite direction and the missed activities are processed
+// (oldest first), so that only one page is held in memory at a time. All 'Create' and 'Announce' activities
+// containing anchor credentials are passed to the anchor credential handler. A synchronization is triggered
+// when a 'Follow' request is accepted and also periodically, so that anchors which were missed while the local
+// server was offline are also processed. Synchronization is performed in a separate goroutine so that
+// it never holds up the processing of inbox activities.
+type Service struct {
+	*Providers
+	*lifecycle.Lifecycle
+
+	store    storage.Store
+	done     chan struct{}
+	syncChan chan *url.URL
+	config   Config
+}
+
+type checkpoint struct {
+	ActivityID string    `json:"activityId"`
+	Updated    time.Time `json:"updated"`
+}
+
+// New returns a new anchor synchronization service.
+func New(cfg Config, providers *Providers, provider storage.Provider) (*Service, error) {
+	s, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	svc := &Service{
+		Providers: providers,
+		store:     s,
+		done:      make(chan struct{}),
+		syncChan:  make(chan *url.URL, syncQueueSize),
+		config:    cfg,
+	}
+
+	svc.Lifecycle = lifecycle.New("anchor-sync",
+		lifecycle.WithStart(svc.start),
+		lifecycle.WithStop(svc.stop))
+
+	return svc, nil
+}
+
+func (s *Service) start() {
+	go s.run(s.Subscriber.Subscribe())
+	go s.syncWorker()
+
+	logger.Infof("Started anchor synchronization service - Interval [%s]", s.config.Interval)
+}
+
+func (s *Service) stop() {
+	close(s.done)
+
+	logger.Infof("Stopped anchor synchronization service")
+}
+
+// run reads the activities from the inbox subscription and queues a synchronization request
+// when a 'Follow' is accepted. This loop must never block on synchronization.
+func (s *Service) run(activityChan <-chan *vocab.ActivityType) {
+	for {
+		select {
+		case activity, ok := <-activityChan:
+			if !ok {
+				logger.Debugf("Activity channel closed.")
+
+				activityChan = nil
+
+				continue
+			}
+
+			if serviceIRI, ok := s.acceptedFollow(activity); ok {
+				logger.Infof("Follow request to [%s] was accepted. Synchronizing anchors.", serviceIRI)
+
+				s.requestSync(serviceIRI)
+			}
+
+		case <-s.done:
+			logger.Debugf("Exiting anchor synchronization service.")
+
+			return
+		}
+	}
+}
+
+// requestSync queues a synchronization request for the given service without blocking.
+func (s *Service) requestSync(serviceIRI *url.URL) {
+	select {
+	case s.syncChan <- serviceIRI:
+	default:
+		logger.Warnf("Synchronization queue is full. Anchors from [%s] will be synchronized on the next interval.",
+			serviceIRI)
+	}
+}
+
+func (s *Service) syncWorker() {
+	for {
+		select {
+		case <-time.After(s.config.Interval):
+			s.syncAll()
+
+		case serviceIRI := <-s.syncChan:
+			s.syncService(serviceIRI)
+
+		case <-s.done:
+			logger.Debugf("Exiting anchor synchronization worker.")
+
+			return
+		}
+	}
+}
+
+func (s *Service) stopped() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// acceptedFollow returns the IRI of the followed service if the given activity is an 'Accept'
+// of a 'Follow' that was sent by the local service.
+func (s *Service) acceptedFollow(activity *vocab.ActivityType) (*url.URL, bool) {
+	if !activity.Type().Is(vocab.TypeAccept) || activity.Actor() == nil {
+		return nil, false
+	}
+
+	follow := activity.Object().Activity()
+	if follow == nil || !follow.Type().Is(vocab.TypeFollow) || follow.Actor() == nil {
+		return nil, false
+	}
+
+	if follow.Actor().String() != s.config.ServiceIRI.String() {
+		return nil, false
+	}
+
+	return activity.Actor(), true
+}
+
+func (s *Service) syncAll() {
+	following, err := s.getFollowing()
+	if err != nil {
+		logger.Errorf("Error retrieving the services that [%s] is following: %s", s.config.ServiceIRI, err)
+
+		return
+	}
+
+	logger.Debugf("Synchronizing anchors from %d followed services", len(following))
+
+	for _, serviceIRI := range following {
+		if s.stopped() {
+			return
+		}
+
+		s.syncService(serviceIRI)
+	}
+}
+
+func (s *Service) syncService(serviceIRI *url.URL) {
+	if err := s.sync(serviceIRI); err != nil {
+		logger.Warnf("Error synchronizing anchors from [%s]: %s", serviceIRI, err)
+	}
+}
+
+func (s *Service) sync(serviceIRI *url.URL) error {
+	actor, err := s.Client.GetActor(serviceIRI)
+	if err != nil {
+		return fmt.Errorf("get actor: %w", err)
+	}
+
+	if actor.Outbox() == nil {
+		return fmt.Errorf("actor has no outbox")
+	}
+
+	cp, err := s.getCheckpoint(serviceIRI)
+	if err != nil {
+		return err
+	}
+
+	page, index, err := s.findCheckpoint(actor.Outbox(), cp)
+	if err != nil {
+		return err
+	}
+
+	return s.processMissedActivities(serviceIRI, page, index)
+}
+
+// findCheckpoint pages through the given outbox (newest first) until the checkpoint activity is found. The page
+// containing the checkpoint is returned along with the index of the checkpoint activity within the page. If
+// there's no checkpoint (or the checkpoint activity isn't found) then the last page (i.e. the page with the
+// oldest activities) is returned along with the number of activities in the page.
+func (s *Service) findCheckpoint(outboxIRI *url.URL, cp *checkpoint) (*client.ActivityPage, int, error) {
+	page, err := s.Client.GetActivitiesPage(outboxIRI)
+	if err != nil {
+		return nil, 0, orberrors.NewTransient(fmt.Errorf("get activities from outbox [%s]: %w", outboxIRI, err))
+	}
+
+	for {
+		if cp != nil {
+			for i, activity := range page.Activities {
+				if activity.ID() != nil && activity.ID().String() == cp.ActivityID {
+					return page, i, nil
+				}
+			}
+		}
+
+		if page.Next == nil {
+			break
+		}
+
+		if s.stopped() {
+			return nil, 0, orberrors.NewTransient(errors.New("service stopped"))
+		}
+
+		nextPage := page.Next
+
+		page, err = s.Client.GetActivitiesPage(nextPage)
+		if err != nil {
+			return nil, 0, orberrors.NewTransient(fmt.Errorf("read activities from outbox [%s]: %w", nextPage, err))
+		}
+	}
+
+	if cp != nil {
+		// The checkpoint activity is no longer in the outbox so there may be a gap in the
+		// anchors that were synchronized. Process all activities in the outbox.
+		logger.Warnf("Gap detected in outbox [%s]: checkpoint activity [%s] from %s was not found. "+
+			"Processing all activities in the outbox.", outboxIRI, cp.ActivityID, cp.Updated)
+	}
+
+	return page, len(page.Activities), nil
+}
+
+// processMissedActivities processes the activities that are newer than the activity at the given index of the
+// given page. The pages are traversed towards the newest activities (using the 'prev' links) and the checkpoint
+// is saved after each page.
+func (s *Service) processMissedActivities(serviceIRI *url.URL, page *client.ActivityPage, index int) error {
+	var total int
+
+	for {
+		lastProcessed, n, err := s.processActivities(serviceIRI, page.Activities[:index])
+
+		total += n
+
+		if lastProcessed != nil {
+			if e := s.putCheckpoint(serviceIRI, lastProcessed.ID().String()); e != nil {
+				logger.Errorf("Error saving checkpoint for [%s]: %s", serviceIRI, e)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if page.Prev == nil || s.stopped() {
+			break
+		}
+
+		prevPage := page.Prev
+
+		page, err = s.Client.GetActivitiesPage(prevPage)
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("read activities from outbox [%s]: %w", prevPage, err))
+		}
+
+		index = len(page.Activities)
+	}
+
+	if total == 0 {
+		logger.Debugf("No new activities in outbox of [%s]", serviceIRI)
+	} else {
+		logger.Infof("Processed %d activities missed from outbox of [%s]", total, serviceIRI)
+	}
+
+	return nil
+}
+
+// processActivities processes the given activities in reverse order (since the outbox is ordered newest
+// first) and returns the last activity that was processed along with the number of processed activities.
+func (s *Service) processActivities(serviceIRI *url.URL,
+	activities []*vocab.ActivityType) (*vocab.ActivityType, int, error) {
+	var lastProcessed *vocab.ActivityType
+
+	var n int
+
+	for i := len(activities) - 1; i >= 0; i-- {
+		activity := activities[i]
+
+		if activity.ID() == nil {
+			continue
+		}
+
+		if err := s.handleActivity(activity); err != nil {
+			if orberrors.IsTransient(err) {
+				// Stop here so that this activity is retried on the next synchronization.
+				return lastProcessed, n, fmt.Errorf("handle activity [%s]: %w", activity.ID(), err)
+			}
+
+			logger.Warnf("Skipping activity [%s] from outbox of [%s]: %s", activity.ID(), serviceIRI, err)
+		}
+
+		lastProcessed = activity
+		n++
+	}
+
+	return lastProcessed, n, nil
+}
+
+func (s *Service) handleActivity(activity *vocab.ActivityType) error {
+	switch {
+	case activity.Type().Is(vocab.TypeCreate):
+		return s.handleCreateActivity(activity)
+	case activity.Type().Is(vocab.TypeAnnounce):
+		return s.handleAnnounceActivity(activity)
+	default:
+		logger.Debugf("Ignoring activity [%s] of type %s", activity.ID(), activity.Type())
+
+		return nil
+	}
+}
+
+func (s *Service) handleCreateActivity(create *vocab.ActivityType) error {
+	obj := create.Object()
+
+	t := obj.Type()
+
+	switch {
+	case t.Is(vocab.TypeAnchorCredential, vocab.TypeVerifiableCredential):
+		return s.handleAnchorCredential(create.Actor(), create.Target(), obj.Object())
+
+	case t.Is(vocab.TypeAnchorRef):
+		ref := obj.AnchorReference()
+
+		return s.handleAnchorCredential(create.Actor(), ref.Target(), ref.Object().Object())
+
+	default:
+		return fmt.Errorf("unsupported object type in 'Create' activity [%s]: %s", create.ID(), t)
+	}
+}
+
+func (s *Service) handleAnnounceActivity(announce *vocab.ActivityType) error {
+	var items []*vocab.ObjectProperty
+
+	obj := announce.Object()
+
+	switch {
+	case obj.Type().Is(vocab.TypeCollection):
+		items = obj.Collection().Items()
+	case obj.Type().Is(vocab.TypeOrderedCollection):
+		items = obj.OrderedCollection().Items()
+	case obj.Type().Is(vocab.TypeSignedTreeHead):
+		logger.Debugf("Ignoring signed tree head in 'Announce' activity [%s]", announce.ID())
+
+		return nil
+	default:
+		return fmt.Errorf("unsupported object type in 'Announce' activity [%s]: %s", announce.ID(), obj.Type())
+	}
+
+	for _, item := range items {
+		if !item.Type().Is(vocab.TypeAnchorRef) {
+			return fmt.Errorf("expecting 'AnchorReference' type in 'Announce' activity [%s]", announce.ID())
+		}
+
+		ref := item.AnchorReference()
+
+		if err := s.handleAnchorCredential(announce.Actor(), ref.Target(), ref.Object().Object()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) handleAnchorCredential(actor *url.URL, target *vocab.ObjectProperty, obj *vocab.ObjectType) error {
+	if target == nil || !target.Type().Is(vocab.TypeContentAddressedStorage) {
+		return fmt.Errorf("unsupported target type")
+	}
+
+	targetIRI := target.Object().ID().URL()
+
+	ok, err := s.hasAnchorCredential(targetIRI)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		logger.Debugf("Ignoring anchor credential [%s] since it was already processed", targetIRI)
+
+		return nil
+	}
+
+	var bytes []byte
+
+	if obj != nil {
+		bytes, err = json.Marshal(obj)
+		if err != nil {
+			return fmt.Errorf("marshal anchor credential: %w", err)
+		}
+	}
+
+	err = s.AnchorCredentialHandler.HandleAnchorCredential(actor, targetIRI, target.Object().CID(), bytes)
+	if err != nil {
+		return fmt.Errorf("handle anchor credential [%s]: %w", targetIRI, err)
+	}
+
+	logger.Debugf("Storing anchor credential reference [%s]", targetIRI)
+
+	err = s.ActivityStore.AddReference(store.AnchorCredential, targetIRI, s.config.ServiceIRI)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store anchor credential reference: %w", err))
+	}
+
+	return nil
+}
+
+func (s *Service) hasAnchorCredential(anchorCredIRI *url.URL) (bool, error) {
+	it, err := s.ActivityStore.QueryReferences(store.AnchorCredential,
+		store.NewCriteria(
+			store.WithObjectIRI(anchorCredIRI),
+			store.WithReferenceIRI(s.config.ServiceIRI),
+		),
+	)
+	if err != nil {
+		return false, orberrors.NewTransient(fmt.Errorf("query references: %w", err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e)
+		}
+	}()
+
+	_, err = it.Next()
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, orberrors.NewTransient(fmt.Errorf("get next reference: %w", err))
+	}
+
+	return true, nil
+}
+
+func (s *Service) getFollowing() ([]*url.URL, error) {
+	it, err := s.ActivityStore.QueryReferences(store.Following,
+		store.NewCriteria(store.WithObjectIRI(s.config.ServiceIRI)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query references: %w", err)
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e)
+		}
+	}()
+
+	return storeutil.ReadReferences(it, -1)
+}
+
+func (s *Service) getCheckpoint(serviceIRI *url.URL) (*checkpoint, error) {
+	cpBytes, err := s.store.Get(serviceIRI.String())
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, nil
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("get checkpoint: %w", err))
+	}
+
+	cp := &checkpoint{}
+
+	if err := json.Unmarshal(cpBytes, cp); err != nil {
+		return nil, fmt.Errorf("unmarshal checkpoint: %w", err)
+	}
+
+	return cp, nil
+}
+
+func (s *Service) putCheckpoint(serviceIRI *url.URL, activityID string) error {
+	cpBytes, err := json.Marshal(&checkpoint{
+		ActivityID: activityID,
+		Updated:    time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("marshal checkpoint: %w", err)
+	}
+
+	logger.Debugf("Saving checkpoint for [%s]: %s", serviceIRI, activityID)
+
+	if err := s.store.Put(serviceIRI.String(), cpBytes); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("put checkpoint: %w", err))
+	}
+
+	return nil
+}
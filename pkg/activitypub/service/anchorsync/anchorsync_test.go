@@ -0,0 +1,526 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorsync
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client"
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/aptestutil"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+var (
+	service1IRI = testutil.MustParseURL("https://orb.domain1.com/services/orb")
+	service2IRI = testutil.MustParseURL("https://orb.domain2.com/services/orb")
+	outbox2IRI  = testutil.NewMockID(service2IRI, "/outbox")
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s, err := New(Config{ServiceIRI: service1IRI, Interval: time.Second}, &Providers{}, mem.NewProvider())
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		p := &mockStoreProvider{Provider: mem.NewProvider(), openErr: errors.New("injected open error")}
+
+		s, err := New(Config{ServiceIRI: service1IRI}, &Providers{}, p)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected open error")
+		require.Nil(t, s)
+	})
+}
+
+func TestService_Sync(t *testing.T) {
+	t.Run("Initial sync and resume from checkpoint", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI))
+
+		// The outbox is ordered newest first.
+		apClient.WithActivities(outbox2IRI, newCreateActivity(2), newCreateActivity(1), newCreateActivity(0))
+
+		s := newService(t, apClient, memstore.New(""), handler)
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{anchorCredID(0), anchorCredID(1), anchorCredID(2)}, handler.IDs())
+
+		cp, err := s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.NotNil(t, cp)
+		require.Equal(t, activityID(2), cp.ActivityID)
+
+		// Nothing new in the outbox.
+		require.NoError(t, s.sync(service2IRI))
+		require.Len(t, handler.IDs(), 3)
+
+		apClient.WithActivities(outbox2IRI,
+			newAnnounceActivity(6, 4, 5), newCreateActivity(3),
+			newCreateActivity(2), newCreateActivity(1), newCreateActivity(0),
+		)
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{
+			anchorCredID(0), anchorCredID(1), anchorCredID(2),
+			anchorCredID(3), anchorCredID(4), anchorCredID(5),
+		}, handler.IDs())
+
+		cp, err = s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.Equal(t, activityID(6), cp.ActivityID)
+	})
+
+	t.Run("Gap detected", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI))
+		activityStore := memstore.New("")
+
+		apClient.WithActivities(outbox2IRI, newCreateActivity(1), newCreateActivity(0))
+
+		s := newService(t, apClient, activityStore, handler)
+
+		// Anchor credential 0 was already received by the inbox.
+		require.NoError(t, activityStore.AddReference(store.AnchorCredential,
+			testutil.MustParseURL(anchorCredID(0)), service1IRI))
+
+		require.NoError(t, s.putCheckpoint(service2IRI, "https://orb.domain2.com/activities/unknown"))
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{anchorCredID(1)}, handler.IDs())
+
+		cp, err := s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.Equal(t, activityID(1), cp.ActivityID)
+	})
+
+	t.Run("Transient handler error", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+		handler.failOn[anchorCredID(1)] = orberrors.NewTransient(errors.New("injected transient error"))
+
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI))
+		apClient.WithActivities(outbox2IRI, newCreateActivity(2), newCreateActivity(1), newCreateActivity(0))
+
+		s := newService(t, apClient, memstore.New(""), handler)
+
+		err := s.sync(service2IRI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected transient error")
+		require.Equal(t, []string{anchorCredID(0)}, handler.IDs())
+
+		cp, err := s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.Equal(t, activityID(0), cp.ActivityID)
+
+		delete(handler.failOn, anchorCredID(1))
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{anchorCredID(0), anchorCredID(1), anchorCredID(2)}, handler.IDs())
+	})
+
+	t.Run("Persistent handler error -> activity skipped", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+		handler.failOn[anchorCredID(1)] = errors.New("injected handler error")
+
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI))
+		apClient.WithActivities(outbox2IRI,
+			newCreateActivity(2), newCreateActivity(1), newCreateActivity(0),
+			vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(service1IRI)),
+				vocab.WithID(testutil.MustParseURL("https://orb.domain2.com/activities/follow")),
+				vocab.WithActor(service2IRI),
+			),
+		)
+
+		s := newService(t, apClient, memstore.New(""), handler)
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{anchorCredID(0), anchorCredID(2)}, handler.IDs())
+
+		cp, err := s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.Equal(t, activityID(2), cp.ActivityID)
+	})
+
+	t.Run("Get actor error", func(t *testing.T) {
+		s := newService(t, mocks.NewActorRetriever(), memstore.New(""), newMockAnchorCredHandler())
+
+		err := s.sync(service2IRI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get actor")
+	})
+
+	t.Run("Get activities error", func(t *testing.T) {
+		s := newService(t, mocks.NewActorRetriever().WithError(errors.New("injected client error")),
+			memstore.New(""), newMockAnchorCredHandler())
+
+		page, _, err := s.findCheckpoint(outbox2IRI, nil)
+		require.Error(t, err)
+		require.Nil(t, page)
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Get next page error", func(t *testing.T) {
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI)).WithPageSize(1)
+		apClient.WithActivities(outbox2IRI, newCreateActivity(1), newCreateActivity(0))
+
+		s := newService(t, &pageErrClient{
+			ActorRetriever: apClient,
+			failOn:         outbox2IRI.String() + "?page-num=1",
+		}, memstore.New(""), newMockAnchorCredHandler())
+
+		err := s.sync(service2IRI)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected page error")
+	})
+
+	t.Run("Get previous page error", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI)).WithPageSize(1)
+		apClient.WithActivities(outbox2IRI, newCreateActivity(1), newCreateActivity(0))
+
+		c := &pageErrClient{ActorRetriever: apClient}
+
+		s := newService(t, c, memstore.New(""), handler)
+
+		// Fail when going back to the first page, after the oldest page was processed.
+		c.failOn = outbox2IRI.String() + "?page-num=0"
+
+		err := s.sync(service2IRI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected page error")
+		require.Equal(t, []string{anchorCredID(0)}, handler.IDs())
+
+		cp, err := s.getCheckpoint(service2IRI)
+		require.NoError(t, err)
+		require.Equal(t, activityID(0), cp.ActivityID)
+
+		c.failOn = ""
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{anchorCredID(0), anchorCredID(1)}, handler.IDs())
+	})
+
+	t.Run("Multiple pages", func(t *testing.T) {
+		handler := newMockAnchorCredHandler()
+
+		apClient := mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI)).WithPageSize(3)
+
+		var activities []*vocab.ActivityType
+
+		for i := 9; i >= 0; i-- {
+			activities = append(activities, newCreateActivity(i))
+		}
+
+		apClient.WithActivities(outbox2IRI, activities[5:]...)
+
+		s := newService(t, apClient, memstore.New(""), handler)
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Equal(t, []string{
+			anchorCredID(0), anchorCredID(1), anchorCredID(2), anchorCredID(3), anchorCredID(4),
+		}, handler.IDs())
+
+		// The checkpoint is now in the middle of a page.
+		apClient.WithActivities(outbox2IRI, activities...)
+
+		require.NoError(t, s.sync(service2IRI))
+		require.Len(t, handler.IDs(), 10)
+
+		for i, id := range handler.IDs() {
+			require.Equal(t, anchorCredID(i), id)
+		}
+	})
+
+	t.Run("Get checkpoint error", func(t *testing.T) {
+		p := &mockStoreProvider{Provider: mem.NewProvider(), getErr: errors.New("injected get error")}
+
+		s, err := New(Config{ServiceIRI: service1IRI}, &Providers{
+			Client: mocks.NewActorRetriever().WithActor(aptestutil.NewMockService(service2IRI)),
+		}, p)
+		require.NoError(t, err)
+
+		err = s.sync(service2IRI)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected get error")
+	})
+}
+
+func TestService_StartStop(t *testing.T) {
+	service3IRI := testutil.MustParseURL("https://orb.domain3.com/services/orb")
+	outbox3IRI := testutil.NewMockID(service3IRI, "/outbox")
+
+	handler := newMockAnchorCredHandler()
+	activityStore := memstore.New("")
+
+	apClient := mocks.NewActorRetriever().
+		WithActor(aptestutil.NewMockService(service2IRI)).
+		WithActor(aptestutil.NewMockService(service3IRI)).
+		WithActivities(outbox2IRI, newCreateActivity(1), newCreateActivity(0)).
+		WithActivities(outbox3IRI, newCreateActivity(2))
+
+	activityChan := make(chan *vocab.ActivityType, 10)
+
+	s, err := New(Config{ServiceIRI: service1IRI, Interval: 50 * time.Millisecond},
+		&Providers{
+			Client:                  apClient,
+			ActivityStore:           activityStore,
+			AnchorCredentialHandler: handler,
+			Subscriber:              &mockSubscriber{ch: activityChan},
+		},
+		mem.NewProvider(),
+	)
+	require.NoError(t, err)
+
+	s.Start()
+	defer s.Stop()
+
+	follow := vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+		vocab.WithID(testutil.MustParseURL("https://orb.domain1.com/activities/follow")),
+		vocab.WithActor(service1IRI),
+	)
+
+	// An 'Accept' of the 'Follow' triggers a synchronization with the followed service.
+	activityChan <- vocab.NewAcceptActivity(vocab.NewObjectProperty(vocab.WithActivity(follow)),
+		vocab.WithID(testutil.MustParseURL("https://orb.domain2.com/activities/accept")),
+		vocab.WithActor(service2IRI),
+	)
+
+	require.Eventually(t, func() bool { return len(handler.IDs()) == 2 }, time.Second, 10*time.Millisecond)
+
+	// The periodic synchronization processes all followed services.
+	require.NoError(t, activityStore.AddReference(store.Following, service1IRI, service3IRI))
+
+	require.Eventually(t, func() bool { return len(handler.IDs()) == 3 }, time.Second, 10*time.Millisecond)
+
+	close(activityChan)
+
+	time.Sleep(100 * time.Millisecond)
+}
+
+func TestService_RequestSync(t *testing.T) {
+	s := newService(t, mocks.NewActorRetriever(), memstore.New(""), newMockAnchorCredHandler())
+
+	// The worker isn't started so the queue fills up. Requests must not block when the queue is full.
+	for i := 0; i < syncQueueSize+10; i++ {
+		s.requestSync(service2IRI)
+	}
+
+	require.Len(t, s.syncChan, syncQueueSize)
+}
+
+func TestService_AcceptedFollow(t *testing.T) {
+	s := newService(t, mocks.NewActorRetriever(), memstore.New(""), newMockAnchorCredHandler())
+
+	newFollow := func(actor *url.URL) *vocab.ActivityType {
+		return vocab.NewFollowActivity(vocab.NewObjectProperty(vocab.WithIRI(service2IRI)),
+			vocab.WithID(testutil.MustParseURL("https://orb.domain1.com/activities/follow")),
+			vocab.WithActor(actor),
+		)
+	}
+
+	iri, ok := s.acceptedFollow(vocab.NewAcceptActivity(
+		vocab.NewObjectProperty(vocab.WithActivity(newFollow(service1IRI))), vocab.WithActor(service2IRI)),
+	)
+	require.True(t, ok)
+	require.Equal(t, service2IRI.String(), iri.String())
+
+	_, ok = s.acceptedFollow(vocab.NewAcceptActivity(
+		vocab.NewObjectProperty(vocab.WithActivity(newFollow(service2IRI))), vocab.WithActor(service2IRI)),
+	)
+	require.False(t, ok)
+
+	_, ok = s.acceptedFollow(vocab.NewRejectActivity(
+		vocab.NewObjectProperty(vocab.WithActivity(newFollow(service1IRI))), vocab.WithActor(service2IRI)),
+	)
+	require.False(t, ok)
+
+	_, ok = s.acceptedFollow(vocab.NewAcceptActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(service1IRI)), vocab.WithActor(service2IRI)),
+	)
+	require.False(t, ok)
+}
+
+func TestService_HandleActivity(t *testing.T) {
+	s := newService(t, mocks.NewActorRetriever(), memstore.New(""), newMockAnchorCredHandler())
+
+	t.Run("Unsupported object in 'Create'", func(t *testing.T) {
+		err := s.handleActivity(vocab.NewCreateActivity(vocab.NewObjectProperty(vocab.WithIRI(service1IRI))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported object type in 'Create' activity")
+	})
+
+	t.Run("Unsupported object in 'Announce'", func(t *testing.T) {
+		err := s.handleActivity(vocab.NewAnnounceActivity(vocab.NewObjectProperty(vocab.WithIRI(service1IRI))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported object type in 'Announce' activity")
+	})
+
+	t.Run("Unsupported item in 'Announce'", func(t *testing.T) {
+		err := s.handleActivity(vocab.NewAnnounceActivity(vocab.NewObjectProperty(vocab.WithCollection(
+			vocab.NewCollection([]*vocab.ObjectProperty{vocab.NewObjectProperty(vocab.WithIRI(service1IRI))}),
+		))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "expecting 'AnchorReference' type")
+	})
+}
+
+func newService(t *testing.T, apClient activityPubClient, activityStore store.Store,
+	handler *mockAnchorCredHandler) *Service {
+	t.Helper()
+
+	s, err := New(Config{ServiceIRI: service1IRI, Interval: time.Minute},
+		&Providers{
+			Client:                  apClient,
+			ActivityStore:           activityStore,
+			AnchorCredentialHandler: handler,
+		},
+		mem.NewProvider(),
+	)
+	require.NoError(t, err)
+
+	return s
+}
+
+func activityID(i int) string {
+	return fmt.Sprintf("https://orb.domain2.com/activities/%d", i)
+}
+
+func anchorCredID(i int) string {
+	return fmt.Sprintf("https://orb.domain2.com/cas/anchor_%d", i)
+}
+
+func newAnchorRef(i int) *vocab.AnchorReferenceType {
+	return vocab.NewAnchorReference(
+		testutil.MustParseURL(fmt.Sprintf("https://orb.domain2.com/transactions/%d", i)),
+		testutil.MustParseURL(anchorCredID(i)),
+		fmt.Sprintf("anchor_%d", i),
+	)
+}
+
+func newCreateActivity(i int) *vocab.ActivityType {
+	return vocab.NewCreateActivity(
+		vocab.NewObjectProperty(vocab.WithAnchorReference(newAnchorRef(i))),
+		vocab.WithID(testutil.MustParseURL(activityID(i))),
+		vocab.WithActor(service2IRI),
+	)
+}
+
+func newAnnounceActivity(i int, refs ...int) *vocab.ActivityType {
+	items := make([]*vocab.ObjectProperty, len(refs))
+
+	for j, ref := range refs {
+		items[j] = vocab.NewObjectProperty(vocab.WithAnchorReference(newAnchorRef(ref)))
+	}
+
+	return vocab.NewAnnounceActivity(
+		vocab.NewObjectProperty(vocab.WithCollection(vocab.NewCollection(items))),
+		vocab.WithID(testutil.MustParseURL(activityID(i))),
+		vocab.WithActor(service2IRI),
+	)
+}
+
+type mockAnchorCredHandler struct {
+	mutex  sync.Mutex
+	ids    []string
+	failOn map[string]error
+}
+
+func newMockAnchorCredHandler() *mockAnchorCredHandler {
+	return &mockAnchorCredHandler{failOn: make(map[string]error)}
+}
+
+func (m *mockAnchorCredHandler) HandleAnchorCredential(_, id *url.URL, _ string, _ []byte) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err := m.failOn[id.String()]; err != nil {
+		return err
+	}
+
+	m.ids = append(m.ids, id.String())
+
+	return nil
+}
+
+func (m *mockAnchorCredHandler) IDs() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return append([]string{}, m.ids...)
+}
+
+type mockSubscriber struct {
+	ch chan *vocab.ActivityType
+}
+
+func (m *mockSubscriber) Subscribe() <-chan *vocab.ActivityType {
+	return m.ch
+}
+
+type mockStoreProvider struct {
+	storage.Provider
+
+	openErr error
+	getErr  error
+}
+
+func (m *mockStoreProvider) OpenStore(name string) (storage.Store, error) {
+	if m.openErr != nil {
+		return nil, m.openErr
+	}
+
+	s, err := m.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mockStore{Store: s, getErr: m.getErr}, nil
+}
+
+type mockStore struct {
+	storage.Store
+
+	getErr error
+}
+
+func (m *mockStore) Get(key string) ([]byte, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	return m.Store.Get(key)
+}
+
+type pageErrClient struct {
+	*mocks.ActorRetriever
+
+	failOn string
+}
+
+func (c *pageErrClient) GetActivitiesPage(iri *url.URL) (*client.ActivityPage, error) {
+	if iri.String() == c.failOn {
+		return nil, errors.New("injected page error")
+	}
+
+	return c.ActorRetriever.GetActivitiesPage(iri)
+}
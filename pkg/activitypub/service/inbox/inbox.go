@@ -223,6 +223,11 @@ func (h *Inbox) handleActivityMsg(msg *message.Message) (*vocab.ActivityType, er
 		if orberrors.IsTransient(err) {
 			return nil, err
 		}
+
+		// Activities from blocked actors are rejected and are not added to the inbox.
+		if errors.Is(err, service.ErrActorBlocked) {
+			return nil, err
+		}
 	}
 
 	logger.Debugf("[%s] Handled message [%s]. Adding activity to inbox...", h.ServiceEndpoint, msg.UUID)
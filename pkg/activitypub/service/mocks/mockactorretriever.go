@@ -16,19 +16,32 @@ import (
 
 // ActorRetriever is a mock retriever for actors and public keys of actors.
 type ActorRetriever struct {
-	actors map[string]*vocab.ActorType
-	keys   map[string]*vocab.PublicKeyType
-	err    error
+	actors     map[string]*vocab.ActorType
+	keys       map[string]*vocab.PublicKeyType
+	activities map[string][]*vocab.ActivityType
+	pageSize   int
+	err        error
 }
 
+const defaultActivitiesPageSize = 2
+
 // NewActorRetriever returns a mock actor retriever.
 func NewActorRetriever() *ActorRetriever {
 	return &ActorRetriever{
-		actors: make(map[string]*vocab.ActorType),
-		keys:   make(map[string]*vocab.PublicKeyType),
+		actors:     make(map[string]*vocab.ActorType),
+		keys:       make(map[string]*vocab.PublicKeyType),
+		activities: make(map[string][]*vocab.ActivityType),
+		pageSize:   defaultActivitiesPageSize,
 	}
 }
 
+// WithPageSize sets the page size used by GetActivitiesPage.
+func (m *ActorRetriever) WithPageSize(pageSize int) *ActorRetriever {
+	m.pageSize = pageSize
+
+	return m
+}
+
 // WithPublicKey adds the given public key to the map of keys which is used
 // by GetPublicKey.
 func (m *ActorRetriever) WithPublicKey(key *vocab.PublicKeyType) *ActorRetriever {
@@ -45,6 +58,13 @@ func (m *ActorRetriever) WithActor(actor *vocab.ActorType) *ActorRetriever {
 	return m
 }
 
+// WithActivities sets the activities that are returned by GetActivitiesPage for the given IRI.
+func (m *ActorRetriever) WithActivities(iri *url.URL, activities ...*vocab.ActivityType) *ActorRetriever {
+	m.activities[iri.String()] = activities
+
+	return m
+}
+
 // WithError sets an error to be returned when any function is invoked on this struct.
 func (m *ActorRetriever) WithError(err error) *ActorRetriever {
 	m.err = err
@@ -82,6 +102,11 @@ func (m *ActorRetriever) GetActor(actorIRI *url.URL) (*vocab.ActorType, error) {
 	return actor, nil
 }
 
+// RefreshActor returns the actor for the given IRI.
+func (m *ActorRetriever) RefreshActor(actorIRI *url.URL) (*vocab.ActorType, error) {
+	return m.GetActor(actorIRI)
+}
+
 // GetReferences simply returns an iterator that contains the IRI passed as an arg.
 func (m *ActorRetriever) GetReferences(iri *url.URL) (client.ReferenceIterator, error) {
 	if m.err != nil {
@@ -94,3 +119,67 @@ func (m *ActorRetriever) GetReferences(iri *url.URL) (client.ReferenceIterator,
 
 	return it, nil
 }
+
+// GetActivitiesPage returns a page of the activities that were set for the given IRI. The page IRIs
+// have the form {iri}?page-num={n} where n is zero for the first page.
+func (m *ActorRetriever) GetActivitiesPage(iri *url.URL) (*client.ActivityPage, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	collIRI, pageNum, err := m.parsePageIRI(iri)
+	if err != nil {
+		return nil, err
+	}
+
+	activities := m.activities[collIRI]
+
+	start := pageNum * m.pageSize
+	if start > len(activities) {
+		return nil, fmt.Errorf("page not found: %s", iri)
+	}
+
+	end := start + m.pageSize
+	if end > len(activities) {
+		end = len(activities)
+	}
+
+	page := &client.ActivityPage{Activities: activities[start:end]}
+
+	if end < len(activities) {
+		page.Next = pageIRI(collIRI, pageNum+1)
+	}
+
+	if pageNum > 0 {
+		page.Prev = pageIRI(collIRI, pageNum-1)
+	}
+
+	return page, nil
+}
+
+func (m *ActorRetriever) parsePageIRI(iri *url.URL) (string, int, error) {
+	pageNumStr := iri.Query().Get("page-num")
+	if pageNumStr == "" {
+		return iri.String(), 0, nil
+	}
+
+	var pageNum int
+
+	if _, err := fmt.Sscanf(pageNumStr, "%d", &pageNum); err != nil {
+		return "", 0, fmt.Errorf("invalid page number: %s", iri)
+	}
+
+	collIRI := *iri
+	collIRI.RawQuery = ""
+
+	return collIRI.String(), pageNum, nil
+}
+
+func pageIRI(collIRI string, pageNum int) *url.URL {
+	u, err := url.Parse(fmt.Sprintf("%s?page-num=%d", collIRI, pageNum))
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
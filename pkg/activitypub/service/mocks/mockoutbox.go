@@ -64,6 +64,20 @@ func (m *Outbox) Post(activity *vocab.ActivityType) (*url.URL, error) {
 	return m.activityID, nil
 }
 
+// Redeliver stores the activity so that it may be retrieved by the Activities function.
+func (m *Outbox) Redeliver(activity *vocab.ActivityType, _ *url.URL) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.activities = append(m.activities, activity)
+
+	return nil
+}
+
 // Start does nothing.
 func (m *Outbox) Start() {
 }
@@ -0,0 +1,51 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package mocks
+
+import (
+	"net/url"
+	"sync"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+)
+
+// SignedTreeHeadHandler implements a mock signed tree head handler.
+type SignedTreeHeadHandler struct {
+	mutex sync.Mutex
+	err   error
+	sths  []*vocab.SignedTreeHeadType
+}
+
+// NewSignedTreeHeadHandler returns a mock handler.
+func NewSignedTreeHeadHandler() *SignedTreeHeadHandler {
+	return &SignedTreeHeadHandler{}
+}
+
+// WithError injects an error.
+func (m *SignedTreeHeadHandler) WithError(err error) *SignedTreeHeadHandler {
+	m.err = err
+
+	return m
+}
+
+// HandleSignedTreeHead handles the signed tree head that was announced by another Orb server.
+func (m *SignedTreeHeadHandler) HandleSignedTreeHead(actor *url.URL, sth *vocab.SignedTreeHeadType) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sths = append(m.sths, sth)
+
+	return m.err
+}
+
+// SignedTreeHeads returns the signed tree heads that were added to this mock.
+func (m *SignedTreeHeadHandler) SignedTreeHeads() []*vocab.SignedTreeHeadType {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.sths
+}
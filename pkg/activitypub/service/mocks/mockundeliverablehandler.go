@@ -17,6 +17,7 @@ import (
 type UndeliverableActivity struct {
 	Activity *vocab.ActivityType
 	ToURL    string
+	Reason   error
 }
 
 // UndeliverableHandler implements a mock undeliverable activity handler.
@@ -31,13 +32,14 @@ func NewUndeliverableHandler() *UndeliverableHandler {
 }
 
 // HandleUndeliverableActivity adds the given undeliverable activity to a map that may be later queried by unit tests.
-func (h *UndeliverableHandler) HandleUndeliverableActivity(activity *vocab.ActivityType, toURL string) {
+func (h *UndeliverableHandler) HandleUndeliverableActivity(activity *vocab.ActivityType, toURL string, reason error) {
 	h.mutex.Lock()
 	defer h.mutex.Unlock()
 
 	h.activities = append(h.activities, &UndeliverableActivity{
 		Activity: activity,
 		ToURL:    toURL,
+		Reason:   reason,
 	})
 }
 
@@ -7,18 +7,30 @@ SPDX-License-Identifier: Apache-2.0
 package monitoring
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/bluele/gcache"
+	"github.com/google/trillian/merkle/logverifier"
+	"github.com/google/trillian/merkle/rfc6962/hasher"
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/crypto/tinkcrypto"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms/localkms"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/piprate/json-gold/ld"
 	"github.com/sirupsen/logrus"
 	"github.com/trustbloc/vct/pkg/client/vct"
+	"github.com/trustbloc/vct/pkg/controller/command"
 
 	"github.com/trustbloc/orb/pkg/webfinger/model"
 )
@@ -26,11 +38,46 @@ import (
 var logger = logrus.New()
 
 const (
-	storeName       = "monitoring"
-	keyPrefix       = "queue"
-	tagNotConfirmed = "not_confirmed"
+	storeName         = "monitoring"
+	keyPrefix         = "queue"
+	sthKeyPrefix      = "sth"
+	incidentKeyPrefix = "incident"
+	tagNotConfirmed   = "not_confirmed"
+	tagSTH            = "sth"
+	tagIncident       = "incident"
+
+	defaultAuditInterval = time.Minute
+
+	publicKeyCacheSize       = 100
+	publicKeyCacheExpiration = time.Hour
 )
 
+// IncidentType is the type of misbehaviour that was detected in a transparency log.
+type IncidentType string
+
+const (
+	// IncidentSplitView indicates that a log presented a signed tree head that is not consistent with
+	// a signed tree head that was previously observed, i.e. the log is not append-only.
+	IncidentSplitView IncidentType = "split_view"
+
+	// IncidentMissingInclusion indicates that a credential was not included in a log within the time
+	// promised by the witness.
+	IncidentMissingInclusion IncidentType = "missing_inclusion"
+
+	// IncidentGossipConflict indicates that a signed tree head reported by a peer is not consistent with
+	// the locally observed signed tree head of the same log.
+	IncidentGossipConflict IncidentType = "gossip_conflict"
+)
+
+// Incident is a record of misbehaviour detected in a transparency log.
+type Incident struct {
+	ID      string       `json:"id"`
+	Type    IncidentType `json:"type"`
+	Domain  string       `json:"domain"`
+	Details string       `json:"details"`
+	Created time.Time    `json:"created"`
+}
+
 // httpClient represents HTTP client.
 type httpClient interface {
 	Do(req *http.Request) (*http.Response, error)
@@ -40,13 +87,22 @@ type webfingerClient interface {
 	GetLedgerType(domain string) (string, error)
 }
 
+type metricsProvider interface {
+	VCTLogIncident(incidentType string)
+}
+
 // Client for the monitoring.
 type Client struct {
 	documentLoader ld.DocumentLoader
 	store          storage.Store
 	http           httpClient
 	ticker         *time.Ticker
+	auditTicker    *time.Ticker
+	auditInterval  time.Duration
 	wfClient       webfingerClient
+	metrics        metricsProvider
+	auditMutex     sync.Mutex
+	publicKeys     gcache.Cache
 }
 
 // Opt represents client option func.
@@ -59,6 +115,21 @@ func WithHTTPClient(client httpClient) Opt {
 	}
 }
 
+// WithAuditInterval sets the interval at which the signed tree heads of all known logs are
+// checked for consistency.
+func WithAuditInterval(interval time.Duration) Opt {
+	return func(o *Client) {
+		o.auditInterval = interval
+	}
+}
+
+// WithMetrics sets the metrics provider which is notified of log incidents.
+func WithMetrics(metrics metricsProvider) Opt {
+	return func(o *Client) {
+		o.metrics = metrics
+	}
+}
+
 // New returns monitoring client.
 func New(provider storage.Provider, documentLoader ld.DocumentLoader, wfClient webfingerClient, opts ...Opt) (*Client, error) { //nolint:lll
 	store, err := provider.OpenStore(storeName)
@@ -66,7 +137,9 @@ func New(provider storage.Provider, documentLoader ld.DocumentLoader, wfClient w
 		return nil, fmt.Errorf("open store: %w", err)
 	}
 
-	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{tagNotConfirmed}})
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{
+		TagNames: []string{tagNotConfirmed, tagSTH, tagIncident},
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to set store configuration: %w", err)
 	}
@@ -77,13 +150,24 @@ func New(provider storage.Provider, documentLoader ld.DocumentLoader, wfClient w
 		ticker:         time.NewTicker(time.Second),
 		http:           &http.Client{Timeout: time.Minute},
 		wfClient:       wfClient,
+		auditInterval:  defaultAuditInterval,
+		metrics:        &noopMetrics{},
 	}
 
 	for _, opt := range opts {
 		opt(client)
 	}
 
+	client.auditTicker = time.NewTicker(client.auditInterval)
+
+	client.publicKeys = gcache.New(publicKeyCacheSize).
+		Expiration(publicKeyCacheExpiration).
+		LoaderFunc(func(domain interface{}) (interface{}, error) {
+			return client.getPublicKey(domain.(string))
+		}).Build()
+
 	go client.worker()
+	go client.auditor()
 
 	return client, nil
 }
@@ -126,6 +210,12 @@ func (c *Client) exist(vc *verifiable.Credential, e *entity) error {
 		return fmt.Errorf("get STH: %w", err)
 	}
 
+	// checks that the log is consistent with the signed tree head that was previously observed. Audit failures
+	// are recorded as incidents and don't affect the inclusion check.
+	if err = c.auditSTH(e.Domain, vctClient, sth); err != nil {
+		logger.Warnf("audit STH for log %q: %v", e.Domain, err)
+	}
+
 	// gets proof by hash
 	resp, err := vctClient.GetProofByHash(context.Background(), hash, sth.TreeSize)
 	if err != nil {
@@ -148,9 +238,18 @@ func (c *Client) worker() {
 	}
 }
 
+func (c *Client) auditor() {
+	for range c.auditTicker.C {
+		if err := c.auditLogs(); err != nil {
+			logger.Errorf("audit logs: %v", err)
+		}
+	}
+}
+
 // Close stops ticker.
 func (c *Client) Close() {
 	c.ticker.Stop()
+	c.auditTicker.Stop()
 }
 
 // Next is helper function that simplifies the usage of the iterator.
@@ -217,6 +316,9 @@ func (c *Client) handleEntities() error {
 
 		logger.Errorf("credential %q existence in the Merkle tree not confirmed", vc.ID)
 
+		c.raiseIncident("", IncidentMissingInclusion, e.Domain,
+			fmt.Sprintf("credential %q was not included in the log by %s", vc.ID, e.ExpirationDate))
+
 		// removes entity from the store bc we failed our promise (log above).
 		if err = c.store.Delete(key(vc.ID)); err != nil {
 			logger.Errorf("delete credential %q from queue: %v", vc.ID, err)
@@ -264,6 +366,9 @@ func (c *Client) Watch(vc *verifiable.Credential, endTime time.Time, domain stri
 	if errors.Is(err, errExpired) {
 		logger.Errorf("credential %q existence in the Merkle tree not confirmed", vc.ID)
 
+		c.raiseIncident("", IncidentMissingInclusion, domain,
+			fmt.Sprintf("credential %q was not included in the log by %s", vc.ID, endTime))
+
 		return err
 	}
 
@@ -289,3 +394,428 @@ func (c *Client) Watch(vc *verifiable.Credential, endTime time.Time, domain stri
 func key(id string) string {
 	return keyPrefix + id
 }
+
+// Incidents returns all of the log incidents that were detected.
+func (c *Client) Incidents() ([]*Incident, error) {
+	records, err := c.store.Query(tagIncident)
+	if err != nil {
+		return nil, fmt.Errorf("query %q entities: %w", tagIncident, err)
+	}
+
+	defer storage.Close(records, logger)
+
+	var incidents []*Incident
+
+	for Next(records) {
+		src, err := records.Value()
+		if err != nil {
+			return nil, fmt.Errorf("get incident value: %w", err)
+		}
+
+		incident := &Incident{}
+		if err := json.Unmarshal(src, incident); err != nil {
+			return nil, fmt.Errorf("unmarshal incident: %w", err)
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	return incidents, nil
+}
+
+// errSplitView is returned when a log presents a signed tree head that is not consistent with
+// a previously observed signed tree head.
+var errSplitView = errors.New("split view")
+
+// errInvalidSignature is returned when the signature of a signed tree head can't be verified with
+// the public key of the log.
+var errInvalidSignature = errors.New("invalid signed tree head signature")
+
+// SignedTreeHead holds the signed tree head (STH) of a log.
+type SignedTreeHead struct {
+	Domain    string `json:"domain"`
+	TreeSize  uint64 `json:"tree_size"`
+	Timestamp uint64 `json:"timestamp"`
+	RootHash  []byte `json:"root_hash"`
+	Signature []byte `json:"signature"`
+}
+
+// SignedTreeHeads returns the latest signed tree head that was observed for each log.
+func (c *Client) SignedTreeHeads() ([]*SignedTreeHead, error) {
+	records, err := c.store.Query(tagSTH)
+	if err != nil {
+		return nil, fmt.Errorf("query %q entities: %w", tagSTH, err)
+	}
+
+	defer storage.Close(records, logger)
+
+	var sths []*SignedTreeHead
+
+	for Next(records) {
+		src, err := records.Value()
+		if err != nil {
+			return nil, fmt.Errorf("get STH value: %w", err)
+		}
+
+		sth := &SignedTreeHead{}
+		if err := json.Unmarshal(src, sth); err != nil {
+			return nil, fmt.Errorf("unmarshal STH: %w", err)
+		}
+
+		sths = append(sths, sth)
+	}
+
+	return sths, nil
+}
+
+// CheckSignedTreeHead cross-checks a signed tree head that was reported by a peer against the signed tree
+// head that was observed locally for the same log. The signature of the reported signed tree head is verified
+// against the public key of the log and an error is returned if it's missing or invalid. If the tree sizes differ
+// then a consistency proof is requested from the log. A conflict is recorded as an incident. Signed tree heads of
+// logs that are not monitored locally are ignored.
+func (c *Client) CheckSignedTreeHead(source string, sth *SignedTreeHead) error {
+	if sth.TreeSize == 0 {
+		return nil
+	}
+
+	local, err := c.getSTH(sth.Domain)
+	if err != nil {
+		return err
+	}
+
+	if local == nil {
+		logger.Debugf("ignoring STH from %s for log %q since the log is not monitored", source, sth.Domain)
+
+		return nil
+	}
+
+	// An STH that wasn't signed by the log can't be attributed to the log, so it's dropped without raising
+	// an incident.
+	if err := c.verifySTH(sth); err != nil {
+		return fmt.Errorf("STH reported by %s: %w", source, err)
+	}
+
+	first, second := local, sth
+	if first.TreeSize > second.TreeSize {
+		first, second = second, first
+	}
+
+	inconsistency, err := verifyConsistency(vct.New(sth.Domain, vct.WithHTTPClient(c.http)), first, second)
+	if err != nil {
+		return err
+	}
+
+	if inconsistency != "" {
+		details := fmt.Sprintf("STH reported by %s conflicts with local STH: %s", source, inconsistency)
+
+		c.raiseIncident(incidentID(IncidentGossipConflict, sth), IncidentGossipConflict, sth.Domain, details)
+
+		return fmt.Errorf("%w: %s", errSplitView, details)
+	}
+
+	return nil
+}
+
+// auditLogs checks that the latest signed tree head of each known log is consistent with
+// the signed tree head that was previously observed.
+func (c *Client) auditLogs() error {
+	records, err := c.store.Query(tagSTH)
+	if err != nil {
+		return fmt.Errorf("query %q entities: %w", tagSTH, err)
+	}
+
+	defer storage.Close(records, logger)
+
+	for Next(records) {
+		src, err := records.Value()
+		if err != nil {
+			return fmt.Errorf("get STH value: %w", err)
+		}
+
+		var prev *SignedTreeHead
+		if err := json.Unmarshal(src, &prev); err != nil {
+			logger.Errorf("unmarshal STH: %v", err)
+
+			continue
+		}
+
+		vctClient := vct.New(prev.Domain, vct.WithHTTPClient(c.http))
+
+		sth, err := vctClient.GetSTH(context.Background())
+		if err != nil {
+			logger.Warnf("get STH for log %q: %v", prev.Domain, err)
+
+			continue
+		}
+
+		if err := c.auditSTH(prev.Domain, vctClient, sth); err != nil {
+			logger.Warnf("audit STH for log %q: %v", prev.Domain, err)
+		}
+	}
+
+	return nil
+}
+
+// auditSTH verifies the signature of the given signed tree head and that it is consistent with the last signed
+// tree head that was observed for the log and, if so, persists it as the latest signed tree head. A signed tree
+// head that was already found to be inconsistent isn't checked again.
+func (c *Client) auditSTH(domain string, vctClient *vct.Client, sth *command.GetSTHResponse) error {
+	// an empty tree is consistent with any other tree.
+	if sth.TreeSize == 0 {
+		return nil
+	}
+
+	current := &SignedTreeHead{
+		Domain:    domain,
+		TreeSize:  sth.TreeSize,
+		Timestamp: sth.Timestamp,
+		RootHash:  sth.SHA256RootHash,
+		Signature: sth.TreeHeadSignature,
+	}
+
+	if err := c.verifySTH(current); err != nil {
+		return err
+	}
+
+	c.auditMutex.Lock()
+	defer c.auditMutex.Unlock()
+
+	prev, err := c.getSTH(domain)
+	if err != nil {
+		return err
+	}
+
+	if prev == nil {
+		return c.putSTH(current)
+	}
+
+	if current.TreeSize == prev.TreeSize && bytes.Equal(current.RootHash, prev.RootHash) {
+		return nil
+	}
+
+	splitViewID := incidentID(IncidentSplitView, current)
+
+	known, err := c.hasIncident(splitViewID)
+	if err != nil {
+		return err
+	}
+
+	if known {
+		return fmt.Errorf("%w: STH of tree size %d was already found to be inconsistent", errSplitView,
+			current.TreeSize)
+	}
+
+	if current.TreeSize < prev.TreeSize {
+		// a log never shrinks. The older STH is retained and the newer one is checked
+		// against it on the next audit.
+		return c.splitView(splitViewID, domain, fmt.Sprintf("tree size decreased from %d to %d",
+			prev.TreeSize, current.TreeSize))
+	}
+
+	inconsistency, err := verifyConsistency(vctClient, prev, current)
+	if err != nil {
+		return err
+	}
+
+	if inconsistency != "" {
+		return c.splitView(splitViewID, domain, inconsistency)
+	}
+
+	return c.putSTH(current)
+}
+
+// verifySTH verifies the signature of the given signed tree head against the public key of the log.
+func (c *Client) verifySTH(sth *SignedTreeHead) error {
+	pubKey, err := c.publicKeys.Get(sth.Domain)
+	if err != nil {
+		return fmt.Errorf("get public key of log %q: %w", sth.Domain, err)
+	}
+
+	if err := verifySTHSignature(pubKey.([]byte), sth); err != nil {
+		return fmt.Errorf("%w for tree size %d of log %q: %s", errInvalidSignature, sth.TreeSize, sth.Domain, err)
+	}
+
+	return nil
+}
+
+// getPublicKey resolves the public key of the log from the log's WebFinger endpoint.
+func (c *Client) getPublicKey(domain string) ([]byte, error) {
+	resp, err := vct.New(domain, vct.WithHTTPClient(c.http)).Webfinger(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("webfinger: %w", err)
+	}
+
+	pubKeyRaw, ok := resp.Properties[command.PublicKeyType]
+	if !ok {
+		return nil, errors.New("no public key")
+	}
+
+	pubKeyStr, ok := pubKeyRaw.(string)
+	if !ok {
+		return nil, errors.New("public key is not a string")
+	}
+
+	pubKey, err := base64.StdEncoding.DecodeString(pubKeyStr)
+	if err != nil {
+		return nil, fmt.Errorf("decode public key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+func verifySTHSignature(pubKey []byte, sth *SignedTreeHead) error {
+	if len(sth.Signature) == 0 {
+		return errors.New("missing signature")
+	}
+
+	var sig *command.DigitallySigned
+
+	if err := json.Unmarshal(sth.Signature, &sig); err != nil {
+		return fmt.Errorf("unmarshal signature: %w", err)
+	}
+
+	data, err := json.Marshal(command.TreeHeadSignature{
+		Version:        command.V1,
+		SignatureType:  command.TreeHeadSignatureType,
+		Timestamp:      sth.Timestamp,
+		TreeSize:       sth.TreeSize,
+		SHA256RootHash: sth.RootHash,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal tree head signature: %w", err)
+	}
+
+	kh, err := (&localkms.LocalKMS{}).PubKeyBytesToHandle(pubKey, sig.Algorithm.Type)
+	if err != nil {
+		return fmt.Errorf("pub key to handle: %w", err)
+	}
+
+	return (&tinkcrypto.Crypto{}).Verify(sig.Signature, data, kh) //nolint:wrapcheck
+}
+
+// verifyConsistency verifies that the second signed tree head (which must have a tree size that is greater
+// than or equal to the first) is consistent with the first signed tree head. A description of the
+// inconsistency is returned if they are not consistent.
+func verifyConsistency(vctClient *vct.Client, first, second *SignedTreeHead) (string, error) {
+	if first.TreeSize == second.TreeSize {
+		if !bytes.Equal(first.RootHash, second.RootHash) {
+			return fmt.Sprintf("different root hashes for tree size %d", first.TreeSize), nil
+		}
+
+		return "", nil
+	}
+
+	resp, err := vctClient.GetSTHConsistency(context.Background(), first.TreeSize, second.TreeSize)
+	if err != nil {
+		return "", fmt.Errorf("get STH consistency: %w", err)
+	}
+
+	err = logverifier.New(hasher.DefaultHasher).VerifyConsistencyProof(
+		int64(first.TreeSize), int64(second.TreeSize), first.RootHash, second.RootHash, resp.Consistency,
+	)
+	if err != nil {
+		return fmt.Sprintf("invalid consistency proof between tree sizes %d and %d: %s",
+			first.TreeSize, second.TreeSize, err), nil
+	}
+
+	return "", nil
+}
+
+func (c *Client) splitView(id, domain, details string) error {
+	c.raiseIncident(id, IncidentSplitView, domain, details)
+
+	return fmt.Errorf("%w: %s", errSplitView, details)
+}
+
+func (c *Client) getSTH(domain string) (*SignedTreeHead, error) {
+	src, err := c.store.Get(sthKeyPrefix + domain)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("get STH: %w", err)
+	}
+
+	var sth *SignedTreeHead
+	if err := json.Unmarshal(src, &sth); err != nil {
+		return nil, fmt.Errorf("unmarshal STH: %w", err)
+	}
+
+	return sth, nil
+}
+
+func (c *Client) putSTH(sth *SignedTreeHead) error {
+	src, err := json.Marshal(sth)
+	if err != nil {
+		return fmt.Errorf("marshal STH: %w", err)
+	}
+
+	if err := c.store.Put(sthKeyPrefix+sth.Domain, src, storage.Tag{Name: tagSTH}); err != nil {
+		return fmt.Errorf("put STH: %w", err)
+	}
+
+	return nil
+}
+
+// incidentID returns the ID of an incident of the given type for the given signed tree head so that the same
+// incident is only recorded once.
+func incidentID(incidentType IncidentType, sth *SignedTreeHead) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%x", incidentType, sth.Domain, sth.TreeSize, sth.RootHash)))
+
+	return hex.EncodeToString(hash[:])
+}
+
+func (c *Client) hasIncident(id string) (bool, error) {
+	_, err := c.store.Get(incidentKeyPrefix + id)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("get incident: %w", err)
+	}
+
+	return true, nil
+}
+
+// raiseIncident records the incident and notifies the metrics provider. If the ID is empty then a random ID is
+// generated, otherwise the incident isn't recorded again if an incident with the same ID already exists.
+func (c *Client) raiseIncident(id string, incidentType IncidentType, domain, details string) {
+	if id == "" {
+		id = uuid.New().String()
+	} else if exists, err := c.hasIncident(id); err != nil {
+		logger.Warnf("check for existing incident: %v", err)
+	} else if exists {
+		logger.Debugf("log incident [%s] for log %q was already recorded: %s", incidentType, domain, details)
+
+		return
+	}
+
+	logger.Errorf("log incident [%s] detected for log %q: %s", incidentType, domain, details)
+
+	c.metrics.VCTLogIncident(string(incidentType))
+
+	incident := &Incident{
+		ID:      id,
+		Type:    incidentType,
+		Domain:  domain,
+		Details: details,
+		Created: time.Now(),
+	}
+
+	src, err := json.Marshal(incident)
+	if err != nil {
+		logger.Errorf("marshal incident: %v", err)
+
+		return
+	}
+
+	if err := c.store.Put(incidentKeyPrefix+incident.ID, src, storage.Tag{Name: tagIncident}); err != nil {
+		logger.Errorf("store incident: %v", err)
+	}
+}
+
+type noopMetrics struct{}
+
+func (m *noopMetrics) VCTLogIncident(string) {}
@@ -8,21 +8,31 @@ package monitoring_test
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	backoff "github.com/cenkalti/backoff/v4"
+	"github.com/google/trillian/merkle/rfc6962/hasher"
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	mockstore "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/pkg/kms"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/vct/pkg/controller/command"
 
 	. "github.com/trustbloc/orb/pkg/activitypub/service/monitoring"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
@@ -481,6 +491,548 @@ func TestClient_Watch(t *testing.T) {
 	})
 }
 
+func TestClient_Audit(t *testing.T) {
+	wfClient := wfclient.New(wfclient.WithHTTPClient(httpMock(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(webfingerPayload)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})))
+
+	h := hasher.DefaultHasher
+
+	leaf1 := h.HashLeaf([]byte("leaf1"))
+	leaf2 := h.HashLeaf([]byte("leaf2"))
+
+	sth1 := &command.GetSTHResponse{TreeSize: 1, SHA256RootHash: leaf1}
+	sth2 := &command.GetSTHResponse{TreeSize: 2, SHA256RootHash: h.HashChildren(leaf1, leaf2)}
+
+	// The consistency proof between a tree of size 1 and a tree of size 2 is the hash of the second leaf.
+	validProof := &command.GetSTHConsistencyResponse{Consistency: [][]byte{leaf2}}
+
+	watch := func(t *testing.T, client *Client, endTime time.Time) error {
+		t.Helper()
+
+		ID := "https://orb.domain.com/" + uuid.New().String()
+
+		return client.Watch(&verifiable.Credential{
+			ID:      ID,
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Subject: ID,
+			Issuer:  verifiable.Issuer{ID: ID},
+			Issued:  &util.TimeWithTrailingZeroMsec{},
+			Types:   []string{"VerifiableCredential"},
+		}, endTime, "https://vct.com", time.Now())
+	}
+
+	t.Run("Consistent STHs", func(t *testing.T) {
+		db := mem.NewProvider()
+		log := newMockLog(t, sth1, validProof)
+
+		client, err := New(db, testutil.GetLoader(t), wfClient, WithHTTPClient(log))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		log.setSTH(sth2)
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+		require.Equal(t, 1, log.consistencyRequests())
+
+		// Same STH again requires no consistency proof.
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+		require.Equal(t, 1, log.consistencyRequests())
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+
+		checkQueue(t, db, 0)
+	})
+
+	t.Run("Split view -> different root for same tree size", func(t *testing.T) {
+		db := mem.NewProvider()
+		log := newMockLog(t, sth1, validProof)
+		metrics := &mockMetrics{}
+
+		client, err := New(db, testutil.GetLoader(t), wfClient, WithHTTPClient(log), WithMetrics(metrics))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		log.setSTH(&command.GetSTHResponse{TreeSize: 1, SHA256RootHash: leaf2})
+
+		// The audit failure doesn't affect the inclusion check.
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+		checkQueue(t, db, 0)
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentSplitView, incidents[0].Type)
+		require.Equal(t, "https://vct.com", incidents[0].Domain)
+		require.Contains(t, incidents[0].Details, "different root hashes for tree size 1")
+		require.Equal(t, 1, metrics.count(string(IncidentSplitView)))
+
+		// The same split view is only recorded once.
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		incidents, err = client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, 1, metrics.count(string(IncidentSplitView)))
+	})
+
+	t.Run("Split view -> tree size decreased", func(t *testing.T) {
+		log := newMockLog(t, sth2, validProof)
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, WithHTTPClient(log))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		log.setSTH(sth1)
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentSplitView, incidents[0].Type)
+		require.Contains(t, incidents[0].Details, "tree size decreased from 2 to 1")
+	})
+
+	t.Run("Split view -> invalid consistency proof", func(t *testing.T) {
+		log := newMockLog(t, sth1, &command.GetSTHConsistencyResponse{Consistency: [][]byte{leaf1}})
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, WithHTTPClient(log))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		log.setSTH(sth2)
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentSplitView, incidents[0].Type)
+		require.Contains(t, incidents[0].Details, "invalid consistency proof between tree sizes 1 and 2")
+		require.Equal(t, 1, log.consistencyRequests())
+
+		// A known split view isn't audited again.
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+		require.Equal(t, 1, log.consistencyRequests())
+
+		incidents, err = client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+	})
+
+	t.Run("Invalid STH signature", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, WithHTTPClient(log))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		for _, sig := range [][]byte{[]byte(`{}`), []byte("invalid"), log.signature(sth1)} {
+			log.setSTH(&command.GetSTHResponse{
+				TreeSize:          sth2.TreeSize,
+				SHA256RootHash:    sth2.SHA256RootHash,
+				TreeHeadSignature: sig,
+			})
+
+			require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+		}
+
+		// The STHs with invalid signatures are ignored.
+		sths, err := client.SignedTreeHeads()
+		require.NoError(t, err)
+		require.Len(t, sths, 1)
+		require.Equal(t, uint64(1), sths[0].TreeSize)
+		require.Equal(t, 0, log.consistencyRequests())
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+	})
+
+	t.Run("Periodic audit", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient,
+			WithHTTPClient(log), WithAuditInterval(50*time.Millisecond))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, watch(t, client, time.Now().Add(time.Minute)))
+
+		log.setSTH(&command.GetSTHResponse{TreeSize: 1, SHA256RootHash: leaf2})
+
+		require.Eventually(t, func() bool {
+			incidents, e := client.Incidents()
+			require.NoError(t, e)
+
+			return len(incidents) == 1
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Missing inclusion", func(t *testing.T) {
+		metrics := &mockMetrics{}
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, WithMetrics(metrics))
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.EqualError(t, watch(t, client, time.Now().Add(-time.Minute)), "expired")
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentMissingInclusion, incidents[0].Type)
+		require.Equal(t, 1, metrics.count(string(IncidentMissingInclusion)))
+	})
+
+	t.Run("Query incidents error", func(t *testing.T) {
+		db := newDBMock(t)
+		db.mockStore.errQuery = func() error { return errors.New("query error") }
+
+		client, err := New(db, testutil.GetLoader(t), wfClient)
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		incidents, err := client.Incidents()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+		require.Nil(t, incidents)
+	})
+}
+
+func TestClient_CheckSignedTreeHead(t *testing.T) {
+	const (
+		logDomain = "https://vct.com"
+		peer      = "https://orb.domain2.com/services/orb"
+	)
+
+	wfClient := wfclient.New(wfclient.WithHTTPClient(httpMock(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       ioutil.NopCloser(bytes.NewBufferString(webfingerPayload)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})))
+
+	h := hasher.DefaultHasher
+
+	leaf1 := h.HashLeaf([]byte("leaf1"))
+	leaf2 := h.HashLeaf([]byte("leaf2"))
+
+	sth1 := &command.GetSTHResponse{TreeSize: 1, SHA256RootHash: leaf1}
+	validProof := &command.GetSTHConsistencyResponse{Consistency: [][]byte{leaf2}}
+
+	peerSTH1 := &SignedTreeHead{Domain: logDomain, TreeSize: 1, RootHash: leaf1}
+	peerSTH2 := &SignedTreeHead{Domain: logDomain, TreeSize: 2, RootHash: h.HashChildren(leaf1, leaf2)}
+
+	// newClient returns a client that has observed the STH of size 1.
+	newClient := func(t *testing.T, log *mockLog, opts ...Opt) *Client {
+		t.Helper()
+
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient, append(opts, WithHTTPClient(log))...)
+		require.NoError(t, err)
+
+		ID := "https://orb.domain.com/" + uuid.New().String()
+
+		require.NoError(t, client.Watch(&verifiable.Credential{
+			ID:      ID,
+			Context: []string{"https://www.w3.org/2018/credentials/v1"},
+			Subject: ID,
+			Issuer:  verifiable.Issuer{ID: ID},
+			Issued:  &util.TimeWithTrailingZeroMsec{},
+			Types:   []string{"VerifiableCredential"},
+		}, time.Now().Add(time.Minute), logDomain, time.Now()))
+
+		sths, err := client.SignedTreeHeads()
+		require.NoError(t, err)
+		require.Len(t, sths, 1)
+		require.Equal(t, logDomain, sths[0].Domain)
+		require.Equal(t, uint64(1), sths[0].TreeSize)
+
+		return client
+	}
+
+	t.Run("Consistent", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		require.NoError(t, client.CheckSignedTreeHead(peer, log.sign(peerSTH1)))
+		require.Equal(t, 0, log.consistencyRequests())
+
+		require.NoError(t, client.CheckSignedTreeHead(peer, log.sign(peerSTH2)))
+		require.Equal(t, 1, log.consistencyRequests())
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+	})
+
+	t.Run("Peer STH is older than local STH", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		// Advance the local view to tree size 2.
+		log.setSTH(&command.GetSTHResponse{TreeSize: 2, SHA256RootHash: peerSTH2.RootHash})
+
+		require.NoError(t, client.CheckSignedTreeHead(peer, log.sign(peerSTH2)))
+
+		require.NoError(t, client.CheckSignedTreeHead(peer, log.sign(peerSTH1)))
+	})
+
+	t.Run("Conflict -> different root hash", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+		metrics := &mockMetrics{}
+
+		client := newClient(t, log, WithMetrics(metrics))
+		defer client.Close()
+
+		err := client.CheckSignedTreeHead(peer,
+			log.sign(&SignedTreeHead{Domain: logDomain, TreeSize: 1, RootHash: leaf2}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "different root hashes for tree size 1")
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentGossipConflict, incidents[0].Type)
+		require.Equal(t, logDomain, incidents[0].Domain)
+		require.Contains(t, incidents[0].Details, peer)
+		require.Equal(t, 1, metrics.count(string(IncidentGossipConflict)))
+	})
+
+	t.Run("Conflict -> invalid consistency proof", func(t *testing.T) {
+		log := newMockLog(t, sth1, &command.GetSTHConsistencyResponse{Consistency: [][]byte{leaf1}})
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		err := client.CheckSignedTreeHead(peer, log.sign(peerSTH2))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid consistency proof between tree sizes 1 and 2")
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Len(t, incidents, 1)
+		require.Equal(t, IncidentGossipConflict, incidents[0].Type)
+	})
+
+	t.Run("Unsigned STH", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		err := client.CheckSignedTreeHead(peer, &SignedTreeHead{Domain: logDomain, TreeSize: 1, RootHash: leaf2})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "missing signature")
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+	})
+
+	t.Run("STH not signed by the log", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+		otherLog := newMockLog(t, sth1, validProof)
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		err := client.CheckSignedTreeHead(peer, otherLog.sign(peerSTH2))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid signed tree head signature")
+		require.Equal(t, 0, log.consistencyRequests())
+
+		incidents, err := client.Incidents()
+		require.NoError(t, err)
+		require.Empty(t, incidents)
+	})
+
+	t.Run("Log not monitored", func(t *testing.T) {
+		log := newMockLog(t, sth1, validProof)
+
+		client := newClient(t, log)
+		defer client.Close()
+
+		require.NoError(t, client.CheckSignedTreeHead(peer,
+			&SignedTreeHead{Domain: "https://other.vct.com", TreeSize: 5, RootHash: leaf2}),
+		)
+		require.Equal(t, 0, log.consistencyRequests())
+	})
+
+	t.Run("Empty tree", func(t *testing.T) {
+		client, err := New(mem.NewProvider(), testutil.GetLoader(t), wfClient)
+		require.NoError(t, err)
+
+		defer client.Close()
+
+		require.NoError(t, client.CheckSignedTreeHead(peer, &SignedTreeHead{Domain: logDomain}))
+	})
+}
+
+// mockLog is a VCT log that signs the STHs that it returns (unless the STH already has a signature).
+type mockLog struct {
+	t                   *testing.T
+	mutex               sync.Mutex
+	sth                 *command.GetSTHResponse
+	consistency         *command.GetSTHConsistencyResponse
+	numConsistencyCalls int
+	privateKey          *ecdsa.PrivateKey
+	publicKey           []byte
+}
+
+func newMockLog(t *testing.T, sth *command.GetSTHResponse,
+	consistency *command.GetSTHConsistencyResponse) *mockLog {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	publicKey, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	return &mockLog{t: t, sth: sth, consistency: consistency, privateKey: privateKey, publicKey: publicKey}
+}
+
+func (m *mockLog) signature(sth *command.GetSTHResponse) []byte {
+	data, err := json.Marshal(command.TreeHeadSignature{
+		Version:        command.V1,
+		SignatureType:  command.TreeHeadSignatureType,
+		Timestamp:      sth.Timestamp,
+		TreeSize:       sth.TreeSize,
+		SHA256RootHash: sth.SHA256RootHash,
+	})
+	require.NoError(m.t, err)
+
+	hash := sha256.Sum256(data)
+
+	sig, err := ecdsa.SignASN1(rand.Reader, m.privateKey, hash[:])
+	require.NoError(m.t, err)
+
+	sigBytes, err := json.Marshal(command.DigitallySigned{
+		Algorithm: command.SignatureAndHashAlgorithm{Type: kms.ECDSAP256TypeDER},
+		Signature: sig,
+	})
+	require.NoError(m.t, err)
+
+	return sigBytes
+}
+
+// sign returns a copy of the given STH that is signed by the log.
+func (m *mockLog) sign(sth *SignedTreeHead) *SignedTreeHead {
+	signed := *sth
+	signed.Signature = m.signature(&command.GetSTHResponse{
+		TreeSize:       sth.TreeSize,
+		Timestamp:      sth.Timestamp,
+		SHA256RootHash: sth.RootHash,
+	})
+
+	return &signed
+}
+
+func (m *mockLog) signedSTH() *command.GetSTHResponse {
+	if len(m.sth.TreeHeadSignature) > 0 {
+		return m.sth
+	}
+
+	sth := *m.sth
+	sth.TreeHeadSignature = m.signature(m.sth)
+
+	return &sth
+}
+
+func (m *mockLog) setSTH(sth *command.GetSTHResponse) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sth = sth
+}
+
+func (m *mockLog) consistencyRequests() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.numConsistencyCalls
+}
+
+func (m *mockLog) Do(req *http.Request) (*http.Response, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var resp interface{}
+
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/get-sth"):
+		resp = m.signedSTH()
+	case strings.HasSuffix(req.URL.Path, "/webfinger"):
+		resp = &command.WebFingerResponse{Properties: map[string]interface{}{command.PublicKeyType: m.publicKey}}
+	case strings.HasSuffix(req.URL.Path, "/get-sth-consistency"):
+		m.numConsistencyCalls++
+
+		resp = m.consistency
+	default:
+		resp = &command.GetProofByHashResponse{AuditPath: [][]byte{{}}}
+	}
+
+	respBytes, err := json.Marshal(resp)
+	require.NoError(m.t, err)
+
+	return &http.Response{
+		Body:       ioutil.NopCloser(bytes.NewBuffer(respBytes)),
+		StatusCode: http.StatusOK,
+	}, nil
+}
+
+type mockMetrics struct {
+	mutex     sync.Mutex
+	incidents map[string]int
+}
+
+func (m *mockMetrics) VCTLogIncident(incidentType string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.incidents == nil {
+		m.incidents = make(map[string]int)
+	}
+
+	m.incidents[incidentType]++
+}
+
+func (m *mockMetrics) count(incidentType string) int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.incidents[incidentType]
+}
+
 func checkQueue(t *testing.T, db storage.Provider, expected int) {
 	t.Helper()
 
@@ -23,8 +23,12 @@ import (
 
 var logger = log.New("activitypub_service")
 
-// MetadataSendTo is the metadata key for the destination URL.
-const MetadataSendTo = "send_to"
+const (
+	// MetadataSendTo is the metadata key for the destination URL.
+	MetadataSendTo = "send_to"
+	// MetadataLastError is the metadata key for the error of the last failed delivery attempt.
+	MetadataLastError = "last_error"
+)
 
 type httpTransport interface {
 	Post(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error)
@@ -62,6 +66,9 @@ func New(serviceName string, t httpTransport) *Publisher {
 func (p *Publisher) Publish(topic string, messages ...*message.Message) error {
 	for _, msg := range messages {
 		if err := p.publish(topic, msg); err != nil {
+			// Save the error in the message so that it's available if the message ends up being undeliverable.
+			msg.Metadata.Set(MetadataLastError, err.Error())
+
 			return err
 		}
 	}
@@ -116,9 +116,12 @@ func TestPublisher_Publish(t *testing.T) {
 	})
 
 	t.Run("NewRequest error", func(t *testing.T) {
-		err := p.Publish("topic", message.NewMessage(watermill.NewUUID(), []byte("payload")))
+		msg := message.NewMessage(watermill.NewUUID(), []byte("payload"))
+
+		err := p.Publish("topic", msg)
 		require.Error(t, err)
 		require.Contains(t, err.Error(), "metadata [send_to] not found in message")
+		require.Equal(t, err.Error(), msg.Metadata[MetadataLastError])
 	})
 
 	t.Run("BadRequest error", func(t *testing.T) {
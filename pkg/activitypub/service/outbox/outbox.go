@@ -9,6 +9,7 @@ package outbox
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -261,6 +262,32 @@ func (h *Outbox) Post(activity *vocab.ActivityType) (*url.URL, error) {
 	return activity.ID().URL(), nil
 }
 
+// Redeliver delivers an activity that was previously posted to the outbox to the given inbox. The activity
+// is not stored or handled again. If the delivery fails then the activity will go through the usual
+// redelivery attempts before being handed to the undeliverable activity handler.
+func (h *Outbox) Redeliver(activity *vocab.ActivityType, inboxURL *url.URL) error {
+	if h.State() != lifecycle.StateStarted {
+		return lifecycle.ErrNotStarted
+	}
+
+	if activity.ID() == nil {
+		return orberrors.NewBadRequest(fmt.Errorf("no ID specified in activity"))
+	}
+
+	activityBytes, err := h.jsonMarshal(activity)
+	if err != nil {
+		return orberrors.NewBadRequest(fmt.Errorf("marshal: %w", err))
+	}
+
+	logger.Debugf("[%s] Redelivering activity [%s] to [%s]", h.ServiceName, activity.ID(), inboxURL)
+
+	if err := h.publish(activity.ID().String(), activityBytes, inboxURL); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("unable to publish activity to inbox %s: %w", inboxURL, err))
+	}
+
+	return nil
+}
+
 func (h *Outbox) storeActivity(activity *vocab.ActivityType) error {
 	if err := h.activityStore.AddActivity(activity); err != nil {
 		return fmt.Errorf("store activity: %w", err)
@@ -327,7 +354,13 @@ func (h *Outbox) handleUndeliverableActivity(msg *message.Message) {
 		logger.Warnf("[%s] Will not attempt redelivery for message. Activity ID [%s], To: [%s]. Reason: %s",
 			h.ServiceName, activity.ID(), toURL, err)
 
-		h.undeliverableHandler.HandleUndeliverableActivity(activity, toURL)
+		reason := err
+
+		if lastErr, ok := msg.Metadata[httppublisher.MetadataLastError]; ok && lastErr != "" {
+			reason = errors.New(lastErr)
+		}
+
+		h.undeliverableHandler.HandleUndeliverableActivity(activity, toURL, reason)
 	} else {
 		activityID := msg.Metadata[middleware.CorrelationIDMetadataKey]
 
@@ -592,7 +625,7 @@ func deduplicate(toIRIs []*url.URL) []*url.URL {
 
 type noOpUndeliverableHandler struct{}
 
-func (h *noOpUndeliverableHandler) HandleUndeliverableActivity(*vocab.ActivityType, string) {
+func (h *noOpUndeliverableHandler) HandleUndeliverableActivity(*vocab.ActivityType, string, error) {
 }
 
 func newHandlerOptions(opts []service.HandlerOpt) *service.Handlers {
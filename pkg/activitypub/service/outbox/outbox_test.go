@@ -19,6 +19,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
@@ -440,6 +441,7 @@ func TestOutbox_PostError(t *testing.T) {
 		undeliverableActivities := undeliverableHandler.Activities()
 		require.Len(t, undeliverableActivities, 1)
 		require.Equal(t, activity.ID(), undeliverableActivities[0].Activity.ID())
+		require.Error(t, undeliverableActivities[0].Reason)
 
 		time.Sleep(100 * time.Millisecond)
 
@@ -547,6 +549,73 @@ func TestOutbox_PostError(t *testing.T) {
 	})
 }
 
+func TestOutbox_Redeliver(t *testing.T) {
+	service1URL := testutil.MustParseURL("http://localhost:8002/services/service1")
+	inbox2URL := testutil.MustParseURL("http://localhost:8002/services/service2/inbox")
+
+	cfg := &Config{
+		ServiceName: "service1",
+		ServiceIRI:  service1URL,
+		Topic:       "activities",
+		RedeliveryConfig: &redelivery.Config{
+			MaxRetries:     1,
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     time.Second,
+			BackoffFactor:  1.5,
+			MaxMessages:    20,
+		},
+	}
+
+	activity := vocab.NewCreateActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(testutil.MustParseURL("http://example.com/transactions/txn1"))),
+		vocab.WithID(testutil.NewMockID(service1URL, "/activities/"+uuid.New().String())),
+		vocab.WithActor(service1URL),
+	)
+
+	t.Run("Undeliverable", func(t *testing.T) {
+		undeliverableHandler := mocks.NewUndeliverableHandler()
+
+		ob, err := New(cfg, memstore.New("service1"), mocks.NewPubSub(), transport.Default(),
+			&mocks.ActivityHandler{}, mocks.NewActorRetriever(), &mocks.WebFingerResolver{}, &orbmocks.MetricsProvider{},
+			spi.WithUndeliverableHandler(undeliverableHandler))
+		require.NoError(t, err)
+
+		ob.Start()
+		defer ob.Stop()
+
+		require.NoError(t, ob.Redeliver(activity, inbox2URL))
+
+		time.Sleep(500 * time.Millisecond)
+
+		undeliverableActivities := undeliverableHandler.Activities()
+		require.Len(t, undeliverableActivities, 1)
+		require.Equal(t, activity.ID(), undeliverableActivities[0].Activity.ID())
+		require.Equal(t, inbox2URL.String(), undeliverableActivities[0].ToURL)
+		require.Error(t, undeliverableActivities[0].Reason)
+	})
+
+	t.Run("Not started", func(t *testing.T) {
+		ob, err := New(cfg, memstore.New("service1"), mocks.NewPubSub(), transport.Default(),
+			&mocks.ActivityHandler{}, mocks.NewActorRetriever(), &mocks.WebFingerResolver{}, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		require.True(t, errors.Is(ob.Redeliver(activity, inbox2URL), lifecycle.ErrNotStarted))
+	})
+
+	t.Run("No activity ID", func(t *testing.T) {
+		ob, err := New(cfg, memstore.New("service1"), mocks.NewPubSub(), transport.Default(),
+			&mocks.ActivityHandler{}, mocks.NewActorRetriever(), &mocks.WebFingerResolver{}, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		ob.Start()
+		defer ob.Stop()
+
+		err = ob.Redeliver(vocab.NewCreateActivity(nil), inbox2URL)
+		require.Error(t, err)
+		require.True(t, orberrors.IsBadRequest(err))
+	})
+}
+
 func TestDeduplicate(t *testing.T) {
 	service1URL := testutil.MustParseURL("http://localhost:8002/services/service1")
 	service2URL := testutil.MustParseURL("http://localhost:8002/services/service2")
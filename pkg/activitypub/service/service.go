@@ -74,6 +74,7 @@ type signatureVerifier interface {
 
 type activityPubClient interface {
 	GetActor(iri *url.URL) (*vocab.ActorType, error)
+	RefreshActor(iri *url.URL) (*vocab.ActorType, error)
 	GetReferences(iri *url.URL) (client.ReferenceIterator, error)
 }
 
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package spi
 
 import (
+	"errors"
 	"net/url"
 	"time"
 
@@ -14,6 +15,9 @@ import (
 	"github.com/trustbloc/orb/pkg/lifecycle"
 )
 
+// ErrActorBlocked is returned by the inbox activity handler if the actor of the activity has been blocked.
+var ErrActorBlocked = errors.New("actor is blocked")
+
 // ServiceLifecycle defines the functions of a service lifecycle.
 type ServiceLifecycle interface {
 	// Start starts the service.
@@ -30,6 +34,9 @@ type Outbox interface {
 
 	// Post posts an activity to the outbox and returns the ID of the activity.
 	Post(activity *vocab.ActivityType) (*url.URL, error)
+
+	// Redeliver delivers a previously posted activity to the given inbox.
+	Redeliver(activity *vocab.ActivityType, inboxURL *url.URL) error
 }
 
 // Inbox defines the functions for an ActivityPub inbox.
@@ -47,6 +54,11 @@ type AnchorEventAcknowledgementHandler interface {
 	AnchorEventAcknowledged(actor, anchorRef *url.URL, additionalAnchorRefs []*url.URL) error
 }
 
+// SignedTreeHeadHandler handles the signed tree head of a VCT log that was announced by another Orb server.
+type SignedTreeHeadHandler interface {
+	HandleSignedTreeHead(actor *url.URL, sth *vocab.SignedTreeHeadType) error
+}
+
 // ActorAuth makes the decision of whether or not a request by the given
 // actor should be accepted.
 type ActorAuth interface {
@@ -74,9 +86,10 @@ type ActivityHandler interface {
 	Subscribe() <-chan *vocab.ActivityType
 }
 
-// UndeliverableActivityHandler handles undeliverable activities.
+// UndeliverableActivityHandler handles undeliverable activities. The reason contains the error
+// from the last failed delivery attempt.
 type UndeliverableActivityHandler interface {
-	HandleUndeliverableActivity(activity *vocab.ActivityType, toURL string)
+	HandleUndeliverableActivity(activity *vocab.ActivityType, toURL string, reason error)
 }
 
 // Handlers contains handlers for various activity events, including undeliverable activities.
@@ -88,6 +101,7 @@ type Handlers struct {
 	Witness                 WitnessHandler
 	ProofHandler            ProofHandler
 	AnchorEventAckHandler   AnchorEventAcknowledgementHandler
+	SignedTreeHeadHandler   SignedTreeHeadHandler
 }
 
 // HandlerOpt sets a specific handler.
@@ -142,3 +156,10 @@ func WithAnchorEventAcknowledgementHandler(handler AnchorEventAcknowledgementHan
 		options.AnchorEventAckHandler = handler
 	}
 }
+
+// WithSignedTreeHeadHandler sets the handler for signed tree heads that are announced by other Orb servers.
+func WithSignedTreeHeadHandler(handler SignedTreeHeadHandler) HandlerOpt {
+	return func(options *Handlers) {
+		options.SignedTreeHeadHandler = handler
+	}
+}
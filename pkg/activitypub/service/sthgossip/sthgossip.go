@@ -0,0 +1,196 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sthgossip
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/monitoring"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("sth-gossip")
+
+const defaultInterval = 5 * time.Minute
+
+// Config contains the configuration for the signed tree head gossip service.
+type Config struct {
+	// ServiceIRI is the IRI of the local service.
+	ServiceIRI *url.URL
+
+	// Interval is the interval at which the latest signed tree heads are announced to followers.
+	Interval time.Duration
+}
+
+// Outbox posts an activity to the outbox.
+type Outbox interface {
+	Post(activity *vocab.ActivityType) (*url.URL, error)
+}
+
+type monitoringSvc interface {
+	SignedTreeHeads() ([]*monitoring.SignedTreeHead, error)
+	CheckSignedTreeHead(source string, sth *monitoring.SignedTreeHead) error
+}
+
+// Service gossips the signed tree heads (STH) of the VCT logs that are monitored by the local service.
+// The latest STH of each log is periodically announced to the followers of the local service (if it has
+// changed since the last announcement), and STHs that are announced by other services are cross-checked
+// against the local view of the log by the monitoring service. Any conflict is recorded by the monitoring
+// service as an incident.
+type Service struct {
+	*lifecycle.Lifecycle
+
+	config       Config
+	monitor      monitoringSvc
+	outbox       func() Outbox
+	followersIRI *url.URL
+	announced    map[string]*monitoring.SignedTreeHead
+	done         chan struct{}
+}
+
+// New returns a new signed tree head gossip service.
+func New(cfg Config, monitor monitoringSvc, outbox func() Outbox) (*Service, error) {
+	followersIRI, err := url.Parse(cfg.ServiceIRI.String() + resthandler.FollowersPath)
+	if err != nil {
+		return nil, fmt.Errorf("followers IRI: %w", err)
+	}
+
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	s := &Service{
+		config:       cfg,
+		monitor:      monitor,
+		outbox:       outbox,
+		followersIRI: followersIRI,
+		announced:    make(map[string]*monitoring.SignedTreeHead),
+		done:         make(chan struct{}),
+	}
+
+	s.Lifecycle = lifecycle.New("sth-gossip",
+		lifecycle.WithStart(s.start),
+		lifecycle.WithStop(s.stop))
+
+	return s, nil
+}
+
+// HandleSignedTreeHead cross-checks the signed tree head that was announced by the given actor
+// against the local view of the log.
+func (s *Service) HandleSignedTreeHead(actor *url.URL, sth *vocab.SignedTreeHeadType) error {
+	logger.Debugf("Checking signed tree head from [%s] for log [%s] - Tree size: %d",
+		actor, sth.Log(), sth.TreeSize())
+
+	err := s.monitor.CheckSignedTreeHead(actor.String(), &monitoring.SignedTreeHead{
+		Domain:    sth.Log().String(),
+		TreeSize:  sth.TreeSize(),
+		Timestamp: sth.Timestamp(),
+		RootHash:  sth.RootHash(),
+		Signature: sth.TreeHeadSignature(),
+	})
+	if err != nil {
+		return fmt.Errorf("check signed tree head from [%s] for log [%s]: %w", actor, sth.Log(), err)
+	}
+
+	return nil
+}
+
+func (s *Service) start() {
+	go s.run()
+
+	logger.Infof("Started signed tree head gossip service - Interval [%s]", s.config.Interval)
+}
+
+func (s *Service) stop() {
+	close(s.done)
+
+	logger.Infof("Stopped signed tree head gossip service")
+}
+
+func (s *Service) run() {
+	for {
+		select {
+		case <-time.After(s.config.Interval):
+			s.announceAll()
+
+		case <-s.done:
+			logger.Debugf("Exiting signed tree head gossip service.")
+
+			return
+		}
+	}
+}
+
+func (s *Service) announceAll() {
+	sths, err := s.monitor.SignedTreeHeads()
+	if err != nil {
+		logger.Errorf("Error retrieving signed tree heads: %s", err)
+
+		return
+	}
+
+	for _, sth := range sths {
+		if !s.changed(sth) {
+			logger.Debugf("Signed tree head for log [%s] has not changed since the last announcement", sth.Domain)
+
+			continue
+		}
+
+		if err := s.announce(sth); err != nil {
+			logger.Warnf("Error announcing signed tree head for log [%s]: %s", sth.Domain, err)
+
+			continue
+		}
+
+		s.announced[sth.Domain] = sth
+	}
+}
+
+func (s *Service) changed(sth *monitoring.SignedTreeHead) bool {
+	prev, ok := s.announced[sth.Domain]
+	if !ok {
+		return true
+	}
+
+	return prev.TreeSize != sth.TreeSize || !bytes.Equal(prev.RootHash, sth.RootHash)
+}
+
+func (s *Service) announce(sth *monitoring.SignedTreeHead) error {
+	logURL, err := url.Parse(sth.Domain)
+	if err != nil {
+		return fmt.Errorf("parse log URL: %w", err)
+	}
+
+	published := time.Now()
+
+	announce := vocab.NewAnnounceActivity(
+		vocab.NewObjectProperty(
+			vocab.WithSignedTreeHead(
+				vocab.NewSignedTreeHead(logURL, sth.TreeSize, sth.Timestamp, sth.RootHash, sth.Signature),
+			),
+		),
+		vocab.WithTo(s.followersIRI, vocab.PublicIRI),
+		vocab.WithPublishedTime(&published),
+	)
+
+	activityID, err := s.outbox().Post(announce)
+	if err != nil {
+		return fmt.Errorf("post 'Announce' activity: %w", err)
+	}
+
+	logger.Debugf("Announced signed tree head for log [%s] in activity [%s] - Tree size: %d",
+		sth.Domain, activityID, sth.TreeSize)
+
+	return nil
+}
@@ -0,0 +1,216 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sthgossip
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/service/monitoring"
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var (
+	service1IRI = testutil.MustParseURL("https://orb.domain1.com/services/orb")
+	service2IRI = testutil.MustParseURL("https://orb.domain2.com/services/orb")
+)
+
+const (
+	log1 = "https://vct1.example.com"
+	log2 = "https://vct2.example.com"
+)
+
+func TestNew(t *testing.T) {
+	s, err := New(Config{ServiceIRI: service1IRI}, newMockMonitor(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, s)
+	require.Equal(t, defaultInterval, s.config.Interval)
+	require.Equal(t, "https://orb.domain1.com/services/orb/followers", s.followersIRI.String())
+}
+
+func TestService_Announce(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		monitor := newMockMonitor().withSTHs(
+			&monitoring.SignedTreeHead{Domain: log1, TreeSize: 5, Timestamp: 1000, RootHash: []byte("root1")},
+			&monitoring.SignedTreeHead{Domain: log2, TreeSize: 7, Timestamp: 2000, RootHash: []byte("root2")},
+		)
+
+		ob := mocks.NewOutbox()
+
+		s, err := New(Config{ServiceIRI: service1IRI, Interval: 10 * time.Millisecond},
+			monitor, func() Outbox { return ob })
+		require.NoError(t, err)
+
+		s.Start()
+		defer s.Stop()
+
+		require.Equal(t, lifecycle.StateStarted, s.State())
+
+		require.Eventually(t, func() bool { return len(ob.Activities()) == 2 }, time.Second, 10*time.Millisecond)
+
+		// Unchanged STHs are not announced again.
+		time.Sleep(50 * time.Millisecond)
+		require.Len(t, ob.Activities(), 2)
+
+		for _, a := range ob.Activities() {
+			require.True(t, a.Type().Is(vocab.TypeAnnounce))
+			require.True(t, a.To().Contains(s.followersIRI))
+			require.True(t, a.To().Contains(vocab.PublicIRI))
+
+			sth := a.Object().SignedTreeHead()
+			require.NotNil(t, sth)
+			require.Contains(t, []string{log1, log2}, sth.Log().String())
+		}
+
+		// A new STH for one of the logs is announced.
+		monitor.withSTHs(
+			&monitoring.SignedTreeHead{Domain: log1, TreeSize: 6, Timestamp: 3000, RootHash: []byte("root3")},
+			&monitoring.SignedTreeHead{Domain: log2, TreeSize: 7, Timestamp: 2000, RootHash: []byte("root2")},
+		)
+
+		require.Eventually(t, func() bool { return len(ob.Activities()) == 3 }, time.Second, 10*time.Millisecond)
+
+		sth := ob.Activities()[2].Object().SignedTreeHead()
+		require.Equal(t, log1, sth.Log().String())
+		require.Equal(t, uint64(6), sth.TreeSize())
+		require.Equal(t, []byte("root3"), sth.RootHash())
+	})
+
+	t.Run("Outbox error", func(t *testing.T) {
+		monitor := newMockMonitor().withSTHs(
+			&monitoring.SignedTreeHead{Domain: log1, TreeSize: 5, Timestamp: 1000, RootHash: []byte("root1")},
+		)
+
+		ob := mocks.NewOutbox().WithError(errors.New("injected outbox error"))
+
+		s, err := New(Config{ServiceIRI: service1IRI}, monitor, func() Outbox { return ob })
+		require.NoError(t, err)
+
+		s.announceAll()
+		require.Empty(t, s.announced)
+	})
+
+	t.Run("Invalid log URL", func(t *testing.T) {
+		monitor := newMockMonitor().withSTHs(
+			&monitoring.SignedTreeHead{Domain: ":invalid", TreeSize: 5, RootHash: []byte("root1")},
+		)
+
+		ob := mocks.NewOutbox()
+
+		s, err := New(Config{ServiceIRI: service1IRI}, monitor, func() Outbox { return ob })
+		require.NoError(t, err)
+
+		s.announceAll()
+		require.Empty(t, s.announced)
+		require.Empty(t, ob.Activities())
+	})
+
+	t.Run("Monitoring error", func(t *testing.T) {
+		monitor := newMockMonitor().withQueryError(errors.New("injected query error"))
+
+		ob := mocks.NewOutbox()
+
+		s, err := New(Config{ServiceIRI: service1IRI}, monitor, func() Outbox { return ob })
+		require.NoError(t, err)
+
+		s.announceAll()
+		require.Empty(t, ob.Activities())
+	})
+}
+
+func TestService_HandleSignedTreeHead(t *testing.T) {
+	sth := vocab.NewSignedTreeHead(testutil.MustParseURL(log1), 5, 1000, []byte("root1"), []byte("sig"))
+
+	t.Run("Success", func(t *testing.T) {
+		monitor := newMockMonitor()
+
+		s, err := New(Config{ServiceIRI: service1IRI}, monitor, nil)
+		require.NoError(t, err)
+
+		require.NoError(t, s.HandleSignedTreeHead(service2IRI, sth))
+
+		require.Len(t, monitor.checked, 1)
+		require.Equal(t, service2IRI.String(), monitor.checked[0].source)
+		require.Equal(t, log1, monitor.checked[0].sth.Domain)
+		require.Equal(t, uint64(5), monitor.checked[0].sth.TreeSize)
+		require.Equal(t, uint64(1000), monitor.checked[0].sth.Timestamp)
+		require.Equal(t, []byte("root1"), monitor.checked[0].sth.RootHash)
+		require.Equal(t, []byte("sig"), monitor.checked[0].sth.Signature)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		errExpected := errors.New("split view")
+
+		s, err := New(Config{ServiceIRI: service1IRI}, newMockMonitor().withCheckError(errExpected), nil)
+		require.NoError(t, err)
+
+		err = s.HandleSignedTreeHead(service2IRI, sth)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
+
+type checkedSTH struct {
+	source string
+	sth    *monitoring.SignedTreeHead
+}
+
+type mockMonitor struct {
+	mutex    sync.Mutex
+	sths     []*monitoring.SignedTreeHead
+	checked  []*checkedSTH
+	queryErr error
+	checkErr error
+}
+
+func newMockMonitor() *mockMonitor {
+	return &mockMonitor{}
+}
+
+func (m *mockMonitor) withSTHs(sths ...*monitoring.SignedTreeHead) *mockMonitor {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.sths = sths
+
+	return m
+}
+
+func (m *mockMonitor) withQueryError(err error) *mockMonitor {
+	m.queryErr = err
+
+	return m
+}
+
+func (m *mockMonitor) withCheckError(err error) *mockMonitor {
+	m.checkErr = err
+
+	return m
+}
+
+func (m *mockMonitor) SignedTreeHeads() ([]*monitoring.SignedTreeHead, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.sths, m.queryErr
+}
+
+func (m *mockMonitor) CheckSignedTreeHead(source string, sth *monitoring.SignedTreeHead) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.checked = append(m.checked, &checkedSTH{source: source, sth: sth})
+
+	return m.checkErr
+}
@@ -0,0 +1,206 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/undeliverable"
+)
+
+const (
+	// ListPath is the endpoint that lists the undeliverable activities.
+	ListPath = "/undeliverable"
+	// ReplayPath is the endpoint that replays an undeliverable activity.
+	ReplayPath = "/undeliverable/{id}/replay"
+	// DiscardPath is the endpoint that discards an undeliverable activity.
+	DiscardPath = "/undeliverable/{id}"
+
+	idPathVariable = "id"
+)
+
+const (
+	notFoundResponse            = "Not Found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("undeliverable-rest-handler")
+
+type undeliverableService interface {
+	List() ([]*undeliverable.Entry, error)
+	Replay(id string) error
+	Discard(id string) error
+}
+
+// List lists the activities that could not be delivered.
+type List struct {
+	service undeliverableService
+	marshal func(interface{}) ([]byte, error)
+}
+
+// NewList returns a new handler that lists undeliverable activities.
+func NewList(s undeliverableService) *List {
+	return &List{
+		service: s,
+		marshal: json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the List service.
+func (h *List) Path() string {
+	return ListPath
+}
+
+// Method returns the HTTP REST method for the List service.
+func (h *List) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the List service.
+func (h *List) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *List) handle(w http.ResponseWriter, _ *http.Request) {
+	entries, err := h.service.List()
+	if err != nil {
+		logger.Errorf("[%s] Error listing undeliverable activities: %s", ListPath, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if entries == nil {
+		entries = []*undeliverable.Entry{}
+	}
+
+	entriesBytes, err := h.marshal(entries)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling undeliverable activities: %s", ListPath, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, entriesBytes)
+}
+
+// Replay redelivers an undeliverable activity to its inbox.
+type Replay struct {
+	service undeliverableService
+}
+
+// NewReplay returns a new handler that replays an undeliverable activity.
+func NewReplay(s undeliverableService) *Replay {
+	return &Replay{service: s}
+}
+
+// Path returns the HTTP REST endpoint for the Replay service.
+func (h *Replay) Path() string {
+	return ReplayPath
+}
+
+// Method returns the HTTP REST method for the Replay service.
+func (h *Replay) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the Replay service.
+func (h *Replay) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Replay) handle(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[idPathVariable]
+
+	if err := h.service.Replay(id); err != nil {
+		handleError(w, ReplayPath, id, err)
+
+		return
+	}
+
+	logger.Debugf("[%s] Replayed undeliverable activity [%s]", ReplayPath, id)
+
+	writeResponse(w, http.StatusOK, nil)
+}
+
+// Discard removes an undeliverable activity.
+type Discard struct {
+	service undeliverableService
+}
+
+// NewDiscard returns a new handler that discards an undeliverable activity.
+func NewDiscard(s undeliverableService) *Discard {
+	return &Discard{service: s}
+}
+
+// Path returns the HTTP REST endpoint for the Discard service.
+func (h *Discard) Path() string {
+	return DiscardPath
+}
+
+// Method returns the HTTP REST method for the Discard service.
+func (h *Discard) Method() string {
+	return http.MethodDelete
+}
+
+// Handler returns the HTTP REST handle for the Discard service.
+func (h *Discard) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Discard) handle(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[idPathVariable]
+
+	if err := h.service.Discard(id); err != nil {
+		handleError(w, DiscardPath, id, err)
+
+		return
+	}
+
+	logger.Debugf("[%s] Discarded undeliverable activity [%s]", DiscardPath, id)
+
+	writeResponse(w, http.StatusOK, nil)
+}
+
+func handleError(w http.ResponseWriter, endpoint, id string, err error) {
+	if errors.Is(err, undeliverable.ErrNotFound) {
+		logger.Debugf("[%s] Undeliverable activity not found [%s]", endpoint, id)
+
+		writeResponse(w, http.StatusNotFound, []byte(notFoundResponse))
+
+		return
+	}
+
+	logger.Errorf("[%s] Error handling undeliverable activity [%s]: %s", endpoint, id, err)
+
+	writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("Unable to write response: %s", err)
+
+			return
+		}
+
+		logger.Debugf("Wrote response: %s", body)
+	}
+}
@@ -0,0 +1,254 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/undeliverable"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const entryID = "entry1"
+
+func TestNew(t *testing.T) {
+	s := &mockService{}
+
+	list := NewList(s)
+	require.Equal(t, ListPath, list.Path())
+	require.Equal(t, http.MethodGet, list.Method())
+	require.NotNil(t, list.Handler())
+
+	replay := NewReplay(s)
+	require.Equal(t, ReplayPath, replay.Path())
+	require.Equal(t, http.MethodPost, replay.Method())
+	require.NotNil(t, replay.Handler())
+
+	discard := NewDiscard(s)
+	require.Equal(t, DiscardPath, discard.Path())
+	require.Equal(t, http.MethodDelete, discard.Method())
+	require.NotNil(t, discard.Handler())
+}
+
+func TestList(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := &mockService{
+			entries: []*undeliverable.Entry{
+				{ID: entryID, InboxURL: "https://orb.domain2.com/services/orb/inbox", Attempts: 2},
+			},
+		}
+
+		rw := httptest.NewRecorder()
+
+		NewList(s).handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var entries []*undeliverable.Entry
+		require.NoError(t, json.Unmarshal(respBytes, &entries))
+		require.Len(t, entries, 1)
+		require.Equal(t, entryID, entries[0].ID)
+		require.Equal(t, 2, entries[0].Attempts)
+	})
+
+	t.Run("No entries", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewList(&mockService{}).handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, "[]", string(respBytes))
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewList(&mockService{err: errors.New("injected error")}).handle(rw,
+			httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewList(&mockService{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, ListPath, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestReplay(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := &mockService{}
+
+		rw := httptest.NewRecorder()
+
+		NewReplay(s).handle(rw, newRequest(http.MethodPost, "/undeliverable/entry1/replay"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, entryID, s.replayed)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewReplay(&mockService{err: undeliverable.ErrNotFound}).handle(rw,
+			newRequest(http.MethodPost, "/undeliverable/entry1/replay"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Service error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewReplay(&mockService{err: errors.New("injected error")}).handle(rw,
+			newRequest(http.MethodPost, "/undeliverable/entry1/replay"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestDiscard(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s := &mockService{}
+
+		rw := httptest.NewRecorder()
+
+		NewDiscard(s).handle(rw, newRequest(http.MethodDelete, "/undeliverable/entry1"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, entryID, s.discarded)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		NewDiscard(&mockService{err: undeliverable.ErrNotFound}).handle(rw,
+			newRequest(http.MethodDelete, "/undeliverable/entry1"))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func TestDiscard_Authorization(t *testing.T) {
+	cfg := auth.Config{
+		AuthTokensDef: []*auth.TokenDef{
+			{
+				EndpointExpression: "/undeliverable",
+				ReadTokens:         []string{"admin", "read"},
+				WriteTokens:        []string{"admin"},
+			},
+		},
+		AuthTokens: map[string]string{
+			"read":  "READ_TOKEN",
+			"admin": "ADMIN_TOKEN",
+		},
+	}
+
+	t.Run("Read token -> unauthorized", func(t *testing.T) {
+		s := &mockService{}
+
+		req := newRequest(http.MethodDelete, "/undeliverable/entry1")
+		req.Header.Set("Authorization", "Bearer READ_TOKEN")
+
+		rw := httptest.NewRecorder()
+
+		auth.NewHandlerWrapper(cfg, NewDiscard(s)).Handler()(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusUnauthorized, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Empty(t, s.discarded)
+	})
+
+	t.Run("Write token -> success", func(t *testing.T) {
+		s := &mockService{}
+
+		req := newRequest(http.MethodDelete, "/undeliverable/entry1")
+		req.Header.Set("Authorization", "Bearer ADMIN_TOKEN")
+
+		rw := httptest.NewRecorder()
+
+		auth.NewHandlerWrapper(cfg, NewDiscard(s)).Handler()(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, entryID, s.discarded)
+	})
+}
+
+func newRequest(method, target string) *http.Request {
+	return mux.SetURLVars(httptest.NewRequest(method, target, nil), map[string]string{idPathVariable: entryID})
+}
+
+type mockService struct {
+	entries   []*undeliverable.Entry
+	err       error
+	replayed  string
+	discarded string
+}
+
+func (m *mockService) List() ([]*undeliverable.Entry, error) {
+	return m.entries, m.err
+}
+
+func (m *mockService) Replay(id string) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.replayed = id
+
+	return nil
+}
+
+func (m *mockService) Discard(id string) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.discarded = id
+
+	return nil
+}
@@ -0,0 +1,451 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package undeliverable
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("activitypub_undeliverable")
+
+const (
+	storeName = "undeliverable-activity"
+	statusTag = "status"
+
+	defaultRetryInterval = 10 * time.Minute
+	defaultReplayTimeout = 10 * time.Minute
+	defaultProbeTimeout  = 10 * time.Second
+)
+
+// ErrNotFound is returned when the undeliverable activity entry is not found.
+var ErrNotFound = errors.New("undeliverable activity not found")
+
+// Status is the status of an undeliverable activity entry.
+type Status string
+
+const (
+	// StatusFailed indicates that the activity could not be delivered to the inbox.
+	StatusFailed Status = "failed"
+
+	// StatusReplaying indicates that the activity was replayed and is in the process of being delivered. If the
+	// delivery fails again then the status transitions back to StatusFailed. If the delivery does not fail within
+	// the replay timeout then the delivery is assumed to have succeeded and the entry is removed.
+	StatusReplaying Status = "replaying"
+)
+
+// Entry contains an activity that could not be delivered to an inbox.
+type Entry struct {
+	ID          string              `json:"id"`
+	Activity    *vocab.ActivityType `json:"activity"`
+	InboxURL    string              `json:"inbox"`
+	Status      Status              `json:"status"`
+	LastError   string              `json:"lastError,omitempty"`
+	Attempts    int                 `json:"attempts"`
+	FirstFailed time.Time           `json:"firstFailed"`
+	LastFailed  time.Time           `json:"lastFailed"`
+	ReplayedAt  *time.Time          `json:"replayedAt,omitempty"`
+}
+
+// Config contains the configuration for the undeliverable activity service.
+type Config struct {
+	// RetryInterval is the interval at which undeliverable activities are retried
+	// for hosts that have come back online.
+	RetryInterval time.Duration
+
+	// ReplayTimeout is the time after which a replayed activity that hasn't failed again is
+	// assumed to have been delivered.
+	ReplayTimeout time.Duration
+
+	// ProbeTimeout is the timeout for the request that checks whether or not a host is online.
+	ProbeTimeout time.Duration
+}
+
+// Outbox delivers a previously posted activity to an inbox.
+type Outbox interface {
+	Redeliver(activity *vocab.ActivityType, inboxURL *url.URL) error
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Service persists activities that could not be delivered by the outbox (after all redelivery attempts
+// have failed) so that they may be listed, replayed or discarded. The service also periodically checks
+// whether the hosts of the failed inboxes are back online and, if so, replays the activities.
+type Service struct {
+	*lifecycle.Lifecycle
+
+	store      storage.Store
+	outbox     func() Outbox
+	httpClient httpClient
+	config     Config
+	done       chan struct{}
+	marshal    func(v interface{}) ([]byte, error)
+	unmarshal  func(data []byte, v interface{}) error
+}
+
+// New returns a new undeliverable activity service. The outbox is provided by a function since the
+// service is created before the ActivityPub outbox.
+func New(cfg Config, provider storage.Provider, outbox func() Outbox, httpClient httpClient) (*Service, error) {
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{statusTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	s := &Service{
+		store:      store,
+		outbox:     outbox,
+		httpClient: httpClient,
+		config:     populateConfigDefaults(cfg),
+		done:       make(chan struct{}),
+		marshal:    json.Marshal,
+		unmarshal:  json.Unmarshal,
+	}
+
+	s.Lifecycle = lifecycle.New("undeliverable-activity",
+		lifecycle.WithStart(s.start),
+		lifecycle.WithStop(s.stop))
+
+	return s, nil
+}
+
+func (s *Service) start() {
+	go s.run()
+
+	logger.Infof("Started undeliverable activity service - Retry interval [%s], Replay timeout [%s]",
+		s.config.RetryInterval, s.config.ReplayTimeout)
+}
+
+func (s *Service) stop() {
+	close(s.done)
+
+	logger.Infof("Stopped undeliverable activity service")
+}
+
+// HandleUndeliverableActivity persists the activity that could not be delivered to the given inbox. If the
+// activity was previously recorded for the inbox then the attempt count and last error are updated.
+func (s *Service) HandleUndeliverableActivity(activity *vocab.ActivityType, toURL string, reason error) {
+	if activity.ID() == nil {
+		logger.Warnf("Not storing undeliverable activity to [%s] since it has no ID", toURL)
+
+		return
+	}
+
+	id := newEntryID(activity.ID().String(), toURL)
+
+	now := time.Now()
+
+	entry, err := s.Get(id)
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			logger.Errorf("Error retrieving undeliverable activity [%s]: %s", id, err)
+
+			return
+		}
+
+		entry = &Entry{
+			ID:          id,
+			Activity:    activity,
+			InboxURL:    toURL,
+			FirstFailed: now,
+		}
+	}
+
+	entry.Status = StatusFailed
+	entry.Attempts++
+	entry.LastFailed = now
+	entry.ReplayedAt = nil
+
+	if reason != nil {
+		entry.LastError = reason.Error()
+	}
+
+	if err := s.put(entry); err != nil {
+		logger.Errorf("Error storing undeliverable activity [%s] to [%s]: %s", activity.ID(), toURL, err)
+
+		return
+	}
+
+	logger.Infof("Stored undeliverable activity [%s] to [%s] - Attempts: %d, Reason: %s",
+		activity.ID(), toURL, entry.Attempts, entry.LastError)
+}
+
+// List returns all undeliverable activity entries.
+func (s *Service) List() ([]*Entry, error) {
+	it, err := s.store.Query(statusTag)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query undeliverable activities: %w", err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	var entries []*Entry
+
+	ok, err := it.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+	}
+
+	for ok {
+		value, e := it.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value: %w", e))
+		}
+
+		entry := &Entry{}
+
+		if e := s.unmarshal(value, entry); e != nil {
+			return nil, fmt.Errorf("unmarshal undeliverable activity: %w", e)
+		}
+
+		entries = append(entries, entry)
+
+		ok, err = it.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+	}
+
+	return entries, nil
+}
+
+// Get returns the undeliverable activity entry for the given ID. ErrNotFound is returned
+// if the entry does not exist.
+func (s *Service) Get(id string) (*Entry, error) {
+	value, err := s.store.Get(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("get undeliverable activity [%s]: %w", id, err))
+	}
+
+	entry := &Entry{}
+
+	if err := s.unmarshal(value, entry); err != nil {
+		return nil, fmt.Errorf("unmarshal undeliverable activity [%s]: %w", id, err)
+	}
+
+	return entry, nil
+}
+
+// Replay redelivers the activity of the given entry to the entry's inbox. The entry remains in the store
+// until the replay timeout elapses so that its attempt count is preserved if the delivery fails again.
+func (s *Service) Replay(id string) error {
+	entry, err := s.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return s.replay(entry)
+}
+
+// Discard removes the undeliverable activity entry for the given ID. ErrNotFound is returned
+// if the entry does not exist.
+func (s *Service) Discard(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+
+	if err := s.store.Delete(id); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("delete undeliverable activity [%s]: %w", id, err))
+	}
+
+	logger.Infof("Discarded undeliverable activity [%s]", id)
+
+	return nil
+}
+
+func (s *Service) replay(entry *Entry) error {
+	inboxURL, err := url.Parse(entry.InboxURL)
+	if err != nil {
+		return fmt.Errorf("parse inbox URL [%s]: %w", entry.InboxURL, err)
+	}
+
+	// Update the status before redelivering since the outbox may call HandleUndeliverableActivity
+	// (if the delivery fails) before Redeliver returns.
+	now := time.Now()
+
+	entry.Status = StatusReplaying
+	entry.ReplayedAt = &now
+
+	if err := s.put(entry); err != nil {
+		return err
+	}
+
+	if err := s.outbox().Redeliver(entry.Activity, inboxURL); err != nil {
+		return fmt.Errorf("redeliver activity [%s] to [%s]: %w", entry.Activity.ID(), inboxURL, err)
+	}
+
+	logger.Infof("Replayed undeliverable activity [%s] to [%s]", entry.Activity.ID(), inboxURL)
+
+	return nil
+}
+
+func (s *Service) put(entry *Entry) error {
+	entryBytes, err := s.marshal(entry)
+	if err != nil {
+		return fmt.Errorf("marshal undeliverable activity: %w", err)
+	}
+
+	err = s.store.Put(entry.ID, entryBytes, storage.Tag{Name: statusTag, Value: string(entry.Status)})
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store undeliverable activity [%s]: %w", entry.ID, err))
+	}
+
+	return nil
+}
+
+func (s *Service) run() {
+	for {
+		select {
+		case <-time.After(s.config.RetryInterval):
+			s.retry()
+		case <-s.done:
+			logger.Debugf("Exiting undeliverable activity retry job.")
+
+			return
+		}
+	}
+}
+
+// retry removes replayed entries that haven't failed again within the replay timeout and replays the
+// failed entries whose host is back online.
+func (s *Service) retry() {
+	entries, err := s.List()
+	if err != nil {
+		logger.Errorf("Error retrieving undeliverable activities: %s", err)
+
+		return
+	}
+
+	logger.Debugf("Checking %d undeliverable activities", len(entries))
+
+	hostsOnline := make(map[string]bool)
+
+	for _, entry := range entries {
+		switch entry.Status {
+		case StatusReplaying:
+			if entry.ReplayedAt != nil && time.Since(*entry.ReplayedAt) > s.config.ReplayTimeout {
+				logger.Debugf("Activity [%s] to [%s] was replayed at %s and has not failed since. Removing entry.",
+					entry.Activity.ID(), entry.InboxURL, entry.ReplayedAt)
+
+				if err := s.store.Delete(entry.ID); err != nil {
+					logger.Warnf("Error deleting undeliverable activity [%s]: %s", entry.ID, err)
+				}
+			}
+
+		case StatusFailed:
+			online, ok := hostsOnline[hostOf(entry.InboxURL)]
+			if !ok {
+				online = s.isOnline(entry.InboxURL)
+
+				hostsOnline[hostOf(entry.InboxURL)] = online
+			}
+
+			if !online {
+				continue
+			}
+
+			if err := s.replay(entry); err != nil {
+				logger.Warnf("Error replaying undeliverable activity [%s]: %s", entry.ID, err)
+			}
+		}
+	}
+}
+
+// isOnline returns true if the host of the given inbox responds to an HTTP request. Any response
+// (including an error status code) indicates that the host is online.
+func (s *Service) isOnline(inboxURL string) bool {
+	u, err := url.Parse(inboxURL)
+	if err != nil {
+		logger.Warnf("Invalid inbox URL [%s]: %s", inboxURL, err)
+
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ProbeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s://%s", u.Scheme, u.Host), nil)
+	if err != nil {
+		logger.Warnf("Error creating request for [%s]: %s", u.Host, err)
+
+		return false
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		logger.Debugf("Host [%s] is still offline: %s", u.Host, err)
+
+		return false
+	}
+
+	if err := resp.Body.Close(); err != nil {
+		logger.Warnf("Error closing response body: %s", err)
+	}
+
+	logger.Debugf("Host [%s] is online", u.Host)
+
+	return true
+}
+
+func hostOf(inboxURL string) string {
+	u, err := url.Parse(inboxURL)
+	if err != nil {
+		return inboxURL
+	}
+
+	return u.Host
+}
+
+func newEntryID(activityID, inboxURL string) string {
+	h := sha256.Sum256([]byte(activityID + " " + inboxURL))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func populateConfigDefaults(cfg Config) Config {
+	if cfg.RetryInterval == 0 {
+		cfg.RetryInterval = defaultRetryInterval
+	}
+
+	if cfg.ReplayTimeout == 0 {
+		cfg.ReplayTimeout = defaultReplayTimeout
+	}
+
+	if cfg.ProbeTimeout == 0 {
+		cfg.ProbeTimeout = defaultProbeTimeout
+	}
+
+	return cfg
+}
@@ -0,0 +1,310 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package undeliverable
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+var (
+	service1IRI = testutil.MustParseURL("https://orb.domain1.com/services/orb")
+	inbox2URL   = "https://orb.domain2.com/services/orb/inbox"
+	inbox3URL   = "https://orb.domain3.com/services/orb/inbox"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s, err := New(Config{}, mem.NewProvider(), nil, nil)
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		require.Equal(t, defaultRetryInterval, s.config.RetryInterval)
+		require.Equal(t, defaultReplayTimeout, s.config.ReplayTimeout)
+		require.Equal(t, defaultProbeTimeout, s.config.ProbeTimeout)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(nil, errExpected)
+
+		_, err := New(Config{}, p, nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		errExpected := errors.New("injected set config error")
+
+		p := &storemocks.Provider{}
+		p.SetStoreConfigReturns(errExpected)
+
+		_, err := New(Config{}, p, nil, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestService_HandleUndeliverableActivity(t *testing.T) {
+	s, err := New(Config{}, mem.NewProvider(), nil, nil)
+	require.NoError(t, err)
+
+	activity := newActivity()
+
+	s.HandleUndeliverableActivity(activity, inbox2URL, errors.New("connection refused"))
+	s.HandleUndeliverableActivity(activity, inbox3URL, nil)
+
+	entries, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entry, err := s.Get(newEntryID(activity.ID().String(), inbox2URL))
+	require.NoError(t, err)
+	require.Equal(t, activity.ID().String(), entry.Activity.ID().String())
+	require.Equal(t, inbox2URL, entry.InboxURL)
+	require.Equal(t, StatusFailed, entry.Status)
+	require.Equal(t, "connection refused", entry.LastError)
+	require.Equal(t, 1, entry.Attempts)
+
+	s.HandleUndeliverableActivity(activity, inbox2URL, errors.New("server responded with error 500"))
+
+	entries, err = s.List()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	entry, err = s.Get(newEntryID(activity.ID().String(), inbox2URL))
+	require.NoError(t, err)
+	require.Equal(t, "server responded with error 500", entry.LastError)
+	require.Equal(t, 2, entry.Attempts)
+	require.True(t, entry.LastFailed.After(entry.FirstFailed))
+
+	t.Run("No activity ID", func(t *testing.T) {
+		s.HandleUndeliverableActivity(vocab.NewCreateActivity(nil), inbox2URL, nil)
+
+		entries, err = s.List()
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		store := &storemocks.Store{}
+		store.GetReturns(nil, storage.ErrDataNotFound)
+		store.PutReturns(errExpected)
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(store, nil)
+
+		s, err := New(Config{}, p, nil, nil)
+		require.NoError(t, err)
+
+		require.NotPanics(t, func() { s.HandleUndeliverableActivity(activity, inbox2URL, nil) })
+
+		store.GetReturns(nil, errExpected)
+
+		require.NotPanics(t, func() { s.HandleUndeliverableActivity(activity, inbox2URL, nil) })
+	})
+}
+
+func TestService_ReplayAndDiscard(t *testing.T) {
+	ob := &mockOutbox{}
+
+	s, err := New(Config{}, mem.NewProvider(), func() Outbox { return ob }, nil)
+	require.NoError(t, err)
+
+	activity := newActivity()
+
+	s.HandleUndeliverableActivity(activity, inbox2URL, errors.New("connection refused"))
+
+	id := newEntryID(activity.ID().String(), inbox2URL)
+
+	t.Run("Replay", func(t *testing.T) {
+		require.NoError(t, s.Replay(id))
+
+		require.Len(t, ob.Delivered(), 1)
+		require.Equal(t, inbox2URL, ob.Delivered()[0])
+
+		entry, err := s.Get(id)
+		require.NoError(t, err)
+		require.Equal(t, StatusReplaying, entry.Status)
+		require.NotNil(t, entry.ReplayedAt)
+
+		// The delivery failed again.
+		s.HandleUndeliverableActivity(activity, inbox2URL, errors.New("connection refused"))
+
+		entry, err = s.Get(id)
+		require.NoError(t, err)
+		require.Equal(t, StatusFailed, entry.Status)
+		require.Nil(t, entry.ReplayedAt)
+		require.Equal(t, 2, entry.Attempts)
+	})
+
+	t.Run("Replay - outbox error", func(t *testing.T) {
+		errExpected := errors.New("injected outbox error")
+
+		ob.err = errExpected
+		defer func() { ob.err = nil }()
+
+		err := s.Replay(id)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Replay - not found", func(t *testing.T) {
+		require.True(t, errors.Is(s.Replay("unknown"), ErrNotFound))
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		require.NoError(t, s.Discard(id))
+
+		_, err := s.Get(id)
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		require.True(t, errors.Is(s.Discard(id), ErrNotFound))
+	})
+
+	t.Run("Store errors", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		store := &storemocks.Store{}
+		store.GetReturns(nil, errExpected)
+		store.QueryReturns(nil, errExpected)
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(store, nil)
+
+		s, err := New(Config{}, p, nil, nil)
+		require.NoError(t, err)
+
+		_, err = s.List()
+		require.True(t, orberrors.IsTransient(err))
+
+		err = s.Replay(id)
+		require.True(t, orberrors.IsTransient(err))
+
+		err = s.Discard(id)
+		require.True(t, orberrors.IsTransient(err))
+
+		store.GetReturns([]byte("{"), nil)
+
+		_, err = s.Get(id)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal")
+
+		store.GetReturns([]byte("{}"), nil)
+		store.DeleteReturns(errExpected)
+
+		err = s.Discard(id)
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
+
+func TestService_Retry(t *testing.T) {
+	ob := &mockOutbox{}
+
+	httpClient := &mockHTTPClient{
+		online: map[string]bool{"orb.domain2.com": true},
+	}
+
+	s, err := New(Config{
+		RetryInterval: 50 * time.Millisecond,
+		ReplayTimeout: 100 * time.Millisecond,
+	}, mem.NewProvider(), func() Outbox { return ob }, httpClient)
+	require.NoError(t, err)
+
+	s.Start()
+	defer s.Stop()
+
+	activity := newActivity()
+
+	s.HandleUndeliverableActivity(activity, inbox2URL, errors.New("connection refused"))
+	s.HandleUndeliverableActivity(activity, inbox3URL, errors.New("connection refused"))
+
+	time.Sleep(100 * time.Millisecond)
+
+	// Only the activity to the online host should have been replayed.
+	require.Equal(t, []string{inbox2URL}, ob.Delivered())
+
+	entry, err := s.Get(newEntryID(activity.ID().String(), inbox3URL))
+	require.NoError(t, err)
+	require.Equal(t, StatusFailed, entry.Status)
+
+	time.Sleep(300 * time.Millisecond)
+
+	// The replayed activity did not fail again so it should have been removed.
+	_, err = s.Get(newEntryID(activity.ID().String(), inbox2URL))
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = s.Get(newEntryID(activity.ID().String(), inbox3URL))
+	require.NoError(t, err)
+}
+
+func newActivity() *vocab.ActivityType {
+	return vocab.NewCreateActivity(
+		vocab.NewObjectProperty(vocab.WithIRI(testutil.MustParseURL("https://example.com/transactions/txn1"))),
+		vocab.WithID(testutil.NewMockID(service1IRI, "/activities/123")),
+		vocab.WithActor(service1IRI),
+	)
+}
+
+type mockOutbox struct {
+	mutex     sync.Mutex
+	delivered []string
+	err       error
+}
+
+func (m *mockOutbox) Redeliver(_ *vocab.ActivityType, inboxURL *url.URL) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.delivered = append(m.delivered, inboxURL.String())
+
+	return nil
+}
+
+func (m *mockOutbox) Delivered() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.delivered
+}
+
+type mockHTTPClient struct {
+	online map[string]bool
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if !m.online[req.URL.Host] {
+		return nil, errors.New("connection refused")
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}, nil
+}
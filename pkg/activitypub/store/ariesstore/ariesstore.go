@@ -158,18 +158,17 @@ func (s *Provider) QueryActivities(query *spi.Criteria, opts ...spi.QueryOpt) (s
 	}
 
 	if len(query.ActivityIRIs) == 0 && len(query.Types) == 0 { // Get all activities
-		iterator, err := s.activityStore.Query(activityTag,
-			ariesstorage.WithSortOrder(&ariesstorage.SortOptions{
-				Order:   ariesstorage.SortOrder(options.SortOrder),
-				TagName: timeAddedTagName,
-			}),
-			ariesstorage.WithPageSize(options.PageSize),
-			ariesstorage.WithInitialPageNum(options.PageNumber))
+		startAfter, err := newStartCursor(options)
 		if err != nil {
-			return nil, orberrors.NewTransient(fmt.Errorf("failed to query store: %w", err))
+			return nil, err
 		}
 
-		return &activityIterator{ariesIterator: iterator}, nil
+		iterator, err := queryStore(s.activityStore, activityTag, options, startAfter)
+		if err != nil {
+			return nil, err
+		}
+
+		return &activityIterator{ariesIterator: iterator, startAfter: startAfter}, nil
 	}
 
 	return nil, errors.New("unsupported query criteria")
@@ -241,20 +240,20 @@ func (s *Provider) QueryReferences(referenceType spi.ReferenceType, query *spi.C
 
 	// If no reference IRI is set, then grab all references associated with the object IRI.
 	if query.ReferenceIRI == nil {
-		iterator, err := referenceStore.Query(
+		startAfter, err := newStartCursor(options)
+		if err != nil {
+			return nil, err
+		}
+
+		iterator, err := queryStore(referenceStore,
 			fmt.Sprintf("%s:%s", objectIRITagName,
 				base64.RawStdEncoding.EncodeToString([]byte(query.ObjectIRI.String()))),
-			ariesstorage.WithSortOrder(&ariesstorage.SortOptions{
-				Order:   ariesstorage.SortOrder(options.SortOrder),
-				TagName: timeAddedTagName,
-			}),
-			ariesstorage.WithPageSize(options.PageSize),
-			ariesstorage.WithInitialPageNum(options.PageNumber))
+			options, startAfter)
 		if err != nil {
-			return nil, orberrors.NewTransient(fmt.Errorf("failed to query store: %w", err))
+			return nil, err
 		}
 
-		return &referenceIterator{ariesIterator: iterator}, nil
+		return &referenceIterator{ariesIterator: iterator, startAfter: startAfter}, nil
 	}
 
 	// Otherwise, if there is a reference IRI,
@@ -293,7 +292,7 @@ func (s *Provider) queryActivitiesByRef(refType spi.ReferenceType, query *spi.Cr
 
 	options := storeutil.GetQueryOptions(opts...)
 
-	refs, err := storeutil.ReadReferences(iterator, options.PageSize)
+	refs, refCursors, err := storeutil.ReadReferencesWithCursors(iterator, options.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -323,7 +322,9 @@ func (s *Provider) queryActivitiesByRef(refType spi.ReferenceType, query *spi.Cr
 
 	var activities []*vocab.ActivityType
 
-	for _, activityBytes := range activitiesBytes {
+	var cursors []string
+
+	for i, activityBytes := range activitiesBytes {
 		if activityBytes != nil {
 			var activity vocab.ActivityType
 
@@ -333,22 +334,28 @@ func (s *Provider) queryActivitiesByRef(refType spi.ReferenceType, query *spi.Cr
 			}
 
 			activities = append(activities, &activity)
+			cursors = append(cursors, refCursors[i])
 		}
 	}
 
-	return memstore.NewActivityIterator(activities, totalItems), nil
+	return memstore.NewActivityIterator(activities, totalItems, memstore.WithCursors(cursors)), nil
 }
 
 type activityIterator struct {
 	ariesIterator ariesstorage.Iterator
+	startAfter    *startCursor
 }
 
 func (a *activityIterator) TotalItems() (int, error) {
 	return a.ariesIterator.TotalItems()
 }
 
+func (a *activityIterator) Cursor() string {
+	return getCursor(a.ariesIterator)
+}
+
 func (a *activityIterator) Next() (*vocab.ActivityType, error) {
-	areMoreResults, err := a.ariesIterator.Next()
+	areMoreResults, err := next(a.ariesIterator, a.startAfter)
 	if err != nil {
 		return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
 	}
@@ -378,14 +385,19 @@ func (a *activityIterator) Close() error {
 
 type referenceIterator struct {
 	ariesIterator ariesstorage.Iterator
+	startAfter    *startCursor
 }
 
 func (r *referenceIterator) TotalItems() (int, error) {
 	return r.ariesIterator.TotalItems()
 }
 
+func (r *referenceIterator) Cursor() string {
+	return getCursor(r.ariesIterator)
+}
+
 func (r *referenceIterator) Next() (*url.URL, error) {
-	areMoreResults, err := r.ariesIterator.Next()
+	areMoreResults, err := next(r.ariesIterator, r.startAfter)
 	if err != nil {
 		return nil, orberrors.NewTransient(fmt.Errorf("failed to determine if there are more results: %w", err))
 	}
@@ -418,6 +430,199 @@ func (r *referenceIterator) Close() error {
 	return r.ariesIterator.Close()
 }
 
+// startCursor holds the cursor (i.e. the time-added value of an item) after which results are to be returned.
+type startCursor struct {
+	timeAdded int64
+	sortOrder spi.SortOrder
+}
+
+func newStartCursor(options *spi.QueryOptions) (*startCursor, error) {
+	if options.Cursor == "" {
+		return nil, nil
+	}
+
+	timeAdded, err := strconv.ParseInt(options.Cursor, 10, 64)
+	if err != nil {
+		return nil, orberrors.NewBadRequest(fmt.Errorf("invalid cursor [%s]: %w", options.Cursor, err))
+	}
+
+	return &startCursor{timeAdded: timeAdded, sortOrder: options.SortOrder}, nil
+}
+
+// follows returns true if an item with the given time-added value follows the cursor in the sort order.
+func (c *startCursor) follows(timeAdded int64) bool {
+	if c.sortOrder == spi.SortDescending {
+		return timeAdded < c.timeAdded
+	}
+
+	return timeAdded > c.timeAdded
+}
+
+// queryStore queries the given store. If a start cursor is provided then the page number in the options
+// is ignored and the query starts at the page that contains the item at the cursor.
+func queryStore(store ariesstorage.Store, expression string, options *spi.QueryOptions,
+	startAfter *startCursor) (ariesstorage.Iterator, error) {
+	pageNum := options.PageNumber
+
+	if startAfter != nil {
+		var err error
+
+		pageNum, err = seek(store, expression, options, startAfter)
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("seek to cursor: %w", err))
+		}
+	}
+
+	iterator, err := store.Query(expression, getQueryOptions(options, pageNum)...)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query store: %w", err))
+	}
+
+	return iterator, nil
+}
+
+// seek returns the number of the page from which to start iterating in order to reach the item that follows
+// the given cursor. The Aries storage interface doesn't support range queries on tags, so a binary search is
+// performed over the pages using the time-added value of the first item of each page. This way only the items
+// in the returned page that precede the cursor need to be skipped.
+func seek(store ariesstorage.Store, expression string, options *spi.QueryOptions,
+	startAfter *startCursor) (int, error) {
+	if options.PageSize <= 0 {
+		// All results are returned in a single page.
+		return 0, nil
+	}
+
+	totalItems, _, err := firstItemOfPage(store, expression, options, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the first page whose first item follows the cursor. The cursor is in the page before it.
+	low, high := 0, (totalItems+options.PageSize-1)/options.PageSize
+
+	for low < high {
+		mid := low + (high-low)/2
+
+		_, timeAdded, err := firstItemOfPage(store, expression, options, mid)
+		if err != nil {
+			return 0, err
+		}
+
+		if timeAdded == nil || startAfter.follows(*timeAdded) {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	if low == 0 {
+		return 0, nil
+	}
+
+	return low - 1, nil
+}
+
+// firstItemOfPage returns the total number of items along with the time-added value of the first item
+// in the given page. Nil is returned for the time-added value if the page is empty.
+func firstItemOfPage(store ariesstorage.Store, expression string, options *spi.QueryOptions,
+	pageNum int) (int, *int64, error) {
+	it, err := store.Query(expression, getQueryOptions(options, pageNum)...)
+	if err != nil {
+		return 0, nil, fmt.Errorf("query store: %w", err)
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Warnf("Error closing iterator: %s", e)
+		}
+	}()
+
+	totalItems, err := it.TotalItems()
+	if err != nil {
+		return 0, nil, fmt.Errorf("get total items: %w", err)
+	}
+
+	ok, err := it.Next()
+	if err != nil {
+		return 0, nil, fmt.Errorf("next: %w", err)
+	}
+
+	if !ok {
+		return totalItems, nil, nil
+	}
+
+	timeAdded, err := getTimeAdded(it)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return totalItems, &timeAdded, nil
+}
+
+func getQueryOptions(options *spi.QueryOptions, pageNum int) []ariesstorage.QueryOption {
+	return []ariesstorage.QueryOption{
+		ariesstorage.WithSortOrder(&ariesstorage.SortOptions{
+			Order:   ariesstorage.SortOrder(options.SortOrder),
+			TagName: timeAddedTagName,
+		}),
+		ariesstorage.WithPageSize(options.PageSize),
+		ariesstorage.WithInitialPageNum(pageNum),
+	}
+}
+
+// next advances the given iterator. If a start cursor is provided then the items up to (and including)
+// the item at the cursor are skipped. The query is expected to start at the page that contains the cursor
+// (see seek) so at most one page of items is skipped. The results remain stable even if items are added
+// while paging.
+func next(it ariesstorage.Iterator, startAfter *startCursor) (bool, error) {
+	for {
+		ok, err := it.Next()
+		if err != nil || !ok || startAfter == nil {
+			return ok, err
+		}
+
+		timeAdded, err := getTimeAdded(it)
+		if err != nil {
+			return false, err
+		}
+
+		if startAfter.follows(timeAdded) {
+			return true, nil
+		}
+	}
+}
+
+func getCursor(it ariesstorage.Iterator) string {
+	timeAdded, err := getTimeAdded(it)
+	if err != nil {
+		logger.Warnf("Unable to determine cursor of current item: %s", err)
+
+		return ""
+	}
+
+	return strconv.FormatInt(timeAdded, 10)
+}
+
+func getTimeAdded(it ariesstorage.Iterator) (int64, error) {
+	tags, err := it.Tags()
+	if err != nil {
+		return 0, fmt.Errorf("get tags: %w", err)
+	}
+
+	for _, tag := range tags {
+		if tag.Name == timeAddedTagName {
+			timeAdded, err := strconv.ParseInt(tag.Value, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value for tag [%s]: %w", timeAddedTagName, err)
+			}
+
+			return timeAdded, nil
+		}
+	}
+
+	return 0, fmt.Errorf("tag [%s] not found", timeAddedTagName)
+}
+
 type stores struct {
 	activities ariesstorage.Store
 	reference  map[spi.ReferenceType]ariesstorage.Store
@@ -458,7 +663,7 @@ func openStores(provider ariesstorage.Provider) (stores, error) {
 func openReferenceStores(provider ariesstorage.Provider) (map[spi.ReferenceType]ariesstorage.Store, error) {
 	referenceTypes := []spi.ReferenceType{
 		spi.Inbox, spi.Outbox, spi.PublicOutbox, spi.Follower, spi.Following, spi.Witness,
-		spi.Witnessing, spi.Like, spi.Liked, spi.Share, spi.AnchorCredential,
+		spi.Witnessing, spi.Like, spi.Liked, spi.Share, spi.AnchorCredential, spi.Blocked,
 	}
 
 	storeConfig := ariesstorage.StoreConfiguration{
@@ -6,11 +6,18 @@ SPDX-License-Identifier: Apache-2.0
 package ariesstore
 
 import (
+	"encoding/json"
 	"errors"
+	"sort"
+	"strconv"
 	"testing"
 
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 func TestIterators_FailureCases(t *testing.T) {
@@ -45,3 +52,232 @@ func TestIterators_FailureCases(t *testing.T) {
 		require.Nil(t, activity)
 	})
 }
+
+func TestIterators_Cursor(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		iterator := referenceIterator{ariesIterator: &mock.Iterator{
+			NextReturn: true,
+			TagsReturn: []ariesstorage.Tag{{Name: timeAddedTagName, Value: "1000"}},
+		}}
+
+		require.Equal(t, "1000", iterator.Cursor())
+	})
+
+	t.Run("Tags error", func(t *testing.T) {
+		iterator := activityIterator{ariesIterator: &mock.Iterator{ErrTags: errors.New("tags error")}}
+
+		require.Empty(t, iterator.Cursor())
+
+		startAfter, err := newStartCursor(&spi.QueryOptions{Cursor: "1000"})
+		require.NoError(t, err)
+
+		iterator = activityIterator{ariesIterator: &mock.Iterator{
+			NextReturn: true,
+			ErrTags:    errors.New("tags error"),
+		}, startAfter: startAfter}
+
+		activity, err := iterator.Next()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tags error")
+		require.Nil(t, activity)
+	})
+
+	t.Run("Invalid tag value", func(t *testing.T) {
+		iterator := referenceIterator{ariesIterator: &mock.Iterator{
+			TagsReturn: []ariesstorage.Tag{{Name: timeAddedTagName, Value: "xxx"}},
+		}}
+
+		require.Empty(t, iterator.Cursor())
+	})
+
+	t.Run("Tag not found", func(t *testing.T) {
+		iterator := referenceIterator{ariesIterator: &mock.Iterator{}}
+
+		require.Empty(t, iterator.Cursor())
+	})
+
+	t.Run("Skip items up to cursor", func(t *testing.T) {
+		startAfter, err := newStartCursor(&spi.QueryOptions{Cursor: "1000", SortOrder: spi.SortDescending})
+		require.NoError(t, err)
+
+		require.True(t, startAfter.follows(999))
+		require.False(t, startAfter.follows(1000))
+		require.False(t, startAfter.follows(1001))
+
+		startAfter, err = newStartCursor(&spi.QueryOptions{Cursor: "1000", SortOrder: spi.SortAscending})
+		require.NoError(t, err)
+
+		require.False(t, startAfter.follows(999))
+		require.False(t, startAfter.follows(1000))
+		require.True(t, startAfter.follows(1001))
+	})
+}
+
+func TestQueryStore_Seek(t *testing.T) {
+	// Items are added with time-added values 10, 20, ..., 1000.
+	store := newPagedStore(100)
+
+	queryFrom := func(t *testing.T, cursor string, sortOrder spi.SortOrder, pageSize int) []string {
+		t.Helper()
+
+		options := &spi.QueryOptions{PageSize: pageSize, SortOrder: sortOrder, Cursor: cursor}
+
+		startAfter, err := newStartCursor(options)
+		require.NoError(t, err)
+
+		it, err := queryStore(store, activityTag, options, startAfter)
+		require.NoError(t, err)
+
+		iterator := &referenceIterator{ariesIterator: it, startAfter: startAfter}
+
+		var cursors []string
+
+		for i := 0; i < pageSize; i++ {
+			if _, err := iterator.Next(); err != nil {
+				require.True(t, errors.Is(err, spi.ErrNotFound))
+
+				break
+			}
+
+			cursors = append(cursors, iterator.Cursor())
+		}
+
+		return cursors
+	}
+
+	t.Run("Ascending", func(t *testing.T) {
+		require.Equal(t, []string{"510", "520", "530"}, queryFrom(t, "500", spi.SortAscending, 3))
+		// Only the items in the page that contains the cursor were skipped.
+		require.LessOrEqual(t, store.last.numRead, 2*3)
+
+		require.Equal(t, []string{"10", "20"}, queryFrom(t, "5", spi.SortAscending, 2))
+		require.Equal(t, []string{"1000"}, queryFrom(t, "990", spi.SortAscending, 2))
+		require.Empty(t, queryFrom(t, "1000", spi.SortAscending, 2))
+	})
+
+	t.Run("Descending", func(t *testing.T) {
+		require.Equal(t, []string{"490", "480", "470"}, queryFrom(t, "500", spi.SortDescending, 3))
+		require.LessOrEqual(t, store.last.numRead, 2*3)
+
+		// The item at the cursor no longer exists.
+		require.Equal(t, []string{"490", "480"}, queryFrom(t, "495", spi.SortDescending, 2))
+		require.Equal(t, []string{"10"}, queryFrom(t, "20", spi.SortDescending, 2))
+		require.Empty(t, queryFrom(t, "10", spi.SortDescending, 2))
+	})
+
+	t.Run("No page size", func(t *testing.T) {
+		options := &spi.QueryOptions{PageSize: -1, Cursor: "500"}
+
+		startAfter, err := newStartCursor(options)
+		require.NoError(t, err)
+
+		pageNum, err := seek(store, activityTag, options, startAfter)
+		require.NoError(t, err)
+		require.Zero(t, pageNum)
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		s := newPagedStore(10)
+		s.queryErr = errExpected
+
+		options := &spi.QueryOptions{PageSize: 2, Cursor: "50"}
+
+		startAfter, err := newStartCursor(options)
+		require.NoError(t, err)
+
+		_, err = queryStore(s, activityTag, options, startAfter)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, errExpected))
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
+
+// pagedStore is an Aries store that supports sorting by the time-added tag and paging, which the
+// in-memory Aries store doesn't support.
+type pagedStore struct {
+	ariesstorage.Store
+
+	timesAdded []int64
+	queryErr   error
+	last       *pagedIterator
+}
+
+func newPagedStore(numItems int) *pagedStore {
+	s := &pagedStore{}
+
+	for i := 1; i <= numItems; i++ {
+		s.timesAdded = append(s.timesAdded, int64(i*10))
+	}
+
+	return s
+}
+
+func (s *pagedStore) Query(_ string, opts ...ariesstorage.QueryOption) (ariesstorage.Iterator, error) {
+	if s.queryErr != nil {
+		return nil, s.queryErr
+	}
+
+	options := &ariesstorage.QueryOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	timesAdded := append([]int64{}, s.timesAdded...)
+
+	if options.SortOptions != nil && options.SortOptions.Order == ariesstorage.SortDescending {
+		sort.Slice(timesAdded, func(i, j int) bool { return timesAdded[i] > timesAdded[j] })
+	}
+
+	start := options.InitialPageNum * options.PageSize
+	if start > len(timesAdded) {
+		start = len(timesAdded)
+	}
+
+	s.last = &pagedIterator{timesAdded: timesAdded[start:], totalItems: len(timesAdded), current: -1}
+
+	return s.last, nil
+}
+
+type pagedIterator struct {
+	timesAdded []int64
+	totalItems int
+	current    int
+	numRead    int
+}
+
+func (it *pagedIterator) Next() (bool, error) {
+	it.current++
+
+	if it.current >= len(it.timesAdded) {
+		return false, nil
+	}
+
+	it.numRead++
+
+	return true, nil
+}
+
+func (it *pagedIterator) Key() (string, error) {
+	return strconv.FormatInt(it.timesAdded[it.current], 10), nil
+}
+
+func (it *pagedIterator) Value() ([]byte, error) {
+	return json.Marshal("https://example.com/" + strconv.FormatInt(it.timesAdded[it.current], 10))
+}
+
+func (it *pagedIterator) Tags() ([]ariesstorage.Tag, error) {
+	return []ariesstorage.Tag{
+		{Name: timeAddedTagName, Value: strconv.FormatInt(it.timesAdded[it.current], 10)},
+	}, nil
+}
+
+func (it *pagedIterator) TotalItems() (int, error) {
+	return it.totalItems, nil
+}
+
+func (it *pagedIterator) Close() error {
+	return nil
+}
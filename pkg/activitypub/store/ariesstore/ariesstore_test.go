@@ -31,6 +31,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/store/ariesstore"
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
 
@@ -524,6 +525,47 @@ func TestStore_Reference(t *testing.T) {
 
 		checkReferenceQueryResultsInOrder(t, it, 1, actor3)
 	})
+	t.Run("Cursor", func(t *testing.T) {
+		serviceName := generateRandomServiceName()
+		couchDBProvider, err := ariescouchdbstorage.NewProvider(couchDBURL, ariescouchdbstorage.WithDBPrefix(serviceName))
+		require.NoError(t, err)
+
+		s, err := ariesstore.New(couchDBProvider, serviceName)
+		require.NoError(t, err)
+
+		actor1 := testutil.MustParseURL("https://actor1")
+		actor2 := testutil.MustParseURL("https://actor2")
+		actor3 := testutil.MustParseURL("https://actor3")
+		actor4 := testutil.MustParseURL("https://actor4")
+
+		require.NoError(t, s.AddReference(spi.Follower, actor1, actor2))
+		require.NoError(t, s.AddReference(spi.Follower, actor1, actor3))
+
+		it, err := s.QueryReferences(spi.Follower, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+			spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(1))
+		require.NoError(t, err)
+
+		iri, err := it.Next()
+		require.NoError(t, err)
+		require.Equal(t, actor3.String(), iri.String())
+
+		cursor := it.Cursor()
+		require.NotEmpty(t, cursor)
+
+		// Adding a reference while paging shouldn't affect the next page.
+		require.NoError(t, s.AddReference(spi.Follower, actor1, actor4))
+
+		it, err = s.QueryReferences(spi.Follower, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+			spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(1), spi.WithCursor(cursor))
+		require.NoError(t, err)
+
+		checkReferenceQueryResultsInOrder(t, it, 3, actor2)
+
+		_, err = s.QueryReferences(spi.Follower, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+			spi.WithCursor("invalid"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsBadRequest(err))
+	})
 	t.Run("Fail to add reference", func(t *testing.T) {
 		t.Run("Fail to store in underlying storage", func(t *testing.T) {
 			provider, err := ariesstore.New(&mock.Provider{
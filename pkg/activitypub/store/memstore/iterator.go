@@ -16,13 +16,31 @@ import (
 type iterator struct {
 	current    int
 	totalItems int
+	cursors    []string
 }
 
-func newIterator(totalItems int) *iterator {
-	return &iterator{
+// IteratorOpt sets an iterator option.
+type IteratorOpt func(it *iterator)
+
+// WithCursors sets the cursors of the results. The cursor at a given index corresponds
+// to the result at the same index.
+func WithCursors(cursors []string) IteratorOpt {
+	return func(it *iterator) {
+		it.cursors = cursors
+	}
+}
+
+func newIterator(totalItems int, opts ...IteratorOpt) *iterator {
+	it := &iterator{
 		totalItems: totalItems,
 		current:    -1,
 	}
+
+	for _, opt := range opts {
+		opt(it)
+	}
+
+	return it
 }
 
 func (it *iterator) TotalItems() (int, error) {
@@ -33,6 +51,16 @@ func (it *iterator) Close() error {
 	return nil
 }
 
+// Cursor returns the cursor of the item that was most recently returned by Next or an empty string
+// if no cursor is available.
+func (it *iterator) Cursor() string {
+	if it.current < 0 || it.current >= len(it.cursors) {
+		return ""
+	}
+
+	return it.cursors[it.current]
+}
+
 // ActivityIterator is used to iterator over activities.
 type ActivityIterator struct {
 	*iterator
@@ -40,9 +68,9 @@ type ActivityIterator struct {
 }
 
 // NewActivityIterator creates a new ActivityIterator.
-func NewActivityIterator(results []*vocab.ActivityType, totalItems int) *ActivityIterator {
+func NewActivityIterator(results []*vocab.ActivityType, totalItems int, opts ...IteratorOpt) *ActivityIterator {
 	return &ActivityIterator{
-		iterator: newIterator(totalItems),
+		iterator: newIterator(totalItems, opts...),
 		results:  results,
 	}
 }
@@ -65,9 +93,9 @@ type ReferenceIterator struct {
 }
 
 // NewReferenceIterator creates a new ReferenceIterator.
-func NewReferenceIterator(results []*url.URL, totalItems int) *ReferenceIterator {
+func NewReferenceIterator(results []*url.URL, totalItems int, opts ...IteratorOpt) *ReferenceIterator {
 	return &ReferenceIterator{
-		iterator: newIterator(totalItems),
+		iterator: newIterator(totalItems, opts...),
 		results:  results,
 	}
 }
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/url"
 	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/trustbloc/edge-core/pkg/log"
@@ -17,6 +18,7 @@ import (
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
 	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
 var logger = log.New("activitypub_memstore")
@@ -47,6 +49,7 @@ func New(serviceName string) *Store {
 			spi.Liked:            newReferenceStore(),
 			spi.Share:            newReferenceStore(),
 			spi.AnchorCredential: newReferenceStore(),
+			spi.Blocked:          newReferenceStore(),
 		},
 		actorStore: make(map[string]*vocab.ActorType),
 	}
@@ -104,7 +107,7 @@ func (s *Store) QueryActivities(query *spi.Criteria, opts ...spi.QueryOpt) (spi.
 		return s.queryActivitiesByRef(query.ReferenceType, query, opts...)
 	}
 
-	return s.activityStore.query(query, opts...), nil
+	return s.activityStore.query(query, opts...)
 }
 
 // AddReference adds the reference of the given type to the given object.
@@ -156,7 +159,7 @@ func (s *Store) queryActivitiesByRef(refType spi.ReferenceType, query *spi.Crite
 
 	options := storeutil.GetQueryOptions(opts...)
 
-	refs, err := storeutil.ReadReferences(it, options.PageSize)
+	refs, refCursors, err := storeutil.ReadReferencesWithCursors(it, options.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -166,23 +169,37 @@ func (s *Store) queryActivitiesByRef(refType spi.ReferenceType, query *spi.Crite
 		return nil, fmt.Errorf("failed to get total items from reference iterator: %w", err)
 	}
 
-	if len(refs) == 0 {
-		return NewActivityIterator(nil, totalItems), nil
-	}
+	var activities []*vocab.ActivityType
+
+	var cursors []string
 
-	ait := s.activityStore.query(
-		spi.NewCriteria(spi.WithActivityIRIs(refs...)),
-		spi.WithSortOrder(options.SortOrder))
+	// The activities are returned in the same order as the references so that the cursor of each
+	// activity is the cursor of its reference.
+	for i, ref := range refs {
+		a, err := s.activityStore.get(ref.String())
+		if err != nil {
+			logger.Debugf("[%s] Activity [%s] referenced by %s not found", s.serviceName, ref, refType)
+
+			continue
+		}
+
+		activities = append(activities, a)
+		cursors = append(cursors, refCursors[i])
+	}
 
 	// Set 'totalItems' to the 'totalItems' returned in the original reference query, which may be based on paging.
-	ait.totalItems = totalItems
+	return NewActivityIterator(activities, totalItems, WithCursors(cursors)), nil
+}
 
-	return ait, nil
+type activityEntry struct {
+	seq      uint64
+	activity *vocab.ActivityType
 }
 
 type activityStore struct {
 	mutex        sync.RWMutex
-	activities   []*vocab.ActivityType
+	seq          uint64
+	activities   []*activityEntry
 	activityByID map[string]*vocab.ActivityType
 }
 
@@ -196,7 +213,9 @@ func (s *activityStore) add(activity *vocab.ActivityType) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.activities = append(s.activities, activity)
+	s.seq++
+
+	s.activities = append(s.activities, &activityEntry{seq: s.seq, activity: activity})
 	s.activityByID[activity.ID().String()] = activity
 
 	return nil
@@ -214,21 +233,32 @@ func (s *activityStore) get(activityID string) (*vocab.ActivityType, error) {
 	return a, nil
 }
 
-func (s *activityStore) query(query *spi.Criteria, opts ...spi.QueryOpt) *ActivityIterator {
+func (s *activityStore) query(query *spi.Criteria, opts ...spi.QueryOpt) (*ActivityIterator, error) {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	return NewActivityIterator(activityQueryResults(s.activities).filter(query, opts...))
+	results, cursors, totalItems, err := activityQueryResults(s.activities).filter(query, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewActivityIterator(results, totalItems, WithCursors(cursors)), nil
+}
+
+type refEntry struct {
+	seq uint64
+	iri *url.URL
 }
 
 type referenceStore struct {
-	irisByObject map[string][]*url.URL
+	seq          uint64
+	irisByObject map[string][]*refEntry
 	mutex        sync.RWMutex
 }
 
 func newReferenceStore() *referenceStore {
 	return &referenceStore{
-		irisByObject: make(map[string][]*url.URL),
+		irisByObject: make(map[string][]*refEntry),
 	}
 }
 
@@ -238,7 +268,9 @@ func (s *referenceStore) add(actor fmt.Stringer, iri *url.URL) error {
 
 	actorID := actor.String()
 
-	s.irisByObject[actorID] = append(s.irisByObject[actorID], iri)
+	s.seq++
+
+	s.irisByObject[actorID] = append(s.irisByObject[actorID], &refEntry{seq: s.seq, iri: iri})
 
 	return nil
 }
@@ -250,7 +282,7 @@ func (s *referenceStore) delete(actor, iri fmt.Stringer) error {
 	irisForActor := s.irisByObject[actor.String()]
 
 	for actorIRI, i := range irisForActor {
-		if i.String() == iri.String() {
+		if i.iri.String() == iri.String() {
 			s.irisByObject[actor.String()] = append(irisForActor[0:actorIRI], irisForActor[actorIRI+1:]...)
 
 			return nil
@@ -268,7 +300,13 @@ func (s *referenceStore) query(query *spi.Criteria, opts ...spi.QueryOpt) (spi.R
 		return nil, fmt.Errorf("object IRI is required")
 	}
 
-	return NewReferenceIterator(refQueryResults(s.irisByObject[query.ObjectIRI.String()]).filter(query, opts...)), nil
+	results, cursors, totalItems, err := refQueryResults(s.irisByObject[query.ObjectIRI.String()]).
+		filter(query, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReferenceIterator(results, totalItems, WithCursors(cursors)), nil
 }
 
 type activityQueryFilter struct {
@@ -281,12 +319,12 @@ func newQueryFilter(query *spi.Criteria) *activityQueryFilter {
 	}
 }
 
-func (q *activityQueryFilter) apply(activities []*vocab.ActivityType) []*vocab.ActivityType {
-	var results []*vocab.ActivityType
+func (q *activityQueryFilter) apply(activities []*activityEntry) []*activityEntry {
+	var results []*activityEntry
 
 	if len(q.ActivityIRIs) > 0 {
 		for _, a := range activities {
-			if containsIRI(q.ActivityIRIs, a.ID().URL()) {
+			if containsIRI(q.ActivityIRIs, a.activity.ID().URL()) {
 				results = append(results, a)
 			}
 		}
@@ -295,7 +333,7 @@ func (q *activityQueryFilter) apply(activities []*vocab.ActivityType) []*vocab.A
 	}
 
 	for _, a := range activities {
-		if len(q.Types) == 0 || a.Type().IsAny(q.Types...) {
+		if len(q.Types) == 0 || a.activity.Type().IsAny(q.Types...) {
 			results = append(results, a)
 		}
 	}
@@ -303,9 +341,10 @@ func (q *activityQueryFilter) apply(activities []*vocab.ActivityType) []*vocab.A
 	return results
 }
 
-type activityQueryResults []*vocab.ActivityType
+type activityQueryResults []*activityEntry
 
-func (r activityQueryResults) filter(query *spi.Criteria, opts ...spi.QueryOpt) ([]*vocab.ActivityType, int) {
+func (r activityQueryResults) filter(query *spi.Criteria,
+	opts ...spi.QueryOpt) ([]*vocab.ActivityType, []string, int, error) {
 	results := newQueryFilter(query).apply(r)
 
 	options := storeutil.GetQueryOptions(opts...)
@@ -314,17 +353,29 @@ func (r activityQueryResults) filter(query *spi.Criteria, opts ...spi.QueryOpt)
 		reverseSort(results)
 	}
 
-	startIdx := getStartIndex(len(results), options)
+	startIdx, err := getStartIndex(len(results), options, func(i int) uint64 { return results[i].seq })
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	if startIdx == -1 {
-		return nil, len(results)
+		return nil, nil, len(results), nil
+	}
+
+	activities := make([]*vocab.ActivityType, 0, len(results)-startIdx)
+	cursors := make([]string, 0, len(results)-startIdx)
+
+	for _, entry := range results[startIdx:] {
+		activities = append(activities, entry.activity)
+		cursors = append(cursors, newCursor(entry.seq))
 	}
 
-	return results[startIdx:], len(results)
+	return activities, cursors, len(results), nil
 }
 
-type refQueryResults []*url.URL
+type refQueryResults []*refEntry
 
-func (r refQueryResults) filter(query *spi.Criteria, opts ...spi.QueryOpt) ([]*url.URL, int) {
+func (r refQueryResults) filter(query *spi.Criteria, opts ...spi.QueryOpt) ([]*url.URL, []string, int, error) {
 	results := newRefQueryFilter(query).apply(r)
 
 	options := storeutil.GetQueryOptions(opts...)
@@ -333,12 +384,24 @@ func (r refQueryResults) filter(query *spi.Criteria, opts ...spi.QueryOpt) ([]*u
 		reverseSort(results)
 	}
 
-	startIdx := getStartIndex(len(results), options)
+	startIdx, err := getStartIndex(len(results), options, func(i int) uint64 { return results[i].seq })
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	if startIdx == -1 {
-		return nil, len(results)
+		return nil, nil, len(results), nil
+	}
+
+	refs := make([]*url.URL, 0, len(results)-startIdx)
+	cursors := make([]string, 0, len(results)-startIdx)
+
+	for _, entry := range results[startIdx:] {
+		refs = append(refs, entry.iri)
+		cursors = append(cursors, newCursor(entry.seq))
 	}
 
-	return results[startIdx:], len(results)
+	return refs, cursors, len(results), nil
 }
 
 type refQueryFilter struct {
@@ -351,11 +414,11 @@ func newRefQueryFilter(query *spi.Criteria) *refQueryFilter {
 	}
 }
 
-func (f *refQueryFilter) apply(refs []*url.URL) []*url.URL {
-	var results []*url.URL
+func (f *refQueryFilter) apply(refs []*refEntry) []*refEntry {
+	var results []*refEntry
 
 	for _, ref := range refs {
-		if f.ReferenceIRI == nil || ref.String() == f.ReferenceIRI.String() {
+		if f.ReferenceIRI == nil || ref.iri.String() == f.ReferenceIRI.String() {
 			results = append(results, ref)
 		}
 	}
@@ -371,17 +434,40 @@ func getFirstPageNum(totalItems, pageSize int) int {
 	return totalItems/pageSize - 1
 }
 
-func getStartIndex(totalItems int, options *spi.QueryOptions) int {
+func getStartIndex(totalItems int, options *spi.QueryOptions, seqAt func(i int) uint64) (int, error) {
+	if options.Cursor != "" {
+		return getCursorStartIndex(totalItems, options, seqAt)
+	}
+
 	if options.PageSize <= 0 {
-		return 0
+		return 0, nil
 	}
 
 	startIdx := startIndex(totalItems, options)
 	if startIdx < 0 || startIdx >= totalItems {
-		return -1
+		return -1, nil
+	}
+
+	return startIdx, nil
+}
+
+// getCursorStartIndex returns the index of the first item that follows the item at the given cursor
+// (according to the sort order) or -1 if there are no more items. The items are sorted by sequence number
+// so the item at the cursor doesn't need to exist anymore.
+func getCursorStartIndex(totalItems int, options *spi.QueryOptions, seqAt func(i int) uint64) (int, error) {
+	seq, err := parseCursor(options.Cursor)
+	if err != nil {
+		return -1, err
+	}
+
+	for i := 0; i < totalItems; i++ {
+		if options.SortOrder == spi.SortAscending && seqAt(i) > seq ||
+			options.SortOrder == spi.SortDescending && seqAt(i) < seq {
+			return i, nil
+		}
 	}
 
-	return startIdx
+	return -1, nil
 }
 
 func startIndex(totalItems int, options *spi.QueryOptions) int {
@@ -396,6 +482,19 @@ func startIndex(totalItems int, options *spi.QueryOptions) int {
 	return (getFirstPageNum(totalItems, options.PageSize) - options.PageNumber) * options.PageSize
 }
 
+func newCursor(seq uint64) string {
+	return strconv.FormatUint(seq, 10)
+}
+
+func parseCursor(cursor string) (uint64, error) {
+	seq, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, orberrors.NewBadRequest(fmt.Errorf("invalid cursor [%s]: %w", cursor, err))
+	}
+
+	return seq, nil
+}
+
 func reverseSort(results interface{}) {
 	sort.SliceStable(results, func(i, j int) bool { return i > j }) //nolint:gocritic
 }
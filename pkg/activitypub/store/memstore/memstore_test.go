@@ -15,7 +15,9 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
 	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 )
 
@@ -160,6 +162,128 @@ func TestStore_ReferenceError(t *testing.T) {
 	})
 }
 
+func TestStore_ReferenceCursor(t *testing.T) {
+	s := New("service1")
+	require.NotNil(t, s)
+
+	actor1 := testutil.MustParseURL("https://actor1")
+
+	refs := testutil.NewMockURLs(5, func(i int) string { return fmt.Sprintf("https://ref_%d", i) })
+
+	for _, ref := range refs {
+		require.NoError(t, s.AddReference(spi.Inbox, actor1, ref))
+	}
+
+	it, err := s.QueryReferences(spi.Inbox, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+		spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(2))
+	require.NoError(t, err)
+
+	page, cursors, err := storeutil.ReadReferencesWithCursors(it, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*url.URL{refs[4], refs[3]}, page)
+
+	// Add a reference while paging. It shouldn't affect the next page.
+	require.NoError(t, s.AddReference(spi.Inbox, actor1, testutil.MustParseURL("https://ref_new")))
+
+	it, err = s.QueryReferences(spi.Inbox, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+		spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(2), spi.WithCursor(cursors[1]))
+	require.NoError(t, err)
+
+	page, cursors, err = storeutil.ReadReferencesWithCursors(it, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*url.URL{refs[2], refs[1]}, page)
+
+	// Delete the reference at the cursor. The next page should still start at the following reference.
+	require.NoError(t, s.DeleteReference(spi.Inbox, actor1, refs[1]))
+
+	it, err = s.QueryReferences(spi.Inbox, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+		spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(2), spi.WithCursor(cursors[1]))
+	require.NoError(t, err)
+
+	page, _, err = storeutil.ReadReferencesWithCursors(it, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*url.URL{refs[0]}, page)
+
+	totalItems, err := it.TotalItems()
+	require.NoError(t, err)
+	require.Equal(t, 5, totalItems)
+
+	// Page in ascending order.
+	it, err = s.QueryReferences(spi.Inbox, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+		spi.WithSortOrder(spi.SortAscending), spi.WithPageSize(2), spi.WithCursor(cursors[0]))
+	require.NoError(t, err)
+
+	page, _, err = storeutil.ReadReferencesWithCursors(it, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*url.URL{refs[3], refs[4]}, page)
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		_, err := s.QueryReferences(spi.Inbox, spi.NewCriteria(spi.WithObjectIRI(actor1)),
+			spi.WithCursor("invalid"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsBadRequest(err))
+
+		_, err = s.QueryActivities(spi.NewCriteria(), spi.WithCursor("invalid"))
+		require.Error(t, err)
+		require.True(t, orberrors.IsBadRequest(err))
+	})
+}
+
+func TestStore_ActivityCursor(t *testing.T) {
+	s := New("service1")
+	require.NotNil(t, s)
+
+	serviceIRI := testutil.MustParseURL("https://example.com/services/service1")
+
+	activities := newMockActivities(vocab.TypeCreate, 5)
+
+	for _, a := range activities {
+		require.NoError(t, s.AddActivity(a))
+		require.NoError(t, s.AddReference(spi.Outbox, serviceIRI, a.ID().URL()))
+	}
+
+	it, err := s.QueryActivities(spi.NewCriteria(
+		spi.WithReferenceType(spi.Outbox), spi.WithObjectIRI(serviceIRI)),
+		spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(3))
+	require.NoError(t, err)
+
+	page, err := storeutil.ReadActivities(it, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	require.Equal(t, activities[2].ID().String(), page[2].ID().String())
+
+	cursor := it.Cursor()
+	require.NotEmpty(t, cursor)
+
+	it, err = s.QueryActivities(spi.NewCriteria(
+		spi.WithReferenceType(spi.Outbox), spi.WithObjectIRI(serviceIRI)),
+		spi.WithSortOrder(spi.SortDescending), spi.WithPageSize(3), spi.WithCursor(cursor))
+	require.NoError(t, err)
+
+	page, err = storeutil.ReadActivities(it, 3)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Equal(t, activities[1].ID().String(), page[0].ID().String())
+	require.Equal(t, activities[0].ID().String(), page[1].ID().String())
+
+	// Query all activities.
+	it, err = s.QueryActivities(spi.NewCriteria(), spi.WithPageSize(2))
+	require.NoError(t, err)
+
+	page, cursors, err := storeutil.ReadActivitiesWithCursors(it, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	require.Len(t, cursors, 2)
+
+	it, err = s.QueryActivities(spi.NewCriteria(), spi.WithCursor(cursors[1]))
+	require.NoError(t, err)
+
+	page, err = storeutil.ReadActivities(it, -1)
+	require.NoError(t, err)
+	require.Len(t, page, 3)
+	require.Equal(t, activities[2].ID().String(), page[0].ID().String())
+}
+
 func TestStore_Actors(t *testing.T) {
 	s := New("service1")
 	require.NotNil(t, s)
@@ -226,136 +350,172 @@ func TestActivityQueryResults(t *testing.T) {
 	createActivities := newMockActivities(vocab.TypeCreate, 7)
 	announceActivities := newMockActivities(vocab.TypeAnnounce, 3)
 
-	results := activityQueryResults(append(createActivities, announceActivities...))
+	results := activityQueryResults(newActivityEntries(append(createActivities, announceActivities...)))
 
 	// No paging
-	filtered, totalItems := results.filter(spi.NewCriteria())
+	filtered, _, totalItems, err := results.filter(spi.NewCriteria())
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
-	require.True(t, filtered[0] == results[0])
-	require.True(t, filtered[9] == results[9])
+	require.True(t, filtered[0] == results[0].activity)
+	require.True(t, filtered[9] == results[9].activity)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(1),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 6)
-	require.True(t, filtered[0] == results[4])
-	require.True(t, filtered[5] == results[9])
+	require.True(t, filtered[0] == results[4].activity)
+	require.True(t, filtered[5] == results[9].activity)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(2),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 2)
-	require.True(t, filtered[0] == results[8])
-	require.True(t, filtered[1] == results[9])
+	require.True(t, filtered[0] == results[8].activity)
+	require.True(t, filtered[1] == results[9].activity)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(3),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Empty(t, filtered)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(1),
 		spi.WithSortOrder(spi.SortDescending),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 6)
-	require.True(t, filtered[0] == results[5])
-	require.True(t, filtered[5] == results[0])
+	require.True(t, filtered[0] == results[5].activity)
+	require.True(t, filtered[5] == results[0].activity)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(spi.WithType(vocab.TypeAnnounce)),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(spi.WithType(vocab.TypeAnnounce)),
 		spi.WithPageSize(3),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 3, totalItems)
 	require.Len(t, filtered, 3)
-	require.True(t, filtered[0] == results[7])
-	require.True(t, filtered[1] == results[8])
-	require.True(t, filtered[2] == results[9])
+	require.True(t, filtered[0] == results[7].activity)
+	require.True(t, filtered[1] == results[8].activity)
+	require.True(t, filtered[2] == results[9].activity)
 }
 
 func TestReferenceQueryResults(t *testing.T) {
-	results := refQueryResults(testutil.NewMockURLs(10, func(i int) string {
+	results := refQueryResults(newRefEntries(testutil.NewMockURLs(10, func(i int) string {
 		return fmt.Sprintf("https://ref_%d", i)
-	}))
+	})))
 
 	// No paging
-	filtered, totalItems := results.filter(spi.NewCriteria())
+	filtered, _, totalItems, err := results.filter(spi.NewCriteria())
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
-	require.True(t, filtered[0] == results[0])
-	require.True(t, filtered[9] == results[9])
+	require.True(t, filtered[0] == results[0].iri)
+	require.True(t, filtered[9] == results[9].iri)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(2),
 		spi.WithPageNum(4),
 		spi.WithSortOrder(spi.SortDescending),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
-	require.Equal(t, results[9].String(), filtered[0].String())
-	require.Equal(t, results[0].String(), filtered[9].String())
+	require.Equal(t, results[9].iri.String(), filtered[0].String())
+	require.Equal(t, results[0].iri.String(), filtered[9].String())
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(1),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 6)
-	require.True(t, filtered[0] == results[4])
-	require.True(t, filtered[5] == results[9])
+	require.True(t, filtered[0] == results[4].iri)
+	require.True(t, filtered[5] == results[9].iri)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(2),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 2)
-	require.True(t, filtered[0] == results[8])
-	require.True(t, filtered[1] == results[9])
+	require.True(t, filtered[0] == results[8].iri)
+	require.True(t, filtered[1] == results[9].iri)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(3),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Empty(t, filtered)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(),
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(),
 		spi.WithPageSize(4),
 		spi.WithPageNum(1),
 		spi.WithSortOrder(spi.SortDescending),
 	)
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 6)
-	require.True(t, filtered[0] == results[5])
-	require.True(t, filtered[5] == results[0])
+	require.True(t, filtered[0] == results[5].iri)
+	require.True(t, filtered[5] == results[0].iri)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(), spi.WithPageSize(20))
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(), spi.WithPageSize(20))
+	require.NoError(t, err)
 	require.Equal(t, 10, totalItems)
 	require.Len(t, filtered, 10)
 
-	filtered, totalItems = results.filter(spi.NewCriteria(spi.WithReferenceIRI(results[7])))
+	filtered, _, totalItems, err = results.filter(spi.NewCriteria(spi.WithReferenceIRI(results[7].iri)))
+	require.NoError(t, err)
 	require.Equal(t, 1, totalItems)
-	require.True(t, filtered[0] == results[7])
+	require.True(t, filtered[0] == results[7].iri)
+}
+
+func newActivityEntries(activities []*vocab.ActivityType) []*activityEntry {
+	entries := make([]*activityEntry, len(activities))
+
+	for i, a := range activities {
+		entries[i] = &activityEntry{seq: uint64(i + 1), activity: a}
+	}
+
+	return entries
+}
+
+func newRefEntries(iris []*url.URL) []*refEntry {
+	entries := make([]*refEntry, len(iris))
+
+	for i, iri := range iris {
+		entries[i] = &refEntry{seq: uint64(i + 1), iri: iri}
+	}
+
+	return entries
 }
 
 func newMockActivities(t vocab.Type, num int) []*vocab.ActivityType {
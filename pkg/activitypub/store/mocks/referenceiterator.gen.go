@@ -19,6 +19,16 @@ type ReferenceIterator struct {
 	closeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	CursorStub        func() string
+	cursorMutex       sync.RWMutex
+	cursorArgsForCall []struct {
+	}
+	cursorReturns struct {
+		result1 string
+	}
+	cursorReturnsOnCall map[int]struct {
+		result1 string
+	}
 	NextStub        func() (*url.URL, error)
 	nextMutex       sync.RWMutex
 	nextArgsForCall []struct {
@@ -100,6 +110,59 @@ func (fake *ReferenceIterator) CloseReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *ReferenceIterator) Cursor() string {
+	fake.cursorMutex.Lock()
+	ret, specificReturn := fake.cursorReturnsOnCall[len(fake.cursorArgsForCall)]
+	fake.cursorArgsForCall = append(fake.cursorArgsForCall, struct {
+	}{})
+	stub := fake.CursorStub
+	fakeReturns := fake.cursorReturns
+	fake.recordInvocation("Cursor", []interface{}{})
+	fake.cursorMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *ReferenceIterator) CursorCallCount() int {
+	fake.cursorMutex.RLock()
+	defer fake.cursorMutex.RUnlock()
+	return len(fake.cursorArgsForCall)
+}
+
+func (fake *ReferenceIterator) CursorCalls(stub func() string) {
+	fake.cursorMutex.Lock()
+	defer fake.cursorMutex.Unlock()
+	fake.CursorStub = stub
+}
+
+func (fake *ReferenceIterator) CursorReturns(result1 string) {
+	fake.cursorMutex.Lock()
+	defer fake.cursorMutex.Unlock()
+	fake.CursorStub = nil
+	fake.cursorReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *ReferenceIterator) CursorReturnsOnCall(i int, result1 string) {
+	fake.cursorMutex.Lock()
+	defer fake.cursorMutex.Unlock()
+	fake.CursorStub = nil
+	if fake.cursorReturnsOnCall == nil {
+		fake.cursorReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.cursorReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
 func (fake *ReferenceIterator) Next() (*url.URL, error) {
 	fake.nextMutex.Lock()
 	ret, specificReturn := fake.nextReturnsOnCall[len(fake.nextArgsForCall)]
@@ -217,6 +280,8 @@ func (fake *ReferenceIterator) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
+	fake.cursorMutex.RLock()
+	defer fake.cursorMutex.RUnlock()
 	fake.nextMutex.RLock()
 	defer fake.nextMutex.RUnlock()
 	fake.totalItemsMutex.RLock()
@@ -48,6 +48,9 @@ const (
 	Share ReferenceType = "SHARE"
 	// AnchorCredential indicates that the reference is an anchor credential.
 	AnchorCredential ReferenceType = "ANCHOR_CRED"
+	// Blocked indicates that the reference is an actor (or a domain) that has been blocked by the local
+	// service. Activities from blocked actors are rejected by the inbox.
+	Blocked ReferenceType = "BLOCKED"
 )
 
 // Store defines the functions of an ActivityPub store.
@@ -87,6 +90,7 @@ type QueryOptions struct {
 	PageNumber int
 	PageSize   int
 	SortOrder  SortOrder
+	Cursor     string
 }
 
 // QueryOpt sets a query option.
@@ -113,6 +117,17 @@ func WithSortOrder(sortOrder SortOrder) QueryOpt {
 	}
 }
 
+// WithCursor sets the cursor from which to start returning results. The cursor is an opaque value that was
+// returned from a previous query (see ReferenceIterator.Cursor and ActivityIterator.Cursor). Only the items
+// that follow the item at the cursor (according to the sort order) are returned. Since the cursor is based
+// on a stable sort key, the results are not affected by items that are added or removed while paging.
+// If a cursor is specified then the page number is ignored.
+func WithCursor(cursor string) QueryOpt {
+	return func(options *QueryOptions) {
+		options.Cursor = cursor
+	}
+}
+
 // Criteria holds the search criteria for a query.
 type Criteria struct {
 	Types         []vocab.Type
@@ -177,6 +192,9 @@ type ActivityIterator interface {
 	TotalItems() (int, error)
 	// Next returns the next activity or an ErrNotFound error if there are no more items.
 	Next() (*vocab.ActivityType, error)
+	// Cursor returns the cursor of the activity that was most recently returned by Next. The cursor
+	// may be used in a subsequent query (see WithCursor) in order to resume from that activity.
+	Cursor() string
 	// Close closes the iterator.
 	Close() error
 }
@@ -187,6 +205,9 @@ type ReferenceIterator interface {
 	TotalItems() (int, error)
 	// Next returns the next reference or an ErrNotFound error if there are no more items.
 	Next() (*url.URL, error)
+	// Cursor returns the cursor of the reference that was most recently returned by Next. The cursor
+	// may be used in a subsequent query (see WithCursor) in order to resume from that reference.
+	Cursor() string
 	// Close closes the iterator.
 	Close() error
 }
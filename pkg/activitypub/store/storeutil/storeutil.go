@@ -50,6 +50,31 @@ func ReadReferences(it store.ReferenceIterator, maxItems int) ([]*url.URL, error
 	return refs, nil
 }
 
+// ReadReferencesWithCursors returns all of the references resulting from iterating over the given iterator
+// along with the cursor of each reference, up to the given maximum number of references. If maxItems is <=0
+// then all items are read.
+func ReadReferencesWithCursors(it store.ReferenceIterator, maxItems int) ([]*url.URL, []string, error) {
+	var refs []*url.URL
+
+	var cursors []string
+
+	for i := 0; maxItems <= 0 || i < maxItems; i++ {
+		ref, err := it.Next()
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				break
+			}
+
+			return nil, nil, orberrors.NewTransient(err)
+		}
+
+		refs = append(refs, ref)
+		cursors = append(cursors, it.Cursor())
+	}
+
+	return refs, cursors, nil
+}
+
 // ReadActivities returns all of the activities resulting from iterating over the given iterator,
 // up to the given maximum number of activities. If maxItems is <=0 then all items are read.
 func ReadActivities(it store.ActivityIterator, maxItems int) ([]*vocab.ActivityType, error) {
@@ -70,3 +95,28 @@ func ReadActivities(it store.ActivityIterator, maxItems int) ([]*vocab.ActivityT
 
 	return activities, nil
 }
+
+// ReadActivitiesWithCursors returns all of the activities resulting from iterating over the given iterator
+// along with the cursor of each activity, up to the given maximum number of activities. If maxItems is <=0
+// then all items are read.
+func ReadActivitiesWithCursors(it store.ActivityIterator, maxItems int) ([]*vocab.ActivityType, []string, error) {
+	var activities []*vocab.ActivityType
+
+	var cursors []string
+
+	for i := 0; maxItems <= 0 || i < maxItems; i++ {
+		activity, err := it.Next()
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				break
+			}
+
+			return nil, nil, err
+		}
+
+		activities = append(activities, activity)
+		cursors = append(cursors, it.Cursor())
+	}
+
+	return activities, cursors, nil
+}
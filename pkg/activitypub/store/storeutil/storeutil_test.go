@@ -83,3 +83,41 @@ func TestReadReferences(t *testing.T) {
 		require.Empty(t, refs)
 	})
 }
+
+func TestReadReferencesWithCursors(t *testing.T) {
+	url1, err := url.Parse("https://url1")
+	require.NoError(t, err)
+
+	url2, err := url.Parse("https://url2")
+	require.NoError(t, err)
+
+	t.Run("All items", func(t *testing.T) {
+		it := &mocks.ReferenceIterator{}
+
+		it.NextReturnsOnCall(0, url1, nil)
+		it.NextReturnsOnCall(1, url2, nil)
+		it.NextReturnsOnCall(2, nil, spi.ErrNotFound)
+		it.CursorReturnsOnCall(0, "1")
+		it.CursorReturnsOnCall(1, "2")
+
+		refs, cursors, err := ReadReferencesWithCursors(it, -1)
+		require.NoError(t, err)
+		require.Len(t, refs, 2)
+		require.Equal(t, url1.String(), refs[0].String())
+		require.Equal(t, url2.String(), refs[1].String())
+		require.Equal(t, []string{"1", "2"}, cursors)
+	})
+
+	t.Run("Iterator error", func(t *testing.T) {
+		errExpected := fmt.Errorf("injected iterator error")
+
+		it := &mocks.ReferenceIterator{}
+
+		it.NextReturns(nil, errExpected)
+
+		refs, cursors, err := ReadReferencesWithCursors(it, 1)
+		require.EqualError(t, err, errExpected.Error())
+		require.Empty(t, refs)
+		require.Empty(t, cursors)
+	})
+}
@@ -249,3 +249,59 @@ func NewUndoActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
 		},
 	}
 }
+
+// NewUpdateActivity returns a new 'Update' activity.
+func NewUpdateActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeUpdate),
+			WithTo(options.To...),
+			WithPublishedTime(options.Published),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
+
+// NewDeleteActivity returns a new 'Delete' activity.
+func NewDeleteActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeDelete),
+			WithTo(options.To...),
+			WithPublishedTime(options.Published),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
+
+// NewBlockActivity returns a new 'Block' activity.
+func NewBlockActivity(obj *ObjectProperty, opts ...Opt) *ActivityType {
+	options := NewOptions(opts...)
+
+	return &ActivityType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams)...),
+			WithID(options.ID),
+			WithType(TypeBlock),
+			WithTo(options.To...),
+		),
+		activity: &activityType{
+			Actor:  NewURLProperty(options.Actor),
+			Object: obj,
+		},
+	}
+}
@@ -32,6 +32,9 @@ var (
 	offerActivityID   = newMockID(service1, "/activities/65b3d005-6bb6-673d-6879-18bc1ee84976")
 	undoActivityID    = newMockID(service1, "/activities/77bcd005-abb6-433d-a889-18bc1ce64981")
 	likeActivityID    = newMockID(witness1, "/likes/87bcd005-abb6-433d-a889-18bc1ce84988")
+	updateActivityID  = newMockID(service1, "/activities/18bcd005-abb6-433d-a889-18bc1ce64982")
+	deleteActivityID  = newMockID(service1, "/activities/28bcd005-abb6-433d-a889-18bc1ce64983")
+	blockActivityID   = newMockID(service1, "/activities/38bcd005-abb6-433d-a889-18bc1ce64984")
 )
 
 func TestCreateTypeMarshal(t *testing.T) {
@@ -1049,6 +1052,99 @@ func TestUndoTypeMarshal(t *testing.T) {
 	})
 }
 
+func TestUpdateTypeMarshal(t *testing.T) {
+	org1Service := testutil.MustParseURL("https://org1.com/services/service1")
+	followers := testutil.MustParseURL("https://org1.com/services/service1/followers")
+	keyID := testutil.MustParseURL("https://org1.com/services/service1/keys/main-key")
+
+	service := NewService(org1Service,
+		WithPublicKey(NewPublicKey(WithID(keyID), WithOwner(org1Service), WithPublicKeyPem("pem"))),
+		WithInbox(testutil.MustParseURL("https://org1.com/services/service1/inbox")),
+	)
+
+	update := NewUpdateActivity(
+		NewObjectProperty(WithActorObject(service)),
+		WithID(updateActivityID),
+		WithActor(org1Service),
+		WithTo(followers),
+	)
+
+	bytes, err := json.Marshal(update)
+	require.NoError(t, err)
+	t.Log(string(bytes))
+
+	a := &ActivityType{}
+	require.NoError(t, json.Unmarshal(bytes, a))
+	require.True(t, a.Type().Is(TypeUpdate))
+	require.Equal(t, updateActivityID.String(), a.ID().String())
+	require.Equal(t, org1Service.String(), a.Actor().String())
+
+	actor := a.Object().Actor()
+	require.NotNil(t, actor)
+	require.True(t, a.Object().Type().Is(TypeService))
+	require.Equal(t, org1Service.String(), actor.ID().String())
+	require.NotNil(t, actor.PublicKey())
+	require.Equal(t, keyID.String(), actor.PublicKey().ID.String())
+	require.Equal(t, "pem", actor.PublicKey().PublicKeyPem)
+}
+
+func TestDeleteTypeMarshal(t *testing.T) {
+	org1Service := testutil.MustParseURL("https://org1.com/services/service1")
+	followers := testutil.MustParseURL("https://org1.com/services/service1/followers")
+
+	del := NewDeleteActivity(
+		NewObjectProperty(WithIRI(org1Service)),
+		WithID(deleteActivityID),
+		WithActor(org1Service),
+		WithTo(followers),
+	)
+
+	bytes, err := json.Marshal(del)
+	require.NoError(t, err)
+	t.Log(string(bytes))
+
+	a := &ActivityType{}
+	require.NoError(t, json.Unmarshal(bytes, a))
+	require.True(t, a.Type().Is(TypeDelete))
+	require.Equal(t, deleteActivityID.String(), a.ID().String())
+	require.Equal(t, org1Service.String(), a.Actor().String())
+	require.Equal(t, org1Service.String(), a.Object().IRI().String())
+	require.Len(t, a.To(), 1)
+	require.Equal(t, followers.String(), a.To()[0].String())
+}
+
+func TestBlockTypeMarshal(t *testing.T) {
+	org1Service := testutil.MustParseURL("https://org1.com/services/service1")
+	org2Domain := testutil.MustParseURL("https://org2.com")
+
+	block := NewBlockActivity(
+		NewObjectProperty(WithIRI(org2Domain)),
+		WithID(blockActivityID),
+		WithActor(org1Service),
+	)
+
+	undo := NewUndoActivity(
+		NewObjectProperty(WithActivity(block)),
+		WithID(undoActivityID),
+		WithActor(org1Service),
+	)
+
+	bytes, err := json.Marshal(undo)
+	require.NoError(t, err)
+	t.Log(string(bytes))
+
+	a := &ActivityType{}
+	require.NoError(t, json.Unmarshal(bytes, a))
+	require.True(t, a.Type().Is(TypeUndo))
+
+	b := a.Object().Activity()
+	require.NotNil(t, b)
+	require.True(t, b.Type().Is(TypeBlock))
+	require.Equal(t, blockActivityID.String(), b.ID().String())
+	require.Equal(t, org1Service.String(), b.Actor().String())
+	require.Equal(t, org2Domain.String(), b.Object().IRI().String())
+}
+
 func TestActivityType_Accessors(t *testing.T) {
 	a := &ActivityType{}
 
@@ -7,7 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package vocab
 
 import (
+	"encoding/json"
 	"net/url"
+	"time"
 )
 
 // PublicKeyType defines a public key object.
@@ -15,6 +17,8 @@ type PublicKeyType struct {
 	ID           *URLProperty `json:"id"`
 	Owner        *URLProperty `json:"owner"`
 	PublicKeyPem string       `json:"publicKeyPem"`
+	Created      *time.Time   `json:"created,omitempty"`
+	Expires      *time.Time   `json:"expires,omitempty"`
 }
 
 // NewPublicKey returns a new public key object.
@@ -25,9 +29,55 @@ func NewPublicKey(opts ...Opt) *PublicKeyType {
 		ID:           NewURLProperty(options.ID),
 		Owner:        NewURLProperty(options.Owner),
 		PublicKeyPem: options.PublicKeyPem,
+		Created:      options.Created,
+		Expires:      options.Expires,
 	}
 }
 
+// IsValid returns true if the key is valid at the given time, i.e. the given time is not before
+// the key's creation time and not at or after the key's expiry time.
+func (t *PublicKeyType) IsValid(at time.Time) bool {
+	if t.Created != nil && at.Before(*t.Created) {
+		return false
+	}
+
+	return t.Expires == nil || at.Before(*t.Expires)
+}
+
+// publicKeysProperty holds the public keys of an actor. A single key is marshalled as an object (which is
+// the format understood by all actors) and multiple keys are marshalled as an array.
+type publicKeysProperty []*PublicKeyType
+
+// MarshalJSON marshals the public keys.
+func (p publicKeysProperty) MarshalJSON() ([]byte, error) {
+	if len(p) == 1 {
+		return json.Marshal(p[0])
+	}
+
+	return json.Marshal([]*PublicKeyType(p))
+}
+
+// UnmarshalJSON unmarshals either a single public key or an array of public keys.
+func (p *publicKeysProperty) UnmarshalJSON(bytes []byte) error {
+	var keys []*PublicKeyType
+
+	if err := json.Unmarshal(bytes, &keys); err == nil {
+		*p = keys
+
+		return nil
+	}
+
+	key := &PublicKeyType{}
+
+	if err := json.Unmarshal(bytes, key); err != nil {
+		return err
+	}
+
+	*p = publicKeysProperty{key}
+
+	return nil
+}
+
 // ActorType defines an 'actor'.
 type ActorType struct {
 	*ObjectType
@@ -36,20 +86,29 @@ type ActorType struct {
 }
 
 type actorType struct {
-	PublicKey  *PublicKeyType `json:"publicKey"`
-	Inbox      *URLProperty   `json:"inbox"`
-	Outbox     *URLProperty   `json:"outbox"`
-	Followers  *URLProperty   `json:"followers"`
-	Following  *URLProperty   `json:"following"`
-	Witnesses  *URLProperty   `json:"witnesses"`
-	Witnessing *URLProperty   `json:"witnessing"`
-	Liked      *URLProperty   `json:"liked"`
-	Likes      *URLProperty   `json:"likes"`
-	Shares     *URLProperty   `json:"shares"`
-}
-
-// PublicKey returns the actor's public key.
+	PublicKey  publicKeysProperty `json:"publicKey"`
+	Inbox      *URLProperty       `json:"inbox"`
+	Outbox     *URLProperty       `json:"outbox"`
+	Followers  *URLProperty       `json:"followers"`
+	Following  *URLProperty       `json:"following"`
+	Witnesses  *URLProperty       `json:"witnesses"`
+	Witnessing *URLProperty       `json:"witnessing"`
+	Liked      *URLProperty       `json:"liked"`
+	Likes      *URLProperty       `json:"likes"`
+	Shares     *URLProperty       `json:"shares"`
+}
+
+// PublicKey returns the actor's (main) public key, which is the first of the actor's public keys.
 func (t *ActorType) PublicKey() *PublicKeyType {
+	if len(t.actor.PublicKey) == 0 {
+		return nil
+	}
+
+	return t.actor.PublicKey[0]
+}
+
+// PublicKeys returns all of the actor's public keys.
+func (t *ActorType) PublicKeys() []*PublicKeyType {
 	return t.actor.PublicKey
 }
 
@@ -133,6 +192,14 @@ func (t *ActorType) UnmarshalJSON(bytes []byte) error {
 func NewService(id *url.URL, opts ...Opt) *ActorType {
 	options := NewOptions(opts...)
 
+	var publicKeys publicKeysProperty
+
+	if options.PublicKey != nil {
+		publicKeys = append(publicKeys, options.PublicKey)
+	}
+
+	publicKeys = append(publicKeys, options.PublicKeys...)
+
 	return &ActorType{
 		ObjectType: NewObject(
 			WithContext(getContexts(options, ContextActivityStreams, ContextSecurity, ContextActivityAnchors)...),
@@ -140,7 +207,7 @@ func NewService(id *url.URL, opts ...Opt) *ActorType {
 			WithType(TypeService),
 		),
 		actor: &actorType{
-			PublicKey:  options.PublicKey,
+			PublicKey:  publicKeys,
 			Inbox:      NewURLProperty(options.Inbox),
 			Outbox:     NewURLProperty(options.Outbox),
 			Followers:  NewURLProperty(options.Followers),
@@ -9,6 +9,7 @@ package vocab
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
@@ -124,6 +125,53 @@ func TestActor(t *testing.T) {
 		require.Nil(t, a.Witnessing())
 		require.Nil(t, a.Liked())
 	})
+
+	t.Run("Multiple keys", func(t *testing.T) {
+		created := time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC)
+		expires := created.Add(24 * time.Hour)
+
+		key2 := NewPublicKey(
+			WithID(testutil.NewMockID(serviceIRI, "/keys/key-2")),
+			WithOwner(serviceIRI),
+			WithPublicKeyPem(keyPem),
+			WithCreated(&created),
+		)
+
+		key1 := NewPublicKey(
+			WithID(keyID),
+			WithOwner(serviceIRI),
+			WithPublicKeyPem(keyPem),
+			WithExpires(&expires),
+		)
+
+		service := NewService(serviceIRI, WithPublicKeys(key2, key1))
+
+		bytes, err := canonicalizer.MarshalCanonical(service)
+		require.NoError(t, err)
+		t.Log(string(bytes))
+
+		require.Contains(t, string(bytes), `"publicKey":[{`)
+
+		a := &ActorType{}
+		require.NoError(t, json.Unmarshal([]byte(jsonServiceMultipleKeys), a))
+
+		keys := a.PublicKeys()
+		require.Len(t, keys, 2)
+		require.Equal(t, key2.ID.String(), a.PublicKey().ID.String())
+		require.Equal(t, created, *keys[0].Created)
+		require.Nil(t, keys[0].Expires)
+		require.Equal(t, expires, *keys[1].Expires)
+
+		require.False(t, keys[0].IsValid(created.Add(-time.Second)))
+		require.True(t, keys[0].IsValid(created))
+		require.True(t, keys[1].IsValid(expires.Add(-time.Second)))
+		require.False(t, keys[1].IsValid(expires))
+	})
+
+	t.Run("Invalid public key", func(t *testing.T) {
+		a := &ActorType{}
+		require.Error(t, json.Unmarshal([]byte(`{"publicKey":"invalid"}`), a))
+	})
 }
 
 const jsonService = `{
@@ -149,3 +197,27 @@ const jsonService = `{
   "likes": "https://alice.example.com/services/orb/likes",
   "shares": "https://alice.example.com/services/orb/shares"
 }`
+
+const jsonServiceMultipleKeys = `{
+  "@context": [
+    "https://www.w3.org/ns/activitystreams",
+    "https://w3id.org/security/v1",
+    "https://w3id.org/activityanchors/v1"
+  ],
+  "id": "https://alice.example.com/services/orb",
+  "type": "Service",
+  "publicKey": [
+    {
+      "id": "https://alice.example.com/services/orb/keys/key-2",
+      "owner": "https://alice.example.com/services/orb",
+      "publicKeyPem": "-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki.....",
+      "created": "2021-05-01T00:00:00Z"
+    },
+    {
+      "id": "https://alice.example.com/services/orb/keys/main-key",
+      "owner": "https://alice.example.com/services/orb",
+      "publicKeyPem": "-----BEGIN PUBLIC KEY-----\nMIIBIjANBgkqhki.....",
+      "expires": "2021-05-02T00:00:00Z"
+    }
+  ]
+}`
@@ -21,6 +21,8 @@ type ObjectProperty struct {
 	orderedColl   *OrderedCollectionType
 	activity      *ActivityType
 	anchorCredRef *AnchorReferenceType
+	actor         *ActorType
+	sth           *SignedTreeHeadType
 }
 
 // NewObjectProperty returns a new 'object' property with the given options.
@@ -34,6 +36,8 @@ func NewObjectProperty(opts ...Opt) *ObjectProperty {
 		orderedColl:   options.OrderedCollection,
 		activity:      options.Activity,
 		anchorCredRef: options.AnchorCredRef,
+		actor:         options.ActorObject,
+		sth:           options.SignedTreeHead,
 	}
 }
 
@@ -64,6 +68,14 @@ func (p *ObjectProperty) Type() *TypeProperty {
 		return p.anchorCredRef.Type()
 	}
 
+	if p.actor != nil {
+		return p.actor.Type()
+	}
+
+	if p.sth != nil {
+		return p.sth.Type()
+	}
+
 	return nil
 }
 
@@ -122,6 +134,24 @@ func (p *ObjectProperty) AnchorReference() *AnchorReferenceType {
 	return p.anchorCredRef
 }
 
+// Actor returns the actor or nil if the actor is not set.
+func (p *ObjectProperty) Actor() *ActorType {
+	if p == nil {
+		return nil
+	}
+
+	return p.actor
+}
+
+// SignedTreeHead returns the signed tree head or nil if the signed tree head is not set.
+func (p *ObjectProperty) SignedTreeHead() *SignedTreeHeadType {
+	if p == nil {
+		return nil
+	}
+
+	return p.sth
+}
+
 // MarshalJSON marshals the 'object' property.
 func (p *ObjectProperty) MarshalJSON() ([]byte, error) {
 	if p.iri != nil {
@@ -148,6 +178,14 @@ func (p *ObjectProperty) MarshalJSON() ([]byte, error) {
 		return json.Marshal(p.anchorCredRef)
 	}
 
+	if p.actor != nil {
+		return json.Marshal(p.actor)
+	}
+
+	if p.sth != nil {
+		return json.Marshal(p.sth)
+	}
+
 	return nil, fmt.Errorf("nil object property")
 }
 
@@ -182,12 +220,18 @@ func (p *ObjectProperty) UnmarshalJSON(bytes []byte) error {
 	case obj.object.Type.Is(TypeOrderedCollection):
 		err = p.unmarshalOrderedCollection(bytes)
 
-	case obj.object.Type.IsAny(TypeFollow, TypeAccept, TypeReject, TypeOffer, TypeLike, TypeInvite):
+	case obj.object.Type.IsAny(TypeFollow, TypeAccept, TypeReject, TypeOffer, TypeLike, TypeInvite, TypeBlock):
 		err = p.unmarshalActivity(bytes)
 
 	case obj.object.Type.Is(TypeAnchorRef):
 		err = p.unmarshalAnchorReference(bytes)
 
+	case obj.object.Type.Is(TypeService):
+		err = p.unmarshalActor(bytes)
+
+	case obj.object.Type.Is(TypeSignedTreeHead):
+		err = p.unmarshalSignedTreeHead(bytes)
+
 	default:
 		p.obj = obj
 	}
@@ -242,3 +286,27 @@ func (p *ObjectProperty) unmarshalAnchorReference(bytes []byte) error {
 
 	return nil
 }
+
+func (p *ObjectProperty) unmarshalActor(bytes []byte) error {
+	a := &ActorType{}
+
+	if err := json.Unmarshal(bytes, &a); err != nil {
+		return err
+	}
+
+	p.actor = a
+
+	return nil
+}
+
+func (p *ObjectProperty) unmarshalSignedTreeHead(bytes []byte) error {
+	sth := &SignedTreeHeadType{}
+
+	if err := json.Unmarshal(bytes, &sth); err != nil {
+		return err
+	}
+
+	p.sth = sth
+
+	return nil
+}
@@ -191,6 +191,8 @@ type ObjectPropertyOptions struct {
 	OrderedCollection *OrderedCollectionType
 	Activity          *ActivityType
 	AnchorCredRef     *AnchorReferenceType
+	ActorObject       *ActorType
+	SignedTreeHead    *SignedTreeHeadType
 }
 
 // WithIRI sets the 'object' property to an IRI.
@@ -235,6 +237,20 @@ func WithAnchorReference(ref *AnchorReferenceType) Opt {
 	}
 }
 
+// WithActorObject sets the 'object' property to an embedded actor.
+func WithActorObject(actor *ActorType) Opt {
+	return func(opts *Options) {
+		opts.ActorObject = actor
+	}
+}
+
+// WithSignedTreeHead sets the 'object' property to an embedded signed tree head.
+func WithSignedTreeHead(sth *SignedTreeHeadType) Opt {
+	return func(opts *Options) {
+		opts.SignedTreeHead = sth
+	}
+}
+
 // ActivityOptions holds the options for an Activity.
 type ActivityOptions struct {
 	Result *ObjectProperty
@@ -266,6 +282,7 @@ func WithResult(result *ObjectProperty) Opt {
 // ActorOptions holds the options for an Activity.
 type ActorOptions struct {
 	PublicKey  *PublicKeyType
+	PublicKeys []*PublicKeyType
 	Inbox      *url.URL
 	Outbox     *url.URL
 	Followers  *url.URL
@@ -284,6 +301,14 @@ func WithPublicKey(publicKey *PublicKeyType) Opt {
 	}
 }
 
+// WithPublicKeys adds the given keys to the 'publicKey' property on the actor. If multiple keys are
+// specified then the property is an array.
+func WithPublicKeys(publicKeys ...*PublicKeyType) Opt {
+	return func(opts *Options) {
+		opts.PublicKeys = publicKeys
+	}
+}
+
 // WithInbox sets the 'inbox' property on the actor.
 func WithInbox(inbox *url.URL) Opt {
 	return func(opts *Options) {
@@ -351,6 +376,8 @@ func WithShares(shares *url.URL) Opt {
 type PublicKeyOptions struct {
 	Owner        *url.URL
 	PublicKeyPem string
+	Created      *time.Time
+	Expires      *time.Time
 }
 
 // WithOwner sets the 'owner' property on the public key.
@@ -367,6 +394,20 @@ func WithPublicKeyPem(pem string) Opt {
 	}
 }
 
+// WithCreated sets the 'created' property on the public key.
+func WithCreated(t *time.Time) Opt {
+	return func(opts *Options) {
+		opts.Created = t
+	}
+}
+
+// WithExpires sets the 'expires' property on the public key, after which the key is no longer valid.
+func WithExpires(t *time.Time) Opt {
+	return func(opts *Options) {
+		opts.Expires = t
+	}
+}
+
 func getContexts(options *Options, contexts ...Context) []Context {
 	return append(contexts, options.Context...)
 }
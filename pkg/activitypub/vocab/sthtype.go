@@ -0,0 +1,106 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vocab
+
+import (
+	"net/url"
+)
+
+// SignedTreeHeadType defines a "SignedTreeHead" type which holds the signed tree head (STH)
+// of a verifiable credential transparency (VCT) log.
+type SignedTreeHeadType struct {
+	*ObjectType
+
+	sth *signedTreeHeadType
+}
+
+type signedTreeHeadType struct {
+	Log               *URLProperty `json:"log,omitempty"`
+	TreeSize          uint64       `json:"treeSize"`
+	Timestamp         uint64       `json:"timestamp"`
+	RootHash          []byte       `json:"rootHash,omitempty"`
+	TreeHeadSignature []byte       `json:"treeHeadSignature,omitempty"`
+}
+
+// NewSignedTreeHead returns a new "SignedTreeHead" for the given log.
+func NewSignedTreeHead(log *url.URL, treeSize, timestamp uint64, rootHash, signature []byte,
+	opts ...Opt) *SignedTreeHeadType {
+	options := NewOptions(opts...)
+
+	return &SignedTreeHeadType{
+		ObjectType: NewObject(
+			WithContext(getContexts(options, ContextActivityStreams, ContextActivityAnchors)...),
+			WithID(options.ID),
+			WithType(TypeSignedTreeHead),
+		),
+		sth: &signedTreeHeadType{
+			Log:               NewURLProperty(log),
+			TreeSize:          treeSize,
+			Timestamp:         timestamp,
+			RootHash:          rootHash,
+			TreeHeadSignature: signature,
+		},
+	}
+}
+
+// Log returns the URL of the log that issued the signed tree head.
+func (t *SignedTreeHeadType) Log() *url.URL {
+	if t == nil || t.sth == nil {
+		return nil
+	}
+
+	return t.sth.Log.URL()
+}
+
+// TreeSize returns the number of entries in the log at the time the tree head was signed.
+func (t *SignedTreeHeadType) TreeSize() uint64 {
+	if t == nil || t.sth == nil {
+		return 0
+	}
+
+	return t.sth.TreeSize
+}
+
+// Timestamp returns the time (in milliseconds since the epoch) at which the tree head was signed.
+func (t *SignedTreeHeadType) Timestamp() uint64 {
+	if t == nil || t.sth == nil {
+		return 0
+	}
+
+	return t.sth.Timestamp
+}
+
+// RootHash returns the Merkle tree root hash.
+func (t *SignedTreeHeadType) RootHash() []byte {
+	if t == nil || t.sth == nil {
+		return nil
+	}
+
+	return t.sth.RootHash
+}
+
+// TreeHeadSignature returns the log's signature over the tree head.
+func (t *SignedTreeHeadType) TreeHeadSignature() []byte {
+	if t == nil || t.sth == nil {
+		return nil
+	}
+
+	return t.sth.TreeHeadSignature
+}
+
+// MarshalJSON marshals the object to JSON.
+func (t *SignedTreeHeadType) MarshalJSON() ([]byte, error) {
+	return MarshalJSON(t.ObjectType, t.sth)
+}
+
+// UnmarshalJSON unmarshals the object from JSON.
+func (t *SignedTreeHeadType) UnmarshalJSON(bytes []byte) error {
+	t.ObjectType = NewObject()
+	t.sth = &signedTreeHeadType{}
+
+	return UnmarshalJSON(bytes, t.ObjectType, t.sth)
+}
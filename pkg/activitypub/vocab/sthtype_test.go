@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package vocab
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/canonicalizer"
+
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+)
+
+var (
+	logURL       = testutil.MustParseURL("https://vct.example.com/maple2021")
+	sthRootHash  = []byte("root-hash")
+	sthSignature = []byte("signature")
+)
+
+func TestNewSignedTreeHead(t *testing.T) {
+	sth := NewSignedTreeHead(logURL, 12, 1626270000000, sthRootHash, sthSignature)
+	require.NotNil(t, sth)
+
+	contextProp := sth.Context()
+	require.NotNil(t, contextProp)
+	require.True(t, contextProp.Contains(ContextActivityStreams, ContextActivityAnchors))
+
+	typeProp := sth.Type()
+	require.NotNil(t, typeProp)
+	require.True(t, typeProp.Is(TypeSignedTreeHead))
+
+	require.Equal(t, logURL.String(), sth.Log().String())
+	require.Equal(t, uint64(12), sth.TreeSize())
+	require.Equal(t, uint64(1626270000000), sth.Timestamp())
+	require.Equal(t, sthRootHash, sth.RootHash())
+	require.Equal(t, sthSignature, sth.TreeHeadSignature())
+}
+
+func TestSignedTreeHeadMarshal(t *testing.T) {
+	t.Run("Marshal", func(t *testing.T) {
+		sth := NewSignedTreeHead(logURL, 12, 1626270000000, sthRootHash, sthSignature)
+
+		bytes, err := canonicalizer.MarshalCanonical(sth)
+		require.NoError(t, err)
+		t.Log(string(bytes))
+
+		require.Equal(t, testutil.GetCanonical(t, jsonSignedTreeHead), string(bytes))
+	})
+
+	t.Run("Unmarshal", func(t *testing.T) {
+		sth := &SignedTreeHeadType{}
+		require.NoError(t, json.Unmarshal([]byte(jsonSignedTreeHead), sth))
+
+		typeProp := sth.Type()
+		require.NotNil(t, typeProp)
+		require.True(t, typeProp.Is(TypeSignedTreeHead))
+
+		require.Equal(t, logURL.String(), sth.Log().String())
+		require.Equal(t, uint64(12), sth.TreeSize())
+		require.Equal(t, uint64(1626270000000), sth.Timestamp())
+		require.Equal(t, sthRootHash, sth.RootHash())
+		require.Equal(t, sthSignature, sth.TreeHeadSignature())
+	})
+
+	t.Run("Embedded in Announce", func(t *testing.T) {
+		announce := NewAnnounceActivity(
+			NewObjectProperty(
+				WithSignedTreeHead(NewSignedTreeHead(logURL, 12, 1626270000000, sthRootHash, sthSignature)),
+			),
+			WithID(newMockID(host1, "/activities/announce_sth")),
+		)
+
+		bytes, err := json.Marshal(announce)
+		require.NoError(t, err)
+
+		a := &ActivityType{}
+		require.NoError(t, json.Unmarshal(bytes, a))
+
+		objProp := a.Object()
+		require.NotNil(t, objProp)
+		require.True(t, objProp.Type().Is(TypeSignedTreeHead))
+
+		sth := objProp.SignedTreeHead()
+		require.NotNil(t, sth)
+		require.Equal(t, logURL.String(), sth.Log().String())
+		require.Equal(t, uint64(12), sth.TreeSize())
+		require.Equal(t, sthRootHash, sth.RootHash())
+	})
+}
+
+func TestSignedTreeHeadAccessors(t *testing.T) {
+	var sth *SignedTreeHeadType
+
+	require.Nil(t, sth.Log())
+	require.Zero(t, sth.TreeSize())
+	require.Zero(t, sth.Timestamp())
+	require.Nil(t, sth.RootHash())
+	require.Nil(t, sth.TreeHeadSignature())
+
+	var objProp *ObjectProperty
+
+	require.Nil(t, objProp.SignedTreeHead())
+}
+
+const jsonSignedTreeHead = `{
+  "@context": [
+    "https://www.w3.org/ns/activitystreams",
+    "https://w3id.org/activityanchors/v1"
+  ],
+  "type": "SignedTreeHead",
+  "log": "https://vct.example.com/maple2021",
+  "treeSize": 12,
+  "timestamp": 1626270000000,
+  "rootHash": "cm9vdC1oYXNo",
+  "treeHeadSignature": "c2lnbmF0dXJl"
+}`
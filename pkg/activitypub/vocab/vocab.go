@@ -70,10 +70,18 @@ const (
 	TypeAnchorRef Type = "AnchorReference"
 	// TypeAnchorReceipt specifies the "AnchorReceipt" object type.
 	TypeAnchorReceipt Type = "AnchorReceipt"
+	// TypeSignedTreeHead specifies the "SignedTreeHead" object type.
+	TypeSignedTreeHead Type = "SignedTreeHead"
 	// TypeOffer specifies the "Offer" activity type.
 	TypeOffer Type = "Offer"
 	// TypeUndo specifies the "Undo" activity type.
 	TypeUndo Type = "Undo"
+	// TypeUpdate specifies the "Update" activity type.
+	TypeUpdate Type = "Update"
+	// TypeDelete specifies the "Delete" activity type.
+	TypeDelete Type = "Delete"
+	// TypeBlock specifies the "Block" activity type.
+	TypeBlock Type = "Block"
 )
 
 const (
@@ -0,0 +1,348 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package admission implements a health-driven admission controller which applies back-pressure to the
+// Sidetree operation pipeline when witnesses are unresponsive or CAS writes are failing.
+package admission
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("admission-control")
+
+const (
+	defaultWindow                     = 5 * time.Minute
+	defaultWitnessResponseGracePeriod = time.Minute
+	defaultEvaluationInterval         = 10 * time.Second
+	defaultMinSamples                 = 10
+	defaultWitnessThrottleRate        = 0.5
+	defaultWitnessRejectRate          = 0.2
+	defaultCASThrottleErrorRate       = 0.2
+	defaultCASRejectErrorRate         = 0.5
+	defaultThrottleFactor             = 4
+	defaultRetryAfter                 = time.Minute
+
+	resolutionsPerWindow = 60
+)
+
+// State is the state of the admission controller.
+type State string
+
+const (
+	// StateHealthy indicates that operations are admitted and batched normally.
+	StateHealthy State = "healthy"
+
+	// StateThrottled indicates that operations are admitted but batches are cut less frequently.
+	StateThrottled State = "throttled"
+
+	// StateRejecting indicates that new operations are rejected.
+	StateRejecting State = "rejecting"
+)
+
+// Config contains the admission controller configuration. Zero values are replaced with defaults.
+type Config struct {
+	// Window is the period over which witness response rates and CAS error rates are calculated.
+	Window time.Duration
+	// WitnessResponseGracePeriod is the time that a witness has to respond to a request before the request
+	// is included in the witness response rate. This should be set to the maximum witness delay.
+	WitnessResponseGracePeriod time.Duration
+	// EvaluationInterval is the interval at which the state is re-evaluated.
+	EvaluationInterval time.Duration
+	// MinSamples is the minimum number of witness requests (or CAS writes) within the window before the
+	// corresponding thresholds are applied.
+	MinSamples int
+	// WitnessThrottleRate is the witness response rate (0-1) below which batching is throttled.
+	WitnessThrottleRate float64
+	// WitnessRejectRate is the witness response rate (0-1) below which new operations are rejected.
+	WitnessRejectRate float64
+	// CASThrottleErrorRate is the CAS write error rate (0-1) above which batching is throttled.
+	CASThrottleErrorRate float64
+	// CASRejectErrorRate is the CAS write error rate (0-1) above which new operations are rejected.
+	CASRejectErrorRate float64
+	// BatchTimeout is the configured batch writer timeout.
+	BatchTimeout time.Duration
+	// ThrottleFactor is the factor by which the batch timeout is multiplied when throttled.
+	ThrottleFactor int
+	// RetryAfter is the value of the Retry-After header returned when operations are rejected.
+	RetryAfter time.Duration
+}
+
+// Status contains the current state along with the values from which the state was derived.
+type Status struct {
+	State               State   `json:"state"`
+	WitnessRequests     uint64  `json:"witnessRequests"`
+	WitnessResponses    uint64  `json:"witnessResponses"`
+	WitnessResponseRate float64 `json:"witnessResponseRate"`
+	CASWrites           uint64  `json:"casWrites"`
+	CASErrors           uint64  `json:"casErrors"`
+	CASErrorRate        float64 `json:"casErrorRate"`
+}
+
+type metricsProvider interface {
+	AdmissionControlState(value float64)
+	AdmissionControlWitnessResponseRate(value float64)
+	AdmissionControlCASErrorRate(value float64)
+	AdmissionControlIncrementRejectedCount()
+}
+
+// Controller tracks witness response rates and CAS error rates and determines whether operations
+// should be admitted normally, admitted with throttled batching, or rejected.
+type Controller struct {
+	*lifecycle.Lifecycle
+
+	config           Config
+	metrics          metricsProvider
+	witnessRequests  *series
+	witnessResponses *series
+	casWrites        *series
+	casErrors        *series
+	done             chan struct{}
+	now              func() time.Time
+	mutex            sync.RWMutex
+	status           Status
+}
+
+// New returns a new admission controller.
+func New(cfg Config, metrics metricsProvider) *Controller {
+	cfg = populateConfigDefaults(cfg)
+
+	retention := cfg.Window + cfg.WitnessResponseGracePeriod
+	resolution := cfg.Window / resolutionsPerWindow
+
+	c := &Controller{
+		config:           cfg,
+		metrics:          metrics,
+		witnessRequests:  newSeries(retention, resolution),
+		witnessResponses: newSeries(retention, resolution),
+		casWrites:        newSeries(retention, resolution),
+		casErrors:        newSeries(retention, resolution),
+		done:             make(chan struct{}),
+		now:              time.Now,
+		status:           Status{State: StateHealthy},
+	}
+
+	c.Lifecycle = lifecycle.New("admission-control",
+		lifecycle.WithStart(c.start),
+		lifecycle.WithStop(c.stop))
+
+	return c
+}
+
+// State returns the current state.
+func (c *Controller) State() State {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.status.State
+}
+
+// Status returns the current status.
+func (c *Controller) Status() Status {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.status
+}
+
+// RetryAfter returns the time after which a client should retry a rejected operation.
+func (c *Controller) RetryAfter() time.Duration {
+	return c.config.RetryAfter
+}
+
+// ThrottledBatchInterval returns the minimum interval between batches when throttled.
+func (c *Controller) ThrottledBatchInterval() time.Duration {
+	return c.config.BatchTimeout * time.Duration(c.config.ThrottleFactor)
+}
+
+// RecordWitnessRequests records the given number of requests for witness proofs.
+func (c *Controller) RecordWitnessRequests(n int) {
+	c.witnessRequests.add(c.now(), uint64(n))
+}
+
+// RecordWitnessResponse records the arrival of a witness proof.
+func (c *Controller) RecordWitnessResponse() {
+	c.witnessResponses.add(c.now(), 1)
+}
+
+// RecordCASWrite records the result of a CAS write.
+func (c *Controller) RecordCASWrite(err error) {
+	now := c.now()
+
+	c.casWrites.add(now, 1)
+
+	if err != nil {
+		c.casErrors.add(now, 1)
+	}
+}
+
+// RecordRejected records that an operation was rejected.
+func (c *Controller) RecordRejected() {
+	c.metrics.AdmissionControlIncrementRejectedCount()
+}
+
+func (c *Controller) start() {
+	go c.run()
+
+	logger.Infof("Started admission controller - Window [%s], Evaluation interval [%s]",
+		c.config.Window, c.config.EvaluationInterval)
+}
+
+func (c *Controller) stop() {
+	close(c.done)
+
+	logger.Infof("Stopped admission controller")
+}
+
+func (c *Controller) run() {
+	for {
+		select {
+		case <-time.After(c.config.EvaluationInterval):
+			c.evaluate()
+		case <-c.done:
+			logger.Debugf("Exiting admission controller.")
+
+			return
+		}
+	}
+}
+
+func (c *Controller) evaluate() {
+	now := c.now()
+
+	// Witness requests are counted only after the grace period has elapsed so that witnesses
+	// have a chance to respond before the request counts against the response rate.
+	requestsTo := now.Add(-c.config.WitnessResponseGracePeriod)
+
+	status := Status{
+		WitnessRequests:  c.witnessRequests.sum(requestsTo.Add(-c.config.Window), requestsTo),
+		WitnessResponses: c.witnessResponses.sum(now.Add(-c.config.Window), now),
+		CASWrites:        c.casWrites.sum(now.Add(-c.config.Window), now),
+		CASErrors:        c.casErrors.sum(now.Add(-c.config.Window), now),
+	}
+
+	status.WitnessResponseRate = rate(status.WitnessResponses, status.WitnessRequests)
+	status.CASErrorRate = rate(status.CASErrors, status.CASWrites)
+	status.State = c.stateOf(&status)
+
+	c.mutex.Lock()
+
+	previousState := c.status.State
+	c.status = status
+
+	c.mutex.Unlock()
+
+	if status.State != previousState {
+		logger.Warnf("Admission control state changed from [%s] to [%s] - %s", previousState, status.State, &status)
+	} else {
+		logger.Debugf("Admission control state: %s", &status)
+	}
+
+	c.metrics.AdmissionControlState(stateValue(status.State))
+	c.metrics.AdmissionControlWitnessResponseRate(status.WitnessResponseRate)
+	c.metrics.AdmissionControlCASErrorRate(status.CASErrorRate)
+}
+
+func (c *Controller) stateOf(status *Status) State {
+	minSamples := uint64(c.config.MinSamples)
+
+	witnessSampled := status.WitnessRequests >= minSamples
+	casSampled := status.CASWrites >= minSamples
+
+	switch {
+	case witnessSampled && status.WitnessResponseRate < c.config.WitnessRejectRate,
+		casSampled && status.CASErrorRate > c.config.CASRejectErrorRate:
+		return StateRejecting
+	case witnessSampled && status.WitnessResponseRate < c.config.WitnessThrottleRate,
+		casSampled && status.CASErrorRate > c.config.CASThrottleErrorRate:
+		return StateThrottled
+	default:
+		return StateHealthy
+	}
+}
+
+func (s *Status) String() string {
+	return fmt.Sprintf("State: %s, Witness requests: %d, Witness responses: %d, CAS writes: %d, CAS errors: %d",
+		s.State, s.WitnessRequests, s.WitnessResponses, s.CASWrites, s.CASErrors)
+}
+
+// rate returns n/total (capped at 1), or 0 if total is 0.
+func rate(n, total uint64) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	r := float64(n) / float64(total)
+
+	// More responses than requests may be counted since responses to requests made before the
+	// window are included.
+	if r > 1 {
+		return 1
+	}
+
+	return r
+}
+
+func stateValue(state State) float64 {
+	switch state {
+	case StateThrottled:
+		return 1
+	case StateRejecting:
+		return 2 //nolint:gomnd
+	default:
+		return 0
+	}
+}
+
+func populateConfigDefaults(cfg Config) Config {
+	if cfg.Window == 0 {
+		cfg.Window = defaultWindow
+	}
+
+	if cfg.WitnessResponseGracePeriod == 0 {
+		cfg.WitnessResponseGracePeriod = defaultWitnessResponseGracePeriod
+	}
+
+	if cfg.EvaluationInterval == 0 {
+		cfg.EvaluationInterval = defaultEvaluationInterval
+	}
+
+	if cfg.MinSamples == 0 {
+		cfg.MinSamples = defaultMinSamples
+	}
+
+	if cfg.WitnessThrottleRate == 0 {
+		cfg.WitnessThrottleRate = defaultWitnessThrottleRate
+	}
+
+	if cfg.WitnessRejectRate == 0 {
+		cfg.WitnessRejectRate = defaultWitnessRejectRate
+	}
+
+	if cfg.CASThrottleErrorRate == 0 {
+		cfg.CASThrottleErrorRate = defaultCASThrottleErrorRate
+	}
+
+	if cfg.CASRejectErrorRate == 0 {
+		cfg.CASRejectErrorRate = defaultCASRejectErrorRate
+	}
+
+	if cfg.ThrottleFactor == 0 {
+		cfg.ThrottleFactor = defaultThrottleFactor
+	}
+
+	if cfg.RetryAfter == 0 {
+		cfg.RetryAfter = defaultRetryAfter
+	}
+
+	return cfg
+}
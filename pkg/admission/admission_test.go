@@ -0,0 +1,238 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	c := New(Config{BatchTimeout: 2 * time.Second}, &mockMetrics{})
+	require.NotNil(t, c)
+
+	require.Equal(t, StateHealthy, c.State())
+	require.Equal(t, defaultRetryAfter, c.RetryAfter())
+	require.Equal(t, 8*time.Second, c.ThrottledBatchInterval())
+
+	c.Start()
+	c.Stop()
+}
+
+func TestController(t *testing.T) {
+	cfg := Config{
+		Window:                     time.Minute,
+		WitnessResponseGracePeriod: 10 * time.Second,
+		MinSamples:                 4,
+	}
+
+	t.Run("Healthy", func(t *testing.T) {
+		c, clock := newTestController(cfg)
+
+		c.RecordWitnessRequests(4)
+
+		for i := 0; i < 4; i++ {
+			c.RecordCASWrite(nil)
+		}
+
+		c.RecordCASWrite(errors.New("injected CAS error"))
+
+		clock.advance(5 * time.Second)
+
+		for i := 0; i < 4; i++ {
+			c.RecordWitnessResponse()
+		}
+
+		clock.advance(10 * time.Second)
+
+		c.evaluate()
+
+		status := c.Status()
+		require.Equal(t, StateHealthy, status.State)
+		require.Equal(t, uint64(4), status.WitnessRequests)
+		require.Equal(t, uint64(4), status.WitnessResponses)
+		require.Equal(t, 1.0, status.WitnessResponseRate)
+		require.Equal(t, uint64(5), status.CASWrites)
+		require.Equal(t, uint64(1), status.CASErrors)
+		require.Equal(t, 0.2, status.CASErrorRate)
+	})
+
+	t.Run("Witness requests within grace period are not counted", func(t *testing.T) {
+		c, clock := newTestController(cfg)
+
+		c.RecordWitnessRequests(10)
+
+		clock.advance(5 * time.Second)
+
+		c.evaluate()
+
+		status := c.Status()
+		require.Equal(t, StateHealthy, status.State)
+		require.Zero(t, status.WitnessRequests)
+
+		clock.advance(10 * time.Second)
+
+		c.evaluate()
+
+		status = c.Status()
+		require.Equal(t, StateRejecting, status.State)
+		require.Equal(t, uint64(10), status.WitnessRequests)
+		require.Zero(t, status.WitnessResponseRate)
+	})
+
+	t.Run("Witnesses degraded -> throttled", func(t *testing.T) {
+		c, clock := newTestController(cfg)
+
+		c.RecordWitnessRequests(10)
+
+		for i := 0; i < 4; i++ {
+			c.RecordWitnessResponse()
+		}
+
+		clock.advance(15 * time.Second)
+
+		c.evaluate()
+
+		require.Equal(t, StateThrottled, c.State())
+		require.Equal(t, 0.4, c.Status().WitnessResponseRate)
+	})
+
+	t.Run("CAS degraded", func(t *testing.T) {
+		c, clock := newTestController(cfg)
+
+		for i := 0; i < 3; i++ {
+			c.RecordCASWrite(nil)
+		}
+
+		c.RecordCASWrite(errors.New("injected CAS error"))
+		c.RecordCASWrite(errors.New("injected CAS error"))
+
+		c.evaluate()
+
+		require.Equal(t, StateThrottled, c.State())
+
+		c.RecordCASWrite(errors.New("injected CAS error"))
+		c.RecordCASWrite(errors.New("injected CAS error"))
+
+		c.evaluate()
+
+		require.Equal(t, StateRejecting, c.State())
+
+		// Recover after the errors fall out of the window.
+		clock.advance(2 * time.Minute)
+
+		c.RecordCASWrite(nil)
+
+		c.evaluate()
+
+		require.Equal(t, StateHealthy, c.State())
+	})
+
+	t.Run("Not enough samples", func(t *testing.T) {
+		c, _ := newTestController(cfg)
+
+		c.RecordCASWrite(errors.New("injected CAS error"))
+		c.RecordCASWrite(errors.New("injected CAS error"))
+
+		c.evaluate()
+
+		status := c.Status()
+		require.Equal(t, StateHealthy, status.State)
+		require.Equal(t, 1.0, status.CASErrorRate)
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		c, _ := newTestController(cfg)
+
+		metrics := c.metrics.(*mockMetrics)
+
+		for i := 0; i < 4; i++ {
+			c.RecordCASWrite(errors.New("injected CAS error"))
+		}
+
+		c.evaluate()
+		c.RecordRejected()
+
+		require.Equal(t, 2.0, metrics.state)
+		require.Equal(t, 1.0, metrics.casErrorRate)
+		require.Equal(t, 1, metrics.rejected)
+	})
+}
+
+func TestRate(t *testing.T) {
+	require.Zero(t, rate(1, 0))
+	require.Equal(t, 0.5, rate(1, 2))
+	require.Equal(t, 1.0, rate(3, 2))
+}
+
+type testClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.now
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.now = c.now.Add(d)
+}
+
+func newTestController(cfg Config) (*Controller, *testClock) {
+	clock := &testClock{now: time.Unix(1000, 0)}
+
+	c := New(cfg, &mockMetrics{})
+	c.now = clock.Now
+
+	return c, clock
+}
+
+type mockMetrics struct {
+	mutex               sync.Mutex
+	state               float64
+	witnessResponseRate float64
+	casErrorRate        float64
+	rejected            int
+}
+
+func (m *mockMetrics) AdmissionControlState(value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.state = value
+}
+
+func (m *mockMetrics) AdmissionControlWitnessResponseRate(value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.witnessResponseRate = value
+}
+
+func (m *mockMetrics) AdmissionControlCASErrorRate(value float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.casErrorRate = value
+}
+
+func (m *mockMetrics) AdmissionControlIncrementRejectedCount() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.rejected++
+}
@@ -0,0 +1,56 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+var errUnavailable = errors.New("operations are temporarily not accepted since the service is degraded")
+
+// HandlerWrapper wraps the Sidetree operations handler and rejects operations with status 503
+// (Service Unavailable) when the admission controller is in the rejecting state.
+type HandlerWrapper struct {
+	common.HTTPHandler
+
+	controller    *Controller
+	handleRequest common.HTTPRequestHandler
+}
+
+// NewHandlerWrapper returns a new admission control handler wrapper.
+func NewHandlerWrapper(handler common.HTTPHandler, controller *Controller) *HandlerWrapper {
+	return &HandlerWrapper{
+		HTTPHandler:   handler,
+		controller:    controller,
+		handleRequest: handler.Handler(),
+	}
+}
+
+// Handler returns the 'wrapper' handler.
+func (h *HandlerWrapper) Handler() common.HTTPRequestHandler {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if h.controller.State() == StateRejecting {
+			logger.Infof("Rejecting operation request: %s", errUnavailable)
+
+			h.controller.RecordRejected()
+
+			w.Header().Set("Retry-After",
+				strconv.Itoa(int(math.Ceil(h.controller.RetryAfter().Seconds()))))
+
+			common.WriteError(w, http.StatusServiceUnavailable, errUnavailable)
+
+			return
+		}
+
+		h.handleRequest(w, req)
+	}
+}
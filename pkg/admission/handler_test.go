@@ -0,0 +1,76 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+)
+
+func TestHandlerWrapper(t *testing.T) {
+	c, _ := newTestController(Config{})
+
+	handler := &mockHTTPHandler{}
+
+	w := NewHandlerWrapper(handler, c)
+	require.NotNil(t, w)
+	require.Equal(t, "/sidetree/v1/operations", w.Path())
+	require.Equal(t, http.MethodPost, w.Method())
+
+	t.Run("Healthy", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, invoke(w).StatusCode)
+		require.Equal(t, 1, handler.n)
+	})
+
+	t.Run("Throttled", func(t *testing.T) {
+		c.status.State = StateThrottled
+
+		require.Equal(t, http.StatusOK, invoke(w).StatusCode)
+		require.Equal(t, 2, handler.n)
+	})
+
+	t.Run("Rejecting", func(t *testing.T) {
+		c.status.State = StateRejecting
+
+		resp := invoke(w)
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, "60", resp.Header.Get("Retry-After"))
+		require.Equal(t, 2, handler.n)
+		require.Equal(t, 1, c.metrics.(*mockMetrics).rejected)
+	})
+}
+
+func invoke(w *HandlerWrapper) *http.Response {
+	rw := httptest.NewRecorder()
+
+	w.Handler()(rw, httptest.NewRequest(http.MethodPost, "/sidetree/v1/operations", strings.NewReader("{}")))
+
+	return rw.Result()
+}
+
+type mockHTTPHandler struct {
+	n int
+}
+
+func (m *mockHTTPHandler) Path() string {
+	return "/sidetree/v1/operations"
+}
+
+func (m *mockHTTPHandler) Method() string {
+	return http.MethodPost
+}
+
+func (m *mockHTTPHandler) Handler() common.HTTPRequestHandler {
+	return func(w http.ResponseWriter, req *http.Request) {
+		m.n++
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"sync"
+	"time"
+)
+
+// series counts events in fixed-size time buckets so that the number of events within an arbitrary
+// time range (within the retention period) may be calculated.
+type series struct {
+	mutex      sync.Mutex
+	resolution time.Duration
+	buckets    []bucket
+}
+
+type bucket struct {
+	index int64
+	count uint64
+}
+
+func newSeries(retention, resolution time.Duration) *series {
+	return &series{
+		resolution: resolution,
+		buckets:    make([]bucket, int(retention/resolution)+1),
+	}
+}
+
+// add adds the given number of events at the given time.
+func (s *series) add(t time.Time, n uint64) {
+	index := s.indexOf(t)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	b := &s.buckets[index%int64(len(s.buckets))]
+
+	if b.index != index {
+		// The bucket holds stale data from a previous cycle.
+		b.index = index
+		b.count = 0
+	}
+
+	b.count += n
+}
+
+// sum returns the number of events in the time range (from, to], at the resolution of the series.
+func (s *series) sum(from, to time.Time) uint64 {
+	fromIndex := s.indexOf(from)
+	toIndex := s.indexOf(to)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var total uint64
+
+	for _, b := range s.buckets {
+		if b.index > fromIndex && b.index <= toIndex {
+			total += b.count
+		}
+	}
+
+	return total
+}
+
+func (s *series) indexOf(t time.Time) int64 {
+	return t.UnixNano() / int64(s.resolution)
+}
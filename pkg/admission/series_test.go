@@ -0,0 +1,36 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	s := newSeries(time.Minute, time.Second)
+
+	start := time.Unix(1000, 0)
+
+	s.add(start, 1)
+	s.add(start.Add(500*time.Millisecond), 2)
+	s.add(start.Add(10*time.Second), 3)
+	s.add(start.Add(30*time.Second), 4)
+
+	require.Equal(t, uint64(10), s.sum(start.Add(-time.Second), start.Add(30*time.Second)))
+	require.Equal(t, uint64(7), s.sum(start, start.Add(30*time.Second)))
+	require.Equal(t, uint64(3), s.sum(start, start.Add(10*time.Second)))
+	require.Equal(t, uint64(0), s.sum(start.Add(30*time.Second), start.Add(40*time.Second)))
+
+	// Buckets from the previous cycle are overwritten.
+	s.add(start.Add(61*time.Second), 5)
+
+	require.Equal(t, uint64(12), s.sum(start, start.Add(61*time.Second)))
+	require.Equal(t, uint64(5), s.sum(start.Add(60*time.Second), start.Add(61*time.Second)))
+}
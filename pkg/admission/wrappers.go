@@ -0,0 +1,190 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"sync"
+	"time"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/cutter"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+)
+
+// WitnessStore is the store in which the witnesses of an anchor credential, and their proofs, are stored.
+type WitnessStore interface {
+	Put(vcID string, witnesses []*proof.WitnessProof) error
+	Replace(vcID string, witnesses []*proof.WitnessProof) error
+	Get(vcID string) ([]*proof.WitnessProof, error)
+	Delete(vcID string) error
+	AddProof(vcID, witness string, p []byte) error
+}
+
+// WitnessStoreWrapper wraps a witness store and records the number of witnesses that were asked for
+// a proof (on Put and Replace) and the number of proofs that were received (on AddProof).
+type WitnessStoreWrapper struct {
+	WitnessStore
+
+	controller *Controller
+}
+
+// WrapWitnessStore returns a witness store that records witness requests and responses.
+func (c *Controller) WrapWitnessStore(store WitnessStore) *WitnessStoreWrapper {
+	return &WitnessStoreWrapper{WitnessStore: store, controller: c}
+}
+
+// Put stores the witnesses for the given anchor credential and records a request for each witness.
+func (w *WitnessStoreWrapper) Put(vcID string, witnesses []*proof.WitnessProof) error {
+	if err := w.WitnessStore.Put(vcID, witnesses); err != nil {
+		return err
+	}
+
+	w.controller.RecordWitnessRequests(len(witnesses))
+
+	return nil
+}
+
+// Replace replaces the witnesses for the given anchor credential (e.g. when the anchor credential is re-witnessed)
+// and records a request for each witness.
+func (w *WitnessStoreWrapper) Replace(vcID string, witnesses []*proof.WitnessProof) error {
+	if err := w.WitnessStore.Replace(vcID, witnesses); err != nil {
+		return err
+	}
+
+	w.controller.RecordWitnessRequests(len(witnesses))
+
+	return nil
+}
+
+// AddProof adds the proof of the given witness and records the response.
+func (w *WitnessStoreWrapper) AddProof(vcID, witness string, p []byte) error {
+	if err := w.WitnessStore.AddProof(vcID, witness, p); err != nil {
+		return err
+	}
+
+	w.controller.RecordWitnessResponse()
+
+	return nil
+}
+
+// Witness witnesses an anchor credential.
+type Witness interface {
+	Witness(anchorCred []byte) ([]byte, error)
+}
+
+// WitnessWrapper wraps the (local) VCT witness and records each request and successful response.
+type WitnessWrapper struct {
+	target     Witness
+	controller *Controller
+}
+
+// WrapWitness returns a witness that records witness requests and responses.
+func (c *Controller) WrapWitness(w Witness) *WitnessWrapper {
+	return &WitnessWrapper{target: w, controller: c}
+}
+
+// Witness witnesses the given anchor credential.
+func (w *WitnessWrapper) Witness(anchorCred []byte) ([]byte, error) {
+	w.controller.RecordWitnessRequests(1)
+
+	proofBytes, err := w.target.Witness(anchorCred)
+	if err != nil {
+		return nil, err
+	}
+
+	w.controller.RecordWitnessResponse()
+
+	return proofBytes, nil
+}
+
+// CASClientWrapper wraps a CAS client and records the result of each write.
+type CASClientWrapper struct {
+	extendedcasclient.Client
+
+	controller *Controller
+}
+
+// WrapCASClient returns a CAS client that records the result of each write.
+func (c *Controller) WrapCASClient(client extendedcasclient.Client) *CASClientWrapper {
+	return &CASClientWrapper{Client: client, controller: c}
+}
+
+// Write writes the given content to CAS.
+func (w *CASClientWrapper) Write(content []byte) (string, error) {
+	address, err := w.Client.Write(content)
+
+	w.controller.RecordCASWrite(err)
+
+	return address, err
+}
+
+// WriteWithCIDFormat writes the given content to CAS using the given CID format.
+func (w *CASClientWrapper) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) { //nolint:lll
+	address, err := w.Client.WriteWithCIDFormat(content, opts...)
+
+	w.controller.RecordCASWrite(err)
+
+	return address, err
+}
+
+// OperationQueueWrapper wraps the operation queue that's used by the batch cutter. When the controller is in
+// the throttled (or rejecting) state, the operations in the queue are hidden from the batch cutter until the
+// throttled batch interval has elapsed since the last batch was cut, which effectively increases the batch
+// timeout.
+type OperationQueueWrapper struct {
+	cutter.OperationQueue
+
+	controller *Controller
+	mutex      sync.RWMutex
+	lastCut    time.Time
+}
+
+// WrapOperationQueue returns an operation queue that throttles batch cutting.
+func (c *Controller) WrapOperationQueue(q cutter.OperationQueue) *OperationQueueWrapper {
+	return &OperationQueueWrapper{OperationQueue: q, controller: c, lastCut: c.now()}
+}
+
+// Peek returns (up to) the given number of operations from the head of the queue but does not remove them.
+// No operations are returned if batching is currently throttled.
+func (w *OperationQueueWrapper) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	if w.throttled() {
+		logger.Debugf("Batching is throttled")
+
+		return nil, nil
+	}
+
+	return w.OperationQueue.Peek(num)
+}
+
+// Remove removes (up to) the given number of items from the head of the queue.
+func (w *OperationQueueWrapper) Remove(num uint) (operation.QueuedOperationsAtTime, func() uint, func(), error) {
+	ops, ack, nack, err := w.OperationQueue.Remove(num)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if len(ops) > 0 {
+		w.mutex.Lock()
+		w.lastCut = w.controller.now()
+		w.mutex.Unlock()
+	}
+
+	return ops, ack, nack, nil
+}
+
+func (w *OperationQueueWrapper) throttled() bool {
+	if w.controller.State() == StateHealthy {
+		return false
+	}
+
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	return w.controller.now().Sub(w.lastCut) < w.controller.ThrottledBatchInterval()
+}
@@ -0,0 +1,195 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package admission
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/batch/opqueue"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/cas/resolver/mocks"
+	witnessstore "github.com/trustbloc/orb/pkg/store/witness"
+)
+
+const (
+	vcID     = "https://orb.domain1.com/vc/1"
+	witness1 = "https://orb.domain2.com/services/orb"
+	witness2 = "https://orb.domain3.com/services/orb"
+	witness3 = "https://orb.domain4.com/services/orb"
+)
+
+func TestWitnessStoreWrapper(t *testing.T) {
+	s, err := witnessstore.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	c, _ := newTestController(Config{})
+
+	ws := c.WrapWitnessStore(s)
+
+	witnesses := []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: witness1},
+		{Type: proof.WitnessTypeSystem, Witness: witness2},
+	}
+
+	require.NoError(t, ws.Put(vcID, witnesses))
+	require.NoError(t, ws.AddProof(vcID, witness1, []byte("proof")))
+
+	// Proof for an unknown anchor credential.
+	require.Error(t, ws.AddProof("https://orb.domain1.com/vc/2", witness1, []byte("proof")))
+
+	// Re-witness with another system witness.
+	require.NoError(t, ws.Replace(vcID, []*proof.WitnessProof{
+		{Type: proof.WitnessTypeBatch, Witness: witness1},
+		{Type: proof.WitnessTypeSystem, Witness: witness3},
+	}))
+
+	ws = c.WrapWitnessStore(&mockWitnessStore{WitnessStore: s, err: errors.New("injected put error")})
+
+	require.Error(t, ws.Put(vcID, witnesses))
+	require.Error(t, ws.Replace(vcID, witnesses))
+
+	now := c.now()
+
+	require.Equal(t, uint64(4), c.witnessRequests.sum(now.Add(-time.Minute), now))
+	require.Equal(t, uint64(1), c.witnessResponses.sum(now.Add(-time.Minute), now))
+}
+
+func TestWitnessWrapper(t *testing.T) {
+	c, _ := newTestController(Config{})
+
+	w := c.WrapWitness(&mockWitness{})
+
+	_, err := w.Witness([]byte("anchor"))
+	require.NoError(t, err)
+
+	w = c.WrapWitness(&mockWitness{err: errors.New("injected witness error")})
+
+	_, err = w.Witness([]byte("anchor"))
+	require.Error(t, err)
+
+	now := c.now()
+
+	require.Equal(t, uint64(2), c.witnessRequests.sum(now.Add(-time.Minute), now))
+	require.Equal(t, uint64(1), c.witnessResponses.sum(now.Add(-time.Minute), now))
+}
+
+func TestCASClientWrapper(t *testing.T) {
+	c, _ := newTestController(Config{})
+
+	casClient := &mocks.CASClient{}
+	casClient.WriteReturnsOnCall(1, "", errors.New("injected write error"))
+	casClient.WriteWithCIDFormatReturnsOnCall(0, "", errors.New("injected write error"))
+
+	w := c.WrapCASClient(casClient)
+
+	_, err := w.Write([]byte("content"))
+	require.NoError(t, err)
+
+	_, err = w.Write([]byte("content"))
+	require.Error(t, err)
+
+	_, err = w.WriteWithCIDFormat([]byte("content"))
+	require.Error(t, err)
+
+	_, err = w.WriteWithCIDFormat([]byte("content"))
+	require.NoError(t, err)
+
+	now := c.now()
+
+	require.Equal(t, uint64(4), c.casWrites.sum(now.Add(-time.Minute), now))
+	require.Equal(t, uint64(2), c.casErrors.sum(now.Add(-time.Minute), now))
+}
+
+func TestOperationQueueWrapper(t *testing.T) {
+	c, clock := newTestController(Config{BatchTimeout: time.Second, ThrottleFactor: 3})
+
+	q := c.WrapOperationQueue(&opqueue.MemQueue{})
+
+	_, err := q.Add(&operation.QueuedOperation{UniqueSuffix: "suffix1"}, 0)
+	require.NoError(t, err)
+
+	t.Run("Healthy", func(t *testing.T) {
+		ops, err := q.Peek(1)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+	})
+
+	c.status.State = StateThrottled
+
+	t.Run("Throttled", func(t *testing.T) {
+		ops, err := q.Peek(1)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+
+		clock.advance(3 * time.Second)
+
+		ops, err = q.Peek(1)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+
+		ops, _, _, err = q.Remove(1)
+		require.NoError(t, err)
+		require.Len(t, ops, 1)
+
+		_, err = q.Add(&operation.QueuedOperation{UniqueSuffix: "suffix2"}, 0)
+		require.NoError(t, err)
+
+		// The throttled interval starts again from the last cut.
+		ops, err = q.Peek(1)
+		require.NoError(t, err)
+		require.Empty(t, ops)
+	})
+
+	t.Run("Remove error", func(t *testing.T) {
+		q := c.WrapOperationQueue(&mockOperationQueue{err: errors.New("injected remove error")})
+
+		_, _, _, err := q.Remove(1)
+		require.Error(t, err)
+	})
+}
+
+type mockWitness struct {
+	err error
+}
+
+func (m *mockWitness) Witness([]byte) ([]byte, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return []byte("proof"), nil
+}
+
+type mockWitnessStore struct {
+	WitnessStore
+
+	err error
+}
+
+func (m *mockWitnessStore) Put(string, []*proof.WitnessProof) error {
+	return m.err
+}
+
+func (m *mockWitnessStore) Replace(string, []*proof.WitnessProof) error {
+	return m.err
+}
+
+type mockOperationQueue struct {
+	opqueue.MemQueue
+
+	err error
+}
+
+func (m *mockOperationQueue) Remove(uint) (operation.QueuedOperationsAtTime, func() uint, func(), error) {
+	return nil, nil, nil, m.err
+}
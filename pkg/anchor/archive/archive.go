@@ -0,0 +1,395 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package archive exports the complete anchor history of a DID to a CAR (IPLD Content Archive) file and imports
+// such a file into the local CAS.
+//
+// The root block of the archive is a JSON manifest which contains the DID suffix and the hashlinks of the DID's
+// anchors (oldest first). The remaining blocks contain the anchors along with the Sidetree core index, core proof,
+// provisional index, provisional proof and chunk files that they reference. Since each block is addressed by a CID,
+// the archive is self-verifying.
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	gocid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	"github.com/trustbloc/orb/pkg/didanchor"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+var logger = log.New("anchor-archive")
+
+// ErrDIDNotFound is returned from Export if there are no anchors for the given DID.
+var ErrDIDNotFound = errors.New("DID not found")
+
+// Manifest is the root block of an archive.
+type Manifest struct {
+	// Suffix is the suffix of the DID.
+	Suffix string `json:"suffix"`
+	// Anchors contains the hashlinks of the anchors of the DID, oldest first.
+	Anchors []string `json:"anchors"`
+}
+
+// ImportResult contains the outcome of an import.
+type ImportResult struct {
+	// Suffix is the suffix of the imported DID.
+	Suffix string `json:"suffix"`
+	// Anchors is the number of anchors of the DID.
+	Anchors int `json:"anchors"`
+	// Blocks is the number of blocks that were written to the CAS.
+	Blocks int `json:"blocks"`
+}
+
+type didAnchorStore interface {
+	Get(suffix string) (string, error)
+}
+
+type anchorGraph interface {
+	GetDidAnchors(hl, suffix string) ([]graph.Anchor, error)
+}
+
+type casResolver interface {
+	Resolve(webCASURL *url.URL, hl string, data []byte) ([]byte, string, error)
+}
+
+type casWriter interface {
+	Write(content []byte) (string, error)
+}
+
+type didPublisher interface {
+	PublishDID(did string) error
+}
+
+// Providers contains the providers required by the archiver.
+type Providers struct {
+	DIDAnchors             didAnchorStore
+	AnchorGraph            anchorGraph
+	CASResolver            casResolver
+	CASWriter              casWriter
+	ProtocolClientProvider protocol.ClientProvider
+	Publisher              didPublisher
+}
+
+// Archiver exports and imports the anchor history of a DID.
+type Archiver struct {
+	*Providers
+}
+
+// New returns a new archiver.
+func New(providers *Providers) *Archiver {
+	return &Archiver{
+		Providers: providers,
+	}
+}
+
+type block struct {
+	cid  gocid.Cid
+	data []byte
+}
+
+// Export writes a CAR file containing the anchors of the given DID, along with the Sidetree files that they
+// reference, to the given writer. The ID may either be a DID or a DID suffix. ErrDIDNotFound is returned if
+// no anchors exist for the DID.
+//
+// All of the content is retrieved before anything is written so that an error results in no output.
+func (a *Archiver) Export(id string, w io.Writer) error {
+	suffix := getSuffix(id)
+
+	latest, err := a.DIDAnchors.Get(suffix)
+	if err != nil {
+		if errors.Is(err, didanchor.ErrDataNotFound) {
+			return ErrDIDNotFound
+		}
+
+		return fmt.Errorf("get latest anchor for DID [%s]: %w", suffix, err)
+	}
+
+	anchors, err := a.AnchorGraph.GetDidAnchors(latest, suffix)
+	if err != nil {
+		return fmt.Errorf("get anchors for DID [%s]: %w", suffix, err)
+	}
+
+	manifest := &Manifest{Suffix: suffix}
+
+	var uris []string
+
+	for _, anchor := range anchors {
+		manifest.Anchors = append(manifest.Anchors, anchor.CID)
+
+		payload, err := util.GetAnchorSubject(anchor.Info)
+		if err != nil {
+			return fmt.Errorf("get subject of anchor [%s]: %w", anchor.CID, err)
+		}
+
+		fileURIs, err := util.GetSidetreeFileURIs(payload, a.ProtocolClientProvider, a.read)
+		if err != nil {
+			return fmt.Errorf("get Sidetree files of anchor [%s]: %w", anchor.CID, err)
+		}
+
+		uris = append(uris, anchor.CID)
+		uris = append(uris, fileURIs...)
+	}
+
+	blocks, err := a.getBlocks(uris)
+	if err != nil {
+		return err
+	}
+
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("marshal manifest: %w", err)
+	}
+
+	root, err := newCID(manifestBytes)
+	if err != nil {
+		return fmt.Errorf("create CID for manifest: %w", err)
+	}
+
+	cw, err := car.NewWriter(w, root)
+	if err != nil {
+		return err
+	}
+
+	if err := cw.Put(root, manifestBytes); err != nil {
+		return err
+	}
+
+	for _, b := range blocks {
+		if err := cw.Put(b.cid, b.data); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Exported %d anchors and %d blocks for DID [%s]", len(anchors), len(blocks), suffix)
+
+	return nil
+}
+
+// Import reads a CAR file that was produced by Export, writes each block to the CAS and then publishes the DID
+// to the observer so that the anchors are processed. A bad request error is returned if the archive is invalid.
+func (a *Archiver) Import(r io.Reader) (*ImportResult, error) {
+	cr, err := car.NewReader(r)
+	if err != nil {
+		return nil, orberrors.NewBadRequest(err)
+	}
+
+	root := cr.Roots()[0]
+
+	var manifestBytes []byte
+
+	imported := make(map[string]struct{})
+
+	for {
+		cid, data, err := cr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, orberrors.NewBadRequest(err)
+		}
+
+		if cid.Equals(root) {
+			manifestBytes = data
+
+			continue
+		}
+
+		key, err := a.write(cid, data)
+		if err != nil {
+			return nil, err
+		}
+
+		imported[key] = struct{}{}
+	}
+
+	manifest, err := parseManifest(manifestBytes)
+	if err != nil {
+		return nil, orberrors.NewBadRequest(err)
+	}
+
+	for _, hl := range manifest.Anchors {
+		if _, ok := imported[toMultihash(hl)]; !ok {
+			return nil, orberrors.NewBadRequest(fmt.Errorf("anchor [%s] is not included in the archive", hl))
+		}
+	}
+
+	latest := manifest.Anchors[len(manifest.Anchors)-1]
+
+	if err := a.Publisher.PublishDID(latest + ":" + manifest.Suffix); err != nil {
+		return nil, fmt.Errorf("publish DID [%s]: %w", manifest.Suffix, err)
+	}
+
+	logger.Infof("Imported %d anchors and %d blocks for DID [%s]", len(manifest.Anchors), len(imported),
+		manifest.Suffix)
+
+	return &ImportResult{
+		Suffix:  manifest.Suffix,
+		Anchors: len(manifest.Anchors),
+		Blocks:  len(imported),
+	}, nil
+}
+
+func (a *Archiver) getBlocks(uris []string) ([]*block, error) {
+	var blocks []*block
+
+	exists := make(map[string]struct{})
+
+	for _, uri := range uris {
+		cid, err := toCID(uri)
+		if err != nil {
+			return nil, fmt.Errorf("get CID for [%s]: %w", uri, err)
+		}
+
+		if _, ok := exists[cid.KeyString()]; ok {
+			continue
+		}
+
+		exists[cid.KeyString()] = struct{}{}
+
+		data, _, err := a.CASResolver.Resolve(nil, uri, nil)
+		if err != nil {
+			return nil, fmt.Errorf("resolve [%s]: %w", uri, err)
+		}
+
+		blocks = append(blocks, &block{cid: cid, data: data})
+	}
+
+	return blocks, nil
+}
+
+// read reads a Sidetree file. Nil is returned if the file isn't found, in which case the export fails
+// when the file is subsequently retrieved.
+func (a *Archiver) read(uri string) ([]byte, error) {
+	data, _, err := a.CASResolver.Resolve(nil, uri, nil)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("resolve [%s]: %w", uri, err)
+	}
+
+	return data, nil
+}
+
+// write writes the given block to the CAS and ensures that the CAS address matches the block's CID.
+// The multihash of the block is returned.
+func (a *Archiver) write(cid gocid.Cid, data []byte) (string, error) {
+	expected, err := multihash.CIDToMultihash(cid.String())
+	if err != nil {
+		return "", orberrors.NewBadRequest(fmt.Errorf("get multihash of CID [%s]: %w", cid, err))
+	}
+
+	address, err := a.CASWriter.Write(data)
+	if err != nil {
+		return "", fmt.Errorf("write block [%s] to CAS: %w", cid, err)
+	}
+
+	if actual := toMultihash(address); actual != expected {
+		return "", orberrors.NewBadRequest(fmt.Errorf("CAS address [%s] of block does not match CID [%s]",
+			address, cid))
+	}
+
+	return expected, nil
+}
+
+func parseManifest(manifestBytes []byte) (*Manifest, error) {
+	if manifestBytes == nil {
+		return nil, errors.New("manifest is not included in the archive")
+	}
+
+	manifest := &Manifest{}
+
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, fmt.Errorf("unmarshal manifest: %w", err)
+	}
+
+	if manifest.Suffix == "" {
+		return nil, errors.New("DID suffix is missing from manifest")
+	}
+
+	if len(manifest.Anchors) == 0 {
+		return nil, errors.New("anchors are missing from manifest")
+	}
+
+	return manifest, nil
+}
+
+// getSuffix returns the suffix of the given ID, which may either be a DID or a DID suffix.
+func getSuffix(id string) string {
+	return id[strings.LastIndex(id, ":")+1:]
+}
+
+// toCID returns the CID for the given URI, which may be a hashlink, a CID or a multibase-encoded multihash.
+func toCID(uri string) (gocid.Cid, error) {
+	if strings.HasPrefix(uri, hashlink.HLPrefix) {
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(uri)
+		if err != nil {
+			return gocid.Undef, err
+		}
+
+		uri = resourceHash
+	}
+
+	if multihash.IsValidCID(uri) {
+		return gocid.Decode(uri)
+	}
+
+	cid, err := multihash.ToV1CID(uri)
+	if err != nil {
+		return gocid.Undef, err
+	}
+
+	return gocid.Decode(cid)
+}
+
+// toMultihash returns the multibase-encoded multihash of the given CAS address, which may be a hashlink or a CID.
+func toMultihash(address string) string {
+	if strings.HasPrefix(address, hashlink.HLPrefix) {
+		resourceHash, err := hashlink.GetResourceHashFromHashLink(address)
+		if err != nil {
+			return address
+		}
+
+		return resourceHash
+	}
+
+	if multihash.IsValidCID(address) {
+		resourceHash, err := multihash.CIDToMultihash(address)
+		if err != nil {
+			return address
+		}
+
+		return resourceHash
+	}
+
+	return address
+}
+
+func newCID(data []byte) (gocid.Cid, error) {
+	return gocid.Prefix{
+		Version:  1,
+		Codec:    gocid.Raw,
+		MhType:   mh.SHA2_256,
+		MhLength: -1,
+	}.Sum(data)
+}
@@ -0,0 +1,404 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/graph"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/store/didanchor"
+)
+
+const (
+	casLink   = "https://domain.com/cas"
+	namespace = "did:orb"
+	suffix    = "suffix1"
+	gzipAlg   = "GZIP"
+)
+
+func TestArchiver_ExportImport(t *testing.T) {
+	source := newTestEnv(t)
+
+	anchors := source.addAnchors()
+
+	t.Run("Success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		require.NoError(t, source.archiver.Export(namespace+":uAAA:"+suffix, buf))
+
+		target := newTestEnv(t)
+
+		result, err := target.archiver.Import(buf)
+		require.NoError(t, err)
+		require.Equal(t, suffix, result.Suffix)
+		require.Equal(t, 2, result.Anchors)
+		require.Equal(t, 8, result.Blocks)
+
+		require.Equal(t, []string{anchors[1] + ":" + suffix}, target.publisher.dids)
+
+		// The complete history must now be available from the target's CAS.
+		didAnchors, err := target.graph.GetDidAnchors(anchors[1], suffix)
+		require.NoError(t, err)
+		require.Len(t, didAnchors, 2)
+		require.Equal(t, anchors[0], didAnchors[0].CID)
+		require.Equal(t, anchors[1], didAnchors[1].CID)
+	})
+
+	t.Run("Export - DID not found", func(t *testing.T) {
+		err := source.archiver.Export("unknown", &bytes.Buffer{})
+		require.True(t, errors.Is(err, ErrDIDNotFound))
+	})
+
+	t.Run("Export - missing file", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		anchor := env.addAnchor(&subject.Payload{
+			CoreIndex:       "hl:uEiCYtJVQpIbd4ADaWnRvOBIvaTP3kBNGEH4dkn0ew1S1sQ",
+			Namespace:       namespace,
+			Version:         1,
+			PreviousAnchors: map[string]string{suffix: ""},
+		})
+
+		require.NoError(t, env.didAnchors.PutBulk([]string{suffix}, anchor))
+
+		buf := &bytes.Buffer{}
+
+		err := env.archiver.Export(suffix, buf)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		require.Zero(t, buf.Len())
+	})
+
+	t.Run("Export - anchor graph error", func(t *testing.T) {
+		errExpected := errors.New("injected anchor graph error")
+
+		env := newTestEnv(t)
+		env.archiver.AnchorGraph = &mockAnchorGraph{err: errExpected}
+
+		require.NoError(t, env.didAnchors.PutBulk([]string{suffix}, "hl:uEiCYtJVQpIbd4ADaWnRvOBIvaTP3kBNGEH4dkn0ew1S1sQ"))
+
+		err := env.archiver.Export(suffix, &bytes.Buffer{})
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Import - invalid archive", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		_, err := env.archiver.Import(strings.NewReader("invalid"))
+		require.True(t, orberrors.IsBadRequest(err))
+	})
+
+	t.Run("Import - missing manifest", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		data := []byte("data")
+
+		cid, err := newCID(data)
+		require.NoError(t, err)
+
+		manifestCID, err := newCID([]byte("manifest"))
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+
+		w, err := car.NewWriter(buf, manifestCID)
+		require.NoError(t, err)
+		require.NoError(t, w.Put(cid, data))
+
+		_, err = env.archiver.Import(buf)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "manifest is not included in the archive")
+	})
+
+	t.Run("Import - missing anchor", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		buf := newArchive(t, &Manifest{
+			Suffix:  suffix,
+			Anchors: []string{"hl:uEiCYtJVQpIbd4ADaWnRvOBIvaTP3kBNGEH4dkn0ew1S1sQ"},
+		})
+
+		_, err := env.archiver.Import(buf)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "is not included in the archive")
+		require.Empty(t, env.publisher.dids)
+	})
+
+	t.Run("Import - invalid manifest", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		_, err := env.archiver.Import(newArchive(t, &Manifest{Anchors: []string{"hl:xxx"}}))
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "DID suffix is missing from manifest")
+
+		_, err = env.archiver.Import(newArchive(t, &Manifest{Suffix: suffix}))
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "anchors are missing from manifest")
+	})
+
+	t.Run("Import - CAS write error", func(t *testing.T) {
+		errExpected := errors.New("injected write error")
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, source.archiver.Export(suffix, buf))
+
+		env := newTestEnv(t)
+		env.archiver.CASWriter = &mockCASWriter{err: errExpected}
+
+		_, err := env.archiver.Import(buf)
+		require.True(t, errors.Is(err, errExpected))
+		require.False(t, orberrors.IsBadRequest(err))
+	})
+
+	t.Run("Import - CAS address mismatch", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, source.archiver.Export(suffix, buf))
+
+		env := newTestEnv(t)
+		env.archiver.CASWriter = &mockCASWriter{address: "hl:uEiCYtJVQpIbd4ADaWnRvOBIvaTP3kBNGEH4dkn0ew1S1sQ"}
+
+		_, err := env.archiver.Import(buf)
+		require.True(t, orberrors.IsBadRequest(err))
+		require.Contains(t, err.Error(), "does not match CID")
+	})
+
+	t.Run("Import - publish error", func(t *testing.T) {
+		errExpected := errors.New("injected publish error")
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, source.archiver.Export(suffix, buf))
+
+		env := newTestEnv(t)
+		env.publisher.err = errExpected
+
+		_, err := env.archiver.Import(buf)
+		require.True(t, errors.Is(err, errExpected))
+	})
+}
+
+func TestToCID(t *testing.T) {
+	const resourceHash = "uEiCYtJVQpIbd4ADaWnRvOBIvaTP3kBNGEH4dkn0ew1S1sQ"
+
+	cid1, err := toCID("hl:" + resourceHash + ":uoQ-BeEJpcGZzOi8vYmFma3JlaWV5d3NrdmJqZWc")
+	require.NoError(t, err)
+
+	cid2, err := toCID(resourceHash)
+	require.NoError(t, err)
+	require.True(t, cid1.Equals(cid2))
+
+	cid3, err := toCID(cid1.String())
+	require.NoError(t, err)
+	require.True(t, cid1.Equals(cid3))
+
+	require.Equal(t, resourceHash, toMultihash(cid1.String()))
+
+	_, err = toCID("invalid")
+	require.Error(t, err)
+}
+
+type testEnv struct {
+	t          *testing.T
+	archiver   *Archiver
+	cas        *cas.CAS
+	didAnchors *didanchor.Store
+	graph      *graph.Graph
+	publisher  *mockPublisher
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	provider := mem.NewProvider()
+
+	casStore, err := cas.New(provider, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	didAnchors, err := didanchor.New(provider)
+	require.NoError(t, err)
+
+	casResolver := &localResolver{cas: casStore}
+
+	anchorGraph := graph.New(&graph.Providers{
+		CasWriter:   casStore,
+		CasResolver: casResolver,
+		DocLoader:   testutil.GetLoader(t),
+	})
+
+	publisher := &mockPublisher{}
+
+	return &testEnv{
+		t:          t,
+		cas:        casStore,
+		didAnchors: didAnchors,
+		graph:      anchorGraph,
+		publisher:  publisher,
+		archiver: New(&Providers{
+			DIDAnchors:             didAnchors,
+			AnchorGraph:            anchorGraph,
+			CASResolver:            casResolver,
+			CASWriter:              casStore,
+			ProtocolClientProvider: orbmocks.NewMockProtocolClientProvider(),
+			Publisher:              publisher,
+		}),
+	}
+}
+
+// addAnchors adds two anchors for the DID. The first anchor has no attachments so its files are found by parsing
+// the index files. The returned hashlinks are ordered oldest first.
+func (env *testEnv) addAnchors() []string {
+	chunk := env.writeFile([]byte(`{"deltas":[]}`))
+	provisionalProof := env.writeFile([]byte(`{"operations":{}}`))
+	provisionalIndex := env.writeJSONFile(&models.ProvisionalIndexFile{
+		ProvisionalProofFileURI: provisionalProof,
+		Chunks:                  []models.Chunk{{ChunkFileURI: chunk}},
+	})
+	coreProof := env.writeFile([]byte(`{"operations":{"recover":[]}}`))
+	coreIndex1 := env.writeJSONFile(&models.CoreIndexFile{
+		ProvisionalIndexFileURI: provisionalIndex,
+		CoreProofFileURI:        coreProof,
+	})
+
+	anchor1 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex1,
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix: ""},
+	})
+
+	coreIndex2 := env.writeFile([]byte(`{"id":"2"}`))
+
+	anchor2 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex2,
+		Attachments:     []string{coreIndex2},
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix: anchor1},
+	})
+
+	require.NoError(env.t, env.didAnchors.PutBulk([]string{suffix}, anchor2))
+
+	// Content that isn't referenced by the DID.
+	env.writeFile([]byte(`{"id":"3"}`))
+
+	return []string{anchor1, anchor2}
+}
+
+func (env *testEnv) writeJSONFile(file interface{}) string {
+	content, err := json.Marshal(file)
+	require.NoError(env.t, err)
+
+	return env.writeFile(content)
+}
+
+func (env *testEnv) writeFile(content []byte) string {
+	compressed, err := compression.New(compression.WithDefaultAlgorithms()).Compress(gzipAlg, content)
+	require.NoError(env.t, err)
+
+	hl, err := env.cas.Write(compressed)
+	require.NoError(env.t, err)
+
+	return hl
+}
+
+func (env *testEnv) addAnchor(payload *subject.Payload) string {
+	act, err := activity.BuildActivityFromPayload(payload)
+	require.NoError(env.t, err)
+
+	hl, err := env.graph.Add(&verifiable.Credential{
+		Types:   []string{"VerifiableCredential"},
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Subject: act,
+		Issuer:  verifiable.Issuer{ID: "http://peer1.com"},
+		Issued:  &util.TimeWithTrailingZeroMsec{Time: time.Now()},
+	})
+	require.NoError(env.t, err)
+
+	return hl
+}
+
+func newArchive(t *testing.T, manifest *Manifest) *bytes.Buffer {
+	t.Helper()
+
+	manifestBytes, err := json.Marshal(manifest)
+	require.NoError(t, err)
+
+	root, err := newCID(manifestBytes)
+	require.NoError(t, err)
+
+	buf := &bytes.Buffer{}
+
+	w, err := car.NewWriter(buf, root)
+	require.NoError(t, err)
+	require.NoError(t, w.Put(root, manifestBytes))
+
+	return buf
+}
+
+// localResolver resolves content from the local CAS only.
+type localResolver struct {
+	cas *cas.CAS
+}
+
+func (r *localResolver) Resolve(_ *url.URL, hl string, _ []byte) ([]byte, string, error) {
+	data, err := r.cas.Read(toMultihash(hl))
+	if err != nil {
+		return nil, "", err
+	}
+
+	return data, hl, nil
+}
+
+type mockAnchorGraph struct {
+	err error
+}
+
+func (m *mockAnchorGraph) GetDidAnchors(string, string) ([]graph.Anchor, error) {
+	return nil, m.err
+}
+
+type mockCASWriter struct {
+	address string
+	err     error
+}
+
+func (m *mockCASWriter) Write([]byte) (string, error) {
+	return m.address, m.err
+}
+
+type mockPublisher struct {
+	dids []string
+	err  error
+}
+
+func (m *mockPublisher) PublishDID(did string) error {
+	if m.err != nil {
+		return m.err
+	}
+
+	m.dids = append(m.dids, did)
+
+	return nil
+}
@@ -0,0 +1,179 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/anchor/archive"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	exportEndpoint = "/anchor/export"
+	importEndpoint = "/anchor/import"
+	idPathVariable = "id"
+
+	// maxImportSize is the maximum size of an archive that may be imported.
+	maxImportSize = 256 * 1024 * 1024
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	notFoundResponse            = "DID not found."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("anchor-archive-rest-handler")
+
+type exporter interface {
+	Export(id string, w io.Writer) error
+}
+
+type importer interface {
+	Import(r io.Reader) (*archive.ImportResult, error)
+}
+
+// Export exports the anchor history of a DID as a CAR file.
+type Export struct {
+	exporter exporter
+}
+
+// NewExport returns a new export handler.
+func NewExport(e exporter) *Export {
+	return &Export{exporter: e}
+}
+
+// Path returns the HTTP REST endpoint for the export service.
+func (h *Export) Path() string {
+	return fmt.Sprintf("%s/{%s}", exportEndpoint, idPathVariable)
+}
+
+// Method returns the HTTP REST method for the export service.
+func (h *Export) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the export service.
+func (h *Export) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Export) handle(w http.ResponseWriter, req *http.Request) {
+	id := mux.Vars(req)[idPathVariable]
+	if id == "" {
+		writeResponse(w, exportEndpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	// The archive is written to a buffer so that an error may be returned if the export fails.
+	buf := &bytes.Buffer{}
+
+	if err := h.exporter.Export(id, buf); err != nil {
+		if errors.Is(err, archive.ErrDIDNotFound) {
+			writeResponse(w, exportEndpoint, http.StatusNotFound, []byte(notFoundResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error exporting DID [%s]: %s", exportEndpoint, id, err)
+
+		writeResponse(w, exportEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", car.ContentType)
+
+	writeResponse(w, exportEndpoint, http.StatusOK, buf.Bytes())
+}
+
+// Import imports a CAR file that was produced by the export service.
+type Import struct {
+	importer importer
+	marshal  func(interface{}) ([]byte, error)
+}
+
+// NewImport returns a new import handler.
+func NewImport(i importer) *Import {
+	return &Import{
+		importer: i,
+		marshal:  json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the import service.
+func (h *Import) Path() string {
+	return importEndpoint
+}
+
+// Method returns the HTTP REST method for the import service.
+func (h *Import) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the import service.
+func (h *Import) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Import) handle(w http.ResponseWriter, req *http.Request) {
+	result, err := h.importer.Import(http.MaxBytesReader(w, req.Body, maxImportSize))
+	if err != nil {
+		if orberrors.IsBadRequest(err) {
+			logger.Debugf("[%s] Invalid archive: %s", importEndpoint, err)
+
+			writeResponse(w, importEndpoint, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error importing archive: %s", importEndpoint, err)
+
+		writeResponse(w, importEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	resultBytes, err := h.marshal(result)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling result: %s", importEndpoint, err)
+
+		writeResponse(w, importEndpoint, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, importEndpoint, http.StatusOK, resultBytes)
+}
+
+func writeResponse(w http.ResponseWriter, endpoint string, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote %d bytes", endpoint, len(body))
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/archive"
+	"github.com/trustbloc/orb/pkg/cas/car"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const suffix = "suffix1"
+
+func TestNewExport(t *testing.T) {
+	h := NewExport(&mockArchiver{})
+	require.NotNil(t, h)
+	require.Equal(t, "/anchor/export/{id}", h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestNewImport(t *testing.T) {
+	h := NewImport(&mockArchiver{})
+	require.NotNil(t, h)
+	require.Equal(t, importEndpoint, h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestExport_Handler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		a := &mockArchiver{content: "car file"}
+
+		h := NewExport(a)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, newExportRequest(suffix))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, car.ContentType, result.Header.Get("Content-Type"))
+		require.Equal(t, suffix, a.id)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, "car file", string(respBytes))
+	})
+
+	t.Run("Missing ID", func(t *testing.T) {
+		a := &mockArchiver{}
+
+		h := NewExport(a)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, newExportRequest(""))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.False(t, a.invoked)
+	})
+
+	t.Run("DID not found", func(t *testing.T) {
+		h := NewExport(&mockArchiver{err: archive.ErrDIDNotFound})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, newExportRequest(suffix))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusNotFound, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Export error", func(t *testing.T) {
+		h := NewExport(&mockArchiver{content: "partial", err: errors.New("injected error")})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, newExportRequest(suffix))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, internalServerErrorResponse, string(respBytes))
+	})
+}
+
+func TestImport_Handler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		h := NewImport(&mockArchiver{result: &archive.ImportResult{Suffix: suffix, Anchors: 2, Blocks: 5}})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, importEndpoint, strings.NewReader("car file")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		importResult := &archive.ImportResult{}
+		require.NoError(t, json.Unmarshal(respBytes, importResult))
+		require.Equal(t, suffix, importResult.Suffix)
+		require.Equal(t, 2, importResult.Anchors)
+		require.Equal(t, 5, importResult.Blocks)
+	})
+
+	t.Run("Invalid archive", func(t *testing.T) {
+		h := NewImport(&mockArchiver{err: orberrors.NewBadRequest(errors.New("invalid archive"))})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, importEndpoint, strings.NewReader("car file")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Import error", func(t *testing.T) {
+		h := NewImport(&mockArchiver{err: fmt.Errorf("import: %w", errors.New("injected error"))})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, importEndpoint, strings.NewReader("car file")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := NewImport(&mockArchiver{result: &archive.ImportResult{}})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, importEndpoint, strings.NewReader("car file")))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+func newExportRequest(id string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, exportEndpoint+"/"+id, nil)
+
+	return mux.SetURLVars(req, map[string]string{idPathVariable: id})
+}
+
+type mockArchiver struct {
+	content string
+	result  *archive.ImportResult
+	err     error
+	id      string
+	invoked bool
+}
+
+func (m *mockArchiver) Export(id string, w io.Writer) error {
+	m.invoked = true
+	m.id = id
+
+	if _, err := w.Write([]byte(m.content)); err != nil {
+		return err
+	}
+
+	return m.err
+}
+
+func (m *mockArchiver) Import(r io.Reader) (*archive.ImportResult, error) {
+	m.invoked = true
+
+	if _, err := ioutil.ReadAll(r); err != nil {
+		return nil, err
+	}
+
+	return m.result, m.err
+}
@@ -9,7 +9,9 @@ package graph
 import (
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/bluele/gcache"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/piprate/json-gold/ld"
 	"github.com/trustbloc/edge-core/pkg/log"
@@ -21,9 +23,25 @@ import (
 
 var logger = log.New("anchor-graph")
 
+const (
+	defaultCacheSize          = 1000
+	defaultCacheExpiration    = time.Hour
+	defaultPrefetchConcurrent = 10
+	defaultPrefetchBatchSize  = 100
+)
+
 // Graph manages anchor graph.
 type Graph struct {
 	*Providers
+
+	cache      gcache.Cache
+	metrics    metricsProvider
+	prefetchCh chan struct{}
+
+	cacheSize          int
+	cacheExpiration    time.Duration
+	prefetchConcurrent int
+	prefetchBatchSize  int
 }
 
 // Providers for anchor graph.
@@ -34,17 +52,89 @@ type Providers struct {
 	DocLoader   ld.DocumentLoader
 }
 
+type metricsProvider interface {
+	AnchorGraphIncrementCacheHitCount()
+	AnchorGraphIncrementCacheMissCount()
+}
+
+// Option is an anchor graph option.
+type Option func(g *Graph)
+
+// WithCacheSize sets the maximum number of parsed anchor credentials that are cached. Defaults to 1000.
+func WithCacheSize(size int) Option {
+	return func(g *Graph) {
+		g.cacheSize = size
+	}
+}
+
+// WithCacheExpiration sets the time after which a parsed anchor credential is evicted from the cache.
+// Defaults to one hour.
+func WithCacheExpiration(expiration time.Duration) Option {
+	return func(g *Graph) {
+		g.cacheExpiration = expiration
+	}
+}
+
+// WithMaxConcurrentPrefetch sets the maximum number of previous anchors (or batches of previous anchors) that are
+// read concurrently in the background. Defaults to 10. If set to 0 then previous anchors aren't prefetched.
+func WithMaxConcurrentPrefetch(limit int) Option {
+	return func(g *Graph) {
+		g.prefetchConcurrent = limit
+	}
+}
+
+// WithMaxPrefetchBatchSize sets the maximum number of previous anchors that are prefetched for each anchor
+// in a DID's chain. Defaults to 100.
+func WithMaxPrefetchBatchSize(size int) Option {
+	return func(g *Graph) {
+		g.prefetchBatchSize = size
+	}
+}
+
+// WithMetrics sets the metrics provider.
+func WithMetrics(metrics metricsProvider) Option {
+	return func(g *Graph) {
+		g.metrics = metrics
+	}
+}
+
 // New creates new graph manager.
-func New(providers *Providers) *Graph {
-	return &Graph{
-		Providers: providers,
+func New(providers *Providers, opts ...Option) *Graph {
+	g := &Graph{
+		Providers:          providers,
+		metrics:            &noopMetrics{},
+		cacheSize:          defaultCacheSize,
+		cacheExpiration:    defaultCacheExpiration,
+		prefetchConcurrent: defaultPrefetchConcurrent,
+		prefetchBatchSize:  defaultPrefetchBatchSize,
+	}
+
+	for _, opt := range opts {
+		opt(g)
 	}
+
+	g.prefetchCh = make(chan struct{}, g.prefetchConcurrent)
+
+	g.cache = gcache.New(g.cacheSize).LRU().
+		Expiration(g.cacheExpiration).
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			g.metrics.AnchorGraphIncrementCacheMissCount()
+
+			return g.read(key.(string))
+		}).Build()
+
+	return g
 }
 
 type casResolver interface {
 	Resolve(webCASURL *url.URL, hl string, data []byte) ([]byte, string, error)
 }
 
+// batchResolver is optionally implemented by the CAS resolver in order to retrieve multiple anchors in one request.
+type batchResolver interface {
+	ResolveBatch(hashLinks []string) ([]string, error)
+}
+
 type casWriter interface {
 	Write(content []byte) (string, error)
 }
@@ -72,8 +162,21 @@ func (g *Graph) Add(vc *verifiable.Credential) (string, error) { //nolint:interf
 	return hl, nil
 }
 
-// Read reads anchor.
+// Read reads anchor. Parsed anchors are cached by hashlink, so the returned credential must not be modified.
 func (g *Graph) Read(hl string) (*verifiable.Credential, error) {
+	if g.cache.Has(hl) {
+		g.metrics.AnchorGraphIncrementCacheHitCount()
+	}
+
+	vc, err := g.cache.Get(hl)
+	if err != nil {
+		return nil, err
+	}
+
+	return vc.(*verifiable.Credential), nil
+}
+
+func (g *Graph) read(hl string) (*verifiable.Credential, error) {
 	anchorBytes, _, err := g.CasResolver.Resolve(nil, hl, nil)
 	if err != nil {
 		return nil, err
@@ -93,11 +196,18 @@ type Anchor struct {
 }
 
 // GetDidAnchors returns all anchors that are referencing did suffix starting from hl.
+//
+// As each anchor is read, the previous anchors of the other DIDs in the anchor (up to the maximum batch size) are
+// prefetched in the background: if the CAS resolver supports batch requests then they're retrieved in a single
+// batch, and they're then read (and parsed) concurrently into the cache. The anchors of the other DIDs in the same
+// anchor are then usually already in the cache (or being loaded) when they're subsequently processed.
 func (g *Graph) GetDidAnchors(hl, suffix string) ([]Anchor, error) {
 	var refs []Anchor
 
 	logger.Debugf("getting did anchors for hl[%s], suffix[%s]", hl, suffix)
 
+	prefetched := make(map[string]struct{})
+
 	cur := hl
 	ok := true
 
@@ -116,6 +226,8 @@ func (g *Graph) GetDidAnchors(hl, suffix string) ([]Anchor, error) {
 
 		previousAnchors := payload.PreviousAnchors
 
+		g.prefetch(previousAnchors, suffix, prefetched)
+
 		cur, ok = previousAnchors[suffix]
 		if ok && cur == "" { // create
 			break
@@ -125,6 +237,105 @@ func (g *Graph) GetDidAnchors(hl, suffix string) ([]Anchor, error) {
 	return reverseOrder(refs), nil
 }
 
+// prefetch retrieves the given previous anchors in the background, in a batch (if supported by the CAS resolver),
+// and then loads them into the cache concurrently. The previous anchor of the given suffix isn't prefetched since
+// it's read immediately. Nothing is prefetched if the maximum number of concurrent prefetches has been reached.
+func (g *Graph) prefetch(previousAnchors map[string]string, suffix string, prefetched map[string]struct{}) {
+	var hashLinks []string
+
+	batch := make(map[string]struct{})
+
+	for _, previous := range previousAnchors {
+		if len(hashLinks) == g.prefetchBatchSize {
+			break
+		}
+
+		if previous == "" || previous == previousAnchors[suffix] {
+			continue
+		}
+
+		if _, exists := prefetched[previous]; exists {
+			continue
+		}
+
+		if _, exists := batch[previous]; exists {
+			continue
+		}
+
+		batch[previous] = struct{}{}
+
+		hashLinks = append(hashLinks, previous)
+	}
+
+	if len(hashLinks) == 0 {
+		return
+	}
+
+	select {
+	case g.prefetchCh <- struct{}{}:
+	default:
+		logger.Debugf("Not prefetching %d anchors since the maximum number of concurrent prefetches has "+
+			"been reached", len(hashLinks))
+
+		return
+	}
+
+	for _, hl := range hashLinks {
+		prefetched[hl] = struct{}{}
+	}
+
+	go func() {
+		g.prefetchBatch(hashLinks)
+
+		<-g.prefetchCh
+
+		for _, hl := range hashLinks {
+			g.prefetchAsync(hl)
+		}
+	}()
+}
+
+func (g *Graph) prefetchBatch(hashLinks []string) {
+	br, ok := g.CasResolver.(batchResolver)
+	if !ok {
+		return
+	}
+
+	unresolved, err := br.ResolveBatch(hashLinks)
+	if err != nil {
+		// Not critical since the anchors will be resolved individually.
+		logger.Debugf("Error prefetching %d anchors: %s", len(hashLinks), err)
+	}
+
+	logger.Debugf("Prefetched %d of %d previous anchors", len(hashLinks)-len(unresolved), len(hashLinks))
+}
+
+// prefetchAsync loads the given anchor into the cache in the background. The anchor isn't prefetched if the
+// maximum number of concurrent prefetches has been reached, in which case it's loaded when it's read.
+func (g *Graph) prefetchAsync(hl string) {
+	if g.cache.Has(hl) {
+		return
+	}
+
+	select {
+	case g.prefetchCh <- struct{}{}:
+	default:
+		logger.Debugf("Not prefetching anchor [%s] since the maximum number of concurrent prefetches has "+
+			"been reached", hl)
+
+		return
+	}
+
+	go func() {
+		defer func() { <-g.prefetchCh }()
+
+		if _, err := g.cache.Get(hl); err != nil {
+			// Not critical since the error will be returned when the anchor is read.
+			logger.Debugf("Error prefetching anchor [%s]: %s", hl, err)
+		}
+	}()
+}
+
 func reverseOrder(original []Anchor) []Anchor {
 	var reversed []Anchor
 
@@ -134,3 +345,8 @@ func reverseOrder(original []Anchor) []Anchor {
 
 	return reversed
 }
+
+type noopMetrics struct{}
+
+func (m *noopMetrics) AnchorGraphIncrementCacheHitCount()  {}
+func (m *noopMetrics) AnchorGraphIncrementCacheMissCount() {}
@@ -7,6 +7,10 @@ SPDX-License-Identifier: Apache-2.0
 package graph
 
 import (
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,7 +20,7 @@ import (
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/stretchr/testify/require"
 
-	apmocks "github.com/trustbloc/orb/pkg/activitypub/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
 	"github.com/trustbloc/orb/pkg/anchor/activity"
 	"github.com/trustbloc/orb/pkg/anchor/subject"
 	vcutil "github.com/trustbloc/orb/pkg/anchor/util"
@@ -41,15 +45,15 @@ func TestNew(t *testing.T) {
 }
 
 func TestGraph_Add(t *testing.T) {
-	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &metricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &mockMetricsProvider{}, 0, nil)
 	require.NoError(t, err)
 
 	providers := &Providers{
 		CasWriter: casClient,
 		CasResolver: casresolver.New(casClient, nil,
 			casresolver.NewWebCASResolver(
-				&apmocks.HTTPTransport{}, webfingerclient.New(), "https"),
-			&metricsProvider{}),
+				transport.Default(), webfingerclient.New(), "https"),
+			&mockMetricsProvider{}),
 		Pkf:       pubKeyFetcherFnc,
 		DocLoader: testutil.GetLoader(t),
 	}
@@ -67,7 +71,7 @@ func TestGraph_Add(t *testing.T) {
 }
 
 func TestGraph_Read(t *testing.T) {
-	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &metricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &mockMetricsProvider{}, 0, nil)
 
 	require.NoError(t, err)
 
@@ -75,8 +79,8 @@ func TestGraph_Read(t *testing.T) {
 		CasWriter: casClient,
 		CasResolver: casresolver.New(casClient, nil,
 			casresolver.NewWebCASResolver(
-				&apmocks.HTTPTransport{}, webfingerclient.New(), "https"),
-			&metricsProvider{}),
+				transport.Default(), webfingerclient.New(), "https"),
+			&mockMetricsProvider{}),
 		Pkf:       pubKeyFetcherFnc,
 		DocLoader: testutil.GetLoader(t),
 	}
@@ -110,7 +114,7 @@ func TestGraph_Read(t *testing.T) {
 }
 
 func TestGraph_GetDidAnchors(t *testing.T) {
-	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &metricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &mockMetricsProvider{}, 0, nil)
 
 	require.NoError(t, err)
 
@@ -118,8 +122,8 @@ func TestGraph_GetDidAnchors(t *testing.T) {
 		CasWriter: casClient,
 		CasResolver: casresolver.New(casClient, nil,
 			casresolver.NewWebCASResolver(
-				&apmocks.HTTPTransport{}, webfingerclient.New(), "https"),
-			&metricsProvider{}),
+				transport.Default(), webfingerclient.New(), "https"),
+			&mockMetricsProvider{}),
 		Pkf:       pubKeyFetcherFnc,
 		DocLoader: testutil.GetLoader(t),
 	}
@@ -183,6 +187,110 @@ func TestGraph_GetDidAnchors(t *testing.T) {
 		require.Equal(t, anchor1HL, didAnchors[0].CID)
 	})
 
+	t.Run("success - previous anchors are prefetched", func(t *testing.T) {
+		br := &mockBatchResolver{Resolver: providers.CasResolver.(*casresolver.Resolver)}
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: br,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		})
+
+		c, err := buildCredential(&subject.Payload{
+			OperationCount:  1,
+			CoreIndex:       "coreIndex-1",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{testDID: ""},
+		})
+		require.NoError(t, err)
+
+		anchor1HL, err := graph.Add(c)
+		require.NoError(t, err)
+
+		c, err = buildCredential(&subject.Payload{
+			OperationCount:  1,
+			CoreIndex:       "coreIndex-other-1",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{"otherDID": ""},
+		})
+		require.NoError(t, err)
+
+		otherAnchorHL, err := graph.Add(c)
+		require.NoError(t, err)
+
+		c, err = buildCredential(&subject.Payload{
+			OperationCount:  3,
+			CoreIndex:       "coreIndex-2",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{
+				testDID: anchor1HL, "otherDID": otherAnchorHL, "otherDID2": otherAnchorHL,
+			},
+		})
+		require.NoError(t, err)
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		didAnchors, err := graph.GetDidAnchors(hl, testDID)
+		require.NoError(t, err)
+		require.Len(t, didAnchors, 2)
+
+		// The previous anchor of the DID being resolved is read directly so only the other anchor is prefetched.
+		require.Eventually(t, func() bool { return len(br.getBatches()) == 1 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, [][]string{{otherAnchorHL}}, br.getBatches())
+	})
+
+	t.Run("success - prefetch batch size is limited", func(t *testing.T) {
+		br := &mockBatchResolver{Resolver: providers.CasResolver.(*casresolver.Resolver)}
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: br,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		}, WithMaxPrefetchBatchSize(2))
+
+		previousAnchors := map[string]string{testDID: ""}
+
+		for i := 0; i < 5; i++ {
+			c, err := buildCredential(&subject.Payload{
+				OperationCount:  1,
+				CoreIndex:       fmt.Sprintf("coreIndex-other-%d", i),
+				Namespace:       testNS,
+				Version:         1,
+				PreviousAnchors: map[string]string{fmt.Sprintf("otherDID%d", i): ""},
+			})
+			require.NoError(t, err)
+
+			otherHL, err := graph.Add(c)
+			require.NoError(t, err)
+
+			previousAnchors[fmt.Sprintf("otherDID%d", i)] = otherHL
+		}
+
+		c, err := buildCredential(&subject.Payload{
+			OperationCount:  6,
+			CoreIndex:       "coreIndex-batch",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: previousAnchors,
+		})
+		require.NoError(t, err)
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		_, err = graph.GetDidAnchors(hl, testDID)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(br.getBatches()) == 1 }, time.Second, 10*time.Millisecond)
+		require.Len(t, br.getBatches()[0], 2)
+	})
+
 	t.Run("success - cid referenced in previous anchor empty (create)", func(t *testing.T) {
 		graph := New(providers)
 
@@ -272,6 +380,181 @@ func TestGraph_GetDidAnchors(t *testing.T) {
 	})
 }
 
+func TestGraph_Cache(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &mockMetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	newResolver := func() *countingResolver {
+		return &countingResolver{
+			Resolver: casresolver.New(casClient, nil,
+				casresolver.NewWebCASResolver(transport.Default(), webfingerclient.New(), "https"),
+				&mockMetricsProvider{}),
+			counts: make(map[string]int),
+		}
+	}
+
+	c, err := buildDefaultCredential()
+	require.NoError(t, err)
+
+	t.Run("success - cache hit", func(t *testing.T) {
+		r := newResolver()
+		metrics := &mockMetricsProvider{}
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: r,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		}, WithMetrics(metrics))
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		vc1, err := graph.Read(hl)
+		require.NoError(t, err)
+
+		vc2, err := graph.Read(hl)
+		require.NoError(t, err)
+		require.True(t, vc1 == vc2)
+
+		require.Equal(t, 1, r.count(hl))
+		require.Equal(t, int32(1), atomic.LoadInt32(&metrics.cacheHits))
+		require.Equal(t, int32(1), atomic.LoadInt32(&metrics.cacheMisses))
+	})
+
+	t.Run("success - cache expiration", func(t *testing.T) {
+		r := newResolver()
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: r,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		}, WithCacheSize(10), WithCacheExpiration(50*time.Millisecond))
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		_, err = graph.Read(hl)
+		require.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = graph.Read(hl)
+		require.NoError(t, err)
+
+		require.Equal(t, 2, r.count(hl))
+	})
+
+	t.Run("success - errors are not cached", func(t *testing.T) {
+		r := newResolver()
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: r,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		})
+
+		_, err := graph.Read("hl:" + nonExistent)
+		require.Error(t, err)
+
+		_, err = graph.Read("hl:" + nonExistent)
+		require.Error(t, err)
+
+		require.Equal(t, 2, r.count("hl:"+nonExistent))
+	})
+
+	t.Run("success - previous anchors of all DIDs are prefetched", func(t *testing.T) {
+		const otherDID = "otherDID"
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: newResolver(),
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		})
+
+		c1, err := buildCredential(&subject.Payload{
+			OperationCount:  1,
+			CoreIndex:       "coreIndex-other-1",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{otherDID: ""},
+		})
+		require.NoError(t, err)
+
+		otherAnchorHL, err := graph.Add(c1)
+		require.NoError(t, err)
+
+		c2, err := buildCredential(&subject.Payload{
+			OperationCount:  2,
+			CoreIndex:       "coreIndex-other-2",
+			Namespace:       testNS,
+			Version:         1,
+			PreviousAnchors: map[string]string{testDID: "", otherDID: otherAnchorHL},
+		})
+		require.NoError(t, err)
+
+		hl, err := graph.Add(c2)
+		require.NoError(t, err)
+
+		anchors, err := graph.GetDidAnchors(hl, testDID)
+		require.NoError(t, err)
+		require.Len(t, anchors, 1)
+
+		require.Eventually(t, func() bool { return graph.cache.Has(otherAnchorHL) }, time.Second,
+			10*time.Millisecond)
+
+		// Prefetch disabled.
+		graph = New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: newResolver(),
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		}, WithMaxConcurrentPrefetch(0))
+
+		_, err = graph.GetDidAnchors(hl, testDID)
+		require.NoError(t, err)
+
+		time.Sleep(50 * time.Millisecond)
+
+		require.False(t, graph.cache.Has(otherAnchorHL))
+	})
+
+	t.Run("success - concurrent reads of an anchor are resolved once", func(t *testing.T) {
+		r := newResolver()
+		r.delay = 50 * time.Millisecond
+
+		graph := New(&Providers{
+			CasWriter:   casClient,
+			CasResolver: r,
+			Pkf:         pubKeyFetcherFnc,
+			DocLoader:   testutil.GetLoader(t),
+		})
+
+		hl, err := graph.Add(c)
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				_, e := graph.Read(hl)
+				require.NoError(t, e)
+			}()
+		}
+
+		wg.Wait()
+
+		require.Equal(t, 1, r.count(hl))
+	})
+}
+
 func buildDefaultCredential() (*verifiable.Credential, error) {
 	previousAnchors := make(map[string]string)
 	previousAnchors["suffix"] = ""
@@ -312,16 +595,80 @@ var pubKeyFetcherFnc = func(issuerID, keyID string) (*verifier.PublicKey, error)
 	return nil, nil
 }
 
-type metricsProvider struct{}
+type mockMetricsProvider struct {
+	cacheHits   int32
+	cacheMisses int32
+}
+
+func (m *mockMetricsProvider) AnchorGraphIncrementCacheHitCount() {
+	atomic.AddInt32(&m.cacheHits, 1)
+}
+
+func (m *mockMetricsProvider) AnchorGraphIncrementCacheMissCount() {
+	atomic.AddInt32(&m.cacheMisses, 1)
+}
+
+func (m *mockMetricsProvider) CASWriteTime(value time.Duration) {
+}
+
+func (m *mockMetricsProvider) CASResolveTime(value time.Duration) {
+}
+
+func (m *mockMetricsProvider) CASIncrementCacheHitCount() {
+}
+
+func (m *mockMetricsProvider) CASReadTime(casType string, value time.Duration) {
+}
+
+func (m *mockMetricsProvider) CASSourceResolveTime(source string, value time.Duration) {
+}
 
-func (m *metricsProvider) CASWriteTime(value time.Duration) {
+func (m *mockMetricsProvider) CASSourceResolveResult(source string, success bool) {
 }
 
-func (m *metricsProvider) CASResolveTime(value time.Duration) {
+type mockBatchResolver struct {
+	*casresolver.Resolver
+
+	mutex   sync.Mutex
+	batches [][]string
 }
 
-func (m *metricsProvider) CASIncrementCacheHitCount() {
+func (r *mockBatchResolver) ResolveBatch(hashLinks []string) ([]string, error) {
+	r.mutex.Lock()
+	r.batches = append(r.batches, hashLinks)
+	r.mutex.Unlock()
+
+	return r.Resolver.ResolveBatch(hashLinks)
 }
 
-func (m *metricsProvider) CASReadTime(casType string, value time.Duration) {
+func (r *mockBatchResolver) getBatches() [][]string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.batches
+}
+
+type countingResolver struct {
+	*casresolver.Resolver
+
+	delay  time.Duration
+	mutex  sync.Mutex
+	counts map[string]int
+}
+
+func (r *countingResolver) Resolve(webCASURL *url.URL, hl string, data []byte) ([]byte, string, error) {
+	r.mutex.Lock()
+	r.counts[hl]++
+	r.mutex.Unlock()
+
+	time.Sleep(r.delay)
+
+	return r.Resolver.Resolve(webCASURL, hl, data)
+}
+
+func (r *countingResolver) count(hl string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.counts[hl]
 }
@@ -0,0 +1,223 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package acknowledgement
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	"github.com/trustbloc/orb/pkg/activitypub/store/storeutil"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+var logger = log.New("anchor-ack-handler")
+
+const (
+	storeName = "anchor-ack"
+	anchorTag = "anchor"
+)
+
+// Acknowledgement contains the details of an acknowledgement (i.e. a 'Like' activity) from a remote
+// Orb service indicating that it has processed one of our anchor events.
+type Acknowledgement struct {
+	Actor             string    `json:"actor"`
+	AnchorRef         string    `json:"anchor"`
+	AdditionalAnchors []string  `json:"additionalAnchors,omitempty"`
+	Acknowledged      time.Time `json:"acknowledged"`
+}
+
+// PropagationStatus contains the propagation status of an anchor, i.e. which services have acknowledged
+// the anchor and which of our followers have not yet acknowledged it.
+type PropagationStatus struct {
+	AnchorRef        string             `json:"anchor"`
+	Acknowledgements []*Acknowledgement `json:"acknowledgements"`
+	Pending          []string           `json:"pending"`
+}
+
+type activityStore interface {
+	QueryReferences(refType store.ReferenceType, query *store.Criteria,
+		opts ...store.QueryOpt) (store.ReferenceIterator, error)
+}
+
+// Handler records the acknowledgements of anchor events from remote Orb services and
+// provides the propagation status of an anchor.
+type Handler struct {
+	serviceIRI    *url.URL
+	store         storage.Store
+	activityStore activityStore
+	marshal       func(v interface{}) ([]byte, error)
+	unmarshal     func(data []byte, v interface{}) error
+}
+
+// New returns a new anchor event acknowledgement handler.
+func New(serviceIRI *url.URL, provider storage.Provider, activityStore activityStore) (*Handler, error) {
+	s, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{anchorTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	return &Handler{
+		serviceIRI:    serviceIRI,
+		store:         s,
+		activityStore: activityStore,
+		marshal:       json.Marshal,
+		unmarshal:     json.Unmarshal,
+	}, nil
+}
+
+// AnchorEventAcknowledged records the acknowledgement of the given anchor by the given actor. If the actor
+// has already acknowledged the anchor then the previous acknowledgement is replaced.
+func (h *Handler) AnchorEventAcknowledged(actor, anchorRef *url.URL, additionalAnchorRefs []*url.URL) error {
+	ack := &Acknowledgement{
+		Actor:        actor.String(),
+		AnchorRef:    anchorRef.String(),
+		Acknowledged: time.Now(),
+	}
+
+	for _, ref := range additionalAnchorRefs {
+		ack.AdditionalAnchors = append(ack.AdditionalAnchors, ref.String())
+	}
+
+	ackBytes, err := h.marshal(ack)
+	if err != nil {
+		return fmt.Errorf("marshal acknowledgement: %w", err)
+	}
+
+	err = h.store.Put(newKey(anchorRef, actor), ackBytes,
+		storage.Tag{Name: anchorTag, Value: encode(anchorRef.String())})
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store acknowledgement of anchor [%s] from [%s]: %w",
+			anchorRef, actor, err))
+	}
+
+	logger.Debugf("Stored acknowledgement of anchor [%s] from [%s]. Additional anchors: %s",
+		anchorRef, actor, ack.AdditionalAnchors)
+
+	return nil
+}
+
+// GetPropagationStatus returns the acknowledgements for the given anchor along with the followers
+// that have not yet acknowledged the anchor.
+func (h *Handler) GetPropagationStatus(anchorRef *url.URL) (*PropagationStatus, error) {
+	acks, err := h.getAcknowledgements(anchorRef)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := h.getFollowers()
+	if err != nil {
+		return nil, err
+	}
+
+	acknowledged := make(map[string]struct{})
+
+	for _, ack := range acks {
+		acknowledged[ack.Actor] = struct{}{}
+	}
+
+	status := &PropagationStatus{
+		AnchorRef:        anchorRef.String(),
+		Acknowledgements: acks,
+		Pending:          []string{},
+	}
+
+	for _, follower := range followers {
+		if _, ok := acknowledged[follower.String()]; !ok {
+			status.Pending = append(status.Pending, follower.String())
+		}
+	}
+
+	return status, nil
+}
+
+func (h *Handler) getAcknowledgements(anchorRef *url.URL) ([]*Acknowledgement, error) {
+	query := fmt.Sprintf("%s:%s", anchorTag, encode(anchorRef.String()))
+
+	it, err := h.store.Query(query)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query acknowledgements [%s]: %w", query, err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	acks := []*Acknowledgement{}
+
+	ok, err := it.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error for anchor [%s]: %w", anchorRef, err))
+	}
+
+	for ok {
+		value, e := it.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value for anchor [%s]: %w",
+				anchorRef, e))
+		}
+
+		ack := &Acknowledgement{}
+
+		if e := h.unmarshal(value, ack); e != nil {
+			return nil, fmt.Errorf("unmarshal acknowledgement: %w", e)
+		}
+
+		acks = append(acks, ack)
+
+		ok, err = it.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for anchor [%s]: %w", anchorRef, err))
+		}
+	}
+
+	return acks, nil
+}
+
+func (h *Handler) getFollowers() ([]*url.URL, error) {
+	it, err := h.activityStore.QueryReferences(store.Follower, store.NewCriteria(store.WithObjectIRI(h.serviceIRI)))
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("query followers: %w", err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	followers, err := storeutil.ReadReferences(it, -1)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("read followers: %w", err))
+	}
+
+	return followers, nil
+}
+
+func newKey(anchorRef, actor fmt.Stringer) string {
+	h := sha256.Sum256([]byte(anchorRef.String() + " " + actor.String()))
+
+	return base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func encode(value string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(value))
+}
@@ -0,0 +1,144 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package acknowledgement
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	apmocks "github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	store "github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+var (
+	service1IRI = testutil.MustParseURL("https://orb.domain1.com/services/orb")
+	service2IRI = testutil.MustParseURL("https://orb.domain2.com/services/orb")
+	service3IRI = testutil.MustParseURL("https://orb.domain3.com/services/orb")
+
+	anchorRef     = testutil.MustParseURL("hl:uEiCsFp-ft8tI1DFGbXs78tw-HS561mMPa3Z6GsGAHElrNQ:uoQ-BeDhodHRwczovL2V4YW1wbGUuY29tL2NmMTQ5YTY4LTA4NTYtNDMwNC1hOWVjLTM0NzU2NzU1NDE2Yw") //nolint:lll
+	additionalRef = testutil.MustParseURL("hl:uEiCsFp-ft8tI1DFGbXs78tw-HS561mMPa3Z6GsGAHElrNQ:uoQ-BeDhoxHRwxzovL2V4YW1wbGUuY29tL2NmMTQ5YTY4LTA4NTYtNDMwNC1hOWVjLTM0NzU2NzU1NDE2Yw") //nolint:lll
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		h, err := New(service1IRI, mem.NewProvider(), memstore.New(""))
+		require.NoError(t, err)
+		require.NotNil(t, h)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(nil, errExpected)
+
+		_, err := New(service1IRI, p, memstore.New(""))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		errExpected := errors.New("injected set config error")
+
+		p := &storemocks.Provider{}
+		p.SetStoreConfigReturns(errExpected)
+
+		_, err := New(service1IRI, p, memstore.New(""))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+}
+
+func TestHandler_GetPropagationStatus(t *testing.T) {
+	apStore := memstore.New("")
+
+	require.NoError(t, apStore.AddReference(store.Follower, service1IRI, service2IRI))
+	require.NoError(t, apStore.AddReference(store.Follower, service1IRI, service3IRI))
+
+	h, err := New(service1IRI, mem.NewProvider(), apStore)
+	require.NoError(t, err)
+
+	status, err := h.GetPropagationStatus(anchorRef)
+	require.NoError(t, err)
+	require.Equal(t, anchorRef.String(), status.AnchorRef)
+	require.Empty(t, status.Acknowledgements)
+	require.Len(t, status.Pending, 2)
+
+	require.NoError(t, h.AnchorEventAcknowledged(service2IRI, anchorRef, nil))
+
+	// Acknowledging twice should replace the previous acknowledgement.
+	require.NoError(t, h.AnchorEventAcknowledged(service2IRI, anchorRef, []*url.URL{additionalRef}))
+
+	status, err = h.GetPropagationStatus(anchorRef)
+	require.NoError(t, err)
+	require.Len(t, status.Acknowledgements, 1)
+	require.Equal(t, service2IRI.String(), status.Acknowledgements[0].Actor)
+	require.Equal(t, []string{additionalRef.String()}, status.Acknowledgements[0].AdditionalAnchors)
+	require.Equal(t, []string{service3IRI.String()}, status.Pending)
+
+	t.Run("Marshal error", func(t *testing.T) {
+		errExpected := errors.New("injected marshal error")
+
+		h.marshal = func(v interface{}) ([]byte, error) { return nil, errExpected }
+		defer func() { h.marshal = json.Marshal }()
+
+		err := h.AnchorEventAcknowledged(service3IRI, anchorRef, nil)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		errExpected := errors.New("injected unmarshal error")
+
+		h.unmarshal = func(data []byte, v interface{}) error { return errExpected }
+		defer func() { h.unmarshal = json.Unmarshal }()
+
+		_, err := h.GetPropagationStatus(anchorRef)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Store error", func(t *testing.T) {
+		errExpected := errors.New("injected store error")
+
+		s := &storemocks.Store{}
+		s.PutReturns(errExpected)
+		s.QueryReturns(nil, errExpected)
+
+		p := &storemocks.Provider{}
+		p.OpenStoreReturns(s, nil)
+
+		h, err := New(service1IRI, p, apStore)
+		require.NoError(t, err)
+
+		err = h.AnchorEventAcknowledged(service3IRI, anchorRef, nil)
+		require.True(t, orberrors.IsTransient(err))
+
+		_, err = h.GetPropagationStatus(anchorRef)
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Activity store error", func(t *testing.T) {
+		errExpected := errors.New("injected activity store error")
+
+		s := &apmocks.ActivityStore{}
+		s.QueryReferencesReturns(nil, errExpected)
+
+		h, err := New(service1IRI, mem.NewProvider(), s)
+		require.NoError(t, err)
+
+		_, err = h.GetPropagationStatus(anchorRef)
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
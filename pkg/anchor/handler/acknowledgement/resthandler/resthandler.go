@@ -0,0 +1,120 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/anchor/handler/acknowledgement"
+)
+
+const (
+	endpoint    = "/anchor/propagation"
+	anchorParam = "anchor"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("anchor-propagation-rest-handler")
+
+type statusProvider interface {
+	GetPropagationStatus(anchorRef *url.URL) (*acknowledgement.PropagationStatus, error)
+}
+
+// PropagationStatus returns the propagation status of an anchor, i.e. which of the remote Orb
+// services have acknowledged the anchor and which followers have not yet acknowledged it.
+type PropagationStatus struct {
+	statusProvider statusProvider
+	marshal        func(interface{}) ([]byte, error)
+}
+
+// New returns a new PropagationStatus handler.
+func New(p statusProvider) *PropagationStatus {
+	return &PropagationStatus{
+		statusProvider: p,
+		marshal:        json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the PropagationStatus service.
+func (h *PropagationStatus) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the PropagationStatus service.
+func (h *PropagationStatus) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the PropagationStatus service.
+func (h *PropagationStatus) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *PropagationStatus) handle(w http.ResponseWriter, req *http.Request) {
+	anchor := req.URL.Query().Get(anchorParam)
+	if anchor == "" {
+		logger.Infof("[%s] Missing query parameter [%s]", endpoint, anchorParam)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	anchorRef, err := url.Parse(anchor)
+	if err != nil {
+		logger.Infof("[%s] Invalid anchor [%s]: %s", endpoint, anchor, err)
+
+		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+		return
+	}
+
+	status, err := h.statusProvider.GetPropagationStatus(anchorRef)
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving propagation status of anchor [%s]: %s", endpoint, anchor, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	statusBytes, err := h.marshal(status)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling propagation status of anchor [%s]: %s", endpoint, anchor, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, statusBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+	}
+}
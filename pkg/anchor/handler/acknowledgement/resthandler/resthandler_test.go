@@ -0,0 +1,116 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/handler/acknowledgement"
+)
+
+const anchor = "hl:uEiCsFp-ft8tI1DFGbXs78tw-HS561mMPa3Z6GsGAHElrNQ"
+
+func TestNew(t *testing.T) {
+	h := New(&mockStatusProvider{})
+	require.NotNil(t, h)
+	require.Equal(t, endpoint, h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		h := New(&mockStatusProvider{
+			status: &acknowledgement.PropagationStatus{
+				AnchorRef: anchor,
+				Acknowledgements: []*acknowledgement.Acknowledgement{
+					{Actor: "https://orb.domain2.com/services/orb", AnchorRef: anchor},
+				},
+				Pending: []string{"https://orb.domain3.com/services/orb"},
+			},
+		})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?anchor="+url.QueryEscape(anchor), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		status := &acknowledgement.PropagationStatus{}
+		require.NoError(t, json.Unmarshal(respBytes, status))
+		require.Equal(t, anchor, status.AnchorRef)
+		require.Len(t, status.Acknowledgements, 1)
+		require.Len(t, status.Pending, 1)
+	})
+
+	t.Run("Missing anchor", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		New(&mockStatusProvider{}).handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Invalid anchor", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		New(&mockStatusProvider{}).handle(rw,
+			httptest.NewRequest(http.MethodGet, endpoint+"?anchor="+url.QueryEscape(":invalid"), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Status provider error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+
+		New(&mockStatusProvider{err: errors.New("injected error")}).handle(rw,
+			httptest.NewRequest(http.MethodGet, endpoint+"?anchor="+url.QueryEscape(anchor), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := New(&mockStatusProvider{status: &acknowledgement.PropagationStatus{}})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?anchor="+url.QueryEscape(anchor), nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockStatusProvider struct {
+	status *acknowledgement.PropagationStatus
+	err    error
+}
+
+func (m *mockStatusProvider) GetPropagationStatus(*url.URL) (*acknowledgement.PropagationStatus, error) {
+	return m.status, m.err
+}
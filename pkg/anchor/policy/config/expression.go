@@ -0,0 +1,189 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+)
+
+// expression is a node of a parsed witness policy expression.
+type expression interface {
+	evaluate(wp *WitnessPolicyConfig, witnesses []*proof.WitnessProof) bool
+	fmt.Stringer
+}
+
+type andExpression struct {
+	left, right expression
+}
+
+func (e *andExpression) evaluate(wp *WitnessPolicyConfig, witnesses []*proof.WitnessProof) bool {
+	return e.left.evaluate(wp, witnesses) && e.right.evaluate(wp, witnesses)
+}
+
+func (e *andExpression) String() string {
+	return fmt.Sprintf("(%s AND %s)", e.left, e.right)
+}
+
+type orExpression struct {
+	left, right expression
+}
+
+func (e *orExpression) evaluate(wp *WitnessPolicyConfig, witnesses []*proof.WitnessProof) bool {
+	return e.left.evaluate(wp, witnesses) || e.right.evaluate(wp, witnesses)
+}
+
+func (e *orExpression) String() string {
+	return fmt.Sprintf("(%s OR %s)", e.left, e.right)
+}
+
+// quorumRule is a leaf of the expression which requires a minimum number, percentage or weight of
+// proofs from the witnesses selected by a role (batch or system) or by a witness group.
+type quorumRule struct {
+	name      string
+	threshold int
+	selector  string
+}
+
+func (r *quorumRule) evaluate(wp *WitnessPolicyConfig, witnesses []*proof.WitnessProof) bool {
+	t := wp.tally(witnesses, r.selector)
+
+	switch r.name {
+	case OutOf:
+		return t.collected >= r.threshold
+	case MinPercent:
+		if t.total == 0 {
+			return true
+		}
+
+		return float64(t.collected)/float64(t.total) >= float64(r.threshold)/maxPercent
+	default: // MinWeight
+		return t.weight >= r.threshold
+	}
+}
+
+func (r *quorumRule) String() string {
+	return fmt.Sprintf("%s(%d,%s)", r.name, r.threshold, r.selector)
+}
+
+type tally struct {
+	// total is the number of selected witnesses.
+	total int
+	// collected is the number of selected witnesses that provided a proof.
+	collected int
+	// weight is the sum of the weights of the selected witnesses that provided a proof.
+	weight int
+}
+
+// tally counts the proofs of the witnesses that match the given selector. For a role, the total is the number of
+// witnesses with that role and, for a group, the total is the number of members of the group. Excluded witnesses
+// are ignored and, if a log is required, proofs from witnesses without a log are not counted.
+func (wp *WitnessPolicyConfig) tally(witnesses []*proof.WitnessProof, selector string) *tally {
+	t := &tally{}
+
+	members, isGroup := wp.Groups[selector]
+	if isGroup {
+		for _, member := range members {
+			if !wp.excluded[member] {
+				t.total++
+			}
+		}
+	}
+
+	counted := make(map[string]bool)
+
+	for _, w := range witnesses {
+		if wp.excluded[w.Witness] || !wp.selects(selector, w) {
+			continue
+		}
+
+		if !isGroup {
+			t.total++
+		} else if counted[w.Witness] {
+			// A group member may have been selected as both a batch and a system witness.
+			continue
+		}
+
+		if w.Proof == nil || !checkLog(wp.LogRequired, w.HasLog) {
+			continue
+		}
+
+		counted[w.Witness] = true
+
+		t.collected++
+		t.weight += wp.weight(w.Witness)
+	}
+
+	return t
+}
+
+func (wp *WitnessPolicyConfig) selects(selector string, w *proof.WitnessProof) bool {
+	switch selector {
+	case RoleBatch:
+		return w.Type == proof.WitnessTypeBatch
+	case RoleSystem:
+		return w.Type == proof.WitnessTypeSystem
+	default:
+		for _, member := range wp.Groups[selector] {
+			if member == w.Witness {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+func (wp *WitnessPolicyConfig) weight(witness string) int {
+	if weight, ok := wp.Weights[witness]; ok {
+		return weight
+	}
+
+	return defaultWeight
+}
+
+// Evaluate returns true if the given witness proofs satisfy the witness policy.
+func (wp *WitnessPolicyConfig) Evaluate(witnesses []*proof.WitnessProof) bool {
+	if wp.expr == nil {
+		return wp.evaluateLegacy(witnesses)
+	}
+
+	return wp.expr.evaluate(wp, witnesses)
+}
+
+// evaluateLegacy evaluates a policy that only uses the original rules. The batch and system witnesses are each
+// evaluated against their minimum number and percentage (a role that isn't mentioned requires 100%) and the
+// results are combined with the policy's operator.
+func (wp *WitnessPolicyConfig) evaluateLegacy(witnesses []*proof.WitnessProof) bool {
+	batch := wp.tally(witnesses, RoleBatch)
+	system := wp.tally(witnesses, RoleSystem)
+
+	return wp.Operator(
+		evaluateLegacyRole(batch, wp.MinNumberBatch, wp.MinPercentBatch),
+		evaluateLegacyRole(system, wp.MinNumberSystem, wp.MinPercentSystem),
+	)
+}
+
+func evaluateLegacyRole(t *tally, minNumber, minPercent int) bool {
+	percentCollected := float64(maxPercent)
+	if t.total != 0 {
+		percentCollected = float64(t.collected) / float64(t.total)
+	}
+
+	return (minNumber != 0 && t.collected >= minNumber) ||
+		percentCollected >= float64(minPercent)/maxPercent
+}
+
+func checkLog(logRequired, hasLog bool) bool {
+	if logRequired {
+		return hasLog
+	}
+
+	// log is not required, witness without log is counted for policy
+	return true
+}
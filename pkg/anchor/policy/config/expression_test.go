@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+)
+
+const (
+	witnessA1 = "https://a1.com/services/orb"
+	witnessA2 = "https://a2.com/services/orb"
+	witnessA3 = "https://a3.com/services/orb"
+	witnessB1 = "https://b1.com/services/orb"
+	witnessB2 = "https://b2.com/services/orb"
+)
+
+const groups = "Group(orgA," + witnessA1 + "," + witnessA2 + "," + witnessA3 + ") " +
+	"Group(orgB," + witnessB1 + "," + witnessB2 + ") "
+
+func TestWitnessPolicyConfig_Evaluate(t *testing.T) {
+	t.Run("groups", func(t *testing.T) {
+		wp, err := Parse(groups + "OutOf(2,orgA) AND OutOf(1,orgB)")
+		require.NoError(t, err)
+
+		require.True(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2, witnessB2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessB1, witnessB2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2, witnessA3)))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		wp, err := Parse(groups + "(OutOf(3,orgA) OR MinPercent(50,orgA)) AND (OutOf(2,orgB) OR OutOf(1,system))")
+		require.NoError(t, err)
+
+		require.True(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2, witnessB1, witnessB2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessB1, witnessB2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2, witnessB1)))
+
+		// The percentage is relative to all of the members of the group.
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessB1)))
+
+		witnesses := newWitnessProofs(witnessA1, witnessA2, witnessB1)
+		witnesses = append(witnesses, &proof.WitnessProof{
+			Type:    proof.WitnessTypeSystem,
+			Witness: "https://system.com/services/orb",
+			Proof:   []byte("proof"),
+		})
+
+		require.True(t, wp.Evaluate(witnesses))
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		wp, err := Parse(groups + "Weight(" + witnessA1 + ",3) Weight(" + witnessA2 + ",0) MinWeight(4,orgA)")
+		require.NoError(t, err)
+
+		require.True(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA3)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA2, witnessA3)))
+	})
+
+	t.Run("exclusions", func(t *testing.T) {
+		wp, err := Parse(groups + "Exclude(" + witnessA3 + ") MinPercent(100,orgA)")
+		require.NoError(t, err)
+
+		// The excluded witness is neither counted as a proof nor as a witness.
+		require.True(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA2)))
+		require.False(t, wp.Evaluate(newWitnessProofs(witnessA1, witnessA3)))
+	})
+
+	t.Run("log required", func(t *testing.T) {
+		wp, err := Parse(groups + "OutOf(1,orgB) LogRequired")
+		require.NoError(t, err)
+
+		witnesses := newWitnessProofs(witnessB1)
+		require.False(t, wp.Evaluate(witnesses))
+
+		witnesses[0].HasLog = true
+		require.True(t, wp.Evaluate(witnesses))
+	})
+
+	t.Run("missing proof", func(t *testing.T) {
+		wp, err := Parse(groups + "OutOf(1,orgB)")
+		require.NoError(t, err)
+
+		witnesses := newWitnessProofs(witnessB1)
+		witnesses[0].Proof = nil
+
+		require.False(t, wp.Evaluate(witnesses))
+	})
+}
+
+func newWitnessProofs(witnesses ...string) []*proof.WitnessProof {
+	var proofs []*proof.WitnessProof
+
+	for _, w := range witnesses {
+		proofs = append(proofs, &proof.WitnessProof{
+			Type:    proof.WitnessTypeBatch,
+			Witness: w,
+			Proof:   []byte("proof"),
+		})
+	}
+
+	return proofs
+}
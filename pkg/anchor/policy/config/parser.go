@@ -7,9 +7,9 @@ SPDX-License-Identifier: Apache-2.0
 package config
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
-	"strings"
 )
 
 // WitnessPolicyConfig parses witness policy.
@@ -23,154 +23,551 @@ type WitnessPolicyConfig struct {
 	Operator operatorFnc
 
 	LogRequired bool
+
+	// Groups contains the named witness groups (group name -> witness IRIs).
+	Groups map[string][]string
+
+	// Weights contains the weights of individual witnesses. A witness without a weight has a weight of 1.
+	Weights map[string]int
+
+	// Excluded contains the witnesses whose proofs are never counted.
+	Excluded []string
+
+	excluded map[string]bool
+	expr     expression
 }
 
 // Gate values.
 const (
 	OutOf       = "OutOf"
 	MinPercent  = "MinPercent"
+	MinWeight   = "MinWeight"
 	LogRequired = "LogRequired"
 
 	AND = "AND"
 	OR  = "OR"
 )
 
+// Definition values.
+const (
+	Group   = "Group"
+	Weight  = "Weight"
+	Exclude = "Exclude"
+)
+
 // Role values.
 const (
 	RoleBatch  = "batch"
 	RoleSystem = "system"
 )
 
-const maxPercent = 100
+const (
+	maxPercent    = 100
+	defaultWeight = 1
+)
 
 type operatorFnc func(a, b bool) bool
 
 // Parse parses witness policy from policy string.
+//
+// A policy consists of an optional expression along with any number of definitions and the LogRequired flag:
+//
+//	expression := term { OR term }
+//	term       := factor { [AND] factor }
+//	factor     := "(" expression ")" | OutOf(n,selector) | MinPercent(n,selector) | MinWeight(n,selector)
+//	selector   := batch | system | <group name>
+//
+//	Group(name,witness1,witness2,...) - defines a named group of witnesses (identified by IRI)
+//	Weight(witness,n)                 - sets the weight of a witness for MinWeight (defaults to 1)
+//	Exclude(witness1,witness2,...)    - proofs from the given witnesses are never counted
+//	LogRequired                       - proofs are only counted if the witness has a log
+//
+// For example: "Group(orgA,https://a1.com/services/orb,https://a2.com/services/orb)
+// Group(orgB,https://b1.com/services/orb) (OutOf(2,orgA) AND OutOf(1,orgB)) OR MinPercent(100,system)".
+//
+// AND takes precedence over OR and rules that aren't separated by an operator are joined with AND. LogRequired
+// may also be joined to the expression with an operator (e.g. "OutOf(1,system) AND LogRequired"). A policy that
+// only uses OutOf and MinPercent rules for the batch and system roles, joined by a single type of operator (the
+// original policy format), retains its original meaning:
+// each role is required to have 100% of proofs unless the role has its own rule, and the results for the
+// two roles are combined with the operator.
 func Parse(policy string) (*WitnessPolicyConfig, error) {
 	// default policy is 100% batch and 100% system witnesses
 	wp := &WitnessPolicyConfig{
 		MinPercentBatch:  maxPercent,
 		MinPercentSystem: maxPercent,
 		Operator:         and,
+		Groups:           make(map[string][]string),
+		Weights:          make(map[string]int),
+		excluded:         make(map[string]bool),
 	}
 
 	if policy == "" {
 		return wp, nil
 	}
 
-	tokens := strings.Split(policy, " ")
+	tokens, err := tokenize(policy)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{
+		tokens:    tokens,
+		wp:        wp,
+		operators: make(map[string]bool),
+	}
+
+	p.removeLogRequired()
+
+	expr, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	if p.isLegacy() {
+		p.applyLegacy()
+	} else {
+		wp.expr = expr
+	}
+
+	return wp, nil
+}
+
+func (wp *WitnessPolicyConfig) String() string {
+	if wp.expr == nil {
+		return fmt.Sprintf("minBatch:%d, minSystem:%d, percentBatch:%d, percentSystem:%d, log:%t",
+			wp.MinNumberBatch, wp.MinNumberSystem, wp.MinPercentBatch, wp.MinPercentSystem, wp.LogRequired)
+	}
+
+	return fmt.Sprintf("expression:%s, groups:%v, weights:%v, excluded:%v, log:%t",
+		wp.expr, wp.Groups, wp.Weights, wp.Excluded, wp.LogRequired)
+}
+
+type parser struct {
+	tokens    []*token
+	pos       int
+	wp        *WitnessPolicyConfig
+	rules     []*quorumRule
+	operators map[string]bool
+	nested    bool
+}
+
+func (p *parser) parse() (expression, error) {
+	var expr expression
+
+	for p.pos < len(p.tokens) {
+		t := p.tokens[p.pos]
+
+		switch {
+		case t.typ == tokenRule && isDefinition(t.name):
+			if err := p.define(t); err != nil {
+				return nil, err
+			}
+
+			p.pos++
+
+		case expr == nil:
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+
+			expr = e
+
+		default:
+			return nil, fmt.Errorf("unexpected '%s' after policy expression", t)
+		}
+	}
+
+	return expr, nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.nextIsOperator(OR) {
+		p.pos++
 
-	for _, token := range tokens {
-		err := wp.processToken(token)
+		right, err := p.parseAnd()
 		if err != nil {
 			return nil, err
 		}
+
+		left = &orExpression{left: left, right: right}
 	}
 
-	return wp, nil
+	return left, nil
 }
 
-func (wp *WitnessPolicyConfig) processToken(token string) error {
-	switch t := token; {
-	case strings.HasPrefix(t, OutOf):
-		err := wp.processOutOf(token)
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.nextIsOperator(AND) {
+			p.pos++
+		} else if !p.nextIsFactor() {
+			break
+		}
+
+		right, err := p.parseFactor()
 		if err != nil {
-			return err
+			return nil, err
 		}
-	case strings.HasPrefix(t, MinPercent):
-		err := wp.processMinPercent(token)
+
+		left = &andExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseFactor() (expression, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of policy: expecting a rule")
+	}
+
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.typ {
+	case tokenOpen:
+		p.nested = true
+
+		expr, err := p.parseOr()
 		if err != nil {
-			return err
-		}
-	case t == LogRequired:
-		wp.LogRequired = true
-	case t == AND:
-		wp.Operator = and
-	case t == OR:
-		wp.Operator = or
+			return nil, err
+		}
+
+		if p.pos >= len(p.tokens) || p.tokens[p.pos].typ != tokenClose {
+			return nil, errors.New("missing closing parenthesis in policy expression")
+		}
+
+		p.pos++
+
+		return expr, nil
+
+	case tokenClose:
+		return nil, errors.New("unexpected closing parenthesis in policy expression")
+
+	case tokenRule:
+		return p.parseRule(t)
+
 	default:
-		return fmt.Errorf("rule not supported: %s", token)
-	}
+		if t.name == AND || t.name == OR {
+			return nil, fmt.Errorf("unexpected operator %s: expecting a rule", t.name)
+		}
 
-	return nil
+		return nil, fmt.Errorf("rule not supported: %s", t)
+	}
 }
 
-// processOutOf rule (e.g. OutOf(2,system) rule means that proofs from at least 2 system witnesses are required.
-func (wp *WitnessPolicyConfig) processOutOf(token string) error {
-	insideBrackets := token[len(OutOf)+1 : len(token)-1]
+// nextIsFactor returns true if the next token starts a factor, in which case the factor is implicitly
+// joined with AND to the preceding factor.
+func (p *parser) nextIsFactor() bool {
+	if p.pos >= len(p.tokens) {
+		return false
+	}
 
-	outOfArgs := strings.Split(insideBrackets, ",")
+	t := p.tokens[p.pos]
 
-	const outOfArgsNo = 2
-	if len(outOfArgs) != outOfArgsNo {
-		return fmt.Errorf("expected 2 but got %d arguments for OutOf policy", len(outOfArgs))
+	return t.typ == tokenOpen || (t.typ == tokenRule && !isDefinition(t.name))
+}
+
+func (p *parser) nextIsOperator(op string) bool {
+	if p.pos >= len(p.tokens) {
+		return false
 	}
 
-	minNo, err := strconv.Atoi(outOfArgs[0])
-	if err != nil {
-		return fmt.Errorf("first argument for OutOf policy must be an integer: %w", err)
+	t := p.tokens[p.pos]
+
+	if t.typ != tokenWord || t.name != op {
+		return false
 	}
 
-	switch outOfArgs[1] {
-	case RoleSystem:
-		wp.MinNumberSystem = minNo
+	p.operators[op] = true
+
+	return true
+}
+
+// removeLogRequired sets the LogRequired flag and removes the LogRequired tokens from the policy, along with
+// the operator (if any) that joins LogRequired to the expression, since LogRequired is a flag and not a rule.
+func (p *parser) removeLogRequired() {
+	tokens := make([]*token, 0, len(p.tokens))
 
-		if wp.MinNumberSystem == 0 {
-			wp.MinPercentSystem = 0
+	for i := 0; i < len(p.tokens); i++ {
+		t := p.tokens[i]
+
+		if t.typ != tokenWord || t.name != LogRequired {
+			tokens = append(tokens, t)
+
+			continue
 		}
 
-	case RoleBatch:
-		wp.MinNumberBatch = minNo
+		p.wp.LogRequired = true
 
-		if wp.MinNumberBatch == 0 {
-			wp.MinPercentBatch = 0
+		switch {
+		case len(tokens) > 0 && isOperator(tokens[len(tokens)-1]):
+			tokens = tokens[:len(tokens)-1]
+		case i+1 < len(p.tokens) && isOperator(p.tokens[i+1]):
+			i++
 		}
+	}
+
+	p.tokens = tokens
+}
 
+func isOperator(t *token) bool {
+	return t.typ == tokenWord && (t.name == AND || t.name == OR)
+}
+
+func (p *parser) parseRule(t *token) (expression, error) {
+	var (
+		threshold int
+		err       error
+	)
+
+	switch t.name {
+	case OutOf:
+		threshold, err = parseOutOf(t.args)
+	case MinPercent:
+		threshold, err = parseMinPercent(t.args)
+	case MinWeight:
+		threshold, err = parseMinWeight(t.args)
 	default:
-		return fmt.Errorf("role '%s' not supported for OutOf policy", outOfArgs[1])
+		if isDefinition(t.name) {
+			return nil, fmt.Errorf("%s may not be used within an expression: %s", t.name, t)
+		}
+
+		return nil, fmt.Errorf("rule not supported: %s", t)
 	}
 
-	return nil
+	if err != nil {
+		return nil, err
+	}
+
+	rule := &quorumRule{name: t.name, threshold: threshold, selector: t.args[1]}
+
+	p.rules = append(p.rules, rule)
+
+	return rule, nil
 }
 
-// processMinPercent will process minimum percent rule.
-// e.g. MinPercent(0.2,system) rule means that proofs from at least 20% of system witnesses are required.
-func (wp *WitnessPolicyConfig) processMinPercent(token string) error {
-	insideBrackets := token[len(MinPercent)+1 : len(token)-1]
+// parseOutOf parses the OutOf rule (e.g. OutOf(2,system) rule means that proofs from at least 2 system
+// witnesses are required).
+func parseOutOf(args []string) (int, error) {
+	const outOfArgsNo = 2
+	if len(args) != outOfArgsNo {
+		return 0, fmt.Errorf("expected 2 but got %d arguments for OutOf policy", len(args))
+	}
 
-	minPercentArgs := strings.Split(insideBrackets, ",")
+	minNo, err := strconv.Atoi(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("first argument for OutOf policy must be an integer: %w", err)
+	}
+
+	if minNo < 0 {
+		return 0, fmt.Errorf("first argument for OutOf policy must be a non-negative integer")
+	}
+
+	return minNo, nil
+}
 
+// parseMinPercent parses the minimum percent rule (e.g. MinPercent(20,system) rule means that proofs from
+// at least 20% of system witnesses are required).
+func parseMinPercent(args []string) (int, error) {
 	const minPercentArgsNo = 2
-	if len(minPercentArgs) != minPercentArgsNo {
-		return fmt.Errorf("expected 2 but got %d arguments for MinPercent policy", len(minPercentArgs))
+	if len(args) != minPercentArgsNo {
+		return 0, fmt.Errorf("expected 2 but got %d arguments for MinPercent policy", len(args))
 	}
 
-	minPercent, err := strconv.Atoi(minPercentArgs[0])
+	minPercent, err := strconv.Atoi(args[0])
 	if err != nil {
-		return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100: %w", err)
+		return 0, fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100: %w", err)
 	}
 
 	if minPercent < 0 || minPercent > 100 {
-		return fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100")
+		return 0, fmt.Errorf("first argument for OutOf policy must be an integer between 0 and 100")
 	}
 
-	switch minPercentArgs[1] {
-	case RoleSystem:
-		wp.MinPercentSystem = minPercent
+	return minPercent, nil
+}
 
-	case RoleBatch:
-		wp.MinPercentBatch = minPercent
+// parseMinWeight parses the minimum weight rule (e.g. MinWeight(5,orgA) rule means that the sum of the
+// weights of the witnesses in group orgA that provided a proof must be at least 5).
+func parseMinWeight(args []string) (int, error) {
+	const minWeightArgsNo = 2
+	if len(args) != minWeightArgsNo {
+		return 0, fmt.Errorf("expected 2 but got %d arguments for MinWeight policy", len(args))
+	}
 
-	default:
-		return fmt.Errorf("role '%s' not supported for MinPercent policy", minPercentArgs[1])
+	minWeight, err := strconv.Atoi(args[0])
+	if err != nil || minWeight < 0 {
+		return 0, fmt.Errorf("first argument for MinWeight policy must be a non-negative integer")
 	}
 
+	return minWeight, nil
+}
+
+func isDefinition(name string) bool {
+	return name == Group || name == Weight || name == Exclude
+}
+
+func (p *parser) define(t *token) error {
+	switch t.name {
+	case Group:
+		return p.defineGroup(t.args)
+	case Weight:
+		return p.defineWeight(t.args)
+	default: // Exclude
+		return p.defineExclusions(t.args)
+	}
+}
+
+func (p *parser) defineGroup(args []string) error {
+	const minGroupArgs = 2
+	if len(args) < minGroupArgs {
+		return fmt.Errorf("expected a name and at least one witness for Group")
+	}
+
+	name := args[0]
+
+	if name == "" || name == RoleBatch || name == RoleSystem {
+		return fmt.Errorf("invalid name for Group: '%s'", name)
+	}
+
+	if _, exists := p.wp.Groups[name]; exists {
+		return fmt.Errorf("duplicate Group: %s", name)
+	}
+
+	members := make(map[string]bool)
+
+	for _, witness := range args[1:] {
+		if witness == "" {
+			return fmt.Errorf("empty witness in Group %s", name)
+		}
+
+		if members[witness] {
+			return fmt.Errorf("duplicate witness in Group %s: %s", name, witness)
+		}
+
+		members[witness] = true
+	}
+
+	p.wp.Groups[name] = args[1:]
+
 	return nil
 }
 
-func (wp *WitnessPolicyConfig) String() string {
-	return fmt.Sprintf("minBatch:%d, minSystem:%d, percentBatch:%d, percentSystem:%d, log:%t",
-		wp.MinNumberBatch, wp.MinNumberSystem, wp.MinPercentBatch, wp.MinPercentSystem, wp.LogRequired)
+func (p *parser) defineWeight(args []string) error {
+	const weightArgsNo = 2
+	if len(args) != weightArgsNo {
+		return fmt.Errorf("expected 2 but got %d arguments for Weight", len(args))
+	}
+
+	if args[0] == "" {
+		return fmt.Errorf("first argument for Weight must be a witness")
+	}
+
+	weight, err := strconv.Atoi(args[1])
+	if err != nil || weight < 0 {
+		return fmt.Errorf("second argument for Weight must be a non-negative integer")
+	}
+
+	p.wp.Weights[args[0]] = weight
+
+	return nil
+}
+
+func (p *parser) defineExclusions(args []string) error {
+	for _, witness := range args {
+		if witness == "" {
+			return fmt.Errorf("empty witness in Exclude")
+		}
+
+		if !p.wp.excluded[witness] {
+			p.wp.excluded[witness] = true
+			p.wp.Excluded = append(p.wp.Excluded, witness)
+		}
+	}
+
+	return nil
+}
+
+// validate ensures that all of the selectors in the rules refer to a role or to a defined group.
+func (p *parser) validate() error {
+	for _, rule := range p.rules {
+		if rule.selector == RoleBatch || rule.selector == RoleSystem {
+			continue
+		}
+
+		if _, ok := p.wp.Groups[rule.selector]; !ok {
+			return fmt.Errorf("role '%s' not supported for %s policy", rule.selector, rule.name)
+		}
+	}
+
+	return nil
+}
+
+// isLegacy returns true if the policy only uses the features of the original policy format.
+func (p *parser) isLegacy() bool {
+	if p.nested || len(p.operators) > 1 ||
+		len(p.wp.Groups) > 0 || len(p.wp.Weights) > 0 || len(p.wp.Excluded) > 0 {
+		return false
+	}
+
+	for _, rule := range p.rules {
+		if rule.name == MinWeight {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *parser) applyLegacy() {
+	for _, rule := range p.rules {
+		switch rule.name {
+		case OutOf:
+			p.applyLegacyOutOf(rule)
+		case MinPercent:
+			if rule.selector == RoleSystem {
+				p.wp.MinPercentSystem = rule.threshold
+			} else {
+				p.wp.MinPercentBatch = rule.threshold
+			}
+		}
+	}
+
+	if p.operators[OR] {
+		p.wp.Operator = or
+	}
+}
+
+func (p *parser) applyLegacyOutOf(rule *quorumRule) {
+	if rule.selector == RoleSystem {
+		p.wp.MinNumberSystem = rule.threshold
+
+		if rule.threshold == 0 {
+			p.wp.MinPercentSystem = 0
+		}
+
+		return
+	}
+
+	p.wp.MinNumberBatch = rule.threshold
+
+	if rule.threshold == 0 {
+		p.wp.MinPercentBatch = 0
+	}
 }
 
 func and(a, b bool) bool {
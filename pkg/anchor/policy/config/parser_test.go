@@ -162,4 +162,183 @@ func TestParse_LogRequired(t *testing.T) {
 		require.Equal(t, true, wp.LogRequired)
 		require.Equal(t, and(true, false), wp.Operator(true, false))
 	})
+
+	t.Run("success - log required joined with an operator", func(t *testing.T) {
+		wp, err := Parse("OutOf(1,system) AND LogRequired")
+		require.NoError(t, err)
+		require.Nil(t, wp.expr)
+		require.Equal(t, 1, wp.MinNumberSystem)
+		require.True(t, wp.LogRequired)
+
+		wp, err = Parse("LogRequired AND OutOf(1,batch) OR OutOf(1,system)")
+		require.NoError(t, err)
+		require.Nil(t, wp.expr)
+		require.Equal(t, 1, wp.MinNumberBatch)
+		require.Equal(t, 1, wp.MinNumberSystem)
+		require.Equal(t, or(true, false), wp.Operator(true, false))
+		require.True(t, wp.LogRequired)
+
+		wp, err = Parse("(OutOf(1,batch) AND LogRequired) OR MinPercent(50,system) AND OutOf(1,batch)")
+		require.NoError(t, err)
+		require.Equal(t, "(OutOf(1,batch) OR (MinPercent(50,system) AND OutOf(1,batch)))", wp.expr.String())
+		require.True(t, wp.LogRequired)
+	})
+}
+
+func TestParse_Expression(t *testing.T) {
+	t.Run("success - AND takes precedence over OR", func(t *testing.T) {
+		wp, err := Parse("OutOf(1,batch) OR OutOf(1,system) AND MinPercent(50,batch)")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, "(OutOf(1,batch) OR (OutOf(1,system) AND MinPercent(50,batch)))", wp.expr.String())
+	})
+
+	t.Run("success - nested expression", func(t *testing.T) {
+		wp, err := Parse("(OutOf(1,batch) OR OutOf(1,system)) AND MinPercent(50,batch) LogRequired")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, "((OutOf(1,batch) OR OutOf(1,system)) AND MinPercent(50,batch))", wp.expr.String())
+		require.True(t, wp.LogRequired)
+		require.Contains(t, wp.String(), "expression:")
+	})
+
+	t.Run("success - legacy policy", func(t *testing.T) {
+		wp, err := Parse("MinPercent(100,batch) AND MinPercent(50,system)")
+		require.NoError(t, err)
+		require.Nil(t, wp.expr)
+		require.Equal(t, 100, wp.MinPercentBatch)
+		require.Equal(t, 50, wp.MinPercentSystem)
+		require.Contains(t, wp.String(), "percentSystem:50")
+	})
+
+	t.Run("error - missing closing parenthesis", func(t *testing.T) {
+		_, err := Parse("(OutOf(1,batch) OR OutOf(1,system)")
+		require.EqualError(t, err, "missing closing parenthesis in policy expression")
+
+		_, err = Parse("OutOf(1,batch")
+		require.EqualError(t, err, "missing closing parenthesis for rule OutOf")
+	})
+
+	t.Run("error - unexpected closing parenthesis", func(t *testing.T) {
+		_, err := Parse("OutOf(1,batch) AND )")
+		require.EqualError(t, err, "unexpected closing parenthesis in policy expression")
+
+		_, err = Parse("OutOf(1,batch))")
+		require.EqualError(t, err, "unexpected ')' after policy expression")
+	})
+
+	t.Run("error - missing rule", func(t *testing.T) {
+		_, err := Parse("OutOf(1,batch) AND")
+		require.EqualError(t, err, "unexpected end of policy: expecting a rule")
+
+		_, err = Parse("OR OutOf(1,batch)")
+		require.EqualError(t, err, "unexpected operator OR: expecting a rule")
+	})
+
+	t.Run("success - rules without an operator are joined with AND", func(t *testing.T) {
+		wp, err := Parse("OutOf(2,batch) MinPercent(50,system)")
+		require.NoError(t, err)
+		require.Nil(t, wp.expr)
+		require.Equal(t, 2, wp.MinNumberBatch)
+		require.Equal(t, 50, wp.MinPercentSystem)
+		require.Equal(t, and(true, false), wp.Operator(true, false))
+
+		wp, err = Parse("Group(orgA,https://a1.com) OutOf(1,orgA) (OutOf(1,batch) OR OutOf(1,system))")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, "(OutOf(1,orgA) AND (OutOf(1,batch) OR OutOf(1,system)))", wp.expr.String())
+	})
+
+	t.Run("error - expression after definition", func(t *testing.T) {
+		_, err := Parse("OutOf(1,batch) Exclude(https://a1.com) OutOf(1,system)")
+		require.EqualError(t, err, "unexpected 'OutOf(1,system)' after policy expression")
+	})
+}
+
+func TestParse_Groups(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		wp, err := Parse("Group(orgA, https://a1.com, https://a2.com) Group(orgB,https://b1.com) " +
+			"OutOf(2,orgA) AND OutOf(1,orgB)")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, []string{"https://a1.com", "https://a2.com"}, wp.Groups["orgA"])
+		require.Equal(t, []string{"https://b1.com"}, wp.Groups["orgB"])
+	})
+
+	t.Run("error - undefined group", func(t *testing.T) {
+		_, err := Parse("Group(orgA,https://a1.com) OutOf(2,orgA) AND MinWeight(1,orgB)")
+		require.EqualError(t, err, "role 'orgB' not supported for MinWeight policy")
+	})
+
+	t.Run("error - no witnesses", func(t *testing.T) {
+		_, err := Parse("Group(orgA) OutOf(2,orgA)")
+		require.EqualError(t, err, "expected a name and at least one witness for Group")
+	})
+
+	t.Run("error - invalid name", func(t *testing.T) {
+		_, err := Parse("Group(system,https://a1.com)")
+		require.EqualError(t, err, "invalid name for Group: 'system'")
+	})
+
+	t.Run("error - duplicate group", func(t *testing.T) {
+		_, err := Parse("Group(orgA,https://a1.com) Group(orgA,https://a2.com)")
+		require.EqualError(t, err, "duplicate Group: orgA")
+	})
+
+	t.Run("error - empty witness", func(t *testing.T) {
+		_, err := Parse("Group(orgA,https://a1.com,)")
+		require.EqualError(t, err, "empty witness in Group orgA")
+
+		_, err = Parse("Group(orgA,https://a1.com,https://a1.com)")
+		require.EqualError(t, err, "duplicate witness in Group orgA: https://a1.com")
+	})
+
+	t.Run("error - definition within expression", func(t *testing.T) {
+		_, err := Parse("OutOf(1,batch) AND Group(orgA,https://a1.com)")
+		require.EqualError(t, err, "Group may not be used within an expression: Group(orgA,https://a1.com)")
+	})
+}
+
+func TestParse_Weights(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		wp, err := Parse("Group(orgA,https://a1.com,https://a2.com) Weight(https://a1.com,3) MinWeight(4,orgA)")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, 3, wp.Weights["https://a1.com"])
+		require.Equal(t, 3, wp.weight("https://a1.com"))
+		require.Equal(t, defaultWeight, wp.weight("https://a2.com"))
+	})
+
+	t.Run("error - invalid weight", func(t *testing.T) {
+		_, err := Parse("Weight(https://a1.com,-1)")
+		require.EqualError(t, err, "second argument for Weight must be a non-negative integer")
+
+		_, err = Parse("Weight(https://a1.com)")
+		require.EqualError(t, err, "expected 2 but got 1 arguments for Weight")
+
+		_, err = Parse("Weight(,1)")
+		require.EqualError(t, err, "first argument for Weight must be a witness")
+	})
+
+	t.Run("error - invalid MinWeight", func(t *testing.T) {
+		_, err := Parse("MinWeight(x,batch)")
+		require.EqualError(t, err, "first argument for MinWeight policy must be a non-negative integer")
+
+		_, err = Parse("MinWeight(1)")
+		require.EqualError(t, err, "expected 2 but got 1 arguments for MinWeight policy")
+	})
+}
+
+func TestParse_Exclude(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		wp, err := Parse("Exclude(https://a1.com,https://a2.com) Exclude(https://a1.com) OutOf(1,batch)")
+		require.NoError(t, err)
+		require.NotNil(t, wp.expr)
+		require.Equal(t, []string{"https://a1.com", "https://a2.com"}, wp.Excluded)
+	})
+
+	t.Run("error - empty witness", func(t *testing.T) {
+		_, err := Parse("Exclude()")
+		require.EqualError(t, err, "empty witness in Exclude")
+	})
 }
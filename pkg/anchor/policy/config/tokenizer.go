@@ -0,0 +1,113 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package config
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	// tokenRule is a rule with arguments, e.g. OutOf(2,system).
+	tokenRule tokenType = iota
+	// tokenWord is a word without arguments, e.g. AND, OR, LogRequired.
+	tokenWord
+	// tokenOpen is an opening parenthesis that starts a nested expression.
+	tokenOpen
+	// tokenClose is a closing parenthesis that ends a nested expression.
+	tokenClose
+)
+
+type token struct {
+	typ  tokenType
+	name string
+	args []string
+}
+
+func (t *token) String() string {
+	switch t.typ {
+	case tokenRule:
+		return fmt.Sprintf("%s(%s)", t.name, strings.Join(t.args, ","))
+	case tokenOpen:
+		return "("
+	case tokenClose:
+		return ")"
+	default:
+		return t.name
+	}
+}
+
+// tokenize splits the policy into tokens. Tokens are separated by white space or parentheses. A rule is a name
+// that is immediately followed by a parenthesised, comma-separated list of arguments.
+func tokenize(policy string) ([]*token, error) {
+	var tokens []*token
+
+	runes := []rune(policy)
+
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, &token{typ: tokenOpen})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, &token{typ: tokenClose})
+			i++
+
+		default:
+			start := i
+
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+				i++
+			}
+
+			name := string(runes[start:i])
+
+			if i == len(runes) || runes[i] != '(' {
+				tokens = append(tokens, &token{typ: tokenWord, name: name})
+
+				continue
+			}
+
+			end := i
+
+			for end < len(runes) && runes[end] != ')' {
+				end++
+			}
+
+			if end == len(runes) {
+				return nil, fmt.Errorf("missing closing parenthesis for rule %s", name)
+			}
+
+			tokens = append(tokens, &token{
+				typ:  tokenRule,
+				name: name,
+				args: splitArgs(string(runes[i+1 : end])),
+			})
+
+			i = end + 1
+		}
+	}
+
+	return tokens, nil
+}
+
+func splitArgs(args string) []string {
+	parts := strings.Split(args, ",")
+
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+
+	return parts
+}
@@ -651,6 +651,46 @@ func TestEvaluate(t *testing.T) {
 		require.Equal(t, true, ok)
 	})
 
+	t.Run("success - policy with witness groups", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		err = configStore.Put(WitnessPolicyKey,
+			[]byte(`"Group(orgA,witness-1,witness-2) Group(orgB,witness-3) OutOf(2,orgA) AND OutOf(1,orgB)"`))
+		require.NoError(t, err)
+
+		wp, err := New(configStore, defaultPolicyCacheExpiry)
+		require.NoError(t, err)
+		require.NotNil(t, wp)
+
+		witnessProofs := []*proof.WitnessProof{
+			{
+				Type:    proof.WitnessTypeBatch,
+				Witness: "witness-1",
+				Proof:   []byte("proof"),
+			},
+			{
+				Type:    proof.WitnessTypeSystem,
+				Witness: "witness-2",
+				Proof:   []byte("proof"),
+			},
+			{
+				Type:    proof.WitnessTypeSystem,
+				Witness: "witness-3",
+			},
+		}
+
+		ok, err := wp.Evaluate(witnessProofs)
+		require.NoError(t, err)
+		require.Equal(t, false, ok)
+
+		witnessProofs[2].Proof = []byte("proof")
+
+		ok, err = wp.Evaluate(witnessProofs)
+		require.NoError(t, err)
+		require.Equal(t, true, ok)
+	})
+
 	t.Run("error - get policy from cache error", func(t *testing.T) {
 		configStore, err := mem.NewProvider().OpenStore(configStoreName)
 		require.NoError(t, err)
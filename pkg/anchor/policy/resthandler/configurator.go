@@ -23,6 +23,7 @@ const endpoint = "/policy"
 
 const (
 	badRequestResponse          = "Bad Request."
+	invalidPolicyResponse       = "Invalid witness policy: "
 	internalServerErrorResponse = "Internal Server Error."
 )
 
@@ -76,7 +77,7 @@ func (pc *PolicyConfigurator) handle(w http.ResponseWriter, req *http.Request) {
 	if err != nil {
 		logger.Errorf("[%s] Invalid witness policy: %s", endpoint, err)
 
-		writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+		writeResponse(w, http.StatusBadRequest, []byte(invalidPolicyResponse+err.Error()))
 
 		return
 	}
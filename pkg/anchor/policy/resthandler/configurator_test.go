@@ -100,7 +100,28 @@ func TestHandler(t *testing.T) {
 
 		respBytes, err := ioutil.ReadAll(result.Body)
 		require.NoError(t, err)
-		require.Equal(t, []byte(badRequestResponse), respBytes)
+		require.Equal(t, invalidPolicyResponse+"rule not supported: InvalidPolicy", string(respBytes))
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("error - undefined witness group", func(t *testing.T) {
+		configStore, err := mem.NewProvider().OpenStore(configStoreName)
+		require.NoError(t, err)
+
+		policyConfigurator := New(configStore)
+		require.NotNil(t, policyConfigurator)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, endpoint, bytes.NewBuffer([]byte("OutOf(2,orgA)")))
+
+		policyConfigurator.handle(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.Equal(t, invalidPolicyResponse+"role 'orgA' not supported for OutOf policy", string(respBytes))
 		require.NoError(t, result.Body.Close())
 	})
 
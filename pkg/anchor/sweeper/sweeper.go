@@ -0,0 +1,637 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sweeper
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	txnapi "github.com/trustbloc/sidetree-core-go/pkg/api/txn"
+
+	"github.com/trustbloc/orb/pkg/activitypub/vocab"
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+var logger = log.New("anchor-sweeper")
+
+const (
+	storeName = "anchor-sweeper"
+
+	// leaseKey is the key of the lease entry in the store. The key can't clash with the deadline entries,
+	// which are keyed by anchor credential ID (a URL).
+	leaseKey = "sweep-lease"
+
+	// defaultLeaseIntervals is the default duration of the lease as a multiple of the sweep interval.
+	defaultLeaseIntervals = 3
+)
+
+// Action defines the action that is taken for an anchor credential whose witnesses did not respond in time.
+type Action string
+
+const (
+	// ActionRewitness indicates that the expired anchor credential is offered to the configured alternate
+	// witnesses. Each witness that did not respond is replaced by an alternate witness which takes on the same
+	// role (batch or system), so the number of witnesses in each role is unchanged. If all of the alternate
+	// witnesses have already been offered the anchor credential then the anchor credential is abandoned.
+	ActionRewitness Action = "rewitness"
+
+	// ActionAbandon indicates that the expired anchor credential is abandoned and its operations are added
+	// back to the operation queue so that they are included in a new batch.
+	ActionAbandon Action = "abandon"
+)
+
+// Config contains the configuration for the sweeper.
+type Config struct {
+	// Interval is the interval at which in-process anchor credentials are checked for expiry.
+	Interval time.Duration
+
+	// MaxWitnessDelay is the maximum time that witnesses have to respond to an offer.
+	MaxWitnessDelay time.Duration
+
+	// Action is the action taken for an expired anchor credential.
+	Action Action
+
+	// AlternateWitnesses are the witnesses to which an expired anchor credential is offered
+	// (only applies to ActionRewitness).
+	AlternateWitnesses []*url.URL
+
+	// LeaseDuration is the duration of the lease that an instance must hold in order to perform the sweep.
+	// The lease is renewed on each sweep and, if the instance holding the lease goes down, another instance
+	// acquires the lease after it expires. Defaults to three times the interval.
+	LeaseDuration time.Duration
+}
+
+type vcStore interface {
+	Get(id string) (*verifiable.Credential, error)
+}
+
+type vcStatusStore interface {
+	GetVCIDsByStatus(status proof.VCStatus) ([]string, error)
+	GetStatus(vcID string) (proof.VCStatus, error)
+	Delete(vcID string) error
+}
+
+type witnessStore interface {
+	Replace(vcID string, witnesses []*proof.WitnessProof) error
+	Get(vcID string) ([]*proof.WitnessProof, error)
+	Delete(vcID string) error
+}
+
+type operationQueue interface {
+	Add(op *operation.QueuedOperation, protocolGenesisTime uint64) (uint, error)
+}
+
+type outbox interface {
+	Post(activity *vocab.ActivityType) (*url.URL, error)
+}
+
+type webfingerClient interface {
+	HasSupportedLedgerType(domain string) (bool, error)
+}
+
+type metricsProvider interface {
+	ExpiredAnchorRewitnessed()
+	ExpiredAnchorAbandoned()
+	ExpiredAnchorOperationsRequeued(count int)
+}
+
+// Providers contains all of the providers required by the sweeper.
+type Providers struct {
+	VCStore                vcStore
+	VCStatusStore          vcStatusStore
+	WitnessStore           witnessStore
+	OpQueue                operationQueue
+	ProtocolClientProvider protocol.ClientProvider
+	Outbox                 outbox
+	WFClient               webfingerClient
+}
+
+// Service periodically scans for in-process anchor credentials whose witnesses did not respond within the
+// maximum witness delay and either offers them to an alternate set of witnesses or abandons them by
+// adding their operations back to the operation queue. Stale entries are purged from the witness
+// and VC status stores.
+//
+// In a clustered deployment, only the instance which holds the sweep lease (stored in the shared database)
+// performs the sweep so that an expired anchor credential isn't handled by more than one instance.
+type Service struct {
+	*Providers
+	*lifecycle.Lifecycle
+
+	store      storage.Store
+	done       chan struct{}
+	config     Config
+	metrics    metricsProvider
+	instanceID string
+}
+
+type deadlineEntry struct {
+	Deadline time.Time `json:"deadline"`
+}
+
+type leaseEntry struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+// New returns a new anchor credential sweeper.
+func New(cfg Config, providers *Providers, provider storage.Provider, metrics metricsProvider) (*Service, error) {
+	if cfg.Action != ActionRewitness && cfg.Action != ActionAbandon {
+		return nil, fmt.Errorf("invalid action [%s]", cfg.Action)
+	}
+
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = defaultLeaseIntervals * cfg.Interval
+	}
+
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	s := &Service{
+		Providers:  providers,
+		store:      store,
+		done:       make(chan struct{}),
+		config:     cfg,
+		metrics:    metrics,
+		instanceID: uuid.New().String(),
+	}
+
+	s.Lifecycle = lifecycle.New("anchor-sweeper",
+		lifecycle.WithStart(s.start),
+		lifecycle.WithStop(s.stop))
+
+	return s, nil
+}
+
+func (s *Service) start() {
+	go s.run()
+
+	logger.Infof("Started anchor credential sweeper - Action [%s], Interval [%s], Alternate witnesses %s",
+		s.config.Action, s.config.Interval, s.config.AlternateWitnesses)
+}
+
+func (s *Service) stop() {
+	close(s.done)
+
+	logger.Infof("Stopped anchor credential sweeper")
+}
+
+func (s *Service) run() {
+	for {
+		select {
+		case <-time.After(s.config.Interval):
+			s.sweep()
+		case <-s.done:
+			logger.Debugf("Exiting anchor credential sweeper.")
+
+			return
+		}
+	}
+}
+
+func (s *Service) sweep() {
+	acquired, err := s.acquireLease()
+	if err != nil {
+		logger.Errorf("Error acquiring sweep lease: %s", err)
+
+		return
+	}
+
+	if !acquired {
+		logger.Debugf("Sweep lease is held by another instance.")
+
+		return
+	}
+
+	vcIDs, err := s.VCStatusStore.GetVCIDsByStatus(proof.VCStatusInProcess)
+	if err != nil {
+		logger.Errorf("Error retrieving in-process anchor credentials: %s", err)
+
+		return
+	}
+
+	logger.Debugf("Checking %d in-process anchor credentials for expiry", len(vcIDs))
+
+	for _, vcID := range vcIDs {
+		if err := s.handle(vcID); err != nil {
+			logger.Warnf("Error handling in-process anchor credential [%s]: %s", vcID, err)
+		}
+	}
+}
+
+func (s *Service) handle(vcID string) error {
+	status, err := s.VCStatusStore.GetStatus(vcID)
+	if err != nil {
+		return fmt.Errorf("get status: %w", err)
+	}
+
+	if status == proof.VCStatusCompleted {
+		// The witness policy was satisfied. Remove the deadline (if any).
+		return s.deleteDeadline(vcID)
+	}
+
+	vc, err := s.VCStore.Get(vcID)
+	if err != nil {
+		return fmt.Errorf("get anchor credential: %w", err)
+	}
+
+	deadline, err := s.getDeadline(vc)
+	if err != nil {
+		return err
+	}
+
+	if time.Now().Before(deadline) {
+		logger.Debugf("Anchor credential [%s] has not expired. Deadline: %s", vcID, deadline)
+
+		return nil
+	}
+
+	logger.Infof("Witnesses did not respond in time for anchor credential [%s]. Deadline: %s", vcID, deadline)
+
+	if s.config.Action == ActionRewitness {
+		rewitnessed, e := s.rewitness(vc)
+		if e != nil {
+			return fmt.Errorf("rewitness: %w", e)
+		}
+
+		if rewitnessed {
+			s.metrics.ExpiredAnchorRewitnessed()
+
+			return nil
+		}
+
+		logger.Infof("No alternate witnesses are available for anchor credential [%s]. The anchor credential "+
+			"will be abandoned.", vcID)
+	}
+
+	abandoned, err := s.abandon(vc)
+	if err != nil {
+		return fmt.Errorf("abandon: %w", err)
+	}
+
+	if abandoned {
+		s.metrics.ExpiredAnchorAbandoned()
+	}
+
+	return nil
+}
+
+// rewitness offers the given anchor credential to the alternate witnesses that have not yet been offered
+// the anchor credential. Each witness that didn't respond is replaced by an alternate witness in the same role
+// so that the number of witnesses in each role (i.e. the denominators used by the witness policy) is unchanged
+// and a role can't end up empty, which would satisfy the policy vacuously. If there are fewer alternates than
+// witnesses that didn't respond then the remaining witnesses are kept (they may still respond). False is
+// returned if no witness could be replaced, e.g. if the witnesses of the anchor credential aren't known.
+func (s *Service) rewitness(vc *verifiable.Credential) (bool, error) {
+	witnesses, err := s.getWitnesses(vc.ID)
+	if err != nil {
+		return false, err
+	}
+
+	alternates := s.getAlternateWitnesses(witnesses)
+
+	var (
+		newWitnesses []*proof.WitnessProof
+		offered      []*url.URL
+	)
+
+	for _, w := range witnesses {
+		if w.Proof != nil || len(alternates) == 0 {
+			newWitnesses = append(newWitnesses, w)
+
+			continue
+		}
+
+		alternate := alternates[0]
+		alternates = alternates[1:]
+
+		hasLog, e := s.WFClient.HasSupportedLedgerType(fmt.Sprintf("%s://%s", alternate.Scheme, alternate.Host))
+		if e != nil {
+			return false, fmt.Errorf("resolve ledger type for witness [%s]: %w", alternate, e)
+		}
+
+		logger.Debugf("Replacing %s witness [%s] with [%s] for anchor credential [%s]",
+			w.Type, w.Witness, alternate, vc.ID)
+
+		newWitnesses = append(newWitnesses,
+			&proof.WitnessProof{
+				Type:    w.Type,
+				Witness: alternate.String(),
+				HasLog:  hasLog,
+			})
+
+		offered = append(offered, alternate)
+	}
+
+	if len(offered) == 0 {
+		return false, nil
+	}
+
+	startTime := time.Now()
+	endTime := startTime.Add(s.config.MaxWitnessDelay)
+
+	// Store the witnesses and the new deadline before posting the offer since the proof handler may
+	// be invoked before the offer returns.
+	err = s.WitnessStore.Replace(vc.ID, newWitnesses)
+	if err != nil {
+		return false, fmt.Errorf("replace witnesses: %w", err)
+	}
+
+	err = s.putDeadline(vc.ID, endTime)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.postOfferActivity(vc, offered, startTime, endTime)
+	if err != nil {
+		return false, err
+	}
+
+	logger.Infof("Offered anchor credential [%s] to alternate witnesses %s", vc.ID, offered)
+
+	return true, nil
+}
+
+func (s *Service) getWitnesses(vcID string) ([]*proof.WitnessProof, error) {
+	witnesses, err := s.WitnessStore.Get(vcID)
+	if err != nil {
+		if orberrors.IsTransient(err) {
+			return nil, fmt.Errorf("get witnesses: %w", err)
+		}
+
+		// The witnesses were not found.
+		logger.Debugf("No witnesses found for anchor credential [%s]: %s", vcID, err)
+
+		return nil, nil
+	}
+
+	return witnesses, nil
+}
+
+func (s *Service) getAlternateWitnesses(witnesses []*proof.WitnessProof) []*url.URL {
+	offered := make(map[string]bool)
+
+	for _, w := range witnesses {
+		offered[w.Witness] = true
+	}
+
+	var alternates []*url.URL
+
+	for _, w := range s.config.AlternateWitnesses {
+		if !offered[w.String()] {
+			alternates = append(alternates, w)
+		}
+	}
+
+	return alternates
+}
+
+func (s *Service) postOfferActivity(vc *verifiable.Credential, witnesses []*url.URL,
+	startTime, endTime time.Time) error {
+	bytes, err := vc.MarshalJSON()
+	if err != nil {
+		return fmt.Errorf("marshal anchor credential: %w", err)
+	}
+
+	obj, err := vocab.NewObjectWithDocument(vocab.MustUnmarshalToDoc(bytes))
+	if err != nil {
+		return fmt.Errorf("create new object with document: %w", err)
+	}
+
+	offer := vocab.NewOfferActivity(
+		vocab.NewObjectProperty(vocab.WithObject(obj)),
+		vocab.WithTo(append(witnesses, vocab.PublicIRI)...),
+		vocab.WithStartTime(&startTime),
+		vocab.WithEndTime(&endTime),
+		vocab.WithTarget(vocab.NewObjectProperty(vocab.WithIRI(vocab.AnchorWitnessTargetIRI))),
+	)
+
+	postID, err := s.Outbox.Post(offer)
+	if err != nil {
+		return fmt.Errorf("post offer for anchor credential [%s]: %w", vc.ID, err)
+	}
+
+	logger.Debugf("Posted offer activity [%s] for anchor credential [%s]", postID, vc.ID)
+
+	return nil
+}
+
+// abandon adds the operations of the given anchor credential back to the operation queue and
+// purges all state associated with the anchor credential. Before the operations are added back to the
+// queue, the sweep lease is renewed and the status of the anchor credential is checked again since a
+// (late) proof may have satisfied the witness policy after the sweep started, in which case the
+// operations would be anchored twice. False is returned if the anchor credential was not abandoned.
+func (s *Service) abandon(vc *verifiable.Credential) (bool, error) {
+	held, err := s.acquireLease()
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+
+	if !held {
+		return false, fmt.Errorf("sweep lease was acquired by another instance")
+	}
+
+	status, err := s.VCStatusStore.GetStatus(vc.ID)
+	if err != nil {
+		return false, fmt.Errorf("get status: %w", err)
+	}
+
+	if status == proof.VCStatusCompleted {
+		logger.Infof("Witness policy was satisfied for anchor credential [%s] during the sweep. "+
+			"The anchor credential will not be abandoned.", vc.ID)
+
+		return false, s.deleteDeadline(vc.ID)
+	}
+
+	payload, err := util.GetAnchorSubject(vc)
+	if err != nil {
+		return false, fmt.Errorf("get anchor subject: %w", err)
+	}
+
+	ops, err := s.getOperations(payload)
+	if err != nil {
+		return false, err
+	}
+
+	for _, op := range ops {
+		_, err = s.OpQueue.Add(
+			&operation.QueuedOperation{
+				OperationBuffer: op.OperationBuffer,
+				UniqueSuffix:    op.UniqueSuffix,
+				Namespace:       payload.Namespace,
+				AnchorOrigin:    op.AnchorOrigin,
+			}, payload.Version,
+		)
+		if err != nil {
+			return false, fmt.Errorf("add operation for suffix [%s] to queue: %w", op.UniqueSuffix, err)
+		}
+	}
+
+	s.metrics.ExpiredAnchorOperationsRequeued(len(ops))
+
+	logger.Infof("Added %d operations from abandoned anchor credential [%s] back to the operation queue",
+		len(ops), vc.ID)
+
+	return true, s.purge(vc.ID)
+}
+
+func (s *Service) getOperations(payload *subject.Payload) ([]*operation.AnchoredOperation, error) {
+	pc, err := s.ProtocolClientProvider.ForNamespace(payload.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol client for namespace [%s]: %w", payload.Namespace, err)
+	}
+
+	v, err := pc.Get(payload.Version)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol version [%d]: %w", payload.Version, err)
+	}
+
+	ad := &util.AnchorData{OperationCount: payload.OperationCount, CoreIndexFileURI: payload.CoreIndex}
+
+	ops, err := v.OperationProvider().GetTxnOperations(
+		&txnapi.SidetreeTxn{
+			AnchorString:        ad.GetAnchorString(),
+			Namespace:           payload.Namespace,
+			ProtocolGenesisTime: payload.Version,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("get operations for core index [%s]: %w", payload.CoreIndex, err)
+	}
+
+	return ops, nil
+}
+
+func (s *Service) purge(vcID string) error {
+	err := s.WitnessStore.Delete(vcID)
+	if err != nil {
+		return fmt.Errorf("delete witnesses: %w", err)
+	}
+
+	err = s.VCStatusStore.Delete(vcID)
+	if err != nil {
+		return fmt.Errorf("delete status: %w", err)
+	}
+
+	return s.deleteDeadline(vcID)
+}
+
+// acquireLease acquires (or renews) the sweep lease. False is returned if the lease is held by another instance.
+func (s *Service) acquireLease() (bool, error) {
+	now := time.Now()
+
+	lease, err := s.getLease()
+	if err != nil {
+		return false, err
+	}
+
+	if lease.Holder != s.instanceID && now.Before(lease.Expires) {
+		return false, nil
+	}
+
+	entryBytes, err := json.Marshal(&leaseEntry{Holder: s.instanceID, Expires: now.Add(s.config.LeaseDuration)})
+	if err != nil {
+		return false, fmt.Errorf("marshal lease: %w", err)
+	}
+
+	err = s.store.Put(leaseKey, entryBytes)
+	if err != nil {
+		return false, orberrors.NewTransient(fmt.Errorf("store lease: %w", err))
+	}
+
+	// The store doesn't support conditional writes, so read the lease back in case another instance
+	// acquired the lease at the same time (the last write wins).
+	lease, err = s.getLease()
+	if err != nil {
+		return false, err
+	}
+
+	return lease.Holder == s.instanceID, nil
+}
+
+func (s *Service) getLease() (*leaseEntry, error) {
+	entryBytes, err := s.store.Get(leaseKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			// No instance has acquired the lease yet.
+			return &leaseEntry{}, nil
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("get lease: %w", err))
+	}
+
+	lease := &leaseEntry{}
+
+	err = json.Unmarshal(entryBytes, lease)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal lease: %w", err)
+	}
+
+	return lease, nil
+}
+
+func (s *Service) getDeadline(vc *verifiable.Credential) (time.Time, error) {
+	entryBytes, err := s.store.Get(vc.ID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrDataNotFound) {
+			return time.Time{}, orberrors.NewTransient(fmt.Errorf("get deadline: %w", err))
+		}
+
+		// The anchor credential has not been re-offered so the deadline is calculated from the issue date.
+		if vc.Issued == nil {
+			return time.Time{}, fmt.Errorf("issue date not found in anchor credential [%s]", vc.ID)
+		}
+
+		return vc.Issued.Add(s.config.MaxWitnessDelay), nil
+	}
+
+	entry := &deadlineEntry{}
+
+	err = json.Unmarshal(entryBytes, entry)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unmarshal deadline: %w", err)
+	}
+
+	return entry.Deadline, nil
+}
+
+func (s *Service) putDeadline(vcID string, deadline time.Time) error {
+	entryBytes, err := json.Marshal(&deadlineEntry{Deadline: deadline})
+	if err != nil {
+		return fmt.Errorf("marshal deadline: %w", err)
+	}
+
+	err = s.store.Put(vcID, entryBytes)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store deadline: %w", err))
+	}
+
+	return nil
+}
+
+func (s *Service) deleteDeadline(vcID string) error {
+	err := s.store.Delete(vcID)
+	if err != nil && !errors.Is(err, storage.ErrDataNotFound) {
+		return orberrors.NewTransient(fmt.Errorf("delete deadline: %w", err))
+	}
+
+	return nil
+}
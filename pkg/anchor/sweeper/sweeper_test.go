@@ -0,0 +1,755 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package sweeper
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	coremocks "github.com/trustbloc/sidetree-core-go/pkg/mocks"
+
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/proof"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/vcstatus"
+	vcstore "github.com/trustbloc/orb/pkg/store/verifiable"
+	"github.com/trustbloc/orb/pkg/store/witness"
+)
+
+const (
+	namespace = "did:orb"
+	vcID      = "https://orb.domain1.com/vc/1234"
+)
+
+var (
+	witness1 = testutil.MustParseURL("https://orb.domain2.com/services/orb")
+	witness2 = testutil.MustParseURL("https://orb.domain3.com/services/orb")
+	witness3 = testutil.MustParseURL("https://orb.domain4.com/services/orb")
+	witness4 = testutil.MustParseURL("https://orb.domain5.com/services/orb")
+)
+
+func TestNew(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		s, err := New(Config{Action: ActionAbandon, Interval: time.Second}, &Providers{}, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+		require.NotNil(t, s)
+		require.Equal(t, 3*time.Second, s.config.LeaseDuration)
+
+		s.Start()
+		s.Stop()
+	})
+
+	t.Run("Invalid action", func(t *testing.T) {
+		s, err := New(Config{Action: "invalid"}, &Providers{}, mem.NewProvider(), &orbmocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid action")
+		require.Nil(t, s)
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		p := &mockStoreProvider{Provider: mem.NewProvider(), openErr: errors.New("injected open error")}
+
+		s, err := New(Config{Action: ActionAbandon}, &Providers{}, p, &orbmocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected open error")
+		require.Nil(t, s)
+	})
+}
+
+func TestService_Sweep(t *testing.T) {
+	t.Run("Not expired", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now(), proof.VCStatusInProcess, witness1)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+
+		status, err := p.VCStatusStore.GetStatus(vcID)
+		require.NoError(t, err)
+		require.Equal(t, proof.VCStatusInProcess, status)
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		err := p.vcStatusStore.AddStatus(vcID, proof.VCStatusCompleted)
+		require.NoError(t, err)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+
+		witnesses, err := p.WitnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 1)
+	})
+
+	t.Run("Abandon", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Len(t, p.opQueue.ops, 2)
+		require.Empty(t, p.outbox.Activities())
+
+		_, err = p.WitnessStore.Get(vcID)
+		require.Error(t, err)
+
+		_, err = p.VCStatusStore.GetStatus(vcID)
+		require.Error(t, err)
+	})
+
+	t.Run("Rewitness", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1, witness2)
+
+		err := p.witnessStore.AddProof(vcID, witness2.String(), []byte(`{}`))
+		require.NoError(t, err)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness1, witness3},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+		require.Len(t, p.outbox.Activities(), 1)
+
+		witnesses, err := p.WitnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 2)
+
+		for _, w := range witnesses {
+			require.NotEqual(t, witness1.String(), w.Witness)
+		}
+
+		// The new deadline hasn't passed so nothing should happen.
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+		require.Len(t, p.outbox.Activities(), 1)
+	})
+
+	t.Run("Rewitness -> roles are retained", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVCWithWitnesses(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess,
+			&proof.WitnessProof{Type: proof.WitnessTypeBatch, Witness: witness1.String()},
+			&proof.WitnessProof{Type: proof.WitnessTypeSystem, Witness: witness2.String()},
+		)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness3, witness4},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+		require.Len(t, p.outbox.Activities(), 1)
+
+		witnesses, err := p.WitnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 2)
+
+		// The witnesses are stored in no particular order, so each unresponsive witness may be replaced by either
+		// alternate, but the number of witnesses in each role is unchanged.
+		var (
+			names []string
+			types []proof.WitnessType
+		)
+
+		for _, w := range witnesses {
+			names = append(names, w.Witness)
+			types = append(types, w.Type)
+		}
+
+		require.ElementsMatch(t, []string{witness3.String(), witness4.String()}, names)
+		require.ElementsMatch(t, []proof.WitnessType{proof.WitnessTypeBatch, proof.WitnessTypeSystem}, types)
+	})
+
+	t.Run("Rewitness -> fewer alternates than unresponsive witnesses", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1, witness2)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness3},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Empty(t, p.opQueue.ops)
+		require.Len(t, p.outbox.Activities(), 1)
+
+		witnesses, err := p.WitnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 2)
+
+		// The witness that couldn't be replaced is retained so that the number of batch witnesses is unchanged.
+		var names []string
+
+		for _, w := range witnesses {
+			require.Equal(t, proof.WitnessTypeBatch, w.Type)
+
+			names = append(names, w.Witness)
+		}
+
+		require.Contains(t, names, witness3.String())
+
+		retained := names[0]
+		if retained == witness3.String() {
+			retained = names[1]
+		}
+
+		require.Contains(t, []string{witness1.String(), witness2.String()}, retained)
+	})
+
+	t.Run("Rewitness -> no witnesses -> abandon", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness3},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Len(t, p.opQueue.ops, 2)
+		require.Empty(t, p.outbox.Activities())
+	})
+
+	t.Run("Rewitness -> no alternates -> abandon", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness1},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		s.sweep()
+
+		require.Len(t, p.opQueue.ops, 2)
+		require.Empty(t, p.outbox.Activities())
+	})
+
+	t.Run("Outbox error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.outbox.WithError(errors.New("injected outbox error"))
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness2},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected outbox error")
+	})
+
+	t.Run("Webfinger error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.wfClient.err = errors.New("injected webfinger error")
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(
+			Config{
+				Action:             ActionRewitness,
+				MaxWitnessDelay:    time.Minute,
+				AlternateWitnesses: []*url.URL{witness2},
+			},
+			p.Providers, mem.NewProvider(), &orbmocks.MetricsProvider{},
+		)
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected webfinger error")
+	})
+
+	t.Run("Operation queue error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.opQueue.err = errors.New("injected queue error")
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected queue error")
+
+		status, err := p.VCStatusStore.GetStatus(vcID)
+		require.NoError(t, err)
+		require.Equal(t, proof.VCStatusInProcess, status)
+	})
+
+	t.Run("Protocol client error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.pcp.err = errors.New("injected protocol error")
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected protocol error")
+	})
+
+	t.Run("VC not found", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		err := p.vcStatusStore.AddStatus(vcID, proof.VCStatusInProcess)
+		require.NoError(t, err)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get anchor credential")
+	})
+
+	t.Run("Get deadline error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		sp := &mockStoreProvider{Provider: mem.NewProvider(), getErr: errors.New("injected get error")}
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, sp,
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		err = s.handle(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected get error")
+	})
+}
+
+func TestService_Abandon(t *testing.T) {
+	t.Run("Completed during sweep -> not abandoned", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, mem.NewProvider(),
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		vc, err := p.VCStore.Get(vcID)
+		require.NoError(t, err)
+
+		// A late proof satisfied the witness policy after the sweep started.
+		require.NoError(t, p.vcStatusStore.AddStatus(vcID, proof.VCStatusCompleted))
+
+		abandoned, err := s.abandon(vc)
+		require.NoError(t, err)
+		require.False(t, abandoned)
+		require.Empty(t, p.opQueue.ops)
+
+		witnesses, err := p.WitnessStore.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 1)
+	})
+
+	t.Run("Lease acquired by another instance -> not abandoned", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		storeProvider := mem.NewProvider()
+
+		cfg := Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute, LeaseDuration: time.Minute}
+
+		s1, err := New(cfg, p.Providers, storeProvider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s2, err := New(cfg, p.Providers, storeProvider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		acquired, err := s2.acquireLease()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		vc, err := p.VCStore.Get(vcID)
+		require.NoError(t, err)
+
+		abandoned, err := s1.abandon(vc)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "sweep lease was acquired by another instance")
+		require.False(t, abandoned)
+		require.Empty(t, p.opQueue.ops)
+	})
+}
+
+func TestService_Lease(t *testing.T) {
+	t.Run("Only the lease holder sweeps", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		cfg := Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute, LeaseDuration: 100 * time.Millisecond}
+
+		storeProvider := mem.NewProvider()
+
+		s1, err := New(cfg, p.Providers, storeProvider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		s2, err := New(cfg, p.Providers, storeProvider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		acquired, err := s1.acquireLease()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		// The lease is held by the first instance so the second instance doesn't sweep.
+		s2.sweep()
+		require.Empty(t, p.opQueue.ops)
+
+		// The lease holder renews the lease.
+		acquired, err = s1.acquireLease()
+		require.NoError(t, err)
+		require.True(t, acquired)
+
+		time.Sleep(200 * time.Millisecond)
+
+		// The lease expired so the second instance acquires it.
+		s2.sweep()
+		require.Len(t, p.opQueue.ops, 2)
+
+		acquired, err = s1.acquireLease()
+		require.NoError(t, err)
+		require.False(t, acquired)
+	})
+
+	t.Run("Get lease error", func(t *testing.T) {
+		p := newTestProviders(t)
+
+		p.addVC(t, time.Now().Add(-time.Hour), proof.VCStatusInProcess, witness1)
+
+		sp := &mockStoreProvider{Provider: mem.NewProvider(), getErr: errors.New("injected get error")}
+
+		s, err := New(Config{Action: ActionAbandon, MaxWitnessDelay: time.Minute}, p.Providers, sp,
+			&orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		_, err = s.acquireLease()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected get error")
+
+		s.sweep()
+		require.Empty(t, p.opQueue.ops)
+	})
+
+	t.Run("Store lease error", func(t *testing.T) {
+		sp := &mockStoreProvider{Provider: mem.NewProvider(), putErr: errors.New("injected put error")}
+
+		s, err := New(Config{Action: ActionAbandon}, &Providers{}, sp, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		_, err = s.acquireLease()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected put error")
+	})
+
+	t.Run("Unmarshal lease error", func(t *testing.T) {
+		storeProvider := mem.NewProvider()
+
+		s, err := New(Config{Action: ActionAbandon}, &Providers{}, storeProvider, &orbmocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		require.NoError(t, s.store.Put(leaseKey, []byte("invalid")))
+
+		_, err = s.acquireLease()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal lease")
+	})
+}
+
+type testProviders struct {
+	*Providers
+
+	vcStore       *vcstore.Store
+	vcStatusStore *vcstatus.Store
+	witnessStore  *witness.Store
+	opQueue       *mockOpQueue
+	outbox        *mocks.Outbox
+	pcp           *mockProtocolClientProvider
+	wfClient      *mockWFClient
+}
+
+func newTestProviders(t *testing.T) *testProviders {
+	t.Helper()
+
+	vcs, err := vcstore.New(mem.NewProvider(), testutil.GetLoader(t))
+	require.NoError(t, err)
+
+	vcss, err := vcstatus.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	ws, err := witness.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	opProvider := &coremocks.OperationProvider{}
+	opProvider.GetTxnOperationsReturns([]*operation.AnchoredOperation{
+		{UniqueSuffix: "suffix1", OperationBuffer: []byte("op1")},
+		{UniqueSuffix: "suffix2", OperationBuffer: []byte("op2")},
+	}, nil)
+
+	pv := &coremocks.ProtocolVersion{}
+	pv.OperationProviderReturns(opProvider)
+
+	p := &testProviders{
+		vcStore:       vcs,
+		vcStatusStore: vcss,
+		witnessStore:  ws,
+		opQueue:       &mockOpQueue{},
+		outbox:        mocks.NewOutbox(),
+		pcp:           &mockProtocolClientProvider{version: pv},
+		wfClient:      &mockWFClient{},
+	}
+
+	p.Providers = &Providers{
+		VCStore:                vcs,
+		VCStatusStore:          vcss,
+		WitnessStore:           ws,
+		OpQueue:                p.opQueue,
+		ProtocolClientProvider: p.pcp,
+		Outbox:                 p.outbox,
+		WFClient:               p.wfClient,
+	}
+
+	return p
+}
+
+func (p *testProviders) addVC(t *testing.T, issued time.Time, status proof.VCStatus, witnesses ...*url.URL) {
+	t.Helper()
+
+	var witnessProofs []*proof.WitnessProof
+
+	for _, w := range witnesses {
+		witnessProofs = append(witnessProofs, &proof.WitnessProof{
+			Type:    proof.WitnessTypeBatch,
+			Witness: w.String(),
+		})
+	}
+
+	p.addVCWithWitnesses(t, issued, status, witnessProofs...)
+}
+
+func (p *testProviders) addVCWithWitnesses(t *testing.T, issued time.Time, status proof.VCStatus,
+	witnessProofs ...*proof.WitnessProof) {
+	t.Helper()
+
+	act, err := activity.BuildActivityFromPayload(&subject.Payload{
+		OperationCount:  2,
+		CoreIndex:       "coreIndex",
+		Namespace:       namespace,
+		Version:         0,
+		PreviousAnchors: map[string]string{"suffix1": "", "suffix2": ""},
+	})
+	require.NoError(t, err)
+
+	vc := &verifiable.Credential{
+		ID:      vcID,
+		Types:   []string{"VerifiableCredential"},
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Subject: act,
+		Issuer:  verifiable.Issuer{ID: "https://orb.domain1.com"},
+		Issued:  &util.TimeWithTrailingZeroMsec{Time: issued},
+	}
+
+	require.NoError(t, p.vcStore.Put(vc))
+	require.NoError(t, p.vcStatusStore.AddStatus(vcID, status))
+
+	if len(witnessProofs) > 0 {
+		require.NoError(t, p.witnessStore.Put(vcID, witnessProofs))
+	}
+}
+
+type mockOpQueue struct {
+	mutex sync.Mutex
+	ops   []*operation.QueuedOperation
+	err   error
+}
+
+func (m *mockOpQueue) Add(op *operation.QueuedOperation, _ uint64) (uint, error) {
+	if m.err != nil {
+		return 0, m.err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.ops = append(m.ops, op)
+
+	return uint(len(m.ops)), nil
+}
+
+type mockProtocolClientProvider struct {
+	version protocol.Version
+	err     error
+}
+
+func (m *mockProtocolClientProvider) ForNamespace(string) (protocol.Client, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	return &mockProtocolClient{version: m.version}, nil
+}
+
+type mockProtocolClient struct {
+	version protocol.Version
+}
+
+func (m *mockProtocolClient) Current() (protocol.Version, error) {
+	return m.version, nil
+}
+
+func (m *mockProtocolClient) Get(uint64) (protocol.Version, error) {
+	return m.version, nil
+}
+
+type mockWFClient struct {
+	err error
+}
+
+func (m *mockWFClient) HasSupportedLedgerType(string) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+
+	return true, nil
+}
+
+type mockStoreProvider struct {
+	storage.Provider
+
+	openErr error
+	getErr  error
+	putErr  error
+}
+
+func (m *mockStoreProvider) OpenStore(name string) (storage.Store, error) {
+	if m.openErr != nil {
+		return nil, m.openErr
+	}
+
+	s, err := m.Provider.OpenStore(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mockStore{Store: s, getErr: m.getErr, putErr: m.putErr}, nil
+}
+
+type mockStore struct {
+	storage.Store
+
+	getErr error
+	putErr error
+}
+
+func (m *mockStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if m.putErr != nil {
+		return m.putErr
+	}
+
+	return m.Store.Put(key, value, tags...)
+}
+
+func (m *mockStore) Get(key string) ([]byte, error) {
+	if m.getErr != nil {
+		return nil, m.getErr
+	}
+
+	return m.Store.Get(key)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"fmt"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+)
+
+// FileReader returns the (compressed) content of the Sidetree file with the given URI. If the file is not
+// available then nil content and a nil error may be returned, in which case the files that are referenced
+// by the file are not included.
+type FileReader func(uri string) ([]byte, error)
+
+// GetSidetreeFileURIs returns the URIs of all of the Sidetree files that are referenced by the given anchor, i.e.
+// the anchor attachments and the core index file along with the core proof, provisional index, provisional proof
+// and chunk files that are referenced by the index files. The attachments should contain all of the files,
+// although the index files are also parsed in case they don't.
+func GetSidetreeFileURIs(payload *subject.Payload, pcp protocol.ClientProvider, read FileReader) ([]string, error) {
+	uris := newURISet(payload.CoreIndex)
+	uris.add(payload.Attachments...)
+
+	content, err := readFile(payload, pcp, read, payload.CoreIndex)
+	if err != nil || content == nil {
+		return uris.values, err
+	}
+
+	coreIndexFile, err := models.ParseCoreIndexFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse core index file [%s]: %w", payload.CoreIndex, err)
+	}
+
+	uris.add(coreIndexFile.CoreProofFileURI, coreIndexFile.ProvisionalIndexFileURI)
+
+	if coreIndexFile.ProvisionalIndexFileURI == "" {
+		return uris.values, nil
+	}
+
+	content, err = readFile(payload, pcp, read, coreIndexFile.ProvisionalIndexFileURI)
+	if err != nil || content == nil {
+		return uris.values, err
+	}
+
+	provisionalIndexFile, err := models.ParseProvisionalIndexFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse provisional index file [%s]: %w", coreIndexFile.ProvisionalIndexFileURI, err)
+	}
+
+	uris.add(provisionalIndexFile.ProvisionalProofFileURI)
+
+	for _, chunk := range provisionalIndexFile.Chunks {
+		uris.add(chunk.ChunkFileURI)
+	}
+
+	return uris.values, nil
+}
+
+// readFile reads the given file and decompresses it using the compression algorithm of the anchor's protocol version.
+func readFile(payload *subject.Payload, pcp protocol.ClientProvider, read FileReader, uri string) ([]byte, error) {
+	content, err := read(uri)
+	if err != nil || content == nil {
+		return nil, err
+	}
+
+	pc, err := pcp.ForNamespace(payload.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol client for namespace [%s]: %w", payload.Namespace, err)
+	}
+
+	v, err := pc.Get(payload.Version)
+	if err != nil {
+		return nil, fmt.Errorf("get protocol version [%d]: %w", payload.Version, err)
+	}
+
+	alg := v.Protocol().CompressionAlgorithm
+
+	content, err = compression.New(compression.WithDefaultAlgorithms()).Decompress(alg, content)
+	if err != nil {
+		return nil, fmt.Errorf("decompress file [%s] using '%s': %w", uri, alg, err)
+	}
+
+	return content, nil
+}
+
+type uriSet struct {
+	values []string
+	exists map[string]struct{}
+}
+
+func newURISet(uris ...string) *uriSet {
+	s := &uriSet{exists: make(map[string]struct{})}
+
+	s.add(uris...)
+
+	return s
+}
+
+func (s *uriSet) add(uris ...string) {
+	for _, uri := range uris {
+		if uri == "" {
+			continue
+		}
+
+		if _, ok := s.exists[uri]; ok {
+			continue
+		}
+
+		s.exists[uri] = struct{}{}
+
+		s.values = append(s.values, uri)
+	}
+}
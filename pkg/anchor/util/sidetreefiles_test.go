@@ -0,0 +1,100 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package util
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+)
+
+func TestGetSidetreeFileURIs(t *testing.T) {
+	pcp := mocks.NewMockProtocolClientProvider()
+
+	files := map[string][]byte{
+		"coreIndex": compress(t, &models.CoreIndexFile{
+			CoreProofFileURI:        "coreProof",
+			ProvisionalIndexFileURI: "provisionalIndex",
+		}),
+		"provisionalIndex": compress(t, &models.ProvisionalIndexFile{
+			ProvisionalProofFileURI: "provisionalProof",
+			Chunks:                  []models.Chunk{{ChunkFileURI: "chunk"}},
+		}),
+	}
+
+	read := func(uri string) ([]byte, error) {
+		return files[uri], nil
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		uris, err := GetSidetreeFileURIs(&subject.Payload{
+			CoreIndex:   "coreIndex",
+			Attachments: []string{"chunk", "coreIndex"},
+			Namespace:   mocks.DefaultNS,
+			Version:     1,
+		}, pcp, read)
+		require.NoError(t, err)
+		require.Equal(t, []string{"coreIndex", "chunk", "coreProof", "provisionalIndex", "provisionalProof"}, uris)
+	})
+
+	t.Run("Core index not available", func(t *testing.T) {
+		uris, err := GetSidetreeFileURIs(&subject.Payload{
+			CoreIndex: "coreIndex2",
+			Namespace: mocks.DefaultNS,
+			Version:   1,
+		}, pcp, read)
+		require.NoError(t, err)
+		require.Equal(t, []string{"coreIndex2"}, uris)
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		errExpected := errors.New("injected read error")
+
+		_, err := GetSidetreeFileURIs(&subject.Payload{CoreIndex: "coreIndex"}, pcp,
+			func(uri string) ([]byte, error) { return nil, errExpected },
+		)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Decompress error", func(t *testing.T) {
+		_, err := GetSidetreeFileURIs(&subject.Payload{CoreIndex: "coreIndex", Namespace: mocks.DefaultNS}, pcp,
+			func(uri string) ([]byte, error) { return []byte("not compressed"), nil },
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decompress file [coreIndex]")
+	})
+
+	t.Run("Parse error", func(t *testing.T) {
+		content, err := compression.New(compression.WithDefaultAlgorithms()).Compress("GZIP", []byte("{"))
+		require.NoError(t, err)
+
+		_, err = GetSidetreeFileURIs(&subject.Payload{CoreIndex: "coreIndex", Namespace: mocks.DefaultNS}, pcp,
+			func(uri string) ([]byte, error) { return content, nil },
+		)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse core index file [coreIndex]")
+	})
+}
+
+func compress(t *testing.T, file interface{}) []byte {
+	t.Helper()
+
+	fileBytes, err := json.Marshal(file)
+	require.NoError(t, err)
+
+	content, err := compression.New(compression.WithDefaultAlgorithms()).Compress("GZIP", fileBytes)
+	require.NoError(t, err)
+
+	return content
+}
@@ -108,7 +108,7 @@ func TestWriter_WriteAnchor(t *testing.T) {
 	ps := mempubsub.New(mempubsub.Config{})
 	defer ps.Stop()
 
-	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 100)
+	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 100, nil)
 
 	require.NoError(t, err)
 
@@ -226,7 +226,7 @@ func TestWriter_WriteAnchor(t *testing.T) {
 		c, err := New(namespace, apServiceIRI, casIRI, providers, &anchormocks.AnchorPublisher{}, ps,
 			testMaxWitnessDelay, false, testutil.GetLoader(t),
 			resourceresolver.New(http.DefaultClient,
-				ipfs.New(testServer.URL, 5*time.Second, 0, &mocks.MetricsProvider{}),
+				ipfs.New(testServer.URL, 5*time.Second, 0, &mocks.MetricsProvider{}, nil),
 			), &mocks.MetricsProvider{})
 		require.NoError(t, err)
 
@@ -784,7 +784,7 @@ func TestWriter_WriteAnchor(t *testing.T) {
 
 		c, err := New(namespace, apServiceIRI, casIRI, providers, &anchormocks.AnchorPublisher{}, ps,
 			testMaxWitnessDelay, false, testutil.GetLoader(t),
-			resourceresolver.New(nil, ipfs.New("SomeIPFSNodeURL", time.Second, 0, &mocks.MetricsProvider{})),
+			resourceresolver.New(nil, ipfs.New("SomeIPFSNodeURL", time.Second, 0, &mocks.MetricsProvider{}, nil)),
 			&mocks.MetricsProvider{})
 		require.NoError(t, err)
 
@@ -873,7 +873,7 @@ func TestWriter_handle(t *testing.T) {
 	ps := mempubsub.New(mempubsub.Config{})
 	defer ps.Stop()
 
-	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 0, nil)
 
 	require.NoError(t, err)
 
@@ -1471,7 +1471,7 @@ func TestWriter_Read(t *testing.T) {
 	ps := mempubsub.New(mempubsub.Config{})
 	defer ps.Stop()
 
-	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casURL, nil, &mocks.MetricsProvider{}, 0, nil)
 
 	require.NoError(t, err)
 
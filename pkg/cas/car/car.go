@@ -0,0 +1,236 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package car implements a writer and reader for CARv1 (IPLD Content Archive) files.
+//
+// A CAR file consists of a header, which contains the root CIDs, followed by a sequence of blocks, each of which
+// contains a CID and the data for that CID. The reader verifies that the data of each block matches its CID, so
+// a CAR file is self-verifying.
+//
+// See https://ipld.io/specs/transport/car/carv1/.
+package car
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	gocid "github.com/ipfs/go-cid"
+)
+
+const (
+	// ContentType is the media type of a CAR file.
+	ContentType = "application/vnd.ipld.car"
+
+	version = 1
+
+	// cidTag is the CBOR tag for a CID (https://github.com/ipld/cid-cbor).
+	cidTag = 42
+
+	// maxHeaderSize is the maximum size of the header.
+	maxHeaderSize = 32 * 1024
+
+	// MaxBlockSize is the maximum size of a block (CID plus data).
+	MaxBlockSize = 32 * 1024 * 1024
+)
+
+type header struct {
+	Roots   []cbor.Tag `cbor:"roots"`
+	Version uint64     `cbor:"version"`
+}
+
+// Writer writes a CAR file.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter writes the header with the given roots to the given writer and returns a CAR writer.
+func NewWriter(w io.Writer, roots ...gocid.Cid) (*Writer, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("at least one root is required")
+	}
+
+	h := &header{Version: version}
+
+	for _, root := range roots {
+		// The CID is encoded with a leading 0x00 (the multibase identity prefix).
+		h.Roots = append(h.Roots, cbor.Tag{Number: cidTag, Content: append([]byte{0}, root.Bytes()...)})
+	}
+
+	em, err := cbor.EncOptions{Sort: cbor.SortCanonical}.EncMode()
+	if err != nil {
+		return nil, fmt.Errorf("create CBOR encoder: %w", err)
+	}
+
+	headerBytes, err := em.Marshal(h)
+	if err != nil {
+		return nil, fmt.Errorf("marshal header: %w", err)
+	}
+
+	cw := &Writer{w: w}
+
+	if err := cw.writeSection(headerBytes); err != nil {
+		return nil, fmt.Errorf("write header: %w", err)
+	}
+
+	return cw, nil
+}
+
+// Put writes a block with the given CID and data. An error is returned if the data doesn't match the CID.
+func (cw *Writer) Put(cid gocid.Cid, data []byte) error {
+	if err := verify(cid, data); err != nil {
+		return err
+	}
+
+	if err := cw.writeSection(append(cid.Bytes(), data...)); err != nil {
+		return fmt.Errorf("write block [%s]: %w", cid, err)
+	}
+
+	return nil
+}
+
+func (cw *Writer) writeSection(data []byte) error {
+	lenBytes := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(lenBytes, uint64(len(data)))
+
+	if _, err := cw.w.Write(lenBytes[:n]); err != nil {
+		return err
+	}
+
+	_, err := cw.w.Write(data)
+
+	return err
+}
+
+// Reader reads a CAR file.
+type Reader struct {
+	r     *bufio.Reader
+	roots []gocid.Cid
+}
+
+// NewReader reads the header from the given reader and returns a CAR reader.
+func NewReader(r io.Reader) (*Reader, error) {
+	cr := &Reader{r: bufio.NewReader(r)}
+
+	headerBytes, err := cr.readSection(maxHeaderSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("read header: unexpected end of file")
+		}
+
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	h := &header{}
+
+	if err := cbor.Unmarshal(headerBytes, h); err != nil {
+		return nil, fmt.Errorf("unmarshal header: %w", err)
+	}
+
+	if h.Version != version {
+		return nil, fmt.Errorf("unsupported version: %d", h.Version)
+	}
+
+	for _, tag := range h.Roots {
+		root, err := parseRoot(tag)
+		if err != nil {
+			return nil, err
+		}
+
+		cr.roots = append(cr.roots, root)
+	}
+
+	if len(cr.roots) == 0 {
+		return nil, errors.New("no roots in header")
+	}
+
+	return cr, nil
+}
+
+// Roots returns the root CIDs from the header.
+func (cr *Reader) Roots() []gocid.Cid {
+	return cr.roots
+}
+
+// Next returns the CID and data of the next block. The data is verified against the CID. io.EOF is returned
+// if there are no more blocks.
+func (cr *Reader) Next() (gocid.Cid, []byte, error) {
+	section, err := cr.readSection(MaxBlockSize)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return gocid.Undef, nil, io.EOF
+		}
+
+		return gocid.Undef, nil, fmt.Errorf("read block: %w", err)
+	}
+
+	n, cid, err := gocid.CidFromBytes(section)
+	if err != nil {
+		return gocid.Undef, nil, fmt.Errorf("parse CID of block: %w", err)
+	}
+
+	data := section[n:]
+
+	if err := verify(cid, data); err != nil {
+		return gocid.Undef, nil, err
+	}
+
+	return cid, data, nil
+}
+
+func (cr *Reader) readSection(maxSize uint64) ([]byte, error) {
+	size, err := binary.ReadUvarint(cr.r)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 || size > maxSize {
+		return nil, fmt.Errorf("invalid section size: %d", size)
+	}
+
+	data := make([]byte, size)
+
+	if _, err := io.ReadFull(cr.r, data); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func parseRoot(tag cbor.Tag) (gocid.Cid, error) {
+	b, ok := tag.Content.([]byte)
+	if tag.Number != cidTag || !ok || len(b) == 0 || b[0] != 0 {
+		return gocid.Undef, errors.New("invalid root CID in header")
+	}
+
+	root, err := gocid.Cast(b[1:])
+	if err != nil {
+		return gocid.Undef, fmt.Errorf("invalid root CID in header: %w", err)
+	}
+
+	return root, nil
+}
+
+func verify(cid gocid.Cid, data []byte) error {
+	expected, err := cid.Prefix().Sum(data)
+	if err != nil {
+		return fmt.Errorf("hash data for CID [%s]: %w", cid, err)
+	}
+
+	if !expected.Equals(cid) {
+		return fmt.Errorf("data does not match CID [%s]", cid)
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package car
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	cbor "github.com/fxamacker/cbor/v2"
+	gocid "github.com/ipfs/go-cid"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteRead(t *testing.T) {
+	data1 := []byte("data1")
+	data2 := []byte("data2")
+
+	cid1 := newCID(t, data1)
+	cid2 := newCID(t, data2)
+
+	t.Run("Success", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		w, err := NewWriter(buf, cid1)
+		require.NoError(t, err)
+
+		require.NoError(t, w.Put(cid1, data1))
+		require.NoError(t, w.Put(cid2, data2))
+
+		r, err := NewReader(buf)
+		require.NoError(t, err)
+		require.Len(t, r.Roots(), 1)
+		require.True(t, cid1.Equals(r.Roots()[0]))
+
+		cid, data, err := r.Next()
+		require.NoError(t, err)
+		require.True(t, cid1.Equals(cid))
+		require.Equal(t, data1, data)
+
+		cid, data, err = r.Next()
+		require.NoError(t, err)
+		require.True(t, cid2.Equals(cid))
+		require.Equal(t, data2, data)
+
+		_, _, err = r.Next()
+		require.True(t, errors.Is(err, io.EOF))
+	})
+
+	t.Run("No roots", func(t *testing.T) {
+		_, err := NewWriter(&bytes.Buffer{})
+		require.EqualError(t, err, "at least one root is required")
+	})
+
+	t.Run("Put - data doesn't match CID", func(t *testing.T) {
+		w, err := NewWriter(&bytes.Buffer{}, cid1)
+		require.NoError(t, err)
+
+		err = w.Put(cid1, data2)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "data does not match CID")
+	})
+
+	t.Run("Read - tampered block", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		w, err := NewWriter(buf, cid1)
+		require.NoError(t, err)
+
+		require.NoError(t, w.Put(cid1, data1))
+
+		carBytes := buf.Bytes()
+		carBytes[len(carBytes)-1] = 'x'
+
+		r, err := NewReader(bytes.NewReader(carBytes))
+		require.NoError(t, err)
+
+		_, _, err = r.Next()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "data does not match CID")
+	})
+
+	t.Run("Read - truncated block", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+
+		w, err := NewWriter(buf, cid1)
+		require.NoError(t, err)
+
+		require.NoError(t, w.Put(cid1, data1))
+
+		carBytes := buf.Bytes()
+
+		r, err := NewReader(bytes.NewReader(carBytes[:len(carBytes)-2]))
+		require.NoError(t, err)
+
+		_, _, err = r.Next()
+		require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	})
+
+	t.Run("Read - empty file", func(t *testing.T) {
+		_, err := NewReader(&bytes.Buffer{})
+		require.EqualError(t, err, "read header: unexpected end of file")
+	})
+
+	t.Run("Read - invalid header", func(t *testing.T) {
+		_, err := NewReader(bytes.NewReader(section([]byte("invalid"))))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal header")
+	})
+
+	t.Run("Read - unsupported version", func(t *testing.T) {
+		headerBytes, err := cbor.Marshal(&header{Version: 2})
+		require.NoError(t, err)
+
+		_, err = NewReader(bytes.NewReader(section(headerBytes)))
+		require.EqualError(t, err, "unsupported version: 2")
+	})
+
+	t.Run("Read - no roots", func(t *testing.T) {
+		headerBytes, err := cbor.Marshal(&header{Version: 1})
+		require.NoError(t, err)
+
+		_, err = NewReader(bytes.NewReader(section(headerBytes)))
+		require.EqualError(t, err, "no roots in header")
+	})
+
+	t.Run("Read - invalid root", func(t *testing.T) {
+		headerBytes, err := cbor.Marshal(&header{
+			Version: 1,
+			Roots:   []cbor.Tag{{Number: cidTag, Content: []byte{1, 2, 3}}},
+		})
+		require.NoError(t, err)
+
+		_, err = NewReader(bytes.NewReader(section(headerBytes)))
+		require.EqualError(t, err, "invalid root CID in header")
+	})
+
+	t.Run("Read - section too large", func(t *testing.T) {
+		lenBytes := make([]byte, binary.MaxVarintLen64)
+		n := binary.PutUvarint(lenBytes, maxHeaderSize+1)
+
+		_, err := NewReader(bytes.NewReader(lenBytes[:n]))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid section size")
+	})
+}
+
+func newCID(t *testing.T, data []byte) gocid.Cid {
+	t.Helper()
+
+	cid, err := gocid.Prefix{
+		Version:  1,
+		Codec:    gocid.Raw,
+		MhType:   mh.SHA2_256,
+		MhLength: -1,
+	}.Sum(data)
+	require.NoError(t, err)
+
+	return cid
+}
+
+func section(data []byte) []byte {
+	lenBytes := make([]byte, binary.MaxVarintLen64)
+
+	n := binary.PutUvarint(lenBytes, uint64(len(data)))
+
+	return append(lenBytes[:n], data...)
+}
@@ -0,0 +1,409 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	"github.com/trustbloc/orb/pkg/anchor/util"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+	"github.com/trustbloc/orb/pkg/store/cas"
+)
+
+var logger = log.New("cas-gc")
+
+// ErrInProgress is returned from Collect if a collection is already in progress.
+var ErrInProgress = errors.New("garbage collection is already in progress")
+
+// suffixBatchSize is the number of suffixes whose latest anchors are retrieved in one call to the DID anchor store.
+const suffixBatchSize = 1000
+
+type didAnchorStore interface {
+	GetBulk(suffixes []string) ([]string, error)
+	GetAnchors() ([]string, error)
+}
+
+type suffixStore interface {
+	ForEachSuffix(fn func(suffix string) error) error
+}
+
+type anchorGraph interface {
+	Read(hl string) (*verifiable.Credential, error)
+}
+
+type casStore interface {
+	Read(address string) ([]byte, error)
+	ForEach(fn func(entry *cas.Entry) error) error
+	Delete(address string) error
+}
+
+type anchorForkStore interface {
+	GetForks(suffix string) ([]*anchorfork.Fork, error)
+}
+
+// Providers contains the providers required by the garbage collector.
+type Providers struct {
+	DIDAnchors             didAnchorStore
+	Suffixes               suffixStore
+	AnchorGraph            anchorGraph
+	CAS                    casStore
+	ProtocolClientProvider protocol.ClientProvider
+	AnchorForks            anchorForkStore // Optional
+}
+
+// Result contains the outcome of a garbage collection run.
+type Result struct {
+	// DryRun indicates that nothing was deleted.
+	DryRun bool `json:"dryRun"`
+	// Anchors is the number of anchors that were walked in the mark phase.
+	Anchors int `json:"anchors"`
+	// Live is the number of CAS entries that are reachable from an anchor.
+	Live int `json:"live"`
+	// Scanned is the number of entries in the CAS that were checked in the sweep phase.
+	Scanned int `json:"scanned"`
+	// Retained is the number of unreachable entries that were kept because they're within the grace period.
+	Retained int `json:"retained"`
+	// Collected is the number of entries that were (or, for a dry run, would have been) deleted.
+	Collected int `json:"collected"`
+	// ReclaimedBytes is the size of the content that was (or, for a dry run, would have been) deleted.
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
+	// CollectedKeys contains the keys of the entries that would have been deleted (only for a dry run).
+	CollectedKeys []string `json:"collectedKeys,omitempty"`
+}
+
+// Collector is a mark-and-sweep garbage collector for the local CAS.
+//
+// The mark phase walks the latest anchor of every DID (plus the branches of any detected forks) back through the
+// anchor graph and marks each anchor along with the Sidetree files that it references (core index, core proof,
+// provisional index, provisional proof and chunk files). The sweep phase deletes (and, if the content was
+// replicated, unpins from IPFS) all CAS entries that weren't marked and that are older than the grace period.
+// The grace period protects content that was written but not yet anchored, e.g. batch files for an anchor that's
+// still being witnessed.
+type Collector struct {
+	*Providers
+
+	gracePeriod time.Duration
+	now         func() time.Time
+
+	mutex      sync.Mutex
+	inProgress bool
+}
+
+// New returns a new CAS garbage collector.
+func New(providers *Providers, gracePeriod time.Duration) *Collector {
+	return &Collector{
+		Providers:   providers,
+		gracePeriod: gracePeriod,
+		now:         time.Now,
+	}
+}
+
+// Collect performs garbage collection on the local CAS. If dryRun is true then the unreachable entries are
+// reported but not deleted. ErrInProgress is returned if a collection is already in progress.
+func (c *Collector) Collect(dryRun bool) (*Result, error) {
+	if !c.start() {
+		return nil, ErrInProgress
+	}
+
+	defer c.done()
+
+	if c.Suffixes == nil {
+		// Without the full set of suffixes the latest anchors of DIDs whose references don't carry the
+		// anchor tag (i.e. references stored before the tag was introduced) can't be found.
+		return nil, errors.New("refusing to collect garbage since the suffix store isn't configured")
+	}
+
+	startTime := c.now()
+
+	result := &Result{DryRun: dryRun}
+
+	live, err := c.mark(result)
+	if err != nil {
+		return nil, fmt.Errorf("mark: %w", err)
+	}
+
+	result.Live = len(live)
+
+	err = c.sweep(live, startTime, result)
+
+	logger.Infof("CAS garbage collection completed in %s - dry run: %t, anchors: %d, live: %d, scanned: %d, "+
+		"retained: %d, collected: %d, reclaimed bytes: %d", c.now().Sub(startTime), dryRun, result.Anchors,
+		result.Live, result.Scanned, result.Retained, result.Collected, result.ReclaimedBytes)
+
+	if err != nil {
+		return result, fmt.Errorf("sweep: %w", err)
+	}
+
+	return result, nil
+}
+
+func (c *Collector) start() bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.inProgress {
+		return false
+	}
+
+	c.inProgress = true
+
+	return true
+}
+
+func (c *Collector) done() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.inProgress = false
+}
+
+// mark returns the CAS keys of all content that's reachable from the latest DID anchors.
+func (c *Collector) mark(result *Result) (map[string]struct{}, error) {
+	queue, err := c.getRootAnchors()
+	if err != nil {
+		return nil, err
+	}
+
+	live := make(map[string]struct{})
+
+	for len(queue) > 0 {
+		hl := queue[0]
+		queue = queue[1:]
+
+		key := toCASKey(hl)
+
+		if _, ok := live[key]; ok {
+			continue
+		}
+
+		live[key] = struct{}{}
+
+		vc, err := c.AnchorGraph.Read(hl)
+		if err != nil {
+			if errors.Is(err, orberrors.ErrContentNotFound) {
+				logger.Debugf("Anchor [%s] was not found in the local CAS", hl)
+
+				continue
+			}
+
+			return nil, fmt.Errorf("read anchor [%s]: %w", hl, err)
+		}
+
+		result.Anchors++
+
+		payload, err := util.GetAnchorSubject(vc)
+		if err != nil {
+			return nil, fmt.Errorf("get subject of anchor [%s]: %w", hl, err)
+		}
+
+		if err := c.markFiles(payload, live); err != nil {
+			return nil, fmt.Errorf("mark files of anchor [%s]: %w", hl, err)
+		}
+
+		for _, previous := range payload.PreviousAnchors {
+			if previous != "" {
+				queue = append(queue, previous)
+			}
+		}
+	}
+
+	return live, nil
+}
+
+// getRootAnchors returns the latest anchors of all DIDs along with the branches of detected forks. The DID anchor
+// references that carry the anchor tag are queried directly but, since references that were stored before the tag
+// was introduced aren't returned by the query, the latest anchor of every suffix in the operation store is also
+// looked up.
+func (c *Collector) getRootAnchors() ([]string, error) {
+	anchors, err := c.DIDAnchors.GetAnchors()
+	if err != nil {
+		return nil, fmt.Errorf("get DID anchors: %w", err)
+	}
+
+	anchors, err = c.appendSuffixAnchors(anchors)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.AnchorForks == nil {
+		return anchors, nil
+	}
+
+	forks, err := c.AnchorForks.GetForks("")
+	if err != nil {
+		return nil, fmt.Errorf("get anchor forks: %w", err)
+	}
+
+	// The branches of forks are kept so that the evidence remains available.
+	for _, fork := range forks {
+		anchors = append(anchors, fork.Branches...)
+	}
+
+	return anchors, nil
+}
+
+func (c *Collector) appendSuffixAnchors(anchors []string) ([]string, error) {
+	existing := make(map[string]struct{}, len(anchors))
+
+	for _, anchor := range anchors {
+		existing[anchor] = struct{}{}
+	}
+
+	var batch []string
+
+	appendBatch := func() error {
+		batchAnchors, err := c.DIDAnchors.GetBulk(batch)
+		if err != nil {
+			return fmt.Errorf("get DID anchors for suffixes: %w", err)
+		}
+
+		for _, anchor := range batchAnchors {
+			if anchor == "" {
+				continue
+			}
+
+			if _, ok := existing[anchor]; !ok {
+				existing[anchor] = struct{}{}
+
+				anchors = append(anchors, anchor)
+			}
+		}
+
+		batch = nil
+
+		return nil
+	}
+
+	err := c.Suffixes.ForEachSuffix(func(suffix string) error {
+		batch = append(batch, suffix)
+
+		if len(batch) < suffixBatchSize {
+			return nil
+		}
+
+		return appendBatch()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("iterate over suffixes: %w", err)
+	}
+
+	if len(batch) > 0 {
+		if err := appendBatch(); err != nil {
+			return nil, fmt.Errorf("iterate over suffixes: %w", err)
+		}
+	}
+
+	return anchors, nil
+}
+
+// markFiles marks the Sidetree files that are referenced by the given anchor.
+func (c *Collector) markFiles(payload *subject.Payload, live map[string]struct{}) error {
+	uris, err := util.GetSidetreeFileURIs(payload, c.ProtocolClientProvider, c.readFile)
+	if err != nil {
+		return err
+	}
+
+	for _, uri := range uris {
+		live[toCASKey(uri)] = struct{}{}
+	}
+
+	return nil
+}
+
+// readFile reads the given Sidetree file from the local CAS. Nil is returned if the file isn't in the local CAS.
+func (c *Collector) readFile(uri string) ([]byte, error) {
+	content, err := c.CAS.Read(toCASKey(uri))
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			logger.Debugf("File [%s] was not found in the local CAS", uri)
+
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read file [%s]: %w", uri, err)
+	}
+
+	return content, nil
+}
+
+func (c *Collector) sweep(live map[string]struct{}, startTime time.Time, result *Result) error {
+	var garbage []*cas.Entry
+
+	err := c.CAS.ForEach(func(entry *cas.Entry) error {
+		result.Scanned++
+
+		if _, ok := live[entry.Key]; ok {
+			return nil
+		}
+
+		if startTime.Sub(entry.Created) < c.gracePeriod {
+			result.Retained++
+
+			return nil
+		}
+
+		garbage = append(garbage, entry)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan CAS entries: %w", err)
+	}
+
+	var errMsgs []string
+
+	for _, entry := range garbage {
+		if !result.DryRun {
+			if err := c.CAS.Delete(entry.Key); err != nil {
+				logger.Warnf("Failed to delete CAS entry [%s]: %s", entry.Key, err)
+
+				errMsgs = append(errMsgs, err.Error())
+
+				continue
+			}
+
+			logger.Debugf("Deleted CAS entry [%s] of size %d", entry.Key, entry.Size)
+		} else {
+			result.CollectedKeys = append(result.CollectedKeys, entry.Key)
+		}
+
+		result.Collected++
+		result.ReclaimedBytes += int64(entry.Size)
+	}
+
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("failed to delete %d of %d entries: %s", len(errMsgs), len(garbage),
+			strings.Join(errMsgs, "; "))
+	}
+
+	return nil
+}
+
+// toCASKey returns the key of the given URI in the local CAS. The URI may either be a hashlink or a resource hash.
+func toCASKey(uri string) string {
+	if !strings.HasPrefix(uri, hashlink.HLPrefix) {
+		return uri
+	}
+
+	key, err := hashlink.GetResourceHashFromHashLink(uri)
+	if err != nil {
+		return uri
+	}
+
+	return key
+}
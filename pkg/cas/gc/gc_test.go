@@ -0,0 +1,513 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package gc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/compression"
+	"github.com/trustbloc/sidetree-core-go/pkg/versions/1_0/txnprovider/models"
+
+	"github.com/trustbloc/orb/pkg/anchor/activity"
+	"github.com/trustbloc/orb/pkg/anchor/subject"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/internal/testutil"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/store/didanchor"
+	opstore "github.com/trustbloc/orb/pkg/store/operation"
+)
+
+const (
+	casLink   = "https://domain.com/cas"
+	namespace = "did:orb"
+	suffix1   = "suffix1"
+	suffix2   = "suffix2"
+	suffix3   = "suffix3"
+	gzipAlg   = "GZIP"
+)
+
+func TestCollector_Collect(t *testing.T) {
+	t.Run("Dry run", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		result, err := env.collector.Collect(true)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.True(t, result.DryRun)
+		require.Equal(t, 4, result.Anchors)
+		require.Equal(t, len(env.live), result.Live)
+		require.Equal(t, len(env.live)+len(env.garbage), result.Scanned)
+		require.Equal(t, len(env.garbage), result.Collected)
+		require.Equal(t, env.garbageSize(), result.ReclaimedBytes)
+		require.ElementsMatch(t, env.garbage, result.CollectedKeys)
+
+		for _, key := range env.garbage {
+			_, err := env.cas.Read(key)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Success", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		result, err := env.collector.Collect(false)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		require.False(t, result.DryRun)
+		require.Equal(t, len(env.garbage), result.Collected)
+		require.Equal(t, env.garbageSize(), result.ReclaimedBytes)
+		require.Empty(t, result.CollectedKeys)
+
+		for _, key := range env.garbage {
+			_, err := env.cas.Read(key)
+			require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+		}
+
+		for _, key := range env.live {
+			_, err := env.cas.Read(key)
+			require.NoError(t, err)
+		}
+
+		// Nothing left to collect.
+		result, err = env.collector.Collect(false)
+		require.NoError(t, err)
+		require.Zero(t, result.Collected)
+		require.Equal(t, len(env.live), result.Scanned)
+	})
+
+	t.Run("Within grace period", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.collector.now = time.Now
+
+		result, err := env.collector.Collect(false)
+		require.NoError(t, err)
+		require.Zero(t, result.Collected)
+		require.Equal(t, len(env.garbage), result.Retained)
+
+		for _, key := range env.garbage {
+			_, err := env.cas.Read(key)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Already in progress", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.collector.inProgress = true
+
+		result, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, ErrInProgress))
+		require.Nil(t, result)
+	})
+
+	t.Run("DID anchor store error", func(t *testing.T) {
+		errExpected := errors.New("injected DID anchor store error")
+
+		env := newTestEnv(t)
+		env.collector.DIDAnchors = &mockDIDAnchors{didAnchorStore: env.didAnchors, getAnchorsErr: errExpected}
+
+		_, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("DID anchor store GetBulk error", func(t *testing.T) {
+		errExpected := errors.New("injected DID anchor store error")
+
+		env := newTestEnv(t)
+		env.collector.DIDAnchors = &mockDIDAnchors{didAnchorStore: env.didAnchors, getBulkErr: errExpected}
+
+		_, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Suffix store error", func(t *testing.T) {
+		errExpected := errors.New("injected suffix store error")
+
+		env := newTestEnv(t)
+		env.collector.Suffixes = &mockSuffixes{err: errExpected}
+
+		_, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Suffix store not configured", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.collector.Suffixes = nil
+
+		result, err := env.collector.Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "suffix store isn't configured")
+		require.Nil(t, result)
+
+		for _, key := range env.garbage {
+			_, err := env.cas.Read(key)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Anchor fork store error", func(t *testing.T) {
+		errExpected := errors.New("injected anchor fork store error")
+
+		env := newTestEnv(t)
+		env.collector.AnchorForks = &mockAnchorForks{err: errExpected}
+
+		_, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Anchor graph error", func(t *testing.T) {
+		errExpected := errors.New("injected anchor graph error")
+
+		env := newTestEnv(t)
+
+		anchorGraph := &orbmocks.AnchorGraph{}
+		anchorGraph.ReadReturns(nil, errExpected)
+
+		env.collector.AnchorGraph = anchorGraph
+
+		_, err := env.collector.Collect(true)
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Invalid core index file", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		hl, err := env.cas.Write([]byte("not compressed"))
+		require.NoError(t, err)
+
+		anchor := env.addAnchor(&subject.Payload{
+			CoreIndex:       hl,
+			Namespace:       namespace,
+			Version:         1,
+			PreviousAnchors: map[string]string{suffix2: ""},
+		})
+
+		require.NoError(t, env.didAnchors.PutBulk([]string{suffix2}, anchor))
+
+		_, err = env.collector.Collect(true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "decompress file")
+	})
+
+	t.Run("CAS scan error", func(t *testing.T) {
+		errExpected := errors.New("injected scan error")
+
+		env := newTestEnv(t)
+		env.collector.CAS = &mockCAS{casStore: env.cas, forEachErr: errExpected}
+
+		result, err := env.collector.Collect(false)
+		require.True(t, errors.Is(err, errExpected))
+		require.NotNil(t, result)
+		require.Zero(t, result.Collected)
+	})
+
+	t.Run("CAS delete error", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.collector.CAS = &mockCAS{casStore: env.cas, deleteErr: errors.New("injected delete error")}
+
+		result, err := env.collector.Collect(false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected delete error")
+		require.NotNil(t, result)
+		require.Zero(t, result.Collected)
+	})
+}
+
+type testEnv struct {
+	t          *testing.T
+	collector  *Collector
+	cas        *cas.CAS
+	didAnchors *didanchor.Store
+	anchors    map[string]*verifiable.Credential
+	live       []string
+	garbage    []string
+	sizes      map[string]int
+}
+
+// newTestEnv creates a CAS with two anchors in the chain of suffix1 (the first one has no attachments, so its
+// files are found by parsing the index files), an anchor that's a branch of a fork, an anchor of suffix3 whose
+// DID anchor reference was stored without the anchor tag, and some unreachable content.
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	provider := mem.NewProvider()
+
+	ops, err := opstore.New(provider)
+	require.NoError(t, err)
+
+	casStore, err := cas.New(provider, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	didAnchors, err := didanchor.New(provider)
+	require.NoError(t, err)
+
+	forks, err := anchorfork.New(provider)
+	require.NoError(t, err)
+
+	env := &testEnv{
+		t:          t,
+		cas:        casStore,
+		didAnchors: didAnchors,
+		anchors:    make(map[string]*verifiable.Credential),
+		sizes:      make(map[string]int),
+	}
+
+	chunk := env.writeFile(true, []byte(`{"deltas":[]}`))
+	provisionalProof := env.writeFile(true, []byte(`{"operations":{}}`))
+	provisionalIndex := env.writeJSONFile(true, &models.ProvisionalIndexFile{
+		ProvisionalProofFileURI: provisionalProof,
+		Chunks:                  []models.Chunk{{ChunkFileURI: chunk}},
+	})
+	coreProof := env.writeFile(true, []byte(`{"operations":{"recover":[]}}`))
+	coreIndex1 := env.writeJSONFile(true, &models.CoreIndexFile{
+		ProvisionalIndexFileURI: provisionalIndex,
+		CoreProofFileURI:        coreProof,
+	})
+
+	anchor1 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex1,
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix1: ""},
+	})
+
+	coreIndex2 := env.writeFile(true, []byte(`{"id":"2"}`))
+
+	anchor2 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex2,
+		Attachments:     []string{coreIndex2},
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix1: anchor1},
+	})
+
+	require.NoError(t, didAnchors.PutBulk([]string{suffix1}, anchor2))
+
+	coreIndex3 := env.writeFile(true, []byte(`{"id":"3"}`))
+
+	anchor3 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex3,
+		Attachments:     []string{coreIndex3},
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix1: anchor1},
+	})
+
+	require.NoError(t, forks.PutFork(&anchorfork.Fork{
+		Suffix:         suffix1,
+		PreviousAnchor: anchor1,
+		Branches:       []string{anchor2, anchor3},
+		Status:         anchorfork.StatusResolved,
+		Winner:         anchor2,
+	}))
+
+	// A reference that was stored before the anchor tag was introduced.
+	coreIndex5 := env.writeFile(true, []byte(`{"id":"5"}`))
+
+	anchor5 := env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex5,
+		Attachments:     []string{coreIndex5},
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix3: ""},
+	})
+
+	didAnchorDB, err := provider.OpenStore("didanchor")
+	require.NoError(t, err)
+
+	require.NoError(t, didAnchorDB.Put(suffix3, []byte(anchor5)))
+
+	require.NoError(t, ops.Put([]*operation.AnchoredOperation{
+		{Type: operation.TypeCreate, UniqueSuffix: suffix1},
+		{Type: operation.TypeUpdate, UniqueSuffix: suffix1},
+		{Type: operation.TypeCreate, UniqueSuffix: suffix3},
+	}))
+
+	env.live = append(env.live, toCASKey(anchor1), toCASKey(anchor2), toCASKey(anchor3), toCASKey(anchor5))
+
+	// An anchor that isn't referenced by any DID along with its files.
+	coreIndex4 := env.writeFile(false, []byte(`{"id":"4"}`))
+
+	env.garbage = append(env.garbage, toCASKey(env.addAnchor(&subject.Payload{
+		CoreIndex:       coreIndex4,
+		Attachments:     []string{coreIndex4},
+		Namespace:       namespace,
+		Version:         1,
+		PreviousAnchors: map[string]string{suffix2: ""},
+	})))
+
+	env.writeFile(false, []byte("orphaned content"))
+
+	anchorGraph := &orbmocks.AnchorGraph{}
+	anchorGraph.ReadStub = func(hl string) (*verifiable.Credential, error) {
+		vc, ok := env.anchors[toCASKey(hl)]
+		if !ok {
+			return nil, orberrors.ErrContentNotFound
+		}
+
+		return vc, nil
+	}
+
+	env.collector = New(&Providers{
+		DIDAnchors:             didAnchors,
+		Suffixes:               ops,
+		AnchorGraph:            anchorGraph,
+		CAS:                    casStore,
+		ProtocolClientProvider: orbmocks.NewMockProtocolClientProvider(),
+		AnchorForks:            forks,
+	}, time.Hour)
+
+	env.collector.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+
+	return env
+}
+
+func (env *testEnv) writeJSONFile(live bool, file interface{}) string {
+	content, err := json.Marshal(file)
+	require.NoError(env.t, err)
+
+	return env.writeFile(live, content)
+}
+
+func (env *testEnv) writeFile(live bool, content []byte) string {
+	compressed, err := compression.New(compression.WithDefaultAlgorithms()).Compress(gzipAlg, content)
+	require.NoError(env.t, err)
+
+	return env.write(live, compressed)
+}
+
+func (env *testEnv) write(live bool, content []byte) string {
+	hl, err := env.cas.Write(content)
+	require.NoError(env.t, err)
+
+	key, err := hashlink.GetResourceHashFromHashLink(hl)
+	require.NoError(env.t, err)
+
+	env.sizes[key] = len(content)
+
+	if live {
+		env.live = append(env.live, key)
+	} else {
+		env.garbage = append(env.garbage, key)
+	}
+
+	return hl
+}
+
+// addAnchor adds an anchor to the anchor graph. The anchor is also written to the CAS (it isn't counted as either
+// live or garbage - this is up to the caller).
+func (env *testEnv) addAnchor(payload *subject.Payload) string {
+	act, err := activity.BuildActivityFromPayload(payload)
+	require.NoError(env.t, err)
+
+	vc := &verifiable.Credential{
+		Types:   []string{"VerifiableCredential"},
+		Context: []string{"https://www.w3.org/2018/credentials/v1"},
+		Subject: act,
+		Issuer:  verifiable.Issuer{ID: "http://peer1.com"},
+		Issued:  &util.TimeWithTrailingZeroMsec{Time: time.Now()},
+	}
+
+	vcBytes, err := vc.MarshalJSON()
+	require.NoError(env.t, err)
+
+	parsedVC, err := verifiable.ParseCredential(vcBytes,
+		verifiable.WithJSONLDDocumentLoader(testutil.GetLoader(env.t)))
+	require.NoError(env.t, err)
+
+	hl, err := env.cas.Write(vcBytes)
+	require.NoError(env.t, err)
+
+	key := toCASKey(hl)
+
+	env.anchors[key] = parsedVC
+	env.sizes[key] = len(vcBytes)
+
+	return hl
+}
+
+func (env *testEnv) garbageSize() int64 {
+	var size int64
+
+	for _, key := range env.garbage {
+		size += int64(env.sizes[key])
+	}
+
+	return size
+}
+
+type mockDIDAnchors struct {
+	didAnchorStore
+
+	getAnchorsErr error
+	getBulkErr    error
+}
+
+func (m *mockDIDAnchors) GetAnchors() ([]string, error) {
+	if m.getAnchorsErr != nil {
+		return nil, m.getAnchorsErr
+	}
+
+	return m.didAnchorStore.GetAnchors()
+}
+
+func (m *mockDIDAnchors) GetBulk(suffixes []string) ([]string, error) {
+	if m.getBulkErr != nil {
+		return nil, m.getBulkErr
+	}
+
+	return m.didAnchorStore.GetBulk(suffixes)
+}
+
+type mockSuffixes struct {
+	err error
+}
+
+func (m *mockSuffixes) ForEachSuffix(func(suffix string) error) error {
+	return m.err
+}
+
+type mockAnchorForks struct {
+	err error
+}
+
+func (m *mockAnchorForks) GetForks(string) ([]*anchorfork.Fork, error) {
+	return nil, m.err
+}
+
+type mockCAS struct {
+	casStore
+
+	forEachErr error
+	deleteErr  error
+}
+
+func (m *mockCAS) ForEach(fn func(entry *cas.Entry) error) error {
+	if m.forEachErr != nil {
+		return m.forEachErr
+	}
+
+	return m.casStore.ForEach(fn)
+}
+
+func (m *mockCAS) Delete(address string) error {
+	if m.deleteErr != nil {
+		return m.deleteErr
+	}
+
+	return m.casStore.Delete(address)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/cas/gc"
+)
+
+const (
+	endpoint    = "/cas/gc"
+	dryRunParam = "dry-run"
+)
+
+const (
+	badRequestResponse          = "Bad Request."
+	conflictResponse            = "Conflict. Garbage collection is already in progress."
+	internalServerErrorResponse = "Internal Server Error."
+)
+
+var logger = log.New("cas-gc-rest-handler")
+
+type collector interface {
+	Collect(dryRun bool) (*gc.Result, error)
+}
+
+// GC triggers garbage collection of the local CAS. If the 'dry-run' query parameter is true then the
+// unreachable content is reported but not deleted.
+type GC struct {
+	collector collector
+	marshal   func(interface{}) ([]byte, error)
+}
+
+// New returns a new GC handler.
+func New(c collector) *GC {
+	return &GC{
+		collector: c,
+		marshal:   json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the GC service.
+func (h *GC) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the GC service.
+func (h *GC) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handle for the GC service.
+func (h *GC) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *GC) handle(w http.ResponseWriter, req *http.Request) {
+	dryRun := false
+
+	if value := req.URL.Query().Get(dryRunParam); value != "" {
+		var err error
+
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			logger.Debugf("[%s] Invalid value for parameter [%s]: %s", endpoint, dryRunParam, err)
+
+			writeResponse(w, http.StatusBadRequest, []byte(badRequestResponse))
+
+			return
+		}
+	}
+
+	result, err := h.collector.Collect(dryRun)
+	if err != nil {
+		if errors.Is(err, gc.ErrInProgress) {
+			writeResponse(w, http.StatusConflict, []byte(conflictResponse))
+
+			return
+		}
+
+		logger.Errorf("[%s] Error collecting garbage: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	resultBytes, err := h.marshal(result)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling result: %s", endpoint, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, resultBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/cas/gc"
+)
+
+func TestNew(t *testing.T) {
+	h := New(&mockCollector{})
+	require.NotNil(t, h)
+	require.Equal(t, endpoint, h.Path())
+	require.Equal(t, http.MethodPost, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		c := &mockCollector{result: &gc.Result{Collected: 2, ReclaimedBytes: 1024}}
+
+		h := New(c)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.False(t, c.dryRun)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		gcResult := &gc.Result{}
+		require.NoError(t, json.Unmarshal(respBytes, gcResult))
+		require.Equal(t, 2, gcResult.Collected)
+		require.Equal(t, int64(1024), gcResult.ReclaimedBytes)
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		c := &mockCollector{result: &gc.Result{DryRun: true}}
+
+		h := New(c)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint+"?dry-run=true", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.True(t, c.dryRun)
+	})
+
+	t.Run("Invalid dry-run parameter", func(t *testing.T) {
+		c := &mockCollector{}
+
+		h := New(c)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint+"?dry-run=xxx", nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusBadRequest, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.False(t, c.invoked)
+	})
+
+	t.Run("Already in progress", func(t *testing.T) {
+		h := New(&mockCollector{err: fmt.Errorf("collect: %w", gc.ErrInProgress)})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusConflict, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Collector error", func(t *testing.T) {
+		h := New(&mockCollector{err: errors.New("injected error")})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := New(&mockCollector{result: &gc.Result{}})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodPost, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockCollector struct {
+	result  *gc.Result
+	err     error
+	dryRun  bool
+	invoked bool
+}
+
+func (m *mockCollector) Collect(dryRun bool) (*gc.Result, error) {
+	m.invoked = true
+	m.dryRun = dryRun
+
+	return m.result, m.err
+}
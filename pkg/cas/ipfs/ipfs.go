@@ -40,6 +40,7 @@ type metricsProvider interface {
 type ipfsClient interface {
 	Cat(path string) (io.ReadCloser, error)
 	Add(r io.Reader, options ...shell.AddOpts) (string, error)
+	Unpin(path string) error
 }
 
 // Client will write new documents to IPFS and read existing documents from IPFS based on CID.
@@ -53,13 +54,20 @@ type Client struct {
 }
 
 // New creates cas client.
+// hl is optional and is used to create and parse hashlinks. If nil then a default hashlink instance is used.
 // If no CID version is specified, then v1 will be used by default.
-func New(url string, timeout time.Duration, cacheSize int, metrics metricsProvider,
+func New(url string, timeout time.Duration, cacheSize int, metrics metricsProvider, hl *hashlink.HashLink,
 	opts ...extendedcasclient.CIDFormatOption) *Client {
 	ipfs := shell.NewShell(url)
 	ipfs.SetTimeout(timeout)
 
-	return newClient(ipfs, cacheSize, metrics, opts...)
+	c := newClient(ipfs, cacheSize, metrics, opts...)
+
+	if hl != nil {
+		c.hl = hl
+	}
+
+	return c
 }
 
 func newClient(ipfs ipfsClient, cacheSize int, metrics metricsProvider,
@@ -135,6 +143,26 @@ func (m *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CI
 	return cid, nil
 }
 
+// Unpin unpins the content with the given CID so that it may be garbage collected by the IPFS node.
+func (m *Client) Unpin(cid string) error {
+	err := m.ipfs.Unpin(cid)
+	if err != nil {
+		if strings.Contains(err.Error(), "not pinned") {
+			logger.Debugf("CID [%s] is not pinned", cid)
+
+			return nil
+		}
+
+		return orberrors.NewTransient(fmt.Errorf("unpin [%s]: %w", cid, err))
+	}
+
+	m.cache.Remove(cid)
+
+	logger.Debugf("Unpinned CID [%s]", cid)
+
+	return nil
+}
+
 // GetPrimaryWriterType returns primary writer type.
 func (m *Client) GetPrimaryWriterType() string {
 	return "ipfs"
@@ -30,7 +30,7 @@ import (
 //go:generate counterfeiter -o ./mocks/ipfsclient.gen.go --fake-name IPFSClient . ipfsClient
 
 func TestNew(t *testing.T) {
-	c := New("ipfs:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+	c := New("ipfs:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 	require.NotNil(t, c)
 }
 
@@ -43,7 +43,7 @@ func TestWrite(t *testing.T) {
 		}()
 
 		t.Run("v1 CIDs", func(t *testing.T) {
-			cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+			cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 			require.NotNil(t, cas)
 
 			var cid string
@@ -65,7 +65,7 @@ func TestWrite(t *testing.T) {
 		})
 		t.Run("v0 CIDs", func(t *testing.T) {
 			cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{},
-				extendedcasclient.WithCIDVersion(0))
+				nil, extendedcasclient.WithCIDVersion(0))
 			require.NotNil(t, cas)
 
 			var cid string
@@ -88,7 +88,7 @@ func TestWrite(t *testing.T) {
 
 		t.Run("success - hashlink", func(t *testing.T) {
 			cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{},
-				extendedcasclient.WithCIDVersion(1))
+				nil, extendedcasclient.WithCIDVersion(1))
 			require.NotNil(t, cas)
 
 			var cid string
@@ -111,7 +111,7 @@ func TestWrite(t *testing.T) {
 
 	t.Run("error - invalid hashlink", func(t *testing.T) {
 		cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{},
-			extendedcasclient.WithCIDVersion(1))
+			nil, extendedcasclient.WithCIDVersion(1))
 		require.NotNil(t, cas)
 
 		read, err := cas.Read("hl:abc")
@@ -122,7 +122,7 @@ func TestWrite(t *testing.T) {
 
 	t.Run("error - hashlink (content not found)", func(t *testing.T) {
 		cas := New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{},
-			extendedcasclient.WithCIDVersion(1))
+			nil, extendedcasclient.WithCIDVersion(1))
 		require.NotNil(t, cas)
 
 		read, err := cas.Read("hl:uEiBGzo1CWjNplt9iSVJdU9B9vfCm7u1d5CvqYsNbuMVT7Q:uoQ-BeEJpcGZzOi8vYmFma3JlaWNnejJndWV3cnRuZ2xuNnlzamtqb3ZodWQ1eHh5a24zeG5seHNjeDJ0Y3lubjNycmt0NXU") //nolint:lll
@@ -137,7 +137,7 @@ func TestWrite(t *testing.T) {
 		}))
 		defer ipfs.Close()
 
-		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		cid, err := cas.Write([]byte("content"))
@@ -147,7 +147,7 @@ func TestWrite(t *testing.T) {
 
 	t.Run("invalid CID version", func(t *testing.T) {
 		cas := New("IPFS URL", 5*time.Second, 0, &orbmocks.MetricsProvider{},
-			extendedcasclient.WithCIDVersion(2))
+			nil, extendedcasclient.WithCIDVersion(2))
 		require.NotNil(t, cas)
 
 		cid, err := cas.Write([]byte("content"))
@@ -156,7 +156,7 @@ func TestWrite(t *testing.T) {
 	})
 
 	t.Run("empty content", func(t *testing.T) {
-		cas := New("IPFS URL", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New("IPFS URL", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		cid, err := cas.Write(nil)
@@ -193,7 +193,7 @@ func TestWrite(t *testing.T) {
 	})
 
 	t.Run("fail to write since node (ipfs.io) doesn't support writes", func(t *testing.T) {
-		cas := New("https://ipfs.io", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New("https://ipfs.io", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		cid, err := cas.Write([]byte("content"))
@@ -202,6 +202,39 @@ func TestWrite(t *testing.T) {
 	})
 }
 
+func TestUnpin(t *testing.T) {
+	const cid = "bafkreihnoabliopjvscf6irvpwbcxlauirzq7pnwafwt5skdekl3t3e7om"
+
+	t.Run("success", func(t *testing.T) {
+		ipfs := &mocks.IPFSClient{}
+
+		cas := newClient(ipfs, 0, &orbmocks.MetricsProvider{})
+		require.NoError(t, cas.Unpin(cid))
+		require.Equal(t, 1, ipfs.UnpinCallCount())
+		require.Equal(t, cid, ipfs.UnpinArgsForCall(0))
+	})
+
+	t.Run("not pinned", func(t *testing.T) {
+		ipfs := &mocks.IPFSClient{}
+		ipfs.UnpinReturns(errors.New("pin/rm: not pinned or pinned indirectly"))
+
+		cas := newClient(ipfs, 0, &orbmocks.MetricsProvider{})
+		require.NoError(t, cas.Unpin(cid))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		ipfs := &mocks.IPFSClient{}
+		ipfs.UnpinReturns(errors.New("injected unpin error"))
+
+		cas := newClient(ipfs, 0, &orbmocks.MetricsProvider{})
+
+		err := cas.Unpin(cid)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected unpin error")
+		require.True(t, orberrors.IsTransient(err))
+	})
+}
+
 func TestRead(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		ipfs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -209,7 +242,7 @@ func TestRead(t *testing.T) {
 		}))
 		defer ipfs.Close()
 
-		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		read, err := cas.Read("uEiAWradITyYpRGT3pMhcKfPL8kpJBGePjFjZOlS0zqAUqw")
@@ -223,7 +256,7 @@ func TestRead(t *testing.T) {
 		}))
 		defer ipfs.Close()
 
-		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		cid, err := cas.Read("cid")
@@ -239,7 +272,7 @@ func TestRead(t *testing.T) {
 		}))
 		defer ipfs.Close()
 
-		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		cas := New(ipfs.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, cas)
 
 		cid, err := cas.Read("uEiAWradITyYpRGT3pMhcKfPL8kpJBGePjFjZOlS0zqAUqw")
@@ -36,6 +36,17 @@ type IPFSClient struct {
 		result1 string
 		result2 error
 	}
+	UnpinStub        func(path string) error
+	unpinMutex       sync.RWMutex
+	unpinArgsForCall []struct {
+		path string
+	}
+	unpinReturns struct {
+		result1 error
+	}
+	unpinReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -143,6 +154,54 @@ func (fake *IPFSClient) AddReturnsOnCall(i int, result1 string, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *IPFSClient) Unpin(path string) error {
+	fake.unpinMutex.Lock()
+	ret, specificReturn := fake.unpinReturnsOnCall[len(fake.unpinArgsForCall)]
+	fake.unpinArgsForCall = append(fake.unpinArgsForCall, struct {
+		path string
+	}{path})
+	fake.recordInvocation("Unpin", []interface{}{path})
+	fake.unpinMutex.Unlock()
+	if fake.UnpinStub != nil {
+		return fake.UnpinStub(path)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fake.unpinReturns.result1
+}
+
+func (fake *IPFSClient) UnpinCallCount() int {
+	fake.unpinMutex.RLock()
+	defer fake.unpinMutex.RUnlock()
+	return len(fake.unpinArgsForCall)
+}
+
+func (fake *IPFSClient) UnpinArgsForCall(i int) string {
+	fake.unpinMutex.RLock()
+	defer fake.unpinMutex.RUnlock()
+	return fake.unpinArgsForCall[i].path
+}
+
+func (fake *IPFSClient) UnpinReturns(result1 error) {
+	fake.UnpinStub = nil
+	fake.unpinReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *IPFSClient) UnpinReturnsOnCall(i int, result1 error) {
+	fake.UnpinStub = nil
+	if fake.unpinReturnsOnCall == nil {
+		fake.unpinReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.unpinReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *IPFSClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -150,6 +209,8 @@ func (fake *IPFSClient) Invocations() map[string][][]interface{} {
 	defer fake.catMutex.RUnlock()
 	fake.addMutex.RLock()
 	defer fake.addMutex.RUnlock()
+	fake.unpinMutex.RLock()
+	defer fake.unpinMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,290 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/webcas"
+)
+
+const (
+	contentTypeHeader     = "Content-Type"
+	jsonContentType       = "application/json"
+	multipartContentType  = "multipart/mixed"
+	multipartBoundaryName = "boundary"
+
+	// Limits the total size of the content in a batch response.
+	maxBatchResponseSize = 64 * 1024 * 1024
+)
+
+type batchEntry struct {
+	hl     string
+	cid    string
+	hlInfo *hashlink.Info
+}
+
+// ResolveBatch ensures that the data for the given hashlinks is in the local CAS. Data that isn't already in the
+// local CAS is retrieved from the batch endpoints of the WebCAS servers referenced in the hashlink metadata, so
+// that a single request is made per server (for up to webcas.MaxBatchSize items) instead of one request per item.
+// The data is verified against the resource hash(es) before it's stored locally.
+//
+// This is an optimization: the hashlinks that could not be retrieved in a batch are returned, and these may still
+// be resolved individually using Resolve.
+func (h *Resolver) ResolveBatch(hashLinks []string) ([]string, error) {
+	if !h.hasSource(SourceWebCAS) {
+		return hashLinks, nil
+	}
+
+	batches, unresolved := h.getBatches(hashLinks)
+
+	var errMsgs []string
+
+	for endpoint, entries := range batches {
+		for start := 0; start < len(entries); start += webcas.MaxBatchSize {
+			end := start + webcas.MaxBatchSize
+			if end > len(entries) {
+				end = len(entries)
+			}
+
+			notStored, err := h.resolveBatch(endpoint, entries[start:end])
+			if err != nil {
+				errMsgs = append(errMsgs, err.Error())
+			}
+
+			unresolved = append(unresolved, notStored...)
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return unresolved, fmt.Errorf("resolve batch: %s", strings.Join(errMsgs, "; "))
+	}
+
+	return unresolved, nil
+}
+
+// getBatches groups the hashlinks whose data isn't in the local CAS by WebCAS endpoint. The hashlinks for which no
+// WebCAS endpoint was found are also returned.
+func (h *Resolver) getBatches(hashLinks []string) (map[string][]*batchEntry, []string) {
+	batches := make(map[string][]*batchEntry)
+
+	var unresolved []string
+
+	for _, hl := range hashLinks {
+		hlInfo, _, err := h.getHashLinkInfoWithPossibleDomain(hl)
+		if err != nil {
+			logger.Debugf("Not resolving [%s] in batch: %s", hl, err)
+
+			unresolved = append(unresolved, hl)
+
+			continue
+		}
+
+		if _, err := h.localCAS.Read(hlInfo.ResourceHash); err == nil {
+			continue
+		}
+
+		webCASLinks, _ := separateLinks(hlInfo.Links)
+
+		endpoint, cid, ok := getBatchEndpoint(webCASLinks)
+		if !ok {
+			unresolved = append(unresolved, hl)
+
+			continue
+		}
+
+		batches[endpoint] = append(batches[endpoint], &batchEntry{hl: hl, cid: cid, hlInfo: hlInfo})
+	}
+
+	return batches, unresolved
+}
+
+// resolveBatch retrieves the given entries from the batch endpoint and stores them in the local CAS. The hashlinks
+// of the entries that weren't stored are returned.
+func (h *Resolver) resolveBatch(endpoint string, entries []*batchEntry) ([]string, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return getHashLinks(entries), fmt.Errorf("parse batch endpoint [%s]: %w", endpoint, err)
+	}
+
+	cids := make([]string, len(entries))
+
+	for i, entry := range entries {
+		cids[i] = entry.cid
+	}
+
+	data, err := h.webCASResolver.GetBatchViaWebCASEndpoint(endpointURL, cids)
+	if err != nil {
+		return getHashLinks(entries), err
+	}
+
+	var unresolved []string
+
+	for _, entry := range entries {
+		d, ok := data[entry.cid]
+		if !ok {
+			unresolved = append(unresolved, entry.hl)
+
+			continue
+		}
+
+		if err := h.verifyContent(d, entry.hlInfo); err != nil {
+			logger.Warnf("Invalid data for [%s] from batch endpoint [%s]: %s", entry.cid, endpoint, err)
+
+			unresolved = append(unresolved, entry.hl)
+
+			continue
+		}
+
+		if _, err := h.storeLocallyAndVerifyHash(d, entry.hlInfo); err != nil {
+			logger.Warnf("Unable to store data for [%s] from batch endpoint [%s]: %s", entry.cid, endpoint, err)
+
+			unresolved = append(unresolved, entry.hl)
+		}
+	}
+
+	logger.Debugf("Resolved %d of %d items from batch endpoint [%s]",
+		len(entries)-len(unresolved), len(entries), endpoint)
+
+	return unresolved, nil
+}
+
+func (h *Resolver) hasSource(source string) bool {
+	for _, s := range h.sources {
+		if s == source {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getBatchEndpoint returns the batch endpoint and CID for the first valid WebCAS link. WebCAS links are of the
+// form https://<domain>/cas/<cid> and the batch endpoint is https://<domain>/cas.
+func getBatchEndpoint(webCASLinks []string) (string, string, bool) {
+	for _, link := range webCASLinks {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+
+		dir, cid := path.Split(u.Path)
+		if cid == "" || path.Clean(dir) != webcas.BatchPath {
+			continue
+		}
+
+		u.Path = webcas.BatchPath
+		u.RawQuery = ""
+
+		return u.String(), cid, true
+	}
+
+	return "", "", false
+}
+
+func getHashLinks(entries []*batchEntry) []string {
+	hashLinks := make([]string, len(entries))
+
+	for i, entry := range entries {
+		hashLinks[i] = entry.hl
+	}
+
+	return hashLinks
+}
+
+// GetBatchViaWebCASEndpoint retrieves the data for the given CIDs from the given WebCAS batch endpoint. The data
+// is returned by CID. CIDs that weren't found by the remote server aren't included in the result.
+func (w *WebCASResolver) GetBatchViaWebCASEndpoint(batchEndpoint *url.URL, cids []string) (map[string][]byte, error) {
+	reqBytes, err := json.Marshal(cids)
+	if err != nil {
+		return nil, fmt.Errorf("marshal CIDs: %w", err)
+	}
+
+	resp, err := w.httpClient.Post(context.Background(), transport.NewRequest(batchEndpoint,
+		transport.WithHeader(contentTypeHeader, jsonContentType),
+		transport.WithHeader(transport.AcceptHeader, multipartContentType)), reqBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute POST call on %s: %w", batchEndpoint, err)
+	}
+
+	defer func() {
+		errClose := resp.Body.Close()
+		if errClose != nil {
+			logger.Errorf("failed to close response body from WebCAS batch endpoint: %s", errClose.Error())
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, e := ioutil.ReadAll(resp.Body)
+		if e != nil {
+			return nil, fmt.Errorf("failed to read response body from WebCAS batch endpoint: %w", e)
+		}
+
+		return nil, fmt.Errorf("failed to retrieve data from %s. Response status code: %d. Response body: %s",
+			batchEndpoint, resp.StatusCode, string(responseBody))
+	}
+
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get(contentTypeHeader))
+	if err != nil {
+		return nil, fmt.Errorf("parse content type of response from %s: %w", batchEndpoint, err)
+	}
+
+	if mediaType != multipartContentType {
+		return nil, fmt.Errorf("unsupported content type of response from %s: %s", batchEndpoint, mediaType)
+	}
+
+	return readParts(multipart.NewReader(resp.Body, params[multipartBoundaryName]), maxBatchResponseSize)
+}
+
+// readParts reads the parts of the given multipart response. An error is returned if the total size of the
+// content in the parts exceeds maxSize.
+func readParts(reader *multipart.Reader, maxSize int64) (map[string][]byte, error) {
+	result := make(map[string][]byte)
+
+	remaining := maxSize
+
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+
+			return nil, fmt.Errorf("read multipart response: %w", err)
+		}
+
+		cid := strings.TrimSuffix(strings.TrimPrefix(part.Header.Get(webcas.ContentIDHeader), "<"), ">")
+
+		data, err := ioutil.ReadAll(io.LimitReader(part, remaining+1))
+		if err != nil {
+			return nil, fmt.Errorf("read part for CID [%s]: %w", cid, err)
+		}
+
+		if int64(len(data)) > remaining {
+			return nil, fmt.Errorf("multipart response exceeds %d bytes", maxSize)
+		}
+
+		remaining -= int64(len(data))
+
+		if cid != "" {
+			result[cid] = data
+		}
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resolver
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	apmocks "github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/webcas"
+	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
+)
+
+func TestResolver_ResolveBatch(t *testing.T) {
+	remoteCAS := createInMemoryCAS(t)
+
+	data1 := []byte("data 1")
+	data2 := []byte("data 2")
+	notFoundData := []byte("not found")
+
+	hl1, err := remoteCAS.Write(data1)
+	require.NoError(t, err)
+
+	hl2, err := remoteCAS.Write(data2)
+	require.NoError(t, err)
+
+	batchHandler := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &apmocks.SignatureVerifier{}, remoteCAS)
+
+	var batchRequests int32
+
+	router := mux.NewRouter()
+	router.HandleFunc(batchHandler.Path(), func(rw http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&batchRequests, 1)
+
+		batchHandler.Handler()(rw, req)
+	}).Methods(batchHandler.Method())
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	newHashLink := func(t *testing.T, data []byte, links ...string) string {
+		t.Helper()
+
+		rh, err := hashlink.New().CreateResourceHash(data)
+		require.NoError(t, err)
+
+		if links == nil {
+			links = []string{testServer.URL + "/cas/" + rh}
+		}
+
+		hl, err := hashlink.New().CreateHashLink(data, links)
+		require.NoError(t, err)
+
+		return hl
+	}
+
+	webCASResolver := NewWebCASResolver(transport.Default(), webfingerclient.New(), "http")
+
+	t.Run("Success", func(t *testing.T) {
+		atomic.StoreInt32(&batchRequests, 0)
+
+		localCAS := createInMemoryCAS(t)
+
+		r := New(localCAS, nil, webCASResolver, &orbmocks.MetricsProvider{})
+
+		notFoundHL := newHashLink(t, notFoundData)
+
+		unresolved, err := r.ResolveBatch([]string{newHashLink(t, data1), newHashLink(t, data2), notFoundHL})
+		require.NoError(t, err)
+		require.Equal(t, []string{notFoundHL}, unresolved)
+		require.Equal(t, int32(1), atomic.LoadInt32(&batchRequests))
+
+		rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+		require.NoError(t, err)
+
+		data, err := localCAS.Read(rh1)
+		require.NoError(t, err)
+		require.Equal(t, data1, data)
+
+		rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+		require.NoError(t, err)
+
+		data, err = localCAS.Read(rh2)
+		require.NoError(t, err)
+		require.Equal(t, data2, data)
+
+		// The data is now local so no requests are made.
+		unresolved, err = r.ResolveBatch([]string{newHashLink(t, data1), newHashLink(t, data2)})
+		require.NoError(t, err)
+		require.Empty(t, unresolved)
+		require.Equal(t, int32(1), atomic.LoadInt32(&batchRequests))
+	})
+
+	t.Run("WebCAS source not enabled", func(t *testing.T) {
+		r := New(createInMemoryCAS(t), nil, webCASResolver, &orbmocks.MetricsProvider{},
+			WithSources(SourceLocal, SourceIPFS))
+
+		hls := []string{newHashLink(t, data1)}
+
+		unresolved, err := r.ResolveBatch(hls)
+		require.NoError(t, err)
+		require.Equal(t, hls, unresolved)
+	})
+
+	t.Run("No WebCAS batch endpoint", func(t *testing.T) {
+		r := New(createInMemoryCAS(t), nil, webCASResolver, &orbmocks.MetricsProvider{})
+
+		hls := []string{
+			newHashLink(t, data1, "https://orb.domain1.com/xxx/"+hl1),
+			newHashLink(t, data1, "ipfs://xxx"),
+			"https:orb.domain1.com:" + hl1,
+			"xx:yy",
+		}
+
+		unresolved, err := r.ResolveBatch(hls)
+		require.NoError(t, err)
+		require.Equal(t, hls, unresolved)
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		badServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			mw := multipart.NewWriter(rw)
+
+			rw.Header().Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+
+			rh, err := hashlink.New().CreateResourceHash(data1)
+			require.NoError(t, err)
+
+			header := make(textproto.MIMEHeader)
+			header.Set(webcas.ContentIDHeader, "<"+rh+">")
+
+			w, err := mw.CreatePart(header)
+			require.NoError(t, err)
+
+			_, err = w.Write([]byte("tampered data"))
+			require.NoError(t, err)
+
+			require.NoError(t, mw.Close())
+		}))
+		defer badServer.Close()
+
+		rh, err := hashlink.New().CreateResourceHash(data1)
+		require.NoError(t, err)
+
+		r := New(createInMemoryCAS(t), nil, webCASResolver, &orbmocks.MetricsProvider{})
+
+		hls := []string{newHashLink(t, data1, badServer.URL+"/cas/"+rh)}
+
+		unresolved, err := r.ResolveBatch(hls)
+		require.NoError(t, err)
+		require.Equal(t, hls, unresolved)
+	})
+
+	t.Run("Batch endpoint error", func(t *testing.T) {
+		errServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusNotFound)
+		}))
+		defer errServer.Close()
+
+		rh, err := hashlink.New().CreateResourceHash(data1)
+		require.NoError(t, err)
+
+		r := New(createInMemoryCAS(t), nil, webCASResolver, &orbmocks.MetricsProvider{})
+
+		hls := []string{newHashLink(t, data1, errServer.URL+"/cas/"+rh)}
+
+		unresolved, err := r.ResolveBatch(hls)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Response status code: 404")
+		require.Equal(t, hls, unresolved)
+	})
+
+	t.Run("Unsupported content type", func(t *testing.T) {
+		jsonServer := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+		}))
+		defer jsonServer.Close()
+
+		rh, err := hashlink.New().CreateResourceHash(data1)
+		require.NoError(t, err)
+
+		r := New(createInMemoryCAS(t), nil, webCASResolver, &orbmocks.MetricsProvider{})
+
+		_, err = r.ResolveBatch([]string{newHashLink(t, data1, jsonServer.URL+"/cas/"+rh)})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported content type")
+	})
+}
+
+func TestReadParts(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	mw := multipart.NewWriter(buf)
+
+	for _, cid := range []string{"cid1", "cid2"} {
+		header := make(textproto.MIMEHeader)
+		header.Set(webcas.ContentIDHeader, "<"+cid+">")
+
+		w, err := mw.CreatePart(header)
+		require.NoError(t, err)
+
+		_, err = w.Write([]byte("data of " + cid))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, mw.Close())
+
+	t.Run("Success", func(t *testing.T) {
+		parts, err := readParts(multipart.NewReader(bytes.NewReader(buf.Bytes()), mw.Boundary()), 24)
+		require.NoError(t, err)
+		require.Equal(t, map[string][]byte{"cid1": []byte("data of cid1"), "cid2": []byte("data of cid2")}, parts)
+	})
+
+	t.Run("Response too large", func(t *testing.T) {
+		_, err := readParts(multipart.NewReader(bytes.NewReader(buf.Bytes()), mw.Boundary()), 20)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "multipart response exceeds 20 bytes")
+	})
+}
@@ -25,6 +25,18 @@ import (
 	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
 
+// Sources from which CAS data may be retrieved.
+const (
+	// SourceLocal is the local CAS.
+	SourceLocal = "local"
+	// SourceWebCAS is the set of WebCAS links in the hashlink metadata.
+	SourceWebCAS = "webcas"
+	// SourceIPFS is the set of IPFS links in the hashlink metadata.
+	SourceIPFS = "ipfs"
+	// SourceDomain is the domain hint (resolved to a WebCAS URL via WebFinger).
+	SourceDomain = "domain"
+)
+
 const (
 	httpPrefix  = "http://"
 	httpsPrefix = "https://"
@@ -33,14 +45,20 @@ const (
 	cidWithPossibleHintNumPartsWithDomainPort = 4
 )
 
+// DefaultSources is the default order in which sources are tried.
+var DefaultSources = []string{SourceLocal, SourceWebCAS, SourceIPFS, SourceDomain}
+
 var logger = log.New("cas-resolver")
 
 type httpClient interface {
 	Get(ctx context.Context, req *transport.Request) (*http.Response, error)
+	Post(ctx context.Context, req *transport.Request, payload []byte) (*http.Response, error)
 }
 
 type metricsProvider interface {
 	CASResolveTime(value time.Duration)
+	CASSourceResolveTime(source string, value time.Duration)
+	CASSourceResolveResult(source string, success bool)
 }
 
 // Resolver represents a resolver that can resolve data in a CAS based on a CID (with possible hint) and a WebCAS URL.
@@ -50,46 +68,98 @@ type Resolver struct {
 	webCASResolver WebCASResolver
 	metrics        metricsProvider
 	hl             *hashlink.HashLink
+	sources        []string
+	timeouts       map[string]time.Duration
+	hedgingDelay   time.Duration
 }
 
 type ipfsReader interface {
 	Read(address string) ([]byte, error)
 }
 
+// Option is a resolver option.
+type Option func(r *Resolver)
+
+// WithSources sets the order in which sources are tried (see SourceLocal, SourceWebCAS, SourceIPFS and
+// SourceDomain). Sources that aren't included are never tried. Defaults to DefaultSources.
+func WithSources(sources ...string) Option {
+	return func(r *Resolver) {
+		r.sources = sources
+	}
+}
+
+// WithSourceTimeout sets the maximum time to wait for a single request to the given source.
+// By default there is no timeout (other than the timeout of the underlying client).
+func WithSourceTimeout(source string, timeout time.Duration) Option {
+	return func(r *Resolver) {
+		r.timeouts[source] = timeout
+	}
+}
+
+// WithHedgingDelay enables hedged requests. If a request to a source doesn't complete within the given delay
+// then a request to the next source is started in parallel, and the first verified response wins.
+// Hedged requests are disabled by default.
+func WithHedgingDelay(delay time.Duration) Option {
+	return func(r *Resolver) {
+		r.hedgingDelay = delay
+	}
+}
+
+// WithHashLink sets the hashlink instance that's used to parse hashlinks and to verify resolved content.
+// The instance should accept the multihash codes used by other servers (see hashlink.WithAcceptedMultihashCodes).
+// By default all of the supported multihash codes are accepted.
+func WithHashLink(hl *hashlink.HashLink) Option {
+	return func(r *Resolver) {
+		r.hl = hl
+	}
+}
+
 // New returns a new Resolver.
 // ipfsReader is optional. If not provided (is nil), CIDs with IPFS hints won't be resolvable.
 func New(casClient extendedcasclient.Client, ipfsReader ipfsReader, webCASResolver WebCASResolver,
-	metrics metricsProvider) *Resolver {
-	return &Resolver{
+	metrics metricsProvider, opts ...Option) *Resolver {
+	r := &Resolver{
 		localCAS:       casClient,
 		ipfsReader:     ipfsReader,
 		webCASResolver: webCASResolver,
 		metrics:        metrics,
-		hl:             hashlink.New(),
+		hl:             hashlink.New(hashlink.WithAcceptedMultihashCodes(hashlink.SupportedMultihashCodes...)),
+		sources:        DefaultSources,
+		timeouts:       make(map[string]time.Duration),
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
+
+	return r
 }
 
 // Resolve does the following:
 // 1. If data is provided (not nil), then it will be stored via the local CAS. That data passed in will then simply be
 //    returned back to the caller, along with the hashlink of the stored data.
-// 2. If data is not provided (is nil), then the local CAS will be checked to see if it has data at the cid provided.
-//    If it does, then it is returned. If it doesn't, and a webCASURL is provided, then the data will be retrieved by
-//    querying the webCASURL. This data will then get stored in the local CAS.
+// 2. If data is not provided (is nil), then the data is retrieved from the configured sources in order: the local
+//    CAS, the WebCAS links and IPFS links in the hashlink metadata, and the domain hint. Every link is tried until
+//    the data is found (optionally with hedged requests). The data retrieved from each source is verified against
+//    the resource hash and any additional resource hashes (computed using other hash algorithms) in the hashlink
+//    metadata. Data that was retrieved from a remote source is then stored in the local CAS.
 //    Finally, the data is returned to the caller, along with the hashlink of the stored data.
 // In both cases above, the CID produced by the local CAS will be checked against the cid passed in to ensure they are
 // the same.
-func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte) ([]byte, string, error) { //nolint:gocyclo,cyclop,lll
+func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte) ([]byte, string, error) {
 	startTime := time.Now()
 
-	defer h.metrics.CASResolveTime(time.Since(startTime))
+	defer func() {
+		h.metrics.CASResolveTime(time.Since(startTime))
+	}()
 
-	resourceHash, domain, links, err := h.getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint)
+	hlInfo, domain, err := h.getHashLinkInfoWithPossibleDomain(hashWithPossibleHint)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to get resource hash from[%s]: %w", hashWithPossibleHint, err)
 	}
 
 	if data != nil {
-		localHL, e := h.storeLocallyAndVerifyHash(data, resourceHash)
+		localHL, e := h.storeLocallyAndVerifyHash(data, hlInfo)
 		if e != nil {
 			return nil, "", fmt.Errorf("failed to store the data in the local CAS: %w", e)
 		}
@@ -97,193 +167,375 @@ func (h *Resolver) Resolve(_ *url.URL, hashWithPossibleHint string, data []byte)
 		return data, localHL, nil
 	}
 
-	casLinks, ipfsLinks := separateLinks(links)
-	logger.Debugf("resolving hashWithPossibleHint[%s], resource hash[%s], domain[%s], cas links%s, ipfs links%s", hashWithPossibleHint, resourceHash, domain, casLinks, ipfsLinks) //nolint:lll
+	casLinks, ipfsLinks := separateLinks(hlInfo.Links)
+	logger.Debugf("resolving hashWithPossibleHint[%s], resource hash[%s], domain[%s], cas links%s, ipfs links%s", hashWithPossibleHint, hlInfo.ResourceHash, domain, casLinks, ipfsLinks) //nolint:lll
 
-	if h.localCAS.GetPrimaryWriterType() == "ipfs" && len(ipfsLinks) > 0 {
-		cid := ipfsLinks[0][len(ipfsPrefix):]
+	return h.resolve(hlInfo, h.getRequests(hlInfo, domain, casLinks, ipfsLinks))
+}
 
-		data, e := h.localCAS.Read(cid)
-		if e != nil {
-			return nil, "", fmt.Errorf("read from IPFS: %w", e)
-		}
+// request is a request for data from a single source.
+type request struct {
+	source string
+	target string
+	read   func() ([]byte, error)
+	// store indicates that the data needs to be stored in the local CAS once it's retrieved.
+	store bool
+}
 
-		return data, "", nil
-	}
+type response struct {
+	req  *request
+	data []byte
+	err  error
+}
 
-	// Ensure we have the data stored in the local CAS.
-	dataFromLocal, err := h.localCAS.Read(resourceHash)
-	if err != nil { //nolint: nestif // Breaking this up seems worse than leaving the nested ifs
-		if errors.Is(err, orberrors.ErrContentNotFound) {
-			if len(casLinks) > 0 {
-				dataFromRemote, localHL, errGetAndStoreRemoteData := h.getAndStoreDataFromWebCASEndpoints(casLinks, resourceHash)
-				if errGetAndStoreRemoteData != nil {
-					return nil, "", fmt.Errorf("failure while getting and storing data from the remote "+
-						"WebCAS endpoints: %w", errGetAndStoreRemoteData)
-				}
+func (h *Resolver) getRequests(hlInfo *hashlink.Info, domain string, casLinks, ipfsLinks []string) []*request {
+	var requests []*request
+
+	resourceHash := hlInfo.ResourceHash
 
-				return dataFromRemote, localHL, nil
+	for _, source := range h.sources {
+		switch source {
+		case SourceLocal:
+			// The local CAS may have stored the data under one of the additional resource hashes if it uses a
+			// different hash algorithm than the one used for the resource hash.
+			for _, rh := range append([]string{resourceHash}, hlInfo.ResourceHashes...) {
+				requests = append(requests, h.newLocalRequest(rh))
 			}
 
-			if h.ipfsReader != nil && len(ipfsLinks) > 0 {
-				return h.getAndStoreDataFromIPFS(ipfsLinks[0][len(ipfsPrefix):], resourceHash)
+		case SourceWebCAS:
+			for _, link := range casLinks {
+				requests = append(requests, h.newWebCASRequest(link))
 			}
 
+		case SourceIPFS:
+			for _, link := range ipfsLinks {
+				if req := h.newIPFSRequest(link[len(ipfsPrefix):]); req != nil {
+					requests = append(requests, req)
+				}
+			}
+
+		case SourceDomain:
 			if domain != "" {
-				return h.getAndStoreDataFromDomain(domain, resourceHash)
+				requests = append(requests, &request{
+					source: SourceDomain,
+					target: domain,
+					read: func() ([]byte, error) {
+						data, err := h.webCASResolver.Resolve(domain, resourceHash)
+						if err != nil {
+							return nil, fmt.Errorf("failed to resolve domain and resource hash via WebCAS: %w", err)
+						}
+
+						return data, nil
+					},
+					store: true,
+				})
 			}
+
+		default:
+			logger.Warnf("Ignoring unsupported CAS source: %s", source)
 		}
+	}
+
+	return requests
+}
+
+func (h *Resolver) newLocalRequest(resourceHash string) *request {
+	return &request{
+		source: SourceLocal,
+		target: resourceHash,
+		read: func() ([]byte, error) {
+			data, err := h.localCAS.Read(resourceHash)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get data stored at %s from the local CAS: %w", resourceHash, err)
+			}
 
-		return nil, "", fmt.Errorf("failed to get data stored at %s from the local CAS: %w", resourceHash, err)
+			return data, nil
+		},
 	}
+}
+
+func (h *Resolver) newWebCASRequest(link string) *request {
+	return &request{
+		source: SourceWebCAS,
+		target: link,
+		read: func() ([]byte, error) {
+			webCASEndpoint, err := url.Parse(link)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse webcas endpoint: %w", err)
+			}
+
+			data, err := h.webCASResolver.GetDataViaWebCASEndpoint(webCASEndpoint)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get data via WebCAS endpoint: %w", err)
+			}
 
-	return dataFromLocal, "", nil
+			return data, nil
+		},
+		store: true,
+	}
 }
 
-func (h *Resolver) getResourceHashWithPossibleDomainAndLinks(hashWithPossibleHint string) (string, string, []string, error) { //nolint:lll
-	var domain string
+func (h *Resolver) newIPFSRequest(cid string) *request {
+	if h.localCAS.GetPrimaryWriterType() == SourceIPFS {
+		// The local CAS is IPFS so there's no need to store the data after it's read.
+		return &request{
+			source: SourceIPFS,
+			target: cid,
+			read: func() ([]byte, error) {
+				data, err := h.localCAS.Read(cid)
+				if err != nil {
+					return nil, fmt.Errorf("read from IPFS: %w", err)
+				}
 
-	var links []string
+				return data, nil
+			},
+		}
+	}
 
-	resourceHash := hashWithPossibleHint
+	if h.ipfsReader == nil {
+		return nil
+	}
 
-	hashWithPossibleHintParts := strings.Split(hashWithPossibleHint, ":")
-	if len(hashWithPossibleHintParts) == 1 {
-		return resourceHash, "", nil, nil
+	return &request{
+		source: SourceIPFS,
+		target: cid,
+		read: func() ([]byte, error) {
+			data, err := h.ipfsReader.Read(cid)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cid[%s] from ipfs: %w", cid, err)
+			}
+
+			return data, nil
+		},
+		store: true,
 	}
+}
 
-	switch hashWithPossibleHintParts[0] {
-	case "https":
-		resourceHash = hashWithPossibleHintParts[len(hashWithPossibleHintParts)-1]
+// resolve sends the requests in order until one of them returns data that matches the resource hash(es). If hedging
+// is enabled then the next request is sent if the previous request doesn't complete within the hedging delay.
+func (h *Resolver) resolve(hlInfo *hashlink.Info, requests []*request) ([]byte, string, error) {
+	resourceHash := hlInfo.ResourceHash
 
-		domain = hashWithPossibleHintParts[1]
+	if len(requests) == 0 {
+		return nil, "", fmt.Errorf("no sources available to retrieve data for resource hash[%s]", resourceHash)
+	}
 
-		// If the domain in the hint contains a port, this will ensure it's included.
-		if len(hashWithPossibleHintParts) == cidWithPossibleHintNumPartsWithDomainPort {
-			domain = fmt.Sprintf("%s:%s", domain, hashWithPossibleHintParts[2])
-		}
+	responses := make(chan *response, len(requests))
 
-	case "hl":
-		resourceHash = hashWithPossibleHintParts[1]
+	var (
+		next    int
+		pending int
+		errs    []*response
+	)
 
-		hlInfo, err := h.hl.ParseHashLink(hashWithPossibleHint)
-		if err != nil {
-			return "", "", nil, fmt.Errorf("failed to parse hash link: %w", err)
+	sendNext := func() {
+		go h.send(requests[next], hlInfo, responses)
+
+		next++
+		pending++
+	}
+
+	sendNext()
+
+	for pending > 0 {
+		var hedge <-chan time.Time
+
+		if h.hedgingDelay > 0 && next < len(requests) {
+			hedge = time.After(h.hedgingDelay)
 		}
 
-		links = hlInfo.Links
+		select {
+		case resp := <-responses:
+			pending--
 
-	default:
-		return "", "", nil, fmt.Errorf("hint '%s' not supported", hashWithPossibleHintParts[0])
-	}
+			if resp.err == nil {
+				return h.handleResponse(resp, hlInfo)
+			}
 
-	return resourceHash, domain, links, nil
-}
+			logger.Debugf("Failed to retrieve data for resource hash[%s] from %s[%s]: %s",
+				resourceHash, resp.req.source, resp.req.target, resp.err)
 
-func separateLinks(links []string) ([]string, []string) {
-	var webcasLinks []string
+			errs = append(errs, resp)
 
-	var ipfsLinks []string
+			if next < len(requests) {
+				sendNext()
+			}
 
-	for _, link := range links {
-		switch {
-		case strings.HasPrefix(link, httpsPrefix) || strings.HasPrefix(link, httpPrefix):
-			webcasLinks = append(webcasLinks, link)
-		case strings.HasPrefix(link, ipfsPrefix):
-			ipfsLinks = append(ipfsLinks, link)
-		default:
-			logger.Debugf("ignoring metadata link during CAS resolution: %s", link)
+		case <-hedge:
+			logger.Debugf("No response for resource hash[%s] within %s. Sending hedged request to %s[%s]",
+				resourceHash, h.hedgingDelay, requests[next].source, requests[next].target)
+
+			sendNext()
 		}
 	}
 
-	return webcasLinks, ipfsLinks
+	return nil, "", newResolveError(resourceHash, errs)
 }
 
-func (h *Resolver) getAndStoreDataFromDomain(domain, resourceHash string) ([]byte, string, error) {
-	dataFromRemote, err := h.webCASResolver.Resolve(domain, resourceHash)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to resolve domain and resource hash via WebCAS: %w", err)
-	}
+func (h *Resolver) send(req *request, hlInfo *hashlink.Info, responses chan<- *response) {
+	startTime := time.Now()
 
-	localHL, errStoreLocallyAndVerifyHash := h.storeLocallyAndVerifyHash(dataFromRemote, resourceHash)
-	if errStoreLocallyAndVerifyHash != nil {
-		return nil, "", fmt.Errorf("failure while storing data retrieved from the remote "+
-			"WebCAS endpoint locally: %w", errStoreLocallyAndVerifyHash)
+	data, err := h.read(req)
+	if err == nil {
+		err = h.verifyContent(data, hlInfo)
 	}
 
-	logger.Debugf("successfully retrieved data for resource hash[%s] from https domain[%s]", resourceHash, domain)
+	h.metrics.CASSourceResolveTime(req.source, time.Since(startTime))
+	h.metrics.CASSourceResolveResult(req.source, err == nil)
 
-	return dataFromRemote, localHL, nil
+	responses <- &response{req: req, data: data, err: err}
 }
 
-func (h *Resolver) getAndStoreDataFromWebCASEndpoints(webCASEndpoints []string, cid string) ([]byte, string, error) {
-	if len(webCASEndpoints) == 0 {
-		return nil, "", fmt.Errorf("must provide at least one cas endpoint in order to retrieve data")
+func (h *Resolver) read(req *request) ([]byte, error) {
+	timeout, ok := h.timeouts[req.source]
+	if !ok || timeout <= 0 {
+		return req.read()
 	}
 
-	var isTransient bool
+	responses := make(chan *response, 1)
 
-	var errMsgs []string
+	go func() {
+		data, err := req.read()
 
-	for _, webCASEndpoint := range webCASEndpoints {
-		data, localHL, err := h.getAndStoreDataFromWebCASEndpoint(webCASEndpoint, cid)
-		if err != nil {
-			errMsg := fmt.Sprintf("endpoint[%s]: %s", webCASEndpoint, err.Error())
+		responses <- &response{data: data, err: err}
+	}()
 
-			errMsgs = append(errMsgs, errMsg)
-			isTransient = isTransient || orberrors.IsTransient(err)
+	select {
+	case resp := <-responses:
+		return resp.data, resp.err
+	case <-time.After(timeout):
+		return nil, orberrors.NewTransient(fmt.Errorf("timed out after %s", timeout))
+	}
+}
 
-			continue
-		}
+func (h *Resolver) handleResponse(resp *response, hlInfo *hashlink.Info) ([]byte, string, error) {
+	logger.Debugf("Retrieved data for resource hash[%s] from %s[%s]", hlInfo.ResourceHash, resp.req.source,
+		resp.req.target)
 
-		return data, localHL, nil
+	if !resp.req.store {
+		return resp.data, "", nil
+	}
+
+	localHL, err := h.storeLocallyAndVerifyHash(resp.data, hlInfo)
+	if err != nil {
+		return nil, "", fmt.Errorf("failure while storing data retrieved from %s[%s] locally: %w",
+			resp.req.source, resp.req.target, err)
 	}
 
-	err := fmt.Errorf("%s", errMsgs)
+	return resp.data, localHL, nil
+}
 
-	if isTransient {
-		return nil, "", orberrors.NewTransient(err)
+// verifyContent ensures that the hash of the given data matches the resource hash and all of the additional
+// resource hashes. Each hash is computed using the hash algorithm of the resource hash.
+func (h *Resolver) verifyContent(data []byte, hlInfo *hashlink.Info) error {
+	if err := h.hl.VerifyContent(data, hlInfo); err != nil {
+		return fmt.Errorf("the retrieved data does not match the requested resource hash: %w", err)
 	}
 
-	return nil, "", err
+	return nil
 }
 
-func (h *Resolver) getAndStoreDataFromWebCASEndpoint(webCASEndpoint, cid string) ([]byte, string, error) {
-	webCASEndpointLink, err := url.Parse(webCASEndpoint)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to parse webcas endpoint: %w", err)
+// newResolveError returns the error of the failed requests. A 'not found' error from the local CAS isn't included
+// if a remote source was also tried. The returned error is transient if any of the errors are transient.
+func newResolveError(resourceHash string, responses []*response) error {
+	var remoteResponses []*response
+
+	for _, resp := range responses {
+		if resp.req.source != SourceLocal || !errors.Is(resp.err, orberrors.ErrContentNotFound) {
+			remoteResponses = append(remoteResponses, resp)
+		}
 	}
 
-	dataFromRemote, err := h.webCASResolver.GetDataViaWebCASEndpoint(webCASEndpointLink)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get data via WebCAS endpoint: %w", err)
+	if len(remoteResponses) > 0 {
+		responses = remoteResponses
 	}
 
-	localHL, errStoreLocallyAndVerifyCID := h.storeLocallyAndVerifyHash(dataFromRemote, cid)
-	if errStoreLocallyAndVerifyCID != nil {
-		return nil, "", fmt.Errorf("failure while storing data retrieved from the remote "+
-			"WebCAS endpoint locally: %w", errStoreLocallyAndVerifyCID)
+	if len(responses) == 1 {
+		return responses[0].err
+	}
+
+	var (
+		isTransient bool
+		errMsgs     []string
+	)
+
+	for _, resp := range responses {
+		errMsgs = append(errMsgs, fmt.Sprintf("%s[%s]: %s", resp.req.source, resp.req.target, resp.err))
+
+		isTransient = isTransient || orberrors.IsTransient(resp.err)
+	}
+
+	err := fmt.Errorf("failed to retrieve data for resource hash[%s] from any source: %s",
+		resourceHash, strings.Join(errMsgs, "; "))
+
+	if isTransient {
+		return orberrors.NewTransient(err)
 	}
 
-	return dataFromRemote, localHL, nil
+	return err
 }
 
-func (h *Resolver) getAndStoreDataFromIPFS(cid, resourceHash string) ([]byte, string, error) {
-	resp, err := h.ipfsReader.Read(cid)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read cid[%s] from ipfs: %w", cid, err)
+// getHashLinkInfoWithPossibleDomain returns the hashlink info (resource hash, links and additional resource
+// hashes) and the domain hint (if any) from the given hash.
+func (h *Resolver) getHashLinkInfoWithPossibleDomain(hashWithPossibleHint string) (*hashlink.Info, string, error) {
+	var domain string
+
+	hashWithPossibleHintParts := strings.Split(hashWithPossibleHint, ":")
+	if len(hashWithPossibleHintParts) == 1 {
+		return &hashlink.Info{ResourceHash: hashWithPossibleHint}, "", nil
 	}
 
-	localHL, err := h.storeLocallyAndVerifyHash(resp, resourceHash)
-	if err != nil {
-		return nil, "", fmt.Errorf("failure while storing data retrieved from the ipfs: %w",
-			err)
+	switch hashWithPossibleHintParts[0] {
+	case "https":
+		resourceHash := hashWithPossibleHintParts[len(hashWithPossibleHintParts)-1]
+
+		domain = hashWithPossibleHintParts[1]
+
+		// If the domain in the hint contains a port, this will ensure it's included.
+		if len(hashWithPossibleHintParts) == cidWithPossibleHintNumPartsWithDomainPort {
+			domain = fmt.Sprintf("%s:%s", domain, hashWithPossibleHintParts[2])
+		}
+
+		return &hashlink.Info{ResourceHash: resourceHash}, domain, nil
+
+	case "hl":
+		hlInfo, err := h.hl.ParseHashLink(hashWithPossibleHint)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to parse hash link: %w", err)
+		}
+
+		return hlInfo, "", nil
+
+	default:
+		return nil, "", fmt.Errorf("hint '%s' not supported", hashWithPossibleHintParts[0])
 	}
+}
+
+func separateLinks(links []string) ([]string, []string) {
+	var webcasLinks []string
 
-	return resp, localHL, nil
+	var ipfsLinks []string
+
+	for _, link := range links {
+		switch {
+		case strings.HasPrefix(link, httpsPrefix) || strings.HasPrefix(link, httpPrefix):
+			webcasLinks = append(webcasLinks, link)
+		case strings.HasPrefix(link, ipfsPrefix):
+			ipfsLinks = append(ipfsLinks, link)
+		default:
+			logger.Debugf("ignoring metadata link during CAS resolution: %s", link)
+		}
+	}
+
+	return webcasLinks, ipfsLinks
 }
 
-func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) (string, error) {
+// storeLocallyAndVerifyHash stores the data in the local CAS and ensures that the resource hash produced by the
+// local CAS matches either the resource hash or one of the additional resource hashes. If the local CAS uses a
+// different hash algorithm than the hashlink then the data is verified against the resource hash instead.
+func (h *Resolver) storeLocallyAndVerifyHash(data []byte, hlInfo *hashlink.Info) (string, error) {
+	resourceHash := hlInfo.ResourceHash
+
 	newHLFromLocalCAS, err := h.localCAS.Write(data)
 	if err != nil {
 		return "", fmt.Errorf("failed to write data to CAS "+
@@ -300,7 +552,14 @@ func (h *Resolver) storeLocallyAndVerifyHash(data []byte, resourceHash string) (
 			"(and get resource hash in the process of doing so): %w", err)
 	}
 
-	if newResourceHash != resourceHash {
+	if !contains(append([]string{resourceHash}, hlInfo.ResourceHashes...), newResourceHash) {
+		if e := h.hl.VerifyResourceHash(data, resourceHash); e == nil {
+			logger.Debugf("The local CAS stored the data for resource hash[%s] under resource hash[%s] which "+
+				"uses a different hash algorithm", resourceHash, newResourceHash)
+
+			return newHLFromLocalCAS, nil
+		}
+
 		return "", fmt.Errorf("successfully stored data into the local CAS, but the resource hash produced by "+
 			"the local CAS (%s) does not match the resource hash from the original request (%s)",
 			newResourceHash, resourceHash)
@@ -371,3 +630,13 @@ func (w *WebCASResolver) GetDataViaWebCASEndpoint(webCASEndpoint *url.URL) ([]by
 
 	return responseBody, nil
 }
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
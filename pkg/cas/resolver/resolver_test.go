@@ -7,19 +7,26 @@ SPDX-License-Identifier: Apache-2.0
 package resolver
 
 import (
+	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
+	cbor "github.com/fxamacker/cbor/v2"
 	"github.com/gorilla/mux"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/activitypub/client/transport"
@@ -98,6 +105,13 @@ const (
 
 func TestNew(t *testing.T) {
 	createNewResolver(t, createInMemoryCAS(t), createInMemoryCAS(t))
+
+	t.Run("With hashlink", func(t *testing.T) {
+		hl := hashlink.New(hashlink.WithAdditionalMultihashCodes(multihash.SHA3_256))
+
+		r := New(createInMemoryCAS(t), nil, WebCASResolver{}, &orbmocks.MetricsProvider{}, WithHashLink(hl))
+		require.True(t, r.hl == hl)
+	})
 }
 
 func TestResolver_Resolve(t *testing.T) {
@@ -325,7 +339,7 @@ func TestResolver_Resolve(t *testing.T) {
 		hl, err := hashlink.New().CreateHashLink([]byte(sampleData), []string{"ipfs://" + sampleDataCIDv1})
 		require.NoError(t, err)
 
-		ipfsClient := ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		ipfsClient := ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, ipfsClient)
 
 		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsClient)
@@ -428,7 +442,7 @@ func TestResolver_Resolve(t *testing.T) {
 		}))
 		defer ipfsServer.Close()
 
-		ipfsClient := ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		ipfsClient := ipfs.New(ipfsServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 		require.NotNil(t, ipfsClient)
 
 		resolver := createNewResolver(t, createInMemoryCAS(t), ipfsClient)
@@ -489,7 +503,7 @@ func TestResolver_Resolve(t *testing.T) {
 
 		data, localHL, err := resolver.Resolve(nil, hl, nil)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "failure while getting and storing data from the remote WebCAS endpoints")
+		require.Contains(t, err.Error(), "failed to get data via WebCAS endpoint")
 		require.Contains(t, err.Error(), "Response status code: 404. Response body: "+
 			"no content at uEiA1t3VICW2jRlU-m3o7-3f1lI5hbLTrPkefScZMEFwbEQ was found: content not found")
 		require.Nil(t, data)
@@ -518,7 +532,7 @@ func TestResolver_Resolve(t *testing.T) {
 				ErrGet: ariesstorage.ErrDataNotFound,
 				ErrPut: errors.New("put error"),
 			},
-		}, sampleCASURL, nil, &orbmocks.MetricsProvider{}, 0)
+		}, sampleCASURL, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		// The local resolver here has a CAS without the data we need, so it'll have to ask the remote Orb server
@@ -544,7 +558,7 @@ func TestResolver_Resolve(t *testing.T) {
 			OpenStoreReturn: &ariesmockstorage.Store{
 				ErrGet: errors.New("get error"),
 			},
-		}, sampleCASURL, nil, &orbmocks.MetricsProvider{}, 0)
+		}, sampleCASURL, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -725,6 +739,219 @@ func TestResolver_Resolve(t *testing.T) {
 	})
 }
 
+func TestResolver_Sources(t *testing.T) {
+	data := []byte(sampleData)
+
+	const (
+		webCASLink1 = "https://orb.domain1.com/cas/xxx"
+		webCASLink2 = "https://orb.domain2.com/cas/xxx"
+		ipfsCID1    = "cid1"
+		ipfsCID2    = "cid2"
+	)
+
+	hl, err := hashlink.New().CreateHashLink(data,
+		[]string{webCASLink1, webCASLink2, ipfsPrefix + ipfsCID1, ipfsPrefix + ipfsCID2})
+	require.NoError(t, err)
+
+	t.Run("Fall back from WebCAS to IPFS", func(t *testing.T) {
+		httpClient := newMockHTTPClient().
+			withResponse(webCASLink1, http.StatusInternalServerError, nil).
+			withResponse(webCASLink2, http.StatusOK, []byte("tampered data"))
+
+		ipfsReader := newMockIPFSReader().withError(ipfsCID1, errors.New("injected IPFS error")).withData(ipfsCID2, data)
+
+		localCAS := createInMemoryCAS(t)
+
+		r := New(localCAS, ipfsReader, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{})
+
+		result, localHL, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.NotEmpty(t, localHL)
+		require.Equal(t, []string{webCASLink1, webCASLink2}, httpClient.requested())
+		require.Equal(t, []string{ipfsCID1, ipfsCID2}, ipfsReader.requested())
+
+		// The data is now in the local CAS.
+		rh, err := hashlink.New().CreateResourceHash(data)
+		require.NoError(t, err)
+
+		_, err = localCAS.Read(rh)
+		require.NoError(t, err)
+	})
+
+	t.Run("Source order", func(t *testing.T) {
+		httpClient := newMockHTTPClient().
+			withResponse(webCASLink1, http.StatusOK, data).
+			withResponse(webCASLink2, http.StatusOK, data)
+
+		ipfsReader := newMockIPFSReader().withData(ipfsCID1, data)
+
+		r := New(createInMemoryCAS(t), ipfsReader, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSources(SourceLocal, SourceIPFS, SourceWebCAS))
+
+		result, _, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.Empty(t, httpClient.requested())
+		require.Equal(t, []string{ipfsCID1}, ipfsReader.requested())
+	})
+
+	t.Run("Data from IPFS (primary writer) is verified", func(t *testing.T) {
+		casClient := &resolvermocks.CASClient{}
+		casClient.GetPrimaryWriterTypeReturns("ipfs")
+		casClient.ReadReturns([]byte("tampered data"), nil)
+
+		r := New(casClient, nil, NewWebCASResolver(newMockHTTPClient(), webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSources(SourceLocal, SourceIPFS))
+
+		_, _, err := r.Resolve(nil, hl, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the requested resource hash")
+		require.Contains(t, err.Error(), "ipfs[cid1]")
+		require.Contains(t, err.Error(), "ipfs[cid2]")
+		require.Equal(t, 3, casClient.ReadCallCount())
+	})
+
+	t.Run("Source timeout", func(t *testing.T) {
+		httpClient := newMockHTTPClient().
+			withResponse(webCASLink1, http.StatusOK, data).
+			withDelay(webCASLink1, time.Second).
+			withResponse(webCASLink2, http.StatusOK, data)
+
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSources(SourceWebCAS),
+			WithSourceTimeout(SourceWebCAS, 50*time.Millisecond))
+
+		result, _, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.Equal(t, []string{webCASLink1, webCASLink2}, httpClient.requested())
+	})
+
+	t.Run("Source timeout error is transient", func(t *testing.T) {
+		httpClient := newMockHTTPClient().
+			withResponse(webCASLink1, http.StatusOK, data).
+			withDelay(webCASLink1, time.Second)
+
+		hl, err := hashlink.New().CreateHashLink(data, []string{webCASLink1})
+		require.NoError(t, err)
+
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSourceTimeout(SourceWebCAS, 50*time.Millisecond))
+
+		_, _, err = r.Resolve(nil, hl, nil)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "timed out after 50ms")
+	})
+
+	t.Run("Hedged requests", func(t *testing.T) {
+		httpClient := newMockHTTPClient().
+			withResponse(webCASLink1, http.StatusOK, data).
+			withDelay(webCASLink1, time.Second).
+			withResponse(webCASLink2, http.StatusOK, data)
+
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSources(SourceWebCAS), WithHedgingDelay(20*time.Millisecond))
+
+		start := time.Now()
+
+		result, _, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.Less(t, int64(time.Since(start)), int64(time.Second))
+	})
+
+	t.Run("Additional resource hashes", func(t *testing.T) {
+		hl, err := hashlink.New(hashlink.WithAdditionalMultihashCodes(multihash.SHA3_256)).CreateHashLink(data,
+			[]string{webCASLink1})
+		require.NoError(t, err)
+
+		httpClient := newMockHTTPClient().withResponse(webCASLink1, http.StatusOK, data)
+
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{})
+
+		result, localHL, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.NotEmpty(t, localHL)
+	})
+
+	t.Run("Additional resource hash doesn't match", func(t *testing.T) {
+		otherHL, err := hashlink.New(hashlink.WithAdditionalMultihashCodes(multihash.SHA3_256)).CreateHashLink(
+			[]byte("other data"), nil)
+		require.NoError(t, err)
+
+		otherInfo, err := hashlink.New().ParseHashLink(otherHL)
+		require.NoError(t, err)
+
+		rh, err := hashlink.New().CreateResourceHash(data)
+		require.NoError(t, err)
+
+		// The resource hash matches the data but the additional (SHA3-256) resource hash doesn't.
+		metadata := createMetadata(t, []string{webCASLink1}, otherInfo.ResourceHashes)
+
+		httpClient := newMockHTTPClient().withResponse(webCASLink1, http.StatusOK, data)
+
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{})
+
+		_, _, err = r.Resolve(nil, hashlink.GetHashLink(rh, metadata), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the requested resource hash")
+	})
+
+	t.Run("Migrated hash algorithm", func(t *testing.T) {
+		hl, err := hashlink.New(hashlink.WithMultihashCode(multihash.SHA3_256),
+			hashlink.WithAdditionalMultihashCodes(multihash.SHA2_256)).CreateHashLink(data, []string{webCASLink1})
+		require.NoError(t, err)
+
+		httpClient := newMockHTTPClient().withResponse(webCASLink1, http.StatusOK, data)
+
+		localCAS := createInMemoryCAS(t)
+
+		r := New(localCAS, nil, NewWebCASResolver(httpClient, webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{})
+
+		// The data is retrieved from WebCAS and stored in the local CAS under the SHA2-256 resource hash.
+		result, localHL, err := r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.NotEmpty(t, localHL)
+		require.Equal(t, []string{webCASLink1}, httpClient.requested())
+
+		// The data is now found in the local CAS using the additional resource hash.
+		result, _, err = r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.Equal(t, []string{webCASLink1}, httpClient.requested())
+
+		// The data is stored even if the hashlink doesn't include a SHA2-256 resource hash.
+		hl, err = hashlink.New(hashlink.WithMultihashCode(multihash.SHA3_256)).CreateHashLink(data,
+			[]string{webCASLink1})
+		require.NoError(t, err)
+
+		r = New(createInMemoryCAS(t), nil, NewWebCASResolver(newMockHTTPClient().withResponse(webCASLink1,
+			http.StatusOK, data), webfingerclient.New(), httpScheme), &orbmocks.MetricsProvider{})
+
+		result, localHL, err = r.Resolve(nil, hl, nil)
+		require.NoError(t, err)
+		require.Equal(t, data, result)
+		require.NotEmpty(t, localHL)
+	})
+
+	t.Run("No sources", func(t *testing.T) {
+		r := New(createInMemoryCAS(t), nil, NewWebCASResolver(newMockHTTPClient(), webfingerclient.New(), httpScheme),
+			&orbmocks.MetricsProvider{}, WithSources("unsupported"))
+
+		_, _, err := r.Resolve(nil, hl, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "no sources available")
+	})
+}
+
 func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsReader ipfsReader) *Resolver {
 	t.Helper()
 
@@ -743,6 +970,15 @@ func createNewResolver(t *testing.T, casClient extendedcasclient.Client, ipfsRea
 	return casResolver
 }
 
+func createMetadata(t *testing.T, links, resourceHashes []string) string {
+	t.Helper()
+
+	metadata, err := cbor.Marshal(map[int]interface{}{0x0f: links, 0x0b: resourceHashes})
+	require.NoError(t, err)
+
+	return "u" + base64.RawURLEncoding.EncodeToString(metadata)
+}
+
 func createInMemoryCAS(t *testing.T) extendedcasclient.Client {
 	t.Helper()
 
@@ -752,8 +988,112 @@ func createInMemoryCAS(t *testing.T) extendedcasclient.Client {
 func createInMemoryCASWithLink(t *testing.T, casLink string) extendedcasclient.Client {
 	t.Helper()
 
-	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 	require.NoError(t, err)
 
 	return casClient
 }
+
+type mockHTTPResponse struct {
+	status int
+	body   []byte
+	delay  time.Duration
+}
+
+type mockHTTPClient struct {
+	mutex     sync.Mutex
+	responses map[string]*mockHTTPResponse
+	requests  []string
+}
+
+func newMockHTTPClient() *mockHTTPClient {
+	return &mockHTTPClient{responses: make(map[string]*mockHTTPResponse)}
+}
+
+func (m *mockHTTPClient) withResponse(u string, status int, body []byte) *mockHTTPClient {
+	m.responses[u] = &mockHTTPResponse{status: status, body: body}
+
+	return m
+}
+
+func (m *mockHTTPClient) withDelay(u string, delay time.Duration) *mockHTTPClient {
+	m.responses[u].delay = delay
+
+	return m
+}
+
+func (m *mockHTTPClient) requested() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.requests
+}
+
+func (m *mockHTTPClient) Get(_ context.Context, req *transport.Request) (*http.Response, error) {
+	m.mutex.Lock()
+	m.requests = append(m.requests, req.URL.String())
+	m.mutex.Unlock()
+
+	resp, ok := m.responses[req.URL.String()]
+	if !ok {
+		return nil, fmt.Errorf("no response for %s", req.URL)
+	}
+
+	time.Sleep(resp.delay)
+
+	return &http.Response{
+		StatusCode: resp.status,
+		Body:       ioutil.NopCloser(bytes.NewReader(resp.body)),
+	}, nil
+}
+
+func (m *mockHTTPClient) Post(_ context.Context, req *transport.Request, _ []byte) (*http.Response, error) {
+	return m.Get(context.Background(), req)
+}
+
+type mockIPFSReader struct {
+	mutex    sync.Mutex
+	data     map[string][]byte
+	errs     map[string]error
+	requests []string
+}
+
+func newMockIPFSReader() *mockIPFSReader {
+	return &mockIPFSReader{data: make(map[string][]byte), errs: make(map[string]error)}
+}
+
+func (m *mockIPFSReader) withData(cid string, data []byte) *mockIPFSReader {
+	m.data[cid] = data
+
+	return m
+}
+
+func (m *mockIPFSReader) withError(cid string, err error) *mockIPFSReader {
+	m.errs[cid] = err
+
+	return m
+}
+
+func (m *mockIPFSReader) requested() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.requests
+}
+
+func (m *mockIPFSReader) Read(cid string) ([]byte, error) {
+	m.mutex.Lock()
+	m.requests = append(m.requests, cid)
+	m.mutex.Unlock()
+
+	if err, ok := m.errs[cid]; ok {
+		return nil, err
+	}
+
+	data, ok := m.data[cid]
+	if !ok {
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	return data, nil
+}
@@ -0,0 +1,305 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	serviceName      = "s3"
+	amzDateFormat    = "20060102T150405Z"
+	metadataPrefix   = "x-amz-meta-"
+
+	// maxErrorBodySize is the maximum number of bytes of an error response that are included in the error.
+	maxErrorBodySize = 1024
+)
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// objectStore is a minimal client for the object API of an S3-compatible service (AWS S3, MinIO, etc.).
+// Path-style addressing is used, i.e. objects are addressed as {endpoint}/{bucket}/{key}, and requests are
+// signed with AWS Signature Version 4.
+type objectStore struct {
+	httpClient      httpClient
+	endpoint        *url.URL
+	region          string
+	bucket          string
+	accessKeyID     string
+	secretAccessKey string
+	now             func() time.Time
+}
+
+// putObject stores the given content under the given key along with the given user-defined metadata.
+func (s *objectStore) putObject(key string, content []byte, metadata map[string]string) error {
+	req, err := s.newRequest(http.MethodPut, key, content)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	for name, value := range metadata {
+		req.Header.Set(metadataPrefix+name, value)
+	}
+
+	resp, err := s.send(req, content)
+	if err != nil {
+		return err
+	}
+
+	closeAndLog(resp.Body)
+
+	return nil
+}
+
+// getObject returns the content stored under the given key. ErrContentNotFound is returned if the key doesn't exist.
+func (s *objectStore) getObject(key string) ([]byte, error) {
+	req, err := s.newRequest(http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.send(req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer closeAndLog(resp.Body)
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("read object [%s]: %w", key, err))
+	}
+
+	return content, nil
+}
+
+func (s *objectStore) newRequest(method, key string, content []byte) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+
+	req, err := http.NewRequestWithContext(context.Background(), method, u.String(), bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("create request for object [%s]: %w", key, err)
+	}
+
+	return req, nil
+}
+
+func (s *objectStore) send(req *http.Request, content []byte) (*http.Response, error) {
+	s.sign(req, content)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("%s %s: %w", req.Method, req.URL, err))
+	}
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return resp, nil
+	}
+
+	defer closeAndLog(resp.Body)
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+	if err != nil {
+		logger.Debugf("Error reading response body: %s", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, orberrors.ErrContentNotFound
+	}
+
+	err = fmt.Errorf("%s %s returned status %d: %s", req.Method, req.URL, resp.StatusCode, body)
+
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+		return nil, orberrors.NewTransient(err)
+	}
+
+	return nil, err
+}
+
+// sign adds the AWS Signature Version 4 headers to the given request. All of the headers that are set on the
+// request at this point, along with the host, are signed.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func (s *objectStore) sign(req *http.Request, content []byte) {
+	amzDate := s.now().UTC().Format(amzDateFormat)
+	date := amzDate[:8]
+
+	payloadHash := sha256.Sum256(content)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", hex.EncodeToString(payloadHash[:]))
+
+	canonicalHeaders, signedHeaders := getCanonicalHeaders(req)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		getCanonicalURI(req.URL),
+		getCanonicalQuery(req.URL),
+		canonicalHeaders,
+		signedHeaders,
+		req.Header.Get("x-amz-content-sha256"),
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region, serviceName, "aws4_request"}, "/")
+
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		signingAlgorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalRequestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.secretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, s.region)
+	signingKey = hmacSHA256(signingKey, serviceName)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signingAlgorithm, s.accessKeyID, scope, signedHeaders, signature))
+}
+
+func getCanonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+
+		if name == "authorization" || name == "user-agent" {
+			continue
+		}
+
+		trimmed := make([]string, len(values))
+
+		for i, value := range values {
+			trimmed[i] = strings.Join(strings.Fields(value), " ")
+		}
+
+		headers[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	canonical := &strings.Builder{}
+
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	return canonical.String(), strings.Join(names, ";")
+}
+
+func getCanonicalURI(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+
+		segments[i] = uriEncode(unescaped)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func getCanonicalQuery(u *url.URL) string {
+	query := u.Query()
+
+	keys := make([]string, 0, len(query))
+
+	for key := range query {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	var params []string
+
+	for _, key := range keys {
+		values := query[key]
+
+		sort.Strings(values)
+
+		for _, value := range values {
+			params = append(params, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// uriEncode encodes every byte except the unreserved characters (A-Z, a-z, 0-9, '-', '.', '_' and '~'),
+// as required by Signature Version 4.
+func uriEncode(s string) string {
+	encoded := &strings.Builder{}
+
+	for _, b := range []byte(s) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') ||
+			b == '-' || b == '.' || b == '_' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(encoded, "%%%02X", b)
+		}
+	}
+
+	return encoded.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+
+	// Write on a hash never returns an error.
+	h.Write([]byte(data)) //nolint:errcheck,gosec
+
+	return h.Sum(nil)
+}
+
+func closeAndLog(rc io.Closer) {
+	if err := rc.Close(); err != nil {
+		logger.Warnf("failed to close response body: %s", err)
+	}
+}
@@ -0,0 +1,283 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package s3 implements a CAS client that stores content in an S3-compatible object store.
+package s3
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	"github.com/trustbloc/orb/pkg/cas/extendedcasclient"
+	"github.com/trustbloc/orb/pkg/cas/ipfs"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/multihash"
+)
+
+var logger = log.New("cas-s3")
+
+const (
+	defaultCacheSize = 1000
+	defaultRegion    = "us-east-1"
+	casType          = "s3"
+
+	// ipfsCIDMetadata is the user-defined object metadata that contains the CID of the content that was
+	// replicated in IPFS.
+	ipfsCIDMetadata = "ipfs-cid"
+)
+
+type metricsProvider interface {
+	CASIncrementCacheHitCount()
+	CASReadTime(casType string, value time.Duration)
+}
+
+// Config contains the settings for the S3-compatible object store.
+type Config struct {
+	// Endpoint is the URL of the object store, e.g. https://s3.us-east-1.amazonaws.com or http://localhost:9000.
+	Endpoint string
+	// Region is the region of the bucket. Defaults to us-east-1.
+	Region string
+	// Bucket is the name of the bucket in which content is stored. The bucket must already exist.
+	Bucket string
+	// KeyPrefix is an optional prefix for the object keys.
+	KeyPrefix string
+	// AccessKeyID and SecretAccessKey are the credentials used to sign requests.
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Client is a CAS client that stores content in an S3-compatible object store. The object key is the
+// multibase-encoded multihash of the content (i.e. the resource hash of the hashlink).
+type Client struct {
+	store      *objectStore
+	keyPrefix  string
+	ipfsClient *ipfs.Client
+	opts       []extendedcasclient.CIDFormatOption
+	cache      gcache.Cache
+	metrics    metricsProvider
+	casLink    string
+	hl         *hashlink.HashLink
+}
+
+// New returns a new S3 CAS client.
+// ipfsClient is optional, but if provided (not nil), then writes will go to IPFS in addition to the object store.
+// Reads are always done on only the object store.
+// hl is optional and is used to create and parse hashlinks. If nil then a default hashlink instance is used.
+// If no CID version is specified, then v1 will be used by default.
+func New(cfg *Config, httpClient httpClient, casLink string, ipfsClient *ipfs.Client, metrics metricsProvider,
+	cacheSize int, hl *hashlink.HashLink, opts ...extendedcasclient.CIDFormatOption) (*Client, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("S3 endpoint is required")
+	}
+
+	if cfg.Bucket == "" {
+		return nil, errors.New("S3 bucket is required")
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint [%s]: %w", cfg.Endpoint, err)
+	}
+
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint [%s]: scheme and host are required", cfg.Endpoint)
+	}
+
+	if err := validateOptions(opts); err != nil {
+		return nil, err
+	}
+
+	region := cfg.Region
+	if region == "" {
+		region = defaultRegion
+	}
+
+	if cacheSize == 0 {
+		cacheSize = defaultCacheSize
+	}
+
+	if hl == nil {
+		hl = hashlink.New()
+	}
+
+	c := &Client{
+		store: &objectStore{
+			httpClient:      httpClient,
+			endpoint:        endpoint,
+			region:          region,
+			bucket:          cfg.Bucket,
+			accessKeyID:     cfg.AccessKeyID,
+			secretAccessKey: cfg.SecretAccessKey,
+			now:             time.Now,
+		},
+		keyPrefix:  cfg.KeyPrefix,
+		ipfsClient: ipfsClient,
+		opts:       opts,
+		metrics:    metrics,
+		casLink:    casLink,
+		hl:         hl,
+	}
+
+	c.cache = gcache.New(cacheSize).ARC().
+		LoaderFunc(func(key interface{}) (interface{}, error) {
+			content, err := c.get(key.(string))
+			if err != nil {
+				return nil, err
+			}
+
+			logger.Debugf("Cached content for resource hash [%s]", key)
+
+			return content, nil
+		},
+		).Build()
+
+	return c, nil
+}
+
+// Write writes the given content to the object store (and IPFS if configured) using this client's
+// default CID version.
+// Returns the hashlink of the content.
+func (c *Client) Write(content []byte) (string, error) {
+	return c.WriteWithCIDFormat(content, c.opts...)
+}
+
+// WriteWithCIDFormat writes the given content to the object store (and IPFS if configured). The CID format
+// specified by opts determines the version of the CID that's produced by IPFS (0 or 1). The object key is
+// the resource hash, which is the same regardless of the CID version.
+// Returns the hashlink of the content.
+func (c *Client) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFormatOption) (string, error) {
+	if len(content) == 0 {
+		return "", errors.New("empty content")
+	}
+
+	if err := validateOptions(opts); err != nil {
+		return "", err
+	}
+
+	resourceHash, err := c.hl.CreateResourceHash(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
+	}
+
+	logger.Debugf("Writing to S3 [%s]: %d bytes", resourceHash, len(content))
+
+	metadata := make(map[string]string)
+
+	// add cas link
+	links := []string{c.casLink + "/" + resourceHash}
+
+	if c.ipfsClient != nil {
+		cid, writeErr := c.ipfsClient.WriteWithCIDFormat(content, opts...)
+		if writeErr != nil {
+			return "", orberrors.NewTransient(fmt.Errorf("failed to put content into IPFS: %w", writeErr))
+		}
+
+		metadata[ipfsCIDMetadata] = cid
+
+		// add ipfs link
+		links = append(links, "ipfs://"+cid)
+	}
+
+	if err := c.store.putObject(c.keyPrefix+resourceHash, content, metadata); err != nil {
+		return "", fmt.Errorf("failed to put content into S3: %w", err)
+	}
+
+	if err := c.cache.Set(resourceHash, content); err != nil {
+		// This shouldn't be possible.
+		logger.Warnf("Error caching content for resource hash[%s]: %s", resourceHash, err)
+	}
+
+	metadataLinks, err := c.hl.CreateMetadata(content, links)
+	if err != nil {
+		return "", fmt.Errorf("failed to create metadata from links: %w", err)
+	}
+
+	return hashlink.GetHashLink(resourceHash, metadataLinks), nil
+}
+
+// GetPrimaryWriterType returns primary writer type.
+func (c *Client) GetPrimaryWriterType() string {
+	return casType
+}
+
+// Read reads the content of the given address from the object store. The address may be a resource hash,
+// a hashlink or a CID (v0 or v1).
+func (c *Client) Read(address string) ([]byte, error) {
+	resourceHash, err := c.toResourceHash(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address [%s]: %w", address, err)
+	}
+
+	if c.cache.Has(resourceHash) {
+		c.metrics.CASIncrementCacheHitCount()
+	}
+
+	content, err := c.cache.Get(resourceHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return content.([]byte), nil
+}
+
+func (c *Client) get(resourceHash string) ([]byte, error) {
+	startTime := time.Now()
+
+	defer func() {
+		c.metrics.CASReadTime(casType, time.Since(startTime))
+	}()
+
+	content, err := c.store.getObject(c.keyPrefix + resourceHash)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			return nil, orberrors.ErrContentNotFound
+		}
+
+		return nil, fmt.Errorf("failed to get content from S3: %w", err)
+	}
+
+	return content, nil
+}
+
+func (c *Client) toResourceHash(address string) (string, error) {
+	if strings.HasPrefix(address, hashlink.HLPrefix) {
+		info, err := c.hl.ParseHashLink(address)
+		if err != nil {
+			return "", err
+		}
+
+		return info.ResourceHash, nil
+	}
+
+	if multihash.IsValidCID(address) {
+		return multihash.CIDToMultihash(address)
+	}
+
+	return address, nil
+}
+
+func validateOptions(opts []extendedcasclient.CIDFormatOption) error {
+	options := &extendedcasclient.CIDFormatOptions{CIDVersion: 1}
+
+	for _, option := range opts {
+		if option != nil {
+			option(options)
+		}
+	}
+
+	if options.CIDVersion != 0 && options.CIDVersion != 1 {
+		return fmt.Errorf("%d is not a supported CID version. It must be either 0 or 1", options.CIDVersion)
+	}
+
+	return nil
+}
@@ -60,6 +60,7 @@ type Queue struct {
 	msgChan       <-chan *message.Message
 	mutex         sync.RWMutex
 	pending       []*operationMessage
+	queued        operationSet
 	jsonMarshal   func(interface{}) ([]byte, error)
 	jsonUnmarshal func(data []byte, v interface{}) error
 	metrics       metricsProvider
@@ -75,6 +76,7 @@ func New(cfg Config, pubSub pubSub, metrics metricsProvider) (*Queue, error) {
 	q := &Queue{
 		pubSub:        pubSub,
 		msgChan:       msgChan,
+		queued:        make(operationSet),
 		jsonMarshal:   json.Marshal,
 		jsonUnmarshal: json.Unmarshal,
 		metrics:       metrics,
@@ -175,6 +177,10 @@ func (q *Queue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func
 	items := q.pending[0:n]
 	q.pending = q.pending[n:]
 
+	for _, item := range items {
+		q.queued.remove(&item.op.QueuedOperation)
+	}
+
 	return asQueuedOperations(items), q.newAckFunc(items, startTime), q.newNackFunc(items, startTime), nil
 }
 
@@ -190,6 +196,15 @@ func (q *Queue) Len() uint {
 	return uint(len(q.pending))
 }
 
+// Contains returns true if an operation for the given suffix with the given operation buffer is in the
+// pending queue.
+func (q *Queue) Contains(suffix string, opBuffer []byte) bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.queued.contains(suffix, opBuffer)
+}
+
 func (q *Queue) start() {
 	go q.listen()
 
@@ -257,6 +272,8 @@ func (q *Queue) handleMessage(msg *message.Message) {
 		op:        op,
 		timeAdded: time.Now(),
 	})
+
+	q.queued.add(&op.QueuedOperation)
 }
 
 func (q *Queue) newAckFunc(items []*operationMessage, startTime time.Time) func() uint {
@@ -91,6 +91,10 @@ func TestQueue(t *testing.T) {
 	require.NoError(t, err)
 	require.NotEmpty(t, ops1)
 
+	for _, op := range ops1 {
+		require.True(t, q1.Contains(op.UniqueSuffix, op.OperationBuffer))
+	}
+
 	ops2, err = q2.Peek(10)
 	require.NoError(t, err)
 	require.NotEmpty(t, ops2)
@@ -102,6 +106,7 @@ func TestQueue(t *testing.T) {
 	require.True(t, pending > 0)
 	require.True(t, q1.Len() > 0)
 	require.Len(t, removedOps1, 2)
+	require.False(t, q1.Contains(removedOps1[0].UniqueSuffix, removedOps1[0].OperationBuffer))
 
 	operations.setProcessed(t, removedOps1)
 
@@ -0,0 +1,47 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"crypto/sha256"
+
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+type operationKey struct {
+	suffix string
+	hash   [sha256.Size]byte
+}
+
+// operationSet holds the hashes of the operations in a queue so that a queued operation may be found without
+// scanning the queue. The number of occurrences of each operation is held since the same operation may be
+// added more than once.
+type operationSet map[operationKey]int
+
+func newOperationKey(suffix string, opBuffer []byte) operationKey {
+	return operationKey{suffix: suffix, hash: sha256.Sum256(opBuffer)}
+}
+
+func (s operationSet) add(op *operation.QueuedOperation) {
+	s[newOperationKey(op.UniqueSuffix, op.OperationBuffer)]++
+}
+
+func (s operationSet) remove(op *operation.QueuedOperation) {
+	key := newOperationKey(op.UniqueSuffix, op.OperationBuffer)
+
+	if s[key] <= 1 {
+		delete(s, key)
+
+		return
+	}
+
+	s[key]--
+}
+
+func (s operationSet) contains(suffix string, opBuffer []byte) bool {
+	return s[newOperationKey(suffix, opBuffer)] > 0
+}
@@ -0,0 +1,39 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+)
+
+func TestOperationSet(t *testing.T) {
+	op1 := &operation.QueuedOperation{UniqueSuffix: "suffix1", OperationBuffer: []byte("op1")}
+	op2 := &operation.QueuedOperation{UniqueSuffix: "suffix2", OperationBuffer: []byte("op1")}
+
+	s := make(operationSet)
+	require.False(t, s.contains(op1.UniqueSuffix, op1.OperationBuffer))
+
+	s.add(op1)
+	require.True(t, s.contains(op1.UniqueSuffix, op1.OperationBuffer))
+	require.False(t, s.contains(op2.UniqueSuffix, op2.OperationBuffer))
+
+	// The same operation may be added more than once.
+	s.add(op1)
+	s.remove(op1)
+	require.True(t, s.contains(op1.UniqueSuffix, op1.OperationBuffer))
+
+	s.remove(op1)
+	require.False(t, s.contains(op1.UniqueSuffix, op1.OperationBuffer))
+	require.Empty(t, s)
+
+	// Removing an operation that's not in the set has no effect.
+	s.remove(op2)
+	require.Empty(t, s)
+}
@@ -0,0 +1,447 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ThreeDotsLabs/watermill"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+)
+
+const (
+	storeNamePrefix = "operation-queue"
+	statusTag       = "status"
+)
+
+var invalidStoreNameChars = regexp.MustCompile(`[^a-z0-9_-]`) //nolint:gochecknoglobals
+
+type status string
+
+const (
+	// statusPending indicates that the operation is in the queue.
+	statusPending status = "pending"
+
+	// statusRemoved indicates that the operation was removed from the queue (i.e. it's part of a batch that's
+	// being processed) but the batch has not yet been acknowledged.
+	statusRemoved status = "removed"
+)
+
+// storedOperation is the persisted form of a queued operation.
+type storedOperation struct {
+	Key       string                           `json:"key"`
+	Operation *operation.QueuedOperationAtTime `json:"operation"`
+	TimeAdded time.Time                        `json:"timeAdded"`
+}
+
+// StoreQueue implements an operation queue that persists operations to a database so that operations which
+// were added but not yet processed survive a restart. Unlike Queue, StoreQueue does not depend on a durable
+// message queue and is therefore suitable when the in-memory publisher/subscriber is used.
+//
+// Each operation is saved on Add. On Remove, the removed operations are marked as such (in one batch) and
+// are deleted when the batch is acknowledged or marked as pending again when the batch is rolled back.
+// On startup, all stored operations (both pending and removed but unacknowledged) are restored to the queue
+// in the order in which they were added.
+//
+// Each server instance uses its own store (named after the instance ID) since an instance restores all of the
+// operations in its store. The instance ID must therefore be unique within a cluster and must remain the same
+// across restarts.
+type StoreQueue struct {
+	*lifecycle.Lifecycle
+
+	store     storage.Store
+	mutex     sync.RWMutex
+	pending   []*storedOperation
+	queued    operationSet
+	marshal   func(interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+	metrics   metricsProvider
+}
+
+// NewStoreQueue returns a new persistent operation queue for the given server instance. Any operations that
+// were left in the instance's store (from a previous run) are restored to the queue.
+func NewStoreQueue(provider storage.Provider, instanceID string, metrics metricsProvider) (*StoreQueue, error) {
+	if instanceID == "" {
+		return nil, errors.New("instance ID is required")
+	}
+
+	storeName := getStoreName(instanceID)
+
+	logger.Debugf("Using store [%s] for operation queue of instance [%s]", storeName, instanceID)
+
+	store, err := provider.OpenStore(storeName)
+	if err != nil {
+		return nil, fmt.Errorf("open store [%s]: %w", storeName, err)
+	}
+
+	err = provider.SetStoreConfig(storeName, storage.StoreConfiguration{TagNames: []string{statusTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	q := &StoreQueue{
+		store:     store,
+		queued:    make(operationSet),
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+		metrics:   metrics,
+	}
+
+	if err := q.restore(); err != nil {
+		return nil, fmt.Errorf("restore operations: %w", err)
+	}
+
+	q.Lifecycle = lifecycle.New("operation-store-queue",
+		lifecycle.WithStart(q.start),
+		lifecycle.WithStop(q.stop),
+	)
+
+	q.Start()
+
+	return q, nil
+}
+
+// Add persists the given operation and adds it to the tail of the queue.
+// Returns the new length of the queue.
+func (q *StoreQueue) Add(op *operation.QueuedOperation, protocolGenesisTime uint64) (uint, error) {
+	if q.State() != lifecycle.StateStarted {
+		return 0, lifecycle.ErrNotStarted
+	}
+
+	startTime := time.Now()
+
+	defer func() {
+		q.metrics.AddOperationTime(time.Since(startTime))
+	}()
+
+	opEntry := &storedOperation{
+		// The key is prefixed with the (zero-padded) time so that the order of the operations
+		// may be restored by sorting on the key.
+		Key: fmt.Sprintf("%020d_%s", startTime.UnixNano(), watermill.NewUUID()),
+		Operation: &operation.QueuedOperationAtTime{
+			QueuedOperation:     *op,
+			ProtocolGenesisTime: protocolGenesisTime,
+		},
+		TimeAdded: startTime,
+	}
+
+	if err := q.put(opEntry, statusPending); err != nil {
+		return 0, err
+	}
+
+	logger.Debugf("Stored operation [%s] - DID [%s]", opEntry.Key, op.UniqueSuffix)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.pending = append(q.pending, opEntry)
+	q.queued.add(&opEntry.Operation.QueuedOperation)
+
+	return uint(len(q.pending)), nil
+}
+
+// Peek returns (up to) the given number of operations from the head of the queue but does not remove them.
+func (q *StoreQueue) Peek(num uint) (operation.QueuedOperationsAtTime, error) {
+	if q.State() != lifecycle.StateStarted {
+		return nil, lifecycle.ErrNotStarted
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	n := int(num)
+	if len(q.pending) < n {
+		n = len(q.pending)
+	}
+
+	return asOperations(q.pending[0:n]), nil
+}
+
+// Remove removes (up to) the given number of items from the head of the queue and marks them as removed
+// in the store. When the returned ack function is invoked, the operations are deleted from the store. When
+// the returned nack function is invoked, the operations are added back to the head of the queue. If the
+// server goes down before either function is invoked then the operations are restored on startup.
+func (q *StoreQueue) Remove(num uint) (ops operation.QueuedOperationsAtTime, ack func() uint, nack func(), err error) {
+	if q.State() != lifecycle.StateStarted {
+		return nil, nil, nil, lifecycle.ErrNotStarted
+	}
+
+	startTime := time.Now()
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	n := int(num)
+	if len(q.pending) < n {
+		n = len(q.pending)
+	}
+
+	if n == 0 {
+		return nil,
+			func() uint { return 0 },
+			func() {}, nil
+	}
+
+	items := q.pending[0:n]
+
+	if err := q.updateStatus(items, statusRemoved); err != nil {
+		return nil, nil, nil, err
+	}
+
+	q.pending = q.pending[n:]
+
+	for _, item := range items {
+		q.queued.remove(&item.Operation.QueuedOperation)
+	}
+
+	return asOperations(items), q.newAckFunc(items, startTime), q.newNackFunc(items, startTime), nil
+}
+
+// Len returns the length of the pending queue.
+func (q *StoreQueue) Len() uint {
+	if q.State() != lifecycle.StateStarted {
+		return 0
+	}
+
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return uint(len(q.pending))
+}
+
+// Contains returns true if an operation for the given suffix with the given operation buffer is in the
+// pending queue.
+func (q *StoreQueue) Contains(suffix string, opBuffer []byte) bool {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	return q.queued.contains(suffix, opBuffer)
+}
+
+func (q *StoreQueue) start() {
+	logger.Infof("Started persistent operation queue with %d pending operations", q.Len())
+}
+
+func (q *StoreQueue) stop() {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+
+	// Nothing to do since the pending operations are persisted and will be restored on startup.
+	logger.Infof("Stopped persistent operation queue with %d pending operations", len(q.pending))
+}
+
+func (q *StoreQueue) newAckFunc(items []*storedOperation, startTime time.Time) func() uint {
+	return func() uint {
+		logger.Infof("Acking %d operations...", len(items))
+
+		batch := make([]storage.Operation, len(items))
+
+		for i, item := range items {
+			batch[i] = storage.Operation{Key: item.Key}
+		}
+
+		if err := q.store.Batch(batch); err != nil {
+			// The operations will be restored (and processed again) on startup.
+			logger.Errorf("Error deleting %d acknowledged operations from the store: %s", len(items), err)
+		}
+
+		// Batch cut time is the time since the first operation was added (which is the oldest operation in the batch).
+		q.metrics.BatchCutTime(time.Since(items[0].TimeAdded))
+
+		// Batch Ack time is the time it took to delete all of the operations.
+		q.metrics.BatchAckTime(time.Since(startTime))
+
+		q.metrics.BatchSize(float64(len(items)))
+
+		q.mutex.RLock()
+		defer q.mutex.RUnlock()
+
+		return uint(len(q.pending))
+	}
+}
+
+func (q *StoreQueue) newNackFunc(items []*storedOperation, startTime time.Time) func() {
+	return func() {
+		logger.Infof("Nacking %d operations...", len(items))
+
+		q.mutex.Lock()
+
+		// Add the operations back to the head of the queue so that they're retried in their original order.
+		pending := make([]*storedOperation, 0, len(items)+len(q.pending))
+		pending = append(pending, items...)
+		q.pending = append(pending, q.pending...)
+
+		for _, item := range items {
+			q.queued.add(&item.Operation.QueuedOperation)
+		}
+
+		q.mutex.Unlock()
+
+		if err := q.updateStatus(items, statusPending); err != nil {
+			// The operations are in the in-memory queue and, if the server restarts, operations with the
+			// 'removed' status are also restored.
+			logger.Warnf("Error marking %d operations as pending: %s", len(items), err)
+		}
+
+		// Batch rollback time is the time since the first operation was added (which is the oldest operation in the batch).
+		q.metrics.BatchRollbackTime(time.Since(items[0].TimeAdded))
+
+		// Batch Nack time is the time it took to roll back all of the operations.
+		q.metrics.BatchNackTime(time.Since(startTime))
+	}
+}
+
+func (q *StoreQueue) put(opEntry *storedOperation, s status) error {
+	opBytes, err := q.marshal(opEntry)
+	if err != nil {
+		return fmt.Errorf("marshal operation: %w", err)
+	}
+
+	err = q.store.Put(opEntry.Key, opBytes, storage.Tag{Name: statusTag, Value: string(s)})
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store operation [%s]: %w", opEntry.Key, err))
+	}
+
+	return nil
+}
+
+// updateStatus sets the status of all of the given operations in one batch.
+func (q *StoreQueue) updateStatus(items []*storedOperation, s status) error {
+	batch := make([]storage.Operation, len(items))
+
+	for i, item := range items {
+		opBytes, err := q.marshal(item)
+		if err != nil {
+			return fmt.Errorf("marshal operation: %w", err)
+		}
+
+		batch[i] = storage.Operation{
+			Key:   item.Key,
+			Value: opBytes,
+			Tags:  []storage.Tag{{Name: statusTag, Value: string(s)}},
+		}
+	}
+
+	if err := q.store.Batch(batch); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("update status of %d operations to [%s]: %w", len(items), s, err))
+	}
+
+	return nil
+}
+
+// restore loads all of the operations from the store into the queue. Operations that were removed but never
+// acknowledged (because the server went down while the batch was being processed) are marked as pending.
+func (q *StoreQueue) restore() error {
+	it, err := q.store.Query(statusTag)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("query operations: %w", err))
+	}
+
+	defer func() {
+		if e := it.Close(); e != nil {
+			logger.Errorf("failed to close iterator: %s", e.Error())
+		}
+	}()
+
+	var (
+		items   []*storedOperation
+		removed []*storedOperation
+	)
+
+	ok, err := it.Next()
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+	}
+
+	for ok {
+		value, e := it.Value()
+		if e != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get iterator value: %w", e))
+		}
+
+		tags, e := it.Tags()
+		if e != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get iterator tags: %w", e))
+		}
+
+		opEntry := &storedOperation{}
+
+		if e := q.unmarshal(value, opEntry); e != nil {
+			return fmt.Errorf("unmarshal operation: %w", e)
+		}
+
+		items = append(items, opEntry)
+
+		if hasStatus(tags, statusRemoved) {
+			removed = append(removed, opEntry)
+		}
+
+		ok, err = it.Next()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+	}
+
+	if len(removed) > 0 {
+		logger.Infof("Marking %d unacknowledged operations as pending", len(removed))
+
+		if err := q.updateStatus(removed, statusPending); err != nil {
+			return err
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Key < items[j].Key
+	})
+
+	logger.Infof("Restored %d operations from the store", len(items))
+
+	q.pending = items
+
+	for _, item := range items {
+		q.queued.add(&item.Operation.QueuedOperation)
+	}
+
+	return nil
+}
+
+func hasStatus(tags []storage.Tag, s status) bool {
+	for _, tag := range tags {
+		if tag.Name == statusTag && tag.Value == string(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func asOperations(items []*storedOperation) []*operation.QueuedOperationAtTime {
+	ops := make([]*operation.QueuedOperationAtTime, len(items))
+
+	for i, item := range items {
+		ops[i] = item.Operation
+	}
+
+	return ops
+}
+
+// getStoreName returns the name of the store for the given instance ID. The instance ID is converted to
+// a name that's valid for all of the supported databases.
+func getStoreName(instanceID string) string {
+	return storeNamePrefix + "-" + invalidStoreNameChars.ReplaceAllString(strings.ToLower(instanceID), "_")
+}
@@ -0,0 +1,390 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package opqueue
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	mockstore "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/lifecycle"
+	"github.com/trustbloc/orb/pkg/mocks"
+)
+
+const (
+	instanceID1 = "orb1.domain1.com"
+	instanceID2 = "orb2.domain1.com"
+)
+
+func TestStoreQueue(t *testing.T) {
+	operations := newProcessedOperations(10)
+
+	provider := mem.NewProvider()
+
+	q, err := NewStoreQueue(provider, instanceID1, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+	require.NotNil(t, q)
+
+	require.Zero(t, q.Len())
+
+	ops, err := q.Peek(2)
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	removedOps, ack, nack, err := q.Remove(2)
+	require.NoError(t, err)
+	require.Empty(t, removedOps)
+	require.Equal(t, uint(0), ack())
+	require.NotPanics(t, nack)
+
+	for i := 0; i < len(operations); i++ {
+		n, e := q.Add(operations[opSuffix(i)].op, 100)
+		require.NoError(t, e)
+		require.Equal(t, uint(i+1), n)
+	}
+
+	ops, err = q.Peek(3)
+	require.NoError(t, err)
+	requireSuffixes(t, ops, 0, 1, 2)
+	require.Equal(t, uint64(100), ops[0].ProtocolGenesisTime)
+
+	op0 := operations[opSuffix(0)].op
+	require.True(t, q.Contains(op0.UniqueSuffix, op0.OperationBuffer))
+	require.False(t, q.Contains(op0.UniqueSuffix, []byte("other")))
+
+	removedOps, ack, _, err = q.Remove(2)
+	require.NoError(t, err)
+	requireSuffixes(t, removedOps, 0, 1)
+	require.Equal(t, uint(8), ack())
+	require.False(t, q.Contains(op0.UniqueSuffix, op0.OperationBuffer))
+
+	operations.setProcessed(t, removedOps)
+
+	removedOps, _, nack, err = q.Remove(2)
+	require.NoError(t, err)
+	requireSuffixes(t, removedOps, 2, 3)
+	require.Equal(t, uint(6), q.Len())
+
+	op2 := operations[opSuffix(2)].op
+	require.False(t, q.Contains(op2.UniqueSuffix, op2.OperationBuffer))
+
+	nack()
+
+	// The nacked operations should be back at the head of the queue.
+	require.Equal(t, uint(8), q.Len())
+	require.True(t, q.Contains(op2.UniqueSuffix, op2.OperationBuffer))
+
+	ops, err = q.Peek(3)
+	require.NoError(t, err)
+	requireSuffixes(t, ops, 2, 3, 4)
+
+	// Remove some operations without acknowledging them to simulate a crash while processing a batch.
+	removedOps, _, _, err = q.Remove(3)
+	require.NoError(t, err)
+	requireSuffixes(t, removedOps, 2, 3, 4)
+
+	q.Stop()
+
+	// The new queue should restore all operations that weren't acknowledged, in the original order.
+	q2, err := NewStoreQueue(provider, instanceID1, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	defer q2.Stop()
+
+	require.Equal(t, uint(8), q2.Len())
+	require.True(t, q2.Contains(op2.UniqueSuffix, op2.OperationBuffer))
+
+	removedOps, ack, _, err = q2.Remove(10)
+	require.NoError(t, err)
+	requireSuffixes(t, removedOps, 2, 3, 4, 5, 6, 7, 8, 9)
+	require.Equal(t, uint(0), ack())
+
+	operations.setProcessed(t, removedOps)
+
+	for _, op := range operations {
+		require.Truef(t, op.processed, "operation %s was not processed", op.op.UniqueSuffix)
+	}
+
+	// Nothing should be restored since all operations were acknowledged.
+	q3, err := NewStoreQueue(provider, instanceID1, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	defer q3.Stop()
+
+	require.Zero(t, q3.Len())
+}
+
+func TestStoreQueue_MultipleInstances(t *testing.T) {
+	operations := newProcessedOperations(4)
+
+	provider := mem.NewProvider()
+
+	q1, err := NewStoreQueue(provider, instanceID1, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	q2, err := NewStoreQueue(provider, instanceID2, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	defer q2.Stop()
+
+	for i := 0; i < 2; i++ {
+		_, err = q1.Add(operations[opSuffix(i)].op, 100)
+		require.NoError(t, err)
+	}
+
+	for i := 2; i < 4; i++ {
+		_, err = q2.Add(operations[opSuffix(i)].op, 100)
+		require.NoError(t, err)
+	}
+
+	q1.Stop()
+
+	// Only the operations that were added by the first instance should be restored.
+	q1, err = NewStoreQueue(provider, instanceID1, &mocks.MetricsProvider{})
+	require.NoError(t, err)
+
+	defer q1.Stop()
+
+	ops, err := q1.Peek(10)
+	require.NoError(t, err)
+	requireSuffixes(t, ops, 0, 1)
+
+	ops, err = q2.Peek(10)
+	require.NoError(t, err)
+	requireSuffixes(t, ops, 2, 3)
+}
+
+func TestStoreQueue_Error(t *testing.T) {
+	op1 := &operation.QueuedOperation{UniqueSuffix: "op1"}
+
+	t.Run("Not started error", func(t *testing.T) {
+		q, err := NewStoreQueue(mem.NewProvider(), instanceID1, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+		require.NotNil(t, q)
+
+		q.Stop()
+
+		_, err = q.Add(op1, 100)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		_, err = q.Peek(1)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		_, _, _, err = q.Remove(1)
+		require.True(t, errors.Is(err, lifecycle.ErrNotStarted))
+
+		require.Equal(t, uint(0), q.Len())
+	})
+
+	t.Run("No instance ID error", func(t *testing.T) {
+		_, err := NewStoreQueue(mem.NewProvider(), "", &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "instance ID is required")
+	})
+
+	t.Run("Open store error", func(t *testing.T) {
+		errExpected := errors.New("injected open store error")
+
+		_, err := NewStoreQueue(&mockstore.Provider{ErrOpenStore: errExpected}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Set store config error", func(t *testing.T) {
+		errExpected := errors.New("injected set store config error")
+
+		_, err := NewStoreQueue(&mockstore.Provider{ErrSetStoreConfig: errExpected}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		errExpected := errors.New("injected query error")
+
+		_, err := NewStoreQueue(&mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{ErrQuery: errExpected},
+		}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Iterator error", func(t *testing.T) {
+		errExpected := errors.New("injected iterator error")
+
+		_, err := NewStoreQueue(&mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{QueryReturn: &mockstore.Iterator{ErrNext: errExpected}},
+		}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		_, err = NewStoreQueue(&mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{QueryReturn: &mockstore.Iterator{
+				NextReturn: true,
+				ErrValue:   errExpected,
+			}},
+		}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+
+		_, err = NewStoreQueue(&mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{QueryReturn: &mockstore.Iterator{
+				NextReturn:  true,
+				ValueReturn: []byte("{}"),
+				ErrTags:     errExpected,
+			}},
+		}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Unmarshal error", func(t *testing.T) {
+		_, err := NewStoreQueue(&mockstore.Provider{
+			OpenStoreReturn: &mockstore.Store{QueryReturn: &mockstore.Iterator{
+				NextReturn:  true,
+				ValueReturn: []byte("invalid"),
+			}},
+		}, instanceID1, &mocks.MetricsProvider{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal operation")
+	})
+
+	t.Run("Put error", func(t *testing.T) {
+		errExpected := errors.New("injected put error")
+
+		s := &failingStore{Store: newMemStore(t), errPut: errExpected}
+
+		q, err := NewStoreQueue(&mockstore.Provider{OpenStoreReturn: s}, instanceID1, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		defer q.Stop()
+
+		_, err = q.Add(op1, 100)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Zero(t, q.Len())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		q, err := NewStoreQueue(mem.NewProvider(), instanceID1, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		defer q.Stop()
+
+		errExpected := errors.New("injected marshal error")
+
+		q.marshal = func(interface{}) ([]byte, error) {
+			return nil, errExpected
+		}
+
+		_, err = q.Add(op1, 100)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), errExpected.Error())
+	})
+
+	t.Run("Batch error", func(t *testing.T) {
+		errExpected := errors.New("injected batch error")
+
+		s := &failingStore{Store: newMemStore(t)}
+
+		q, err := NewStoreQueue(&mockstore.Provider{OpenStoreReturn: s}, instanceID1, &mocks.MetricsProvider{})
+		require.NoError(t, err)
+
+		defer q.Stop()
+
+		_, err = q.Add(op1, 100)
+		require.NoError(t, err)
+
+		s.errBatch = errExpected
+
+		// The operation should remain in the queue if it can't be marked as removed.
+		_, _, _, err = q.Remove(1)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), errExpected.Error())
+		require.Equal(t, uint(1), q.Len())
+
+		s.errBatch = nil
+
+		_, _, nack, err := q.Remove(1)
+		require.NoError(t, err)
+
+		s.errBatch = errExpected
+
+		// Errors in ack and nack are logged.
+		require.NotPanics(t, nack)
+		require.Equal(t, uint(1), q.Len())
+
+		s.errBatch = nil
+
+		_, ack, _, err := q.Remove(1)
+		require.NoError(t, err)
+
+		s.errBatch = errExpected
+
+		require.Equal(t, uint(0), ack())
+	})
+}
+
+func opSuffix(i int) string {
+	return fmt.Sprintf("op%d", i)
+}
+
+func requireSuffixes(t *testing.T, ops operation.QueuedOperationsAtTime, indexes ...int) {
+	t.Helper()
+
+	require.Len(t, ops, len(indexes))
+
+	for i, index := range indexes {
+		require.Equal(t, opSuffix(index), ops[i].UniqueSuffix)
+	}
+}
+
+func TestGetStoreName(t *testing.T) {
+	require.Equal(t, "operation-queue-orb1_domain1_com", getStoreName(instanceID1))
+	require.Equal(t, "operation-queue-orb-1_8080", getStoreName("Orb-1:8080"))
+}
+
+func newMemStore(t *testing.T) storage.Store {
+	t.Helper()
+
+	s, err := mem.NewProvider().OpenStore(getStoreName(instanceID1))
+	require.NoError(t, err)
+
+	return s
+}
+
+type failingStore struct {
+	storage.Store
+
+	errPut   error
+	errBatch error
+}
+
+func (s *failingStore) Put(key string, value []byte, tags ...storage.Tag) error {
+	if s.errPut != nil {
+		return s.errPut
+	}
+
+	return s.Store.Put(key, value, tags...)
+}
+
+func (s *failingStore) Batch(operations []storage.Operation) error {
+	if s.errBatch != nil {
+		return s.errBatch
+	}
+
+	return s.Store.Batch(operations)
+}
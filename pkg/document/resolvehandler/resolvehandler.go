@@ -81,6 +81,13 @@ func WithCreateDocumentStore(store storage.Store) Option {
 	}
 }
 
+// WithHashLink sets the hashlink instance that's used to parse the hashlinks in DIDs.
+func WithHashLink(hl *hashlink.HashLink) Option {
+	return func(opts *ResolveHandler) {
+		opts.hl = hl
+	}
+}
+
 // NewResolveHandler returns a new document resolve handler.
 func NewResolveHandler(namespace string, resolver dochandler.Resolver, discovery discovery,
 	anchorGraph common.AnchorGraph, metrics metricsProvider, opts ...Option) *ResolveHandler {
@@ -19,12 +19,14 @@ import (
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/sidetree-core-go/pkg/document"
 
 	"github.com/trustbloc/orb/pkg/anchor/graph"
 	"github.com/trustbloc/orb/pkg/document/resolvehandler/mocks"
 	"github.com/trustbloc/orb/pkg/document/util"
+	"github.com/trustbloc/orb/pkg/hashlink"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 	storemocks "github.com/trustbloc/orb/pkg/store/mocks"
 )
@@ -535,6 +537,30 @@ func TestResolveHandler_Resolve(t *testing.T) {
 	})
 }
 
+func TestResolveHandler_WithHashLink(t *testing.T) {
+	hl := hashlink.New(hashlink.WithMultihashCode(multihash.SHA3_256))
+
+	rh, err := hl.CreateResourceHash([]byte("content"))
+	require.NoError(t, err)
+
+	id := testNS + ":" + hashlink.GetHashLinkFromResourceHash(rh) + ":suffix"
+
+	handler := NewResolveHandler(testNS, &mocks.Resolver{}, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+		&orbmocks.MetricsProvider{})
+
+	_, _, err = handler.getCIDAndSuffix(id)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "is not a valid multihash")
+
+	handler = NewResolveHandler(testNS, &mocks.Resolver{}, &mocks.Discovery{}, &orbmocks.AnchorGraph{},
+		&orbmocks.MetricsProvider{}, WithHashLink(hl))
+
+	cid, suffix, err := handler.getCIDAndSuffix(id)
+	require.NoError(t, err)
+	require.Equal(t, rh, cid)
+	require.Equal(t, "suffix", suffix)
+}
+
 func TestResolveHandler_VerifyCID(t *testing.T) {
 	t.Run("success - CID in DID matches resolved document CID", func(t *testing.T) {
 		anchorGraph := &orbmocks.AnchorGraph{}
@@ -0,0 +1,321 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package ratelimit implements an HTTP handler wrapper for the Sidetree operations endpoint which limits the
+// rate at which operations are accepted (per DID and per client) and rejects operations that are already queued.
+package ratelimit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/protocol"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+var logger = log.New("update-rate-limit")
+
+const (
+	defaultInterval  = time.Minute
+	defaultCacheSize = 10000
+
+	// maxRequestSize is the maximum size of an operation request that's read by the rate limiter. It's well
+	// above the maximum operation size of any protocol version.
+	maxRequestSize = 1024 * 1024
+
+	// Reasons for rejecting an operation (used in metrics).
+	reasonDIDRateLimit    = "did_rate_limit"
+	reasonClientRateLimit = "client_rate_limit"
+	reasonDuplicate       = "duplicate"
+)
+
+var (
+	errDIDRateLimit    = errors.New("too many operations for DID")
+	errClientRateLimit = errors.New("too many operations from client")
+	errDuplicate       = errors.New("operation is already queued")
+	errRequestTooLarge = fmt.Errorf("request exceeds %d bytes", maxRequestSize)
+)
+
+// Config contains the rate limiter configuration.
+type Config struct {
+	// MaxOperationsPerDID is the maximum number of operations that are accepted for a DID (suffix) within
+	// the interval. If 0 then operations are not limited per DID.
+	MaxOperationsPerDID int
+	// MaxOperationsPerClient is the maximum number of operations that are accepted from a client within the
+	// interval. A client is identified by its bearer token if the token was verified (by the auth handler
+	// wrapper) or otherwise by its IP address.
+	// If 0 then operations are not limited per client.
+	MaxOperationsPerClient int
+	// Interval is the rate limiting window. Defaults to one minute.
+	Interval time.Duration
+	// ClientIPHeader is an optional header (e.g. X-Forwarded-For) from which the client IP address is taken
+	// when the server is behind a proxy. If not set then the remote address of the request is used.
+	// This must only be set if the server is behind a trusted proxy that appends the address of its peer to
+	// the header since a client may otherwise set the header to an arbitrary address.
+	ClientIPHeader string
+	// ClientIPHeaderHops is the position, counted from the right, of the entry in ClientIPHeader that holds the
+	// client IP address, i.e. the number of trusted proxies in front of the server. Entries further to the left
+	// are ignored since they may be set by the client. Defaults to 1 (the rightmost entry).
+	ClientIPHeaderHops int
+	// CacheSize is the maximum number of DIDs (and clients) that are tracked. Defaults to 10000.
+	CacheSize int
+}
+
+type operationQueue interface {
+	Contains(suffix string, opBuffer []byte) bool
+}
+
+type metricsProvider interface {
+	DocumentUpdateRejected(reason string)
+}
+
+// HandlerWrapper wraps the Sidetree operations handler. Before the wrapped handler is invoked, the operation
+// is rejected with status 429 (Too Many Requests) if the client or DID has exceeded its limit, or with status
+// 409 (Conflict) if an identical operation is already in the operation queue.
+type HandlerWrapper struct {
+	common.HTTPHandler
+
+	namespace      string
+	pc             protocol.Client
+	opQueue        operationQueue
+	metrics        metricsProvider
+	handleRequest  common.HTTPRequestHandler
+	didLimiter     *limiter
+	clientLimiter  *limiter
+	clientIPHeader string
+	clientIPHops   int
+}
+
+// NewHandlerWrapper returns a new rate limiting handler wrapper. The operation queue is optional and, if
+// provided, is used to detect duplicate operations.
+func NewHandlerWrapper(cfg Config, handler common.HTTPHandler, namespace string, pc protocol.Client,
+	opQueue operationQueue, metrics metricsProvider) *HandlerWrapper {
+	if cfg.Interval == 0 {
+		cfg.Interval = defaultInterval
+	}
+
+	if cfg.CacheSize == 0 {
+		cfg.CacheSize = defaultCacheSize
+	}
+
+	if cfg.ClientIPHeaderHops == 0 {
+		cfg.ClientIPHeaderHops = 1
+	}
+
+	logger.Infof("Operation rate limits - Per DID: %d, Per client: %d, Interval: %s",
+		cfg.MaxOperationsPerDID, cfg.MaxOperationsPerClient, cfg.Interval)
+
+	return &HandlerWrapper{
+		HTTPHandler:    handler,
+		namespace:      namespace,
+		pc:             pc,
+		opQueue:        opQueue,
+		metrics:        metrics,
+		handleRequest:  handler.Handler(),
+		didLimiter:     newLimiter(cfg.MaxOperationsPerDID, cfg.Interval, cfg.CacheSize),
+		clientLimiter:  newLimiter(cfg.MaxOperationsPerClient, cfg.Interval, cfg.CacheSize),
+		clientIPHeader: cfg.ClientIPHeader,
+		clientIPHops:   cfg.ClientIPHeaderHops,
+	}
+}
+
+// Handler returns the 'wrapper' handler.
+func (h *HandlerWrapper) Handler() common.HTTPRequestHandler {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if ok, retryAfter := h.clientLimiter.allow(h.clientKey(req)); !ok {
+			h.reject(w, http.StatusTooManyRequests, reasonClientRateLimit, errClientRateLimit, retryAfter)
+
+			return
+		}
+
+		opBytes, err := ioutil.ReadAll(io.LimitReader(req.Body, maxRequestSize+1))
+		if err != nil {
+			common.WriteError(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		if len(opBytes) > maxRequestSize {
+			common.WriteError(w, http.StatusRequestEntityTooLarge, errRequestTooLarge)
+
+			return
+		}
+
+		// Restore the body for the wrapped handler.
+		req.Body = ioutil.NopCloser(bytes.NewReader(opBytes))
+
+		op, err := h.parse(opBytes)
+		if err != nil {
+			// Let the wrapped handler respond to an invalid operation.
+			logger.Debugf("Unable to parse operation: %s", err)
+
+			h.handleRequest(w, req)
+
+			return
+		}
+
+		if h.isQueued(op) {
+			h.reject(w, http.StatusConflict, reasonDuplicate, errDuplicate, 0)
+
+			return
+		}
+
+		if ok, retryAfter := h.didLimiter.allow(op.UniqueSuffix); !ok {
+			h.reject(w, http.StatusTooManyRequests, reasonDIDRateLimit,
+				fmt.Errorf("%w [%s]", errDIDRateLimit, op.UniqueSuffix), retryAfter)
+
+			return
+		}
+
+		h.handleRequest(w, req)
+	}
+}
+
+func (h *HandlerWrapper) parse(opBytes []byte) (*operation.Operation, error) {
+	currentProtocol, err := h.pc.Current()
+	if err != nil {
+		return nil, fmt.Errorf("get current protocol: %w", err)
+	}
+
+	return currentProtocol.OperationParser().Parse(h.namespace, opBytes)
+}
+
+// isQueued returns true if an identical operation is already in the operation queue.
+func (h *HandlerWrapper) isQueued(op *operation.Operation) bool {
+	return h.opQueue != nil && h.opQueue.Contains(op.UniqueSuffix, op.OperationBuffer)
+}
+
+// clientKey returns the key that identifies the client of the given request. A bearer token is only used if
+// it was verified since a client could otherwise evade the limit by sending a different (bogus) token with
+// each request. The token is hashed so that tokens aren't held in memory.
+func (h *HandlerWrapper) clientKey(req *http.Request) string {
+	if token, ok := auth.VerifiedToken(req); ok {
+		hash := sha256.Sum256([]byte(token))
+
+		return "token:" + base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+
+	if h.clientIPHeader != "" {
+		if ip := h.clientIPFromHeader(req); ip != "" {
+			return "ip:" + ip
+		}
+	}
+
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return "ip:" + req.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+// clientIPFromHeader returns the client IP address from the client IP header. The header (e.g. X-Forwarded-For)
+// may contain a list of addresses to which each proxy appends the address of its peer. Only the entries that were
+// appended by the trusted proxies may be relied upon, so the entry at the configured number of hops from the right
+// is used. If the list has fewer entries then the leftmost entry is used.
+func (h *HandlerWrapper) clientIPFromHeader(req *http.Request) string {
+	var entries []string
+
+	// The header may be repeated, in which case the values are treated as a single list.
+	for _, value := range req.Header.Values(h.clientIPHeader) {
+		for _, entry := range strings.Split(value, ",") {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				entries = append(entries, entry)
+			}
+		}
+	}
+
+	if len(entries) == 0 {
+		return ""
+	}
+
+	i := len(entries) - h.clientIPHops
+	if i < 0 {
+		i = 0
+	}
+
+	return entries[i]
+}
+
+func (h *HandlerWrapper) reject(w http.ResponseWriter, status int, reason string, err error,
+	retryAfter time.Duration) {
+	logger.Infof("Rejecting operation request: %s", err)
+
+	h.metrics.DocumentUpdateRejected(reason)
+
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+
+	common.WriteError(w, status, err)
+}
+
+// limiter allows (up to) a maximum number of requests per key within a fixed window that starts
+// at the first request for the key.
+type limiter struct {
+	max    int32
+	counts gcache.Cache
+}
+
+type window struct {
+	count   int32
+	expires time.Time
+}
+
+func newLimiter(max int, interval time.Duration, cacheSize int) *limiter {
+	if max <= 0 {
+		return &limiter{}
+	}
+
+	return &limiter{
+		max: int32(max),
+		counts: gcache.New(cacheSize).LRU().Expiration(interval).
+			LoaderFunc(func(interface{}) (interface{}, error) {
+				return &window{expires: time.Now().Add(interval)}, nil
+			}).Build(),
+	}
+}
+
+// allow returns true if the request for the given key is allowed. If not allowed then the time after which
+// the request may be retried is also returned.
+func (l *limiter) allow(key string) (bool, time.Duration) {
+	if l.counts == nil {
+		return true, 0
+	}
+
+	value, err := l.counts.Get(key)
+	if err != nil {
+		// This shouldn't happen since the loader doesn't return an error.
+		logger.Warnf("Unable to get rate limit window for [%s]: %s", key, err)
+
+		return true, 0
+	}
+
+	w := value.(*window)
+
+	if atomic.AddInt32(&w.count, 1) > l.max {
+		return false, time.Until(w.expires)
+	}
+
+	return true, 0
+}
@@ -0,0 +1,379 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package ratelimit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/sidetree-core-go/pkg/api/operation"
+	"github.com/trustbloc/sidetree-core-go/pkg/mocks"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+)
+
+const (
+	namespace = "did:orb"
+	suffix1   = "suffix1"
+	suffix2   = "suffix2"
+)
+
+func TestHandlerWrapper(t *testing.T) {
+	t.Run("No limits", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+
+		w := NewHandlerWrapper(Config{}, handler, namespace, newMockProtocolClient(), nil, metrics)
+		require.NotNil(t, w)
+		require.Equal(t, "/sidetree/v1/operations", w.Path())
+		require.Equal(t, http.MethodPost, w.Method())
+
+		for i := 0; i < 10; i++ {
+			require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, i, "")).StatusCode)
+		}
+
+		require.Equal(t, 10, handler.count())
+		require.Empty(t, metrics.rejected)
+	})
+
+	t.Run("DID rate limit", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerDID: 2}, handler, namespace, newMockProtocolClient(), nil,
+			metrics)
+
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 1, "")).StatusCode)
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 2, "")).StatusCode)
+
+		resp := invoke(w, newRequest(suffix1, 3, ""))
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(body), "too many operations for DID [suffix1]")
+
+		// Other DIDs are not affected.
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix2, 1, "")).StatusCode)
+
+		require.Equal(t, 3, handler.count())
+		require.Equal(t, []string{reasonDIDRateLimit}, metrics.rejected)
+	})
+
+	t.Run("Client rate limit", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerClient: 1, ClientIPHeader: "X-Forwarded-For"},
+			handler, namespace, newMockProtocolClient(), nil, metrics)
+
+		authWrapper := auth.NewHandlerWrapper(auth.Config{
+			AuthTokensDef: []*auth.TokenDef{
+				{EndpointExpression: "/sidetree/v1/operations", WriteTokens: []string{"client1", "client2"}},
+			},
+			AuthTokens: map[string]string{"client1": "token1", "client2": "token2"},
+		}, w)
+
+		// Clients identified by (verified) token.
+		require.Equal(t, http.StatusOK, invoke(authWrapper, newRequest(suffix1, 1, "token1")).StatusCode)
+		require.Equal(t, http.StatusTooManyRequests,
+			invoke(authWrapper, newRequest(suffix2, 1, "token1")).StatusCode)
+		require.Equal(t, http.StatusOK, invoke(authWrapper, newRequest(suffix2, 1, "token2")).StatusCode)
+
+		// Clients identified by IP address.
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 2, "")).StatusCode)
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, newRequest(suffix1, 3, "")).StatusCode)
+
+		req := newRequest(suffix1, 4, "")
+		req.Header.Set("X-Forwarded-For", "10.1.1.1, 10.2.2.2")
+		require.Equal(t, http.StatusOK, invoke(w, req).StatusCode)
+
+		req = newRequest(suffix1, 5, "")
+		req.Header.Set("X-Forwarded-For", "10.2.2.2")
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, req).StatusCode)
+
+		require.Equal(t, 4, handler.count())
+		require.Equal(t, []string{reasonClientRateLimit, reasonClientRateLimit, reasonClientRateLimit},
+			metrics.rejected)
+	})
+
+	t.Run("Spoofed client IP header entry", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerClient: 1, ClientIPHeader: "X-Forwarded-For"},
+			handler, namespace, newMockProtocolClient(), nil, metrics)
+
+		req := newRequest(suffix1, 1, "")
+		req.Header.Set("X-Forwarded-For", "1.1.1.1, 10.2.2.2")
+		require.Equal(t, http.StatusOK, invoke(w, req).StatusCode)
+
+		// A different (spoofed) leading entry doesn't evade the limit since the entry appended by the proxy is used.
+		req = newRequest(suffix1, 2, "")
+		req.Header.Set("X-Forwarded-For", "2.2.2.2, 10.2.2.2")
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, req).StatusCode)
+
+		// The header is repeated.
+		req = newRequest(suffix1, 3, "")
+		req.Header.Add("X-Forwarded-For", "3.3.3.3")
+		req.Header.Add("X-Forwarded-For", "10.2.2.2")
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, req).StatusCode)
+
+		require.Equal(t, 1, handler.count())
+		require.Equal(t, []string{reasonClientRateLimit, reasonClientRateLimit}, metrics.rejected)
+	})
+
+	t.Run("Client IP header hops", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+
+		w := NewHandlerWrapper(Config{
+			MaxOperationsPerClient: 1,
+			ClientIPHeader:         "X-Forwarded-For",
+			ClientIPHeaderHops:     2,
+		}, handler, namespace, newMockProtocolClient(), nil, &mockMetrics{})
+
+		req := newRequest(suffix1, 1, "")
+		req.Header.Set("X-Forwarded-For", "1.1.1.1, 10.1.1.1, 10.9.9.9")
+		require.Equal(t, http.StatusOK, invoke(w, req).StatusCode)
+
+		req = newRequest(suffix1, 2, "")
+		req.Header.Set("X-Forwarded-For", "2.2.2.2, 10.1.1.1, 10.8.8.8")
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, req).StatusCode)
+
+		// Fewer entries than hops -> the leftmost entry is used.
+		req = newRequest(suffix1, 3, "")
+		req.Header.Set("X-Forwarded-For", "10.1.1.1")
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, req).StatusCode)
+
+		req = newRequest(suffix1, 4, "")
+		req.Header.Set("X-Forwarded-For", "10.2.2.2, 10.9.9.9")
+		require.Equal(t, http.StatusOK, invoke(w, req).StatusCode)
+
+		require.Equal(t, 2, handler.count())
+	})
+
+	t.Run("Request too large", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+
+		w := NewHandlerWrapper(Config{}, handler, namespace, newMockProtocolClient(), nil, &mockMetrics{})
+
+		req := httptest.NewRequest(http.MethodPost, "/sidetree/v1/operations",
+			bytes.NewReader(make([]byte, maxRequestSize+1)))
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, invoke(w, req).StatusCode)
+		require.Zero(t, handler.count())
+	})
+
+	t.Run("Unverified token -> client identified by IP", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerClient: 1}, handler, namespace, newMockProtocolClient(),
+			nil, metrics)
+
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 1, "token1")).StatusCode)
+
+		// A different token from the same IP address doesn't evade the limit.
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, newRequest(suffix2, 1, "token2")).StatusCode)
+
+		require.Equal(t, 1, handler.count())
+		require.Equal(t, []string{reasonClientRateLimit}, metrics.rejected)
+	})
+
+	t.Run("Window expires", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerDID: 1, Interval: 50 * time.Millisecond},
+			handler, namespace, newMockProtocolClient(), nil, &mockMetrics{})
+
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 1, "")).StatusCode)
+		require.Equal(t, http.StatusTooManyRequests, invoke(w, newRequest(suffix1, 2, "")).StatusCode)
+
+		time.Sleep(100 * time.Millisecond)
+
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 3, "")).StatusCode)
+	})
+
+	t.Run("Duplicate operation", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+		metrics := &mockMetrics{}
+		opQueue := &mockOperationQueue{}
+
+		w := NewHandlerWrapper(Config{}, handler, namespace, newMockProtocolClient(), opQueue, metrics)
+
+		opQueue.add(suffix1, newOperation(suffix1, 1))
+
+		resp := invoke(w, newRequest(suffix1, 1, ""))
+		require.Equal(t, http.StatusConflict, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Retry-After"))
+
+		// A different operation for the same DID is accepted.
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 2, "")).StatusCode)
+
+		require.Equal(t, 1, handler.count())
+		require.Equal(t, []string{reasonDuplicate}, metrics.rejected)
+	})
+
+	t.Run("Invalid operation -> passed to wrapped handler", func(t *testing.T) {
+		handler := &mockHTTPHandler{}
+
+		w := NewHandlerWrapper(Config{MaxOperationsPerDID: 1}, handler, namespace, newMockProtocolClient(),
+			&mockOperationQueue{}, &mockMetrics{})
+
+		req := httptest.NewRequest(http.MethodPost, "/sidetree/v1/operations", strings.NewReader("invalid"))
+
+		require.Equal(t, http.StatusOK, invoke(w, req).StatusCode)
+		require.Equal(t, 1, handler.count())
+		require.Equal(t, "invalid", handler.lastBody)
+
+		pc := newMockProtocolClient()
+		pc.Err = errors.New("injected protocol error")
+
+		w = NewHandlerWrapper(Config{MaxOperationsPerDID: 1}, handler, namespace, pc, nil, &mockMetrics{})
+
+		require.Equal(t, http.StatusOK, invoke(w, newRequest(suffix1, 1, "")).StatusCode)
+		require.Equal(t, 2, handler.count())
+	})
+
+	t.Run("Read body error", func(t *testing.T) {
+		w := NewHandlerWrapper(Config{}, &mockHTTPHandler{}, namespace, newMockProtocolClient(), nil,
+			&mockMetrics{})
+
+		req := httptest.NewRequest(http.MethodPost, "/sidetree/v1/operations", &errReader{})
+
+		require.Equal(t, http.StatusBadRequest, invoke(w, req).StatusCode)
+	})
+}
+
+func invoke(w common.HTTPHandler, req *http.Request) *http.Response {
+	rw := httptest.NewRecorder()
+
+	w.Handler()(rw, req)
+
+	return rw.Result()
+}
+
+func newRequest(suffix string, n int, token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/sidetree/v1/operations",
+		strings.NewReader(string(newOperation(suffix, n))))
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return req
+}
+
+func newOperation(suffix string, n int) []byte {
+	return []byte(fmt.Sprintf(`{"didSuffix":"%s","n":%d}`, suffix, n))
+}
+
+func newMockProtocolClient() *mocks.MockProtocolClient {
+	parser := &mocks.OperationParser{}
+	parser.ParseStub = func(namespace string, opBytes []byte) (*operation.Operation, error) {
+		op := &struct {
+			DIDSuffix string `json:"didSuffix"`
+		}{}
+
+		if err := json.Unmarshal(opBytes, op); err != nil {
+			return nil, err
+		}
+
+		return &operation.Operation{
+			UniqueSuffix:    op.DIDSuffix,
+			OperationBuffer: opBytes,
+		}, nil
+	}
+
+	pc := mocks.NewMockProtocolClient()
+	pc.CurrentVersion.OperationParserReturns(parser)
+
+	return pc
+}
+
+type mockHTTPHandler struct {
+	mutex    sync.Mutex
+	n        int
+	lastBody string
+}
+
+func (m *mockHTTPHandler) Path() string {
+	return "/sidetree/v1/operations"
+}
+
+func (m *mockHTTPHandler) Method() string {
+	return http.MethodPost
+}
+
+func (m *mockHTTPHandler) Handler() common.HTTPRequestHandler {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			panic(err)
+		}
+
+		m.mutex.Lock()
+		defer m.mutex.Unlock()
+
+		m.n++
+		m.lastBody = string(body)
+	}
+}
+
+func (m *mockHTTPHandler) count() int {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.n
+}
+
+type mockMetrics struct {
+	rejected []string
+}
+
+func (m *mockMetrics) DocumentUpdateRejected(reason string) {
+	m.rejected = append(m.rejected, reason)
+}
+
+type mockOperationQueue struct {
+	ops []*operation.QueuedOperation
+}
+
+func (m *mockOperationQueue) add(suffix string, opBytes []byte) {
+	m.ops = append(m.ops, &operation.QueuedOperation{
+		UniqueSuffix:    suffix,
+		OperationBuffer: opBytes,
+	})
+}
+
+func (m *mockOperationQueue) Contains(suffix string, opBuffer []byte) bool {
+	for _, op := range m.ops {
+		if op.UniqueSuffix == suffix && bytes.Equal(op.OperationBuffer, opBuffer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type errReader struct{}
+
+func (r *errReader) Read([]byte) (int, error) {
+	return 0, errors.New("injected read error")
+}
@@ -6,21 +6,55 @@ SPDX-License-Identifier: Apache-2.0
 package restapi
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"mime"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/did"
 	"github.com/hyperledger/aries-framework-go/pkg/framework/aries/api/vdr"
 	"github.com/trustbloc/edge-core/pkg/log"
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 )
 
 const (
-	resolveDIDEndpoint = "/resolveDID"
-	didLDJson          = "application/did+ld+json"
+	resolveDIDEndpoint  = "/resolveDID"
+	identifiersEndpoint = "/1.0/identifiers/{" + didPathVariable + "}"
+	didPathVariable     = "did"
+
+	didJSON             = "application/did+json"
+	didLDJson           = "application/did+ld+json"
+	ldJSON              = "application/ld+json"
+	resolutionProfile   = "https://w3id.org/did-resolution"
+	didResolutionResult = ldJSON + `;profile="` + resolutionProfile + `"`
+
+	didResolutionContext = "https://w3id.org/did-resolution/v1"
+)
+
+// Error codes defined by the DID Resolution specification.
+const (
+	errInvalidDID                 = "invalidDid"
+	errNotFound                   = "notFound"
+	errRepresentationNotSupported = "representationNotSupported"
+	errMethodNotSupported         = "methodNotSupported"
+	errInternal                   = "internalError"
 )
 
 var logger = log.New("driver")
 
+// unexpectedResponseRegex matches the error that's returned by the Orb VDR when the Orb server responds with
+// an unexpected HTTP status code, e.g. "got unexpected response from <url> status '404' body <body>".
+var unexpectedResponseRegex = regexp.MustCompile(`got unexpected response from \S+ status '(\d{3})'`)
+
+// marshaller returns the representation of the given DID resolution result.
+type marshaller func(docResolution *did.DocResolution) ([]byte, error)
+
 // Handler http handler for each controller API endpoint.
 type Handler interface {
 	Path() string
@@ -43,45 +77,135 @@ func New(config *Config) *Operation {
 	return &Operation{orbVDR: config.OrbVDR}
 }
 
+// resolutionResult is the DID resolution result as defined by the DID Resolution specification.
+type resolutionResult struct {
+	Context          string                `json:"@context"`
+	DIDDocument      json.RawMessage       `json:"didDocument"`
+	ResolutionMeta   *resolutionMetadata   `json:"didResolutionMetadata"`
+	DocumentMetadata *did.DocumentMetadata `json:"didDocumentMetadata"`
+}
+
+type resolutionMetadata struct {
+	ContentType  string `json:"contentType,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// resolveDIDHandler resolves the DID given in the 'did' query parameter. This (legacy) endpoint ignores the
+// Accept header and always responds with the DID resolution result as returned by the VDR.
 func (o *Operation) resolveDIDHandler(rw http.ResponseWriter, req *http.Request) {
 	didParam, ok := req.URL.Query()["did"]
 
 	if !ok || didParam[0] == "" {
-		o.writeErrorResponse(rw, http.StatusBadRequest, "url param 'did' is missing")
+		o.writeErrorResponse(rw, http.StatusBadRequest, errInvalidDID, "url param 'did' is missing")
+
+		return
+	}
+
+	o.resolve(rw, didParam[0], didLDJson, func(docResolution *did.DocResolution) ([]byte, error) {
+		return docResolution.JSONBytes()
+	})
+}
+
+// resolveIdentifierHandler resolves the DID in the request path according to the HTTP(S) binding of the
+// DID Resolution specification (i.e. /1.0/identifiers/{did}).
+func (o *Operation) resolveIdentifierHandler(rw http.ResponseWriter, req *http.Request) {
+	didID := mux.Vars(req)[didPathVariable]
+
+	if didID == "" {
+		o.writeErrorResponse(rw, http.StatusBadRequest, errInvalidDID, "DID is missing from the request path")
+
+		return
+	}
+
+	contentType, ok := negotiateContentType(req.Header.Get("Accept"))
+	if !ok {
+		o.writeErrorResponse(rw, http.StatusNotAcceptable, errRepresentationNotSupported,
+			fmt.Sprintf("unsupported representation: %s", req.Header.Get("Accept")))
 
 		return
 	}
 
-	DocResolution, err := o.orbVDR.Read(didParam[0])
+	o.resolve(rw, didID, contentType, func(docResolution *did.DocResolution) ([]byte, error) {
+		return marshalRepresentation(docResolution, contentType)
+	})
+}
+
+func (o *Operation) resolve(rw http.ResponseWriter, didID, contentType string, marshal marshaller) {
+	parsedDID, err := did.Parse(didID)
+	if err != nil {
+		o.writeErrorResponse(rw, http.StatusBadRequest, errInvalidDID, fmt.Sprintf("invalid DID [%s]", didID))
+
+		return
+	}
+
+	if !o.orbVDR.Accept(parsedDID.Method) {
+		o.writeErrorResponse(rw, http.StatusNotImplemented, errMethodNotSupported,
+			fmt.Sprintf("DID method [%s] is not supported", parsedDID.Method))
+
+		return
+	}
+
+	docResolution, err := o.orbVDR.Read(didID)
 	if err != nil {
-		o.writeErrorResponse(rw, http.StatusBadRequest,
+		if isNotFound(err) {
+			o.writeErrorResponse(rw, http.StatusNotFound, errNotFound, fmt.Sprintf("DID [%s] not found", didID))
+
+			return
+		}
+
+		logger.Errorf("Error resolving DID [%s]: %s", didID, err)
+
+		o.writeErrorResponse(rw, http.StatusInternalServerError, errInternal,
 			fmt.Sprintf("failed to resolve did: %s", err.Error()))
 
 		return
 	}
 
-	bytes, err := DocResolution.JSONBytes()
+	respBytes, err := marshal(docResolution)
 	if err != nil {
-		o.writeErrorResponse(rw, http.StatusInternalServerError,
+		o.writeErrorResponse(rw, http.StatusInternalServerError, errInternal,
 			fmt.Sprintf("failed to marshal doc resolution: %s", err.Error()))
 
 		return
 	}
 
-	rw.Header().Set("Content-type", didLDJson)
-	rw.WriteHeader(http.StatusOK)
+	status := http.StatusOK
 
-	if _, err := rw.Write(bytes); err != nil {
-		logger.Errorf("Unable to send error message, %s", err)
+	if docResolution.DocumentMetadata != nil && docResolution.DocumentMetadata.Deactivated {
+		// The DID Resolution specification requires 410 (Gone) for a deactivated DID.
+		status = http.StatusGone
 	}
+
+	o.writeResponse(rw, status, contentType, respBytes)
 }
 
-// writeErrorResponse writes interface value to response.
-func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, msg string) {
+// writeErrorResponse writes a DID resolution result that contains the given error in its resolution metadata.
+func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, code, msg string) {
+	respBytes, err := json.Marshal(&resolutionResult{
+		Context:          didResolutionContext,
+		DIDDocument:      json.RawMessage("null"),
+		ResolutionMeta:   &resolutionMetadata{Error: code, ErrorMessage: msg},
+		DocumentMetadata: &did.DocumentMetadata{},
+	})
+	if err != nil {
+		// Shouldn't happen.
+		logger.Errorf("Unable to marshal error response: %s", err)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	o.writeResponse(rw, status, didResolutionResult, respBytes)
+}
+
+func (o *Operation) writeResponse(rw http.ResponseWriter, status int, contentType string, body []byte) {
+	rw.Header().Set("Content-Type", contentType)
 	rw.WriteHeader(status)
 
-	if _, err := rw.Write([]byte(msg)); err != nil {
-		logger.Errorf("Unable to send error message, %s", err)
+	if _, err := rw.Write(body); err != nil {
+		logger.Errorf("Unable to send response, %s", err)
 	}
 }
 
@@ -89,7 +213,127 @@ func (o *Operation) writeErrorResponse(rw http.ResponseWriter, status int, msg s
 func (o *Operation) GetRESTHandlers() []common.HTTPHandler {
 	return []common.HTTPHandler{
 		newHTTPHandler(resolveDIDEndpoint, http.MethodGet, o.resolveDIDHandler),
+		newHTTPHandler(identifiersEndpoint, http.MethodGet, o.resolveIdentifierHandler),
+	}
+}
+
+// marshalRepresentation returns the DID document (application/did+json or application/did+ld+json) or the
+// full DID resolution result, depending on the given content type.
+func marshalRepresentation(docResolution *did.DocResolution, contentType string) ([]byte, error) {
+	if docResolution.DIDDocument == nil {
+		return nil, errors.New("DID document is missing from resolution result")
+	}
+
+	docBytes, err := docResolution.DIDDocument.JSONBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	switch contentType {
+	case didLDJson:
+		return docBytes, nil
+	case didJSON:
+		// The plain JSON representation doesn't have a JSON-LD context.
+		doc := make(map[string]json.RawMessage)
+
+		if err := json.Unmarshal(docBytes, &doc); err != nil {
+			return nil, err
+		}
+
+		delete(doc, "@context")
+
+		return json.Marshal(doc)
+	}
+
+	docMetadata := docResolution.DocumentMetadata
+	if docMetadata == nil {
+		docMetadata = &did.DocumentMetadata{}
+	}
+
+	return json.Marshal(&resolutionResult{
+		Context:          didResolutionContext,
+		DIDDocument:      docBytes,
+		ResolutionMeta:   &resolutionMetadata{ContentType: didLDJson},
+		DocumentMetadata: docMetadata,
+	})
+}
+
+type mediaRange struct {
+	contentType string
+	q           float64
+}
+
+// negotiateContentType returns the content type of the response according to the given Accept header.
+// The full resolution result is returned if the client doesn't ask for a specific representation.
+func negotiateContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return didResolutionResult, true
 	}
+
+	var ranges []mediaRange
+
+	for _, r := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(r))
+		if err != nil {
+			logger.Debugf("Ignoring invalid media range [%s]: %s", r, err)
+
+			continue
+		}
+
+		q := 1.0
+
+		if qStr, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(qStr, 64); err != nil || q <= 0 {
+				continue
+			}
+		}
+
+		contentType, ok := toContentType(mediaType, params)
+		if !ok {
+			continue
+		}
+
+		ranges = append(ranges, mediaRange{contentType: contentType, q: q})
+	}
+
+	if len(ranges) == 0 {
+		return "", false
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].q > ranges[j].q })
+
+	return ranges[0].contentType, true
+}
+
+func toContentType(mediaType string, params map[string]string) (string, bool) {
+	switch mediaType {
+	case didJSON, didLDJson:
+		return mediaType, true
+	case ldJSON:
+		if strings.Contains(params["profile"], resolutionProfile) {
+			return didResolutionResult, true
+		}
+
+		return "", false
+	case "application/json", "application/*", "*/*":
+		return didResolutionResult, true
+	default:
+		return "", false
+	}
+}
+
+// isNotFound returns true if the given error from the VDR indicates that the DID was not found, i.e. the error
+// is vdr.ErrNotFound or the Orb server responded with HTTP status 404.
+func isNotFound(err error) bool {
+	if errors.Is(err, vdr.ErrNotFound) {
+		return true
+	}
+
+	// The Orb VDR doesn't return a typed error for an unexpected HTTP response, so the status code is taken from
+	// the error message.
+	matches := unexpectedResponseRegex.FindStringSubmatch(err.Error())
+
+	return len(matches) == 2 && matches[1] == strconv.Itoa(http.StatusNotFound)
 }
 
 // newHTTPHandler returns instance of HTTPHandler which can be used to handle http requests.
@@ -7,6 +7,8 @@ package restapi_test
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -23,7 +25,13 @@ import (
 )
 
 const (
-	resolveDIDEndpoint = "/resolveDID"
+	resolveDIDEndpoint  = "/resolveDID"
+	identifiersEndpoint = "/1.0/identifiers/{did}"
+	identifiersPath     = "/1.0/identifiers/"
+
+	testDID = "did:orb:uAAA:EiDJpL-xeSE4kVgoGjaQm_OurMdAGA0TXGRsCT8f_FI7xg"
+
+	didResolutionResult = `application/ld+json;profile="https://w3id.org/did-resolution"`
 )
 
 func TestDIDResolve(t *testing.T) {
@@ -36,10 +44,14 @@ func TestDIDResolve(t *testing.T) {
 
 		require.Equal(t, http.StatusBadRequest, rr.Code)
 		require.Contains(t, rr.Body.String(), "url param 'did' is missing")
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "invalidDid", result.ResolutionMetadata["error"])
 	})
 
 	t.Run("test error from read did", func(t *testing.T) {
 		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
+			AcceptValue: true,
 			ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
 				return nil, fmt.Errorf("failed to read did")
 			},
@@ -47,30 +59,279 @@ func TestDIDResolve(t *testing.T) {
 
 		handler := getHandler(t, c, resolveDIDEndpoint)
 
-		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1", nil, nil)
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did="+testDID, nil, nil)
 
-		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
 		require.Contains(t, rr.Body.String(), "failed to read did")
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "internalError", result.ResolutionMetadata["error"])
 	})
 
 	t.Run("test success", func(t *testing.T) {
-		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
-			ReadFunc: func(didID string, opts ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
-				return &did.DocResolution{DIDDocument: &did.Doc{ID: "did1"}}, nil
-			},
-		}})
+		c := restapi.New(&restapi.Config{OrbVDR: newMockVDR(&did.DocResolution{
+			DIDDocument: &did.Doc{ID: testDID},
+		})})
 
 		handler := getHandler(t, c, resolveDIDEndpoint)
 
-		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did=did1", nil, nil)
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did="+testDID, nil, nil)
 
 		require.Equal(t, http.StatusOK, rr.Code)
-		require.Contains(t, rr.Body.String(), "did1")
+		require.Contains(t, rr.Body.String(), testDID)
+	})
+
+	t.Run("Accept header is ignored", func(t *testing.T) {
+		docResolution := &did.DocResolution{DIDDocument: &did.Doc{ID: testDID}}
+
+		expected, err := docResolution.JSONBytes()
+		require.NoError(t, err)
+
+		c := restapi.New(&restapi.Config{OrbVDR: newMockVDR(docResolution)})
+
+		handler := getHandler(t, c, resolveDIDEndpoint)
+
+		for _, accept := range []string{"application/ld+json", "text/html", "application/did+json"} {
+			rr := serveHTTP(t, handler.Handler(), http.MethodGet, resolveDIDEndpoint+"?did="+testDID, nil, nil,
+				accept)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, "application/did+ld+json", rr.Header().Get("Content-Type"))
+			require.Equal(t, expected, rr.Body.Bytes())
+		}
 	})
 }
 
+func TestResolveIdentifier(t *testing.T) {
+	docResolution := &did.DocResolution{
+		DIDDocument: &did.Doc{
+			Context: []string{did.ContextV1},
+			ID:      testDID,
+		},
+		DocumentMetadata: &did.DocumentMetadata{
+			CanonicalID:  testDID,
+			EquivalentID: []string{"did:orb:https:example.com:uAAA:EiDJpL-xeSE4kVgoGjaQm_OurMdAGA0TXGRsCT8f_FI7xg"},
+			Method: &did.MethodMetadata{
+				Published:        true,
+				UpdateCommitment: "EiCvAWNMJn8hTZ0JY-Q1yeTP5sPVbYyE5O0e-6Y-CfJpFw",
+			},
+		},
+	}
+
+	c := restapi.New(&restapi.Config{OrbVDR: newMockVDR(docResolution)})
+
+	handler := getHandler(t, c, identifiersEndpoint)
+	require.Equal(t, http.MethodGet, handler.Method())
+
+	t.Run("Resolution result", func(t *testing.T) {
+		for _, accept := range []string{"", didResolutionResult, "application/json", "*/*"} {
+			rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+testDID, nil,
+				map[string]string{"did": testDID}, accept)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			require.Equal(t, didResolutionResult, rr.Header().Get("Content-Type"))
+
+			result := unmarshalResult(t, rr)
+			require.Equal(t, "https://w3id.org/did-resolution/v1", result.Context)
+			require.Equal(t, "application/did+ld+json", result.ResolutionMetadata["contentType"])
+			require.Empty(t, result.ResolutionMetadata["error"])
+
+			doc, err := did.ParseDocument(result.DIDDocument)
+			require.NoError(t, err)
+			require.Equal(t, testDID, doc.ID)
+
+			require.Equal(t, testDID, result.DocumentMetadata.CanonicalID)
+			require.Equal(t, docResolution.DocumentMetadata.EquivalentID, result.DocumentMetadata.EquivalentID)
+			require.NotNil(t, result.DocumentMetadata.Method)
+			require.True(t, result.DocumentMetadata.Method.Published)
+			require.Equal(t, docResolution.DocumentMetadata.Method.UpdateCommitment,
+				result.DocumentMetadata.Method.UpdateCommitment)
+		}
+	})
+
+	t.Run("DID document (JSON-LD)", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+testDID, nil,
+			map[string]string{"did": testDID}, "application/did+ld+json")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/did+ld+json", rr.Header().Get("Content-Type"))
+
+		doc := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		require.Equal(t, testDID, doc["id"])
+		require.NotNil(t, doc["@context"])
+		require.Nil(t, doc["didDocumentMetadata"])
+	})
+
+	t.Run("DID document (JSON)", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+testDID, nil,
+			map[string]string{"did": testDID}, "application/did+json")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/did+json", rr.Header().Get("Content-Type"))
+
+		doc := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+		require.Equal(t, testDID, doc["id"])
+		require.Nil(t, doc["@context"])
+	})
+
+	t.Run("Quality values", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+testDID, nil,
+			map[string]string{"did": testDID}, "application/did+ld+json;q=0.5, application/did+json, text/html")
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "application/did+json", rr.Header().Get("Content-Type"))
+	})
+
+	t.Run("Representation not supported", func(t *testing.T) {
+		for _, accept := range []string{"text/html", "application/ld+json", "application/did+json;q=0"} {
+			rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+testDID, nil,
+				map[string]string{"did": testDID}, accept)
+
+			require.Equal(t, http.StatusNotAcceptable, rr.Code)
+
+			result := unmarshalResult(t, rr)
+			require.Equal(t, "representationNotSupported", result.ResolutionMetadata["error"])
+		}
+	})
+
+	t.Run("Invalid DID", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath+"invalid", nil,
+			map[string]string{"did": "invalid"})
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, didResolutionResult, rr.Header().Get("Content-Type"))
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "invalidDid", result.ResolutionMetadata["error"])
+		require.Equal(t, "null", string(result.DIDDocument))
+	})
+
+	t.Run("Missing DID", func(t *testing.T) {
+		rr := serveHTTP(t, handler.Handler(), http.MethodGet, identifiersPath, nil, nil)
+
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "invalidDid", result.ResolutionMetadata["error"])
+	})
+
+	t.Run("Method not supported", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{}})
+
+		rr := serveHTTP(t, getHandler(t, c, identifiersEndpoint).Handler(), http.MethodGet,
+			identifiersPath+"did:web:example.com", nil, map[string]string{"did": "did:web:example.com"})
+
+		require.Equal(t, http.StatusNotImplemented, rr.Code)
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "methodNotSupported", result.ResolutionMetadata["error"])
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		for _, readErr := range []error{
+			vdrapi.ErrNotFound,
+			fmt.Errorf("failed to resolve DID: %w", vdrapi.ErrNotFound),
+			errors.New("got unexpected response from https://orb.domain1.com/sidetree/v1/identifiers/" + testDID +
+				" status '404' body document not found"),
+		} {
+			c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
+				AcceptValue: true,
+				ReadFunc: func(string, ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					return nil, readErr
+				},
+			}})
+
+			rr := serveHTTP(t, getHandler(t, c, identifiersEndpoint).Handler(), http.MethodGet,
+				identifiersPath+testDID, nil, map[string]string{"did": testDID})
+
+			require.Equal(t, http.StatusNotFound, rr.Code)
+
+			result := unmarshalResult(t, rr)
+			require.Equal(t, "notFound", result.ResolutionMetadata["error"])
+		}
+	})
+
+	t.Run("Other errors are not mapped to not found", func(t *testing.T) {
+		for _, readErr := range []error{
+			errors.New("document not found in cache"),
+			errors.New("anchor does not exist"),
+			errors.New("got unexpected response from https://orb.domain1.com/sidetree/v1/identifiers/" + testDID +
+				" status '500' body document not found"),
+		} {
+			c := restapi.New(&restapi.Config{OrbVDR: &mockvdr.MockVDR{
+				AcceptValue: true,
+				ReadFunc: func(string, ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+					return nil, readErr
+				},
+			}})
+
+			rr := serveHTTP(t, getHandler(t, c, identifiersEndpoint).Handler(), http.MethodGet,
+				identifiersPath+testDID, nil, map[string]string{"did": testDID})
+
+			require.Equal(t, http.StatusInternalServerError, rr.Code)
+
+			result := unmarshalResult(t, rr)
+			require.Equal(t, "internalError", result.ResolutionMetadata["error"])
+		}
+	})
+
+	t.Run("Deactivated", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{OrbVDR: newMockVDR(&did.DocResolution{
+			DIDDocument:      &did.Doc{ID: testDID},
+			DocumentMetadata: &did.DocumentMetadata{Deactivated: true},
+		})})
+
+		rr := serveHTTP(t, getHandler(t, c, identifiersEndpoint).Handler(), http.MethodGet,
+			identifiersPath+testDID, nil, map[string]string{"did": testDID})
+
+		require.Equal(t, http.StatusGone, rr.Code)
+
+		result := unmarshalResult(t, rr)
+		require.True(t, result.DocumentMetadata.Deactivated)
+	})
+
+	t.Run("Missing DID document", func(t *testing.T) {
+		c := restapi.New(&restapi.Config{OrbVDR: newMockVDR(&did.DocResolution{})})
+
+		rr := serveHTTP(t, getHandler(t, c, identifiersEndpoint).Handler(), http.MethodGet,
+			identifiersPath+testDID, nil, map[string]string{"did": testDID})
+
+		require.Equal(t, http.StatusInternalServerError, rr.Code)
+
+		result := unmarshalResult(t, rr)
+		require.Equal(t, "internalError", result.ResolutionMetadata["error"])
+	})
+}
+
+type resolutionResult struct {
+	Context            string                `json:"@context"`
+	DIDDocument        json.RawMessage       `json:"didDocument"`
+	ResolutionMetadata map[string]string     `json:"didResolutionMetadata"`
+	DocumentMetadata   *did.DocumentMetadata `json:"didDocumentMetadata"`
+}
+
+func unmarshalResult(t *testing.T, rr *httptest.ResponseRecorder) *resolutionResult {
+	t.Helper()
+
+	result := &resolutionResult{}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), result))
+
+	return result
+}
+
+func newMockVDR(docResolution *did.DocResolution) *mockvdr.MockVDR {
+	return &mockvdr.MockVDR{
+		AcceptValue: true,
+		ReadFunc: func(string, ...vdrapi.DIDMethodOption) (*did.DocResolution, error) {
+			return docResolution, nil
+		},
+	}
+}
+
 func serveHTTP(t *testing.T, handler common.HTTPRequestHandler, method, path string,
-	req []byte, urlVars map[string]string) *httptest.ResponseRecorder {
+	req []byte, urlVars map[string]string, accept ...string) *httptest.ResponseRecorder {
 	t.Helper()
 
 	httpReq, err := http.NewRequest(
@@ -80,6 +341,10 @@ func serveHTTP(t *testing.T, handler common.HTTPRequestHandler, method, path str
 	)
 	require.NoError(t, err)
 
+	if len(accept) > 0 && accept[0] != "" {
+		httpReq.Header.Set("Accept", accept[0])
+	}
+
 	rr := httptest.NewRecorder()
 	req1 := mux.SetURLVars(httpReq, urlVars)
 
@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package hashlink
 
 import (
+	"bytes"
 	"encoding/base64"
 	"fmt"
 	"strings"
@@ -20,6 +21,11 @@ const (
 	sha2_256 = 18
 	linksKey = 0x0f
 
+	// resourceHashesKey is the metadata key for the additional resource hashes of the content (computed using
+	// hash algorithms other than the one used for the resource hash). This key isn't defined by the hashlink
+	// specification.
+	resourceHashesKey = 0x0b
+
 	hl        = "hl"
 	separator = ":"
 
@@ -30,12 +36,23 @@ const (
 	HLPrefix = hl + separator
 )
 
+// SupportedMultihashCodes contains the multihash codes of the hash algorithms that may be used for resource hashes:
+// SHA2-256, SHA2-512, SHA3-256, SHA3-384, SHA3-512, BLAKE2b-256 and BLAKE2b-512.
+var SupportedMultihashCodes = []uint{ //nolint:gochecknoglobals
+	multihash.SHA2_256, multihash.SHA2_512,
+	multihash.SHA3_256, multihash.SHA3_384, multihash.SHA3_512,
+	multihash.BLAKE2B_MIN + 31, multihash.BLAKE2B_MAX,
+}
+
 // Encoder defines encoding function.
 type Encoder func(content []byte) string
 
 // Decoder defines decoding function.
 type Decoder func(encodedContent string) ([]byte, error)
 
+// Fetcher fetches the content at the given link.
+type Fetcher func(link string) ([]byte, error)
+
 // New creates HashLink.
 func New(opts ...Option) *HashLink {
 	// default encoder/decoder is base64 URL encoder/decoder
@@ -61,23 +78,32 @@ type Option func(opts *HashLink)
 
 // HashLink implements hashlink related functionality.
 type HashLink struct {
-	encoder       Encoder
-	decoder       Decoder
-	multihashCode uint
+	encoder                  Encoder
+	decoder                  Decoder
+	multihashCode            uint
+	additionalMultihashCodes []uint
+	acceptedMultihashCodes   []uint
 }
 
-// CreateHashLink will create hashlink for the supplied content and links.
+// CreateHashLink will create hashlink for the supplied content and links. If additional multihash codes are
+// configured then the additional resource hashes of the content are also added to the metadata.
 func (hl *HashLink) CreateHashLink(content []byte, links []string) (string, error) {
 	hashLink, err := hl.CreateResourceHash(content)
 	if err != nil {
 		return "", fmt.Errorf("failed to create resource hash from content[%s]: %w", string(content), err)
 	}
 
+	resourceHashes, err := hl.createAdditionalResourceHashes(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to create additional resource hashes from content[%s]: %w",
+			string(content), err)
+	}
+
 	// hash link without metadata
 	hashLink = HLPrefix + hashLink
 
-	if len(links) > 0 {
-		metadata, err := hl.CreateMetadataFromLinks(links)
+	if len(links) > 0 || len(resourceHashes) > 0 {
+		metadata, err := hl.createMetadata(links, resourceHashes)
 		if err != nil {
 			return "", fmt.Errorf("failed to create hashlink metadata for links[%+v]: %w", links, err)
 		}
@@ -111,26 +137,47 @@ func (hl *HashLink) ParseHashLink(hashLink string) (*Info, error) {
 	info := &Info{ResourceHash: rh}
 
 	if len(parts) > minHLParts {
-		links, err := hl.GetLinksFromMetadata(parts[2])
+		metadata, err := hl.decodeMetadata(parts[2])
 		if err != nil {
 			return nil, fmt.Errorf("failed to get links from metadata: %w", err)
 		}
 
-		info.Links = links
+		_, hasLinks := metadata[linksKey]
+		_, hasResourceHashes := metadata[resourceHashesKey]
+
+		if !hasLinks && !hasResourceHashes {
+			return nil, fmt.Errorf("failed to get links from metadata: missing key")
+		}
+
+		if hasLinks {
+			info.Links, err = getStringArray(metadata, linksKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert links from metadata to string array: %w", err)
+			}
+		}
+
+		if hasResourceHashes {
+			info.ResourceHashes, err = hl.getResourceHashes(metadata)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get resource hashes from metadata: %w", err)
+			}
+		}
 	}
 
 	return info, nil
 }
 
-// Info contains hashlink information: resource hash and links.
+// Info contains hashlink information: resource hash, links and the additional resource hashes (computed using
+// other hash algorithms) of the content.
 type Info struct {
-	ResourceHash string
-	Links        []string
+	ResourceHash   string
+	Links          []string
+	ResourceHashes []string
 }
 
 // CreateResourceHash will create resource hash for the supplied content.
 func (hl *HashLink) CreateResourceHash(content []byte) (string, error) {
-	mh, err := hashing.ComputeMultihash(hl.multihashCode, content)
+	mh, err := computeMultihash(hl.multihashCode, content)
 	if err != nil {
 		return "", fmt.Errorf("failed to compute multihash for code[%d]: %w", hl.multihashCode, err)
 	}
@@ -144,43 +191,114 @@ func (hl *HashLink) CreateMetadataFromLinks(links []string) (string, error) {
 		return "", fmt.Errorf("links not provided")
 	}
 
-	// generate the encoded metadata
-	metadata := make(map[int]interface{})
-	metadata[linksKey] = links
+	return hl.createMetadata(links, nil)
+}
+
+// CreateMetadata will create metadata for the supplied links along with the additional resource hashes of the
+// given content (if additional multihash codes are configured).
+func (hl *HashLink) CreateMetadata(content []byte, links []string) (string, error) {
+	if len(links) == 0 {
+		return "", fmt.Errorf("links not provided")
+	}
 
-	bytes, err := cbor.Marshal(metadata)
+	resourceHashes, err := hl.createAdditionalResourceHashes(content)
 	if err != nil {
-		return "", fmt.Errorf("failed to cbor.marshal links[%+v]: %w", links, err)
+		return "", fmt.Errorf("failed to create additional resource hashes: %w", err)
 	}
 
-	return hl.encoder(bytes), nil
+	return hl.createMetadata(links, resourceHashes)
 }
 
 // GetLinksFromMetadata will create links from metadata.
 func (hl *HashLink) GetLinksFromMetadata(enc string) ([]string, error) {
-	metadataBytes, err := hl.decoder(enc)
+	metadata, err := hl.decodeMetadata(enc)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+		return nil, err
 	}
 
-	var metadata map[int]interface{}
+	if _, ok := metadata[linksKey]; !ok {
+		return nil, fmt.Errorf("failed to get links from metadata: missing key")
+	}
 
-	err = cbor.Unmarshal(metadataBytes, &metadata)
+	links, err := getStringArray(metadata, linksKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to cbor.unmarshal metadata: %w", err)
+		return nil, fmt.Errorf("failed to convert links from metadata to string array: %w", err)
 	}
 
-	linksObj, ok := metadata[linksKey]
-	if !ok {
-		return nil, fmt.Errorf("failed to get links from metadata: missing key")
+	return links, nil
+}
+
+// VerifyResourceHash verifies that the given resource hash is the hash of the given content. The content is
+// hashed using the algorithm of the resource hash, which may be any of the supported algorithms.
+func (hl *HashLink) VerifyResourceHash(content []byte, resourceHash string) error {
+	expected, err := hl.decoder(resourceHash)
+	if err != nil {
+		return fmt.Errorf("failed to decode resource hash[%s]: %w", resourceHash, err)
 	}
 
-	links, err := toStringArray(linksObj)
+	mh, err := multihash.Decode(expected)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert links from metadata to string array: %w", err)
+		return fmt.Errorf("failed to decode multihash of resource hash[%s]: %w", resourceHash, err)
 	}
 
-	return links, nil
+	actual, err := computeMultihash(uint(mh.Code), content)
+	if err != nil {
+		return fmt.Errorf("failed to compute multihash for code[%d]: %w", mh.Code, err)
+	}
+
+	if !bytes.Equal(actual, expected) {
+		return fmt.Errorf("the resource hash of the content (%s) does not match the resource hash (%s)",
+			hl.encoder(actual), resourceHash)
+	}
+
+	return nil
+}
+
+// VerifyContent verifies that the given content matches the resource hash and all of the additional resource
+// hashes of the given hashlink info.
+func (hl *HashLink) VerifyContent(content []byte, info *Info) error {
+	for _, resourceHash := range append([]string{info.ResourceHash}, info.ResourceHashes...) {
+		if err := hl.VerifyResourceHash(content, resourceHash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Verify fetches the content of each of the links in the metadata of the given hashlink and verifies that the
+// content matches the resource hash and all of the additional resource hashes of the hashlink. An error is
+// returned if the hashlink has no links or if the content of any of the links can't be fetched or verified.
+func (hl *HashLink) Verify(hashLink string, fetch Fetcher) error {
+	info, err := hl.ParseHashLink(hashLink)
+	if err != nil {
+		return err
+	}
+
+	if len(info.Links) == 0 {
+		return fmt.Errorf("hashlink[%s] has no links to verify", hashLink)
+	}
+
+	var errMsgs []string
+
+	for _, link := range info.Links {
+		content, err := fetch(link)
+		if err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: fetch content: %s", link, err))
+
+			continue
+		}
+
+		if err := hl.VerifyContent(content, info); err != nil {
+			errMsgs = append(errMsgs, fmt.Sprintf("%s: %s", link, err))
+		}
+	}
+
+	if len(errMsgs) > 0 {
+		return fmt.Errorf("failed to verify links of hashlink[%s]: %s", hashLink, strings.Join(errMsgs, "; "))
+	}
+
+	return nil
 }
 
 // WithEncoder option is for specifying custom encoder.
@@ -204,6 +322,25 @@ func WithMultihashCode(mhCode uint) Option {
 	}
 }
 
+// WithAdditionalMultihashCodes option is for specifying the multihash codes (e.g. SHA3-256 or BLAKE2b-256) of the
+// additional resource hashes that are added to the metadata of created hashlinks. Resource hashes that use these
+// codes are also accepted when parsing a hashlink, which allows the primary hash algorithm to be migrated without
+// breaking existing hashlinks.
+func WithAdditionalMultihashCodes(mhCodes ...uint) Option {
+	return func(opts *HashLink) {
+		opts.additionalMultihashCodes = mhCodes
+	}
+}
+
+// WithAcceptedMultihashCodes option is for specifying the multihash codes of the resource hashes that are accepted
+// when parsing a hashlink, in addition to the multihash code and the additional multihash codes. (Hashlinks created
+// by other servers may use a different primary hash algorithm.)
+func WithAcceptedMultihashCodes(mhCodes ...uint) Option {
+	return func(opts *HashLink) {
+		opts.acceptedMultihashCodes = mhCodes
+	}
+}
+
 // GetHashLink will create hashlink from resource hash and metadata.
 func GetHashLink(resource, metadata string) string {
 	return fmt.Sprintf("%s:%s:%s", hl, resource, metadata)
@@ -225,6 +362,114 @@ func GetResourceHashFromHashLink(hashLink string) (string, error) {
 	return parts[1], nil
 }
 
+func (hl *HashLink) createAdditionalResourceHashes(content []byte) ([]string, error) {
+	var resourceHashes []string
+
+	for _, code := range hl.additionalMultihashCodes {
+		mh, err := computeMultihash(code, content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute multihash for code[%d]: %w", code, err)
+		}
+
+		resourceHashes = append(resourceHashes, hl.encoder(mh))
+	}
+
+	return resourceHashes, nil
+}
+
+func (hl *HashLink) createMetadata(links, resourceHashes []string) (string, error) {
+	// generate the encoded metadata
+	metadata := make(map[int]interface{})
+
+	if len(links) > 0 {
+		metadata[linksKey] = links
+	}
+
+	if len(resourceHashes) > 0 {
+		metadata[resourceHashesKey] = resourceHashes
+	}
+
+	metadataBytes, err := cbor.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("failed to cbor.marshal links[%+v]: %w", links, err)
+	}
+
+	return hl.encoder(metadataBytes), nil
+}
+
+func (hl *HashLink) decodeMetadata(enc string) (map[int]interface{}, error) {
+	metadataBytes, err := hl.decoder(enc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	var metadata map[int]interface{}
+
+	err = cbor.Unmarshal(metadataBytes, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to cbor.unmarshal metadata: %w", err)
+	}
+
+	return metadata, nil
+}
+
+func (hl *HashLink) getResourceHashes(metadata map[int]interface{}) ([]string, error) {
+	resourceHashes, err := getStringArray(metadata, resourceHashesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resourceHash := range resourceHashes {
+		multihashBytes, err := hl.decoder(resourceHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode resource hash[%s]: %w", resourceHash, err)
+		}
+
+		// The code isn't checked since a hashlink may contain resource hashes for algorithms that aren't
+		// (yet) configured on this instance.
+		if _, err := multihash.Decode(multihashBytes); err != nil {
+			return nil, fmt.Errorf("resource hash[%s] is not a valid multihash: %w", resourceHash, err)
+		}
+	}
+
+	return resourceHashes, nil
+}
+
+func getStringArray(metadata map[int]interface{}, key int) ([]string, error) {
+	return toStringArray(metadata[key])
+}
+
+// GetMultihashCode returns the multihash code for the given hash algorithm name (e.g. sha2-256, sha3-256 or
+// blake2b-256). An error is returned if the algorithm isn't supported.
+func GetMultihashCode(name string) (uint, error) {
+	code, ok := multihash.Names[strings.ToLower(name)]
+	if !ok || !isSupportedMultihashCode(uint(code)) {
+		return 0, fmt.Errorf("hash algorithm [%s] is not supported", name)
+	}
+
+	return uint(code), nil
+}
+
+// computeMultihash computes the multihash of the given content. The SHA-2 algorithms (and unsupported algorithms)
+// are handled by the Sidetree hashing package and the other supported algorithms are computed using go-multihash.
+func computeMultihash(code uint, content []byte) ([]byte, error) {
+	if code == multihash.SHA2_256 || code == multihash.SHA2_512 || !isSupportedMultihashCode(code) {
+		return hashing.ComputeMultihash(code, content)
+	}
+
+	return multihash.Sum(content, uint64(code), -1)
+}
+
+func isSupportedMultihashCode(code uint) bool {
+	for _, c := range SupportedMultihashCodes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
 // StringArray is utility function to return string array from interface.
 func toStringArray(obj interface{}) ([]string, error) {
 	if obj == nil {
@@ -274,9 +519,14 @@ func (hl *HashLink) isValidMultihash(encodedMultihash string) error {
 		return fmt.Errorf("failed to decode multihash: %w", err)
 	}
 
-	if mh.Code != uint64(hl.multihashCode) {
-		return fmt.Errorf("resource multihash code[%d] is not supported code[%d]", mh.Code, hl.multihashCode)
+	codes := append([]uint{hl.multihashCode}, hl.additionalMultihashCodes...)
+	codes = append(codes, hl.acceptedMultihashCodes...)
+
+	for _, code := range codes {
+		if mh.Code == uint64(code) {
+			return nil
+		}
 	}
 
-	return nil
+	return fmt.Errorf("resource multihash code[%d] is not supported code%v", mh.Code, codes)
 }
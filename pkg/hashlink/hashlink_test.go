@@ -7,11 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package hashlink
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
 	"github.com/btcsuite/btcutil/base58"
 	cbor "github.com/fxamacker/cbor/v2"
+	"github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/require"
 )
 
@@ -20,6 +22,8 @@ const (
 	exampleURL     = "https://example.com/hw.txt"
 
 	invalidMultihashCode = 55
+
+	blake2b256 = multihash.BLAKE2B_MIN + 31
 )
 
 func TestHashLink_CreateHashLink(t *testing.T) {
@@ -225,6 +229,68 @@ func TestHashLink_CreateMetadataFromLinks(t *testing.T) {
 	})
 }
 
+func TestHashLink_CreateMetadata(t *testing.T) {
+	t.Run("success - with additional resource hashes", func(t *testing.T) {
+		hl := New(WithAdditionalMultihashCodes(multihash.SHA3_256))
+
+		rh, err := hl.CreateResourceHash([]byte(exampleContent))
+		require.NoError(t, err)
+
+		md, err := hl.CreateMetadata([]byte(exampleContent), []string{exampleURL})
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(GetHashLink(rh, md))
+		require.NoError(t, err)
+		require.Equal(t, []string{exampleURL}, hlInfo.Links)
+		require.Len(t, hlInfo.ResourceHashes, 1)
+		require.NoError(t, hl.VerifyContent([]byte(exampleContent), hlInfo))
+	})
+
+	t.Run("success - no additional resource hashes", func(t *testing.T) {
+		hl := New()
+
+		md, err := hl.CreateMetadata([]byte(exampleContent), []string{exampleURL})
+		require.NoError(t, err)
+
+		md2, err := hl.CreateMetadataFromLinks([]string{exampleURL})
+		require.NoError(t, err)
+		require.Equal(t, md2, md)
+	})
+
+	t.Run("error - links not provided", func(t *testing.T) {
+		md, err := New().CreateMetadata([]byte(exampleContent), nil)
+		require.EqualError(t, err, "links not provided")
+		require.Empty(t, md)
+	})
+
+	t.Run("error - unsupported additional code", func(t *testing.T) {
+		md, err := New(WithAdditionalMultihashCodes(55)).CreateMetadata([]byte(exampleContent), []string{exampleURL})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create additional resource hashes")
+		require.Empty(t, md)
+	})
+}
+
+func TestGetMultihashCode(t *testing.T) {
+	code, err := GetMultihashCode("sha2-256")
+	require.NoError(t, err)
+	require.Equal(t, uint(multihash.SHA2_256), code)
+
+	code, err = GetMultihashCode("SHA3-256")
+	require.NoError(t, err)
+	require.Equal(t, uint(multihash.SHA3_256), code)
+
+	code, err = GetMultihashCode("blake2b-256")
+	require.NoError(t, err)
+	require.Equal(t, uint(blake2b256), code)
+
+	_, err = GetMultihashCode("md5")
+	require.EqualError(t, err, "hash algorithm [md5] is not supported")
+
+	_, err = GetMultihashCode("invalid")
+	require.EqualError(t, err, "hash algorithm [invalid] is not supported")
+}
+
 func TestHashLink_ParseHashLink(t *testing.T) {
 	t.Run("success - defaults", func(t *testing.T) {
 		hl := New()
@@ -318,6 +384,223 @@ func TestHashLink_ParseHashLink(t *testing.T) {
 	})
 }
 
+func TestHashLink_AdditionalResourceHashes(t *testing.T) {
+	t.Run("success - with links", func(t *testing.T) {
+		hl := New(WithAdditionalMultihashCodes(multihash.SHA3_256, blake2b256))
+
+		hash, err := hl.CreateHashLink([]byte(exampleContent), []string{exampleURL})
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Equal(t, "uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hlInfo.ResourceHash)
+		require.Equal(t, []string{exampleURL}, hlInfo.Links)
+		require.Len(t, hlInfo.ResourceHashes, 2)
+
+		for i, code := range []uint64{multihash.SHA3_256, blake2b256} {
+			mhBytes, e := hl.decoder(hlInfo.ResourceHashes[i])
+			require.NoError(t, e)
+
+			mh, e := multihash.Decode(mhBytes)
+			require.NoError(t, e)
+			require.Equal(t, code, mh.Code)
+		}
+
+		require.NoError(t, hl.VerifyContent([]byte(exampleContent), hlInfo))
+
+		// A hashlink instance without the additional codes is still able to parse the hashlink.
+		hlInfo2, err := New().ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Equal(t, hlInfo, hlInfo2)
+
+		// The links are still returned for existing callers.
+		links, err := hl.GetLinksFromMetadata(hash[len(HLPrefix+hlInfo.ResourceHash+separator):])
+		require.NoError(t, err)
+		require.Equal(t, []string{exampleURL}, links)
+	})
+
+	t.Run("success - no links", func(t *testing.T) {
+		hl := New(WithAdditionalMultihashCodes(multihash.SHA3_256))
+
+		hash, err := hl.CreateHashLink([]byte(exampleContent), nil)
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Empty(t, hlInfo.Links)
+		require.Len(t, hlInfo.ResourceHashes, 1)
+	})
+
+	t.Run("success - migrated primary hash algorithm", func(t *testing.T) {
+		hl := New(WithMultihashCode(multihash.SHA3_256), WithAdditionalMultihashCodes(sha2_256))
+
+		hash, err := hl.CreateHashLink([]byte(exampleContent), nil)
+		require.NoError(t, err)
+
+		hlInfo, err := hl.ParseHashLink(hash)
+		require.NoError(t, err)
+		require.Equal(t, []string{"uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ"}, hlInfo.ResourceHashes)
+
+		// Old hashlinks are still accepted.
+		_, err = hl.ParseHashLink("hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ")
+		require.NoError(t, err)
+
+		// The new hashlink isn't accepted by an instance that doesn't support SHA3-256 as a primary hash.
+		_, err = New().ParseHashLink(hash)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "resource multihash code[22] is not supported code[18]")
+
+		_, err = New(WithAcceptedMultihashCodes(SupportedMultihashCodes...)).ParseHashLink(hash)
+		require.NoError(t, err)
+	})
+
+	t.Run("error - unsupported additional multihash code", func(t *testing.T) {
+		hl := New(WithAdditionalMultihashCodes(invalidMultihashCode))
+
+		_, err := hl.CreateHashLink([]byte(exampleContent), nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to create additional resource hashes from content")
+	})
+
+	t.Run("error - invalid resource hashes in metadata", func(t *testing.T) {
+		hl := New()
+
+		metadata := map[int]interface{}{resourceHashesKey: []string{"uabc"}}
+
+		bytes, err := cbor.Marshal(metadata)
+		require.NoError(t, err)
+
+		_, err = hl.ParseHashLink(GetHashLink("uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hl.encoder(bytes)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get resource hashes from metadata: resource hash[uabc] "+
+			"is not a valid multihash")
+
+		metadata = map[int]interface{}{resourceHashesKey: "uabc"}
+
+		bytes, err = cbor.Marshal(metadata)
+		require.NoError(t, err)
+
+		_, err = hl.ParseHashLink(GetHashLink("uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hl.encoder(bytes)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get resource hashes from metadata: expecting an array")
+	})
+
+	t.Run("error - no links or resource hashes in metadata", func(t *testing.T) {
+		hl := New()
+
+		bytes, err := cbor.Marshal(map[int]interface{}{})
+		require.NoError(t, err)
+
+		_, err = hl.ParseHashLink(GetHashLink("uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", hl.encoder(bytes)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to get links from metadata: missing key")
+	})
+}
+
+func TestHashLink_VerifyResourceHash(t *testing.T) {
+	hl := New()
+
+	for _, code := range SupportedMultihashCodes {
+		rh, err := New(WithMultihashCode(code)).CreateResourceHash([]byte(exampleContent))
+		require.NoError(t, err)
+
+		require.NoError(t, hl.VerifyResourceHash([]byte(exampleContent), rh))
+
+		err = hl.VerifyResourceHash([]byte("other content"), rh)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "does not match the resource hash")
+	}
+
+	t.Run("error - invalid resource hash", func(t *testing.T) {
+		err := hl.VerifyResourceHash([]byte(exampleContent), "uabc")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode multihash of resource hash[uabc]")
+
+		err = hl.VerifyResourceHash([]byte(exampleContent), "u=")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to decode resource hash[u=]")
+	})
+
+	t.Run("error - unsupported algorithm", func(t *testing.T) {
+		mh, err := multihash.Sum([]byte(exampleContent), multihash.MD5, -1)
+		require.NoError(t, err)
+
+		err = hl.VerifyResourceHash([]byte(exampleContent), hl.encoder(mh))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to compute multihash for code[213]")
+	})
+}
+
+func TestHashLink_Verify(t *testing.T) {
+	hl := New(WithAdditionalMultihashCodes(multihash.SHA3_256))
+
+	const (
+		link1 = "https://example.com/cas/1"
+		link2 = "https://example.com/cas/2"
+	)
+
+	hash, err := hl.CreateHashLink([]byte(exampleContent), []string{link1, link2})
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		var fetched []string
+
+		require.NoError(t, hl.Verify(hash, func(link string) ([]byte, error) {
+			fetched = append(fetched, link)
+
+			return []byte(exampleContent), nil
+		}))
+
+		require.Equal(t, []string{link1, link2}, fetched)
+	})
+
+	t.Run("error - content mismatch", func(t *testing.T) {
+		err := hl.Verify(hash, func(link string) ([]byte, error) {
+			if link == link2 {
+				return []byte("tampered"), nil
+			}
+
+			return []byte(exampleContent), nil
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), link2+": the resource hash of the content")
+		require.NotContains(t, err.Error(), link1)
+	})
+
+	t.Run("error - additional resource hash mismatch", func(t *testing.T) {
+		metadata, err := hl.createMetadata([]string{link1}, []string{"uFiDAYBmBsxBGNzCyF3g-Vp8_TlGYRTwxaxQxj6OZ2BLWYA"})
+		require.NoError(t, err)
+
+		err = hl.Verify(GetHashLink("uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", metadata),
+			func(link string) ([]byte, error) {
+				return []byte(exampleContent), nil
+			})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), link1+": the resource hash of the content")
+	})
+
+	t.Run("error - fetch error", func(t *testing.T) {
+		err := hl.Verify(hash, func(link string) ([]byte, error) {
+			return nil, errors.New("injected fetch error")
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), link1+": fetch content: injected fetch error")
+		require.Contains(t, err.Error(), link2+": fetch content: injected fetch error")
+	})
+
+	t.Run("error - no links", func(t *testing.T) {
+		err := hl.Verify("hl:uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "has no links to verify")
+	})
+
+	t.Run("error - invalid hashlink", func(t *testing.T) {
+		err := hl.Verify("invalid", nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must start with 'hl:' prefix")
+	})
+}
+
 func TestGetHashLink(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		hl := GetHashLink("resource", "metadata")
@@ -7,13 +7,17 @@ SPDX-License-Identifier: Apache-2.0
 package auth
 
 import (
+	"context"
 	"net/http"
+	"strings"
 
 	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
 )
 
 const unauthorizedResponse = "Unauthorized.\n"
 
+type verifiedTokenKey struct{}
+
 // HandlerWrapper wraps an existing HTTP handler and performs bearer token authorization.
 // If authorized then the wrapped handler is invoked.
 type HandlerWrapper struct {
@@ -58,6 +62,20 @@ func (h *HandlerWrapper) Handler() common.HTTPRequestHandler {
 			return
 		}
 
+		if h.verifier.TokenRequired() {
+			// The bearer token was verified so make it available to the wrapped handler.
+			req = req.WithContext(context.WithValue(req.Context(), verifiedTokenKey{},
+				strings.TrimPrefix(req.Header.Get(authHeader), tokenPrefix)))
+		}
+
 		h.handleRequest(w, req)
 	}
 }
+
+// VerifiedToken returns the bearer token of the given request if the token was verified by the handler
+// wrapper. False is returned if the request wasn't authorized with a bearer token (i.e. the endpoint is open).
+func VerifiedToken(req *http.Request) (string, bool) {
+	token, ok := req.Context().Value(verifiedTokenKey{}).(string)
+
+	return token, ok
+}
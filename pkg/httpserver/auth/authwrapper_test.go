@@ -53,6 +53,35 @@ func TestHandlerWrapper(t *testing.T) {
 		require.NoError(t, result.Body.Close())
 	})
 
+	t.Run("Verified token", func(t *testing.T) {
+		handler := &mockHTTPHandler{path: "/services/orb/outbox", method: http.MethodPost}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/services/orb/outbox", nil)
+		req.Header[authHeader] = []string{tokenPrefix + "ADMIN_TOKEN"}
+
+		NewHandlerWrapper(cfg, handler).Handler()(rw, req)
+
+		require.NoError(t, rw.Result().Body.Close())
+		require.True(t, handler.hasToken)
+		require.Equal(t, "ADMIN_TOKEN", handler.token)
+	})
+
+	t.Run("Open endpoint -> no verified token", func(t *testing.T) {
+		handler := &mockHTTPHandler{path: "/services/orb/other", method: http.MethodPost}
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/services/orb/other", nil)
+		req.Header[authHeader] = []string{tokenPrefix + "SOME_TOKEN"}
+
+		NewHandlerWrapper(cfg, handler).Handler()(rw, req)
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+		require.False(t, handler.hasToken)
+	})
+
 	t.Run("Unauthorized", func(t *testing.T) {
 		rw := httptest.NewRecorder()
 		req := httptest.NewRequest(http.MethodPost, "/services/orb/outbox", nil)
@@ -66,8 +95,10 @@ func TestHandlerWrapper(t *testing.T) {
 }
 
 type mockHTTPHandler struct {
-	path   string
-	method string
+	path     string
+	method   string
+	token    string
+	hasToken bool
 }
 
 func (m *mockHTTPHandler) Path() string {
@@ -79,5 +110,7 @@ func (m *mockHTTPHandler) Method() string {
 }
 
 func (m *mockHTTPHandler) Handler() common.HTTPRequestHandler {
-	return func(writer http.ResponseWriter, request *http.Request) {}
+	return func(writer http.ResponseWriter, request *http.Request) {
+		m.token, m.hasToken = VerifiedToken(request)
+	}
 }
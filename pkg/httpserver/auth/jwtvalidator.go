@@ -0,0 +1,280 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/square/go-jose/v3"
+	"github.com/square/go-jose/v3/jwt"
+)
+
+const (
+	defaultJWKSRefreshInterval    = 15 * time.Minute
+	defaultJWKSMinRefreshInterval = 10 * time.Second
+	defaultJWTLeeway              = time.Minute
+)
+
+// JWTConfig contains the configuration for validating JWT bearer tokens.
+type JWTConfig struct {
+	// JWKS is the URL (http or https) or the path of a file that contains the JSON Web Key Set
+	// which is used to verify the signatures of tokens.
+	JWKS string
+
+	// Issuer is the expected issuer ('iss' claim) of tokens. If empty then the issuer isn't checked.
+	Issuer string
+
+	// Audience is the expected audience ('aud' claim) of tokens. The audience is required when JWKS is set since
+	// otherwise a token that was issued by the identity provider for another service would be accepted.
+	Audience string
+
+	// RefreshInterval is the interval after which the key set is reloaded.
+	RefreshInterval time.Duration
+
+	// Leeway is the allowed clock skew when validating the time-based claims of a token.
+	Leeway time.Duration
+}
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// JWTValidator validates signed JWT bearer tokens against a JSON Web Key Set and returns the scopes that are
+// granted by the token. The key set is reloaded periodically and also whenever a token is signed with a key
+// that's not in the cached key set (so that keys may be rotated by the identity provider).
+type JWTValidator struct {
+	JWTConfig
+
+	httpClient         httpClient
+	readFile           func(path string) ([]byte, error)
+	minRefreshInterval time.Duration
+	now                func() time.Time
+
+	mutex    sync.RWMutex
+	keySet   *jose.JSONWebKeySet
+	loadedAt time.Time
+}
+
+// NewJWTValidator returns a new JWT validator.
+func NewJWTValidator(cfg JWTConfig, client httpClient) *JWTValidator {
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = defaultJWKSRefreshInterval
+	}
+
+	if cfg.Leeway == 0 {
+		cfg.Leeway = defaultJWTLeeway
+	}
+
+	return &JWTValidator{
+		JWTConfig:          cfg,
+		httpClient:         client,
+		readFile:           ioutil.ReadFile,
+		minRefreshInterval: defaultJWKSMinRefreshInterval,
+		now:                time.Now,
+	}
+}
+
+type tokenClaims struct {
+	jwt.Claims
+
+	Scope string          `json:"scope,omitempty"`
+	Scp   json.RawMessage `json:"scp,omitempty"`
+}
+
+// Validate verifies the signature of the given JWT and validates its issuer, audience and expiry.
+// The scopes in the token's 'scope' (space-delimited) or 'scp' claim are returned.
+func (v *JWTValidator) Validate(token string) ([]string, error) {
+	tok, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, fmt.Errorf("parse token: %w", err)
+	}
+
+	if len(tok.Headers) != 1 {
+		return nil, errors.New("token must have exactly one signature")
+	}
+
+	key, err := v.getKey(tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if key.Algorithm != "" && key.Algorithm != tok.Headers[0].Algorithm {
+		return nil, fmt.Errorf("algorithm [%s] does not match the algorithm of key [%s]",
+			tok.Headers[0].Algorithm, key.KeyID)
+	}
+
+	claims := &tokenClaims{}
+
+	if err := tok.Claims(key.Key, claims); err != nil {
+		return nil, fmt.Errorf("verify token: %w", err)
+	}
+
+	if err := v.validateClaims(&claims.Claims); err != nil {
+		return nil, err
+	}
+
+	return claims.scopes()
+}
+
+func (v *JWTValidator) validateClaims(claims *jwt.Claims) error {
+	if claims.Expiry == nil {
+		return errors.New("token has no expiry")
+	}
+
+	expected := jwt.Expected{
+		Issuer: v.Issuer,
+		Time:   v.now(),
+	}
+
+	if v.Audience != "" {
+		expected.Audience = jwt.Audience{v.Audience}
+	}
+
+	if err := claims.ValidateWithLeeway(expected, v.Leeway); err != nil {
+		return fmt.Errorf("validate claims: %w", err)
+	}
+
+	return nil
+}
+
+func (v *JWTValidator) getKey(keyID string) (*jose.JSONWebKey, error) {
+	keySet, loadedAt := v.getKeySet()
+
+	now := v.now()
+
+	if keySet == nil || now.Sub(loadedAt) > v.RefreshInterval {
+		var err error
+
+		keySet, err = v.loadKeySet()
+		if err != nil {
+			return nil, err
+		}
+	} else if len(keySet.Key(keyID)) == 0 && now.Sub(loadedAt) > v.minRefreshInterval {
+		// The key may have been added to the key set since it was loaded.
+		logger.Debugf("Key [%s] not found in JWKS. Reloading key set.", keyID)
+
+		var err error
+
+		keySet, err = v.loadKeySet()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return findKey(keySet, keyID)
+}
+
+func (v *JWTValidator) getKeySet() (*jose.JSONWebKeySet, time.Time) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.keySet, v.loadedAt
+}
+
+func (v *JWTValidator) loadKeySet() (*jose.JSONWebKeySet, error) {
+	keySetBytes, err := v.readKeySet()
+	if err != nil {
+		return nil, fmt.Errorf("load JWKS from [%s]: %w", v.JWKS, err)
+	}
+
+	keySet := &jose.JSONWebKeySet{}
+
+	if err := json.Unmarshal(keySetBytes, keySet); err != nil {
+		return nil, fmt.Errorf("unmarshal JWKS from [%s]: %w", v.JWKS, err)
+	}
+
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	v.keySet = keySet
+	v.loadedAt = v.now()
+
+	logger.Debugf("Loaded %d key(s) from JWKS [%s]", len(keySet.Keys), v.JWKS)
+
+	return keySet, nil
+}
+
+func (v *JWTValidator) readKeySet() ([]byte, error) {
+	if !strings.HasPrefix(v.JWKS, "http://") && !strings.HasPrefix(v.JWKS, "https://") {
+		return v.readFile(strings.TrimPrefix(v.JWKS, "file://"))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, v.JWKS, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+
+	defer func() {
+		if e := resp.Body.Close(); e != nil {
+			logger.Warnf("Error closing response body: %s", e)
+		}
+	}()
+
+	respBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code [%d]: %s", resp.StatusCode, respBytes)
+	}
+
+	return respBytes, nil
+}
+
+func findKey(keySet *jose.JSONWebKeySet, keyID string) (*jose.JSONWebKey, error) {
+	if keyID == "" {
+		// A token without a key ID may be verified if the key set contains only one signing key.
+		if len(keySet.Keys) == 1 {
+			return &keySet.Keys[0], nil
+		}
+
+		return nil, errors.New("token has no key ID")
+	}
+
+	keys := keySet.Key(keyID)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("key [%s] not found in JWKS", keyID)
+	}
+
+	return &keys[0], nil
+}
+
+func (c *tokenClaims) scopes() ([]string, error) {
+	scopes := strings.Fields(c.Scope)
+
+	if len(c.Scp) == 0 {
+		return scopes, nil
+	}
+
+	// The 'scp' claim may be either an array of strings or a space-delimited string.
+	var scpArray []string
+
+	if err := json.Unmarshal(c.Scp, &scpArray); err == nil {
+		return append(scopes, scpArray...), nil
+	}
+
+	var scpString string
+
+	if err := json.Unmarshal(c.Scp, &scpString); err != nil {
+		return nil, fmt.Errorf("invalid 'scp' claim: %w", err)
+	}
+
+	return append(scopes, strings.Fields(scpString)...), nil
+}
@@ -0,0 +1,366 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/square/go-jose/v3"
+	"github.com/square/go-jose/v3/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	issuer   = "https://idp.example.com"
+	audience = "https://orb.example.com"
+)
+
+func TestJWTValidator(t *testing.T) {
+	key1 := newSigningKey(t, "key1")
+	key2 := newSigningKey(t, "key2")
+
+	jwks := newJWKSServer(t, key1)
+	defer jwks.Close()
+
+	v := NewJWTValidator(JWTConfig{JWKS: jwks.URL, Issuer: issuer, Audience: audience}, http.DefaultClient)
+	require.Equal(t, defaultJWKSRefreshInterval, v.RefreshInterval)
+	require.Equal(t, defaultJWTLeeway, v.Leeway)
+
+	t.Run("Success", func(t *testing.T) {
+		scopes, err := v.Validate(newToken(t, key1, newClaims(), "read admin"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"read", "admin"}, scopes)
+	})
+
+	t.Run("scp claim", func(t *testing.T) {
+		claims := newClaims()
+
+		scopes, err := v.Validate(newTokenWithClaims(t, key1, claims, map[string]interface{}{
+			"scp": []string{"read", "admin"},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, []string{"read", "admin"}, scopes)
+
+		scopes, err = v.Validate(newTokenWithClaims(t, key1, claims, map[string]interface{}{
+			"scp": "read admin",
+		}))
+		require.NoError(t, err)
+		require.Equal(t, []string{"read", "admin"}, scopes)
+
+		_, err = v.Validate(newTokenWithClaims(t, key1, claims, map[string]interface{}{
+			"scp": 123,
+		}))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid 'scp' claim")
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		_, err := v.Validate("invalid")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parse token")
+	})
+
+	t.Run("Invalid issuer", func(t *testing.T) {
+		claims := newClaims()
+		claims.Issuer = "https://other.example.com"
+
+		_, err := v.Validate(newToken(t, key1, claims, "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid issuer")
+	})
+
+	t.Run("Invalid audience", func(t *testing.T) {
+		claims := newClaims()
+		claims.Audience = jwt.Audience{"https://other.example.com"}
+
+		_, err := v.Validate(newToken(t, key1, claims, "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid audience")
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		claims := newClaims()
+		claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+
+		_, err := v.Validate(newToken(t, key1, claims, "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "token is expired")
+	})
+
+	t.Run("No expiry", func(t *testing.T) {
+		claims := newClaims()
+		claims.Expiry = nil
+
+		_, err := v.Validate(newToken(t, key1, claims, "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "token has no expiry")
+	})
+
+	t.Run("Invalid signature", func(t *testing.T) {
+		// Sign with key2 but use the key ID of key1.
+		k := &signingKey{JSONWebKey: key1.JSONWebKey, privateKey: key2.privateKey}
+
+		_, err := v.Validate(newToken(t, k, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "verify token")
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		_, err := v.Validate(newToken(t, key2, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "key [key2] not found in JWKS")
+	})
+}
+
+func TestJWTValidator_KeyRotation(t *testing.T) {
+	key1 := newSigningKey(t, "key1")
+	key2 := newSigningKey(t, "key2")
+
+	jwks := newJWKSServer(t, key1)
+	defer jwks.Close()
+
+	v := NewJWTValidator(JWTConfig{JWKS: jwks.URL, Issuer: issuer, Audience: audience}, http.DefaultClient)
+	v.minRefreshInterval = 50 * time.Millisecond
+
+	_, err := v.Validate(newToken(t, key1, newClaims(), "read"))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), jwks.requests())
+
+	jwks.setKeys(key1, key2)
+
+	// The key set was loaded recently so it isn't reloaded.
+	_, err = v.Validate(newToken(t, key2, newClaims(), "read"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not found in JWKS")
+	require.Equal(t, int32(1), jwks.requests())
+
+	time.Sleep(100 * time.Millisecond)
+
+	// The key set should be reloaded since the key ID is unknown.
+	_, err = v.Validate(newToken(t, key2, newClaims(), "read"))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), jwks.requests())
+
+	// Known keys don't cause a reload.
+	_, err = v.Validate(newToken(t, key1, newClaims(), "read"))
+	require.NoError(t, err)
+	require.Equal(t, int32(2), jwks.requests())
+
+	// The key set is reloaded after the refresh interval.
+	v.now = func() time.Time { return time.Now().Add(defaultJWKSRefreshInterval + time.Minute) }
+
+	claims := newClaims()
+	claims.Expiry = jwt.NewNumericDate(time.Now().Add(2 * defaultJWKSRefreshInterval))
+
+	_, err = v.Validate(newToken(t, key1, claims, "read"))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), jwks.requests())
+}
+
+func TestJWTValidator_File(t *testing.T) {
+	key := newSigningKey(t, "key1")
+
+	keySetBytes, err := json.Marshal(&jose.JSONWebKeySet{Keys: []jose.JSONWebKey{key.Public()}})
+	require.NoError(t, err)
+
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+
+	require.NoError(t, ioutil.WriteFile(jwksFile, keySetBytes, 0o600))
+
+	t.Run("Success", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: jwksFile}, nil)
+
+		scopes, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"read"}, scopes)
+	})
+
+	t.Run("File URL", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: "file://" + jwksFile}, nil)
+
+		_, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.NoError(t, err)
+	})
+
+	t.Run("No key ID with single key", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: jwksFile}, nil)
+
+		k := &signingKey{JSONWebKey: key.JSONWebKey, privateKey: key.privateKey}
+		k.KeyID = ""
+
+		_, err := v.Validate(newToken(t, k, newClaims(), "read"))
+		require.NoError(t, err)
+	})
+
+	t.Run("File not found", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: filepath.Join(t.TempDir(), "missing.json")}, nil)
+
+		_, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "load JWKS")
+	})
+
+	t.Run("Invalid JWKS", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: jwksFile}, nil)
+		v.readFile = func(string) ([]byte, error) { return []byte("{"), nil }
+
+		_, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unmarshal JWKS")
+	})
+}
+
+func TestJWTValidator_HTTPError(t *testing.T) {
+	key := newSigningKey(t, "key1")
+
+	t.Run("Status code", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		v := NewJWTValidator(JWTConfig{JWKS: server.URL}, http.DefaultClient)
+
+		_, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unexpected status code [500]")
+	})
+
+	t.Run("Client error", func(t *testing.T) {
+		v := NewJWTValidator(JWTConfig{JWKS: "https://idp.example.com/jwks"},
+			&mockHTTPClient{err: errors.New("injected client error")})
+
+		_, err := v.Validate(newToken(t, key, newClaims(), "read"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "injected client error")
+	})
+}
+
+type signingKey struct {
+	jose.JSONWebKey
+
+	privateKey *ecdsa.PrivateKey
+}
+
+func newSigningKey(t *testing.T, keyID string) *signingKey {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	return &signingKey{
+		JSONWebKey: jose.JSONWebKey{
+			Key:       &privateKey.PublicKey,
+			KeyID:     keyID,
+			Algorithm: string(jose.ES256),
+			Use:       "sig",
+		},
+		privateKey: privateKey,
+	}
+}
+
+func (k *signingKey) Public() jose.JSONWebKey {
+	return k.JSONWebKey
+}
+
+func newClaims() jwt.Claims {
+	now := time.Now()
+
+	return jwt.Claims{
+		Issuer:   issuer,
+		Subject:  "client1",
+		Audience: jwt.Audience{audience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+}
+
+func newToken(t *testing.T, key *signingKey, claims jwt.Claims, scope string) string {
+	t.Helper()
+
+	return newTokenWithClaims(t, key, claims, map[string]interface{}{"scope": scope})
+}
+
+func newTokenWithClaims(t *testing.T, key *signingKey, claims jwt.Claims, extra map[string]interface{}) string {
+	t.Helper()
+
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+
+	if key.KeyID != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), key.KeyID)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key.privateKey}, opts)
+	require.NoError(t, err)
+
+	token, err := jwt.Signed(signer).Claims(claims).Claims(extra).CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+// jwksServer is a local stand-in for the JWKS endpoint of an identity provider.
+type jwksServer struct {
+	*httptest.Server
+
+	keySet   atomic.Value
+	numCalls int32
+}
+
+func newJWKSServer(t *testing.T, keys ...*signingKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{}
+	s.setKeys(keys...)
+
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&s.numCalls, 1)
+
+		keySetBytes, err := json.Marshal(s.keySet.Load())
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+
+		_, err = w.Write(keySetBytes)
+		require.NoError(t, err)
+	}))
+
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...*signingKey) {
+	keySet := &jose.JSONWebKeySet{}
+
+	for _, k := range keys {
+		keySet.Keys = append(keySet.Keys, k.Public())
+	}
+
+	s.keySet.Store(keySet)
+}
+
+func (s *jwksServer) requests() int32 {
+	return atomic.LoadInt32(&s.numCalls)
+}
+
+type mockHTTPClient struct {
+	err error
+}
+
+func (m *mockHTTPClient) Do(*http.Request) (*http.Response, error) {
+	return nil, m.err
+}
@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
 	"github.com/trustbloc/edge-core/pkg/log"
 )
@@ -29,10 +30,21 @@ type TokenDef struct {
 	WriteTokens        []string
 }
 
+// BearerTokenValidator validates a bearer token (for example, a signed JWT) and returns the scopes that
+// are granted by the token.
+type BearerTokenValidator interface {
+	Validate(token string) ([]string, error)
+}
+
 // Config contains the authorization token configuration.
 type Config struct {
 	AuthTokensDef []*TokenDef
 	AuthTokens    map[string]string
+
+	// TokenValidator is optional. If set then a bearer token that doesn't match any of the static tokens
+	// is passed to the validator and the request is authorized if one of the scopes granted by the token
+	// matches one of the token IDs (e.g. "read" or "admin") required by the endpoint.
+	TokenValidator BearerTokenValidator
 }
 
 // TokenVerifier authorizes requests with bearer tokens.
@@ -40,12 +52,14 @@ type TokenVerifier struct {
 	Config
 
 	endpoint   string
+	tokenIDs   []string
 	authTokens []string
 }
 
 // NewTokenVerifier returns a verifier that performs bearer token authorization.
 func NewTokenVerifier(cfg Config, endpoint, method string) *TokenVerifier {
-	authTokens, err := resolveAuthTokens(endpoint, method, cfg.AuthTokensDef, cfg.AuthTokens)
+	tokenIDs, authTokens, err := resolveAuthTokens(endpoint, method, cfg.AuthTokensDef, cfg.AuthTokens,
+		cfg.TokenValidator != nil)
 	if err != nil {
 		// This would occur on startup due to bad configuration, so it's better to panic.
 		panic(fmt.Errorf("resolve authorization tokens: %w", err))
@@ -54,20 +68,26 @@ func NewTokenVerifier(cfg Config, endpoint, method string) *TokenVerifier {
 	return &TokenVerifier{
 		Config:     cfg,
 		endpoint:   endpoint,
+		tokenIDs:   tokenIDs,
 		authTokens: authTokens,
 	}
 }
 
+// TokenRequired returns true if a bearer token is required in order to access the endpoint.
+func (h *TokenVerifier) TokenRequired() bool {
+	return len(h.tokenIDs) > 0
+}
+
 // Verify verifies that the request has the required bearer token. If not, false is returned.
 func (h *TokenVerifier) Verify(req *http.Request) bool {
-	if len(h.authTokens) == 0 {
+	if len(h.tokenIDs) == 0 {
 		// Open access.
 		logger.Debugf("[%s] No auth token required.", h.endpoint)
 
 		return true
 	}
 
-	logger.Debugf("[%s] Auth tokens required: %s", h.endpoint, h.authTokens)
+	logger.Debugf("[%s] Auth tokens required: %s", h.endpoint, h.tokenIDs)
 
 	actHdr := req.Header.Get(authHeader)
 	if actHdr == "" {
@@ -87,17 +107,55 @@ func (h *TokenVerifier) Verify(req *http.Request) bool {
 		}
 	}
 
+	if h.TokenValidator == nil {
+		return false
+	}
+
+	return h.verifyScopes(actHdr)
+}
+
+// verifyScopes validates the bearer token with the token validator and returns true if the token
+// grants a scope that matches one of the token IDs required by the endpoint.
+func (h *TokenVerifier) verifyScopes(actHdr string) bool {
+	if !strings.HasPrefix(actHdr, tokenPrefix) {
+		logger.Debugf("[%s] Authorization header is not a bearer token", h.endpoint)
+
+		return false
+	}
+
+	scopes, err := h.TokenValidator.Validate(strings.TrimPrefix(actHdr, tokenPrefix))
+	if err != nil {
+		logger.Debugf("[%s] Invalid bearer token: %s", h.endpoint, err)
+
+		return false
+	}
+
+	for _, scope := range scopes {
+		for _, tokenID := range h.tokenIDs {
+			if scope == tokenID {
+				logger.Debugf("[%s] Found scope %s in bearer token", h.endpoint, scope)
+
+				return true
+			}
+		}
+	}
+
+	logger.Debugf("[%s] Bearer token scopes %s do not match required scopes %s", h.endpoint, scopes, h.tokenIDs)
+
 	return false
 }
 
+// resolveAuthTokens returns the IDs of the tokens required by the given endpoint along with the
+// static token values. If allowMissing is true then a token ID without a static value is allowed
+// since the token may be validated by a token validator.
 func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
-	authTokenMap map[string]string) ([]string, error) {
-	var authTokens []string
+	authTokenMap map[string]string, allowMissing bool) ([]string, []string, error) {
+	var tokenIDs, authTokens []string
 
 	for _, def := range authTokensDef {
 		ok, err := endpointMatches(endpoint, def.EndpointExpression)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		if !ok {
@@ -106,16 +164,24 @@ func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
 
 		var tokens []string
 
-		if method == http.MethodPost {
-			tokens = def.WriteTokens
-		} else {
+		// Any method other than GET or HEAD (e.g. POST or DELETE) may modify state and therefore requires
+		// a write token.
+		if method == http.MethodGet || method == http.MethodHead {
 			tokens = def.ReadTokens
+		} else {
+			tokens = def.WriteTokens
 		}
 
 		for _, tokenID := range tokens {
+			tokenIDs = append(tokenIDs, tokenID)
+
 			token, ok := authTokenMap[tokenID]
 			if !ok {
-				return nil, fmt.Errorf("token not found: %s", tokenID)
+				if allowMissing {
+					continue
+				}
+
+				return nil, nil, fmt.Errorf("token not found: %s", tokenID)
 			}
 
 			authTokens = append(authTokens, token)
@@ -124,9 +190,9 @@ func resolveAuthTokens(endpoint, method string, authTokensDef []*TokenDef,
 		break
 	}
 
-	logger.Debugf("[%s] Authorization tokens: %s", endpoint, authTokens)
+	logger.Debugf("[%s] Authorization tokens: %s", endpoint, tokenIDs)
 
-	return authTokens, nil
+	return tokenIDs, authTokens, nil
 }
 
 func endpointMatches(endpoint, pattern string) (bool, error) {
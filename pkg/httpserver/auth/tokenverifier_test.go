@@ -7,6 +7,7 @@ SPDX-License-Identifier: Apache-2.0
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -67,6 +68,21 @@ func TestTokenVerifier(t *testing.T) {
 		require.True(t, v.Verify(req))
 	})
 
+	t.Run("DELETE requires write token", func(t *testing.T) {
+		v := NewTokenVerifier(cfg, "/services/orb/outbox", http.MethodDelete)
+		require.NotNil(t, v)
+
+		req := httptest.NewRequest(http.MethodDelete, "/services/orb/outbox", nil)
+		req.Header[authHeader] = []string{tokenPrefix + "READ_TOKEN"}
+
+		require.False(t, v.Verify(req))
+
+		req = httptest.NewRequest(http.MethodDelete, "/services/orb/outbox", nil)
+		req.Header[authHeader] = []string{tokenPrefix + "ADMIN_TOKEN"}
+
+		require.True(t, v.Verify(req))
+	})
+
 	t.Run("GET with no auth token -> unauthorized", func(t *testing.T) {
 		v := NewTokenVerifier(cfg, "/services/orb/outbox", http.MethodGet)
 		require.NotNil(t, v)
@@ -94,4 +110,82 @@ func TestTokenVerifier(t *testing.T) {
 
 		require.True(t, v.Verify(req))
 	})
+
+	t.Run("Token validator", func(t *testing.T) {
+		c := Config{
+			AuthTokensDef: []*TokenDef{
+				{
+					EndpointExpression: "/services/orb/outbox",
+					ReadTokens:         []string{"admin", "read"},
+					WriteTokens:        []string{"admin"},
+				},
+			},
+			AuthTokens: map[string]string{
+				"admin": "ADMIN_TOKEN",
+			},
+			TokenValidator: &mockValidator{
+				tokens: map[string][]string{
+					"READ_JWT":  {"read"},
+					"ADMIN_JWT": {"openid", "admin"},
+				},
+			},
+		}
+
+		// The "read" token has no static value but it doesn't panic since a token validator is configured.
+		vGet := NewTokenVerifier(c, "/services/orb/outbox", http.MethodGet)
+		vPost := NewTokenVerifier(c, "/services/orb/outbox", http.MethodPost)
+
+		t.Run("Static token -> success", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{tokenPrefix + "ADMIN_TOKEN"}
+
+			require.True(t, vPost.Verify(req))
+		})
+
+		t.Run("Token with required scope -> success", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{tokenPrefix + "READ_JWT"}
+
+			require.True(t, vGet.Verify(req))
+
+			req = httptest.NewRequest(http.MethodPost, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{tokenPrefix + "ADMIN_JWT"}
+
+			require.True(t, vPost.Verify(req))
+		})
+
+		t.Run("Token without required scope -> unauthorized", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{tokenPrefix + "READ_JWT"}
+
+			require.False(t, vPost.Verify(req))
+		})
+
+		t.Run("Invalid token -> unauthorized", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{tokenPrefix + "INVALID_JWT"}
+
+			require.False(t, vGet.Verify(req))
+		})
+
+		t.Run("Not a bearer token -> unauthorized", func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/services/orb/outbox", nil)
+			req.Header[authHeader] = []string{"Basic READ_JWT"}
+
+			require.False(t, vGet.Verify(req))
+		})
+	})
+}
+
+type mockValidator struct {
+	tokens map[string][]string
+}
+
+func (m *mockValidator) Validate(token string) ([]string, error) {
+	scopes, ok := m.tokens[token]
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+
+	return scopes, nil
 }
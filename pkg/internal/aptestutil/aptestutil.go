@@ -17,7 +17,8 @@ import (
 
 // ServiceOptions are options passed in to NewMockService.
 type ServiceOptions struct {
-	PublicKey *vocab.PublicKeyType
+	PublicKey  *vocab.PublicKeyType
+	PublicKeys []*vocab.PublicKeyType
 }
 
 // ServiceOpt is a mock service option.
@@ -30,6 +31,13 @@ func WithPublicKey(pubKey *vocab.PublicKeyType) ServiceOpt {
 	}
 }
 
+// WithPublicKeys sets additional public keys on the mock service.
+func WithPublicKeys(pubKeys ...*vocab.PublicKeyType) ServiceOpt {
+	return func(options *ServiceOptions) {
+		options.PublicKeys = pubKeys
+	}
+}
+
 // NewMockService returns a mock 'Service' type actor with the given IRI and options.
 func NewMockService(serviceIRI *url.URL, opts ...ServiceOpt) *vocab.ActorType {
 	options := &ServiceOptions{
@@ -50,6 +58,7 @@ func NewMockService(serviceIRI *url.URL, opts ...ServiceOpt) *vocab.ActorType {
 
 	return vocab.NewService(serviceIRI,
 		vocab.WithPublicKey(options.PublicKey),
+		vocab.WithPublicKeys(options.PublicKeys...),
 		vocab.WithInbox(inbox),
 		vocab.WithOutbox(outbox),
 		vocab.WithFollowers(followers),
@@ -23,6 +23,7 @@ const (
 	apResolveInboxesTimeMetric    = "outbox_resolve_inboxes_seconds"
 	apInboxHandlerTimeMetric      = "inbox_handler_seconds"
 	apOutboxActivityCounterMetric = "outbox_count"
+	apHTTPSigRejectedMetric       = "httpsig_rejected_count"
 
 	// Anchor.
 	anchor                                         = "anchor"
@@ -41,6 +42,11 @@ const (
 	anchorWriteSignLocalStoreTimeMetric            = "write_sign_local_store_seconds"
 	anchorWriteSignLocalWatchTimeMetric            = "write_sign_local_watch_seconds"
 	anchorWriteResolveHostMetaLinkTimeMetric       = "write_resolve_host_meta_link_seconds"
+	anchorExpiredRewitnessCountMetric              = "expired_rewitness_count"
+	anchorExpiredAbandonCountMetric                = "expired_abandon_count"
+	anchorExpiredRequeuedOperationsCountMetric     = "expired_requeued_operations_count"
+	anchorGraphCacheHitCountMetric                 = "graph_cache_hit_count"
+	anchorGraphCacheMissCountMetric                = "graph_cache_miss_count"
 
 	// Operation queue.
 	operationQueue                 = "opqueue"
@@ -55,6 +61,7 @@ const (
 	observer                        = "observer"
 	observerProcessAnchorTimeMetric = "process_anchor_seconds"
 	observerProcessDIDTimeMetric    = "process_did_seconds"
+	observerAnchorForkCountMetric   = "anchor_fork_count"
 
 	// CAS.
 	cas                    = "cas"
@@ -63,10 +70,14 @@ const (
 	casCacheHitCountMetric = "cache_hit_count"
 	casReadTimeMetric      = "read_seconds"
 
+	casSourceResolveTimeMetric  = "source_resolve_seconds"
+	casSourceResolveCountMetric = "source_resolve_count"
+
 	// Document handler.
 	document                  = "document"
 	docCreateUpdateTimeMetric = "create_update_seconds"
 	docResolveTimeMetric      = "resolve_seconds"
+	docUpdateRejectedMetric   = "update_rejected_count"
 
 	// DB.
 	db                  = "db"
@@ -86,12 +97,20 @@ const (
 	vctWitnessVerifyVCTTimeMetric        = "witness_verify_vct_signature_seconds"
 	vctAddProofParseCredentialTimeMetric = "witness_add_proof_parse_credential_seconds"
 	vctAddProofSignTimeMetric            = "witness_add_proof_sign_seconds"
+	vctLogIncidentCountMetric            = "log_incident_count"
 
 	// Signer.
 	signer                         = "signer"
 	signerGetKeyTimeMetric         = "get_key_seconds"
 	signerSignMetric               = "sign_seconds"
 	signerAddLinkedDataProofMetric = "add_linked_data_proof_seconds"
+
+	// Admission control.
+	admission                          = "admission"
+	admissionStateMetric               = "state"
+	admissionWitnessResponseRateMetric = "witness_response_rate"
+	admissionCASErrorRateMetric        = "cas_error_rate"
+	admissionRejectedCountMetric       = "rejected_count"
 )
 
 var logger = log.New("metrics")
@@ -107,6 +126,7 @@ type Metrics struct {
 	apOutboxResolveInboxesTime prometheus.Histogram
 	apInboxHandlerTimes        map[string]prometheus.Histogram
 	apOutboxActivityCounts     map[string]prometheus.Counter
+	apHTTPSigRejectedCounts    map[string]prometheus.Counter
 
 	anchorWriteTime                          prometheus.Histogram
 	anchorWitnessTime                        prometheus.Histogram
@@ -123,6 +143,11 @@ type Metrics struct {
 	anchorWriteSignLocalStoreTime            prometheus.Histogram
 	anchorWriteSignLocalWatchTime            prometheus.Histogram
 	anchorWriteResolveHostMetaLinkTime       prometheus.Histogram
+	anchorExpiredRewitnessCount              prometheus.Counter
+	anchorExpiredAbandonCount                prometheus.Counter
+	anchorExpiredRequeuedOperationsCount     prometheus.Counter
+	anchorGraphCacheHitCount                 prometheus.Counter
+	anchorGraphCacheMissCount                prometheus.Counter
 
 	opqueueAddOperationTime  prometheus.Histogram
 	opqueueBatchCutTime      prometheus.Histogram
@@ -133,14 +158,19 @@ type Metrics struct {
 
 	observerProcessAnchorTime prometheus.Histogram
 	observerProcessDIDTime    prometheus.Histogram
+	observerAnchorForkCount   prometheus.Counter
 
 	casWriteTime     prometheus.Histogram
 	casResolveTime   prometheus.Histogram
 	casCacheHitCount prometheus.Counter
 	casReadTimes     map[string]prometheus.Histogram
 
+	casSourceResolveTimes  map[string]prometheus.Histogram
+	casSourceResolveCounts map[string]map[bool]prometheus.Counter
+
 	docCreateUpdateTime prometheus.Histogram
 	docResolveTime      prometheus.Histogram
+	docUpdateRejected   map[string]prometheus.Counter
 
 	dbPutTimes     map[string]prometheus.Histogram
 	dbGetTimes     map[string]prometheus.Histogram
@@ -156,9 +186,15 @@ type Metrics struct {
 	vctWitnessVerifyVCTimes         prometheus.Histogram
 	vctAddProofParseCredentialTimes prometheus.Histogram
 	vctAddProofSignTimes            prometheus.Histogram
+	vctLogIncidentCounts            map[string]prometheus.Counter
 	signerGetKeyTimes               prometheus.Histogram
 	signerSignTimes                 prometheus.Histogram
 	signerAddLinkedDataProofTimes   prometheus.Histogram
+
+	admissionState               prometheus.Gauge
+	admissionWitnessResponseRate prometheus.Gauge
+	admissionCASErrorRate        prometheus.Gauge
+	admissionRejectedCount       prometheus.Counter
 }
 
 // Get returns an Orb metrics provider.
@@ -173,6 +209,10 @@ func Get() *Metrics {
 func newMetrics() *Metrics { //nolint:funlen
 	activityTypes := []string{"Create", "Announce", "Offer", "Like", "Follow", "InviteWitness", "Accept", "Reject"}
 	dbTypes := []string{"CouchDB", "MongoDB"}
+	incidentTypes := []string{"split_view", "missing_inclusion", "gossip_conflict"}
+	casSources := []string{"local", "webcas", "ipfs", "domain"}
+	updateRejectReasons := []string{"did_rate_limit", "client_rate_limit", "duplicate"}
+	httpSigRejectReasons := []string{"clock_skew", "digest", "replay"}
 
 	m := &Metrics{
 		apOutboxPostTime:                         newOutboxPostTime(),
@@ -192,6 +232,11 @@ func newMetrics() *Metrics { //nolint:funlen
 		anchorWriteSignLocalStoreTime:            newAnchorWriteSignLocalStoreTime(),
 		anchorWriteSignLocalWatchTime:            newAnchorWriteSignLocalWatchTime(),
 		anchorWriteResolveHostMetaLinkTime:       newAnchorWriteResolveHostMetaLinkTime(),
+		anchorExpiredRewitnessCount:              newAnchorExpiredRewitnessCount(),
+		anchorExpiredAbandonCount:                newAnchorExpiredAbandonCount(),
+		anchorExpiredRequeuedOperationsCount:     newAnchorExpiredRequeuedOperationsCount(),
+		anchorGraphCacheHitCount:                 newAnchorGraphCacheHitCount(),
+		anchorGraphCacheMissCount:                newAnchorGraphCacheMissCount(),
 		opqueueAddOperationTime:                  newOpQueueAddOperationTime(),
 		opqueueBatchCutTime:                      newOpQueueBatchCutTime(),
 		opqueueBatchRollbackTime:                 newOpQueueBatchRollbackTime(),
@@ -200,14 +245,19 @@ func newMetrics() *Metrics { //nolint:funlen
 		opqueueBatchSize:                         newOpQueueBatchSize(),
 		observerProcessAnchorTime:                newObserverProcessAnchorTime(),
 		observerProcessDIDTime:                   newObserverProcessDIDTime(),
+		observerAnchorForkCount:                  newObserverAnchorForkCount(),
 		casWriteTime:                             newCASWriteTime(),
 		casResolveTime:                           newCASResolveTime(),
 		casReadTimes:                             newCASReadTimes(),
 		casCacheHitCount:                         newCASCacheHitCount(),
+		casSourceResolveTimes:                    newCASSourceResolveTimes(casSources),
+		casSourceResolveCounts:                   newCASSourceResolveCounts(casSources),
 		docCreateUpdateTime:                      newDocCreateUpdateTime(),
 		docResolveTime:                           newDocResolveTime(),
+		docUpdateRejected:                        newDocUpdateRejectedCounts(updateRejectReasons),
 		apInboxHandlerTimes:                      newInboxHandlerTimes(activityTypes),
 		apOutboxActivityCounts:                   newOutboxActivityCounts(activityTypes),
+		apHTTPSigRejectedCounts:                  newHTTPSignatureRejectedCounts(httpSigRejectReasons),
 		dbPutTimes:                               newDBPutTime(dbTypes),
 		dbGetTimes:                               newDBGetTime(dbTypes),
 		dbGetTagsTimes:                           newDBGetTagsTime(dbTypes),
@@ -221,9 +271,14 @@ func newMetrics() *Metrics { //nolint:funlen
 		vctWitnessVerifyVCTimes:                  newVCTWitnessVerifyVCTTime(),
 		vctAddProofParseCredentialTimes:          newVCTAddProofParseCredentialTime(),
 		vctAddProofSignTimes:                     newVCTAddProofSignTime(),
+		vctLogIncidentCounts:                     newVCTLogIncidentCounts(incidentTypes),
 		signerGetKeyTimes:                        newSignerGetKeyTime(),
 		signerSignTimes:                          newSignerSignTime(),
 		signerAddLinkedDataProofTimes:            newSignerAddLinkedDataProofTime(),
+		admissionState:                           newAdmissionState(),
+		admissionWitnessResponseRate:             newAdmissionWitnessResponseRate(),
+		admissionCASErrorRate:                    newAdmissionCASErrorRate(),
+		admissionRejectedCount:                   newAdmissionRejectedCount(),
 	}
 
 	prometheus.MustRegister(
@@ -235,19 +290,26 @@ func newMetrics() *Metrics { //nolint:funlen
 		m.anchorWriteSignLocalStoreTime, m.anchorWriteSignLocalWatchTime,
 		m.opqueueAddOperationTime, m.opqueueBatchCutTime, m.opqueueBatchRollbackTime,
 		m.opqueueBatchAckTime, m.opqueueBatchNackTime, m.opqueueBatchSize,
-		m.observerProcessAnchorTime, m.observerProcessDIDTime,
+		m.observerProcessAnchorTime, m.observerProcessDIDTime, m.observerAnchorForkCount,
 		m.casWriteTime, m.casResolveTime, m.casCacheHitCount,
 		m.docCreateUpdateTime, m.docResolveTime,
 		m.vctWitnessAddProofVCTNilTimes, m.vctWitnessAddVCTimes, m.vctWitnessAddProofTimes,
 		m.vctWitnessAddWebFingerTimes, m.vctWitnessVerifyVCTimes, m.vctAddProofParseCredentialTimes,
 		m.vctAddProofSignTimes, m.signerSignTimes, m.signerGetKeyTimes, m.signerAddLinkedDataProofTimes,
 		m.anchorWriteResolveHostMetaLinkTime,
+		m.anchorExpiredRewitnessCount, m.anchorExpiredAbandonCount, m.anchorExpiredRequeuedOperationsCount,
+		m.anchorGraphCacheHitCount, m.anchorGraphCacheMissCount,
+		m.admissionState, m.admissionWitnessResponseRate, m.admissionCASErrorRate, m.admissionRejectedCount,
 	)
 
 	for _, c := range m.apInboxHandlerTimes {
 		prometheus.MustRegister(c)
 	}
 
+	for _, c := range m.apHTTPSigRejectedCounts {
+		prometheus.MustRegister(c)
+	}
+
 	for _, c := range m.dbPutTimes {
 		prometheus.MustRegister(c)
 	}
@@ -268,10 +330,28 @@ func newMetrics() *Metrics { //nolint:funlen
 		prometheus.MustRegister(c)
 	}
 
+	for _, c := range m.vctLogIncidentCounts {
+		prometheus.MustRegister(c)
+	}
+
+	for _, c := range m.docUpdateRejected {
+		prometheus.MustRegister(c)
+	}
+
 	for _, c := range m.casReadTimes {
 		prometheus.MustRegister(c)
 	}
 
+	for _, c := range m.casSourceResolveTimes {
+		prometheus.MustRegister(c)
+	}
+
+	for _, counters := range m.casSourceResolveCounts {
+		for _, c := range counters {
+			prometheus.MustRegister(c)
+		}
+	}
+
 	return m
 }
 
@@ -305,6 +385,16 @@ func (m *Metrics) OutboxIncrementActivityCount(activityType string) {
 	}
 }
 
+// HTTPSignatureRejected increments the number of signed ActivityPub requests that were rejected for the
+// given reason (clock_skew, digest or replay).
+func (m *Metrics) HTTPSignatureRejected(reason string) {
+	if c, ok := m.apHTTPSigRejectedCounts[reason]; ok {
+		c.Inc()
+	}
+
+	logger.Debugf("HTTP signature rejected: %s", reason)
+}
+
 // WriteAnchorTime records the time it takes to write an anchor credential and post an 'Offer' activity.
 func (m *Metrics) WriteAnchorTime(value time.Duration) {
 	m.anchorWriteTime.Observe(value.Seconds())
@@ -396,6 +486,35 @@ func (m *Metrics) WriteAnchorResolveHostMetaLinkTime(value time.Duration) {
 	logger.Debugf("WriteAnchor resolve host meta link time: %s", value)
 }
 
+// ExpiredAnchorRewitnessed increments the number of expired anchor credentials that were offered to
+// an alternate set of witnesses.
+func (m *Metrics) ExpiredAnchorRewitnessed() {
+	m.anchorExpiredRewitnessCount.Inc()
+}
+
+// ExpiredAnchorAbandoned increments the number of expired anchor credentials that were abandoned.
+func (m *Metrics) ExpiredAnchorAbandoned() {
+	m.anchorExpiredAbandonCount.Inc()
+}
+
+// ExpiredAnchorOperationsRequeued records the number of operations from abandoned anchor credentials
+// that were added back to the operation queue.
+func (m *Metrics) ExpiredAnchorOperationsRequeued(count int) {
+	m.anchorExpiredRequeuedOperationsCount.Add(float64(count))
+
+	logger.Debugf("ExpiredAnchorOperationsRequeued: %d", count)
+}
+
+// AnchorGraphIncrementCacheHitCount increments the number of anchor graph cache hits.
+func (m *Metrics) AnchorGraphIncrementCacheHitCount() {
+	m.anchorGraphCacheHitCount.Inc()
+}
+
+// AnchorGraphIncrementCacheMissCount increments the number of anchor graph cache misses.
+func (m *Metrics) AnchorGraphIncrementCacheMissCount() {
+	m.anchorGraphCacheMissCount.Inc()
+}
+
 // WitnessAnchorCredentialTime records the time it takes for a verifiable credential to gather proofs from all
 // required witnesses (according to witness policy). The start time is when the verifiable credential is issued
 // and the end time is the time that the witness policy is satisfied.
@@ -472,6 +591,13 @@ func (m *Metrics) ProcessDIDTime(value time.Duration) {
 	logger.Infof("ProcessDID time: %s", value)
 }
 
+// AnchorForkDetected increments the number of forks detected in DID anchor chains.
+func (m *Metrics) AnchorForkDetected() {
+	m.observerAnchorForkCount.Inc()
+
+	logger.Debugf("Anchor fork detected")
+}
+
 // CASWriteTime records the time it takes to write a document to CAS.
 func (m *Metrics) CASWriteTime(value time.Duration) {
 	m.casWriteTime.Observe(value.Seconds())
@@ -498,6 +624,24 @@ func (m *Metrics) CASReadTime(casType string, value time.Duration) {
 	}
 }
 
+// CASSourceResolveTime records the time it takes to retrieve a document from the given CAS source
+// (local, webcas, ipfs or domain).
+func (m *Metrics) CASSourceResolveTime(source string, value time.Duration) {
+	if c, ok := m.casSourceResolveTimes[source]; ok {
+		c.Observe(value.Seconds())
+	}
+
+	logger.Debugf("CAS resolve time for source [%s]: %s", source, value)
+}
+
+// CASSourceResolveResult increments the number of successful or failed attempts to retrieve a document from
+// the given CAS source (local, webcas, ipfs or domain).
+func (m *Metrics) CASSourceResolveResult(source string, success bool) {
+	if c, ok := m.casSourceResolveCounts[source][success]; ok {
+		c.Inc()
+	}
+}
+
 // DocumentCreateUpdateTime records the time it takes the REST handler to process a create/update operation.
 func (m *Metrics) DocumentCreateUpdateTime(value time.Duration) {
 	m.docCreateUpdateTime.Observe(value.Seconds())
@@ -512,6 +656,16 @@ func (m *Metrics) DocumentResolveTime(value time.Duration) {
 	logger.Debugf("DocumentResolve time: %s", value)
 }
 
+// DocumentUpdateRejected increments the number of create/update operations that were rejected by the REST
+// handler for the given reason (did_rate_limit, client_rate_limit or duplicate).
+func (m *Metrics) DocumentUpdateRejected(reason string) {
+	if c, ok := m.docUpdateRejected[reason]; ok {
+		c.Inc()
+	}
+
+	logger.Debugf("Document update rejected: %s", reason)
+}
+
 // DBPutTime records the time it takes to store data in db.
 func (m *Metrics) DBPutTime(dbType string, value time.Duration) {
 	if c, ok := m.dbPutTimes[dbType]; ok {
@@ -603,6 +757,16 @@ func (m *Metrics) AddProofSign(value time.Duration) {
 	logger.Debugf("vct sign add proof: %s", value)
 }
 
+// VCTLogIncident increments the number of incidents of the given type (split view, missing inclusion)
+// that were detected in VCT logs.
+func (m *Metrics) VCTLogIncident(incidentType string) {
+	if c, ok := m.vctLogIncidentCounts[incidentType]; ok {
+		c.Inc()
+	}
+
+	logger.Debugf("VCT log incident: %s", incidentType)
+}
+
 // SignerGetKey records get key time.
 func (m *Metrics) SignerGetKey(value time.Duration) {
 	m.signerGetKeyTimes.Observe(value.Seconds())
@@ -624,6 +788,26 @@ func (m *Metrics) SignerSign(value time.Duration) {
 	logger.Debugf("signer sign time: %s", value)
 }
 
+// AdmissionControlState sets the current admission control state (0=healthy, 1=throttled, 2=rejecting).
+func (m *Metrics) AdmissionControlState(value float64) {
+	m.admissionState.Set(value)
+}
+
+// AdmissionControlWitnessResponseRate sets the witness response rate calculated by the admission controller.
+func (m *Metrics) AdmissionControlWitnessResponseRate(value float64) {
+	m.admissionWitnessResponseRate.Set(value)
+}
+
+// AdmissionControlCASErrorRate sets the CAS write error rate calculated by the admission controller.
+func (m *Metrics) AdmissionControlCASErrorRate(value float64) {
+	m.admissionCASErrorRate.Set(value)
+}
+
+// AdmissionControlIncrementRejectedCount increments the number of operations rejected by the admission controller.
+func (m *Metrics) AdmissionControlIncrementRejectedCount() {
+	m.admissionRejectedCount.Inc()
+}
+
 func newCounter(subsystem, name, help string, labels prometheus.Labels) prometheus.Counter {
 	return prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace:   namespace,
@@ -697,6 +881,34 @@ func newOutboxActivityCounts(activityTypes []string) map[string]prometheus.Count
 	return counters
 }
 
+func newHTTPSignatureRejectedCounts(reasons []string) map[string]prometheus.Counter {
+	counters := make(map[string]prometheus.Counter)
+
+	for _, reason := range reasons {
+		counters[reason] = newCounter(
+			activityPub, apHTTPSigRejectedMetric,
+			"The number of signed requests that were rejected due to clock skew, an invalid digest or a replay.",
+			prometheus.Labels{"reason": reason},
+		)
+	}
+
+	return counters
+}
+
+func newVCTLogIncidentCounts(incidentTypes []string) map[string]prometheus.Counter {
+	counters := make(map[string]prometheus.Counter)
+
+	for _, incidentType := range incidentTypes {
+		counters[incidentType] = newCounter(
+			vct, vctLogIncidentCountMetric,
+			"The number of incidents (split view, missing inclusion) detected in VCT logs.",
+			prometheus.Labels{"type": incidentType},
+		)
+	}
+
+	return counters
+}
+
 func newAnchorWriteTime() prometheus.Histogram {
 	return newHistogram(
 		anchor, anchorWriteTimeMetric,
@@ -885,6 +1097,15 @@ func newObserverProcessDIDTime() prometheus.Histogram {
 	)
 }
 
+func newObserverAnchorForkCount() prometheus.Counter {
+	return newCounter(
+		observer, observerAnchorForkCountMetric,
+		"The number of forks detected in DID anchor chains, i.e. different anchors that claim the same "+
+			"previous anchor for a DID.",
+		nil,
+	)
+}
+
 func newCASWriteTime() prometheus.Histogram {
 	return newHistogram(
 		cas, casWriteTimeMetric,
@@ -912,7 +1133,7 @@ func newCASCacheHitCount() prometheus.Counter {
 func newCASReadTimes() map[string]prometheus.Histogram {
 	times := make(map[string]prometheus.Histogram)
 
-	for _, casType := range []string{"local", "ipfs"} {
+	for _, casType := range []string{"local", "ipfs", "s3"} {
 		times[casType] = newHistogram(
 			cas, casReadTimeMetric,
 			"The time (in seconds) that it takes to read a document from the CAS storage.",
@@ -923,6 +1144,41 @@ func newCASReadTimes() map[string]prometheus.Histogram {
 	return times
 }
 
+func newCASSourceResolveTimes(sources []string) map[string]prometheus.Histogram {
+	times := make(map[string]prometheus.Histogram)
+
+	for _, source := range sources {
+		times[source] = newHistogram(
+			cas, casSourceResolveTimeMetric,
+			"The time (in seconds) that it takes to retrieve a document from a CAS source.",
+			prometheus.Labels{"source": source},
+		)
+	}
+
+	return times
+}
+
+func newCASSourceResolveCounts(sources []string) map[string]map[bool]prometheus.Counter {
+	counters := make(map[string]map[bool]prometheus.Counter)
+
+	for _, source := range sources {
+		counters[source] = map[bool]prometheus.Counter{
+			true: newCounter(
+				cas, casSourceResolveCountMetric,
+				"The number of attempts to retrieve a document from a CAS source.",
+				prometheus.Labels{"source": source, "result": "success"},
+			),
+			false: newCounter(
+				cas, casSourceResolveCountMetric,
+				"The number of attempts to retrieve a document from a CAS source.",
+				prometheus.Labels{"source": source, "result": "failure"},
+			),
+		}
+	}
+
+	return counters
+}
+
 func newDocCreateUpdateTime() prometheus.Histogram {
 	return newHistogram(
 		document, docCreateUpdateTimeMetric,
@@ -931,6 +1187,20 @@ func newDocCreateUpdateTime() prometheus.Histogram {
 	)
 }
 
+func newDocUpdateRejectedCounts(reasons []string) map[string]prometheus.Counter {
+	counters := make(map[string]prometheus.Counter)
+
+	for _, reason := range reasons {
+		counters[reason] = newCounter(
+			document, docUpdateRejectedMetric,
+			"The number of create/update operations that were rejected due to rate limiting or duplication.",
+			prometheus.Labels{"reason": reason},
+		)
+	}
+
+	return counters
+}
+
 func newDocResolveTime() prometheus.Histogram {
 	return newHistogram(
 		document, docResolveTimeMetric,
@@ -1023,6 +1293,46 @@ func newDBDeleteTime(dbTypes []string) map[string]prometheus.Histogram {
 	return counters
 }
 
+func newAnchorExpiredRewitnessCount() prometheus.Counter {
+	return newCounter(
+		anchor, anchorExpiredRewitnessCountMetric,
+		"The number of expired anchor credentials that were offered to an alternate set of witnesses.",
+		nil,
+	)
+}
+
+func newAnchorExpiredAbandonCount() prometheus.Counter {
+	return newCounter(
+		anchor, anchorExpiredAbandonCountMetric,
+		"The number of expired anchor credentials that were abandoned.",
+		nil,
+	)
+}
+
+func newAnchorExpiredRequeuedOperationsCount() prometheus.Counter {
+	return newCounter(
+		anchor, anchorExpiredRequeuedOperationsCountMetric,
+		"The number of operations from abandoned anchor credentials that were added back to the operation queue.",
+		nil,
+	)
+}
+
+func newAnchorGraphCacheHitCount() prometheus.Counter {
+	return newCounter(
+		anchor, anchorGraphCacheHitCountMetric,
+		"The number of times a parsed anchor credential was found in the anchor graph cache.",
+		nil,
+	)
+}
+
+func newAnchorGraphCacheMissCount() prometheus.Counter {
+	return newCounter(
+		anchor, anchorGraphCacheMissCountMetric,
+		"The number of times an anchor credential had to be resolved and parsed by the anchor graph.",
+		nil,
+	)
+}
+
 func newVCTWitnessAddProofVCTNilTime() prometheus.Histogram {
 	return newHistogram(
 		vct, vctWitnessAddProofVCTNilTimeMetric,
@@ -1102,3 +1412,32 @@ func newSignerAddLinkedDataProofTime() prometheus.Histogram {
 		nil,
 	)
 }
+
+func newAdmissionState() prometheus.Gauge {
+	return newGauge(
+		admission, admissionStateMetric,
+		"The admission control state (0=healthy, 1=throttled, 2=rejecting).",
+	)
+}
+
+func newAdmissionWitnessResponseRate() prometheus.Gauge {
+	return newGauge(
+		admission, admissionWitnessResponseRateMetric,
+		"The rate (0-1) at which witnesses responded to requests for proofs within the admission control window.",
+	)
+}
+
+func newAdmissionCASErrorRate() prometheus.Gauge {
+	return newGauge(
+		admission, admissionCASErrorRateMetric,
+		"The rate (0-1) at which CAS writes failed within the admission control window.",
+	)
+}
+
+func newAdmissionRejectedCount() prometheus.Counter {
+	return newCounter(
+		admission, admissionRejectedCountMetric,
+		"The number of operations that were rejected by the admission controller.",
+		nil,
+	)
+}
@@ -38,6 +38,11 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.WriteAnchorSignLocalWatchTime(time.Second) })
 		require.NotPanics(t, func() { m.WriteAnchorResolveHostMetaLinkTime(time.Second) })
 		require.NotPanics(t, func() { m.ProcessWitnessedAnchorCredentialTime(time.Second) })
+		require.NotPanics(t, func() { m.ExpiredAnchorRewitnessed() })
+		require.NotPanics(t, func() { m.ExpiredAnchorAbandoned() })
+		require.NotPanics(t, func() { m.ExpiredAnchorOperationsRequeued(10) })
+		require.NotPanics(t, func() { m.AnchorGraphIncrementCacheHitCount() })
+		require.NotPanics(t, func() { m.AnchorGraphIncrementCacheMissCount() })
 		require.NotPanics(t, func() { m.AddOperationTime(time.Second) })
 		require.NotPanics(t, func() { m.BatchCutTime(time.Second) })
 		require.NotPanics(t, func() { m.BatchRollbackTime(time.Second) })
@@ -46,6 +51,10 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.BatchSize(float64(500)) })
 		require.NotPanics(t, func() { m.ProcessAnchorTime(time.Second) })
 		require.NotPanics(t, func() { m.ProcessDIDTime(time.Second) })
+		require.NotPanics(t, func() { m.AnchorForkDetected() })
+		require.NotPanics(t, func() { m.CASSourceResolveTime("webcas", time.Second) })
+		require.NotPanics(t, func() { m.CASSourceResolveResult("webcas", true) })
+		require.NotPanics(t, func() { m.CASSourceResolveResult("ipfs", false) })
 		require.NotPanics(t, func() { m.CASWriteTime(time.Second) })
 		require.NotPanics(t, func() { m.CASResolveTime(time.Second) })
 		require.NotPanics(t, func() { m.CASIncrementCacheHitCount() })
@@ -66,9 +75,16 @@ func TestMetrics(t *testing.T) {
 		require.NotPanics(t, func() { m.WitnessVerifyVCTSignature(time.Second) })
 		require.NotPanics(t, func() { m.AddProofParseCredential(time.Second) })
 		require.NotPanics(t, func() { m.AddProofSign(time.Second) })
+		require.NotPanics(t, func() { m.VCTLogIncident("split_view") })
+		require.NotPanics(t, func() { m.DocumentUpdateRejected("duplicate") })
 		require.NotPanics(t, func() { m.SignerGetKey(time.Second) })
 		require.NotPanics(t, func() { m.SignerSign(time.Second) })
 		require.NotPanics(t, func() { m.SignerAddLinkedDataProof(time.Second) })
+		require.NotPanics(t, func() { m.AdmissionControlState(1) })
+		require.NotPanics(t, func() { m.AdmissionControlWitnessResponseRate(0.5) })
+		require.NotPanics(t, func() { m.AdmissionControlCASErrorRate(0.1) })
+		require.NotPanics(t, func() { m.AdmissionControlIncrementRejectedCount() })
+		require.NotPanics(t, func() { m.HTTPSignatureRejected("replay") })
 	})
 }
 
@@ -80,6 +80,28 @@ func (m *MetricsProvider) WriteAnchorResolveHostMetaLinkTime(value time.Duration
 func (m *MetricsProvider) ProcessWitnessedAnchorCredentialTime(value time.Duration) {
 }
 
+// ExpiredAnchorRewitnessed increments the number of expired anchor credentials that were offered to
+// an alternate set of witnesses.
+func (m *MetricsProvider) ExpiredAnchorRewitnessed() {
+}
+
+// ExpiredAnchorAbandoned increments the number of expired anchor credentials that were abandoned.
+func (m *MetricsProvider) ExpiredAnchorAbandoned() {
+}
+
+// ExpiredAnchorOperationsRequeued records the number of operations from abandoned anchor credentials
+// that were added back to the operation queue.
+func (m *MetricsProvider) ExpiredAnchorOperationsRequeued(count int) {
+}
+
+// AnchorGraphIncrementCacheHitCount increments the number of anchor graph cache hits.
+func (m *MetricsProvider) AnchorGraphIncrementCacheHitCount() {
+}
+
+// AnchorGraphIncrementCacheMissCount increments the number of anchor graph cache misses.
+func (m *MetricsProvider) AnchorGraphIncrementCacheMissCount() {
+}
+
 // AddOperationTime records the time it takes to add an operation to the queue.
 func (m *MetricsProvider) AddOperationTime(value time.Duration) {
 }
@@ -100,6 +122,10 @@ func (m *MetricsProvider) ProcessAnchorTime(value time.Duration) {
 func (m *MetricsProvider) ProcessDIDTime(value time.Duration) {
 }
 
+// AnchorForkDetected increments the number of forks detected in DID anchor chains.
+func (m *MetricsProvider) AnchorForkDetected() {
+}
+
 // CASWriteTime records the time it takes to write a document to CAS.
 func (m *MetricsProvider) CASWriteTime(value time.Duration) {
 }
@@ -130,6 +156,10 @@ func (m *MetricsProvider) DocumentCreateUpdateTime(value time.Duration) {
 func (m *MetricsProvider) DocumentResolveTime(value time.Duration) {
 }
 
+// DocumentUpdateRejected increments the number of create/update operations rejected for the given reason.
+func (m *MetricsProvider) DocumentUpdateRejected(reason string) {
+}
+
 // OutboxIncrementActivityCount increments the number of activities of the given type posted to the outbox.
 func (m *MetricsProvider) OutboxIncrementActivityCount(activityType string) {
 }
@@ -142,6 +172,15 @@ func (m *MetricsProvider) CASIncrementCacheHitCount() {
 func (m *MetricsProvider) CASReadTime(casType string, value time.Duration) {
 }
 
+// CASSourceResolveTime records the time it takes to retrieve a document from the given CAS source.
+func (m *MetricsProvider) CASSourceResolveTime(source string, value time.Duration) {
+}
+
+// CASSourceResolveResult increments the number of successful or failed attempts to retrieve a document from
+// the given CAS source.
+func (m *MetricsProvider) CASSourceResolveResult(source string, success bool) {
+}
+
 // BatchSize records the size of an operation batch.
 func (m *MetricsProvider) BatchSize(float64) {
 }
@@ -174,6 +213,10 @@ func (m *MetricsProvider) AddProofParseCredential(value time.Duration) {
 func (m *MetricsProvider) AddProofSign(value time.Duration) {
 }
 
+// VCTLogIncident increments the number of incidents of the given type detected in VCT logs.
+func (m *MetricsProvider) VCTLogIncident(incidentType string) {
+}
+
 // SignerGetKey records get key time.
 func (m *MetricsProvider) SignerGetKey(value time.Duration) {
 }
@@ -185,3 +228,23 @@ func (m *MetricsProvider) SignerSign(value time.Duration) {
 // SignerAddLinkedDataProof records add data linked proof.
 func (m *MetricsProvider) SignerAddLinkedDataProof(value time.Duration) {
 }
+
+// AdmissionControlState sets the current admission control state.
+func (m *MetricsProvider) AdmissionControlState(value float64) {
+}
+
+// AdmissionControlWitnessResponseRate sets the witness response rate.
+func (m *MetricsProvider) AdmissionControlWitnessResponseRate(value float64) {
+}
+
+// AdmissionControlCASErrorRate sets the CAS write error rate.
+func (m *MetricsProvider) AdmissionControlCASErrorRate(value float64) {
+}
+
+// AdmissionControlIncrementRejectedCount increments the number of operations rejected by the admission controller.
+func (m *MetricsProvider) AdmissionControlIncrementRejectedCount() {
+}
+
+// HTTPSignatureRejected increments the number of signed requests that were rejected for the given reason.
+func (m *MetricsProvider) HTTPSignatureRejected(reason string) {
+}
@@ -42,16 +42,34 @@ type Service struct {
 	apStore    apstore.Store
 	stats      *stats
 	mutex      sync.RWMutex
+	metadata   map[string]func() interface{}
+}
+
+// Option is a NodeInfo service option.
+type Option func(s *Service)
+
+// WithMetadata adds an entry to the metadata section of the NodeInfo. The given function is invoked
+// each time the NodeInfo is requested so that the value is always current.
+func WithMetadata(key string, getValue func() interface{}) Option {
+	return func(s *Service) {
+		s.metadata[key] = getValue
+	}
 }
 
 // NewService returns a new NodeInfo service.
-func NewService(apStore apstore.Store, serviceIRI *url.URL, refreshInterval time.Duration) *Service {
+func NewService(apStore apstore.Store, serviceIRI *url.URL, refreshInterval time.Duration,
+	opts ...Option) *Service {
 	r := &Service{
 		apStore:    apStore,
 		serviceIRI: serviceIRI,
 		done:       make(chan struct{}),
 		interval:   refreshInterval,
 		stats:      &stats{},
+		metadata:   make(map[string]func() interface{}),
+	}
+
+	for _, opt := range opts {
+		opt(r)
 	}
 
 	r.Lifecycle = lifecycle.New("nodeinfo",
@@ -95,9 +113,24 @@ func (r *Service) GetNodeInfo(version Version) *NodeInfo {
 			LocalPosts:    int(stats.Posts),
 			LocalComments: int(stats.Comments),
 		},
+		Metadata: r.getMetadata(),
 	}
 }
 
+func (r *Service) getMetadata() map[string]interface{} {
+	if len(r.metadata) == 0 {
+		return nil
+	}
+
+	metadata := make(map[string]interface{})
+
+	for key, getValue := range r.metadata {
+		metadata[key] = getValue()
+	}
+
+	return metadata
+}
+
 func (r *Service) start() {
 	go r.refresh()
 
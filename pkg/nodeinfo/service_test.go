@@ -78,3 +78,24 @@ func TestService(t *testing.T) {
 	require.Equal(t, numCreates, nodeInfo.Usage.LocalPosts)
 	require.Equal(t, numLikes, nodeInfo.Usage.LocalComments)
 }
+
+func TestServiceWithMetadata(t *testing.T) {
+	serviceIRI := testutil.MustParseURL("https://example.com/services/orb")
+
+	state := "healthy"
+
+	s := NewService(memstore.New(""), serviceIRI, time.Minute,
+		WithMetadata("admissionControl", func() interface{} { return state }),
+	)
+	require.NotNil(t, s)
+
+	nodeInfo := s.GetNodeInfo(V2_1)
+	require.NotNil(t, nodeInfo)
+	require.Equal(t, "healthy", nodeInfo.Metadata["admissionControl"])
+
+	state = "rejecting"
+
+	nodeInfo = s.GetNodeInfo(V2_1)
+	require.NotNil(t, nodeInfo)
+	require.Equal(t, "rejecting", nodeInfo.Metadata["admissionControl"])
+}
@@ -0,0 +1,298 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/trustbloc/orb/pkg/didanchor"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+)
+
+type anchorForkStore interface {
+	PutSuccessor(suffix, previousAnchor, anchor string) (string, error)
+	GetSuccessor(suffix, previousAnchor string) (string, error)
+	GetFork(suffix, previousAnchor string) (*anchorfork.Fork, error)
+	PutFork(fork *anchorfork.Fork) error
+	Block(suffix string, anchors ...string) error
+	IsBlocked(suffix, anchor string) (bool, error)
+}
+
+// checkForks returns the suffixes (from the given previous anchors) whose operations in the given anchor may be
+// processed and for which the DID's anchor pointer may be advanced to the given anchor. It must be called before
+// the operations of the anchor are processed.
+//
+// A fork occurs when two different anchors claim the same previous anchor for a suffix. In this case both branches
+// are recorded and the branch whose anchor credential has the most (witness) proofs wins. If the branches have the
+// same number of proofs then the branch that was observed first wins. Anchors on a losing branch, and anchors that
+// descend from them, are blocked. If the DID's anchor pointer follows a branch that loses then the pointer is
+// rolled back.
+func (o *Observer) checkForks(anchor string, previousAnchors map[string]string) ([]string, error) {
+	if o.AnchorForks == nil {
+		return getKeys(previousAnchors), nil
+	}
+
+	var suffixes []string
+
+	for suffix, previousAnchor := range previousAnchors {
+		advance, err := o.checkFork(suffix, previousAnchor, anchor)
+		if err != nil {
+			return nil, fmt.Errorf("check fork for suffix[%s] in anchor[%s]: %w", suffix, anchor, err)
+		}
+
+		if advance {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+
+	return suffixes, nil
+}
+
+func (o *Observer) checkFork(suffix, previousAnchor, anchor string) (bool, error) {
+	anchorID := canonicalAnchorID(anchor)
+	previousID := canonicalAnchorID(previousAnchor)
+
+	blocked, err := o.AnchorForks.IsBlocked(suffix, anchorID)
+	if err != nil {
+		return false, err
+	}
+
+	if blocked {
+		logger.Infof("Not advancing suffix[%s] to anchor[%s] since the anchor is on a losing branch of a fork",
+			suffix, anchor)
+
+		return false, nil
+	}
+
+	if previousID != "" {
+		blocked, err = o.AnchorForks.IsBlocked(suffix, previousID)
+		if err != nil {
+			return false, err
+		}
+
+		if blocked {
+			logger.Infof("Not advancing suffix[%s] to anchor[%s] since the previous anchor[%s] is on a losing "+
+				"branch of a fork", suffix, anchor, previousAnchor)
+
+			return false, o.AnchorForks.Block(suffix, anchorID)
+		}
+	}
+
+	successor, err := o.AnchorForks.PutSuccessor(suffix, previousID, anchor)
+	if err != nil {
+		return false, err
+	}
+
+	if canonicalAnchorID(successor) == anchorID {
+		return true, nil
+	}
+
+	return o.handleFork(suffix, previousAnchor, successor, anchor)
+}
+
+func (o *Observer) handleFork(suffix, previousAnchor, existingAnchor, anchor string) (bool, error) {
+	previousID := canonicalAnchorID(previousAnchor)
+
+	fork, err := o.AnchorForks.GetFork(suffix, previousID)
+	if err != nil {
+		if !errors.Is(err, anchorfork.ErrNotFound) {
+			return false, err
+		}
+
+		logger.Warnf("Fork detected for suffix[%s]: anchors [%s] and [%s] both claim previous anchor [%s]",
+			suffix, existingAnchor, anchor, previousAnchor)
+
+		o.Metrics.AnchorForkDetected()
+
+		fork = &anchorfork.Fork{
+			Suffix:         suffix,
+			PreviousAnchor: previousID,
+			Branches:       []string{existingAnchor},
+			DetectedAt:     time.Now(),
+		}
+	}
+
+	// The branches are kept in the order in which they were observed.
+	if !containsAnchor(fork.Branches, anchor) {
+		fork.Branches = append(fork.Branches, anchor)
+	}
+
+	winner, err := o.resolveFork(fork.Branches)
+	if err != nil {
+		return false, err
+	}
+
+	fork.Winner = winner
+	fork.Status = anchorfork.StatusResolved
+
+	if err := o.AnchorForks.PutFork(fork); err != nil {
+		return false, err
+	}
+
+	var losers []string
+
+	for _, branch := range fork.Branches {
+		if canonicalAnchorID(branch) != canonicalAnchorID(winner) {
+			losers = append(losers, branch)
+		}
+	}
+
+	if err := o.blockBranches(suffix, losers); err != nil {
+		return false, err
+	}
+
+	if err := o.rollBackAnchorPointer(suffix, previousAnchor, winner, anchor); err != nil {
+		return false, err
+	}
+
+	logger.Infof("Fork for suffix[%s] at previous anchor [%s] resolved to anchor [%s] - branches: %s",
+		suffix, previousAnchor, winner, fork.Branches)
+
+	return canonicalAnchorID(winner) == canonicalAnchorID(anchor), nil
+}
+
+// resolveFork returns the branch whose anchor credential has the most proofs or, if more than one branch has the
+// most proofs, the branch that was observed first. The issue date of the credential isn't considered since it's
+// chosen by the issuer, which would allow an equivocating issuer to choose the winner.
+func (o *Observer) resolveFork(branches []string) (string, error) {
+	var (
+		winner       string
+		winnerProofs int
+	)
+
+	for _, branch := range branches {
+		vc, err := o.AnchorGraph.Read(branch)
+		if err != nil {
+			return "", fmt.Errorf("read anchor[%s] from anchor graph: %w", branch, err)
+		}
+
+		if winner == "" || len(vc.Proofs) > winnerProofs {
+			winner, winnerProofs = branch, len(vc.Proofs)
+		}
+	}
+
+	return winner, nil
+}
+
+// blockBranches blocks the given anchors along with the anchors that were already observed to descend from them.
+func (o *Observer) blockBranches(suffix string, anchors []string) error {
+	var blocked []string
+
+	visited := make(map[string]bool)
+
+	for len(anchors) > 0 {
+		anchorID := canonicalAnchorID(anchors[0])
+		anchors = anchors[1:]
+
+		if visited[anchorID] {
+			continue
+		}
+
+		visited[anchorID] = true
+		blocked = append(blocked, anchorID)
+
+		descendants, err := o.getSuccessors(suffix, anchorID)
+		if err != nil {
+			return err
+		}
+
+		anchors = append(anchors, descendants...)
+	}
+
+	return o.AnchorForks.Block(suffix, blocked...)
+}
+
+// getSuccessors returns the anchors that were observed to claim the given anchor as their previous anchor.
+func (o *Observer) getSuccessors(suffix, anchorID string) ([]string, error) {
+	fork, err := o.AnchorForks.GetFork(suffix, anchorID)
+	if err == nil {
+		return fork.Branches, nil
+	}
+
+	if !errors.Is(err, anchorfork.ErrNotFound) {
+		return nil, err
+	}
+
+	successor, err := o.AnchorForks.GetSuccessor(suffix, anchorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if successor == "" {
+		return nil, nil
+	}
+
+	return []string{successor}, nil
+}
+
+// rollBackAnchorPointer moves the DID's anchor pointer off of a losing branch of a fork. The pointer is moved to the
+// winning branch or, if the winner is the anchor that's currently being processed, to the previous anchor. (The
+// pointer is advanced to the winner once its operations are processed.)
+func (o *Observer) rollBackAnchorPointer(suffix, previousAnchor, winner, anchor string) error {
+	current, err := o.DidAnchors.Get(suffix)
+	if err != nil {
+		if errors.Is(err, didanchor.ErrDataNotFound) {
+			return nil
+		}
+
+		return fmt.Errorf("get anchor pointer for suffix[%s]: %w", suffix, err)
+	}
+
+	blocked, err := o.AnchorForks.IsBlocked(suffix, canonicalAnchorID(current))
+	if err != nil {
+		return err
+	}
+
+	if !blocked {
+		return nil
+	}
+
+	target := winner
+	if canonicalAnchorID(winner) == canonicalAnchorID(anchor) {
+		target = previousAnchor
+	}
+
+	if target == "" {
+		// The fork is at the first anchor of the DID so there's no previous anchor to roll back to.
+		return nil
+	}
+
+	logger.Warnf("Rolling back the anchor pointer of suffix[%s] from anchor [%s] on a losing branch to anchor [%s]",
+		suffix, current, target)
+
+	return o.DidAnchors.PutBulk([]string{suffix}, target)
+}
+
+// canonicalAnchorID returns the resource hash of the given hashlink so that references to the same anchor (which may
+// contain different metadata) are treated as equal.
+func canonicalAnchorID(hl string) string {
+	if hl == "" {
+		return ""
+	}
+
+	id, err := hashlink.GetResourceHashFromHashLink(hl)
+	if err != nil {
+		return hl
+	}
+
+	return id
+}
+
+func containsAnchor(anchors []string, anchor string) bool {
+	id := canonicalAnchorID(anchor)
+
+	for _, a := range anchors {
+		if canonicalAnchorID(a) == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,250 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package observer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/util"
+	"github.com/hyperledger/aries-framework-go/pkg/doc/verifiable"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/didanchor/memdidanchor"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+)
+
+const (
+	forkSuffix1 = "suffix1"
+	forkSuffix2 = "suffix2"
+
+	forkAnchor1 = "hl:uEiAnchor1"
+	forkAnchor2 = "hl:uEiAnchor2"
+	forkAnchor3 = "hl:uEiAnchor3"
+	forkAnchor4 = "hl:uEiAnchor4"
+)
+
+func TestObserver_CheckForks(t *testing.T) {
+	now := time.Now()
+
+	t.Run("Fork detection disabled", func(t *testing.T) {
+		o := &Observer{Providers: &Providers{}}
+
+		suffixes, err := o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor1, forkSuffix2: ""})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{forkSuffix1, forkSuffix2}, suffixes)
+	})
+
+	t.Run("No fork", func(t *testing.T) {
+		o, _, metrics := newForkObserver(t, nil)
+
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: "", forkSuffix2: ""})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{forkSuffix1, forkSuffix2}, suffixes)
+
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor1})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		// Processing the same anchor again isn't a fork.
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor1 + ":metadata"})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		require.Zero(t, metrics.forks)
+	})
+
+	t.Run("Fork resolved by witness proofs", func(t *testing.T) {
+		o, forks, metrics := newForkObserver(t, map[string]*verifiable.Credential{
+			forkAnchor1: newAnchorCredential(now, 1),
+			forkAnchor2: newAnchorCredential(now.Add(-time.Minute), 2),
+		})
+
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: ""})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: "", forkSuffix2: ""})
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{forkSuffix1, forkSuffix2}, suffixes)
+		require.Equal(t, 1, metrics.forks)
+
+		fork, err := forks.GetFork(forkSuffix1, "")
+		require.NoError(t, err)
+		require.Equal(t, anchorfork.StatusResolved, fork.Status)
+		require.Equal(t, forkAnchor2, fork.Winner)
+		require.Equal(t, []string{forkAnchor1, forkAnchor2}, fork.Branches)
+
+		// The losing anchor may not advance the anchor pointer.
+		suffixes, err = o.checkForks(forkAnchor1, map[string]string{forkSuffix1: ""})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		// Nor may anchors that descend from the losing anchor.
+		suffixes, err = o.checkForks(forkAnchor3, map[string]string{forkSuffix1: forkAnchor1})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		suffixes, err = o.checkForks(forkAnchor4, map[string]string{forkSuffix1: forkAnchor3})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		// Anchors that descend from the winning anchor advance the anchor pointer.
+		suffixes, err = o.checkForks(forkAnchor3+"x", map[string]string{forkSuffix1: forkAnchor2})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		require.Equal(t, 1, metrics.forks)
+	})
+
+	t.Run("Fork resolved by observation order", func(t *testing.T) {
+		// The second branch was issued first but the issue date is chosen by the issuer so it's not considered.
+		o, forks, _ := newForkObserver(t, map[string]*verifiable.Credential{
+			forkAnchor1: newAnchorCredential(now, 2),
+			forkAnchor2: newAnchorCredential(now.Add(-time.Hour), 2),
+		})
+
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		fork, err := forks.GetFork(forkSuffix1, canonicalAnchorID(forkAnchor4))
+		require.NoError(t, err)
+		require.Equal(t, anchorfork.StatusResolved, fork.Status)
+		require.Equal(t, forkAnchor1, fork.Winner)
+	})
+
+	t.Run("Anchor pointer rolled back", func(t *testing.T) {
+		o, forks, metrics := newForkObserver(t, map[string]*verifiable.Credential{
+			forkAnchor1: newAnchorCredential(now, 1),
+			forkAnchor2: newAnchorCredential(now, 1),
+			forkAnchor3: newAnchorCredential(now, 2),
+		})
+
+		// The anchor pointer follows the first branch (anchor1 -> anchor2).
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor1})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		require.NoError(t, o.DidAnchors.PutBulk([]string{forkSuffix1}, forkAnchor2))
+
+		// A branch with more proofs wins so the first branch, including its descendants, is blocked and the
+		// anchor pointer is rolled back to the previous anchor of the fork.
+		suffixes, err = o.checkForks(forkAnchor3, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+		require.Equal(t, 1, metrics.forks)
+
+		fork, err := forks.GetFork(forkSuffix1, canonicalAnchorID(forkAnchor4))
+		require.NoError(t, err)
+		require.Equal(t, forkAnchor3, fork.Winner)
+
+		current, err := o.DidAnchors.Get(forkSuffix1)
+		require.NoError(t, err)
+		require.Equal(t, forkAnchor4, current)
+
+		for _, anchor := range []string{forkAnchor1, forkAnchor2} {
+			blocked, err := forks.IsBlocked(forkSuffix1, canonicalAnchorID(anchor))
+			require.NoError(t, err)
+			require.True(t, blocked)
+		}
+
+		// Descendants of the blocked branch that are observed later may not advance the anchor pointer.
+		suffixes, err = o.checkForks(forkAnchor4+"x", map[string]string{forkSuffix1: forkAnchor2})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+	})
+
+	t.Run("Anchor pointer not on losing branch", func(t *testing.T) {
+		o, _, _ := newForkObserver(t, map[string]*verifiable.Credential{
+			forkAnchor1: newAnchorCredential(now, 2),
+			forkAnchor2: newAnchorCredential(now, 1),
+		})
+
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		require.NoError(t, o.DidAnchors.PutBulk([]string{forkSuffix1}, forkAnchor1))
+
+		suffixes, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: forkAnchor4})
+		require.NoError(t, err)
+		require.Empty(t, suffixes)
+
+		current, err := o.DidAnchors.Get(forkSuffix1)
+		require.NoError(t, err)
+		require.Equal(t, forkAnchor1, current)
+	})
+
+	t.Run("Anchor graph error", func(t *testing.T) {
+		o, _, _ := newForkObserver(t, nil)
+
+		suffixes, err := o.checkForks(forkAnchor1, map[string]string{forkSuffix1: ""})
+		require.NoError(t, err)
+		require.Equal(t, []string{forkSuffix1}, suffixes)
+
+		_, err = o.checkForks(forkAnchor2, map[string]string{forkSuffix1: ""})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read anchor[hl:uEiAnchor1] from anchor graph")
+	})
+}
+
+type forkMetrics struct {
+	orbmocks.MetricsProvider
+
+	forks int
+}
+
+func (m *forkMetrics) AnchorForkDetected() {
+	m.forks++
+}
+
+func newForkObserver(t *testing.T, vcs map[string]*verifiable.Credential) (*Observer, *anchorfork.Store, *forkMetrics) {
+	t.Helper()
+
+	forks, err := anchorfork.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	anchorGraph := &orbmocks.AnchorGraph{}
+	anchorGraph.ReadStub = func(hl string) (*verifiable.Credential, error) {
+		vc, ok := vcs[hl]
+		if !ok {
+			return nil, errors.New("not found")
+		}
+
+		return vc, nil
+	}
+
+	metrics := &forkMetrics{}
+
+	return &Observer{
+		Providers: &Providers{
+			AnchorGraph: anchorGraph,
+			DidAnchors:  memdidanchor.New(),
+			Metrics:     metrics,
+			AnchorForks: forks,
+		},
+	}, forks, metrics
+}
+
+func newAnchorCredential(issued time.Time, proofs int) *verifiable.Credential {
+	return &verifiable.Credential{
+		Issued: util.NewTime(issued),
+		Proofs: make([]verifiable.Proof, proofs),
+	}
+}
@@ -48,6 +48,7 @@ type OperationFilter interface {
 
 type didAnchors interface {
 	PutBulk(dids []string, cid string) error
+	Get(suffix string) (string, error)
 }
 
 // Publisher publishes anchors and DIDs to a message queue for processing.
@@ -65,6 +66,7 @@ type pubSub interface {
 type metricsProvider interface {
 	ProcessAnchorTime(value time.Duration)
 	ProcessDIDTime(value time.Duration)
+	AnchorForkDetected()
 }
 
 // Outbox defines an ActivityPub outbox.
@@ -92,6 +94,9 @@ type Providers struct {
 	PubSub     pubSub
 	Metrics    metricsProvider
 	Outbox     outboxProvider
+
+	// AnchorForks is used to detect forks in DID anchor chains. Fork detection is disabled if not set.
+	AnchorForks anchorForkStore
 }
 
 // Observer receives transactions over a channel and processes them by storing them to an operation store.
@@ -144,7 +149,9 @@ func (o *Observer) handleAnchor(anchor *anchorinfo.AnchorInfo) error {
 
 	startTime := time.Now()
 
-	defer o.Metrics.ProcessAnchorTime(time.Since(startTime))
+	defer func() {
+		o.Metrics.ProcessAnchorTime(time.Since(startTime))
+	}()
 
 	anchorInfo, err := o.AnchorGraph.Read(anchor.Hashlink)
 	if err != nil {
@@ -208,6 +215,24 @@ func (o *Observer) processDID(did string) error {
 	return nil
 }
 
+// filterPreviousAnchors returns the previous anchors of the given suffixes or, if no suffixes are specified,
+// all of the previous anchors.
+func filterPreviousAnchors(previousAnchors map[string]string, suffixes []string) map[string]string {
+	if len(suffixes) == 0 {
+		return previousAnchors
+	}
+
+	filtered := make(map[string]string)
+
+	for _, suffix := range suffixes {
+		if previousAnchor, ok := previousAnchors[suffix]; ok {
+			filtered[suffix] = previousAnchor
+		}
+	}
+
+	return filtered
+}
+
 func getDidParts(did string) (cid, suffix string, err error) {
 	const delimiter = ":"
 
@@ -261,19 +286,32 @@ func (o *Observer) processAnchor(anchor *anchorinfo.AnchorInfo, info *verifiable
 		EquivalentReferences: equivalentRefs,
 	}
 
+	// Forks are checked before the operations are processed so that the operations of an anchor on a losing
+	// branch of a fork are not stored.
+	acSuffixes, err := o.checkForks(anchor.Hashlink, filterPreviousAnchors(anchorPayload.PreviousAnchors, suffixes))
+	if err != nil {
+		return fmt.Errorf("failed to check forks for anchor credential[%s]: %w", anchor.Hashlink, err)
+	}
+
+	if len(acSuffixes) == 0 {
+		logger.Infof("Not processing anchor[%s] since all of its suffixes are on a losing branch of a fork",
+			anchor.Hashlink)
+
+		return nil
+	}
+
 	logger.Debugf("processing anchor[%s], core index[%s]", anchor.Hashlink, anchorPayload.CoreIndex)
 
-	err = v.TransactionProcessor().Process(sidetreeTxn, suffixes...)
+	err = v.TransactionProcessor().Process(sidetreeTxn, acSuffixes...)
 	if err != nil {
 		return fmt.Errorf("failed to processAnchors core index[%s]: %w", anchorPayload.CoreIndex, err)
 	}
 
 	// update global did/anchor references
-	acSuffixes := getKeys(anchorPayload.PreviousAnchors)
-
 	err = o.DidAnchors.PutBulk(acSuffixes, anchor.Hashlink)
 	if err != nil {
-		return fmt.Errorf("failed updating did anchor references for anchor credential[%s]: %w", anchor.Hashlink, err)
+		return fmt.Errorf("failed updating did anchor references for anchor credential[%s]: %w",
+			anchor.Hashlink, err)
 	}
 
 	logger.Infof("Successfully processed %d DIDs in anchor[%s], core index[%s]",
@@ -28,12 +28,14 @@ import (
 	anchorinfo "github.com/trustbloc/orb/pkg/anchor/info"
 	"github.com/trustbloc/orb/pkg/anchor/subject"
 	casresolver "github.com/trustbloc/orb/pkg/cas/resolver"
+	"github.com/trustbloc/orb/pkg/didanchor"
 	"github.com/trustbloc/orb/pkg/didanchor/memdidanchor"
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/internal/testutil"
 	orbmocks "github.com/trustbloc/orb/pkg/mocks"
 	"github.com/trustbloc/orb/pkg/pubsub/mempubsub"
 	"github.com/trustbloc/orb/pkg/pubsub/spi"
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
 	"github.com/trustbloc/orb/pkg/store/cas"
 	webfingerclient "github.com/trustbloc/orb/pkg/webfinger/client"
 )
@@ -92,7 +94,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -167,7 +169,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -230,7 +232,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -299,7 +301,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -364,7 +366,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -427,7 +429,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 
@@ -506,7 +508,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		graphProviders := &graph.Providers{
@@ -612,7 +614,7 @@ func TestStartObserver(t *testing.T) {
 		pc.Versions[0].TransactionProcessorReturns(tp)
 		pc.Versions[0].ProtocolReturns(pc.Protocol)
 
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		graphProviders := &graph.Providers{
@@ -673,6 +675,84 @@ func TestStartObserver(t *testing.T) {
 	})
 }
 
+func TestObserver_ProcessAnchorWithFork(t *testing.T) {
+	const namespace = "did:orb"
+
+	tp := &mocks.TxnProcessor{}
+
+	pc := mocks.NewMockProtocolClient()
+	pc.Protocol.GenesisTime = 1
+	pc.Versions[0].TransactionProcessorReturns(tp)
+	pc.Versions[0].ProtocolReturns(pc.Protocol)
+
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	anchorGraph := graph.New(&graph.Providers{
+		CasWriter: casClient,
+		CasResolver: casresolver.New(casClient, nil,
+			casresolver.NewWebCASResolver(
+				transport.New(&http.Client{}, testutil.MustParseURL("https://example.com/keys/public-key"),
+					transport.DefaultSigner(), transport.DefaultSigner()),
+				webfingerclient.New(), "https"), &orbmocks.MetricsProvider{}),
+		Pkf:       pubKeyFetcherFnc,
+		DocLoader: testutil.GetLoader(t),
+	})
+
+	forks, err := anchorfork.New(mem.NewProvider())
+	require.NoError(t, err)
+
+	didAnchors := memdidanchor.New()
+
+	o, err := New(&Providers{
+		ProtocolClientProvider: mocks.NewMockProtocolClientProvider().WithProtocolClient(namespace, pc),
+		AnchorGraph:            anchorGraph,
+		DidAnchors:             didAnchors,
+		PubSub:                 mempubsub.New(mempubsub.DefaultConfig()),
+		Metrics:                &orbmocks.MetricsProvider{},
+		Outbox:                 func() Outbox { return apmocks.NewOutbox() },
+		AnchorForks:            forks,
+	})
+	require.NoError(t, err)
+
+	addAnchor := func(coreIndex string) (*anchorinfo.AnchorInfo, *verifiable.Credential) {
+		vc, e := buildCredential(&subject.Payload{
+			Namespace:       namespace,
+			Version:         1,
+			CoreIndex:       coreIndex,
+			PreviousAnchors: map[string]string{"did1": ""},
+		})
+		require.NoError(t, e)
+
+		hl, e := anchorGraph.Add(vc)
+		require.NoError(t, e)
+
+		vc, e = anchorGraph.Read(hl)
+		require.NoError(t, e)
+
+		return &anchorinfo.AnchorInfo{Hashlink: hl}, vc
+	}
+
+	anchor1, vc1 := addAnchor("core1")
+	anchor2, vc2 := addAnchor("core2")
+
+	require.NoError(t, o.processAnchor(anchor1, vc1))
+	require.Equal(t, 1, tp.ProcessCallCount())
+
+	// The second anchor is a losing branch of a fork (it was observed later and has the same number of proofs)
+	// so its operations aren't processed and the anchor pointer isn't advanced.
+	require.NoError(t, o.processAnchor(anchor2, vc2))
+	require.Equal(t, 1, tp.ProcessCallCount())
+
+	current, err := didAnchors.Get("did1")
+	require.NoError(t, err)
+	require.Equal(t, anchor1.Hashlink, current)
+
+	fork, err := forks.GetFork("did1", "")
+	require.NoError(t, err)
+	require.Equal(t, anchor1.Hashlink, fork.Winner)
+}
+
 func buildCredential(payload *subject.Payload) (*verifiable.Credential, error) {
 	const defVCContext = "https://www.w3.org/2018/credentials/v1"
 
@@ -709,3 +789,7 @@ func (m *mockDidAnchor) PutBulk(_ []string, _ string) error {
 
 	return nil
 }
+
+func (m *mockDidAnchor) Get(string) (string, error) {
+	return "", didanchor.ErrDataNotFound
+}
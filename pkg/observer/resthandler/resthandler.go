@@ -0,0 +1,104 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+)
+
+const (
+	endpoint    = "/anchor/forks"
+	suffixParam = "suffix"
+)
+
+const internalServerErrorResponse = "Internal Server Error."
+
+var logger = log.New("anchor-forks-rest-handler")
+
+type forkProvider interface {
+	GetForks(suffix string) ([]*anchorfork.Fork, error)
+}
+
+// Forks returns the forks that were detected in DID anchor chains. If the 'suffix' query parameter
+// is specified then only the forks for the given DID suffix are returned.
+type Forks struct {
+	forkProvider forkProvider
+	marshal      func(interface{}) ([]byte, error)
+}
+
+// New returns a new Forks handler.
+func New(p forkProvider) *Forks {
+	return &Forks{
+		forkProvider: p,
+		marshal:      json.Marshal,
+	}
+}
+
+// Path returns the HTTP REST endpoint for the Forks service.
+func (h *Forks) Path() string {
+	return endpoint
+}
+
+// Method returns the HTTP REST method for the Forks service.
+func (h *Forks) Method() string {
+	return http.MethodGet
+}
+
+// Handler returns the HTTP REST handle for the Forks service.
+func (h *Forks) Handler() common.HTTPRequestHandler {
+	return h.handle
+}
+
+func (h *Forks) handle(w http.ResponseWriter, req *http.Request) {
+	suffix := req.URL.Query().Get(suffixParam)
+
+	forks, err := h.forkProvider.GetForks(suffix)
+	if err != nil {
+		logger.Errorf("[%s] Error retrieving forks for suffix [%s]: %s", endpoint, suffix, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	if forks == nil {
+		forks = []*anchorfork.Fork{}
+	}
+
+	forksBytes, err := h.marshal(forks)
+	if err != nil {
+		logger.Errorf("[%s] Error marshalling forks for suffix [%s]: %s", endpoint, suffix, err)
+
+		writeResponse(w, http.StatusInternalServerError, []byte(internalServerErrorResponse))
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	writeResponse(w, http.StatusOK, forksBytes)
+}
+
+func writeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.WriteHeader(status)
+
+	if len(body) > 0 {
+		if _, err := w.Write(body); err != nil {
+			logger.Warnf("[%s] Unable to write response: %s", endpoint, err)
+
+			return
+		}
+
+		logger.Debugf("[%s] Wrote response: %s", endpoint, body)
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package resthandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/store/anchorfork"
+)
+
+const (
+	suffix  = "EiDahaOGH-liLLdDtTxEAdc8i-cfCz-WUcQdRJheMVNn3A"
+	anchor1 = "hl:uEiCsFp-ft8tI1DFGbXs78tw-HS561mMPa3Z6GsGAHElrNQ"
+	anchor2 = "hl:uEiDSXnjuBSJQnL8Xl1tdpgMmNCOxQb7cbmTvwkXXOFWcvg"
+)
+
+func TestNew(t *testing.T) {
+	h := New(&mockForkProvider{})
+	require.NotNil(t, h)
+	require.Equal(t, endpoint, h.Path())
+	require.Equal(t, http.MethodGet, h.Method())
+	require.NotNil(t, h.Handler())
+}
+
+func TestHandler(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		p := &mockForkProvider{
+			forks: []*anchorfork.Fork{
+				{
+					Suffix:   suffix,
+					Branches: []string{anchor1, anchor2},
+					Status:   anchorfork.StatusResolved,
+					Winner:   anchor2,
+				},
+			},
+		}
+
+		h := New(p)
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint+"?suffix="+suffix, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+		require.Equal(t, suffix, p.suffix)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+
+		var forks []*anchorfork.Fork
+		require.NoError(t, json.Unmarshal(respBytes, &forks))
+		require.Len(t, forks, 1)
+		require.Equal(t, suffix, forks[0].Suffix)
+		require.Equal(t, anchor2, forks[0].Winner)
+	})
+
+	t.Run("No forks", func(t *testing.T) {
+		h := New(&mockForkProvider{})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusOK, result.StatusCode)
+
+		respBytes, err := ioutil.ReadAll(result.Body)
+		require.NoError(t, err)
+		require.NoError(t, result.Body.Close())
+		require.Equal(t, "[]", string(respBytes))
+	})
+
+	t.Run("Fork provider error", func(t *testing.T) {
+		h := New(&mockForkProvider{err: errors.New("injected error")})
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+
+	t.Run("Marshal error", func(t *testing.T) {
+		h := New(&mockForkProvider{})
+		h.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		rw := httptest.NewRecorder()
+
+		h.handle(rw, httptest.NewRequest(http.MethodGet, endpoint, nil))
+
+		result := rw.Result()
+		require.Equal(t, http.StatusInternalServerError, result.StatusCode)
+		require.NoError(t, result.Body.Close())
+	})
+}
+
+type mockForkProvider struct {
+	forks  []*anchorfork.Fork
+	suffix string
+	err    error
+}
+
+func (m *mockForkProvider) GetForks(suffix string) ([]*anchorfork.Fork, error) {
+	m.suffix = suffix
+
+	return m.forks, m.err
+}
@@ -102,7 +102,7 @@ func createNewResolver(t *testing.T, casClient extendedcasclient.Client) *casres
 func createInMemoryCAS(t *testing.T) extendedcasclient.Client {
 	t.Helper()
 
-	casClient, err := cas.New(mem.NewProvider(), "https://domain.com/cas", nil, &orbmocks.MetricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), "https://domain.com/cas", nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 	require.NoError(t, err)
 
@@ -72,7 +72,7 @@ func TestResolver_Resolve(t *testing.T) {
 		testServerURL = testServer.URL
 		witnessResource = fmt.Sprintf("%s/services/orb", testServerURL)
 
-		resolver := New(http.DefaultClient, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}))
+		resolver := New(http.DefaultClient, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil))
 
 		resource, err := resolver.ResolveHostMetaLink("ipns://k51qzi5uqu5dgjceyz40t6xfnae8jqn5z17ojojggzwz2mhl7uyhdre8ateqek",
 			discoveryrest.ActivityJSONType)
@@ -89,7 +89,7 @@ func TestResolver_Resolve(t *testing.T) {
 		require.Empty(t, resource)
 	})
 	t.Run("Fail to resolve via IPNS (IPFS node not reachable)", func(t *testing.T) {
-		resolver := New(nil, ipfs.New("SomeIPFSNodeURL", 5*time.Second, 0, &orbmocks.MetricsProvider{}))
+		resolver := New(nil, ipfs.New("SomeIPFSNodeURL", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil))
 
 		resource, err := resolver.ResolveHostMetaLink("ipns://k51qzi5uqu5dgjceyz40t6xfnae8jqn5z17ojojggzwz2mhl7uyhdre8ateqek",
 			discoveryrest.ActivityJSONType)
@@ -107,7 +107,7 @@ func TestResolver_Resolve(t *testing.T) {
 			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
 		defer testServer.Close()
 
-		resolver := New(nil, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}))
+		resolver := New(nil, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil))
 
 		resource, err := resolver.ResolveHostMetaLink("ipns://k51qzi5uqu5dgjceyz40t6xfnae8jqn5z17ojojggzwz2mhl7uyhdre8ateqek",
 			discoveryrest.ActivityJSONType)
@@ -126,7 +126,7 @@ func TestResolver_Resolve(t *testing.T) {
 			}))
 		defer testServer.Close()
 
-		resolver := New(nil, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}))
+		resolver := New(nil, ipfs.New(testServer.URL, 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil))
 
 		resource, err := resolver.ResolveHostMetaLink("ipns://k51qzi5uqu5dgjceyz40t6xfnae8jqn5z17ojojggzwz2mhl7uyhdre8ateqek",
 			discoveryrest.ActivityJSONType)
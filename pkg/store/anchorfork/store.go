@@ -0,0 +1,265 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorfork
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/trustbloc/edge-core/pkg/log"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	namespace = "anchorfork"
+	suffixTag = "suffix"
+	statusTag = "status"
+
+	successorPrefix = "successor"
+	forkPrefix      = "fork"
+	blockedPrefix   = "blocked"
+)
+
+var logger = log.New("anchor-fork-store")
+
+// ErrNotFound is returned when a fork is not found.
+var ErrNotFound = errors.New("fork not found")
+
+// Status is the status of a fork.
+type Status string
+
+// StatusResolved indicates that one of the branches of the fork was chosen as the winner.
+const StatusResolved Status = "resolved"
+
+// Fork contains the anchors (branches) that claim the same previous anchor for a DID suffix.
+type Fork struct {
+	Suffix         string    `json:"suffix"`
+	PreviousAnchor string    `json:"previousAnchor,omitempty"`
+	Branches       []string  `json:"branches"`
+	Status         Status    `json:"status"`
+	Winner         string    `json:"winner,omitempty"`
+	DetectedAt     time.Time `json:"detectedAt"`
+}
+
+// Store keeps track of the successors of anchors for DID suffixes in order to detect forks in anchor chains.
+type Store struct {
+	store     storage.Store
+	marshal   func(v interface{}) ([]byte, error)
+	unmarshal func(data []byte, v interface{}) error
+}
+
+// New returns a new anchor fork store.
+func New(provider storage.Provider) (*Store, error) {
+	store, err := provider.OpenStore(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open anchor fork store: %w", err)
+	}
+
+	err = provider.SetStoreConfig(namespace, storage.StoreConfiguration{TagNames: []string{suffixTag, statusTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
+	return &Store{
+		store:     store,
+		marshal:   json.Marshal,
+		unmarshal: json.Unmarshal,
+	}, nil
+}
+
+// PutSuccessor records the given anchor as the successor of the previous anchor for the given suffix. If another
+// anchor was already recorded as the successor then that anchor is returned (and the successor isn't updated),
+// otherwise the given anchor is returned.
+func (s *Store) PutSuccessor(suffix, previousAnchor, anchor string) (string, error) {
+	key := newKey(successorPrefix, suffix, previousAnchor)
+
+	existing, err := s.store.Get(key)
+	if err == nil {
+		return string(existing), nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return "", orberrors.NewTransient(fmt.Errorf("get successor of anchor[%s] for suffix[%s]: %w",
+			previousAnchor, suffix, err))
+	}
+
+	err = s.store.Put(key, []byte(anchor))
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("put successor of anchor[%s] for suffix[%s]: %w",
+			previousAnchor, suffix, err))
+	}
+
+	logger.Debugf("stored anchor[%s] as successor of anchor[%s] for suffix[%s]", anchor, previousAnchor, suffix)
+
+	return anchor, nil
+}
+
+// GetSuccessor returns the anchor that was recorded as the successor of the previous anchor for the given suffix.
+// An empty string is returned if no successor was recorded.
+func (s *Store) GetSuccessor(suffix, previousAnchor string) (string, error) {
+	successor, err := s.store.Get(newKey(successorPrefix, suffix, previousAnchor))
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return "", nil
+		}
+
+		return "", orberrors.NewTransient(fmt.Errorf("get successor of anchor[%s] for suffix[%s]: %w",
+			previousAnchor, suffix, err))
+	}
+
+	return string(successor), nil
+}
+
+// PutFork stores the given fork.
+func (s *Store) PutFork(fork *Fork) error {
+	forkBytes, err := s.marshal(fork)
+	if err != nil {
+		return fmt.Errorf("marshal fork: %w", err)
+	}
+
+	tags := []storage.Tag{
+		{
+			Name:  suffixTag,
+			Value: base64.RawURLEncoding.EncodeToString([]byte(fork.Suffix)),
+		},
+		{
+			Name:  statusTag,
+			Value: string(fork.Status),
+		},
+	}
+
+	err = s.store.Put(newKey(forkPrefix, fork.Suffix, fork.PreviousAnchor), forkBytes, tags...)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("put fork of anchor[%s] for suffix[%s]: %w",
+			fork.PreviousAnchor, fork.Suffix, err))
+	}
+
+	logger.Debugf("stored fork of anchor[%s] for suffix[%s]: %s", fork.PreviousAnchor, fork.Suffix, forkBytes)
+
+	return nil
+}
+
+// GetFork returns the fork of the previous anchor for the given suffix. ErrNotFound is returned if no fork
+// was recorded.
+func (s *Store) GetFork(suffix, previousAnchor string) (*Fork, error) {
+	forkBytes, err := s.store.Get(newKey(forkPrefix, suffix, previousAnchor))
+	if err != nil {
+		if errors.Is(err, storage.ErrDataNotFound) {
+			return nil, ErrNotFound
+		}
+
+		return nil, orberrors.NewTransient(fmt.Errorf("get fork of anchor[%s] for suffix[%s]: %w",
+			previousAnchor, suffix, err))
+	}
+
+	fork := &Fork{}
+
+	err = s.unmarshal(forkBytes, fork)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshal fork: %w", err)
+	}
+
+	return fork, nil
+}
+
+// GetForks returns the forks for the given suffix or, if suffix is empty, all forks.
+func (s *Store) GetForks(suffix string) ([]*Fork, error) {
+	query := suffixTag
+	if suffix != "" {
+		query = fmt.Sprintf("%s:%s", suffixTag, base64.RawURLEncoding.EncodeToString([]byte(suffix)))
+	}
+
+	iter, err := s.store.Query(query)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query forks[%s]: %w", query, err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error for query[%s]: %w", query, err))
+	}
+
+	var forks []*Fork
+
+	for ok {
+		value, e := iter.Value()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator value for query[%s]: %w", query, e))
+		}
+
+		fork := &Fork{}
+
+		e = s.unmarshal(value, fork)
+		if e != nil {
+			return nil, fmt.Errorf("unmarshal fork: %w", e)
+		}
+
+		forks = append(forks, fork)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for query[%s]: %w", query, err))
+		}
+	}
+
+	logger.Debugf("found %d forks for query[%s]", len(forks), query)
+
+	return forks, nil
+}
+
+// Block marks the given anchors as blocked for the given suffix, i.e. the anchors are on a branch of a fork that
+// was not chosen as the winner.
+func (s *Store) Block(suffix string, anchors ...string) error {
+	operations := make([]storage.Operation, len(anchors))
+
+	for i, anchor := range anchors {
+		operations[i] = storage.Operation{
+			Key:   newKey(blockedPrefix, suffix, anchor),
+			Value: []byte(anchor),
+		}
+	}
+
+	err := s.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("block anchors%s for suffix[%s]: %w", anchors, suffix, err))
+	}
+
+	logger.Debugf("blocked anchors%s for suffix[%s]", anchors, suffix)
+
+	return nil
+}
+
+// IsBlocked returns true if the given anchor is blocked for the given suffix.
+func (s *Store) IsBlocked(suffix, anchor string) (bool, error) {
+	_, err := s.store.Get(newKey(blockedPrefix, suffix, anchor))
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, storage.ErrDataNotFound) {
+		return false, nil
+	}
+
+	return false, orberrors.NewTransient(fmt.Errorf("get block of anchor[%s] for suffix[%s]: %w",
+		anchor, suffix, err))
+}
+
+func newKey(prefix, suffix, anchor string) string {
+	return fmt.Sprintf("%s_%s_%s", prefix, suffix, anchor)
+}
@@ -0,0 +1,258 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package anchorfork
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/stretchr/testify/require"
+
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+	"github.com/trustbloc/orb/pkg/store/mocks"
+)
+
+const (
+	suffix1 = "suffix1"
+	suffix2 = "suffix2"
+
+	anchor0 = "hl:uEiAsiwjaXOYDmOHxmvDl3Mx0TfJ0uCar5YXqumjFJUNIBg"
+	anchor1 = "hl:uEiC0arCOQrIDw2F2Zca10gEUuIMd4Qt-IgDPh8XUKbTjcg"
+	anchor2 = "hl:uEiDSXnjuBSJQnL8Xl1tdpgMmNCOxQb7cbmTvwkXXOFWcvg"
+)
+
+func TestNew(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+		require.NotNil(t, s)
+	})
+
+	t.Run("error - open store fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(nil, fmt.Errorf("open store error"))
+
+		s, err := New(provider)
+		require.EqualError(t, err, "failed to open anchor fork store: open store error")
+		require.Nil(t, s)
+	})
+
+	t.Run("error - set store config fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.SetStoreConfigReturns(fmt.Errorf("set store config error"))
+
+		s, err := New(provider)
+		require.EqualError(t, err, "failed to set store configuration: set store config error")
+		require.Nil(t, s)
+	})
+}
+
+func TestStore_PutSuccessor(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		successor, err := s.PutSuccessor(suffix1, anchor0, anchor1)
+		require.NoError(t, err)
+		require.Equal(t, anchor1, successor)
+
+		successor, err = s.PutSuccessor(suffix1, anchor0, anchor2)
+		require.NoError(t, err)
+		require.Equal(t, anchor1, successor)
+
+		successor, err = s.PutSuccessor(suffix2, anchor0, anchor2)
+		require.NoError(t, err)
+		require.Equal(t, anchor2, successor)
+
+		successor, err = s.PutSuccessor(suffix1, "", anchor0)
+		require.NoError(t, err)
+		require.Equal(t, anchor0, successor)
+
+		successor, err = s.GetSuccessor(suffix1, anchor0)
+		require.NoError(t, err)
+		require.Equal(t, anchor1, successor)
+
+		successor, err = s.GetSuccessor(suffix1, anchor1)
+		require.NoError(t, err)
+		require.Empty(t, successor)
+	})
+
+	t.Run("get error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetReturns(nil, errors.New("injected get error"))
+
+		s := newStoreWithMock(t, store)
+
+		_, err := s.PutSuccessor(suffix1, anchor0, anchor1)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected get error")
+
+		_, err = s.GetSuccessor(suffix1, anchor0)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected get error")
+	})
+
+	t.Run("put error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetReturns(nil, storage.ErrDataNotFound)
+		store.PutReturns(errors.New("injected put error"))
+
+		s := newStoreWithMock(t, store)
+
+		_, err := s.PutSuccessor(suffix1, anchor0, anchor1)
+		require.Error(t, err)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected put error")
+	})
+}
+
+func TestStore_Forks(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		_, err = s.GetFork(suffix1, anchor0)
+		require.True(t, errors.Is(err, ErrNotFound))
+
+		fork1 := &Fork{
+			Suffix:         suffix1,
+			PreviousAnchor: anchor0,
+			Branches:       []string{anchor1, anchor2},
+			Status:         StatusResolved,
+			Winner:         anchor1,
+			DetectedAt:     time.Now().UTC().Truncate(time.Second),
+		}
+
+		fork2 := &Fork{
+			Suffix:     suffix2,
+			Branches:   []string{anchor0, anchor1},
+			Status:     StatusResolved,
+			Winner:     anchor0,
+			DetectedAt: time.Now().UTC().Truncate(time.Second),
+		}
+
+		require.NoError(t, s.PutFork(fork1))
+		require.NoError(t, s.PutFork(fork2))
+
+		f, err := s.GetFork(suffix1, anchor0)
+		require.NoError(t, err)
+		require.Equal(t, fork1, f)
+
+		forks, err := s.GetForks(suffix2)
+		require.NoError(t, err)
+		require.Len(t, forks, 1)
+		require.Equal(t, fork2, forks[0])
+
+		forks, err = s.GetForks("")
+		require.NoError(t, err)
+		require.Len(t, forks, 2)
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		s.marshal = func(interface{}) ([]byte, error) { return nil, errors.New("injected marshal error") }
+
+		require.EqualError(t, s.PutFork(&Fork{Suffix: suffix1}), "marshal fork: injected marshal error")
+	})
+
+	t.Run("unmarshal error", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.PutFork(&Fork{Suffix: suffix1, PreviousAnchor: anchor0}))
+
+		s.unmarshal = func([]byte, interface{}) error { return errors.New("injected unmarshal error") }
+
+		_, err = s.GetFork(suffix1, anchor0)
+		require.EqualError(t, err, "unmarshal fork: injected unmarshal error")
+
+		_, err = s.GetForks(suffix1)
+		require.EqualError(t, err, "unmarshal fork: injected unmarshal error")
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.PutReturns(errors.New("injected put error"))
+		store.GetReturns(nil, errors.New("injected get error"))
+		store.QueryReturns(nil, errors.New("injected query error"))
+
+		s := newStoreWithMock(t, store)
+
+		err := s.PutFork(&Fork{Suffix: suffix1})
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected put error")
+
+		_, err = s.GetFork(suffix1, anchor0)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected get error")
+
+		_, err = s.GetForks(suffix1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected query error")
+	})
+}
+
+func TestStore_Block(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		blocked, err := s.IsBlocked(suffix1, anchor1)
+		require.NoError(t, err)
+		require.False(t, blocked)
+
+		require.NoError(t, s.Block(suffix1, anchor1, anchor2))
+
+		blocked, err = s.IsBlocked(suffix1, anchor1)
+		require.NoError(t, err)
+		require.True(t, blocked)
+
+		blocked, err = s.IsBlocked(suffix2, anchor1)
+		require.NoError(t, err)
+		require.False(t, blocked)
+
+		blocked, err = s.IsBlocked(suffix1, anchor2)
+		require.NoError(t, err)
+		require.True(t, blocked)
+	})
+
+	t.Run("store errors", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.BatchReturns(errors.New("injected batch error"))
+		store.GetReturns(nil, errors.New("injected get error"))
+
+		s := newStoreWithMock(t, store)
+
+		err := s.Block(suffix1, anchor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected batch error")
+
+		_, err = s.IsBlocked(suffix1, anchor1)
+		require.True(t, orberrors.IsTransient(err))
+		require.Contains(t, err.Error(), "injected get error")
+	})
+}
+
+func newStoreWithMock(t *testing.T, store *mocks.Store) *Store {
+	t.Helper()
+
+	provider := &mocks.Provider{}
+	provider.OpenStoreReturns(store, nil)
+
+	s, err := New(provider)
+	require.NoError(t, err)
+
+	return s
+}
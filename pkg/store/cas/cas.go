@@ -9,6 +9,7 @@ package cas
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/bluele/gcache"
@@ -26,8 +27,22 @@ var logger = log.New("cas-store")
 const (
 	defaultCacheSize = 1000
 	casType          = "local"
+	storeName        = "cas_store"
+
+	// createdTag contains the time (in Unix seconds) at which the content was written.
+	createdTag = "casCreated"
+	// ipfsCIDTag contains the CID of the content that was replicated in IPFS.
+	ipfsCIDTag = "ipfsCID"
 )
 
+// Entry contains information about content stored in the CAS.
+type Entry struct {
+	Key     string
+	Created time.Time
+	Size    int
+	IPFSCID string
+}
+
 type metricsProvider interface {
 	CASIncrementCacheHitCount()
 	CASReadTime(casType string, value time.Duration)
@@ -47,24 +62,35 @@ type CAS struct {
 // New returns a new CAS that uses the passed in provider as a backing store for local CAS storage.
 // ipfsClient is optional, but if provided (not nil), then writes will go to IPFS in addition to the passed in provider.
 // Reads are always done on only the passed in provider.
+// hl is optional and is used to create and parse hashlinks. If nil then a default hashlink instance is used.
 // If no CID version is specified, then v1 will be used by default.
 func New(provider ariesstorage.Provider, casLink string, ipfsClient *ipfs.Client,
-	metrics metricsProvider, cacheSize int, opts ...extendedcasclient.CIDFormatOption) (*CAS, error) {
-	cas, err := provider.OpenStore("cas_store")
+	metrics metricsProvider, cacheSize int, hl *hashlink.HashLink,
+	opts ...extendedcasclient.CIDFormatOption) (*CAS, error) {
+	cas, err := provider.OpenStore(storeName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open store in underlying storage provider: %w", err)
 	}
 
+	err = provider.SetStoreConfig(storeName, ariesstorage.StoreConfiguration{TagNames: []string{createdTag, ipfsCIDTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
 	if cacheSize == 0 {
 		cacheSize = defaultCacheSize
 	}
 
+	if hl == nil {
+		hl = hashlink.New()
+	}
+
 	c := &CAS{
 		cas:        cas,
 		ipfsClient: ipfsClient,
 		opts:       opts,
 		metrics:    metrics,
-		hl:         hashlink.New(),
+		hl:         hl,
 		casLink:    casLink,
 	}
 
@@ -108,10 +134,7 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 
 	logger.Debugf("Writing to CAS store [%s]: %s", resourceHash, content)
 
-	err = p.cas.Put(resourceHash, content)
-	if err != nil {
-		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into underlying storage provider: %w", err))
-	}
+	tags := []ariesstorage.Tag{{Name: createdTag, Value: strconv.FormatInt(time.Now().Unix(), 10)}}
 
 	// add cas link
 	links := []string{p.casLink + "/" + resourceHash}
@@ -119,14 +142,20 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 	if p.ipfsClient != nil {
 		cid, writeErr := p.ipfsClient.WriteWithCIDFormat(content, opts...)
 		if writeErr != nil {
-			return "", orberrors.NewTransient(fmt.Errorf("failed to put content into IPFS (but it was "+
-				"successfully stored in the local storage provider): %w", writeErr))
+			return "", orberrors.NewTransient(fmt.Errorf("failed to put content into IPFS: %w", writeErr))
 		}
 
+		tags = append(tags, ariesstorage.Tag{Name: ipfsCIDTag, Value: cid})
+
 		// add ipfs link
 		links = append(links, "ipfs://"+cid)
 	}
 
+	err = p.cas.Put(resourceHash, content, tags...)
+	if err != nil {
+		return "", orberrors.NewTransient(fmt.Errorf("failed to put content into underlying storage provider: %w", err))
+	}
+
 	if err = p.cache.Set(resourceHash, content); err != nil {
 		// This shouldn't be possible.
 		logger.Warnf("Error caching content for resource hash[%s]: %s", resourceHash, err)
@@ -134,7 +163,7 @@ func (p *CAS) WriteWithCIDFormat(content []byte, opts ...extendedcasclient.CIDFo
 		logger.Debugf("Cached content for resource hash [%s]", resourceHash)
 	}
 
-	metadata, err := p.hl.CreateMetadataFromLinks(links)
+	metadata, err := p.hl.CreateMetadata(content, links)
 	if err != nil {
 		return "", fmt.Errorf("failed to create resource hash from content: %w", err)
 	}
@@ -162,6 +191,109 @@ func (p *CAS) Read(address string) ([]byte, error) {
 	return content.([]byte), nil
 }
 
+// ForEach invokes the given function for each entry in the CAS. Content that was written before the write time was
+// recorded (i.e. by a previous version) is not included.
+func (p *CAS) ForEach(fn func(entry *Entry) error) error {
+	iter, err := p.cas.Query(createdTag)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to query CAS entries: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Errorf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+
+		if !ok {
+			return nil
+		}
+
+		entry, err := getEntry(iter)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+}
+
+// Delete deletes the content at the given address from the underlying local CAS provider. If the content was
+// replicated in IPFS then it is also unpinned from IPFS.
+func (p *CAS) Delete(address string) error {
+	tags, err := p.cas.GetTags(address)
+	if err != nil && !errors.Is(err, ariesstorage.ErrDataNotFound) {
+		return orberrors.NewTransient(fmt.Errorf("failed to get tags for [%s]: %w", address, err))
+	}
+
+	if err := p.cas.Delete(address); err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete [%s] from the local CAS provider: %w",
+			address, err))
+	}
+
+	p.cache.Remove(address)
+
+	if p.ipfsClient == nil {
+		return nil
+	}
+
+	for _, tag := range tags {
+		if tag.Name != ipfsCIDTag || tag.Value == "" {
+			continue
+		}
+
+		if err := p.ipfsClient.Unpin(tag.Value); err != nil {
+			return fmt.Errorf("failed to unpin [%s] from IPFS (but it was deleted from the local "+
+				"storage provider): %w", tag.Value, err)
+		}
+	}
+
+	return nil
+}
+
+func getEntry(iter ariesstorage.Iterator) (*Entry, error) {
+	key, err := iter.Key()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get key from iterator: %w", err))
+	}
+
+	value, err := iter.Value()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get value from iterator: %w", err))
+	}
+
+	tags, err := iter.Tags()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get tags from iterator: %w", err))
+	}
+
+	entry := &Entry{Key: key, Size: len(value)}
+
+	for _, tag := range tags {
+		switch tag.Name {
+		case createdTag:
+			created, e := strconv.ParseInt(tag.Value, 10, 64)
+			if e != nil {
+				return nil, fmt.Errorf("invalid value for tag [%s] of [%s]: %w", createdTag, key, e)
+			}
+
+			entry.Created = time.Unix(created, 0)
+		case ipfsCIDTag:
+			entry.IPFSCID = tag.Value
+		}
+	}
+
+	return entry, nil
+}
+
 func (p *CAS) get(address string) ([]byte, error) {
 	startTime := time.Now()
 
@@ -15,6 +15,7 @@ import (
 	ariesmemstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mem"
 	ariesmockstorage "github.com/hyperledger/aries-framework-go/component/storageutil/mock"
 	ariesstorage "github.com/hyperledger/aries-framework-go/spi/storage"
+	"github.com/multiformats/go-multihash"
 	dctest "github.com/ory/dockertest/v3"
 	dc "github.com/ory/dockertest/v3/docker"
 	"github.com/stretchr/testify/require"
@@ -32,18 +33,147 @@ const casLink = "https://domain.com/cas"
 func TestNew(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil,
-			&orbmocks.MetricsProvider{}, 0)
+			&orbmocks.MetricsProvider{}, 0, nil)
 
 		require.NoError(t, err)
 		require.NotNil(t, provider)
 	})
 	t.Run("Fail to store in underlying storage provider", func(t *testing.T) {
 		provider, err := localcas.New(&ariesmockstorage.Provider{ErrOpenStore: errors.New("open store error")},
-			casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 		require.EqualError(t, err, "failed to open store in underlying storage provider: open store error")
 		require.Nil(t, provider)
 	})
+	t.Run("Fail to set store config", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{ErrSetStoreConfig: errors.New("set config error")},
+			casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+
+		require.EqualError(t, err, "failed to set store configuration: set config error")
+		require.Nil(t, provider)
+	})
+}
+
+func TestProvider_HashLink(t *testing.T) {
+	hl := hashlink.New(hashlink.WithMultihashCode(multihash.SHA3_256),
+		hashlink.WithAdditionalMultihashCodes(multihash.SHA2_256))
+
+	provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil,
+		&orbmocks.MetricsProvider{}, 0, hl)
+	require.NoError(t, err)
+
+	address, err := provider.Write([]byte("content"))
+	require.NoError(t, err)
+
+	info, err := hl.ParseHashLink(address)
+	require.NoError(t, err)
+	require.Equal(t, []string{casLink + "/" + info.ResourceHash}, info.Links)
+	require.Len(t, info.ResourceHashes, 1)
+	require.NoError(t, hl.VerifyContent([]byte("content"), info))
+
+	content, err := provider.Read(info.ResourceHash)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(content))
+}
+
+func TestProvider_ForEach_Delete(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil,
+			&orbmocks.MetricsProvider{}, 0, nil)
+		require.NoError(t, err)
+
+		hl1, err := provider.Write([]byte("content1"))
+		require.NoError(t, err)
+
+		hl2, err := provider.Write([]byte("content22"))
+		require.NoError(t, err)
+
+		rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+		require.NoError(t, err)
+
+		rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+		require.NoError(t, err)
+
+		entries := make(map[string]*localcas.Entry)
+
+		require.NoError(t, provider.ForEach(func(entry *localcas.Entry) error {
+			entries[entry.Key] = entry
+
+			return nil
+		}))
+
+		require.Len(t, entries, 2)
+		require.Equal(t, len("content1"), entries[rh1].Size)
+		require.Equal(t, len("content22"), entries[rh2].Size)
+		require.WithinDuration(t, time.Now(), entries[rh1].Created, 5*time.Second)
+		require.Empty(t, entries[rh1].IPFSCID)
+
+		require.NoError(t, provider.Delete(rh1))
+
+		_, err = provider.Read(rh1)
+		require.True(t, errors.Is(err, orberrors.ErrContentNotFound))
+
+		content, err := provider.Read(rh2)
+		require.NoError(t, err)
+		require.Equal(t, "content22", string(content))
+
+		var keys []string
+
+		require.NoError(t, provider.ForEach(func(entry *localcas.Entry) error {
+			keys = append(keys, entry.Key)
+
+			return nil
+		}))
+
+		require.Equal(t, []string{rh2}, keys)
+
+		// Deleting content that doesn't exist isn't an error.
+		require.NoError(t, provider.Delete(rh1))
+	})
+
+	t.Run("Callback error", func(t *testing.T) {
+		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, nil,
+			&orbmocks.MetricsProvider{}, 0, nil)
+		require.NoError(t, err)
+
+		_, err = provider.Write([]byte("content1"))
+		require.NoError(t, err)
+
+		errExpected := errors.New("injected callback error")
+
+		err = provider.ForEach(func(entry *localcas.Entry) error {
+			return errExpected
+		})
+		require.True(t, errors.Is(err, errExpected))
+	})
+
+	t.Run("Query error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{
+				ErrQuery: errors.New("query error"),
+			},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+		require.NoError(t, err)
+
+		err = provider.ForEach(func(entry *localcas.Entry) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("Delete error", func(t *testing.T) {
+		provider, err := localcas.New(&ariesmockstorage.Provider{
+			OpenStoreReturn: &ariesmockstorage.Store{
+				ErrDelete: errors.New("delete error"),
+			},
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+		require.NoError(t, err)
+
+		err = provider.Delete("uEiDat0G2KJ59zMHtQjMMrhrMwrdVzoB5ws1dS1Nmyfdppg")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "delete error")
+		require.True(t, orberrors.IsTransient(err))
+	})
 }
 
 func TestProvider_Write_Read(t *testing.T) {
@@ -54,10 +184,10 @@ func TestProvider_Write_Read(t *testing.T) {
 	}()
 
 	t.Run("Success", func(t *testing.T) {
-		client := ipfs.New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		client := ipfs.New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 
 		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, client,
-			&orbmocks.MetricsProvider{}, 0)
+			&orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		var hl string
@@ -86,7 +216,7 @@ func TestProvider_Write_Read(t *testing.T) {
 			OpenStoreReturn: &ariesmockstorage.Store{
 				ErrPut: errors.New("put error"),
 			},
-		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		address, err := provider.Write([]byte("content"))
@@ -99,7 +229,7 @@ func TestProvider_Write_Read(t *testing.T) {
 				OpenStoreReturn: &ariesmockstorage.Store{
 					ErrGet: ariesstorage.ErrDataNotFound,
 				},
-			}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 			require.NoError(t, err)
 
 			content, err := provider.Read("AVUSIO1wArQ56ayEXyI1fYIrrBREcw-9tgFtPslDIpe57J9z")
@@ -111,7 +241,7 @@ func TestProvider_Write_Read(t *testing.T) {
 				OpenStoreReturn: &ariesmockstorage.Store{
 					ErrGet: errors.New("get error"),
 				},
-			}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 			require.NoError(t, err)
 
@@ -121,10 +251,10 @@ func TestProvider_Write_Read(t *testing.T) {
 		})
 	})
 	t.Run("Invalid CID version", func(t *testing.T) {
-		client := ipfs.New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		client := ipfs.New("localhost:5001", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 
 		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, client,
-			&orbmocks.MetricsProvider{}, 0, extendedcasclient.WithCIDVersion(2))
+			&orbmocks.MetricsProvider{}, 0, nil, extendedcasclient.WithCIDVersion(2))
 		require.NoError(t, err)
 
 		address, err := provider.Write([]byte("content"))
@@ -132,15 +262,15 @@ func TestProvider_Write_Read(t *testing.T) {
 		require.Equal(t, "", address)
 	})
 	t.Run("Fail to write to IPFS", func(t *testing.T) {
-		client := ipfs.New("InvalidURL", 5*time.Second, 0, &orbmocks.MetricsProvider{})
+		client := ipfs.New("InvalidURL", 5*time.Second, 0, &orbmocks.MetricsProvider{}, nil)
 
 		provider, err := localcas.New(ariesmemstorage.NewProvider(), casLink, client,
-			&orbmocks.MetricsProvider{}, 0)
+			&orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		address, err := provider.Write([]byte("content"))
-		require.Contains(t, err.Error(), `failed to put content into IPFS (but it was successfully stored in `+
-			`the local storage provider): Post "http://InvalidURL/api/v0/add?cid-version=1": dial tcp:`)
+		require.Contains(t, err.Error(),
+			`failed to put content into IPFS: Post "http://InvalidURL/api/v0/add?cid-version=1": dial tcp:`)
 		require.Empty(t, address)
 	})
 	t.Run("Local CAS write and read -> success", func(t *testing.T) {
@@ -151,7 +281,7 @@ func TestProvider_Write_Read(t *testing.T) {
 			OpenStoreReturn: &ariesmockstorage.Store{
 				GetReturn: content1,
 			},
-		}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		// Should read from DB and save to cache.
@@ -181,7 +311,7 @@ func TestProvider_Write_Read(t *testing.T) {
 
 	t.Run("Empty content", func(t *testing.T) {
 		provider, err := localcas.New(&ariesmockstorage.Provider{}, casLink,
-			nil, &orbmocks.MetricsProvider{}, 0)
+			nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		address, err := provider.Write(nil)
@@ -17,7 +17,12 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
-const nameSpace = "didanchor"
+const (
+	nameSpace = "didanchor"
+
+	// anchorTag is used to query all DID anchor references.
+	anchorTag = "didAnchor"
+)
 
 var logger = log.New("didanchor-store")
 
@@ -28,6 +33,11 @@ func New(provider storage.Provider) (*Store, error) {
 		return nil, fmt.Errorf("failed to open did anchor store: %w", err)
 	}
 
+	err = provider.SetStoreConfig(nameSpace, storage.StoreConfiguration{TagNames: []string{anchorTag}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set store configuration: %w", err)
+	}
+
 	return &Store{
 		store: store,
 	}, nil
@@ -46,6 +56,7 @@ func (s *Store) PutBulk(suffixes []string, cid string) error {
 		op := storage.Operation{
 			Key:   suffix,
 			Value: []byte(cid),
+			Tags:  []storage.Tag{{Name: anchorTag}},
 		}
 
 		operations[i] = op
@@ -100,3 +111,50 @@ func (s *Store) Get(suffix string) (string, error) {
 
 	return anchor, nil
 }
+
+// GetAnchors returns the distinct set of latest anchors for all DIDs. Note that references that were
+// stored before the anchor tag was introduced are not returned.
+func (s *Store) GetAnchors() ([]string, error) {
+	iter, err := s.store.Query(anchorTag)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query did anchor references: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Errorf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	anchorMap := make(map[string]struct{})
+
+	var anchors []string
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		anchorBytes, err := iter.Value()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get value from iterator: %w", err))
+		}
+
+		anchor := string(anchorBytes)
+
+		if _, exists := anchorMap[anchor]; !exists {
+			anchorMap[anchor] = struct{}{}
+
+			anchors = append(anchors, anchor)
+		}
+	}
+
+	logger.Debugf("retrieved %d distinct anchors", len(anchors))
+
+	return anchors, nil
+}
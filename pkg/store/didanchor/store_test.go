@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/didanchor"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
 	"github.com/trustbloc/orb/pkg/store/mocks"
 )
 
@@ -39,6 +40,16 @@ func TestNew(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to open did anchor store: open store error")
 		require.Nil(t, s)
 	})
+
+	t.Run("error - set store config fails", func(t *testing.T) {
+		provider := &mocks.Provider{}
+		provider.SetStoreConfigReturns(fmt.Errorf("set config error"))
+
+		s, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "failed to set store configuration: set config error")
+		require.Nil(t, s)
+	})
 }
 
 func TestStore_PutAll(t *testing.T) {
@@ -159,3 +170,62 @@ func TestStore_Get(t *testing.T) {
 		require.Contains(t, err.Error(), "store error")
 	})
 }
+
+func TestStore_GetAnchors(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		anchors, err := s.GetAnchors()
+		require.NoError(t, err)
+		require.Empty(t, anchors)
+
+		require.NoError(t, s.PutBulk([]string{"suffix-1", "suffix-2"}, "cid-1"))
+		require.NoError(t, s.PutBulk([]string{"suffix-3"}, "cid-2"))
+
+		anchors, err = s.GetAnchors()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{"cid-1", "cid-2"}, anchors)
+
+		require.NoError(t, s.PutBulk([]string{"suffix-3"}, "cid-1"))
+
+		anchors, err = s.GetAnchors()
+		require.NoError(t, err)
+		require.Equal(t, []string{"cid-1"}, anchors)
+	})
+
+	t.Run("error - query error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		anchors, err := s.GetAnchors()
+		require.Error(t, err)
+		require.Nil(t, anchors)
+		require.Contains(t, err.Error(), "query error")
+		require.True(t, orberrors.IsTransient(err))
+	})
+
+	t.Run("error - iterator error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		_, err = s.GetAnchors()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator error")
+	})
+}
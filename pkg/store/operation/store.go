@@ -135,3 +135,55 @@ func (s *Store) Get(suffix string) ([]*operation.AnchoredOperation, error) {
 
 	return ops, nil
 }
+
+// ForEachSuffix invokes the given function for each distinct suffix that has operations in the store.
+func (s *Store) ForEachSuffix(fn func(suffix string) error) error {
+	iter, err := s.store.Query(index)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to query suffixes: %w", err))
+	}
+
+	defer func() {
+		if errClose := iter.Close(); errClose != nil {
+			logger.Errorf("failed to close iterator: %s", errClose.Error())
+		}
+	}()
+
+	suffixes := make(map[string]struct{})
+
+	for {
+		ok, err := iter.Next()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("iterator error: %w", err))
+		}
+
+		if !ok {
+			break
+		}
+
+		tags, err := iter.Tags()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get tags from iterator: %w", err))
+		}
+
+		for _, tag := range tags {
+			if tag.Name != index {
+				continue
+			}
+
+			if _, exists := suffixes[tag.Value]; exists {
+				continue
+			}
+
+			suffixes[tag.Value] = struct{}{}
+
+			if err := fn(tag.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	logger.Debugf("iterated over %d suffixes", len(suffixes))
+
+	return nil
+}
@@ -187,6 +187,93 @@ func TestStore_Get(t *testing.T) {
 	})
 }
 
+func TestStore_ForEachSuffix(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{
+			{Type: operation.TypeCreate, UniqueSuffix: "suffix1"},
+			{Type: operation.TypeUpdate, UniqueSuffix: "suffix1"},
+			{Type: operation.TypeCreate, UniqueSuffix: "suffix2"},
+		}))
+
+		var suffixes []string
+
+		require.NoError(t, s.ForEachSuffix(func(suffix string) error {
+			suffixes = append(suffixes, suffix)
+
+			return nil
+		}))
+
+		require.ElementsMatch(t, []string{"suffix1", "suffix2"}, suffixes)
+	})
+
+	t.Run("error - callback error", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Put([]*operation.AnchoredOperation{getTestOperation()}))
+
+		errExpected := fmt.Errorf("callback error")
+
+		err = s.ForEachSuffix(func(string) error { return errExpected })
+		require.Equal(t, errExpected, err)
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.ForEachSuffix(func(string) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator next() error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, fmt.Errorf("iterator next() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.ForEachSuffix(func(string) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator next() error")
+	})
+
+	t.Run("error - iterator tags() error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(true, nil)
+		iterator.TagsReturns(nil, fmt.Errorf("iterator tags() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.ForEachSuffix(func(string) error { return nil })
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "iterator tags() error")
+	})
+}
+
 func getTestOperation() *operation.AnchoredOperation {
 	return &operation.AnchoredOperation{
 		Type:         operation.TypeCreate,
@@ -9,7 +9,9 @@ package vcstatus
 import (
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/spi/storage"
@@ -20,8 +22,16 @@ import (
 )
 
 const (
-	namespace = "vcstatus"
-	index     = "vcID"
+	namespace          = "vcstatus"
+	index              = "vcID"
+	currentStatusIndex = "currentStatus"
+
+	// currentStatusKeyPrefix is the key prefix of the record that holds the current status of a VC.
+	currentStatusKeyPrefix = "current_"
+
+	// backfillKey is the key of the record which indicates that the current status records were
+	// created for the VCs that were stored before current statuses were recorded.
+	backfillKey = "current_status_backfilled"
 )
 
 var logger = log.New("vc-status")
@@ -33,16 +43,24 @@ func New(provider storage.Provider) (*Store, error) {
 		return nil, fmt.Errorf("failed to open vc-status store: %w", err)
 	}
 
-	err = provider.SetStoreConfig(namespace, storage.StoreConfiguration{TagNames: []string{index}})
+	err = provider.SetStoreConfig(namespace,
+		storage.StoreConfiguration{TagNames: []string{index, currentStatusIndex}})
 	if err != nil {
 		return nil, fmt.Errorf("failed to set store configuration: %w", err)
 	}
 
-	return &Store{
+	s := &Store{
 		store:     store,
 		marshal:   json.Marshal,
 		unmarshal: json.Unmarshal,
-	}, nil
+	}
+
+	err = s.backfillCurrentStatus()
+	if err != nil {
+		return nil, fmt.Errorf("backfill current status: %w", err)
+	}
+
+	return s, nil
 }
 
 // Store is db implementation of vc status store.
@@ -52,21 +70,27 @@ type Store struct {
 	unmarshal func(data []byte, v interface{}) error
 }
 
-// AddStatus adds verifiable credential proof collecting status.
+// AddStatus adds verifiable credential proof collecting status. The status is added to the status history
+// of the VC and also replaces the current status of the VC.
 func (s *Store) AddStatus(vcID string, status proof.VCStatus) error {
-	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
-
-	tag := storage.Tag{
-		Name:  index,
-		Value: vcIDEncoded,
-	}
-
 	statusBytes, err := s.marshal(status)
 	if err != nil {
 		return fmt.Errorf("marshal: %w", err)
 	}
 
-	err = s.store.Put(uuid.New().String(), statusBytes, tag)
+	err = s.store.Batch([]storage.Operation{
+		{
+			Key:   uuid.New().String(),
+			Value: statusBytes,
+			Tags: []storage.Tag{
+				{
+					Name:  index,
+					Value: base64.RawURLEncoding.EncodeToString([]byte(vcID)),
+				},
+			},
+		},
+		newCurrentStatusOperation(vcID, status, statusBytes),
+	})
 	if err != nil {
 		return orberrors.NewTransient(fmt.Errorf("failed to store vcID[%s] status '%s': %w",
 			vcID, status, err))
@@ -128,3 +152,263 @@ func (s *Store) GetStatus(vcID string) (proof.VCStatus, error) {
 
 	return status, nil
 }
+
+// GetVCIDsByStatus returns the IDs of all verifiable credentials whose current status is the given status.
+func (s *Store) GetVCIDsByStatus(status proof.VCStatus) ([]string, error) {
+	query := fmt.Sprintf("%s:%s", currentStatusIndex, status)
+
+	iter, err := s.store.Query(query)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query vc statuses[%s]: %w", query, err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error for status[%s]: %w", status, err))
+	}
+
+	var vcIDs []string
+
+	for ok {
+		tags, e := iter.Tags()
+		if e != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("failed to get iterator tags for status[%s]: %w", status, e))
+		}
+
+		vcID, e := getVCID(tags)
+		if e != nil {
+			return nil, e
+		}
+
+		vcIDs = append(vcIDs, vcID)
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for status[%s]: %w", status, err))
+		}
+	}
+
+	logger.Debugf("found %d vcIDs with status '%s'", len(vcIDs), status)
+
+	return vcIDs, nil
+}
+
+// Delete deletes all statuses (including the current status) for the given verifiable credential.
+func (s *Store) Delete(vcID string) error {
+	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
+
+	query := fmt.Sprintf("%s:%s", index, vcIDEncoded)
+
+	iter, err := s.store.Query(query)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to get statuses for vcID[%s] query[%s]: %w",
+			vcID, query, err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	ok, err := iter.Next()
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("iterator error for vcID[%s] statuses: %w", vcID, err))
+	}
+
+	var operations []storage.Operation
+
+	for ok {
+		key, e := iter.Key()
+		if e != nil {
+			return orberrors.NewTransient(fmt.Errorf("failed to get iterator key for vcID[%s]: %w", vcID, e))
+		}
+
+		operations = append(operations, storage.Operation{Key: key})
+
+		ok, err = iter.Next()
+		if err != nil {
+			return orberrors.NewTransient(fmt.Errorf("iterator error for vcID[%s]: %w", vcID, err))
+		}
+	}
+
+	if len(operations) == 0 {
+		logger.Debugf("no statuses to delete for vcID[%s], nothing to do", vcID)
+
+		return nil
+	}
+
+	err = s.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete statuses for vcID[%s]: %w", vcID, err))
+	}
+
+	logger.Debugf("deleted %d statuses for vcID[%s]", len(operations), vcID)
+
+	return nil
+}
+
+func getVCID(tags []storage.Tag) (string, error) {
+	for _, tag := range tags {
+		if tag.Name != index {
+			continue
+		}
+
+		vcID, err := base64.RawURLEncoding.DecodeString(tag.Value)
+		if err != nil {
+			return "", fmt.Errorf("decode vcID tag[%s]: %w", tag.Value, err)
+		}
+
+		return string(vcID), nil
+	}
+
+	return "", fmt.Errorf("tag [%s] not found", index)
+}
+
+// backfillCurrentStatus creates the current status record for each VC that was stored before current statuses
+// were recorded (i.e. the VC only has a status history). This is done only once.
+func (s *Store) backfillCurrentStatus() error {
+	_, err := s.store.Get(backfillKey)
+	if err == nil {
+		return nil
+	}
+
+	if !errors.Is(err, storage.ErrDataNotFound) {
+		return orberrors.NewTransient(fmt.Errorf("get backfill record: %w", err))
+	}
+
+	statuses, err := s.getStatusesWithoutCurrent()
+	if err != nil {
+		return err
+	}
+
+	operations := make([]storage.Operation, 0, len(statuses)+1)
+
+	for vcID, status := range statuses {
+		statusBytes, e := s.marshal(status)
+		if e != nil {
+			return fmt.Errorf("marshal: %w", e)
+		}
+
+		operations = append(operations, newCurrentStatusOperation(vcID, status, statusBytes))
+	}
+
+	operations = append(operations, storage.Operation{Key: backfillKey, Value: []byte("{}")})
+
+	err = s.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("store current statuses: %w", err))
+	}
+
+	if len(statuses) > 0 {
+		logger.Infof("Backfilled the current status of %d VCs", len(statuses))
+	}
+
+	return nil
+}
+
+// getStatusesWithoutCurrent returns the status (from the status history) of each VC that doesn't have
+// a current status record. As in GetStatus, the 'completed' status takes precedence.
+func (s *Store) getStatusesWithoutCurrent() (map[string]proof.VCStatus, error) {
+	iter, err := s.store.Query(index)
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to query vc statuses: %w", err))
+	}
+
+	defer func() {
+		err = iter.Close()
+		if err != nil {
+			logger.Errorf("failed to close iterator: %s", err.Error())
+		}
+	}()
+
+	statuses := make(map[string]proof.VCStatus)
+	hasCurrent := make(map[string]bool)
+
+	ok, err := iter.Next()
+	if err != nil {
+		return nil, orberrors.NewTransient(fmt.Errorf("iterator error for vc statuses: %w", err))
+	}
+
+	for ok {
+		vcID, status, isCurrent, e := s.getIteratorStatus(iter)
+		if e != nil {
+			return nil, e
+		}
+
+		if isCurrent {
+			hasCurrent[vcID] = true
+		} else if statuses[vcID] != proof.VCStatusCompleted {
+			statuses[vcID] = status
+		}
+
+		ok, err = iter.Next()
+		if err != nil {
+			return nil, orberrors.NewTransient(fmt.Errorf("iterator error for vc statuses: %w", err))
+		}
+	}
+
+	for vcID := range hasCurrent {
+		delete(statuses, vcID)
+	}
+
+	return statuses, nil
+}
+
+func (s *Store) getIteratorStatus(iter storage.Iterator) (string, proof.VCStatus, bool, error) {
+	key, err := iter.Key()
+	if err != nil {
+		return "", "", false, orberrors.NewTransient(fmt.Errorf("failed to get iterator key: %w", err))
+	}
+
+	tags, err := iter.Tags()
+	if err != nil {
+		return "", "", false, orberrors.NewTransient(fmt.Errorf("failed to get iterator tags: %w", err))
+	}
+
+	vcID, err := getVCID(tags)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	value, err := iter.Value()
+	if err != nil {
+		return "", "", false, orberrors.NewTransient(fmt.Errorf("failed to get iterator value: %w", err))
+	}
+
+	var status proof.VCStatus
+
+	err = s.unmarshal(value, &status)
+	if err != nil {
+		return "", "", false, fmt.Errorf("unmarshal status: %w", err)
+	}
+
+	return vcID, status, strings.HasPrefix(key, currentStatusKeyPrefix), nil
+}
+
+func newCurrentStatusOperation(vcID string, status proof.VCStatus, statusBytes []byte) storage.Operation {
+	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
+
+	return storage.Operation{
+		Key:   currentStatusKeyPrefix + vcIDEncoded,
+		Value: statusBytes,
+		Tags: []storage.Tag{
+			{
+				Name:  index,
+				Value: vcIDEncoded,
+			},
+			{
+				Name:  currentStatusIndex,
+				Value: string(status),
+			},
+		},
+	}
+}
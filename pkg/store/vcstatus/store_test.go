@@ -7,11 +7,15 @@ SPDX-License-Identifier: Apache-2.0
 package vcstatus
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/hyperledger/aries-framework-go/spi/storage"
 	"github.com/stretchr/testify/require"
 
 	"github.com/trustbloc/orb/pkg/anchor/proof"
@@ -48,6 +52,100 @@ func TestNew(t *testing.T) {
 		require.Contains(t, err.Error(), "failed to set store configuration: set store config error")
 		require.Nil(t, s)
 	})
+
+	t.Run("error - get backfill record fails", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetReturns(nil, fmt.Errorf("get error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "get error")
+		require.Nil(t, s)
+	})
+
+	t.Run("error - backfill query fails", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.GetReturns(nil, storage.ErrDataNotFound)
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+		require.Nil(t, s)
+	})
+
+	t.Run("error - backfill batch fails", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+		iterator.NextReturns(false, nil)
+
+		store := &mocks.Store{}
+		store.GetReturns(nil, storage.ErrDataNotFound)
+		store.QueryReturns(iterator, nil)
+		store.BatchReturns(fmt.Errorf("batch error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch error")
+		require.Nil(t, s)
+	})
+}
+
+func TestStore_BackfillCurrentStatus(t *testing.T) {
+	const (
+		vcID2 = "vcID2"
+		vcID3 = "vcID3"
+	)
+
+	provider := mem.NewProvider()
+
+	store, err := provider.OpenStore(namespace)
+	require.NoError(t, err)
+
+	// Add status history records as they were stored before current statuses were recorded.
+	putHistory := func(id string, status proof.VCStatus) {
+		statusBytes, e := json.Marshal(status)
+		require.NoError(t, e)
+
+		require.NoError(t, store.Put(uuid.New().String(), statusBytes,
+			storage.Tag{Name: index, Value: base64.RawURLEncoding.EncodeToString([]byte(id))}))
+	}
+
+	putHistory(vcID, proof.VCStatusInProcess)
+	putHistory(vcID2, proof.VCStatusInProcess)
+	putHistory(vcID2, proof.VCStatusCompleted)
+
+	s, err := New(provider)
+	require.NoError(t, err)
+
+	// Added after the backfill so it has a current status record.
+	require.NoError(t, s.AddStatus(vcID3, proof.VCStatusInProcess))
+
+	vcIDs, err := s.GetVCIDsByStatus(proof.VCStatusInProcess)
+	require.NoError(t, err)
+	require.Len(t, vcIDs, 2)
+	require.Contains(t, vcIDs, vcID)
+	require.Contains(t, vcIDs, vcID3)
+
+	vcIDs, err = s.GetVCIDsByStatus(proof.VCStatusCompleted)
+	require.NoError(t, err)
+	require.Equal(t, []string{vcID2}, vcIDs)
+
+	// The backfill is only performed once.
+	s, err = New(provider)
+	require.NoError(t, err)
+
+	vcIDs, err = s.GetVCIDsByStatus(proof.VCStatusCompleted)
+	require.NoError(t, err)
+	require.Equal(t, []string{vcID2}, vcIDs)
 }
 
 func TestStore_Put(t *testing.T) {
@@ -80,7 +178,7 @@ func TestStore_Put(t *testing.T) {
 
 	t.Run("error - store error ", func(t *testing.T) {
 		store := &mocks.Store{}
-		store.PutReturns(fmt.Errorf("put error"))
+		store.BatchReturns(fmt.Errorf("batch error"))
 
 		provider := &mocks.Provider{}
 		provider.OpenStoreReturns(store, nil)
@@ -90,7 +188,7 @@ func TestStore_Put(t *testing.T) {
 
 		err = s.AddStatus(vcID, proof.VCStatusInProcess)
 		require.Error(t, err)
-		require.Contains(t, err.Error(), "put error")
+		require.Contains(t, err.Error(), "batch error")
 	})
 }
 
@@ -214,3 +312,130 @@ func TestStore_Get(t *testing.T) {
 		require.Contains(t, err.Error(), "iterator value() error")
 	})
 }
+
+func TestStore_GetVCIDsByStatus(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		const vcID2 = "vcID2"
+
+		require.NoError(t, s.AddStatus(vcID, proof.VCStatusInProcess))
+		require.NoError(t, s.AddStatus(vcID2, proof.VCStatusInProcess))
+		require.NoError(t, s.AddStatus(vcID2, proof.VCStatusCompleted))
+
+		// Only the current status is considered.
+		vcIDs, err := s.GetVCIDsByStatus(proof.VCStatusInProcess)
+		require.NoError(t, err)
+		require.Equal(t, []string{vcID}, vcIDs)
+
+		vcIDs, err = s.GetVCIDsByStatus(proof.VCStatusCompleted)
+		require.NoError(t, err)
+		require.Equal(t, []string{vcID2}, vcIDs)
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDsByStatus(proof.VCStatusInProcess)
+		require.Error(t, err)
+		require.Empty(t, vcIDs)
+		require.Contains(t, err.Error(), "query error")
+	})
+
+	t.Run("error - iterator tags() error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+
+		iterator.NextReturns(true, nil)
+		iterator.TagsReturns(nil, fmt.Errorf("iterator tags() error"))
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		vcIDs, err := s.GetVCIDsByStatus(proof.VCStatusInProcess)
+		require.Error(t, err)
+		require.Empty(t, vcIDs)
+		require.Contains(t, err.Error(), "iterator tags() error")
+	})
+}
+
+func TestStore_Delete(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		require.NoError(t, s.AddStatus(vcID, proof.VCStatusInProcess))
+		require.NoError(t, s.AddStatus(vcID, proof.VCStatusCompleted))
+
+		require.NoError(t, s.Delete(vcID))
+
+		_, err = s.GetStatus(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "not found")
+
+		vcIDs, err := s.GetVCIDsByStatus(proof.VCStatusInProcess)
+		require.NoError(t, err)
+		require.Empty(t, vcIDs)
+	})
+
+	t.Run("success - nothing to delete", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		require.NoError(t, s.Delete(vcID))
+	})
+
+	t.Run("error - batch error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+
+		iterator.NextReturnsOnCall(0, true, nil)
+		iterator.NextReturnsOnCall(1, false, nil)
+		iterator.KeyReturns("key", nil)
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+		store.BatchReturns(fmt.Errorf("batch error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Delete(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch error")
+	})
+
+	t.Run("error - store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Delete(vcID)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+	})
+}
@@ -50,44 +50,83 @@ type Store struct {
 
 // Put saves witnesses into anchor credential witness store.
 func (s *Store) Put(vcID string, witnesses []*proof.WitnessProof) error {
-	operations := make([]storage.Operation, len(witnesses))
+	operations, err := newPutOperations(vcID, witnesses)
+	if err != nil {
+		return err
+	}
 
-	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
+	err = s.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to store witnesses for vcID[%s]: %w", vcID, err))
+	}
 
-	for i, w := range witnesses {
-		value, err := json.Marshal(w)
-		if err != nil {
-			return fmt.Errorf("failed to marshal anchor credential witness: %w", err)
-		}
+	logger.Debugf("stored %d witnesses for vcID[%s]", len(witnesses), vcID)
 
-		logger.Debugf("adding %s witness to storage batch: %s", w.Type, w.Witness)
+	return nil
+}
 
-		op := storage.Operation{
-			Key:   uuid.New().String(),
-			Value: value,
-			Tags: []storage.Tag{
-				{
-					Name:  vcIndex,
-					Value: vcIDEncoded,
-				},
-			},
-		}
+// Replace replaces all witnesses associated with VC ID with the given witnesses. The existing witnesses
+// are deleted and the new witnesses are stored in a single batch.
+func (s *Store) Replace(vcID string, witnesses []*proof.WitnessProof) error {
+	witnessKeys, err := s.getKeys(vcID)
+	if err != nil {
+		return err
+	}
 
-		operations[i] = op
+	putOperations, err := newPutOperations(vcID, witnesses)
+	if err != nil {
+		return err
+	}
+
+	operations := make([]storage.Operation, 0, len(witnessKeys)+len(putOperations))
+
+	for _, k := range witnessKeys {
+		operations = append(operations, storage.Operation{Key: k})
 	}
 
-	err := s.store.Batch(operations)
+	operations = append(operations, putOperations...)
+
+	err = s.store.Batch(operations)
 	if err != nil {
-		return orberrors.NewTransient(fmt.Errorf("failed to store witnesses for vcID[%s]: %w", vcID, err))
+		return orberrors.NewTransient(fmt.Errorf("failed to replace witnesses for vcID[%s]: %w", vcID, err))
 	}
 
-	logger.Debugf("stored %d witnesses for vcID[%s]", len(witnesses), vcID)
+	logger.Debugf("replaced %d witnesses with %d witnesses for vcID[%s]", len(witnessKeys), len(witnesses), vcID)
 
 	return nil
 }
 
 // Delete deletes all witnesses associated with VC ID.
 func (s *Store) Delete(vcID string) error {
+	witnessKeys, err := s.getKeys(vcID)
+	if err != nil {
+		return err
+	}
+
+	if len(witnessKeys) == 0 {
+		logger.Debugf("no witnesses to delete for vcID[%s], nothing to do", vcID)
+
+		return nil
+	}
+
+	operations := make([]storage.Operation, len(witnessKeys))
+
+	for i, k := range witnessKeys {
+		operations[i] = storage.Operation{Key: k}
+	}
+
+	err = s.store.Batch(operations)
+	if err != nil {
+		return orberrors.NewTransient(fmt.Errorf("failed to delete witnesses for vcID[%s]: %w", vcID, err))
+	}
+
+	logger.Debugf("deleted %d witnesses for vcID[%s]", len(witnessKeys), vcID)
+
+	return nil
+}
+
+// getKeys returns the keys of all witnesses associated with VC ID.
+func (s *Store) getKeys(vcID string) ([]string, error) {
 	var err error
 
 	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
@@ -95,7 +134,7 @@ func (s *Store) Delete(vcID string) error {
 
 	iter, err := s.store.Query(query)
 	if err != nil {
-		return orberrors.NewTransient(fmt.Errorf("failed to get witnesses for[%s]: %w", query, err))
+		return nil, orberrors.NewTransient(fmt.Errorf("failed to get witnesses for[%s]: %w", query, err))
 	}
 
 	defer func() {
@@ -107,13 +146,7 @@ func (s *Store) Delete(vcID string) error {
 
 	ok, err := iter.Next()
 	if err != nil {
-		return fmt.Errorf("iterator error for vcID[%s] : %w", vcID, err)
-	}
-
-	if !ok {
-		logger.Debugf("no witnesses to delete for vcID[%s], nothing to do", vcID)
-
-		return nil
+		return nil, fmt.Errorf("iterator error for vcID[%s] : %w", vcID, err)
 	}
 
 	var witnessKeys []string
@@ -123,31 +156,18 @@ func (s *Store) Delete(vcID string) error {
 
 		key, err = iter.Key()
 		if err != nil {
-			return fmt.Errorf("failed to get iterator value for vcID[%s]: %w", vcID, err)
+			return nil, fmt.Errorf("failed to get iterator value for vcID[%s]: %w", vcID, err)
 		}
 
 		witnessKeys = append(witnessKeys, key)
 
 		ok, err = iter.Next()
 		if err != nil {
-			return fmt.Errorf("iterator error for vcID[%s] : %w", vcID, err)
+			return nil, fmt.Errorf("iterator error for vcID[%s] : %w", vcID, err)
 		}
 	}
 
-	operations := make([]storage.Operation, len(witnessKeys))
-
-	for i, k := range witnessKeys {
-		operations[i] = storage.Operation{Key: k}
-	}
-
-	err = s.store.Batch(operations)
-	if err != nil {
-		return orberrors.NewTransient(fmt.Errorf("failed to delete witnesses for vcID[%s]: %w", vcID, err))
-	}
-
-	logger.Debugf("deleted %d witnesses for vcID[%s]", len(witnessKeys), vcID)
-
-	return nil
+	return witnessKeys, nil
 }
 
 // Get retrieves witnesses for the given vc id.
@@ -291,3 +311,31 @@ func (s *Store) AddProof(vcID, witness string, p []byte) error { //nolint:funlen
 
 	return nil
 }
+
+func newPutOperations(vcID string, witnesses []*proof.WitnessProof) ([]storage.Operation, error) {
+	operations := make([]storage.Operation, len(witnesses))
+
+	vcIDEncoded := base64.RawURLEncoding.EncodeToString([]byte(vcID))
+
+	for i, w := range witnesses {
+		value, err := json.Marshal(w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal anchor credential witness: %w", err)
+		}
+
+		logger.Debugf("adding %s witness to storage batch: %s", w.Type, w.Witness)
+
+		operations[i] = storage.Operation{
+			Key:   uuid.New().String(),
+			Value: value,
+			Tags: []storage.Tag{
+				{
+					Name:  vcIndex,
+					Value: vcIDEncoded,
+				},
+			},
+		}
+	}
+
+	return operations, nil
+}
@@ -297,6 +297,85 @@ func TestStore_Delete(t *testing.T) {
 	})
 }
 
+func TestStore_Replace(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		provider := mem.NewProvider()
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Put(vcID, []*proof.WitnessProof{getTestWitness()})
+		require.NoError(t, err)
+
+		err = s.Replace(vcID, []*proof.WitnessProof{
+			{Type: proof.WitnessTypeSystem, Witness: "witness2"},
+			{Type: proof.WitnessTypeSystem, Witness: "witness3"},
+		})
+		require.NoError(t, err)
+
+		witnesses, err := s.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 2)
+		require.Equal(t, "witness2", witnesses[0].Witness)
+		require.Equal(t, "witness3", witnesses[1].Witness)
+	})
+
+	t.Run("success - no existing witnesses", func(t *testing.T) {
+		s, err := New(mem.NewProvider())
+		require.NoError(t, err)
+
+		err = s.Replace(vcID, []*proof.WitnessProof{getTestWitness()})
+		require.NoError(t, err)
+
+		witnesses, err := s.Get(vcID)
+		require.NoError(t, err)
+		require.Len(t, witnesses, 1)
+	})
+
+	t.Run("error - query store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.QueryReturns(nil, fmt.Errorf("query error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Replace(vcID, []*proof.WitnessProof{getTestWitness()})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "query error")
+		require.Zero(t, store.BatchCallCount())
+	})
+
+	t.Run("error - batch error", func(t *testing.T) {
+		iterator := &mocks.Iterator{}
+
+		iterator.NextReturnsOnCall(0, true, nil)
+		iterator.NextReturnsOnCall(1, false, nil)
+
+		iterator.KeyReturnsOnCall(0, "key", nil)
+
+		store := &mocks.Store{}
+		store.QueryReturns(iterator, nil)
+		store.BatchReturns(fmt.Errorf("batch error"))
+
+		provider := &mocks.Provider{}
+		provider.OpenStoreReturns(store, nil)
+
+		s, err := New(provider)
+		require.NoError(t, err)
+
+		err = s.Replace(vcID, []*proof.WitnessProof{getTestWitness()})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "batch error")
+
+		// The delete and the put should be in the same batch.
+		require.Equal(t, 1, store.BatchCallCount())
+		require.Len(t, store.BatchArgsForCall(0), 2)
+	})
+}
+
 func TestStore_AddProof(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		provider := mem.NewProvider()
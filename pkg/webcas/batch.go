@@ -0,0 +1,204 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+
+	"github.com/trustbloc/edge-core/pkg/log"
+	casapi "github.com/trustbloc/sidetree-core-go/pkg/api/cas"
+	"github.com/trustbloc/sidetree-core-go/pkg/restapi/common"
+
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/store/spi"
+	orberrors "github.com/trustbloc/orb/pkg/errors"
+)
+
+const (
+	// BatchPath is the path of the WebCAS batch endpoint.
+	BatchPath = "/cas"
+
+	// ContentIDHeader is the multipart header that contains the CID of the content in the part.
+	ContentIDHeader = "Content-ID"
+
+	// MaxBatchSize is the maximum number of CIDs that may be requested in a single batch request.
+	MaxBatchSize = 100
+
+	// Limits the size of the request body (roughly MaxBatchSize CIDs).
+	maxBatchRequestSize = 64 * 1024
+)
+
+// Batch implements a WebCAS endpoint that returns the content for multiple CIDs in a single request.
+//
+// The request body is a JSON array of CIDs. The response is a multipart/mixed body that is streamed back to the
+// client, with one part per CID that was found. Each part contains a Content-ID header with the CID (enclosed in
+// angle brackets), along with the same Content-Type and ETag headers that are returned by the single-object endpoint.
+// CIDs that were not found are omitted from the response.
+type Batch struct {
+	*resthandler.AuthHandler
+
+	casClient casapi.Client
+	logger    logger
+}
+
+// Path returns the HTTP REST endpoint for the WebCAS batch service.
+func (b *Batch) Path() string {
+	return BatchPath
+}
+
+// Method returns the HTTP REST method for the WebCAS batch service.
+func (b *Batch) Method() string {
+	return http.MethodPost
+}
+
+// Handler returns the HTTP REST handler for the WebCAS batch service.
+func (b *Batch) Handler() common.HTTPRequestHandler {
+	return b.handler
+}
+
+// NewBatch returns a new WebCAS batch handler.
+func NewBatch(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, casClient casapi.Client) *Batch {
+	h := &Batch{
+		casClient: casClient,
+		logger:    log.New("webcas"),
+	}
+
+	// The batch endpoint only reads data, so it's protected by the same (read) tokens as the GET endpoint.
+	h.AuthHandler = resthandler.NewAuthHandler(authCfg, BatchPath, http.MethodGet, s, verifier,
+		func(actorIRI *url.URL) (bool, error) {
+			// Let all actors through, the same as for the single-object endpoint.
+			h.logger.Debugf("[%s] Authorized actor [%s]", h.Path(), actorIRI)
+
+			return true, nil
+		})
+
+	return h
+}
+
+func (b *Batch) handler(rw http.ResponseWriter, req *http.Request) {
+	if !authorize(b, b.logger, rw, req) {
+		return
+	}
+
+	cids, err := b.unmarshalCIDs(req.Body)
+	if err != nil {
+		b.logger.Infof("Invalid batch request from %s: %s", req.URL, err)
+
+		rw.WriteHeader(http.StatusBadRequest)
+
+		if _, errWrite := rw.Write([]byte(fmt.Sprintf("invalid request: %s", err))); errWrite != nil {
+			b.logger.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return
+	}
+
+	mw := multipart.NewWriter(rw)
+
+	rw.Header().Set(contentTypeHeader, "multipart/mixed; boundary="+mw.Boundary())
+	rw.WriteHeader(http.StatusOK)
+
+	flusher, _ := rw.(http.Flusher)
+
+	for _, cid := range cids {
+		if err := b.writePart(mw, cid); err != nil {
+			b.logger.Errorf("Failed to write batch response: %s", err)
+
+			return
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		b.logger.Errorf("Failed to write batch response: %s", err)
+	}
+}
+
+func (b *Batch) writePart(mw *multipart.Writer, cid string) error {
+	content, err := b.casClient.Read(cid)
+	if err != nil {
+		if errors.Is(err, orberrors.ErrContentNotFound) {
+			b.logger.Debugf("No content at %s was found", cid)
+		} else {
+			b.logger.Errorf("Failure while finding content at %s: %s", cid, err)
+		}
+
+		// Omit the part. The client can fall back to the single-object endpoint.
+		return nil
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set(ContentIDHeader, "<"+cid+">")
+	header.Set(contentTypeHeader, contentType(content))
+	header.Set(etagHeader, etag(cid))
+
+	w, err := mw.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("create part for %s: %w", cid, err)
+	}
+
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("write part for %s: %w", cid, err)
+	}
+
+	return nil
+}
+
+func (b *Batch) unmarshalCIDs(body io.Reader) ([]string, error) {
+	reqBytes, err := ioutil.ReadAll(io.LimitReader(body, maxBatchRequestSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+
+	if len(reqBytes) > maxBatchRequestSize {
+		return nil, fmt.Errorf("request body exceeds %d bytes", maxBatchRequestSize)
+	}
+
+	var cids []string
+
+	if err := json.Unmarshal(reqBytes, &cids); err != nil {
+		return nil, fmt.Errorf("expecting a JSON array of CIDs: %w", err)
+	}
+
+	if len(cids) == 0 {
+		return nil, errors.New("no CIDs specified")
+	}
+
+	if len(cids) > MaxBatchSize {
+		return nil, fmt.Errorf("the number of CIDs exceeds the maximum of %d", MaxBatchSize)
+	}
+
+	unique := make(map[string]struct{}, len(cids))
+	result := make([]string, 0, len(cids))
+
+	for _, cid := range cids {
+		if cid == "" {
+			return nil, errors.New("empty CID")
+		}
+
+		if _, ok := unique[cid]; ok {
+			continue
+		}
+
+		unique[cid] = struct{}{}
+
+		result = append(result, cid)
+	}
+
+	return result, nil
+}
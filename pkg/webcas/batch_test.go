@@ -0,0 +1,199 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package webcas_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/hyperledger/aries-framework-go/component/storageutil/mem"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/orb/pkg/activitypub/resthandler"
+	"github.com/trustbloc/orb/pkg/activitypub/service/mocks"
+	"github.com/trustbloc/orb/pkg/activitypub/store/memstore"
+	"github.com/trustbloc/orb/pkg/hashlink"
+	"github.com/trustbloc/orb/pkg/httpserver/auth"
+	orbmocks "github.com/trustbloc/orb/pkg/mocks"
+	"github.com/trustbloc/orb/pkg/store/cas"
+	"github.com/trustbloc/orb/pkg/webcas"
+)
+
+func TestNewBatch(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	b := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+	require.NotNil(t, b)
+	require.Equal(t, "/cas", b.Path())
+	require.Equal(t, http.MethodPost, b.Method())
+	require.NotNil(t, b.Handler())
+}
+
+func TestBatchHandler(t *testing.T) {
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+	require.NoError(t, err)
+
+	hl1, err := casClient.Write([]byte(sampleAnchorCredential))
+	require.NoError(t, err)
+
+	hl2, err := casClient.Write([]byte("some data"))
+	require.NoError(t, err)
+
+	rh1, err := hashlink.GetResourceHashFromHashLink(hl1)
+	require.NoError(t, err)
+
+	rh2, err := hashlink.GetResourceHashFromHashLink(hl2)
+	require.NoError(t, err)
+
+	const notFoundCID = "uEiB_g7Flf_H8U7ktwYFIodZd_C1LH6PWdyhK3dIAEm2QaQ"
+
+	b := webcas.NewBatch(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+
+	router := mux.NewRouter()
+	router.HandleFunc(b.Path(), b.Handler()).Methods(b.Method())
+
+	testServer := httptest.NewServer(router)
+	defer testServer.Close()
+
+	t.Run("Success", func(t *testing.T) {
+		resp := postCIDs(t, testServer.URL+"/cas", rh1, notFoundCID, rh2, rh1)
+		defer func() {
+			require.NoError(t, resp.Body.Close())
+		}()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		require.Equal(t, "multipart/mixed", mediaType)
+
+		reader := multipart.NewReader(resp.Body, params["boundary"])
+
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, "<"+rh1+">", part.Header.Get(webcas.ContentIDHeader))
+		require.Equal(t, "application/json", part.Header.Get("Content-Type"))
+		require.Equal(t, `"`+rh1+`"`, part.Header.Get("ETag"))
+
+		data, err := ioutil.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, sampleAnchorCredential, string(data))
+
+		part, err = reader.NextPart()
+		require.NoError(t, err)
+		require.Equal(t, "<"+rh2+">", part.Header.Get(webcas.ContentIDHeader))
+		require.True(t, strings.HasPrefix(part.Header.Get("Content-Type"), "text/plain"))
+
+		data, err = ioutil.ReadAll(part)
+		require.NoError(t, err)
+		require.Equal(t, "some data", string(data))
+
+		// The CID that wasn't found and the duplicate CID are omitted.
+		_, err = reader.NextPart()
+		require.Error(t, err)
+	})
+
+	t.Run("Invalid request", func(t *testing.T) {
+		t.Run("Not a JSON array", func(t *testing.T) {
+			resp, err := http.DefaultClient.Post(testServer.URL+"/cas", "application/json",
+				bytes.NewReader([]byte("{}")))
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("No CIDs", func(t *testing.T) {
+			resp := postCIDs(t, testServer.URL+"/cas")
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("Empty CID", func(t *testing.T) {
+			resp := postCIDs(t, testServer.URL+"/cas", rh1, "")
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+
+		t.Run("Too many CIDs", func(t *testing.T) {
+			cids := make([]string, webcas.MaxBatchSize+1)
+			for i := range cids {
+				cids[i] = rh1
+			}
+
+			resp := postCIDs(t, testServer.URL+"/cas", cids...)
+			require.NoError(t, resp.Body.Close())
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		b := webcas.NewBatch(authConfig(), memstore.New(""), &mocks.SignatureVerifier{}, casClient)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte(`["`+rh1+`"]`)))
+
+		b.Handler()(rw, req)
+
+		require.Equal(t, http.StatusUnauthorized, rw.Code)
+	})
+
+	t.Run("Authorization error", func(t *testing.T) {
+		v := &mocks.SignatureVerifier{}
+		v.VerifyRequestReturns(false, nil, errors.New("injected authorization error"))
+
+		b := webcas.NewBatch(authConfig(), memstore.New(""), v, casClient)
+
+		rw := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/cas", bytes.NewReader([]byte(`["`+rh1+`"]`)))
+
+		b.Handler()(rw, req)
+
+		require.Equal(t, http.StatusInternalServerError, rw.Code)
+	})
+}
+
+func authConfig() *resthandler.Config {
+	return &resthandler.Config{
+		Config: auth.Config{
+			AuthTokensDef: []*auth.TokenDef{
+				{
+					EndpointExpression: "/cas",
+					ReadTokens:         []string{"read"},
+				},
+			},
+			AuthTokens: map[string]string{
+				"read": "READ_TOKEN",
+			},
+		},
+	}
+}
+
+func postCIDs(t *testing.T, u string, cids ...string) *http.Response {
+	t.Helper()
+
+	if cids == nil {
+		cids = []string{}
+	}
+
+	reqBytes, err := json.Marshal(cids)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Post(u, "application/json", bytes.NewReader(reqBytes))
+	require.NoError(t, err)
+
+	return resp
+}
@@ -7,10 +7,13 @@ SPDX-License-Identifier: Apache-2.0
 package webcas
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/trustbloc/edge-core/pkg/log"
@@ -22,7 +25,22 @@ import (
 	orberrors "github.com/trustbloc/orb/pkg/errors"
 )
 
-const cidPathVariable = "cid"
+const (
+	cidPathVariable = "cid"
+
+	contentTypeHeader   = "Content-Type"
+	contentLengthHeader = "Content-Length"
+	cacheControlHeader  = "Cache-Control"
+	etagHeader          = "ETag"
+	ifNoneMatchHeader   = "If-None-Match"
+
+	jsonContentType = "application/json"
+
+	// Content is addressed by its hash so it never changes and may be cached indefinitely. If authorization is
+	// required then the content must not be cached by shared caches.
+	immutableCacheControl        = "public, max-age=31536000, immutable"
+	privateImmutableCacheControl = "private, max-age=31536000, immutable"
+)
 
 type logger interface {
 	Errorf(msg string, args ...interface{})
@@ -34,6 +52,10 @@ type signatureVerifier interface {
 	VerifyRequest(req *http.Request) (bool, *url.URL, error)
 }
 
+type authorizer interface {
+	Authorize(req *http.Request) (bool, *url.URL, error)
+}
+
 // WebCAS represents a WebCAS handler + client for the backing CAS.
 type WebCAS struct {
 	*resthandler.AuthHandler
@@ -81,35 +103,19 @@ func New(authCfg *resthandler.Config, s spi.Store, verifier signatureVerifier, c
 }
 
 func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
-	ok, _, err := w.Authorize(req)
-	if err != nil {
-		w.logger.Errorf("Error authorizing request from %s: %s", req.URL, err)
-
-		rw.WriteHeader(http.StatusInternalServerError)
-
-		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
-			w.logger.Errorf("Unable to write response: %s", errWrite)
-		}
-
+	if !authorize(w, w.logger, rw, req) {
 		return
 	}
 
-	if !ok {
-		w.logger.Infof("Request from %s is unauthorized", req.URL)
-
-		rw.WriteHeader(http.StatusUnauthorized)
+	cid := mux.Vars(req)[cidPathVariable]
+	ifNoneMatch := req.Header.Get(ifNoneMatchHeader)
 
-		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
-			w.logger.Errorf("Unable to write response: %s", errWrite)
-		}
+	if etagMatches(ifNoneMatch, cid) {
+		w.writeNotModified(rw, cid)
 
 		return
 	}
 
-	w.logger.Debugf("Request from %s is authorized", req.URL)
-
-	cid := mux.Vars(req)[cidPathVariable]
-
 	content, err := w.casClient.Read(cid)
 	if err != nil {
 		if errors.Is(err, orberrors.ErrContentNotFound) {
@@ -135,8 +141,107 @@ func (w *WebCAS) handler(rw http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	// The wildcard only matches if the content exists.
+	if etagMatchesAny(ifNoneMatch) {
+		w.writeNotModified(rw, cid)
+
+		return
+	}
+
+	w.setCachingHeaders(rw.Header(), cid)
+	rw.Header().Set(contentTypeHeader, contentType(content))
+	rw.Header().Set(contentLengthHeader, strconv.Itoa(len(content)))
+
 	_, err = rw.Write(content)
 	if err != nil {
 		w.logger.Errorf("failed to write success response: %s", err.Error())
 	}
 }
+
+// authorize authorizes the request and writes an error response if the request is not authorized.
+func authorize(a authorizer, l logger, rw http.ResponseWriter, req *http.Request) bool {
+	ok, _, err := a.Authorize(req)
+	if err != nil {
+		l.Errorf("Error authorizing request from %s: %s", req.URL, err)
+
+		rw.WriteHeader(http.StatusInternalServerError)
+
+		if _, errWrite := rw.Write([]byte("Internal Server Error.\n")); errWrite != nil {
+			l.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return false
+	}
+
+	if !ok {
+		l.Infof("Request from %s is unauthorized", req.URL)
+
+		rw.WriteHeader(http.StatusUnauthorized)
+
+		if _, errWrite := rw.Write([]byte("Unauthorized.\n")); errWrite != nil {
+			l.Errorf("Unable to write response: %s", errWrite)
+		}
+
+		return false
+	}
+
+	l.Debugf("Request from %s is authorized", req.URL)
+
+	return true
+}
+
+func (w *WebCAS) writeNotModified(rw http.ResponseWriter, cid string) {
+	w.logger.Debugf("Content at %s has not been modified", cid)
+
+	w.setCachingHeaders(rw.Header(), cid)
+	rw.WriteHeader(http.StatusNotModified)
+}
+
+func (w *WebCAS) setCachingHeaders(header http.Header, cid string) {
+	header.Set(etagHeader, etag(cid))
+
+	if w.AuthRequired() {
+		header.Set(cacheControlHeader, privateImmutableCacheControl)
+	} else {
+		header.Set(cacheControlHeader, immutableCacheControl)
+	}
+}
+
+// etag returns the entity tag for the given CID. Since the CID is derived from the content, it is used
+// as a strong validator.
+func etag(cid string) string {
+	return `"` + cid + `"`
+}
+
+// etagMatches returns true if the given If-None-Match header value contains the entity tag of the given CID.
+func etagMatches(ifNoneMatch, cid string) bool {
+	return containsTag(ifNoneMatch, etag(cid))
+}
+
+// etagMatchesAny returns true if the given If-None-Match header value contains the wildcard, which matches
+// any existing content.
+func etagMatchesAny(ifNoneMatch string) bool {
+	return containsTag(ifNoneMatch, "*")
+}
+
+func containsTag(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(tag), "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contentType(content []byte) string {
+	if json.Valid(content) {
+		return jsonContentType
+	}
+
+	return http.DetectContentType(content)
+}
@@ -30,7 +30,7 @@ const casLink = "https://domain.com/cas"
 type failingResponseWriter struct{}
 
 func (f *failingResponseWriter) Header() http.Header {
-	return nil
+	return http.Header{}
 }
 
 func (f *failingResponseWriter) Write([]byte) (int, error) {
@@ -60,7 +60,7 @@ func TestWriteResponseFailures(t *testing.T) {
 		t.Run("Status not found", func(t *testing.T) {
 			casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{
 				ErrGet: ariesstorage.ErrDataNotFound,
-			}}, casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			}}, casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 			require.NoError(t, err)
 
@@ -78,7 +78,7 @@ func TestWriteResponseFailures(t *testing.T) {
 				"content not found. Response write error: response write failure", testLogger.log)
 		})
 		t.Run("Internal server error", func(t *testing.T) {
-			casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+			casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 
 			require.NoError(t, err)
 
@@ -99,7 +99,7 @@ func TestWriteResponseFailures(t *testing.T) {
 	})
 	t.Run("Fail to write success response", func(t *testing.T) {
 		casClient, err := cas.New(&mock.Provider{OpenStoreReturn: &mock.Store{}}, casLink, nil,
-			&orbmocks.MetricsProvider{}, 0)
+			&orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		testLogger := &stringLogger{}
@@ -81,7 +81,7 @@ const sampleAnchorCredential = `{
 }`
 
 func TestNew(t *testing.T) {
-	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+	casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 	require.NoError(t, err)
 
 	webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
@@ -93,7 +93,7 @@ func TestNew(t *testing.T) {
 
 func TestHandler(t *testing.T) {
 	t.Run("Content found", func(t *testing.T) {
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		hl, err := casClient.Write([]byte(sampleAnchorCredential))
@@ -125,9 +125,55 @@ func TestHandler(t *testing.T) {
 
 		require.Equal(t, http.StatusOK, response.StatusCode)
 		require.Equal(t, sampleAnchorCredential, string(responseBody))
+		require.Equal(t, "application/json", response.Header.Get("Content-Type"))
+		require.Equal(t, `"`+rh+`"`, response.Header.Get("ETag"))
+		require.Equal(t, "public, max-age=31536000, immutable", response.Header.Get("Cache-Control"))
+
+		t.Run("Not modified", func(t *testing.T) {
+			for _, etag := range []string{`"` + rh + `"`, `"xxx", W/"` + rh + `"`, "*"} {
+				req, err := http.NewRequest(http.MethodGet, testServer.URL+"/cas/"+rh, nil)
+				require.NoError(t, err)
+
+				req.Header.Set("If-None-Match", etag)
+
+				resp, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				require.NoError(t, resp.Body.Close())
+
+				require.Equal(t, http.StatusNotModified, resp.StatusCode)
+				require.Equal(t, `"`+rh+`"`, resp.Header.Get("ETag"))
+			}
+		})
+
+		t.Run("Wildcard for content that doesn't exist", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet,
+				testServer.URL+"/cas/QmeKWPxUJP9M3WJgBuj8ykLtGU37iqur5gZ8cDCi49WJVG", nil)
+			require.NoError(t, err)
+
+			req.Header.Set("If-None-Match", "*")
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		})
+
+		t.Run("Modified", func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, testServer.URL+"/cas/"+rh, nil)
+			require.NoError(t, err)
+
+			req.Header.Set("If-None-Match", `"xxx"`)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			require.NoError(t, resp.Body.Close())
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+		})
 	})
 	t.Run("Content not found", func(t *testing.T) {
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
 		require.NoError(t, err)
 
 		webCAS := webcas.New(&resthandler.Config{}, memstore.New(""), &mocks.SignatureVerifier{}, casClient)
@@ -156,7 +202,13 @@ func TestHandler(t *testing.T) {
 	})
 
 	t.Run("Authorization", func(t *testing.T) {
-		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0)
+		casClient, err := cas.New(mem.NewProvider(), casLink, nil, &orbmocks.MetricsProvider{}, 0, nil)
+		require.NoError(t, err)
+
+		hl, err := casClient.Write([]byte(sampleAnchorCredential))
+		require.NoError(t, err)
+
+		rh, err := hashlink.GetResourceHashFromHashLink(hl)
 		require.NoError(t, err)
 
 		cfg := &resthandler.Config{
@@ -194,6 +246,14 @@ func TestHandler(t *testing.T) {
 
 			require.Equal(t, http.StatusNotFound, response.StatusCode)
 			require.NoError(t, response.Body.Close())
+
+			// Content that requires authorization must not be stored in shared caches.
+			response, err = http.DefaultClient.Get(testServer.URL + "/cas/" + rh)
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusOK, response.StatusCode)
+			require.Equal(t, "private, max-age=31536000, immutable", response.Header.Get("Cache-Control"))
+			require.NoError(t, response.Body.Close())
 		})
 
 		t.Run("Unauthorized", func(t *testing.T) {
@@ -252,7 +252,7 @@ func (d *DIDOrbSteps) clientRequestsAnchorOrigin(url string) error {
 
 	docLoader, err := ld.NewDocumentLoader(p, ld.WithExtraContexts(ldcontext.MustGetAll()...))
 
-	casClient := ipfs.New(url, 20*time.Second, 0, &mocks.MetricsProvider{})
+	casClient := ipfs.New(url, 20*time.Second, 0, &mocks.MetricsProvider{}, nil)
 
 	orbClient, err := orbclient.New(didDocNamespace, casClient,
 		orbclient.WithJSONLDDocumentLoader(docLoader),
@@ -346,3 +346,128 @@ Feature:
     When an HTTP GET is sent to "https://orb.domain2.com/services/orb/outbox?page=true"
     Then the JSON path "orderedItems.#.id" of the response contains "${undoFollowID}"
     And the JSON path "orderedItems.#.id" of the response contains "${followID}"
+
+  @activitypub_update
+  Scenario: update service
+    Given the authorization bearer token for "POST" requests to path "/services/orb/outbox" is set to "ADMIN_TOKEN"
+    And the authorization bearer token for "GET" requests to path "/services/orb" is set to "READ_TOKEN"
+
+    When an HTTP GET is sent to "https://orb.domain2.com/services/orb"
+    Then the response is saved to variable "domain2Service"
+
+    # An actor may only update itself
+    Given variable "invalidUpdateActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Update","actor":"${domain1IRI}","to":"${domain2IRI}","object":#{domain2Service}}'
+    When an HTTP POST is sent to "https://orb.domain1.com/services/orb/outbox" with content "${invalidUpdateActivity}" of type "application/json" and the returned status code is 400
+
+    # domain2 sends an update of its service to domain1
+    Given variable "updateActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Update","actor":"${domain2IRI}","to":"${domain1IRI}","object":#{domain2Service}}'
+    When an HTTP POST is sent to "https://orb.domain2.com/services/orb/outbox" with content "${updateActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "updateID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "${updateID}"
+    Then the JSON path "type" of the response equals "Update"
+    And the JSON path "object.id" of the response equals "${domain2IRI}"
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/inbox?page=true"
+    Then the JSON path "orderedItems.#.id" of the response contains "${updateID}"
+
+  @activitypub_delete
+  Scenario: delete service
+    Given the authorization bearer token for "POST" requests to path "/services/orb/outbox" is set to "ADMIN_TOKEN"
+    And the authorization bearer token for "GET" requests to path "/services/orb" is set to "READ_TOKEN"
+
+    # domain3 follows domain2
+    Given variable "followActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Follow","actor":"${domain3IRI}","to":"${domain2IRI}","object":"${domain2IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain3.com/services/orb/outbox" with content "${followActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "followID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain2.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response contains "${domain3IRI}"
+
+    # A service may only delete itself
+    Given variable "invalidDeleteActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Delete","actor":"${domain3IRI}","to":"${domain2IRI}","object":"${domain2IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain3.com/services/orb/outbox" with content "${invalidDeleteActivity}" of type "application/json" and the returned status code is 400
+
+    # domain3 is retracted so domain2 removes it from its collections
+    Given variable "deleteActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Delete","actor":"${domain3IRI}","to":"${domain2IRI}","object":"${domain3IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain3.com/services/orb/outbox" with content "${deleteActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "deleteID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain2.com/services/orb/inbox?page=true"
+    Then the JSON path "orderedItems.#.id" of the response contains "${deleteID}"
+
+    When an HTTP GET is sent to "https://orb.domain2.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response does not contain "${domain3IRI}"
+
+    # Clean up domain3's following collection
+    Given variable "undoFollowActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Undo","actor":"${domain3IRI}","to":"${domain2IRI}","object":{"actor":"${domain3IRI}","id":"${followID}","object":"${domain2IRI}","type":"Follow"}}'
+    When an HTTP POST is sent to "https://orb.domain3.com/services/orb/outbox" with content "${undoFollowActivity}" of type "application/json"
+
+  @activitypub_block
+  Scenario: block/undo
+    Given the authorization bearer token for "POST" requests to path "/services/orb/outbox" is set to "ADMIN_TOKEN"
+    And the authorization bearer token for "GET" requests to path "/services/orb" is set to "READ_TOKEN"
+
+    # domain2 follows domain1
+    Given variable "followActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Follow","actor":"${domain2IRI}","to":"${domain1IRI}","object":"${domain1IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain2.com/services/orb/outbox" with content "${followActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "followID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response contains "${domain2IRI}"
+
+    # A service may not block itself
+    Given variable "invalidBlockActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Block","actor":"${domain1IRI}","to":"${domain2IRI}","object":"${domain1IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain1.com/services/orb/outbox" with content "${invalidBlockActivity}" of type "application/json" and the returned status code is 400
+
+    # domain1 blocks domain2
+    Given variable "blockActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Block","actor":"${domain1IRI}","to":"${domain2IRI}","object":"${domain2IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain1.com/services/orb/outbox" with content "${blockActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "blockID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response does not contain "${domain2IRI}"
+
+    When an HTTP GET is sent to "https://orb.domain2.com/services/orb/following?page=true"
+    Then the JSON path "items" of the response does not contain "${domain1IRI}"
+
+    # Activities from a blocked service are rejected
+    Given variable "followAgainActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Follow","actor":"${domain2IRI}","to":"${domain1IRI}","object":"${domain1IRI}"}'
+    When an HTTP POST is sent to "https://orb.domain2.com/services/orb/outbox" with content "${followAgainActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "followAgainID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/inbox?page=true"
+    Then the JSON path "orderedItems.#.id" of the response does not contain "${followAgainID}"
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response does not contain "${domain2IRI}"
+
+    # domain1 unblocks domain2
+    Given variable "undoBlockActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Undo","actor":"${domain1IRI}","to":"${domain2IRI}","object":{"actor":"${domain1IRI}","id":"${blockID}","object":"${domain2IRI}","type":"Block"}}'
+    When an HTTP POST is sent to "https://orb.domain1.com/services/orb/outbox" with content "${undoBlockActivity}" of type "application/json"
+
+    Then we wait 3 seconds
+
+    # domain2 may follow domain1 again
+    When an HTTP POST is sent to "https://orb.domain2.com/services/orb/outbox" with content "${followActivity}" of type "application/json"
+    Then the value of the JSON string response is saved to variable "refollowID"
+
+    Then we wait 3 seconds
+
+    When an HTTP GET is sent to "https://orb.domain1.com/services/orb/followers?page=true"
+    Then the JSON path "items" of the response contains "${domain2IRI}"
+
+    Given variable "undoFollowActivity" is assigned the JSON value '{"@context":"https://www.w3.org/ns/activitystreams","type":"Undo","actor":"${domain2IRI}","to":"${domain1IRI}","object":{"actor":"${domain2IRI}","id":"${refollowID}","object":"${domain1IRI}","type":"Follow"}}'
+    When an HTTP POST is sent to "https://orb.domain2.com/services/orb/outbox" with content "${undoFollowActivity}" of type "application/json"